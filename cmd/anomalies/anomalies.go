@@ -0,0 +1,163 @@
+package anomalies
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"cloudsift/internal/anomaly"
+	"cloudsift/internal/history"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+type anomaliesOptions struct {
+	historyDir   string
+	detector     string
+	sensitivity  float64
+	minResources int
+	minSavings   float64
+}
+
+// NewAnomaliesCmd creates and returns the anomalies command
+func NewAnomaliesCmd() *cobra.Command {
+	opts := &anomaliesOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "anomalies",
+		Short: "Highlight sudden changes in unused resources or savings since the previous scan",
+		Long: `Compare the latest two scan runs recorded with scan --history-dir and highlight
+accounts whose unused resource count or monthly savings changed suddenly. A change is an
+anomaly when it is at least the sensitivity relative to the previous scan and at least the
+minimum absolute change. Prices that changed in the pricing cache snapshots of the two runs are
+listed too, as they change the savings of unchanged resources.`,
+		Example: `  # Compare the latest two scans
+  cloudsift anomalies --history-dir history
+
+  # Only flag changes of at least 100%
+  cloudsift anomalies --history-dir history --sensitivity 1.0`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !cmd.Flags().Changed("history-dir") {
+				opts.historyDir = viper.GetString("scan.history_dir")
+			}
+			if opts.historyDir == "" {
+				return fmt.Errorf("--history-dir is required")
+			}
+			if opts.sensitivity <= 0 {
+				return fmt.Errorf("--sensitivity must be greater than 0")
+			}
+
+			detector, err := anomaly.NewDetector(opts.detector, anomaly.DetectorConfig{
+				Sensitivity:      opts.sensitivity,
+				MinResources:     opts.minResources,
+				MinSavingsChange: opts.minSavings,
+			})
+			if err != nil {
+				return err
+			}
+
+			summaries, err := anomaly.LoadSummaries(opts.historyDir)
+			if err != nil {
+				return err
+			}
+			if len(summaries) < 2 {
+				return fmt.Errorf("found %d scan runs in %s, at least 2 are needed", len(summaries), opts.historyDir)
+			}
+			previous, current := summaries[len(summaries)-2], summaries[len(summaries)-1]
+
+			printAnomalies(os.Stdout, previous, current, detector.Detect(previous, current))
+
+			// Compare the pricing cache snapshots when both runs kept one
+			previousPrices, err := history.LoadPriceCache(opts.historyDir, previous.RunID)
+			if err != nil {
+				return err
+			}
+			currentPrices, err := history.LoadPriceCache(opts.historyDir, current.RunID)
+			if err != nil {
+				return err
+			}
+			if previousPrices != nil && currentPrices != nil {
+				printPriceChanges(os.Stdout, anomaly.PriceChanges(previousPrices, currentPrices))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.historyDir, "history-dir", "", "Directory of scan runs recorded by scan --history-dir (defaults to scan.history_dir of the config file)")
+	cmd.Flags().StringVar(&opts.detector, "detector", "threshold", fmt.Sprintf("Anomaly detector (%s)", strings.Join(anomaly.DetectorNames(), ", ")))
+	cmd.Flags().Float64Var(&opts.sensitivity, "sensitivity", anomaly.DefaultDetectorConfig.Sensitivity, "Relative change since the previous scan flagged as an anomaly (0.5 flags changes of 50% or more)")
+	cmd.Flags().IntVar(&opts.minResources, "min-resources", anomaly.DefaultDetectorConfig.MinResources, "Minimum change of the unused resource count of an account flagged as an anomaly")
+	cmd.Flags().Float64Var(&opts.minSavings, "min-savings", anomaly.DefaultDetectorConfig.MinSavingsChange, "Minimum change of the monthly savings of an account flagged as an anomaly")
+
+	return cmd
+}
+
+// printAnomalies writes the anomalies between two scans as a table
+func printAnomalies(w io.Writer, previous, current *anomaly.Summary, anomalies []anomaly.Anomaly) {
+	fmt.Fprintf(w, "Comparing scan of %s with previous scan of %s\n\n",
+		current.ScannedAt.Format("2006-01-02 15:04 MST"), previous.ScannedAt.Format("2006-01-02 15:04 MST"))
+
+	if previous.Currency != current.Currency {
+		fmt.Fprintf(w, "Savings were reported in %s before and in %s now, so they are not comparable\n\n", previous.Currency, current.Currency)
+	}
+
+	if len(anomalies) == 0 {
+		fmt.Fprintln(w, "No anomalies found")
+		return
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ACCOUNT\tMETRIC\tPREVIOUS\tCURRENT\tCHANGE")
+	for _, a := range anomalies {
+		account := a.AccountID
+		if a.AccountName != "" {
+			account = fmt.Sprintf("%s (%s)", a.AccountName, a.AccountID)
+		}
+		change := "new"
+		if relative := a.RelativeChange(); !math.IsInf(relative, 0) {
+			change = fmt.Sprintf("%+.0f%%", relative*100)
+		}
+		switch a.Metric {
+		case anomaly.MetricMonthlySavings:
+			fmt.Fprintf(tw, "%s\tMonthly savings (%s)\t%.2f\t%.2f\t%s\n", account, current.Currency, a.Previous, a.Current, change)
+		default:
+			fmt.Fprintf(tw, "%s\tUnused resources\t%.0f\t%.0f\t%s\n", account, a.Previous, a.Current, change)
+		}
+	}
+	tw.Flush()
+}
+
+// maxPriceChanges is the number of price changes listed, largest first
+const maxPriceChanges = 10
+
+// printPriceChanges writes the prices that changed between two scans as a table
+func printPriceChanges(w io.Writer, changes []anomaly.PriceChange) {
+	fmt.Fprintln(w)
+	if len(changes) == 0 {
+		fmt.Fprintln(w, "No cached prices changed")
+		return
+	}
+	fmt.Fprintf(w, "%d cached prices changed since the previous scan\n\n", len(changes))
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PRICE\tPREVIOUS\tCURRENT\tCHANGE")
+	for i, c := range changes {
+		if i == maxPriceChanges {
+			break
+		}
+		change := "new"
+		if relative := c.RelativeChange(); !math.IsInf(relative, 0) {
+			change = fmt.Sprintf("%+.0f%%", relative*100)
+		}
+		fmt.Fprintf(tw, "%s\t%g\t%g\t%s\n", c.Key, c.Previous, c.Current, change)
+	}
+	tw.Flush()
+	if len(changes) > maxPriceChanges {
+		fmt.Fprintf(w, "... and %d more\n", len(changes)-maxPriceChanges)
+	}
+}
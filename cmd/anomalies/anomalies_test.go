@@ -0,0 +1,187 @@
+package anomalies
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"cloudsift/internal/anomaly"
+	awsinternal "cloudsift/internal/aws"
+	"cloudsift/internal/history"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// findings returns scan results of a resource type, each costing the given monthly rate
+func findings(resourceType string, count int, monthlyRate float64) awsinternal.ScanResults {
+	results := make(awsinternal.ScanResults, count)
+	for i := range results {
+		results[i] = awsinternal.ScanResult{
+			ResourceType: resourceType,
+			Cost:         map[string]interface{}{"total": &awsinternal.CostBreakdown{MonthlyRate: monthlyRate}},
+		}
+	}
+	return results
+}
+
+// writeRun records a scan run of the accounts, given by ID and name, with the findings
+func writeRun(t *testing.T, dir string, startedAt time.Time, accounts map[string]string, findings ...history.Finding) {
+	t.Helper()
+	run := history.NewRun(startedAt, startedAt.Add(time.Minute), "USD", accounts, findings, history.NewScope(), history.RunMetrics{})
+	_, err := history.WriteRun(dir, run, findings)
+	require.NoError(t, err)
+}
+
+// runFindings returns recorded findings of a resource type in an account, each costing the
+// given monthly rate
+func runFindings(accountID, resourceType string, count int, monthlyRate float64) []history.Finding {
+	findings := make([]history.Finding, count)
+	for i := range findings {
+		findings[i] = history.Finding{
+			AccountID:    accountID,
+			ResourceType: resourceType,
+			ResourceID:   fmt.Sprintf("%s-%d", resourceType, i),
+			MonthlyCost:  monthlyRate,
+		}
+	}
+	return findings
+}
+
+func TestDetectAnomalies(t *testing.T) {
+	dir := t.TempDir()
+	scannedAt := time.Date(2024, 3, 1, 6, 0, 0, 0, time.UTC)
+
+	var previous []history.Finding
+	previous = append(previous, runFindings("111111111111", "EBS Volumes", 10, 10)...)
+	previous = append(previous, runFindings("222222222222", "EBS Volumes", 10, 10)...)
+	writeRun(t, dir, scannedAt, map[string]string{"111111111111": "prod", "222222222222": "dev", "333333333333": "staging"}, previous...)
+
+	var current []history.Finding
+	current = append(current, runFindings("111111111111", "EBS Volumes", 12, 10)...)   // Small change
+	current = append(current, runFindings("222222222222", "EBS Volumes", 25, 10)...)   // Jump in resources and savings
+	current = append(current, runFindings("333333333333", "Elastic IPs", 6, 3.65)...)  // New findings, but little savings
+	current = append(current, runFindings("444444444444", "Elastic IPs", 50, 3.65)...) // Not in the previous scan
+	unconfirmed := runFindings("111111111111", "NAT Gateways", 20, 32.85)              // Held back, not compared
+	for i := range unconfirmed {
+		unconfirmed[i].Unconfirmed = true
+	}
+	current = append(current, unconfirmed...)
+	writeRun(t, dir, scannedAt.AddDate(0, 0, 1), map[string]string{
+		"111111111111": "prod", "222222222222": "dev", "333333333333": "staging", "444444444444": "sandbox",
+	}, current...)
+
+	summaries, err := anomaly.LoadSummaries(dir)
+	require.NoError(t, err)
+	require.Len(t, summaries, 2)
+	assert.Equal(t, 10, summaries[0].Accounts["111111111111"].ResourcesByType["EBS Volumes"])
+	assert.InDelta(t, 250, summaries[1].Accounts["222222222222"].MonthlySavings, 0.001)
+	assert.Equal(t, 12, summaries[1].Accounts["111111111111"].Resources)
+	assert.Equal(t, 0, summaries[0].Accounts["333333333333"].Resources)
+
+	detector, err := anomaly.NewDetector("threshold", anomaly.DefaultDetectorConfig)
+	require.NoError(t, err)
+	anomalies := detector.Detect(summaries[0], summaries[1])
+	require.Len(t, anomalies, 3)
+	assert.Equal(t, "222222222222", anomalies[0].AccountID)
+	assert.Equal(t, anomaly.MetricResources, anomalies[0].Metric)
+	assert.Equal(t, anomaly.MetricMonthlySavings, anomalies[1].Metric)
+	assert.Equal(t, "333333333333", anomalies[2].AccountID)
+	assert.Equal(t, anomaly.MetricResources, anomalies[2].Metric)
+
+	var buf bytes.Buffer
+	printAnomalies(&buf, summaries[0], summaries[1], anomalies)
+	output := buf.String()
+	assert.Contains(t, output, "Comparing scan of 2024-03-02 06:00 UTC with previous scan of 2024-03-01 06:00 UTC")
+	assert.Contains(t, output, "dev (222222222222)")
+	assert.Contains(t, output, "+150%")
+	assert.Contains(t, output, "Monthly savings (USD)")
+	assert.Contains(t, output, "new")
+
+	// Lower sensitivity flags smaller changes
+	detector, err = anomaly.NewDetector("threshold", anomaly.DetectorConfig{Sensitivity: 0.1, MinResources: 1})
+	require.NoError(t, err)
+	assert.Len(t, detector.Detect(summaries[0], summaries[1]), 6)
+
+	_, err = anomaly.NewDetector("unknown", anomaly.DefaultDetectorConfig)
+	assert.Error(t, err)
+}
+
+func TestPriceChanges(t *testing.T) {
+	dir := t.TempDir()
+	scannedAt := time.Date(2024, 3, 1, 6, 0, 0, 0, time.UTC)
+	accounts := map[string]string{"111111111111": "prod"}
+	writeRun(t, dir, scannedAt, accounts)
+	writeRun(t, dir, scannedAt.AddDate(0, 0, 1), accounts)
+
+	summaries, err := anomaly.LoadSummaries(dir)
+	require.NoError(t, err)
+	require.Len(t, summaries, 2)
+	assert.Equal(t, "20240301T060000Z", summaries[0].RunID)
+
+	// Runs without a pricing cache snapshot have no prices to compare
+	prices, err := history.LoadPriceCache(dir, summaries[0].RunID)
+	require.NoError(t, err)
+	assert.Nil(t, prices)
+
+	require.NoError(t, history.WritePriceCache(dir, summaries[0].RunID, map[string]float64{
+		"EBS:us-east-1:gp3": 0.08, "NAT:us-east-1": 0.045, "EIP:us-east-1": 0.005, "Removed": 1,
+	}))
+	require.NoError(t, history.WritePriceCache(dir, summaries[1].RunID, map[string]float64{
+		"EBS:us-east-1:gp3": 0.08, "NAT:us-east-1": 0.05, "EIP:us-east-1": 0.0075, "Added": 1,
+	}))
+	previous, err := history.LoadPriceCache(dir, summaries[0].RunID)
+	require.NoError(t, err)
+	current, err := history.LoadPriceCache(dir, summaries[1].RunID)
+	require.NoError(t, err)
+
+	// Only prices cached by both scans are compared, largest relative change first
+	changes := anomaly.PriceChanges(previous, current)
+	assert.Equal(t, []anomaly.PriceChange{
+		{Key: "EIP:us-east-1", Previous: 0.005, Current: 0.0075},
+		{Key: "NAT:us-east-1", Previous: 0.045, Current: 0.05},
+	}, changes)
+
+	var buf bytes.Buffer
+	printPriceChanges(&buf, changes)
+	output := buf.String()
+	assert.Contains(t, output, "2 cached prices changed since the previous scan")
+	assert.Contains(t, output, "+50%")
+	assert.Contains(t, output, "+11%")
+}
+
+func TestWasteForecast(t *testing.T) {
+	forecast := awsinternal.ForecastWaste(awsinternal.TotalMonthlyWaste(findings("EBS Volumes", 10, 10)), 0)
+	assert.InDelta(t, 100, forecast.MonthlyWaste, 0.001)
+	require.Len(t, forecast.Projections, 3)
+	assert.Equal(t, 3, forecast.Projections[0].Months)
+	assert.InDelta(t, 300, forecast.Projections[0].Waste, 0.001)
+	assert.InDelta(t, 600, forecast.Projections[1].Waste, 0.001)
+	assert.InDelta(t, 1200, forecast.Projections[2].Waste, 0.001)
+
+	// Growing waste compounds monthly
+	forecast = awsinternal.ForecastWaste(100, 0.1)
+	assert.InDelta(t, 331, forecast.Projections[0].Waste, 0.001)
+
+	scannedAt := time.Date(2024, 3, 1, 6, 0, 0, 0, time.UTC)
+	oldest := anomaly.NewSummary(scannedAt, "USD")
+	oldest.Add("111111111111", "prod", findings("EBS Volumes", 10, 10))
+	recent := anomaly.NewSummary(scannedAt.AddDate(0, 0, 3), "USD")
+	recent.Add("111111111111", "prod", findings("EBS Volumes", 11, 10))
+
+	// A history shorter than a week gives no trend
+	_, ok := anomaly.SavingsGrowth([]*anomaly.Summary{oldest, recent})
+	assert.False(t, ok)
+
+	latest := anomaly.NewSummary(scannedAt.Add(365*24*time.Hour/12), "USD")
+	latest.Add("111111111111", "prod", findings("EBS Volumes", 12, 10))
+	growth, ok := anomaly.SavingsGrowth([]*anomaly.Summary{oldest, recent, latest})
+	assert.True(t, ok)
+	assert.InDelta(t, 0.2, growth, 0.001)
+
+	// Summaries in another currency are not compared
+	latest.Currency = "EUR"
+	_, ok = anomaly.SavingsGrowth([]*anomaly.Summary{oldest, recent, latest})
+	assert.False(t, ok)
+}
@@ -0,0 +1,131 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	historycmd "cloudsift/cmd/history"
+	"cloudsift/internal/history"
+
+	"github.com/spf13/cobra"
+)
+
+// defaultLimit is the number of newly unused and resolved findings listed in tables by default
+const defaultLimit = 20
+
+type diffOptions struct {
+	format string
+	limit  int
+}
+
+// resultsDiff is the change of the findings between two scan outputs, as written by
+// --format json
+type resultsDiff struct {
+	Old                  string               `json:"old"`
+	New                  string               `json:"new"`
+	OldFindings          int                  `json:"old_findings"`
+	NewFindings          int                  `json:"new_findings"`
+	OldMonthlySavings    float64              `json:"old_monthly_savings"`
+	NewMonthlySavings    float64              `json:"new_monthly_savings"`
+	MonthlySavingsChange float64              `json:"monthly_savings_change"`
+	NewlyUnused          []history.Finding    `json:"newly_unused"` // Findings of the new output only
+	Resolved             []history.Finding    `json:"resolved"`     // Findings of the old output only, remediated or gone
+	Unchanged            int                  `json:"unchanged"`
+	ResourceTypes        []history.TypeChange `json:"resource_types"`
+}
+
+// NewDiffCmd creates and returns the diff command
+func NewDiffCmd() *cobra.Command {
+	opts := &diffOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "diff <old> <new>",
+		Short: "Compare the findings of two JSON scan outputs",
+		Long: `Compare the findings of two scan outputs written by scan --output-format json, and report
+the newly unused resources, the resources that were remediated or disappeared, and the change
+of the estimated monthly savings, in total and by resource type.
+
+Each output is a results file (.json or .json.gz), or a directory whose results files are
+read together, such as the output directory of a scan of several accounts. When a directory
+holds several results of an account, the last one by file name, which is the latest scan, is
+used.`,
+		Example: `  # Compare the results of an account of two days
+  cloudsift diff output/2024/03/01/123456789012/06-00-00+0000.json.gz output/2024/03/08/123456789012/06-00-00+0000.json.gz
+
+  # Compare all accounts of two days as JSON
+  cloudsift diff output/2024/03/01 output/2024/03/08 --format json`,
+		Args:         cobra.ExactArgs(2),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if opts.format != "table" && opts.format != "json" {
+				return fmt.Errorf("invalid format: %s (table or json)", opts.format)
+			}
+
+			oldFindings, err := history.ReadResultsFindings(args[0])
+			if err != nil {
+				return err
+			}
+			newFindings, err := history.ReadResultsFindings(args[1])
+			if err != nil {
+				return err
+			}
+			diff := compare(args[0], args[1], oldFindings, newFindings)
+
+			if opts.format == "json" {
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(diff)
+			}
+			printDiff(os.Stdout, diff, opts.limit)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.format, "format", "table", "Output format (table, json)")
+	cmd.Flags().IntVar(&opts.limit, "limit", defaultLimit, "Number of newly unused and resolved findings listed in tables, by highest monthly cost (0 lists all)")
+	_ = cmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"table", "json"}, cobra.ShellCompDirectiveNoFileComp
+	})
+
+	return cmd
+}
+
+// compare returns the change of the findings between two scan outputs
+func compare(oldPath, newPath string, oldFindings, newFindings []history.Finding) resultsDiff {
+	changes := history.DiffRuns(oldFindings, newFindings)
+	diff := resultsDiff{
+		Old:           oldPath,
+		New:           newPath,
+		OldFindings:   len(oldFindings),
+		NewFindings:   len(newFindings),
+		NewlyUnused:   changes.New,
+		Resolved:      changes.Resolved,
+		Unchanged:     changes.Unchanged,
+		ResourceTypes: changes.ResourceTypes,
+	}
+	for _, finding := range oldFindings {
+		diff.OldMonthlySavings += finding.MonthlyCost
+	}
+	for _, finding := range newFindings {
+		diff.NewMonthlySavings += finding.MonthlyCost
+	}
+	diff.MonthlySavingsChange = diff.NewMonthlySavings - diff.OldMonthlySavings
+	return diff
+}
+
+// printDiff writes the totals of a diff, the change of each resource type, and the newly
+// unused and resolved findings up to the limit
+func printDiff(w io.Writer, diff resultsDiff, limit int) {
+	fmt.Fprintf(w, "Comparing %s with %s\n\n", diff.New, diff.Old)
+	fmt.Fprintf(w, "Findings:         %d -> %d (%+d): %d newly unused, %d resolved, %d unchanged\n",
+		diff.OldFindings, diff.NewFindings, diff.NewFindings-diff.OldFindings, len(diff.NewlyUnused), len(diff.Resolved), diff.Unchanged)
+	fmt.Fprintf(w, "Monthly savings:  %.2f -> %.2f (%+.2f)\n", diff.OldMonthlySavings, diff.NewMonthlySavings, diff.MonthlySavingsChange)
+	historycmd.PrintChanges(w, history.RunDiff{
+		New:           diff.NewlyUnused,
+		Resolved:      diff.Resolved,
+		Unchanged:     diff.Unchanged,
+		ResourceTypes: diff.ResourceTypes,
+	}, limit)
+}
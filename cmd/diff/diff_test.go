@@ -0,0 +1,92 @@
+package diff
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	awsinternal "cloudsift/internal/aws"
+	"cloudsift/internal/history"
+	"cloudsift/internal/output"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// volume returns the scan result of an unused EBS volume costing the given monthly rate
+func volume(id string, monthlyCost float64) awsinternal.ScanResult {
+	return awsinternal.ScanResult{
+		ResourceType: "EBS Volumes",
+		ResourceID:   id,
+		Reason:       "Volume is unattached.",
+		Details:      map[string]interface{}{"region": "us-east-1"},
+		Cost:         map[string]interface{}{"total": &awsinternal.CostBreakdown{MonthlyRate: monthlyCost}},
+	}
+}
+
+// writeResults writes the results of an account like scan --output-format json
+func writeResults(t *testing.T, path, accountID string, results awsinternal.ScanResults) {
+	data, err := json.Marshal(output.AccountResults{
+		AccountID:   accountID,
+		AccountName: "prod",
+		Results:     map[string]awsinternal.ScanResults{"EBS Volumes": results},
+	})
+	require.NoError(t, err)
+	var gzipped bytes.Buffer
+	gz := gzip.NewWriter(&gzipped)
+	_, err = gz.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, gzipped.Bytes(), 0644))
+}
+
+func TestDiff(t *testing.T) {
+	dir := t.TempDir()
+	oldDir := filepath.Join(dir, "2024", "03", "01")
+	newDir := filepath.Join(dir, "2024", "03", "08")
+
+	writeResults(t, filepath.Join(oldDir, "111111111111", "06-00-00+0000.json.gz"), "111111111111", awsinternal.ScanResults{volume("vol-1", 10), volume("vol-2", 20)})
+	writeResults(t, filepath.Join(newDir, "111111111111", "06-00-00+0000.json.gz"), "111111111111", awsinternal.ScanResults{volume("vol-1", 10)})
+	// The later scan of the day replaces the earlier one
+	writeResults(t, filepath.Join(newDir, "111111111111", "18-00-00+0000.json.gz"), "111111111111", awsinternal.ScanResults{volume("vol-2", 20), volume("vol-3", 50)})
+	writeResults(t, filepath.Join(newDir, "222222222222", "06-00-00+0000.json.gz"), "222222222222", awsinternal.ScanResults{volume("vol-4", 5)})
+
+	oldFindings, err := history.ReadResultsFindings(oldDir)
+	require.NoError(t, err)
+	newFindings, err := history.ReadResultsFindings(newDir)
+	require.NoError(t, err)
+	require.Len(t, newFindings, 3)
+	assert.Equal(t, "prod", newFindings[0].AccountName)
+
+	diff := compare(oldDir, newDir, oldFindings, newFindings)
+	require.Len(t, diff.NewlyUnused, 2)
+	assert.Equal(t, "vol-3", diff.NewlyUnused[0].ResourceID)
+	assert.Equal(t, "222222222222", diff.NewlyUnused[1].AccountID)
+	require.Len(t, diff.Resolved, 1)
+	assert.Equal(t, "vol-1", diff.Resolved[0].ResourceID)
+	assert.Equal(t, 1, diff.Unchanged)
+	assert.InDelta(t, 45.0, diff.MonthlySavingsChange, 0.001)
+
+	var buf bytes.Buffer
+	printDiff(&buf, diff, 1)
+	assert.Contains(t, buf.String(), "Findings:         2 -> 3 (+1): 2 newly unused, 1 resolved, 1 unchanged\n")
+	assert.Contains(t, buf.String(), "Monthly savings:  30.00 -> 75.00 (+45.00)\n")
+	assert.Contains(t, buf.String(), "New findings (1 of 2, by monthly cost)\n")
+	assert.Contains(t, buf.String(), "vol-3")
+	assert.NotContains(t, buf.String(), "vol-4")
+
+	data, err := json.Marshal(diff)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"monthly_savings_change":45`)
+	assert.Contains(t, string(data), `"resource_type":"EBS Volumes"`)
+
+	_, err = history.ReadResultsFindings(filepath.Join(dir, "missing"))
+	assert.Error(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "empty"), 0755))
+	_, err = history.ReadResultsFindings(filepath.Join(dir, "empty"))
+	assert.EqualError(t, err, "no scan results found in "+filepath.Join(dir, "empty"))
+}
@@ -0,0 +1,280 @@
+package doctor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"text/tabwriter"
+
+	awsinternal "cloudsift/internal/aws"
+	_ "cloudsift/internal/aws/scanners" // Import for side effects (scanner registration)
+	"cloudsift/internal/config"
+	"cloudsift/internal/output/html"
+	"cloudsift/internal/version"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/pricing"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// Check statuses
+const (
+	statusPass = "PASS"
+	statusWarn = "WARN"
+	statusFail = "FAIL"
+	statusSkip = "SKIP"
+)
+
+// checkResult is the outcome of a single doctor check
+type checkResult struct {
+	Name    string
+	Status  string
+	Message string
+}
+
+// NewDoctorCmd creates and returns the doctor command
+func NewDoctorCmd() *cobra.Command {
+	var region string
+
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check that CloudSift is installed and configured correctly",
+		Long: `Check that CloudSift is installed and configured correctly.
+Verifies the binary, configuration, AWS credentials, Pricing API reachability and cache
+writability, then validates each registered scanner against a single region without
+scanning. Include the output when filing a support request.`,
+		Example: `  # Run all checks
+  cloudsift doctor
+
+  # Validate scanners against a specific region
+  cloudsift doctor --region eu-west-1`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			results := runChecks(region)
+			printResults(os.Stdout, results)
+			for _, result := range results {
+				if result.Status == statusFail {
+					return fmt.Errorf("one or more checks failed")
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&region, "region", "us-east-1", "Region used to validate scanners")
+
+	return cmd
+}
+
+// runChecks runs all checks in order. Scanner checks are skipped when no credentials are available.
+func runChecks(region string) []checkResult {
+	results := []checkResult{
+		checkBinary(),
+		checkConfig(),
+	}
+
+	sess, credentialsResult := checkCredentials(region)
+	results = append(results, credentialsResult)
+	results = append(results, checkPricingAPI(sess))
+	results = append(results, checkCache("cache"))
+	results = append(results, checkScanners(sess, region)...)
+
+	return results
+}
+
+// checkBinary reports the version and checksum of the running binary
+func checkBinary() checkResult {
+	result := checkResult{Name: "Binary"}
+
+	path, err := os.Executable()
+	if err != nil {
+		result.Status, result.Message = statusFail, fmt.Sprintf("cannot locate executable: %v", err)
+		return result
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		result.Status, result.Message = statusFail, fmt.Sprintf("cannot read executable: %v", err)
+		return result
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		result.Status, result.Message = statusFail, fmt.Sprintf("cannot read executable: %v", err)
+		return result
+	}
+
+	versionString := version.ShortString()
+	if versionString == "" {
+		versionString = "dev"
+	}
+	result.Status = statusPass
+	result.Message = fmt.Sprintf("version %s, sha256 %s", versionString, hex.EncodeToString(hash.Sum(nil))[:16])
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.modified" && setting.Value == "true" {
+				result.Status = statusWarn
+				result.Message += ", built from a modified source tree"
+			}
+		}
+	}
+	return result
+}
+
+// checkConfig validates the loaded configuration values
+func checkConfig() checkResult {
+	result := checkResult{Name: "Configuration"}
+
+	source := viper.ConfigFileUsed()
+	if source == "" {
+		source = "defaults (no config file found)"
+	}
+
+	var problems []string
+	switch viper.GetString("scan.output_format") {
+	case "json", "html", "csv", "markdown":
+	default:
+		problems = append(problems, fmt.Sprintf("invalid scan.output_format %q", viper.GetString("scan.output_format")))
+	}
+	switch viper.GetString("scan.output") {
+	case "filesystem", "s3":
+	default:
+		problems = append(problems, fmt.Sprintf("invalid scan.output %q", viper.GetString("scan.output")))
+	}
+	switch strings.ToUpper(viper.GetString("app.log_level")) {
+	case "DEBUG", "INFO", "WARN", "ERROR":
+	default:
+		problems = append(problems, fmt.Sprintf("invalid app.log_level %q", viper.GetString("app.log_level")))
+	}
+	if err := html.ValidateLanguage(viper.GetString("scan.report_language")); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if viper.GetInt("scan.days_unused") <= 0 {
+		problems = append(problems, "scan.days_unused must be positive")
+	}
+
+	if len(problems) > 0 {
+		result.Status = statusFail
+		result.Message = fmt.Sprintf("%s: %s", source, strings.Join(problems, "; "))
+		return result
+	}
+	result.Status, result.Message = statusPass, source
+	return result
+}
+
+// checkCredentials verifies that the configured profile has working credentials
+func checkCredentials(region string) (*session.Session, checkResult) {
+	result := checkResult{Name: "Credentials"}
+
+	sess, err := awsinternal.NewSession(config.Config.Profile, region)
+	if err != nil {
+		result.Status, result.Message = statusFail, fmt.Sprintf("profile %s: %v", config.Config.Profile, err)
+		return nil, result
+	}
+	identity, err := sts.New(sess).GetCallerIdentity(&sts.GetCallerIdentityInput{})
+	if err != nil {
+		result.Status, result.Message = statusFail, fmt.Sprintf("profile %s: %v", config.Config.Profile, err)
+		return nil, result
+	}
+
+	result.Status, result.Message = statusPass, aws.StringValue(identity.Arn)
+	return sess, result
+}
+
+// checkPricingAPI verifies that the AWS Pricing API can be reached
+func checkPricingAPI(sess *session.Session) checkResult {
+	result := checkResult{Name: "Pricing API"}
+	if sess == nil {
+		result.Status, result.Message = statusSkip, "no credentials"
+		return result
+	}
+
+	_, err := pricing.New(sess, awsinternal.OptionalFIPSConfig("api.pricing", "us-east-1")).DescribeServices(&pricing.DescribeServicesInput{
+		ServiceCode: aws.String("AmazonEC2"),
+		MaxResults:  aws.Int64(1),
+	})
+	if err != nil {
+		result.Status, result.Message = statusFail, err.Error()
+		return result
+	}
+	result.Status, result.Message = statusPass, "reachable"
+	return result
+}
+
+// checkCache verifies that the price cache directory is writable
+func checkCache(dir string) checkResult {
+	result := checkResult{Name: "Cache"}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		result.Status, result.Message = statusFail, fmt.Sprintf("cannot create %s: %v", dir, err)
+		return result
+	}
+	f, err := os.CreateTemp(dir, ".doctor-*")
+	if err != nil {
+		result.Status, result.Message = statusFail, fmt.Sprintf("cannot write to %s: %v", dir, err)
+		return result
+	}
+	f.Close()
+	os.Remove(f.Name())
+
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		abs = dir
+	}
+	result.Status, result.Message = statusPass, abs
+	return result
+}
+
+// checkScanners validates each registered scanner by probing the region for resources,
+// which exercises the scanner's API permissions without running a scan
+func checkScanners(sess *session.Session, region string) []checkResult {
+	var results []checkResult
+	for _, name := range awsinternal.DefaultRegistry.ListScanners() {
+		scanner, err := awsinternal.DefaultRegistry.GetScanner(name)
+		if err != nil {
+			continue
+		}
+		result := checkResult{Name: "Scanner " + name}
+
+		prober, ok := scanner.(awsinternal.ResourceProber)
+		switch {
+		case sess == nil:
+			result.Status, result.Message = statusSkip, "no credentials"
+		case !ok:
+			result.Status, result.Message = statusSkip, "scanner does not support validation"
+		default:
+			hasResources, err := prober.HasResources(awsinternal.ScanOptions{
+				Region:  region,
+				Session: sess,
+			})
+			if err != nil {
+				result.Status, result.Message = statusFail, err.Error()
+			} else if hasResources {
+				result.Status, result.Message = statusPass, fmt.Sprintf("resources found in %s", region)
+			} else {
+				result.Status, result.Message = statusPass, fmt.Sprintf("no resources in %s", region)
+			}
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// printResults writes the check results as a table
+func printResults(w io.Writer, results []checkResult) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CHECK\tSTATUS\tDETAILS")
+	for _, result := range results {
+		message := strings.Join(strings.Fields(result.Message), " ")
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", result.Name, result.Status, message)
+	}
+	tw.Flush()
+}
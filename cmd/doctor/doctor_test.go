@@ -0,0 +1,33 @@
+package doctor
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckCache(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "cache")
+	result := checkCache(dir)
+	assert.Equal(t, statusPass, result.Status)
+	assert.DirExists(t, dir)
+}
+
+func TestCheckScannersWithoutCredentials(t *testing.T) {
+	results := checkScanners(nil, "us-east-1")
+	assert.NotEmpty(t, results)
+	for _, result := range results {
+		assert.Equal(t, statusSkip, result.Status)
+	}
+}
+
+func TestPrintResults(t *testing.T) {
+	var buf bytes.Buffer
+	printResults(&buf, []checkResult{
+		{Name: "Credentials", Status: statusFail, Message: "no valid providers\nin chain"},
+	})
+	assert.Contains(t, buf.String(), "CHECK")
+	assert.Contains(t, buf.String(), "no valid providers in chain")
+}
@@ -0,0 +1,298 @@
+package history
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"cloudsift/internal/history"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// defaultDiffLimit is the number of new and resolved findings listed by diff by default
+const defaultDiffLimit = 20
+
+type historyOptions struct {
+	historyDir string
+	findings   bool
+	limit      int
+}
+
+// NewHistoryCmd creates and returns the history command
+func NewHistoryCmd() *cobra.Command {
+	opts := &historyOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "history",
+		Aliases: []string{"hist"},
+		Short:   "List, show and compare the scan runs recorded with scan --history-dir",
+		Long: `List, show and compare the scan runs recorded with scan --history-dir, to follow how
+the unused resources and estimated savings change over time. Runs are named by their start
+time, e.g. 20240301T060000Z; "latest" and "previous" name the last two runs.`,
+	}
+	cmd.PersistentFlags().StringVar(&opts.historyDir, "history-dir", "", "Directory of scan runs recorded by scan --history-dir (defaults to scan.history_dir of the config file)")
+
+	cmd.AddCommand(newListCmd(opts), newShowCmd(opts), newDiffCmd(opts))
+	return cmd
+}
+
+// loadRuns returns the runs of the history directory of the flag or the config file
+func loadRuns(cmd *cobra.Command, opts *historyOptions) ([]*history.Run, error) {
+	if !cmd.Flags().Changed("history-dir") {
+		opts.historyDir = viper.GetString("scan.history_dir")
+	}
+	if opts.historyDir == "" {
+		return nil, fmt.Errorf("--history-dir is required")
+	}
+	runs, err := history.ListRuns(opts.historyDir)
+	if err != nil {
+		return nil, err
+	}
+	if len(runs) == 0 {
+		return nil, fmt.Errorf("no scan runs recorded in %s", opts.historyDir)
+	}
+	return runs, nil
+}
+
+func newListCmd(opts *historyOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the recorded scan runs with their findings and savings",
+		Example: `  # List the runs recorded in the history directory
+  cloudsift history list --history-dir history`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			runs, err := loadRuns(cmd, opts)
+			if err != nil {
+				return err
+			}
+			printRuns(os.Stdout, runs)
+			return nil
+		},
+	}
+}
+
+func newShowCmd(opts *historyOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "show [run]",
+		Short: "Show the metrics and findings of a scan run (default: latest)",
+		Example: `  # Show the latest run
+  cloudsift history show --history-dir history
+
+  # Show a run with all of its findings
+  cloudsift history show 20240301T060000Z --history-dir history --findings`,
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			runs, err := loadRuns(cmd, opts)
+			if err != nil {
+				return err
+			}
+			id := "latest"
+			if len(args) > 0 {
+				id = args[0]
+			}
+			run, err := history.FindRun(runs, id)
+			if err != nil {
+				return err
+			}
+			findings, err := history.LoadFindings(opts.historyDir, run.ID)
+			if err != nil {
+				return err
+			}
+			printRun(os.Stdout, run, history.Confirmed(findings), opts.findings)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&opts.findings, "findings", false, "List every finding of the run")
+	return cmd
+}
+
+func newDiffCmd(opts *historyOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff [from] [to]",
+		Short: "Compare the findings of two scan runs (default: previous and latest)",
+		Example: `  # Compare the last two runs
+  cloudsift history diff --history-dir history
+
+  # Compare a run with the latest run
+  cloudsift history diff 20240301T060000Z --history-dir history`,
+		Args:         cobra.MaximumNArgs(2),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			runs, err := loadRuns(cmd, opts)
+			if err != nil {
+				return err
+			}
+			ids := []string{"previous", "latest"}
+			copy(ids, args)
+			from, err := history.FindRun(runs, ids[0])
+			if err != nil {
+				return err
+			}
+			to, err := history.FindRun(runs, ids[1])
+			if err != nil {
+				return err
+			}
+			fromFindings, err := history.LoadFindings(opts.historyDir, from.ID)
+			if err != nil {
+				return err
+			}
+			toFindings, err := history.LoadFindings(opts.historyDir, to.ID)
+			if err != nil {
+				return err
+			}
+			printDiff(os.Stdout, from, to, history.DiffRuns(history.Confirmed(fromFindings), history.Confirmed(toFindings)), opts.limit)
+			return nil
+		},
+	}
+	cmd.Flags().IntVar(&opts.limit, "limit", defaultDiffLimit, "Number of new and resolved findings listed, by highest monthly cost (0 lists all)")
+	return cmd
+}
+
+// printRuns writes the runs as a table, with the change of the savings since the run before
+func printRuns(w io.Writer, runs []*history.Run) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "RUN\tSTARTED\tACCOUNTS\tFINDINGS\tMONTHLY SAVINGS\tCHANGE\tFAILED TASKS\tDURATION")
+	for i, run := range runs {
+		change := "-"
+		if i > 0 && runs[i-1].Currency == run.Currency {
+			change = fmt.Sprintf("%+.2f", run.MonthlySavings-runs[i-1].MonthlySavings)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%.2f %s\t%s\t%d\t%s\n",
+			run.ID, run.StartedAt.Format("2006-01-02 15:04 MST"), run.Accounts, run.Findings,
+			run.MonthlySavings, run.Currency, change, run.Metrics.FailedTasks, runDuration(run))
+	}
+	tw.Flush()
+}
+
+// printRun writes the metrics of a run and its findings by resource type, and every finding
+// if requested
+func printRun(w io.Writer, run *history.Run, findings []history.Finding, allFindings bool) {
+	fmt.Fprintf(w, "Run:              %s\n", run.ID)
+	fmt.Fprintf(w, "Started:          %s\n", run.StartedAt.Format("2006-01-02 15:04:05 MST"))
+	fmt.Fprintf(w, "Duration:         %s\n", runDuration(run))
+	fmt.Fprintf(w, "Accounts:         %d (%d skipped)\n", run.Accounts, run.Metrics.SkippedAccounts)
+	fmt.Fprintf(w, "Findings:         %d\n", run.Findings)
+	fmt.Fprintf(w, "Monthly savings:  %.2f %s\n", run.MonthlySavings, run.Currency)
+	fmt.Fprintf(w, "Tasks:            %d completed, %d failed\n", run.Metrics.CompletedTasks, run.Metrics.FailedTasks)
+	fmt.Fprintf(w, "API calls:        %d (about %.2f USD)\n", run.Metrics.APICalls, run.Metrics.APICallCost)
+	if run.Metrics.APIBudgetExceeded {
+		fmt.Fprintln(w, "The scan stopped early at its API call budget")
+	}
+
+	type typeTotal struct {
+		findings int
+		savings  float64
+	}
+	totals := make(map[string]*typeTotal)
+	var resourceTypes []string
+	for _, finding := range findings {
+		total, ok := totals[finding.ResourceType]
+		if !ok {
+			total = &typeTotal{}
+			totals[finding.ResourceType] = total
+			resourceTypes = append(resourceTypes, finding.ResourceType)
+		}
+		total.findings++
+		total.savings += finding.SavingsCost()
+	}
+	sort.Slice(resourceTypes, func(i, j int) bool {
+		a, b := totals[resourceTypes[i]], totals[resourceTypes[j]]
+		if a.savings != b.savings {
+			return a.savings > b.savings
+		}
+		return resourceTypes[i] < resourceTypes[j]
+	})
+
+	if len(resourceTypes) > 0 {
+		fmt.Fprintln(w)
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "RESOURCE TYPE\tFINDINGS\tMONTHLY SAVINGS")
+		for _, resourceType := range resourceTypes {
+			fmt.Fprintf(tw, "%s\t%d\t%.2f\n", resourceType, totals[resourceType].findings, totals[resourceType].savings)
+		}
+		tw.Flush()
+	}
+
+	if allFindings && len(findings) > 0 {
+		fmt.Fprintln(w)
+		printFindings(w, findings)
+	}
+}
+
+// printDiff writes the change of the findings between two runs: the totals, and the changes
+// of PrintChanges
+func printDiff(w io.Writer, from, to *history.Run, diff history.RunDiff, limit int) {
+	fmt.Fprintf(w, "Comparing run %s with run %s\n\n", to.ID, from.ID)
+	if from.Currency != to.Currency {
+		fmt.Fprintf(w, "Savings were reported in %s before and in %s now, so they are not comparable\n\n", from.Currency, to.Currency)
+	}
+
+	fmt.Fprintf(w, "Findings:         %d -> %d (%+d): %d new, %d resolved, %d unchanged\n",
+		from.Findings, to.Findings, to.Findings-from.Findings, len(diff.New), len(diff.Resolved), diff.Unchanged)
+	fmt.Fprintf(w, "Monthly savings:  %.2f -> %.2f %s (%+.2f)\n",
+		from.MonthlySavings, to.MonthlySavings, to.Currency, to.MonthlySavings-from.MonthlySavings)
+	PrintChanges(w, diff, limit)
+}
+
+// PrintChanges writes the change of each resource type of a diff, and its new and resolved
+// findings up to the limit (0 for all), by highest monthly cost
+func PrintChanges(w io.Writer, diff history.RunDiff, limit int) {
+	if len(diff.ResourceTypes) > 0 {
+		fmt.Fprintln(w)
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "RESOURCE TYPE\tFINDINGS\tCHANGE\tMONTHLY SAVINGS\tCHANGE")
+		for _, change := range diff.ResourceTypes {
+			fmt.Fprintf(tw, "%s\t%d -> %d\t%+d\t%.2f -> %.2f\t%+.2f\n", change.ResourceType,
+				change.PreviousCount, change.CurrentCount, change.CurrentCount-change.PreviousCount,
+				change.PreviousSavings, change.CurrentSavings, change.CurrentSavings-change.PreviousSavings)
+		}
+		tw.Flush()
+	}
+
+	for _, section := range []struct {
+		title    string
+		findings []history.Finding
+	}{
+		{"New findings", diff.New},
+		{"Resolved findings", diff.Resolved},
+	} {
+		if len(section.findings) == 0 {
+			continue
+		}
+		findings := section.findings
+		title := fmt.Sprintf("%s (%d)", section.title, len(findings))
+		if limit > 0 && len(findings) > limit {
+			findings = findings[:limit]
+			title = fmt.Sprintf("%s (%d of %d, by monthly cost)", section.title, limit, len(section.findings))
+		}
+		fmt.Fprintf(w, "\n%s\n", title)
+		printFindings(w, findings)
+	}
+}
+
+// printFindings writes findings as a table
+func printFindings(w io.Writer, findings []history.Finding) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ACCOUNT\tREGION\tRESOURCE TYPE\tRESOURCE\tMONTHLY COST")
+	for _, finding := range findings {
+		resource := finding.ResourceID
+		if finding.ResourceName != "" && finding.ResourceName != finding.ResourceID {
+			resource = fmt.Sprintf("%s (%s)", finding.ResourceName, finding.ResourceID)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%.2f\n", finding.AccountID, finding.Region, finding.ResourceType, resource, finding.MonthlyCost)
+	}
+	tw.Flush()
+}
+
+// runDuration returns the run time of a run rounded to seconds
+func runDuration(run *history.Run) string {
+	return (time.Duration(run.Metrics.RunTimeSeconds * float64(time.Second))).Round(time.Second).String()
+}
@@ -0,0 +1,102 @@
+package history
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"cloudsift/internal/history"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// volume returns a finding of an unused EBS volume costing the given monthly rate
+func volume(id string, monthlyCost float64) history.Finding {
+	return history.Finding{
+		AccountID:    "111111111111",
+		AccountName:  "prod",
+		Region:       "us-east-1",
+		ResourceType: "EBS Volumes",
+		ResourceID:   id,
+		Reason:       "Volume is unattached.",
+		MonthlyCost:  monthlyCost,
+	}
+}
+
+func TestHistoryRuns(t *testing.T) {
+	dir := t.TempDir()
+	startedAt := time.Date(2024, 3, 1, 6, 0, 0, 0, time.UTC)
+
+	previousFindings := []history.Finding{volume("vol-1", 10), volume("vol-2", 20)}
+	previous := history.NewRun(startedAt, startedAt.Add(2*time.Minute), "USD", map[string]string{"111111111111": "prod"}, previousFindings, history.NewScope(), history.RunMetrics{
+		CompletedTasks: 40,
+		RunTimeSeconds: 120,
+	})
+	_, err := history.WriteRun(dir, previous, previousFindings)
+	require.NoError(t, err)
+
+	currentFindings := []history.Finding{
+		volume("vol-2", 20),
+		volume("vol-3", 50),
+		{AccountID: "111111111111", Region: "us-east-1", ResourceType: "Elastic IPs", ResourceID: "eipalloc-1", MonthlyCost: 3.65},
+	}
+	current := history.NewRun(startedAt.AddDate(0, 0, 1), startedAt.AddDate(0, 0, 1).Add(time.Minute), "USD", map[string]string{"111111111111": "prod"}, currentFindings, history.NewScope(), history.RunMetrics{
+		CompletedTasks: 38,
+		FailedTasks:    2,
+		RunTimeSeconds: 61.4,
+	})
+	_, err = history.WriteRun(dir, current, currentFindings)
+	require.NoError(t, err)
+
+	runs, err := history.ListRuns(dir)
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+	assert.Equal(t, "20240301T060000Z", runs[0].ID)
+	assert.InDelta(t, 73.65, runs[1].MonthlySavings, 0.001)
+
+	var buf bytes.Buffer
+	printRuns(&buf, runs)
+	assert.Contains(t, buf.String(), "20240302T060000Z  2024-03-02 06:00 UTC  1         3         73.65 USD        +43.65  2             1m1s")
+
+	run, err := history.FindRun(runs, "previous")
+	require.NoError(t, err)
+	assert.Equal(t, previous.ID, run.ID)
+	_, err = history.FindRun(runs, "20240101T000000Z")
+	assert.EqualError(t, err, "scan run 20240101T000000Z not found")
+
+	findings, err := history.LoadFindings(dir, runs[1].ID)
+	require.NoError(t, err)
+	assert.Equal(t, currentFindings, findings)
+
+	buf.Reset()
+	printRun(&buf, runs[1], findings, true)
+	assert.Contains(t, buf.String(), "Tasks:            38 completed, 2 failed\n")
+	assert.Contains(t, buf.String(), "EBS Volumes    2         70.00\n")
+	assert.Contains(t, buf.String(), "111111111111  us-east-1  Elastic IPs    eipalloc-1  3.65\n")
+
+	diff := history.DiffRuns(previousFindings, findings)
+	assert.Equal(t, []history.Finding{volume("vol-3", 50), currentFindings[2]}, diff.New)
+	assert.Equal(t, []history.Finding{volume("vol-1", 10)}, diff.Resolved)
+	assert.Equal(t, 1, diff.Unchanged)
+	require.Len(t, diff.ResourceTypes, 2)
+	assert.Equal(t, history.TypeChange{
+		ResourceType:    "EBS Volumes",
+		PreviousCount:   2,
+		CurrentCount:    2,
+		PreviousSavings: 30,
+		CurrentSavings:  70,
+		New:             1,
+		Resolved:        1,
+		Unchanged:       1,
+	}, diff.ResourceTypes[0])
+
+	buf.Reset()
+	printDiff(&buf, runs[0], runs[1], diff, 1)
+	assert.Contains(t, buf.String(), "Findings:         2 -> 3 (+1): 2 new, 1 resolved, 1 unchanged\n")
+	assert.Contains(t, buf.String(), "Monthly savings:  30.00 -> 73.65 USD (+43.65)\n")
+	assert.Contains(t, buf.String(), "New findings (1 of 2, by monthly cost)\n")
+	assert.Contains(t, buf.String(), "vol-3")
+	assert.NotContains(t, buf.String(), "eipalloc-1")
+	assert.Contains(t, buf.String(), "Resolved findings (1)\n")
+}
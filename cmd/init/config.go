@@ -5,6 +5,9 @@ import (
 	"os"
 	"path/filepath"
 
+	awsinternal "cloudsift/internal/aws"
+	"cloudsift/internal/config"
+
 	"github.com/spf13/cobra"
 )
 
@@ -15,6 +18,17 @@ aws:
   profile: default  # AWS profile to use (supports SSO profiles)
   organization_role: ""  # Role name to assume for organization-wide operations
   scanner_role: ""  # Role name to assume for scanning operations
+  sts_region: ""  # Region of the regional STS endpoint used for role assumption (defaults to the session region)
+  accounts_cache_ttl: 1h  # How long the organization account list is cached in the cache directory (0 disables the cache)
+  https_proxy: ""  # Proxy URL of AWS requests (defaults to the HTTPS_PROXY environment variable)
+  ca_bundle: ""  # PEM file of certificates trusted in addition to the system roots, e.g. of a TLS intercepting proxy
+  request_timeout: 25s  # Timeout of each AWS request (0 disables it)
+  use_fips_endpoints: false  # Use FIPS endpoints for all AWS requests (US, Canada and GovCloud regions)
+  account_mappings:  # Friendly names, teams and environments by account ID, overriding Organizations names
+    # "123456789012":
+    #   name: payments-prod
+    #   team: payments
+    #   environment: prod
 
 # Application Configuration
 app:
@@ -28,7 +42,7 @@ list:
 
 # Scan Command Configuration
 scan:
-  # List of regions to scan (default: all available regions)
+  # List of regions to scan, or [all-enabled] (default: all enabled regions)
   regions:
    # - us-west-2  # Example region
    # - us-east-1  # Example region
@@ -44,10 +58,110 @@ scan:
    # - 098765432109  # Example account ID
   
   output: filesystem  # Output type (filesystem or s3)
-  output_format: html  # Output format (json or html)
+  output_format: html  # Output format (json, html, csv or markdown)
   bucket: ""  # S3 bucket name (required when output=s3)
   bucket_region: ""  # S3 bucket region (required when output=s3)
   days_unused: 90  # Number of days a resource must be unused to be reported
+  count_resources: false  # Count resources with the tagging API before scanning to report progress by resource
+  skip_empty_regions: false  # Skip region/scanner combinations that contain no resources
+  exclude_asg_instances: false  # Exclude instances managed by Auto Scaling groups from EC2 idle detection
+  exclude_spot_instances: false  # Exclude Spot instances, including Spot fleet members, from EC2 idle detection
+  business_hours: ""  # Business hours window for utilization analysis, e.g. "Mon-Fri 08:00-18:00" (empty disables)
+  business_hours_timezone: UTC  # IANA timezone of the business hours window
+  scheduling_plan: ""  # Path to write an AWS Instance Scheduler compatible scheduling plan (requires business_hours)
+  report_language: en  # Language of the HTML report (en, de, fr)
+  currency: USD  # Currency to report costs in (e.g. EUR); costs are converted from USD
+  exchange_rate: 0  # Static USD exchange rate for currency (0 uses exchange_rate_url or exchange_rates)
+  exchange_rate_url: ""  # URL returning USD exchange rates as JSON, e.g. {"rates": {"EUR": 0.92}}
+  annotations_file: ""  # Path to a YAML or JSON file of reviewer annotations keyed by resource ID
+  sso_start_url: ""  # IAM Identity Center start URL (sign in first with cloudsift login)
+  sso_region: us-east-1  # Region of the IAM Identity Center instance
+  sso_role_name: ""  # Permission set used in each account (requires sso_start_url)
+  require_read_only: false  # Abort the scan unless the scan credentials are verified to be read-only
+  enabled_scanners:  # Scanners run when no scanners are selected (default: all available scanners)
+    # - ec2-instances
+    # - ebs-volumes
+  disabled_scanners:  # Scanners never run, even when selected with --scanners
+    # - iam-users
+  account_scanner_exclusions:  # Scanners never run in an account, keyed by account ID
+    # "123456789012":
+    #   - iam-users
+  account_max_workers:  # Per-account overrides of max_workers_per_account, keyed by account ID
+    # "123456789012": 1
+  scanner_days_unused:  # Per-scanner overrides of days_unused
+    # ebs-snapshots: 180
+    # elastic-ips: 30
+  trim_details: false  # Trim result details to reduce memory use; full details are written to output/details
+  iam_last_accessed: false  # Confirm unused IAM roles with IAM access advisor service last accessed data
+  access_analyzer: false  # Import IAM Access Analyzer unused access findings into the IAM results
+  emr_idle_hours: 4  # Hours an EMR cluster may sit in WAITING without steps before it is reported
+  security_hub: false  # Publish findings to AWS Security Hub as custom findings (ASFF)
+  security_hub_region: us-east-1  # Region Security Hub findings are imported into
+  ops_center: false  # Open AWS Systems Manager OpsCenter OpsItems for severe findings
+  ops_center_region: us-east-1  # Region OpsCenter OpsItems are opened in
+  ops_center_min_severity: HIGH  # Lowest finding severity OpsItems are opened for (INFORMATIONAL, LOW, MEDIUM, HIGH)
+  prefetch_prices: false  # Resolve distinct prices once in a pre-scan inventory pass across all accounts
+  include_suspended_accounts: false  # Scan organization accounts that are suspended or pending closure instead of skipping them
+  include_metric_samples: false  # Keep the raw CloudWatch datapoints behind each finding in its details
+  confirmation_scans: 1  # Consecutive scans a finding must appear in before it is reported (requires history_dir)
+  include_managed_resources: false  # Report AWS-managed resources excluded by default (service-linked roles, default VPCs, ...)
+  account_spend: false  # Show identified waste as a percentage of each account's month-to-date spend from Cost Explorer
+  spend_summary_file: ""  # CSV of month-to-date spend per account (e.g. a CUR summary) used instead of Cost Explorer
+  delegated_admin: false  # List organization accounts with the credentials of a delegated administrator account (requires scanner_role)
+  accounts_file: ""  # Account list (local path or s3://bucket/key) used when organization accounts cannot be listed
+  dynamodb_table: ""  # DynamoDB table to write each finding to, keyed by pk (account ID) and sk (resource ID#scan time)
+  dynamodb_region: us-east-1  # Region of the DynamoDB table
+  max_api_calls: 0  # Maximum number of AWS API calls of the scan (0 for no limit)
+  reason_verbosity: normal  # Detail of the reasons of findings: summary, normal or debug
+  account_bucket: ""  # Template of the bucket each account's results are written to with output=s3-account
+  account_prefix: ""  # Template of the key prefix of each account's results with output=s3-account
+  grace_period_days: 0  # Never report resources created within this many days (0 to disable)
+  max_workers_per_account: 0  # Maximum number of scanner tasks run at the same time in an account (0 for no limit)
+  exclude_warm_instances: false  # Exclude Auto Scaling warm pool and hibernated instances from EC2 idle detection
+  provider: aws  # Cloud provider to scan (aws, gcp)
+  gcp_projects: ""  # Comma-separated GCP project IDs scanned with provider gcp
+  gcp_credentials_file: ""  # GCP credentials file (default: application default credentials)
+  compute_optimizer: false  # Import AWS Compute Optimizer over-provisioning findings into the results
+  cost_allocation_tags: []  # Tag keys the estimated savings are grouped by for chargeback, e.g. [CostCenter, Team]
+  plan_only: false  # Write the tasks the scan would run to plan_file without scanning
+  plan_file: "scan-plan.json"  # Path the scan plan of plan_only is written to
+  csv_per_account: false  # Write a CSV file per account with output_format csv
+  edge_zones: false  # Price EC2 instances and EBS volumes in Local Zones and Wavelength Zones by their zone
+  exclude_management_account: false  # Skip the management account of the organization
+  notify_slack_webhook: ""  # Slack incoming webhook URL the scan summary is posted to
+  history_dir: ""  # Directory recording the metrics and findings of each scan, listed and compared by cloudsift history
+  prometheus_file: ""  # File the findings, savings and scan metrics are written to in Prometheus text format (.prom)
+  prometheus_pushgateway_url: ""  # Prometheus Pushgateway URL the scan metrics are pushed to under job cloudsift
+  prometheus_listen_address: ""  # Address /metrics of the running scan is served on in Prometheus text format, e.g. :9101
+  compare_to: ""  # JSON results of a previous scan (file or directory) the HTML report compares the findings with
+  gcp_impersonate_service_account: ""  # GCP service account to impersonate with the credentials
+  exchange_rates:  # Static USD exchange rates used when exchange_rate and exchange_rate_url are not set
+    # EUR: 0.92
+    # GBP: 0.79
+  cost_overrides:  # Multipliers applied to list prices per resource type (EC2, EBSVolumes, ...), service (ec2, rds, ...) or default
+    # ec2: 0.72
+    # default: 0.80
+  marketplace_rates:  # Hourly Marketplace software charges keyed by product code, added to Marketplace instance costs
+    # abcdefghijklmnopqrstuvwxy: 0.25
+  reason_templates:  # Rewrite matching reasons in the HTML report, e.g. to link internal runbooks
+    # - resource_type: EBS Volumes  # Optional scanner label
+    #   match: "^Volume has not been used"  # Regular expression matched against each reason line
+    #   template: "{{ .Reason }} See https://wiki.example.com/ebs-cleanup"
+  managed_resources:  # Extend the built-in list of AWS-managed resources excluded from results (patterns support *)
+    # - resource_type: IAM Roles  # Optional scanner label
+    #   name: "aws-controltower-*"  # Pattern matched against the resource name
+    #   description: Roles managed by AWS Control Tower
+  output_overrides:  # Write matching accounts in their own format and to their own directory or bucket instead of the scan output
+    # - accounts: ["123456789012"]  # Account IDs
+    #   organizational_units: [ou-ab12-34cd56ef]  # Accounts in these organizational units, at any depth
+    #   format: html  # json, html, csv or markdown (defaults to output_format)
+    #   output: s3  # filesystem (with directory) or s3 (with bucket, bucket_region and prefix)
+    #   bucket: payments-finops
+  metrics_exporters:  # Push the findings, savings and run metrics of each scan to Datadog or New Relic
+    # - type: datadog  # datadog or newrelic
+    #   site: datadoghq.eu  # Datadog site (default datadoghq.com); New Relic uses region: us or eu
+    #   api_key_env: DD_API_KEY  # Environment variable holding the API key (default DD_API_KEY or NEW_RELIC_LICENSE_KEY)
+    #   tags: ["team:finops"]
 
   # Ignore list configuration
   # Resources matching any of these criteria will be excluded from scan results
@@ -83,6 +197,7 @@ scan:
 func NewConfigCmd() *cobra.Command {
 	var force bool
 	var output string
+	var detect bool
 
 	cmd := &cobra.Command{
 		Use:   "config",
@@ -90,7 +205,11 @@ func NewConfigCmd() *cobra.Command {
 		Long: `Create a default config.yaml file with recommended settings.
 
 The file will be created in the current directory by default.
-You can specify a different location using the --output flag.`,
+You can specify a different location using the --output flag.
+
+With --detect, the AWS credentials of the configured profile are used to detect whether
+the organization's accounts can be listed and which regions are enabled, and each scanner
+is probed for resources to write a recommended scanner list.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if output == "" {
 				output = "config.yaml"
@@ -113,8 +232,22 @@ You can specify a different location using the --output flag.`,
 				return fmt.Errorf("failed to create directory %s: %w", dir, err)
 			}
 
+			content := defaultConfigContent
+			if detect {
+				sess, err := awsinternal.NewSession(config.Config.Profile, "us-east-1")
+				if err != nil {
+					return fmt.Errorf("failed to create session: %w", err)
+				}
+				fmt.Println("Detecting AWS setup, this may take a minute...")
+				setup, err := detectSetup(sess)
+				if err != nil {
+					return fmt.Errorf("failed to detect AWS setup: %w", err)
+				}
+				content = renderDetectedConfig(setup)
+			}
+
 			// Write the file
-			if err := os.WriteFile(absPath, []byte(defaultConfigContent), 0644); err != nil {
+			if err := os.WriteFile(absPath, []byte(content), 0644); err != nil {
 				return fmt.Errorf("failed to write config file: %w", err)
 			}
 
@@ -125,6 +258,7 @@ You can specify a different location using the --output flag.`,
 
 	cmd.Flags().BoolVarP(&force, "force", "f", false, "Overwrite existing file")
 	cmd.Flags().StringVarP(&output, "output", "o", "", "Output file path (default: ./config.yaml)")
+	cmd.Flags().BoolVar(&detect, "detect", false, "Detect organization access, enabled regions and recommended scanners with the configured AWS credentials")
 
 	return cmd
 }
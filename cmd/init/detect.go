@@ -0,0 +1,192 @@
+package init
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	awsinternal "cloudsift/internal/aws"
+	_ "cloudsift/internal/aws/scanners" // Import for side effects (scanner registration)
+	"cloudsift/internal/config"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
+)
+
+// Blocks of the default config replaced with detected values
+const (
+	defaultRegionsBlock = `  regions:
+   # - us-west-2  # Example region
+   # - us-east-1  # Example region
+`
+	defaultEnabledScannersBlock = `  enabled_scanners:  # Scanners run when no scanners are selected (default: all available scanners)
+    # - ec2-instances
+    # - ebs-volumes
+`
+)
+
+// detectedSetup describes the AWS environment of the configured credentials
+type detectedSetup struct {
+	AccountID    string
+	Organization bool   // Whether the organization's accounts can be listed
+	AccountCount int    // Number of organization accounts, if listed
+	OrgError     string // Why the organization's accounts could not be listed
+	Regions      []string
+	Scanners     []scannerRecommendation
+}
+
+// scannerRecommendation records whether a scanner is recommended and why
+type scannerRecommendation struct {
+	Name        string
+	Recommended bool
+	Note        string
+}
+
+// detectSetup inspects the account, organization access and enabled regions of a session and
+// probes the scanners to recommend those with resources to scan
+func detectSetup(sess *session.Session) (*detectedSetup, error) {
+	identity, err := sts.New(sess, awsinternal.STSConfig(sess)).GetCallerIdentity(&sts.GetCallerIdentityInput{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get caller identity: %w", err)
+	}
+	setup := &detectedSetup{AccountID: aws.StringValue(identity.Account)}
+
+	orgSession := sess
+	if config.Config.OrganizationRole != "" {
+		orgSession, err = awsinternal.GetSessionChain(config.Config.OrganizationRole, "", "", "us-east-1")
+	}
+	if err == nil {
+		var accounts []awsinternal.Account
+		accounts, err = awsinternal.ListAccountsWithSession(orgSession)
+		setup.AccountCount = len(accounts)
+	}
+	setup.Organization = err == nil
+	if err != nil {
+		setup.OrgError = err.Error()
+	}
+
+	setup.Regions, err = awsinternal.GetAvailableRegions(sess)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(setup.Regions)
+
+	setup.Scanners = recommendScanners(sess, setup.Regions)
+	return setup, nil
+}
+
+// recommendScanners probes each scanner across the regions until resources are found.
+// Scanners that cannot be probed are recommended, since their resources are unknown.
+func recommendScanners(sess *session.Session, regions []string) []scannerRecommendation {
+	names := awsinternal.DefaultRegistry.ListScanners()
+	recommendations := make([]scannerRecommendation, len(names))
+
+	workers := config.Config.MaxWorkers
+	if workers <= 0 {
+		workers = 1
+	}
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			recommendations[i] = recommendScanner(sess, name, regions)
+		}(i, name)
+	}
+	wg.Wait()
+	return recommendations
+}
+
+// recommendScanner probes a single scanner across the regions
+func recommendScanner(sess *session.Session, name string, regions []string) scannerRecommendation {
+	recommendation := scannerRecommendation{Name: name, Recommended: true}
+	scanner, err := awsinternal.DefaultRegistry.GetScanner(name)
+	if err != nil {
+		return recommendation
+	}
+	prober, ok := scanner.(awsinternal.ResourceProber)
+	if !ok {
+		recommendation.Note = "resources not probed"
+		return recommendation
+	}
+
+	return probeRegions(recommendation, regions, func(region string) (bool, error) {
+		return prober.HasResources(awsinternal.ScanOptions{
+			Region:  region,
+			Session: sess,
+		})
+	})
+}
+
+// probeRegions recommends a scanner once it finds resources in a region. A region that
+// cannot be probed is recorded in the note and the other regions are still probed; the
+// scanner is not recommended unless one of them has resources.
+func probeRegions(recommendation scannerRecommendation, regions []string, hasResources func(region string) (bool, error)) scannerRecommendation {
+	var failed []string
+	var firstErr error
+	for _, region := range regions {
+		found, err := hasResources(region)
+		if err != nil {
+			failed = append(failed, region)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if found {
+			recommendation.Note = fmt.Sprintf("resources found in %s", region)
+			return recommendation
+		}
+	}
+	recommendation.Recommended = false
+	recommendation.Note = "no resources found"
+	if len(failed) > 0 {
+		recommendation.Note = fmt.Sprintf("no resources found, probe failed in %s: %s",
+			strings.Join(failed, ", "), strings.ReplaceAll(firstErr.Error(), "\n", " "))
+	}
+	return recommendation
+}
+
+// renderDetectedConfig fills the default config with the detected regions and recommended
+// scanners, and summarizes the detected setup in its header
+func renderDetectedConfig(setup *detectedSetup) string {
+	var header strings.Builder
+	header.WriteString("# CloudSift Configuration File\n#\n# Detected setup (cloudsift init config --detect):\n")
+	fmt.Fprintf(&header, "#   Account: %s\n", setup.AccountID)
+	if setup.Organization {
+		fmt.Fprintf(&header, "#   Organizations access: yes (%d accounts)\n", setup.AccountCount)
+	} else {
+		header.WriteString("#   Organizations access: no, only this account is scanned unless organization_role is set\n")
+	}
+	fmt.Fprintf(&header, "#   Enabled regions: %d\n", len(setup.Regions))
+
+	var regions strings.Builder
+	regions.WriteString("  regions:  # Regions enabled in the account\n")
+	for _, region := range setup.Regions {
+		fmt.Fprintf(&regions, "    - %s\n", region)
+	}
+
+	var scanners strings.Builder
+	scanners.WriteString("  enabled_scanners:  # Recommended scanners; scanners without resources are commented out\n")
+	for _, recommendation := range setup.Scanners {
+		prefix := "    - "
+		if !recommendation.Recommended {
+			prefix = "    # - "
+		}
+		scanners.WriteString(prefix + recommendation.Name)
+		if recommendation.Note != "" {
+			scanners.WriteString("  # " + recommendation.Note)
+		}
+		scanners.WriteString("\n")
+	}
+
+	content := strings.Replace(defaultConfigContent, "# CloudSift Configuration File\n", header.String(), 1)
+	content = strings.Replace(content, defaultRegionsBlock, regions.String(), 1)
+	content = strings.Replace(content, defaultEnabledScannersBlock, scanners.String(), 1)
+	return content
+}
@@ -0,0 +1,65 @@
+package init
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestRenderDetectedConfig(t *testing.T) {
+	content := renderDetectedConfig(&detectedSetup{
+		AccountID:    "123456789012",
+		Organization: true,
+		AccountCount: 42,
+		Regions:      []string{"eu-west-1", "us-east-1"},
+		Scanners: []scannerRecommendation{
+			{Name: "ebs-volumes", Recommended: true, Note: "resources found in eu-west-1"},
+			{Name: "rds", Note: "no resources found"},
+		},
+	})
+
+	assert.Contains(t, content, "#   Organizations access: yes (42 accounts)\n")
+	assert.Contains(t, content, "    - ebs-volumes  # resources found in eu-west-1\n")
+	assert.Contains(t, content, "    # - rds  # no resources found\n")
+	assert.False(t, strings.Contains(content, "Example region"))
+
+	var parsed struct {
+		Scan struct {
+			Regions         []string `yaml:"regions"`
+			EnabledScanners []string `yaml:"enabled_scanners"`
+		} `yaml:"scan"`
+	}
+	require.NoError(t, yaml.Unmarshal([]byte(content), &parsed))
+	assert.Equal(t, []string{"eu-west-1", "us-east-1"}, parsed.Scan.Regions)
+	assert.Equal(t, []string{"ebs-volumes"}, parsed.Scan.EnabledScanners)
+}
+
+func TestProbeRegions(t *testing.T) {
+	probe := func(found map[string]bool, failed map[string]bool) func(string) (bool, error) {
+		return func(region string) (bool, error) {
+			if failed[region] {
+				return false, errors.New("AccessDenied:\nnot authorized")
+			}
+			return found[region], nil
+		}
+	}
+	regions := []string{"eu-west-1", "us-east-1", "us-west-2"}
+
+	recommendation := probeRegions(scannerRecommendation{Name: "rds", Recommended: true}, regions,
+		probe(map[string]bool{"us-west-2": true}, map[string]bool{"eu-west-1": true}))
+	assert.True(t, recommendation.Recommended, "a failed region must not stop the other regions from being probed")
+	assert.Equal(t, "resources found in us-west-2", recommendation.Note)
+
+	recommendation = probeRegions(scannerRecommendation{Name: "rds", Recommended: true}, regions,
+		probe(nil, map[string]bool{"eu-west-1": true, "us-west-2": true}))
+	assert.False(t, recommendation.Recommended)
+	assert.Equal(t, "no resources found, probe failed in eu-west-1, us-west-2: AccessDenied: not authorized", recommendation.Note)
+
+	recommendation = probeRegions(scannerRecommendation{Name: "rds", Recommended: true}, regions, probe(nil, nil))
+	assert.False(t, recommendation.Recommended)
+	assert.Equal(t, "no resources found", recommendation.Note)
+}
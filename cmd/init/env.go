@@ -66,7 +66,7 @@ CLOUDSIFT_SCAN_ACCOUNTS=
 # Default: filesystem
 CLOUDSIFT_SCAN_OUTPUT=filesystem
 
-# Output format (json or html)
+# Output format (json, html, csv or markdown)
 # Default: html
 CLOUDSIFT_SCAN_OUTPUT_FORMAT=html
 
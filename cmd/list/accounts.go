@@ -2,8 +2,10 @@ package list
 
 import (
 	"fmt"
+	"strings"
 
 	"cloudsift/internal/aws"
+	"cloudsift/internal/config"
 	"github.com/spf13/cobra"
 )
 
@@ -31,10 +33,22 @@ If an organization role is provided, shows all accounts in the organization.`,
 
 func runAccounts(cmd *cobra.Command) error {
 	organizationRole, _ := cmd.Flags().GetString("organization-role")
-	accounts, err := aws.ListAccounts(organizationRole)
+	list := func() ([]aws.Account, error) {
+		return aws.ListAccounts(organizationRole)
+	}
+	var accounts []aws.Account
+	var err error
+	if organizationRole != "" {
+		// Only organization account lists are slow enough to be worth caching
+		accounts, err = aws.CachedAccounts(aws.AccountCacheFile, aws.AccountCacheKey(config.Config.Profile, organizationRole),
+			config.Config.AccountsCacheTTL, config.Config.RefreshAccounts, list)
+	} else {
+		accounts, err = list()
+	}
 	if err != nil {
 		return fmt.Errorf("failed to list accounts: %w", err)
 	}
+	accounts = aws.ApplyAccountMappings(accounts, config.Config.AccountMappings)
 
 	if len(accounts) == 0 {
 		fmt.Println("No accounts found")
@@ -43,8 +57,27 @@ func runAccounts(cmd *cobra.Command) error {
 
 	fmt.Println("Available accounts:")
 	for _, account := range accounts {
-		fmt.Printf("  %s - %s\n", account.ID, account.Name)
+		fmt.Printf("  %s - %s%s\n", account.ID, account.Name, accountLabels(account))
 	}
 
 	return nil
 }
+
+// accountLabels formats the team and environment of an account from the account mappings,
+// and the status of accounts that are not active
+func accountLabels(account aws.Account) string {
+	var labels []string
+	if !account.Active() {
+		labels = append(labels, "status: "+account.Status)
+	}
+	if account.Team != "" {
+		labels = append(labels, "team: "+account.Team)
+	}
+	if account.Environment != "" {
+		labels = append(labels, "environment: "+account.Environment)
+	}
+	if len(labels) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(labels, ", ") + ")"
+}
@@ -8,19 +8,22 @@ import (
 // NewListCmd creates the list command
 func NewListCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "list",
-		Short: "List various AWS resources",
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List various AWS resources",
 		Long: `List various AWS resources and configurations.
 Currently supports listing:
   - AWS accounts in an organization or current account
   - Available AWS credential profiles
-  - Available resource scanners`,
+  - Available resource scanners
+  - Reason codes of findings`,
 	}
 
 	// Add subcommands
 	cmd.AddCommand(NewAccountsCmd())
 	cmd.AddCommand(NewProfilesCmd())
 	cmd.AddCommand(NewScannersCmd())
+	cmd.AddCommand(NewReasonsCmd())
 
 	return cmd
 }
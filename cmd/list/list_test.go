@@ -2,11 +2,14 @@ package list
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -119,6 +122,7 @@ func TestNewListCmd(t *testing.T) {
 		"accounts",
 		"profiles",
 		"scanners",
+		"reasons",
 	}
 
 	assert.Len(t, subcommands, len(expectedSubcommands))
@@ -466,3 +470,71 @@ func TestIntegration(t *testing.T) {
 	// Restore original registry
 	awspkg.DefaultRegistry = originalRegistry
 }
+
+func TestAccountLabels(t *testing.T) {
+	assert.Equal(t, "", accountLabels(awspkg.Account{ID: "123456789012", Status: "ACTIVE"}))
+	assert.Equal(t, " (team: payments)", accountLabels(awspkg.Account{ID: "123456789012", Team: "payments"}))
+	assert.Equal(t, " (status: SUSPENDED, team: payments)", accountLabels(awspkg.Account{ID: "123456789012", Team: "payments", Status: "SUSPENDED"}))
+}
+
+func TestCachedAccounts(t *testing.T) {
+	cacheFile := filepath.Join(t.TempDir(), "cache", "accounts.json")
+	key := awspkg.AccountCacheKey("default", "OrganizationRole")
+	listed := 0
+	list := func() ([]awspkg.Account, error) {
+		listed++
+		return []awspkg.Account{{ID: "123456789012", Name: "prod", Status: "ACTIVE"}}, nil
+	}
+
+	// The first listing is cached and reused within the TTL
+	accounts, err := awspkg.CachedAccounts(cacheFile, key, time.Hour, false, list)
+	require.NoError(t, err)
+	assert.Equal(t, []awspkg.Account{{ID: "123456789012", Name: "prod", Status: "ACTIVE"}}, accounts)
+	accounts, err = awspkg.CachedAccounts(cacheFile, key, time.Hour, false, list)
+	require.NoError(t, err)
+	assert.Equal(t, []awspkg.Account{{ID: "123456789012", Name: "prod", Status: "ACTIVE"}}, accounts)
+	assert.Equal(t, 1, listed)
+
+	// Refreshing, another source or disabling the cache lists the accounts again
+	_, err = awspkg.CachedAccounts(cacheFile, key, time.Hour, true, list)
+	require.NoError(t, err)
+	_, err = awspkg.CachedAccounts(cacheFile, awspkg.AccountCacheKey("other", "OrganizationRole"), time.Hour, false, list)
+	require.NoError(t, err)
+	_, err = awspkg.CachedAccounts(cacheFile, key, 0, false, list)
+	require.NoError(t, err)
+	assert.Equal(t, 4, listed)
+
+	// Expired lists are listed again, and listing errors are not cached
+	_, err = awspkg.CachedAccounts(cacheFile, key, time.Nanosecond, false, list)
+	require.NoError(t, err)
+	assert.Equal(t, 5, listed)
+	_, err = awspkg.CachedAccounts(cacheFile, "failing/", time.Hour, false, func() ([]awspkg.Account, error) {
+		return nil, fmt.Errorf("access denied")
+	})
+	assert.Error(t, err)
+}
+
+// TestRunReasons tests the reasons command
+func TestRunReasons(t *testing.T) {
+	cmd := NewReasonsCmd()
+	require.NoError(t, cmd.Flags().Set("format", "json"))
+	output := captureOutput(func() {
+		require.NoError(t, cmd.RunE(cmd, nil))
+	})
+
+	var codes []map[string]string
+	require.NoError(t, json.Unmarshal([]byte(output), &codes))
+	require.Len(t, codes, len(awspkg.ReasonCodes))
+	seen := make(map[string]bool)
+	for _, code := range codes {
+		assert.NotEmpty(t, code["code"])
+		assert.Contains(t, []string{"cost", "security", "hygiene"}, code["category"])
+		assert.NotEmpty(t, code["description"])
+		assert.False(t, seen[code["code"]], "duplicate reason code %s", code["code"])
+		seen[code["code"]] = true
+	}
+
+	cmd = NewReasonsCmd()
+	require.NoError(t, cmd.Flags().Set("format", "csv"))
+	assert.Error(t, cmd.RunE(cmd, nil))
+}
@@ -0,0 +1,53 @@
+package list
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"cloudsift/internal/aws"
+	"github.com/spf13/cobra"
+)
+
+// NewReasonsCmd creates and returns the reasons command
+func NewReasonsCmd() *cobra.Command {
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "reasons",
+		Short: "List the reason codes of findings",
+		Long: `List the taxonomy of reason codes. Each finding carries the codes of its reasons in its
+reason_codes detail, so automation such as ticketing can map findings to runbooks without
+parsing the reason text. Codes are grouped into the cost, security and hygiene categories.`,
+		Example: `  # List reason codes
+  cloudsift list reasons
+
+  # Export reason codes as JSON
+  cloudsift list reasons --format json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switch format {
+			case "json":
+				data, err := json.MarshalIndent(aws.ReasonCodes, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to marshal reason codes: %w", err)
+				}
+				fmt.Println(string(data))
+			case "table":
+				w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "CODE\tCATEGORY\tDESCRIPTION")
+				for _, code := range aws.ReasonCodes {
+					fmt.Fprintf(w, "%s\t%s\t%s\n", code.Code, code.Category, code.Description)
+				}
+				w.Flush()
+			default:
+				return fmt.Errorf("invalid format %q, must be one of: table, json", format)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", "table", "Output format (table, json)")
+
+	return cmd
+}
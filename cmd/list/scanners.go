@@ -4,19 +4,41 @@ import (
 	"fmt"
 
 	"cloudsift/internal/aws"
+	"cloudsift/internal/gcp"
+	_ "cloudsift/internal/gcp/scanners" // Import for side effects (scanner registration)
 	"github.com/spf13/cobra"
 )
 
 // NewScannersCmd creates and returns the scanners command
 func NewScannersCmd() *cobra.Command {
+	var provider string
+
 	cmd := &cobra.Command{
 		Use:   "scanners",
 		Short: "List available resource scanners",
 		Long: `List all available resource scanners that can be used to scan AWS resources.
 Each scanner is specialized for a specific type of resource.`,
 		Example: `  # List all available resource scanners
-  cloudsift list scanners`,
+  cloudsift list scanners
+
+  # List the scanners of GCP projects
+  cloudsift list scanners --provider gcp`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if provider == "gcp" {
+				fmt.Println("Available scanners:")
+				for _, name := range gcp.DefaultRegistry.ListScanners() {
+					scanner, err := gcp.DefaultRegistry.GetScanner(name)
+					if err != nil {
+						continue
+					}
+					fmt.Printf("  - %s - %s\n", scanner.ArgumentName(), scanner.Label())
+				}
+				return nil
+			}
+			if provider != "aws" {
+				return fmt.Errorf("invalid provider: %s", provider)
+			}
+
 			scannerList := aws.DefaultRegistry.ListScanners()
 			if len(scannerList) == 0 {
 				fmt.Println("No scanners registered")
@@ -35,5 +57,10 @@ Each scanner is specialized for a specific type of resource.`,
 		},
 	}
 
+	cmd.Flags().StringVar(&provider, "provider", "aws", "Cloud provider whose scanners are listed (aws, gcp)")
+	_ = cmd.RegisterFlagCompletionFunc("provider", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"aws", "gcp"}, cobra.ShellCompDirectiveNoFileComp
+	})
+
 	return cmd
 }
@@ -0,0 +1,73 @@
+package login
+
+import (
+	"fmt"
+
+	"cloudsift/internal/aws"
+	"cloudsift/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+// NewLoginCmd creates and returns the login command
+func NewLoginCmd() *cobra.Command {
+	var startURL, region string
+
+	cmd := &cobra.Command{
+		Use:   "login",
+		Short: "Sign in with AWS IAM Identity Center (SSO)",
+		Long: `Sign in with AWS IAM Identity Center (SSO) using the device authorization flow.
+The access token is cached in ~/.aws/sso/cache, shared with the AWS CLI, and used by
+'cloudsift scan --sso-start-url ... --sso-role-name ...' to scan every account the
+permission set is assigned to without pre-provisioned scanner roles.`,
+		Example: `  # Sign in and list the accounts available to you
+  cloudsift login --sso-start-url https://my-org.awsapps.com/start --sso-region eu-west-1
+
+  # Scan all accounts using a permission set
+  cloudsift scan --sso-start-url https://my-org.awsapps.com/start --sso-region eu-west-1 --sso-role-name ReadOnlyAccess`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runLogin(startURL, region)
+		},
+	}
+
+	cmd.Flags().StringVar(&startURL, "sso-start-url", "", "IAM Identity Center start URL (e.g. https://my-org.awsapps.com/start)")
+	cmd.Flags().StringVar(&region, "sso-region", "us-east-1", "Region of the IAM Identity Center instance")
+	_ = cmd.MarkFlagRequired("sso-start-url")
+
+	return cmd
+}
+
+func runLogin(startURL, region string) error {
+	if token, err := aws.LoadSSOToken(startURL); err == nil {
+		fmt.Printf("Already signed in to %s (token expires %s)\n", startURL, token.ExpiresAt)
+	} else {
+		_, err := aws.SSOLogin(startURL, region, func(auth aws.SSODeviceAuthorization) {
+			fmt.Println("To sign in, open the following URL in a browser and confirm the code:")
+			fmt.Printf("  %s\n", auth.VerificationURIComplete)
+			fmt.Printf("  Code: %s\n", auth.UserCode)
+			fmt.Println("Waiting for authorization...")
+		})
+		if err != nil {
+			return fmt.Errorf("failed to sign in: %w", err)
+		}
+		fmt.Printf("Signed in to %s\n", startURL)
+	}
+
+	accounts, err := aws.ListSSOAccounts(startURL, region)
+	if err != nil {
+		return fmt.Errorf("failed to list accounts: %w", err)
+	}
+	accounts = aws.ApplyAccountMappings(accounts, config.Config.AccountMappings)
+
+	if len(accounts) == 0 {
+		fmt.Println("No accounts found")
+		return nil
+	}
+
+	fmt.Println("Available accounts:")
+	for _, account := range accounts {
+		fmt.Printf("  %s - %s\n", account.ID, account.Name)
+	}
+
+	return nil
+}
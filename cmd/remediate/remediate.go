@@ -0,0 +1,385 @@
+package remediate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	awsinternal "cloudsift/internal/aws"
+	"cloudsift/internal/config"
+	"cloudsift/internal/output"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/spf13/cobra"
+)
+
+// Statuses of audit log entries
+const (
+	statusDryRun  = "dry_run"
+	statusDeleted = "deleted"
+	statusFailed  = "failed"
+	statusSkipped = "skipped"
+)
+
+type remediateOptions struct {
+	resultsFiles []string
+	dryRun       bool
+	auditLog     string
+	maxAge       time.Duration    // Age above which scan results are refused
+	targets      map[string]*bool // Opt-in of each remediation target by flag name
+}
+
+// deletion is the planned deletion of the resource of a scan result
+type deletion struct {
+	target      awsinternal.RemediationTarget
+	result      awsinternal.ScanResult
+	accountName string
+	region      string
+	skip        string // Why the resource is not deleted, if it is not
+}
+
+// auditEntry is a line of the audit log, recording one action on a resource
+type auditEntry struct {
+	Time         time.Time `json:"time"`
+	DryRun       bool      `json:"dry_run"`
+	AccountID    string    `json:"account_id"`
+	AccountName  string    `json:"account_name,omitempty"`
+	Region       string    `json:"region,omitempty"`
+	ResourceType string    `json:"resource_type"`
+	ResourceID   string    `json:"resource_id"`
+	ResourceName string    `json:"resource_name,omitempty"`
+	Action       string    `json:"action"`
+	Status       string    `json:"status"`
+	Note         string    `json:"note,omitempty"`
+	Error        string    `json:"error,omitempty"`
+}
+
+// clientFactory returns the clients deleting resources of an account in a region
+type clientFactory func(accountID, region string) (awsinternal.RemediationClients, error)
+
+// NewRemediateCmd creates and returns the remediate command
+func NewRemediateCmd() *cobra.Command {
+	opts := &remediateOptions{targets: make(map[string]*bool)}
+
+	cmd := &cobra.Command{
+		Use:   "remediate",
+		Short: "Delete unused resources found by a previous scan",
+		Long: `Delete the unused resources of JSON scan results written by scan --output-format json.
+Only resource types opted in to with their flag are deleted. Runs are dry runs that only list
+the deletions unless --dry-run=false is passed. Every deletion, dry run or failure is appended
+to the audit log. Sessions are created like for scans, so the scanner role (or the profile)
+must be allowed to delete the resources.
+
+Only resources flagged for a cost reason are deleted: results that are low confidence because
+CloudWatch metrics were unavailable, results flagged only for security or hygiene reasons and
+results older than --max-age are skipped. Before each deletion, the live state of volumes,
+Elastic IPs and load balancers is checked again, skipping those used since the scan. Elastic
+IPs are only released if still unassociated or associated with an instance that is stopped.`,
+		Example: `  # List the volumes and Elastic IPs a scan found that would be deleted
+  cloudsift remediate --results output/2024/03/01/123456789012/06-00-00+0000.json.gz --ebs-volumes --elastic-ips
+
+  # Delete them
+  cloudsift remediate --results output/2024/03/01/123456789012/06-00-00+0000.json.gz --ebs-volumes --elastic-ips --dry-run=false`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(opts.resultsFiles) == 0 {
+				return fmt.Errorf("--results is required")
+			}
+			if !anyTargetEnabled(opts) {
+				return fmt.Errorf("no resource types selected: pass one or more of %s", strings.Join(targetFlags(), ", "))
+			}
+
+			var accounts []output.AccountResults
+			for _, file := range opts.resultsFiles {
+				loaded, err := loadResults(file)
+				if err != nil {
+					return err
+				}
+				accounts = append(accounts, loaded...)
+			}
+
+			audit, err := openAuditLog(opts.auditLog)
+			if err != nil {
+				return err
+			}
+			defer audit.Close()
+
+			return remediate(os.Stdout, audit, opts, accounts, sessionClients())
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&opts.resultsFiles, "results", nil, "JSON scan result files written by scan --output-format json (.json or .json.gz, comma-separated or repeated)")
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", true, "Only list and audit the deletions; pass --dry-run=false to delete the resources")
+	cmd.Flags().DurationVar(&opts.maxAge, "max-age", 7*24*time.Hour, "Skip the resources of scan results older than this, as they may be used again")
+	cmd.Flags().StringVar(&opts.auditLog, "audit-log", filepath.Join("output", "remediation-audit.jsonl"), "File every action is appended to as a JSON line")
+	for _, target := range awsinternal.RemediationTargets {
+		opts.targets[target.Name] = new(bool)
+		cmd.Flags().BoolVar(opts.targets[target.Name], target.Name, false, "Delete "+target.Description)
+	}
+
+	return cmd
+}
+
+// targetFlags returns the flags opting in to the remediation targets
+func targetFlags() []string {
+	var flags []string
+	for _, target := range awsinternal.RemediationTargets {
+		flags = append(flags, "--"+target.Name)
+	}
+	return flags
+}
+
+// anyTargetEnabled returns true if at least one remediation target is opted in to
+func anyTargetEnabled(opts *remediateOptions) bool {
+	for _, enabled := range opts.targets {
+		if *enabled {
+			return true
+		}
+	}
+	return false
+}
+
+// loadResults reads the scan results of a file. Results of scans that did not record when
+// they were scanned are dated by the modification time of the file.
+func loadResults(path string) ([]output.AccountResults, error) {
+	accounts, err := output.ReadResults(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat scan results: %w", err)
+	}
+	for i := range accounts {
+		if accounts[i].ScannedAt.IsZero() {
+			accounts[i].ScannedAt = info.ModTime()
+		}
+	}
+	return accounts, nil
+}
+
+// openAuditLog opens the audit log for appending, creating it if needed
+func openAuditLog(path string) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	return f, nil
+}
+
+// planDeletions returns the deletions of the opted-in resource types, sorted by account,
+// region, resource type and ID. Deletions of resources that must not be deleted based on the
+// scan results alone are planned as skipped.
+func planDeletions(opts *remediateOptions, accounts []output.AccountResults, now time.Time) []deletion {
+	var deletions []deletion
+	for _, account := range accounts {
+		var stale string
+		if age := now.Sub(account.ScannedAt); opts.maxAge > 0 && age > opts.maxAge {
+			stale = fmt.Sprintf("scan results are %s old, older than --max-age %s", age.Round(time.Minute), opts.maxAge)
+		}
+		for _, results := range account.Results {
+			for _, result := range results {
+				target, ok := awsinternal.RemediationTargetForType(result.ResourceType)
+				if !ok || !*opts.targets[target.Name] {
+					continue
+				}
+				if result.AccountID == "" {
+					result.AccountID = account.AccountID
+				}
+				region, _ := result.Details["region"].(string)
+				skip := stale
+				if skip == "" {
+					skip = unsafeReason(result)
+				}
+				deletions = append(deletions, deletion{
+					target:      target,
+					result:      result,
+					accountName: account.AccountName,
+					region:      region,
+					skip:        skip,
+				})
+			}
+		}
+	}
+	sort.Slice(deletions, func(i, j int) bool {
+		a, b := deletions[i], deletions[j]
+		if a.result.AccountID != b.result.AccountID {
+			return a.result.AccountID < b.result.AccountID
+		}
+		if a.region != b.region {
+			return a.region < b.region
+		}
+		if a.result.ResourceType != b.result.ResourceType {
+			return a.result.ResourceType < b.result.ResourceType
+		}
+		return a.result.ResourceID < b.result.ResourceID
+	})
+	return deletions
+}
+
+// unsafeReason returns why the resource of a scan result must not be deleted, or an empty
+// string if its result shows it unused at a cost. Resources whose metrics could not be
+// checked are reported as unused with low confidence, and those flagged for security or
+// hygiene reasons are not necessarily unused.
+func unsafeReason(result awsinternal.ScanResult) string {
+	if status, _ := result.Details["metrics_status"].(string); status != "" {
+		return "usage could not be verified (metrics " + status + ")"
+	}
+	if confidence, _ := result.Details["confidence"].(string); confidence != "" {
+		return "scan result has " + confidence + " confidence"
+	}
+	codes := awsinternal.ReasonCodesOf(result)
+	for _, code := range codes {
+		if awsinternal.ReasonCodeCategory(code) == awsinternal.ReasonCategoryCost {
+			return ""
+		}
+	}
+	if len(codes) == 0 {
+		return "scan result has no cost reason"
+	}
+	return "scan result has no cost reason (" + strings.Join(codes, ", ") + ")"
+}
+
+// remediate performs or, in dry runs, lists the planned deletions, writing each to the audit
+// log. It returns an error if any deletion failed.
+func remediate(w io.Writer, audit io.Writer, opts *remediateOptions, accounts []output.AccountResults, newClients clientFactory) error {
+	deletions := planDeletions(opts, accounts, time.Now())
+	if len(deletions) == 0 {
+		fmt.Fprintln(w, "No resources of the selected types found in the scan results")
+		return nil
+	}
+
+	clients := make(map[string]awsinternal.RemediationClients)
+	clientErrors := make(map[string]error)
+	encoder := json.NewEncoder(audit)
+	counts := make(map[string]int)
+	for _, d := range deletions {
+		entry := auditEntry{
+			Time:         time.Now().UTC(),
+			DryRun:       opts.dryRun,
+			AccountID:    d.result.AccountID,
+			AccountName:  d.accountName,
+			Region:       d.region,
+			ResourceType: d.result.ResourceType,
+			ResourceID:   d.result.ResourceID,
+			ResourceName: d.result.ResourceName,
+			Action:       d.target.Action(d.result),
+		}
+
+		switch {
+		case d.skip != "":
+			entry.Status = statusSkipped
+			entry.Error = d.skip
+		case d.region == "" || awsinternal.IsGlobalRegionLabel(d.region):
+			entry.Status = statusSkipped
+			entry.Error = "scan result has no region"
+		case opts.dryRun:
+			entry.Status = statusDryRun
+		default:
+			key := d.result.AccountID + "/" + d.region
+			if _, ok := clients[key]; !ok && clientErrors[key] == nil {
+				c, err := newClients(d.result.AccountID, d.region)
+				if err != nil {
+					clientErrors[key] = err
+				} else {
+					clients[key] = c
+				}
+			}
+			if err := clientErrors[key]; err != nil {
+				entry.Status = statusFailed
+				entry.Error = err.Error()
+				break
+			}
+			var err error
+			if d.target.Check != nil {
+				err = d.target.Check(clients[key], d.result)
+			}
+			if err == nil {
+				entry.Note, err = d.target.Delete(clients[key], d.result)
+			}
+			switch {
+			case errors.Is(err, awsinternal.ErrResourceInUse):
+				entry.Status = statusSkipped
+				entry.Error = err.Error()
+			case err != nil:
+				entry.Status = statusFailed
+				entry.Error = err.Error()
+			default:
+				entry.Status = statusDeleted
+			}
+		}
+
+		if err := encoder.Encode(entry); err != nil {
+			return fmt.Errorf("failed to write audit log: %w", err)
+		}
+		counts[entry.Status]++
+		printEntry(w, entry)
+	}
+
+	fmt.Fprintf(w, "\n%d deleted, %d failed, %d skipped", counts[statusDeleted], counts[statusFailed], counts[statusSkipped])
+	if opts.dryRun {
+		fmt.Fprintf(w, ", %d would be deleted (dry run; pass --dry-run=false to delete them)", counts[statusDryRun])
+	}
+	fmt.Fprintln(w)
+
+	if counts[statusFailed] > 0 {
+		return fmt.Errorf("%d deletions failed, see the audit log", counts[statusFailed])
+	}
+	return nil
+}
+
+// printEntry prints an audited action
+func printEntry(w io.Writer, entry auditEntry) {
+	resource := entry.ResourceID
+	if entry.ResourceName != "" && entry.ResourceName != entry.ResourceID {
+		resource = fmt.Sprintf("%s (%s)", entry.ResourceID, entry.ResourceName)
+	}
+	location := entry.AccountID
+	if entry.Region != "" {
+		location += " " + entry.Region
+	}
+
+	switch entry.Status {
+	case statusDryRun:
+		fmt.Fprintf(w, "Would delete %s %s in %s [%s]\n", entry.ResourceType, resource, location, entry.Action)
+	case statusDeleted:
+		line := fmt.Sprintf("Deleted %s %s in %s", entry.ResourceType, resource, location)
+		if entry.Note != "" {
+			line += " (" + entry.Note + ")"
+		}
+		fmt.Fprintln(w, line)
+	case statusFailed:
+		fmt.Fprintf(w, "Failed to delete %s %s in %s: %s\n", entry.ResourceType, resource, location, entry.Error)
+	case statusSkipped:
+		fmt.Fprintf(w, "Skipped %s %s in %s: %s\n", entry.ResourceType, resource, location, entry.Error)
+	}
+}
+
+// sessionClients creates clients from sessions chained like for scans, refusing credentials
+// of another account than the one the resources are in
+func sessionClients() clientFactory {
+	return func(accountID, region string) (awsinternal.RemediationClients, error) {
+		sess, err := awsinternal.GetSessionChain(config.Config.OrganizationRole, config.Config.ScannerRole, accountID, region)
+		if err != nil {
+			return awsinternal.RemediationClients{}, fmt.Errorf("failed to create session: %w", err)
+		}
+		identity, err := sts.New(sess, awsinternal.STSConfig(sess)).GetCallerIdentity(&sts.GetCallerIdentityInput{})
+		if err != nil {
+			return awsinternal.RemediationClients{}, fmt.Errorf("failed to get caller identity: %w", err)
+		}
+		if aws.StringValue(identity.Account) != accountID {
+			return awsinternal.RemediationClients{}, fmt.Errorf("credentials are for account %s, not %s; set --scanner-role to assume a role in the account", aws.StringValue(identity.Account), accountID)
+		}
+		return awsinternal.NewRemediationClients(sess), nil
+	}
+}
@@ -0,0 +1,348 @@
+package remediate
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	awsinternal "cloudsift/internal/aws"
+	"cloudsift/internal/output"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/cloudwatch/cloudwatchiface"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/aws/aws-sdk-go/service/elb"
+	"github.com/aws/aws-sdk-go/service/elb/elbiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeEC2 records the EC2 deletions it is asked for
+type fakeEC2 struct {
+	ec2iface.EC2API
+	calls        []string
+	volumeStates map[string]string       // State of volumes by ID, available if unset
+	addresses    map[string]*ec2.Address // Addresses by allocation ID, unassociated if unset
+	instances    map[string]string       // State of instances by ID
+}
+
+func (f *fakeEC2) DescribeAddresses(input *ec2.DescribeAddressesInput) (*ec2.DescribeAddressesOutput, error) {
+	id := aws.StringValue(input.AllocationIds[0])
+	address, ok := f.addresses[id]
+	if !ok {
+		address = &ec2.Address{AllocationId: aws.String(id)}
+	}
+	return &ec2.DescribeAddressesOutput{Addresses: []*ec2.Address{address}}, nil
+}
+
+func (f *fakeEC2) DescribeInstances(input *ec2.DescribeInstancesInput) (*ec2.DescribeInstancesOutput, error) {
+	id := aws.StringValue(input.InstanceIds[0])
+	return &ec2.DescribeInstancesOutput{Reservations: []*ec2.Reservation{{Instances: []*ec2.Instance{{
+		InstanceId: aws.String(id),
+		State:      &ec2.InstanceState{Name: aws.String(f.instances[id])},
+	}}}}}, nil
+}
+
+func (f *fakeEC2) DisassociateAddress(input *ec2.DisassociateAddressInput) (*ec2.DisassociateAddressOutput, error) {
+	f.calls = append(f.calls, "DisassociateAddress "+aws.StringValue(input.AssociationId))
+	return &ec2.DisassociateAddressOutput{}, nil
+}
+
+func (f *fakeEC2) DescribeVolumes(input *ec2.DescribeVolumesInput) (*ec2.DescribeVolumesOutput, error) {
+	id := aws.StringValue(input.VolumeIds[0])
+	state := ec2.VolumeStateAvailable
+	if s, ok := f.volumeStates[id]; ok {
+		state = s
+	}
+	return &ec2.DescribeVolumesOutput{Volumes: []*ec2.Volume{{VolumeId: aws.String(id), State: aws.String(state)}}}, nil
+}
+
+func (f *fakeEC2) CreateSnapshot(input *ec2.CreateSnapshotInput) (*ec2.Snapshot, error) {
+	f.calls = append(f.calls, "CreateSnapshot "+aws.StringValue(input.VolumeId))
+	return &ec2.Snapshot{SnapshotId: aws.String("snap-new")}, nil
+}
+
+func (f *fakeEC2) DeleteVolume(input *ec2.DeleteVolumeInput) (*ec2.DeleteVolumeOutput, error) {
+	f.calls = append(f.calls, "DeleteVolume "+aws.StringValue(input.VolumeId))
+	return &ec2.DeleteVolumeOutput{}, nil
+}
+
+func (f *fakeEC2) ReleaseAddress(input *ec2.ReleaseAddressInput) (*ec2.ReleaseAddressOutput, error) {
+	f.calls = append(f.calls, "ReleaseAddress "+aws.StringValue(input.AllocationId))
+	return nil, fmt.Errorf("InvalidAllocationID.NotFound")
+}
+
+// fakeELB records the classic load balancer deletions it is asked for
+type fakeELB struct {
+	elbiface.ELBAPI
+	calls []string
+}
+
+func (f *fakeELB) DeleteLoadBalancer(input *elb.DeleteLoadBalancerInput) (*elb.DeleteLoadBalancerOutput, error) {
+	f.calls = append(f.calls, "DeleteLoadBalancer "+aws.StringValue(input.LoadBalancerName))
+	return &elb.DeleteLoadBalancerOutput{}, nil
+}
+
+// fakeCloudWatch returns the same sum for every metric
+type fakeCloudWatch struct {
+	cloudwatchiface.CloudWatchAPI
+	sum float64
+}
+
+func (f *fakeCloudWatch) GetMetricStatistics(input *cloudwatch.GetMetricStatisticsInput) (*cloudwatch.GetMetricStatisticsOutput, error) {
+	return &cloudwatch.GetMetricStatisticsOutput{Datapoints: []*cloudwatch.Datapoint{{Sum: aws.Float64(f.sum)}}}, nil
+}
+
+// testResults returns the scan results of an account with a resource of each kind
+func testResults() []output.AccountResults {
+	return []output.AccountResults{{
+		AccountID:   "123456789012",
+		AccountName: "prod",
+		ScannedAt:   time.Now().Add(-time.Hour),
+		Results: map[string]awsinternal.ScanResults{
+			"EBS Volumes": {{
+				ResourceType: "EBS Volumes",
+				ResourceID:   "vol-1",
+				Reason:       "Volume has not been used for 30 days",
+				Details:      map[string]interface{}{"region": "us-east-1"},
+			}},
+			"Elastic IPs": {{
+				ResourceType: "Elastic IPs",
+				ResourceID:   "eipalloc-1",
+				Reason:       "Not associated with any resource",
+				Details:      map[string]interface{}{"region": "eu-west-1"},
+			}},
+			"Load Balancers": {{
+				ResourceType: "Load Balancers",
+				ResourceID:   "legacy-lb",
+				Reason:       "No traffic recorded during the threshold period of 30 days",
+				Details:      map[string]interface{}{"region": "us-east-1", "type": "classic"},
+			}},
+			"EBS Snapshots": {{
+				ResourceType: "EBS Snapshots",
+				ResourceID:   "snap-1",
+				Reason:       "Snapshot is 400 days old.",
+				Details:      map[string]interface{}{},
+			}},
+			"IAM Roles": {{
+				ResourceType: "IAM Roles",
+				ResourceID:   "unused-role",
+			}},
+		},
+	}}
+}
+
+// testOptions returns remediate options with the given targets opted in to
+func testOptions(dryRun bool, targets ...string) *remediateOptions {
+	opts := &remediateOptions{dryRun: dryRun, maxAge: 7 * 24 * time.Hour, targets: make(map[string]*bool)}
+	for _, target := range awsinternal.RemediationTargets {
+		opts.targets[target.Name] = new(bool)
+	}
+	for _, target := range targets {
+		*opts.targets[target] = true
+	}
+	return opts
+}
+
+// auditEntries parses the lines of an audit log
+func auditEntries(t *testing.T, audit *bytes.Buffer) []auditEntry {
+	var entries []auditEntry
+	for _, line := range strings.Split(strings.TrimSpace(audit.String()), "\n") {
+		var entry auditEntry
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestRemediateDryRun(t *testing.T) {
+	var out, audit bytes.Buffer
+	opts := testOptions(true, "ebs-volumes", "elastic-ips", "ebs-snapshots")
+	err := remediate(&out, &audit, opts, testResults(), func(accountID, region string) (awsinternal.RemediationClients, error) {
+		t.Fatal("dry runs must not create clients")
+		return awsinternal.RemediationClients{}, nil
+	})
+	require.NoError(t, err)
+
+	entries := auditEntries(t, &audit)
+	require.Len(t, entries, 3)
+	assert.Equal(t, "snap-1", entries[0].ResourceID)
+	assert.Equal(t, statusSkipped, entries[0].Status)
+	assert.Equal(t, "eipalloc-1", entries[1].ResourceID)
+	assert.Equal(t, "vol-1", entries[2].ResourceID)
+	assert.Equal(t, statusDryRun, entries[2].Status)
+	assert.Equal(t, "ec2:DeleteVolume", entries[2].Action)
+	assert.True(t, entries[2].DryRun)
+	assert.Equal(t, "prod", entries[2].AccountName)
+	assert.Contains(t, out.String(), "Would delete EBS Volumes vol-1 in 123456789012 us-east-1 [ec2:DeleteVolume]")
+	assert.Contains(t, out.String(), "0 deleted, 0 failed, 1 skipped, 2 would be deleted")
+}
+
+func TestRemediate(t *testing.T) {
+	fakeEC2 := &fakeEC2{}
+	fakeELB := &fakeELB{}
+	var regions []string
+
+	var out, audit bytes.Buffer
+	opts := testOptions(false, "ebs-volumes", "elastic-ips", "load-balancers")
+	err := remediate(&out, &audit, opts, testResults(), func(accountID, region string) (awsinternal.RemediationClients, error) {
+		assert.Equal(t, "123456789012", accountID)
+		regions = append(regions, region)
+		return awsinternal.RemediationClients{EC2: fakeEC2, ELB: fakeELB, CloudWatch: &fakeCloudWatch{}}, nil
+	})
+	assert.EqualError(t, err, "1 deletions failed, see the audit log")
+
+	// Clients are created once per account and region
+	assert.Equal(t, []string{"eu-west-1", "us-east-1"}, regions)
+	assert.Equal(t, []string{"ReleaseAddress eipalloc-1", "CreateSnapshot vol-1", "DeleteVolume vol-1"}, fakeEC2.calls)
+	assert.Equal(t, []string{"DeleteLoadBalancer legacy-lb"}, fakeELB.calls)
+
+	entries := auditEntries(t, &audit)
+	require.Len(t, entries, 3)
+	assert.Equal(t, statusFailed, entries[0].Status)
+	assert.Equal(t, "InvalidAllocationID.NotFound", entries[0].Error)
+	assert.Equal(t, statusDeleted, entries[1].Status)
+	assert.Equal(t, "snapshot snap-new", entries[1].Note)
+	assert.False(t, entries[1].DryRun)
+	assert.Equal(t, statusDeleted, entries[2].Status)
+	assert.Equal(t, "elasticloadbalancing:DeleteLoadBalancer", entries[2].Action)
+	assert.Contains(t, out.String(), "Deleted EBS Volumes vol-1 in 123456789012 us-east-1 (snapshot snap-new)")
+	assert.Contains(t, out.String(), "2 deleted, 1 failed, 0 skipped")
+}
+
+func TestRemediateSkipsUnsafeResults(t *testing.T) {
+	accounts := testResults()
+	results := accounts[0].Results
+	results["EBS Volumes"] = append(results["EBS Volumes"], awsinternal.ScanResult{
+		ResourceType: "EBS Volumes",
+		ResourceID:   "vol-attached",
+		Reason:       "Volume has been idle for 30 days",
+		Details:      map[string]interface{}{"region": "us-east-1"},
+	})
+	results["Load Balancers"][0].Details["metrics_status"] = "unavailable"
+	results["Load Balancers"][0].Details["confidence"] = "low"
+	// Read back from JSON, reason codes are a list of values
+	results["EBS Snapshots"][0].Details = map[string]interface{}{"region": "us-east-1", "reason_codes": []interface{}{"snapshot_public"}}
+	accounts = append(accounts, output.AccountResults{
+		AccountID: "210987654321",
+		ScannedAt: time.Now().Add(-30 * 24 * time.Hour),
+		Results: map[string]awsinternal.ScanResults{
+			"EBS Volumes": {{ResourceType: "EBS Volumes", ResourceID: "vol-old", Reason: "Volume has not been used for 30 days", Details: map[string]interface{}{"region": "us-east-1"}}},
+		},
+	})
+
+	fakeEC2 := &fakeEC2{volumeStates: map[string]string{"vol-attached": ec2.VolumeStateInUse}}
+	var out, audit bytes.Buffer
+	opts := testOptions(false, "ebs-volumes", "ebs-snapshots", "load-balancers")
+	err := remediate(&out, &audit, opts, accounts, func(accountID, region string) (awsinternal.RemediationClients, error) {
+		return awsinternal.RemediationClients{EC2: fakeEC2, ELB: &fakeELB{}, CloudWatch: &fakeCloudWatch{}}, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"CreateSnapshot vol-1", "DeleteVolume vol-1"}, fakeEC2.calls)
+
+	skipped := make(map[string]string)
+	for _, entry := range auditEntries(t, &audit) {
+		if entry.Status == statusSkipped {
+			skipped[entry.ResourceID] = entry.Error
+		}
+	}
+	assert.Equal(t, map[string]string{
+		"snap-1":       "scan result has no cost reason (snapshot_public)",
+		"legacy-lb":    "usage could not be verified (metrics unavailable)",
+		"vol-attached": "resource is in use again: volume is in-use",
+		"vol-old":      "scan results are 720h0m0s old, older than --max-age 168h0m0s",
+	}, skipped)
+}
+
+func TestCheckLoadBalancerIdle(t *testing.T) {
+	result := testResults()[0].Results["Load Balancers"][0]
+	target, ok := awsinternal.RemediationTargetForType("Load Balancers")
+	require.True(t, ok)
+
+	assert.NoError(t, target.Check(awsinternal.RemediationClients{CloudWatch: &fakeCloudWatch{}}, result))
+	err := target.Check(awsinternal.RemediationClients{CloudWatch: &fakeCloudWatch{sum: 12}}, result)
+	assert.ErrorIs(t, err, awsinternal.ErrResourceInUse)
+	assert.EqualError(t, err, "resource is in use again: RequestCount was 12 in the last 24 hours")
+}
+
+func TestReleaseAddressChecksInstance(t *testing.T) {
+	target, ok := awsinternal.RemediationTargetForType("Elastic IPs")
+	require.True(t, ok)
+	address := func(id, instanceID string) *ec2.Address {
+		return &ec2.Address{AllocationId: aws.String(id), AssociationId: aws.String("eipassoc-" + id), InstanceId: aws.String(instanceID)}
+	}
+	fakeEC2 := &fakeEC2{
+		addresses: map[string]*ec2.Address{
+			"eipalloc-stopped": address("eipalloc-stopped", "i-stopped"),
+			"eipalloc-started": address("eipalloc-started", "i-started"),
+			"eipalloc-eni":     {AllocationId: aws.String("eipalloc-eni"), AssociationId: aws.String("eipassoc-eni"), NetworkInterfaceId: aws.String("eni-1")},
+		},
+		instances: map[string]string{"i-stopped": ec2.InstanceStateNameStopped, "i-started": ec2.InstanceStateNameRunning},
+	}
+	clients := awsinternal.RemediationClients{EC2: fakeEC2}
+
+	// The address was associated with a stopped instance when scanned, which was started since
+	_, err := target.Delete(clients, awsinternal.ScanResult{ResourceID: "eipalloc-started", Details: map[string]interface{}{"association_id": "eipassoc-old"}})
+	assert.ErrorIs(t, err, awsinternal.ErrResourceInUse)
+	assert.EqualError(t, err, "resource is in use again: address is associated with instance i-started, which is running")
+
+	_, err = target.Delete(clients, awsinternal.ScanResult{ResourceID: "eipalloc-eni"})
+	assert.ErrorIs(t, err, awsinternal.ErrResourceInUse)
+	assert.Empty(t, fakeEC2.calls)
+
+	_, err = target.Delete(clients, awsinternal.ScanResult{ResourceID: "eipalloc-stopped"})
+	assert.EqualError(t, err, "InvalidAllocationID.NotFound")
+	assert.Equal(t, []string{"DisassociateAddress eipassoc-eipalloc-stopped", "ReleaseAddress eipalloc-stopped"}, fakeEC2.calls)
+}
+
+func TestLoadResults(t *testing.T) {
+	dir := t.TempDir()
+	data, err := json.Marshal(testResults()[0])
+	require.NoError(t, err)
+
+	// Scan output is gzipped JSON of one account
+	var gzipped bytes.Buffer
+	gz := gzip.NewWriter(&gzipped)
+	_, err = gz.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+	gzPath := filepath.Join(dir, "06-00-00+0000.json.gz")
+	require.NoError(t, os.WriteFile(gzPath, gzipped.Bytes(), 0600))
+
+	accounts, err := output.ReadResults(gzPath)
+	require.NoError(t, err)
+	require.Len(t, accounts, 1)
+	assert.Equal(t, "123456789012", accounts[0].AccountID)
+	assert.Equal(t, "vol-1", accounts[0].Results["EBS Volumes"][0].ResourceID)
+
+	listPath := filepath.Join(dir, "accounts.json")
+	require.NoError(t, os.WriteFile(listPath, []byte("["+string(data)+","+string(data)+"]"), 0600))
+	accounts, err = output.ReadResults(listPath)
+	require.NoError(t, err)
+	assert.Len(t, accounts, 2)
+
+	invalidPath := filepath.Join(dir, "invalid.json")
+	require.NoError(t, os.WriteFile(invalidPath, []byte(`{"results": {}}`), 0600))
+	_, err = output.ReadResults(invalidPath)
+	assert.Error(t, err)
+}
+
+func TestRemediateCmdRequiresTargets(t *testing.T) {
+	cmd := NewRemediateCmd()
+	cmd.SetArgs([]string{"--results", "scan.json"})
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetErr(new(bytes.Buffer))
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--ebs-volumes")
+
+	assert.Equal(t, "true", cmd.Flags().Lookup("dry-run").DefValue)
+}
@@ -1,12 +1,23 @@
 package cmd
 
 import (
+	"fmt"
 	"strings"
+	"time"
 
+	"cloudsift/cmd/anomalies"
+	"cloudsift/cmd/diff"
+	"cloudsift/cmd/doctor"
+	"cloudsift/cmd/history"
 	initCmd "cloudsift/cmd/init"
 	"cloudsift/cmd/list"
+	"cloudsift/cmd/login"
+	"cloudsift/cmd/remediate"
 	"cloudsift/cmd/scan"
+	"cloudsift/cmd/serve"
 	"cloudsift/cmd/version"
+	"cloudsift/cmd/whatif"
+	awsinternal "cloudsift/internal/aws"
 	"cloudsift/internal/config"
 	"cloudsift/internal/logging"
 
@@ -24,8 +35,9 @@ func Execute() error {
 		Long: `CloudSift is a command-line tool for managing and inspecting AWS resources.
 It provides a simple interface for common AWS tasks and operations.`,
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-			// Skip config initialization for certain commands
-			if cmd.Name() == "version" || cmd.Name() == "help" || cmd.Name() == "completion" {
+			// Skip config initialization for certain commands, and for shell completion, which
+			// must not log or fail on an invalid config file
+			if cmd.Name() == "version" || cmd.Name() == "help" || isCompletionCmd(cmd) {
 				// Reset config to empty values for these commands
 				config.Config = &config.GlobalConfig{}
 				return nil
@@ -41,6 +53,24 @@ It provides a simple interface for common AWS tasks and operations.`,
 			if err := viper.BindPFlag("aws.scanner_role", cmd.Root().PersistentFlags().Lookup("scanner-role")); err != nil {
 				return err
 			}
+			if err := viper.BindPFlag("aws.sts_region", cmd.Root().PersistentFlags().Lookup("sts-region")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("aws.accounts_cache_ttl", cmd.Root().PersistentFlags().Lookup("accounts-cache-ttl")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("aws.https_proxy", cmd.Root().PersistentFlags().Lookup("https-proxy")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("aws.ca_bundle", cmd.Root().PersistentFlags().Lookup("ca-bundle")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("aws.request_timeout", cmd.Root().PersistentFlags().Lookup("request-timeout")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("aws.use_fips_endpoints", cmd.Root().PersistentFlags().Lookup("use-fips-endpoints")); err != nil {
+				return err
+			}
 			if err := viper.BindPFlag("app.max_workers", cmd.Root().PersistentFlags().Lookup("max-workers")); err != nil {
 				return err
 			}
@@ -73,9 +103,33 @@ It provides a simple interface for common AWS tasks and operations.`,
 			config.Config.Profile = viper.GetString("aws.profile")
 			config.Config.OrganizationRole = viper.GetString("aws.organization_role")
 			config.Config.ScannerRole = viper.GetString("aws.scanner_role")
+			config.Config.STSRegion = viper.GetString("aws.sts_region")
+			config.Config.AccountsCacheTTL = viper.GetDuration("aws.accounts_cache_ttl")
+			config.Config.HTTPSProxy = viper.GetString("aws.https_proxy")
+			config.Config.CABundle = viper.GetString("aws.ca_bundle")
+			config.Config.RequestTimeout = viper.GetDuration("aws.request_timeout")
+			config.Config.UseFIPSEndpoints = viper.GetBool("aws.use_fips_endpoints")
 			config.Config.MaxWorkers = viper.GetInt("app.max_workers")
 			config.Config.LogFormat = viper.GetString("app.log_format")
 			config.Config.LogLevel = viper.GetString("app.log_level")
+			if err := viper.UnmarshalKey("aws.account_mappings", &config.Config.AccountMappings); err != nil {
+				return fmt.Errorf("invalid account mappings: %w", err)
+			}
+
+			// Route the requests of all AWS sessions through the configured proxy and CA bundle.
+			// Base sessions keep requests without a timeout unless one is set explicitly.
+			var baseRequestTimeout time.Duration
+			if viper.IsSet("aws.request_timeout") {
+				baseRequestTimeout = config.Config.RequestTimeout
+			}
+			if err := awsinternal.ConfigureHTTPClient(awsinternal.HTTPClientOptions{
+				HTTPSProxy:         config.Config.HTTPSProxy,
+				CABundle:           config.Config.CABundle,
+				RequestTimeout:     config.Config.RequestTimeout,
+				BaseRequestTimeout: baseRequestTimeout,
+			}); err != nil {
+				return fmt.Errorf("invalid HTTP client configuration: %w", err)
+			}
 
 			// Log configuration sources if logging is enabled
 			if shouldLog {
@@ -120,6 +174,13 @@ It provides a simple interface for common AWS tasks and operations.`,
 	rootCmd.PersistentFlags().StringVarP(&config.Config.Profile, "profile", "p", "default", "AWS profile to use (supports SSO profiles)")
 	rootCmd.PersistentFlags().StringVar(&config.Config.OrganizationRole, "organization-role", "", "Role name to assume for organization-wide operations")
 	rootCmd.PersistentFlags().StringVar(&config.Config.ScannerRole, "scanner-role", "", "Role name to assume for scanning operations")
+	rootCmd.PersistentFlags().StringVar(&config.Config.STSRegion, "sts-region", "", "Region of the regional STS endpoint used for role assumption (defaults to the session region)")
+	rootCmd.PersistentFlags().DurationVar(&config.Config.AccountsCacheTTL, "accounts-cache-ttl", time.Hour, "How long the organization account list is cached in the cache directory (0 disables the cache)")
+	rootCmd.PersistentFlags().StringVar(&config.Config.HTTPSProxy, "https-proxy", "", "Proxy URL of AWS requests (defaults to the HTTPS_PROXY environment variable)")
+	rootCmd.PersistentFlags().StringVar(&config.Config.CABundle, "ca-bundle", "", "PEM file of certificates to trust in addition to the system roots, e.g. of a TLS intercepting proxy")
+	rootCmd.PersistentFlags().DurationVar(&config.Config.RequestTimeout, "request-timeout", awsinternal.DefaultRequestTimeout, "Timeout of each AWS request (0 disables it)")
+	rootCmd.PersistentFlags().BoolVar(&config.Config.UseFIPSEndpoints, "use-fips-endpoints", false, "Use FIPS endpoints for all AWS requests; services without one in a region are refused, except optional ones such as pricing")
+	rootCmd.PersistentFlags().BoolVar(&config.Config.RefreshAccounts, "refresh-accounts", false, "List the organization accounts again instead of using the cached account list")
 
 	// Add commands
 	rootCmd.AddCommand(
@@ -127,7 +188,25 @@ It provides a simple interface for common AWS tasks and operations.`,
 		list.NewListCmd(),
 		version.NewVersionCmd(),
 		initCmd.NewInitCmd(),
+		login.NewLoginCmd(),
+		doctor.NewDoctorCmd(),
+		whatif.NewWhatIfCmd(),
+		serve.NewServeCmd(),
+		anomalies.NewAnomaliesCmd(),
+		history.NewHistoryCmd(),
+		diff.NewDiffCmd(),
+		remediate.NewRemediateCmd(),
 	)
 
 	return rootCmd.Execute()
 }
+
+// isCompletionCmd returns whether a command generates a shell completion script or completes
+// a command line for the shell
+func isCompletionCmd(cmd *cobra.Command) bool {
+	switch cmd.Name() {
+	case "completion", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+		return true
+	}
+	return cmd.Parent() != nil && cmd.Parent().Name() == "completion"
+}
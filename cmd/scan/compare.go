@@ -0,0 +1,37 @@
+package scan
+
+import (
+	"fmt"
+
+	"cloudsift/internal/history"
+	"cloudsift/internal/logging"
+	"cloudsift/internal/output/html"
+)
+
+// loadPreviousScan reads the findings of the previous scan results of --compare-to, which the
+// HTML report compares the findings with
+func loadPreviousScan(path string) (*html.PreviousScan, error) {
+	if path == "" {
+		return nil, nil
+	}
+	findings, err := history.ReadResultsFindings(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --compare-to: %w", err)
+	}
+	logging.Info("Loaded previous scan results to compare with", map[string]interface{}{
+		"path":     path,
+		"findings": len(findings),
+	})
+	return &html.PreviousScan{Source: path, Findings: findings}, nil
+}
+
+// reportAccounts returns the IDs of the accounts a report is written of. Findings of other
+// accounts in the previous scan are left out of the comparison, since they were not scanned
+// or are reported elsewhere.
+func reportAccounts(accountResults map[string]*scanResult) map[string]bool {
+	accountIDs := make(map[string]bool, len(accountResults))
+	for accountID := range accountResults {
+		accountIDs[accountID] = true
+	}
+	return accountIDs
+}
@@ -0,0 +1,68 @@
+package scan
+
+import (
+	"strings"
+
+	awsinternal "cloudsift/internal/aws"
+	_ "cloudsift/internal/aws/scanners" // Import for side effects (scanner registration)
+	"cloudsift/internal/gcp"
+
+	"github.com/spf13/cobra"
+)
+
+// registerCompletions registers the shell completions of the scan flags with fixed values or
+// scanner names
+func registerCompletions(cmd *cobra.Command) {
+	completions := map[string]func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective){
+		"scanners":                completeScanners(""),
+		"scanner-days-unused":     completeScanners("="),
+		"provider":                completeValues("aws", "gcp"),
+		"output":                  completeValues("filesystem", "s3", "s3-account"),
+		"output-format":           completeValues("json", "html", "csv", "markdown"),
+		"report-language":         completeValues("en", "de", "fr"),
+		"reason-verbosity":        completeValues("summary", "normal", "debug"),
+		"ops-center-min-severity": completeValues("INFORMATIONAL", "LOW", "MEDIUM", "HIGH"),
+	}
+	for flag, complete := range completions {
+		// Registration only fails for unknown flags or flags registered twice
+		_ = cmd.RegisterFlagCompletionFunc(flag, complete)
+	}
+}
+
+// completeValues completes a flag with a fixed set of values
+func completeValues(values ...string) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return values, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeScanners completes the last item of a comma-separated list of scanner names from
+// the scanner registry of the --provider. Scanners already listed are left out, and each name
+// is completed with the suffix, e.g. "=" for SCANNER=DAYS items.
+func completeScanners(suffix string) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		names := awsinternal.DefaultRegistry.ListScanners()
+		if provider, _ := cmd.Flags().GetString("provider"); provider == "gcp" {
+			names = gcp.DefaultRegistry.ListScanners()
+		}
+
+		prefix := ""
+		listed := make(map[string]bool)
+		if i := strings.LastIndex(toComplete, ","); i >= 0 {
+			prefix = toComplete[:i+1]
+			for _, item := range strings.Split(toComplete[:i], ",") {
+				name, _, _ := strings.Cut(item, "=")
+				listed[strings.TrimSpace(name)] = true
+			}
+		}
+
+		var completions []string
+		for _, name := range names {
+			if !listed[name] && strings.HasPrefix(prefix+name, toComplete) {
+				completions = append(completions, prefix+name+suffix)
+			}
+		}
+		// No space after a name, so further scanners or the days can be typed right after it
+		return completions, cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
+	}
+}
@@ -0,0 +1,249 @@
+package scan
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	awsinternal "cloudsift/internal/aws"
+	"cloudsift/internal/config"
+	"cloudsift/internal/gcp"
+	_ "cloudsift/internal/gcp/scanners" // Import for side effects (scanner registration)
+	"cloudsift/internal/history"
+	"cloudsift/internal/logging"
+	"cloudsift/internal/output/html"
+	"cloudsift/internal/worker"
+)
+
+// gcpRegionLabel is the region GCP scanner tasks are logged with, since they list the
+// resources of all zones and regions of a project at once
+const gcpRegionLabel = "global"
+
+// validateGCPOptions returns an error for options the gcp provider does not support, as
+// they write to or depend on AWS accounts
+func validateGCPOptions(opts *scanOptions) error {
+	if opts.gcpProjects == "" {
+		return fmt.Errorf("--gcp-projects is required when --provider=gcp")
+	}
+	unsupported := []struct {
+		option string
+		set    bool
+	}{
+		{"--output=s3-account", opts.output == "s3-account"},
+		{"--security-hub", opts.securityHub},
+		{"--ops-center", opts.opsCenter},
+		{"--dynamodb-table", opts.dynamoDBTable != ""},
+		{"--access-analyzer", opts.accessAnalyzer},
+		{"--compute-optimizer", opts.computeOptimizer},
+		{"--plan-only", opts.planOnly},
+		{"--edge-zones", opts.edgeZones},
+		{"--exclude-management-account", opts.excludeManagementAccount},
+		{"scan.output_overrides", len(config.Config.ScanOutputOverrides) > 0},
+		{"--prometheus-listen-address", opts.prometheusListenAddress != ""},
+	}
+	for _, u := range unsupported {
+		if u.set {
+			return fmt.Errorf("%s is not supported with --provider=gcp", u.option)
+		}
+	}
+	return nil
+}
+
+// getGCPScanners returns the GCP scanners of a comma-separated list, or all of them if the
+// list is empty
+func getGCPScanners(scannerList string) ([]gcp.Scanner, error) {
+	names := gcp.DefaultRegistry.ListScanners()
+	if scannerList != "" {
+		names = strings.Split(scannerList, ",")
+	}
+
+	var scanners []gcp.Scanner
+	var invalidScanners []string
+	for _, name := range names {
+		scanner, err := gcp.DefaultRegistry.GetScanner(strings.TrimSpace(name))
+		if err != nil {
+			invalidScanners = append(invalidScanners, name)
+			continue
+		}
+		scanners = append(scanners, scanner)
+	}
+	if len(invalidScanners) > 0 {
+		return nil, fmt.Errorf("invalid GCP scanners: %s (available: %s)", strings.Join(invalidScanners, ", "), strings.Join(gcp.DefaultRegistry.ListScanners(), ", "))
+	}
+	return scanners, nil
+}
+
+// runGCPScan scans the GCP projects of the scan and writes the findings to the same outputs
+// as AWS scans, with each project in place of an account
+func runGCPScan(opts *scanOptions) error {
+	scanners, err := getGCPScanners(opts.scanners)
+	if err != nil {
+		return err
+	}
+	var projects []string
+	for _, project := range strings.Split(opts.gcpProjects, ",") {
+		if project = strings.TrimSpace(project); project != "" {
+			projects = append(projects, project)
+		}
+	}
+
+	httpClient := awsinternal.HTTPClient()
+	creds, err := gcp.FindCredentials(httpClient, opts.gcpCredentialsFile, opts.gcpImpersonateServiceAccount)
+	if err != nil {
+		return err
+	}
+	client := gcp.NewClient(creds)
+	client.HTTPClient = httpClient
+	logging.Info("Using GCP credentials", map[string]interface{}{
+		"source": creds.Source,
+	})
+
+	converter, err := newCurrencyConverter(opts)
+	if err != nil {
+		return err
+	}
+
+	accountResults := make(map[string]*scanResult)
+	for _, project := range projects {
+		accountResults[project] = &scanResult{
+			AccountID:   project,
+			AccountName: project,
+			Results:     make(map[string]awsinternal.ScanResults),
+		}
+	}
+
+	if err := worker.InitSharedPool(config.Config.MaxWorkers); err != nil {
+		return fmt.Errorf("failed to initialize worker pool: %w", err)
+	}
+	workerPool := worker.GetSharedPool()
+
+	var scannerNames []string
+	for _, scanner := range scanners {
+		scannerNames = append(scannerNames, scanner.Label())
+	}
+	var accountInfo []logging.Account
+	for _, project := range projects {
+		accountInfo = append(accountInfo, logging.Account{ID: project, Name: project})
+	}
+	startTime := time.Now()
+	logging.ScanStart(scannerNames, accountInfo, []string{gcpRegionLabel})
+	for _, result := range accountResults {
+		result.ScannedAt = startTime.UTC()
+	}
+
+	var tasks []worker.Task
+	var resultsMutex sync.Mutex
+	for _, scanner := range scanners {
+		for _, project := range projects {
+			scanner := scanner
+			project := project
+			tasks = append(tasks, worker.Task(func(ctx context.Context) (err error) {
+				defer func() {
+					if err != nil {
+						resultsMutex.Lock()
+						accountResults[project].Errors = append(accountResults[project].Errors, scanError{
+							Scanner: scanner.Label(),
+							Region:  gcpRegionLabel,
+							Error:   err.Error(),
+						})
+						resultsMutex.Unlock()
+					}
+				}()
+				defer worker.Recover(&err)
+
+				logging.ScannerStart(scanner.Label(), project, project, gcpRegionLabel)
+				results, err := scanner.Scan(gcp.ScanOptions{
+					Project:    project,
+					DaysUnused: opts.daysUnused,
+					Client:     client,
+				})
+				if err != nil {
+					logging.ScannerError(scanner.Label(), project, project, gcpRegionLabel, err)
+					return err
+				}
+
+				var filteredResults awsinternal.ScanResults
+				for _, result := range results {
+					if awsinternal.WithinGracePeriod(result, opts.gracePeriodDays, time.Now()) {
+						continue
+					}
+					if rule := ignoreRule(result); rule != "" {
+						logging.Debug("Ignoring resource by "+rule, map[string]interface{}{
+							"resource_id":   result.ResourceID,
+							"resource_name": result.ResourceName,
+							"scanner":       scanner.Label(),
+							"project":       project,
+						})
+						continue
+					}
+					result.AccountID = project
+					result.AccountName = project
+					filteredResults = append(filteredResults, result)
+				}
+				warnMalformedResults(scanner.Label(), project, gcpRegionLabel, filteredResults)
+
+				resultsMutex.Lock()
+				accountResults[project].Results[scanner.Label()] = filteredResults
+				resultsMutex.Unlock()
+
+				resultInterfaces := make([]interface{}, len(filteredResults))
+				for i, r := range filteredResults {
+					resultInterfaces[i] = r
+				}
+				logging.ScannerComplete(scanner.Label(), project, project, gcpRegionLabel, resultInterfaces)
+				return nil
+			}))
+		}
+	}
+	workerPool.ExecuteTasks(tasks)
+
+	// Classify and phrase the reasons, and convert the costs, as for AWS findings
+	for _, accountResult := range accountResults {
+		accountResult.Currency = converter.Currency
+		for _, scannerResults := range accountResult.Results {
+			awsinternal.ApplyReasonCodes(scannerResults)
+			if opts.reasonVerbosity != awsinternal.ReasonVerbosityNormal {
+				awsinternal.ApplyReasonVerbosity(scannerResults, opts.reasonVerbosity, opts.daysUnused)
+			}
+			converter.ConvertResults(scannerResults)
+		}
+	}
+	addCostAllocation(accountResults, config.Config.ScanCostAllocationTags)
+
+	metrics := workerPool.GetMetrics()
+	duration := time.Since(startTime).Seconds()
+	reportMetrics := html.ScanMetrics{
+		CompletedScans:     metrics.CompletedTasks,
+		FailedScans:        metrics.FailedTasks,
+		TotalRunTime:       duration,
+		AvgScansPerSecond:  float64(metrics.CompletedTasks) / duration,
+		CompletedAt:        time.Now(),
+		PeakWorkers:        metrics.PeakWorkers,
+		MaxWorkers:         config.Config.MaxWorkers,
+		WorkerUtilization:  float64(metrics.PeakWorkers) / float64(config.Config.MaxWorkers) * 100,
+		AvgExecutionTimeMs: metrics.AverageExecutionMs,
+	}
+	if opts.historyDir != "" {
+		// GCP findings do not take part in confirmation, so the run covers none of their streaks
+		recordHistoryRun(opts.historyDir, startTime, converter.Currency, accountResults, nil, history.NewScope(), nil, reportMetrics)
+	}
+	summary := scanSummary(accountResults, reportMetrics, converter.Currency)
+	exportPrometheusMetrics(opts, summary)
+	pushMetrics(config.Config.ScanMetricsExporters, summary)
+	writeResults(opts, accountResults, nil, reportMetrics, html.ReportOptions{
+		Language:           opts.reportLanguage,
+		Currency:           converter.Currency,
+		CurrencySymbol:     converter.Symbol(),
+		ReasonTemplates:    reportReasonTemplates(),
+		CostAllocationTags: config.Config.ScanCostAllocationTags,
+		PreviousScan:       opts.previousScan,
+	})
+
+	printScanSummary(os.Stdout, accountResults, converter.Symbol())
+	notifySlack(opts, accountResults, converter.Symbol())
+	logging.ScanComplete(len(accountResults))
+	return nil
+}
@@ -0,0 +1,169 @@
+package scan
+
+import (
+	"fmt"
+	"os"
+	"sync/atomic"
+
+	"cloudsift/internal/config"
+	"cloudsift/internal/logging"
+	"cloudsift/internal/metrics"
+	"cloudsift/internal/output/html"
+	"cloudsift/internal/worker"
+)
+
+// scanSummary returns the metrics summary of a finished scan, with the findings and estimated
+// monthly savings of each resource type of each account
+func scanSummary(accountResults map[string]*scanResult, reportMetrics html.ScanMetrics, currency string) metrics.ScanSummary {
+	summary := metrics.ScanSummary{
+		CompletedAt:     reportMetrics.CompletedAt,
+		DurationSeconds: reportMetrics.TotalRunTime,
+		Accounts:        len(accountResults),
+		SkippedAccounts: len(reportMetrics.SkippedAccounts),
+		CompletedTasks:  reportMetrics.CompletedScans,
+		FailedTasks:     reportMetrics.FailedScans,
+		PeakWorkers:     reportMetrics.PeakWorkers,
+		MaxWorkers:      reportMetrics.MaxWorkers,
+		APICalls:        reportMetrics.APICalls,
+		Currency:        currency,
+	}
+	for _, group := range summarizeAccounts(accountResults).Groups {
+		summary.Groups = append(summary.Groups, metrics.FindingsGroup{
+			AccountID:      group.AccountID,
+			AccountName:    group.AccountName,
+			ResourceType:   group.ResourceType,
+			Findings:       group.Findings,
+			MonthlySavings: group.MonthlySavings,
+		})
+	}
+	metrics.SortGroups(summary.Groups)
+	return summary
+}
+
+// servePrometheusMetrics serves the worker pool metrics of the running scan on /metrics of
+// the address, followed by the metrics of the finished scan once they are stored in summary
+func servePrometheusMetrics(address string, pool *worker.Pool, plannedTasks int, summary *atomic.Pointer[metrics.ScanSummary]) (*metrics.PrometheusServer, error) {
+	server, err := metrics.ServePrometheus(address, func() []metrics.Family {
+		poolMetrics := pool.GetMetrics()
+		families := metrics.PoolFamilies(metrics.PoolStats{
+			TotalTasks:     poolMetrics.TotalTasks,
+			CompletedTasks: poolMetrics.CompletedTasks,
+			FailedTasks:    poolMetrics.FailedTasks,
+			ActiveWorkers:  poolMetrics.CurrentWorkers,
+			PeakWorkers:    poolMetrics.PeakWorkers,
+			MaxWorkers:     config.Config.MaxWorkers,
+			PlannedTasks:   plannedTasks,
+		})
+		if finished := summary.Load(); finished != nil {
+			families = append(families, metrics.SummaryFamilies(*finished)...)
+		}
+		return families
+	})
+	if err != nil {
+		return nil, err
+	}
+	logging.Info("Serving scan metrics", map[string]interface{}{
+		"address": server.Address(),
+	})
+	return server, nil
+}
+
+// exportPrometheusMetrics writes the metrics of a finished scan to the Prometheus file and
+// pushes them to the Pushgateway. Failures are logged, since the scan results are written
+// already.
+func exportPrometheusMetrics(opts *scanOptions, summary metrics.ScanSummary) {
+	if opts.prometheusFile == "" && opts.prometheusPushgatewayURL == "" {
+		return
+	}
+	families := metrics.SummaryFamilies(summary)
+
+	if opts.prometheusFile != "" {
+		if err := metrics.WritePrometheusFile(opts.prometheusFile, families); err != nil {
+			logging.Error("Failed to write Prometheus metrics file", err, map[string]interface{}{
+				"path": opts.prometheusFile,
+			})
+		} else {
+			logging.Info("Wrote Prometheus metrics file", map[string]interface{}{
+				"path": opts.prometheusFile,
+			})
+		}
+	}
+
+	if opts.prometheusPushgatewayURL != "" {
+		if err := metrics.PushPrometheus(nil, opts.prometheusPushgatewayURL, metrics.PrometheusJob, families); err != nil {
+			logging.Error("Failed to push metrics to the Prometheus Pushgateway", err, nil)
+		} else {
+			logging.Info("Pushed metrics to the Prometheus Pushgateway", map[string]interface{}{
+				"job": metrics.PrometheusJob,
+			})
+		}
+	}
+}
+
+// defaultAPIKeyEnv is the environment variable holding the API key of each type of metrics
+// exporter unless the exporter names another
+var defaultAPIKeyEnv = map[string]string{
+	"datadog":  "DD_API_KEY",
+	"newrelic": "NEW_RELIC_LICENSE_KEY",
+}
+
+// apiKeyEnv returns the environment variable holding the API key of a metrics exporter
+func apiKeyEnv(exporter config.MetricsExporter) string {
+	if exporter.APIKeyEnv != "" {
+		return exporter.APIKeyEnv
+	}
+	return defaultAPIKeyEnv[exporter.Type]
+}
+
+// validateMetricsExporters checks the metrics exporters of the config file, including that
+// their API keys are set, so a misconfigured exporter fails before scanning
+func validateMetricsExporters(exporters []config.MetricsExporter) error {
+	for i, exporter := range exporters {
+		if _, ok := defaultAPIKeyEnv[exporter.Type]; !ok {
+			return fmt.Errorf("invalid type of metrics exporter %d: %q (datadog or newrelic)", i+1, exporter.Type)
+		}
+		if exporter.Type == "newrelic" {
+			switch exporter.Region {
+			case "", "us", "eu":
+			default:
+				return fmt.Errorf("invalid region of metrics exporter %d: %q (us or eu)", i+1, exporter.Region)
+			}
+		}
+		if os.Getenv(apiKeyEnv(exporter)) == "" {
+			return fmt.Errorf("API key of %s metrics exporter %d is not set in %s", exporter.Type, i+1, apiKeyEnv(exporter))
+		}
+	}
+	return nil
+}
+
+// pushMetrics sends the metrics of a finished scan to the monitoring services of the metrics
+// exporters. Failures are logged, since the scan results are written already.
+func pushMetrics(exporters []config.MetricsExporter, summary metrics.ScanSummary) {
+	for _, exporter := range exporters {
+		apiKey := os.Getenv(apiKeyEnv(exporter))
+		var err error
+		switch exporter.Type {
+		case "datadog":
+			url := exporter.URL
+			if url == "" {
+				url = metrics.DatadogURL(exporter.Site)
+			}
+			err = metrics.PushDatadog(nil, url, apiKey, summary, exporter.Tags)
+		case "newrelic":
+			url := exporter.URL
+			if url == "" {
+				url = metrics.NewRelicURL(exporter.Region)
+			}
+			err = metrics.PushNewRelic(nil, url, apiKey, summary, exporter.Tags)
+		}
+		if err != nil {
+			logging.Error("Failed to push scan metrics", err, map[string]interface{}{
+				"exporter": exporter.Type,
+			})
+			continue
+		}
+		logging.Info("Pushed scan metrics", map[string]interface{}{
+			"exporter": exporter.Type,
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package scan
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	awsinternal "cloudsift/internal/aws"
+	"cloudsift/internal/config"
+	"cloudsift/internal/logging"
+	"cloudsift/internal/output"
+	"cloudsift/internal/output/html"
+)
+
+// validateOutputOverrides returns an error for output overrides of the config file that match
+// no accounts or have no valid format and destination
+func validateOutputOverrides(overrides []config.OutputOverride) error {
+	for i, override := range overrides {
+		if len(override.Accounts) == 0 && len(override.OrganizationalUnits) == 0 {
+			return fmt.Errorf("output override %d needs accounts or organizational_units", i+1)
+		}
+		switch override.Format {
+		case "", "json", "html", "csv", "markdown":
+			// Valid formats; empty uses the scan output format
+		default:
+			return fmt.Errorf("invalid format of output override %d: %s", i+1, override.Format)
+		}
+		switch override.Output {
+		case "filesystem":
+			if override.Directory == "" {
+				return fmt.Errorf("output override %d needs a directory with output filesystem", i+1)
+			}
+		case "s3":
+			if override.Bucket == "" {
+				return fmt.Errorf("output override %d needs a bucket with output s3", i+1)
+			}
+		default:
+			return fmt.Errorf("invalid output of output override %d: %q (filesystem or s3)", i+1, override.Output)
+		}
+	}
+	return nil
+}
+
+// overridesMatchOrganizationalUnits reports whether any output override matches organizational
+// units, which are only listed for the accounts then
+func overridesMatchOrganizationalUnits(overrides []config.OutputOverride) bool {
+	for _, override := range overrides {
+		if len(override.OrganizationalUnits) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// outputOverrideOf returns the index of the first output override matching an account by ID
+// or by an organizational unit containing it, or -1 if none does
+func outputOverrideOf(account awsinternal.Account, overrides []config.OutputOverride) int {
+	for i, override := range overrides {
+		for _, accountID := range override.Accounts {
+			if accountID == account.ID {
+				return i
+			}
+		}
+		for _, unit := range override.OrganizationalUnits {
+			for _, accountUnit := range account.OrganizationalUnits {
+				if unit == accountUnit {
+					return i
+				}
+			}
+		}
+	}
+	return -1
+}
+
+// writeOutputOverrides writes the results of the accounts matching the configured output
+// overrides to their destinations and returns the results of the other accounts, which are
+// written to the scan output. Results of an override that cannot be written are returned as
+// well, so they still reach the scan output.
+func writeOutputOverrides(opts *scanOptions, accounts []awsinternal.Account, accountResults map[string]*scanResult, metrics html.ScanMetrics, reportOptions html.ReportOptions) map[string]*scanResult {
+	overrides := config.Config.ScanOutputOverrides
+	if len(overrides) == 0 {
+		return accountResults
+	}
+
+	overrideOf := make(map[string]int, len(accounts))
+	for _, account := range accounts {
+		if i := outputOverrideOf(account, overrides); i >= 0 {
+			overrideOf[account.ID] = i
+		}
+	}
+
+	remaining := make(map[string]*scanResult, len(accountResults))
+	grouped := make(map[int]map[string]*scanResult)
+	for accountID, result := range accountResults {
+		i, ok := overrideOf[accountID]
+		if !ok {
+			remaining[accountID] = result
+			continue
+		}
+		if grouped[i] == nil {
+			grouped[i] = make(map[string]*scanResult)
+		}
+		grouped[i][accountID] = result
+	}
+
+	indexes := make([]int, 0, len(grouped))
+	for i := range grouped {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+
+	for _, i := range indexes {
+		override := overrides[i]
+		if override.Format == "" {
+			override.Format = opts.outputFormat
+		}
+		destination, err := writeOutputOverride(override, opts.organizationRole, grouped[i], metrics, reportOptions)
+		if err != nil {
+			logging.Error("Error writing results of output override, writing them to the scan output instead", err, map[string]interface{}{
+				"override": i + 1,
+				"accounts": len(grouped[i]),
+			})
+			for accountID, result := range grouped[i] {
+				remaining[accountID] = result
+			}
+			continue
+		}
+		fmt.Printf("%s results of %d accounts written to %s\n", override.Format, len(grouped[i]), destination)
+	}
+	return remaining
+}
+
+// writeOutputOverride writes the results of the accounts of an output override in its format
+// and returns where they were written. JSON results are written per account like the scan
+// output; the other formats are one report of all the accounts.
+func writeOutputOverride(override config.OutputOverride, organizationRole string, accountResults map[string]*scanResult, metrics html.ScanMetrics, reportOptions html.ReportOptions) (string, error) {
+	reportOptions.PreviousScan = reportOptions.PreviousScan.ForAccounts(reportAccounts(accountResults))
+	writerConfig := output.Config{
+		Type:      output.FileSystem,
+		OutputDir: override.Directory,
+	}
+	destination := override.Directory
+	if override.Output == "s3" {
+		writerConfig = output.Config{
+			Type:             output.S3,
+			S3Bucket:         override.Bucket,
+			S3Region:         override.BucketRegion,
+			S3Prefix:         override.Prefix,
+			OrganizationRole: organizationRole,
+		}
+		destination = "s3://" + filepath.ToSlash(filepath.Join(override.Bucket, override.Prefix))
+	}
+	writer := output.NewWriter(writerConfig)
+
+	if override.Format == "json" {
+		for accountID, result := range accountResults {
+			if err := writer.Write(accountID, result); err != nil {
+				return "", fmt.Errorf("failed to write results of account %s: %w", accountID, err)
+			}
+		}
+		return destination, nil
+	}
+
+	var results []awsinternal.ScanResult
+	for _, accountResult := range accountResults {
+		for _, scannerResults := range accountResult.Results {
+			results = append(results, scannerResults...)
+		}
+	}
+
+	if override.Output == "filesystem" {
+		return destination, writeReportFile(override.Format, override.Directory, results, metrics, reportOptions)
+	}
+
+	// Reports for S3 are rendered into a temporary directory, and uploaded with the files
+	// written next to them, such as the details of large HTML reports
+	dir, err := os.MkdirTemp("", "cloudsift-report-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create report directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := writeReportFile(override.Format, dir, results, metrics, reportOptions); err != nil {
+		return "", err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to read report directory: %w", err)
+	}
+	for _, entry := range entries {
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return "", fmt.Errorf("failed to read report file: %w", err)
+		}
+		if err := writer.WriteFile(entry.Name(), data); err != nil {
+			return "", fmt.Errorf("failed to upload %s: %w", entry.Name(), err)
+		}
+	}
+	return destination, nil
+}
+
+// writeReportFile writes results as an HTML report, CSV results or a Markdown summary into a
+// directory, with the file names of the scan output
+func writeReportFile(format, dir string, results []awsinternal.ScanResult, metrics html.ScanMetrics, reportOptions html.ReportOptions) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	switch format {
+	case "html":
+		return html.WriteHTMLWithOptions(results, filepath.Join(dir, "scan_report.html"), metrics, reportOptions)
+	case "csv":
+		return output.WriteCSVFile(results, reportOptions.Currency, filepath.Join(dir, "scan_results.csv"))
+	case "markdown":
+		return output.WriteMarkdownFile(results, reportOptions.CurrencySymbol, filepath.Join(dir, "scan_summary.md"))
+	default:
+		return fmt.Errorf("unsupported report format: %s", format)
+	}
+}
@@ -0,0 +1,92 @@
+package scan
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	awsinternal "cloudsift/internal/aws"
+)
+
+// scanPlan lists the account, region and scanner tasks a scan would run, so its scope can be
+// reviewed, e.g. in change management approvals, before scanning production organizations
+type scanPlan struct {
+	GeneratedAt        time.Time     `json:"generated_at"`
+	Accounts           int           `json:"accounts"`
+	Regions            []string      `json:"regions"`
+	Scanners           []string      `json:"scanners"`
+	TotalTasks         int           `json:"total_tasks"`
+	EstimatedResources *int          `json:"estimated_resources,omitempty"` // Known with --count-resources
+	Tasks              []plannedTask `json:"tasks"`
+}
+
+// plannedTask is a scanner task of the scan plan
+type plannedTask struct {
+	AccountID          string `json:"account_id"`
+	AccountName        string `json:"account_name"`
+	Region             string `json:"region"` // Global label of the partition for IAM and multi-region scanners
+	Scanner            string `json:"scanner"`
+	Label              string `json:"label"`
+	EstimatedResources *int   `json:"estimated_resources,omitempty"` // Known with --count-resources
+}
+
+// buildScanPlan builds the plan of the tasks of a scan. Resource counts are added to the
+// tasks they are known for when the resources were counted before.
+func buildScanPlan(tasks []scanTask, regions []string, scanners []awsinternal.Scanner, resourceCounts *awsinternal.ResourceCounts) scanPlan {
+	plan := scanPlan{
+		GeneratedAt: time.Now().UTC(),
+		Regions:     regions,
+		TotalTasks:  len(tasks),
+		Tasks:       make([]plannedTask, 0, len(tasks)),
+	}
+	for _, scanner := range scanners {
+		plan.Scanners = append(plan.Scanners, scanner.ArgumentName())
+	}
+
+	accounts := make(map[string]bool)
+	for _, task := range tasks {
+		accounts[task.account.ID] = true
+
+		planned := plannedTask{
+			AccountID:   task.account.ID,
+			AccountName: task.account.Name,
+			Region:      task.region,
+			Scanner:     task.scanner.ArgumentName(),
+			Label:       task.scanner.Label(),
+		}
+		if isIAMScanner(task.scanner) || isMultiRegionScanner(task.scanner) {
+			planned.Region = awsinternal.GlobalRegionLabel(awsinternal.RegionPartition(task.region))
+		} else if resourceCounts != nil {
+			if count, ok := resourceCounts.Get(task.account.ID, task.region, task.scanner.ArgumentName()); ok {
+				planned.EstimatedResources = &count
+				if plan.EstimatedResources == nil {
+					plan.EstimatedResources = new(int)
+				}
+				*plan.EstimatedResources += count
+			}
+		}
+		plan.Tasks = append(plan.Tasks, planned)
+	}
+	plan.Accounts = len(accounts)
+	return plan
+}
+
+// writeScanPlan writes a scan plan as JSON to a path
+func writeScanPlan(plan scanPlan, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal scan plan: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write scan plan: %w", err)
+	}
+
+	return nil
+}
@@ -4,44 +4,138 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
-	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/sts"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
+	"cloudsift/internal/annotations"
+	"cloudsift/internal/anomaly"
 	awsinternal "cloudsift/internal/aws"
 	"cloudsift/internal/config"
+	"cloudsift/internal/currency"
+	"cloudsift/internal/history"
 	"cloudsift/internal/logging"
+	"cloudsift/internal/metrics"
 	"cloudsift/internal/output"
 	"cloudsift/internal/output/html"
 	"cloudsift/internal/worker"
 )
 
 type scanOptions struct {
-	regions             string
-	scanners            string
-	output              string // filesystem or s3
-	outputFormat        string // html or json
-	bucket              string
-	bucketRegion        string
-	organizationRole    string // Role to assume for listing organization accounts
-	scannerRole         string // Role to assume for scanning accounts
-	daysUnused          int    // Number of days a resource must be unused to be reported
-	ignoreResourceIDs   string
-	ignoreResourceNames string
-	ignoreTags          string
-	accounts            string // Comma-separated list of account IDs to scan
+	regions                      string
+	scanners                     string
+	output                       string // filesystem or s3
+	outputFormat                 string // html, json, csv or markdown
+	bucket                       string
+	bucketRegion                 string
+	organizationRole             string // Role to assume for listing organization accounts
+	scannerRole                  string // Role to assume for scanning accounts
+	daysUnused                   int    // Number of days a resource must be unused to be reported
+	ignoreResourceIDs            string
+	ignoreResourceNames          string
+	ignoreTags                   string
+	accounts                     string   // Comma-separated list of account IDs to scan
+	countResources               bool     // Count resources with the tagging API before scanning
+	skipEmptyRegions             bool     // Skip region/scanner combinations that contain no resources
+	excludeASGInstances          bool     // Exclude Auto Scaling group members from EC2 idle detection
+	excludeSpotInstances         bool     // Exclude Spot instances from EC2 idle detection
+	businessHours                string   // Business hours window for utilization analysis (e.g. "Mon-Fri 08:00-18:00")
+	businessHoursTimezone        string   // IANA timezone of the business hours window
+	schedulingPlan               string   // Path of the scheduling plan to write (requires businessHours)
+	reportLanguage               string   // Language of the HTML report
+	currency                     string   // Currency to report costs in
+	exchangeRate                 float64  // Static USD exchange rate for currency (0 looks the rate up)
+	exchangeRateURL              string   // URL returning USD exchange rates as JSON
+	annotationsFile              string   // Path to the reviewer annotations file
+	ssoStartURL                  string   // IAM Identity Center start URL used to create account sessions
+	ssoRegion                    string   // Region of the IAM Identity Center instance
+	ssoRoleName                  string   // Permission set used in each account
+	requireReadOnly              bool     // Abort unless scan credentials are verified read-only
+	scannerDaysUnused            string   // Per-scanner overrides of daysUnused in SCANNER=DAYS format
+	trimDetails                  bool     // Trim result details and write full details to disk
+	iamLastAccessed              bool     // Confirm unused IAM roles with service last accessed data
+	accessAnalyzer               bool     // Import IAM Access Analyzer unused access findings
+	emrIdleHours                 int      // Hours an EMR cluster may wait without steps before it is reported
+	securityHub                  bool     // Publish findings to AWS Security Hub
+	securityHubRegion            string   // Region findings are imported into
+	opsCenter                    bool     // Open OpsCenter OpsItems for severe findings
+	opsCenterRegion              string   // Region OpsItems are opened in
+	opsCenterMinSeverity         string   // Lowest finding severity OpsItems are opened for
+	prefetchPrices               bool     // Prefetch prices in a pre-scan inventory pass
+	includeSuspendedAccounts     bool     // Scan suspended and closed organization accounts
+	includeMetricSamples         bool     // Keep raw CloudWatch datapoints in result details
+	confirmationScans            int      // Consecutive scans a finding must appear in before it is reported
+	includeManagedResources      bool     // Report resources matching the managed resource exclusion list
+	accountSpend                 bool     // Show identified waste as a share of each account's month-to-date spend
+	spendSummaryFile             string   // CSV of month-to-date spend per account used instead of Cost Explorer
+	delegatedAdmin               bool     // List organization accounts from a delegated administrator account
+	accountsFile                 string   // Account list used when Organizations is unavailable
+	dynamoDBTable                string   // DynamoDB table findings are written to
+	dynamoDBRegion               string   // Region of the DynamoDB table
+	maxAPICalls                  int      // API call budget of the scan (0 for no limit)
+	reasonVerbosity              string   // Detail of the reasons of findings (summary, normal or debug)
+	accountBucket                string   // Template of the account-local results bucket
+	accountPrefix                string   // Template of the key prefix in the account-local results bucket
+	gracePeriodDays              int      // Grace period of recently created resources in days (0 to disable)
+	maxWorkersPerAccount         int      // Concurrent scanner tasks per account (0 for no limit)
+	excludeWarmInstances         bool     // Skip warm pool and hibernated EC2 instances
+	provider                     string   // Cloud provider to scan
+	gcpProjects                  string   // GCP projects to scan
+	gcpCredentialsFile           string   // GCP credentials file
+	computeOptimizer             bool     // Import AWS Compute Optimizer over-provisioning findings
+	costAllocationTags           string   // Tag keys the estimated savings are grouped by
+	planOnly                     bool     // Write the scan plan without scanning
+	planFile                     string   // Path of the scan plan written with planOnly
+	csvPerAccount                bool     // Write a CSV file per account
+	edgeZones                    bool     // Price resources in Local Zones and Wavelength Zones by their zone
+	excludeManagementAccount     bool     // Skip the management account of the organization
+	notifySlackWebhook           string   // Slack incoming webhook URL the scan summary is posted to
+	historyDir                   string   // Directory of the recorded scan runs
+	prometheusFile               string   // File the scan metrics are written to in Prometheus text format
+	prometheusPushgatewayURL     string   // Pushgateway the scan metrics are pushed to
+	prometheusListenAddress      string   // Address the metrics of the running scan are served on
+	compareTo                    string   // Previous scan results the HTML report compares the findings with
+	gcpImpersonateServiceAccount string   // GCP service account impersonated with the credentials
+	observer                     Observer // Receives progress and results when the scan is driven by a service
+
+	previousScan *html.PreviousScan // Findings of compareTo, loaded before scanning
+}
+
+// ProgressEvent reports a scanner task starting or completing
+type ProgressEvent struct {
+	AccountID   string
+	AccountName string
+	Region      string
+	Scanner     string
+	Completed   bool  // Whether the scanner task completed
+	ResultCount int   // Number of scan results found, once completed
+	Done        int64 // Completed units of the scan
+	Total       int64 // Total units of the scan
+	Unit        string
+}
+
+// Observer follows the progress and results of a scan, so services driving scans such as the
+// gRPC server can report them. Progress is reported from the worker goroutines.
+type Observer interface {
+	ScanProgress(event ProgressEvent)
+	// AccountResults receives the results of an account as JSON in the format of the JSON output
+	AccountResults(accountID, accountName string, data []byte)
 }
 
 type scannerProgress struct {
@@ -55,6 +149,8 @@ type scannerProgress struct {
 type scannerProgressMap struct {
 	sync.RWMutex
 	progress map[string]*scannerProgress // key is accountID:region:scanner
+	overall  *overallProgress
+	observer Observer
 }
 
 func newScannerProgressMap() *scannerProgressMap {
@@ -74,6 +170,7 @@ func (s *scannerProgressMap) startScanner(accountID, accountName, region, scanne
 		Scanner:     scanner,
 		ResultCount: 0,
 	}
+	s.notify(*s.progress[key], false)
 }
 
 func (s *scannerProgressMap) updateResultCount(accountID, region, scanner string, count int) {
@@ -89,9 +186,33 @@ func (s *scannerProgressMap) completeScanner(accountID, region, scanner string)
 	s.Lock()
 	defer s.Unlock()
 	key := fmt.Sprintf("%s:%s:%s", accountID, region, scanner)
+	if prog, exists := s.progress[key]; exists {
+		s.notify(*prog, true)
+	}
 	delete(s.progress, key)
 }
 
+// notify reports a scanner starting or completing to the observer, if any
+func (s *scannerProgressMap) notify(prog scannerProgress, completed bool) {
+	if s.observer == nil {
+		return
+	}
+	event := ProgressEvent{
+		AccountID:   prog.AccountID,
+		AccountName: prog.AccountName,
+		Region:      prog.Region,
+		Scanner:     prog.Scanner,
+		Completed:   completed,
+		ResultCount: prog.ResultCount,
+	}
+	if s.overall != nil {
+		event.Done = atomic.LoadInt64(&s.overall.completed)
+		event.Total = atomic.LoadInt64(&s.overall.total)
+		event.Unit = s.overall.unit
+	}
+	s.observer.ScanProgress(event)
+}
+
 func (s *scannerProgressMap) getRunning() []*scannerProgress {
 	s.RLock()
 	defer s.RUnlock()
@@ -102,13 +223,84 @@ func (s *scannerProgressMap) getRunning() []*scannerProgress {
 	return running
 }
 
+// overallProgress tracks completion of the whole scan. Each task carries a weight equal to
+// its expected resource count when a resource count pre-pass was run, or 1 otherwise.
+type overallProgress struct {
+	total     int64
+	completed int64
+	unit      string // "tasks" or "resources"
+}
+
+func (p *overallProgress) add(weight int64) {
+	atomic.AddInt64(&p.total, weight)
+}
+
+func (p *overallProgress) complete(weight int64) {
+	atomic.AddInt64(&p.completed, weight)
+}
+
+func (p *overallProgress) percent() float64 {
+	total := atomic.LoadInt64(&p.total)
+	if total == 0 {
+		return 0
+	}
+	return float64(atomic.LoadInt64(&p.completed)) / float64(total) * 100
+}
+
+// slowestTaskCount is the number of slowest tasks included in the metrics output
+const slowestTaskCount = 20
+
+// taskTiming records the wall-clock duration of a single scanner/account/region task
+type taskTiming struct {
+	Scanner     string
+	AccountID   string
+	AccountName string
+	Region      string
+	Duration    time.Duration
+	Failed      bool
+}
+
+// taskTimings collects task durations from concurrently running workers
+type taskTimings struct {
+	sync.Mutex
+	timings []taskTiming
+}
+
+func (t *taskTimings) record(timing taskTiming) {
+	t.Lock()
+	defer t.Unlock()
+	t.timings = append(t.timings, timing)
+}
+
+// slowest returns up to n task timings ordered from slowest to fastest
+func (t *taskTimings) slowest(n int) []taskTiming {
+	t.Lock()
+	defer t.Unlock()
+	sorted := make([]taskTiming, len(t.timings))
+	copy(sorted, t.timings)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Duration > sorted[j].Duration
+	})
+	if len(sorted) > n {
+		sorted = sorted[:n]
+	}
+	return sorted
+}
+
 // NewScanCmd creates the scan command
 func NewScanCmd() *cobra.Command {
-	opts := &scanOptions{}
+	return NewScanCmdWithObserver(nil)
+}
+
+// NewScanCmdWithObserver creates the scan command reporting its progress and results to an
+// observer
+func NewScanCmdWithObserver(observer Observer) *cobra.Command {
+	opts := &scanOptions{observer: observer}
 
 	cmd := &cobra.Command{
-		Use:   "scan",
-		Short: "Scan AWS resources",
+		Use:     "scan",
+		Aliases: []string{"s"},
+		Short:   "Scan AWS resources",
 		Long: `Scan AWS resources for potential cost savings.
 
 When no scanners or regions are specified, all available scanners will be run in all available regions.
@@ -182,6 +374,274 @@ Examples:
 			if cmd.Flags().Changed("accounts") {
 				config.Config.ScanAccounts = strings.Split(opts.accounts, ",")
 			}
+			if cmd.Flags().Changed("count-resources") {
+				config.Config.ScanCountResources = opts.countResources
+			}
+			if cmd.Flags().Changed("skip-empty-regions") {
+				config.Config.ScanSkipEmptyRegions = opts.skipEmptyRegions
+			}
+			if cmd.Flags().Changed("exclude-asg-instances") {
+				config.Config.ScanExcludeASGInstances = opts.excludeASGInstances
+			}
+			if cmd.Flags().Changed("exclude-spot-instances") {
+				config.Config.ScanExcludeSpotInstances = opts.excludeSpotInstances
+			}
+			if cmd.Flags().Changed("business-hours") {
+				config.Config.ScanBusinessHours = opts.businessHours
+			}
+			if cmd.Flags().Changed("business-hours-timezone") {
+				config.Config.ScanBusinessHoursTimezone = opts.businessHoursTimezone
+			}
+			if cmd.Flags().Changed("scheduling-plan") {
+				config.Config.ScanSchedulingPlan = opts.schedulingPlan
+			}
+			if cmd.Flags().Changed("report-language") {
+				config.Config.ScanReportLanguage = opts.reportLanguage
+			}
+			if cmd.Flags().Changed("currency") {
+				config.Config.ScanCurrency = opts.currency
+			}
+			if cmd.Flags().Changed("exchange-rate") {
+				config.Config.ScanExchangeRate = opts.exchangeRate
+			}
+			if cmd.Flags().Changed("exchange-rate-url") {
+				config.Config.ScanExchangeRateURL = opts.exchangeRateURL
+			}
+			config.Config.ScanExchangeRates = make(map[string]float64)
+			for code := range viper.GetStringMap("scan.exchange_rates") {
+				config.Config.ScanExchangeRates[code] = viper.GetFloat64("scan.exchange_rates." + code)
+			}
+			config.Config.ScanCostOverrides = make(map[string]float64)
+			for key := range viper.GetStringMap("scan.cost_overrides") {
+				config.Config.ScanCostOverrides[key] = viper.GetFloat64("scan.cost_overrides." + key)
+			}
+			config.Config.ScanMarketplaceRates = make(map[string]float64)
+			for code := range viper.GetStringMap("scan.marketplace_rates") {
+				config.Config.ScanMarketplaceRates[code] = viper.GetFloat64("scan.marketplace_rates." + code)
+			}
+			config.Config.ScanReasonTemplates = nil
+			if err := viper.UnmarshalKey("scan.reason_templates", &config.Config.ScanReasonTemplates); err != nil {
+				return fmt.Errorf("invalid reason templates: %w", err)
+			}
+			config.Config.ScanOutputOverrides = nil
+			if err := viper.UnmarshalKey("scan.output_overrides", &config.Config.ScanOutputOverrides); err != nil {
+				return fmt.Errorf("invalid output overrides: %w", err)
+			}
+			if err := validateOutputOverrides(config.Config.ScanOutputOverrides); err != nil {
+				return err
+			}
+			config.Config.ScanMetricsExporters = nil
+			if err := viper.UnmarshalKey("scan.metrics_exporters", &config.Config.ScanMetricsExporters); err != nil {
+				return fmt.Errorf("invalid metrics exporters: %w", err)
+			}
+			if err := validateMetricsExporters(config.Config.ScanMetricsExporters); err != nil {
+				return err
+			}
+			config.Config.ScanManagedResources = nil
+			if err := viper.UnmarshalKey("scan.managed_resources", &config.Config.ScanManagedResources); err != nil {
+				return fmt.Errorf("invalid managed resources: %w", err)
+			}
+			if cmd.Flags().Changed("annotations-file") {
+				config.Config.ScanAnnotationsFile = opts.annotationsFile
+			}
+			if cmd.Flags().Changed("sso-start-url") {
+				config.Config.ScanSSOStartURL = opts.ssoStartURL
+			}
+			if cmd.Flags().Changed("sso-region") {
+				config.Config.ScanSSORegion = opts.ssoRegion
+			}
+			if cmd.Flags().Changed("sso-role-name") {
+				config.Config.ScanSSORoleName = opts.ssoRoleName
+			}
+			if cmd.Flags().Changed("require-read-only") {
+				config.Config.ScanRequireReadOnly = opts.requireReadOnly
+			}
+			config.Config.ScanScannerDaysUnused = make(map[string]int)
+			for name := range viper.GetStringMap("scan.scanner_days_unused") {
+				config.Config.ScanScannerDaysUnused[name] = viper.GetInt("scan.scanner_days_unused." + name)
+			}
+			// Overrides may also be given in SCANNER=DAYS format by the flag or environment
+			scannerDaysUnused := viper.GetString("scan.scanner_days_unused")
+			if cmd.Flags().Changed("scanner-days-unused") {
+				scannerDaysUnused = opts.scannerDaysUnused
+			}
+			if scannerDaysUnused != "" {
+				overrides, err := parseScannerDaysUnused(scannerDaysUnused)
+				if err != nil {
+					return err
+				}
+				for name, days := range overrides {
+					config.Config.ScanScannerDaysUnused[name] = days
+				}
+			}
+			for name, days := range config.Config.ScanScannerDaysUnused {
+				scanner, err := awsinternal.DefaultRegistry.GetScanner(name)
+				if err != nil {
+					return fmt.Errorf("invalid scanner in days unused overrides: %s", name)
+				}
+				if days <= 0 {
+					return fmt.Errorf("invalid days unused for scanner %s: %d", name, days)
+				}
+				// Overrides are looked up by argument name
+				if scanner.ArgumentName() != name {
+					delete(config.Config.ScanScannerDaysUnused, name)
+					config.Config.ScanScannerDaysUnused[scanner.ArgumentName()] = days
+				}
+			}
+			config.Config.ScanEnabledScanners = viper.GetStringSlice("scan.enabled_scanners")
+			config.Config.ScanDisabledScanners = viper.GetStringSlice("scan.disabled_scanners")
+			config.Config.ScanAccountScannerExclusions = nil
+			if err := viper.UnmarshalKey("scan.account_scanner_exclusions", &config.Config.ScanAccountScannerExclusions); err != nil {
+				return fmt.Errorf("invalid account scanner exclusions: %w", err)
+			}
+			config.Config.ScanMaxWorkersPerAccount = viper.GetInt("scan.max_workers_per_account")
+			config.Config.ScanAccountMaxWorkers = nil
+			if err := viper.UnmarshalKey("scan.account_max_workers", &config.Config.ScanAccountMaxWorkers); err != nil {
+				return fmt.Errorf("invalid account max workers: %w", err)
+			}
+			for accountID, workers := range config.Config.ScanAccountMaxWorkers {
+				if workers < 0 {
+					return fmt.Errorf("invalid max workers for account %s: %d", accountID, workers)
+				}
+			}
+			if err := validateScannerSelection(); err != nil {
+				return err
+			}
+			if cmd.Flags().Changed("trim-details") {
+				config.Config.ScanTrimDetails = opts.trimDetails
+			}
+			if cmd.Flags().Changed("iam-last-accessed") {
+				config.Config.ScanIAMLastAccessed = opts.iamLastAccessed
+			}
+			if cmd.Flags().Changed("access-analyzer") {
+				config.Config.ScanAccessAnalyzer = opts.accessAnalyzer
+			}
+			if cmd.Flags().Changed("emr-idle-hours") {
+				config.Config.ScanEMRIdleHours = opts.emrIdleHours
+			}
+			if cmd.Flags().Changed("security-hub") {
+				config.Config.ScanSecurityHub = opts.securityHub
+			}
+			if cmd.Flags().Changed("security-hub-region") {
+				config.Config.ScanSecurityHubRegion = opts.securityHubRegion
+			}
+			if cmd.Flags().Changed("ops-center") {
+				config.Config.ScanOpsCenter = opts.opsCenter
+			}
+			if cmd.Flags().Changed("ops-center-region") {
+				config.Config.ScanOpsCenterRegion = opts.opsCenterRegion
+			}
+			if cmd.Flags().Changed("ops-center-min-severity") {
+				config.Config.ScanOpsCenterMinSeverity = opts.opsCenterMinSeverity
+			}
+			if cmd.Flags().Changed("prefetch-prices") {
+				config.Config.ScanPrefetchPrices = opts.prefetchPrices
+			}
+			if cmd.Flags().Changed("include-suspended-accounts") {
+				config.Config.ScanIncludeSuspendedAccounts = opts.includeSuspendedAccounts
+			}
+			if cmd.Flags().Changed("include-metric-samples") {
+				config.Config.ScanIncludeMetricSamples = opts.includeMetricSamples
+			}
+			if cmd.Flags().Changed("confirmation-scans") {
+				config.Config.ScanConfirmationScans = opts.confirmationScans
+			}
+			if cmd.Flags().Changed("include-managed-resources") {
+				config.Config.ScanIncludeManagedResources = opts.includeManagedResources
+			}
+			if cmd.Flags().Changed("account-spend") {
+				config.Config.ScanAccountSpend = opts.accountSpend
+			}
+			if cmd.Flags().Changed("spend-summary-file") {
+				config.Config.ScanSpendSummaryFile = opts.spendSummaryFile
+			}
+			if cmd.Flags().Changed("delegated-admin") {
+				config.Config.ScanDelegatedAdmin = opts.delegatedAdmin
+			}
+			if cmd.Flags().Changed("accounts-file") {
+				config.Config.ScanAccountsFile = opts.accountsFile
+			}
+			if cmd.Flags().Changed("dynamodb-table") {
+				config.Config.ScanDynamoDBTable = opts.dynamoDBTable
+			}
+			if cmd.Flags().Changed("dynamodb-region") {
+				config.Config.ScanDynamoDBRegion = opts.dynamoDBRegion
+			}
+			if cmd.Flags().Changed("max-api-calls") {
+				config.Config.ScanMaxAPICalls = opts.maxAPICalls
+			}
+			if cmd.Flags().Changed("reason-verbosity") {
+				config.Config.ScanReasonVerbosity = opts.reasonVerbosity
+			}
+			if cmd.Flags().Changed("account-bucket") {
+				config.Config.ScanAccountBucket = opts.accountBucket
+			}
+			if cmd.Flags().Changed("account-prefix") {
+				config.Config.ScanAccountPrefix = opts.accountPrefix
+			}
+			if cmd.Flags().Changed("grace-period-days") {
+				config.Config.ScanGracePeriodDays = opts.gracePeriodDays
+			}
+			if cmd.Flags().Changed("max-workers-per-account") {
+				config.Config.ScanMaxWorkersPerAccount = opts.maxWorkersPerAccount
+			}
+			if cmd.Flags().Changed("exclude-warm-instances") {
+				config.Config.ScanExcludeWarmInstances = opts.excludeWarmInstances
+			}
+			if cmd.Flags().Changed("provider") {
+				config.Config.ScanProvider = opts.provider
+			}
+			if cmd.Flags().Changed("gcp-projects") {
+				config.Config.ScanGCPProjects = opts.gcpProjects
+			}
+			if cmd.Flags().Changed("gcp-credentials-file") {
+				config.Config.ScanGCPCredentialsFile = opts.gcpCredentialsFile
+			}
+			if cmd.Flags().Changed("compute-optimizer") {
+				config.Config.ScanComputeOptimizer = opts.computeOptimizer
+			}
+
+			// Tag keys are a list in the config file or comma-separated in the flag and environment
+			config.Config.ScanCostAllocationTags = parseTagKeys(strings.Join(viper.GetStringSlice("scan.cost_allocation_tags"), ","))
+			if cmd.Flags().Changed("cost-allocation-tags") {
+				config.Config.ScanCostAllocationTags = parseTagKeys(opts.costAllocationTags)
+			}
+			if cmd.Flags().Changed("plan-only") {
+				config.Config.ScanPlanOnly = opts.planOnly
+			}
+			if cmd.Flags().Changed("plan-file") {
+				config.Config.ScanPlanFile = opts.planFile
+			}
+			if cmd.Flags().Changed("csv-per-account") {
+				config.Config.ScanCSVPerAccount = opts.csvPerAccount
+			}
+			if cmd.Flags().Changed("edge-zones") {
+				config.Config.ScanEdgeZones = opts.edgeZones
+			}
+			if cmd.Flags().Changed("exclude-management-account") {
+				config.Config.ScanExcludeManagementAccount = opts.excludeManagementAccount
+			}
+			if cmd.Flags().Changed("notify-slack-webhook") {
+				config.Config.ScanNotifySlackWebhook = opts.notifySlackWebhook
+			}
+			if cmd.Flags().Changed("history-dir") {
+				config.Config.ScanHistoryDir = opts.historyDir
+			}
+			if cmd.Flags().Changed("prometheus-file") {
+				config.Config.ScanPrometheusFile = opts.prometheusFile
+			}
+			if cmd.Flags().Changed("prometheus-pushgateway-url") {
+				config.Config.ScanPrometheusPushgatewayURL = opts.prometheusPushgatewayURL
+			}
+			if cmd.Flags().Changed("prometheus-listen-address") {
+				config.Config.ScanPrometheusListenAddress = opts.prometheusListenAddress
+			}
+			if cmd.Flags().Changed("compare-to") {
+				config.Config.ScanCompareTo = opts.compareTo
+			}
+			if cmd.Flags().Changed("gcp-impersonate-service-account") {
+				config.Config.ScanGCPImpersonateServiceAccount = opts.gcpImpersonateServiceAccount
+			}
 
 			// Bind scan-specific flags to viper
 			if err := viper.BindPFlag("scan.regions", cmd.Flags().Lookup("regions")); err != nil {
@@ -217,24 +677,258 @@ Examples:
 			if err := viper.BindPFlag("scan.accounts", cmd.Flags().Lookup("accounts")); err != nil {
 				return err
 			}
+			if err := viper.BindPFlag("scan.count_resources", cmd.Flags().Lookup("count-resources")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.skip_empty_regions", cmd.Flags().Lookup("skip-empty-regions")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.exclude_asg_instances", cmd.Flags().Lookup("exclude-asg-instances")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.exclude_spot_instances", cmd.Flags().Lookup("exclude-spot-instances")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.business_hours", cmd.Flags().Lookup("business-hours")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.business_hours_timezone", cmd.Flags().Lookup("business-hours-timezone")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.scheduling_plan", cmd.Flags().Lookup("scheduling-plan")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.report_language", cmd.Flags().Lookup("report-language")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.currency", cmd.Flags().Lookup("currency")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.exchange_rate", cmd.Flags().Lookup("exchange-rate")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.exchange_rate_url", cmd.Flags().Lookup("exchange-rate-url")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.annotations_file", cmd.Flags().Lookup("annotations-file")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.sso_start_url", cmd.Flags().Lookup("sso-start-url")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.sso_region", cmd.Flags().Lookup("sso-region")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.sso_role_name", cmd.Flags().Lookup("sso-role-name")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.require_read_only", cmd.Flags().Lookup("require-read-only")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.scanner_days_unused", cmd.Flags().Lookup("scanner-days-unused")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.trim_details", cmd.Flags().Lookup("trim-details")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.iam_last_accessed", cmd.Flags().Lookup("iam-last-accessed")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.access_analyzer", cmd.Flags().Lookup("access-analyzer")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.emr_idle_hours", cmd.Flags().Lookup("emr-idle-hours")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.security_hub", cmd.Flags().Lookup("security-hub")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.security_hub_region", cmd.Flags().Lookup("security-hub-region")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.ops_center", cmd.Flags().Lookup("ops-center")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.ops_center_region", cmd.Flags().Lookup("ops-center-region")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.ops_center_min_severity", cmd.Flags().Lookup("ops-center-min-severity")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.prefetch_prices", cmd.Flags().Lookup("prefetch-prices")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.include_suspended_accounts", cmd.Flags().Lookup("include-suspended-accounts")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.include_metric_samples", cmd.Flags().Lookup("include-metric-samples")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.confirmation_scans", cmd.Flags().Lookup("confirmation-scans")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.include_managed_resources", cmd.Flags().Lookup("include-managed-resources")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.account_spend", cmd.Flags().Lookup("account-spend")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.spend_summary_file", cmd.Flags().Lookup("spend-summary-file")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.delegated_admin", cmd.Flags().Lookup("delegated-admin")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.accounts_file", cmd.Flags().Lookup("accounts-file")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.dynamodb_table", cmd.Flags().Lookup("dynamodb-table")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.dynamodb_region", cmd.Flags().Lookup("dynamodb-region")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.max_api_calls", cmd.Flags().Lookup("max-api-calls")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.reason_verbosity", cmd.Flags().Lookup("reason-verbosity")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.account_bucket", cmd.Flags().Lookup("account-bucket")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.account_prefix", cmd.Flags().Lookup("account-prefix")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.grace_period_days", cmd.Flags().Lookup("grace-period-days")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.max_workers_per_account", cmd.Flags().Lookup("max-workers-per-account")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.exclude_warm_instances", cmd.Flags().Lookup("exclude-warm-instances")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.provider", cmd.Flags().Lookup("provider")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.gcp_projects", cmd.Flags().Lookup("gcp-projects")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.gcp_credentials_file", cmd.Flags().Lookup("gcp-credentials-file")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.compute_optimizer", cmd.Flags().Lookup("compute-optimizer")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.cost_allocation_tags", cmd.Flags().Lookup("cost-allocation-tags")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.plan_only", cmd.Flags().Lookup("plan-only")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.plan_file", cmd.Flags().Lookup("plan-file")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.csv_per_account", cmd.Flags().Lookup("csv-per-account")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.edge_zones", cmd.Flags().Lookup("edge-zones")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.exclude_management_account", cmd.Flags().Lookup("exclude-management-account")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.notify_slack_webhook", cmd.Flags().Lookup("notify-slack-webhook")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.history_dir", cmd.Flags().Lookup("history-dir")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.prometheus_file", cmd.Flags().Lookup("prometheus-file")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.prometheus_pushgateway_url", cmd.Flags().Lookup("prometheus-pushgateway-url")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.prometheus_listen_address", cmd.Flags().Lookup("prometheus-listen-address")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.compare_to", cmd.Flags().Lookup("compare-to")); err != nil {
+				return err
+			}
+			if err := viper.BindPFlag("scan.gcp_impersonate_service_account", cmd.Flags().Lookup("gcp-impersonate-service-account")); err != nil {
+				return err
+			}
 
 			// Log configuration sources after binding all flags
 			config.LogConfigurationSources(true, cmd)
 
 			// Validate output format
 			switch opts.outputFormat {
-			case "json", "html":
+			case "json", "html", "csv", "markdown":
 				// Valid formats
 			default:
 				return fmt.Errorf("invalid output format: %s", opts.outputFormat)
 			}
 
-			// Validate output type
-			switch opts.output {
-			case "filesystem", "s3":
-				// Valid output types
-			default:
-				return fmt.Errorf("invalid output type: %s", opts.output)
+			// Validate report language
+			if err := html.ValidateLanguage(opts.reportLanguage); err != nil {
+				return err
+			}
+			if err := html.ValidateReasonTemplates(reportReasonTemplates()); err != nil {
+				return err
+			}
+
+			// Validate EMR idle threshold
+			if opts.emrIdleHours <= 0 {
+				return fmt.Errorf("--emr-idle-hours must be greater than 0")
+			}
+
+			if opts.maxAPICalls < 0 {
+				return fmt.Errorf("--max-api-calls must not be negative")
+			}
+
+			if opts.maxWorkersPerAccount < 0 {
+				return fmt.Errorf("--max-workers-per-account must not be negative")
+			}
+
+			if err := awsinternal.ValidateReasonVerbosity(opts.reasonVerbosity); err != nil {
+				return err
+			}
+
+			if opts.securityHub && opts.securityHubRegion == "" {
+				return fmt.Errorf("--security-hub-region is required when --security-hub is set")
+			}
+
+			if opts.opsCenter {
+				if opts.opsCenterRegion == "" {
+					return fmt.Errorf("--ops-center-region is required when --ops-center is set")
+				}
+				if err := output.ValidateSeverity(opts.opsCenterMinSeverity); err != nil {
+					return fmt.Errorf("invalid --ops-center-min-severity: %w", err)
+				}
+			}
+
+			// Validate IAM Identity Center options
+			if (opts.ssoStartURL == "") != (opts.ssoRoleName == "") {
+				return fmt.Errorf("--sso-start-url and --sso-role-name must be used together")
+			}
+
+			// Validate account listing options
+			if (opts.delegatedAdmin || opts.accountsFile != "") && opts.scannerRole == "" {
+				return fmt.Errorf("--delegated-admin and --accounts-file require --scanner-role")
+			}
+			if opts.delegatedAdmin && opts.organizationRole != "" {
+				return fmt.Errorf("--delegated-admin and --organization-role cannot be used together")
+			}
+
+			// Validate output type
+			switch opts.output {
+			case "filesystem", "s3", "s3-account":
+				// Valid output types
+			default:
+				return fmt.Errorf("invalid output type: %s", opts.output)
 			}
 
 			// Validate S3 parameters
@@ -246,15 +940,42 @@ Examples:
 					return fmt.Errorf("--bucket-region is required when --output=s3")
 				}
 			}
+			if opts.output == "s3-account" {
+				if opts.accountBucket == "" {
+					return fmt.Errorf("--account-bucket is required when --output=s3-account")
+				}
+				for _, text := range []string{opts.accountBucket, opts.accountPrefix} {
+					if _, err := output.RenderAccountLocation(text, output.AccountLocation{}); err != nil {
+						return err
+					}
+				}
+			}
 
-			return runScan(cmd, opts)
+			// Read the previous scan results before scanning, so a bad path fails right away
+			previousScan, err := loadPreviousScan(opts.compareTo)
+			if err != nil {
+				return err
+			}
+			opts.previousScan = previousScan
+
+			switch opts.provider {
+			case "aws":
+				return runScan(cmd, opts)
+			case "gcp":
+				if err := validateGCPOptions(opts); err != nil {
+					return err
+				}
+				return runGCPScan(opts)
+			default:
+				return fmt.Errorf("invalid provider: %s", opts.provider)
+			}
 		},
 	}
 
-	cmd.Flags().StringVar(&opts.regions, "regions", "", "Comma-separated list of regions to scan (default: all available regions)")
+	cmd.Flags().StringVar(&opts.regions, "regions", "", "Comma-separated list of regions to scan, or all-enabled for all regions enabled in the account (default: all-enabled)")
 	cmd.Flags().StringVar(&opts.scanners, "scanners", "", "Comma-separated list of scanners to run (default: all available scanners)")
-	cmd.Flags().StringVar(&opts.output, "output", "filesystem", "Output type (filesystem, s3)")
-	cmd.Flags().StringVarP(&opts.outputFormat, "output-format", "o", "html", "Output format (json, html)")
+	cmd.Flags().StringVar(&opts.output, "output", "filesystem", "Output type (filesystem, s3, s3-account)")
+	cmd.Flags().StringVarP(&opts.outputFormat, "output-format", "o", "html", "Output format (json, html, csv, markdown)")
 	cmd.Flags().StringVar(&opts.bucket, "bucket", "", "S3 bucket name (required when --output=s3)")
 	cmd.Flags().StringVar(&opts.bucketRegion, "bucket-region", "", "S3 bucket region (required when --output=s3)")
 	cmd.Flags().StringVar(&opts.organizationRole, "organization-role", "", "Role to assume for listing organization accounts")
@@ -264,19 +985,164 @@ Examples:
 	cmd.Flags().StringVar(&opts.ignoreResourceNames, "ignore-resource-names", "", "Comma-separated list of resource names to ignore (case-insensitive)")
 	cmd.Flags().StringVar(&opts.ignoreTags, "ignore-tags", "", "Comma-separated list of tags to ignore in KEY=VALUE format (case-insensitive)")
 	cmd.Flags().StringVar(&opts.accounts, "accounts", "", "Comma-separated list of account IDs to scan (default: all accounts in organization)")
+	cmd.Flags().BoolVar(&opts.countResources, "count-resources", false, "Count resources per region with the Resource Groups Tagging API before scanning to report progress by resource")
+	cmd.Flags().BoolVar(&opts.skipEmptyRegions, "skip-empty-regions", false, "Skip region/scanner combinations that contain no resources")
+	cmd.Flags().BoolVar(&opts.excludeASGInstances, "exclude-asg-instances", false, "Exclude instances managed by Auto Scaling groups from EC2 idle detection")
+	cmd.Flags().BoolVar(&opts.excludeSpotInstances, "exclude-spot-instances", false, "Exclude Spot instances, including Spot fleet members, from EC2 idle detection")
+	cmd.Flags().StringVar(&opts.businessHours, "business-hours", "", "Business hours window for utilization analysis, e.g. \"Mon-Fri 08:00-18:00\" (default: disabled)")
+	cmd.Flags().StringVar(&opts.businessHoursTimezone, "business-hours-timezone", "UTC", "IANA timezone of the business hours window (e.g. Europe/Berlin)")
+	cmd.Flags().StringVar(&opts.schedulingPlan, "scheduling-plan", "", "Write an AWS Instance Scheduler compatible scheduling plan to this path (requires --business-hours)")
+	cmd.Flags().StringVar(&opts.reportLanguage, "report-language", "en", "Language of the HTML report (en, de, fr)")
+	cmd.Flags().StringVar(&opts.currency, "currency", "USD", "Currency to report costs in (e.g. EUR); costs are converted from USD")
+	cmd.Flags().Float64Var(&opts.exchangeRate, "exchange-rate", 0, "Static USD exchange rate for --currency (e.g. 0.92 for EUR)")
+	cmd.Flags().StringVar(&opts.exchangeRateURL, "exchange-rate-url", "", "URL returning USD exchange rates as JSON ({\"rates\": {\"EUR\": 0.92}})")
+	cmd.Flags().StringVar(&opts.annotationsFile, "annotations-file", "", "Path to a YAML or JSON file of reviewer annotations keyed by resource ID, merged into reports")
+	cmd.Flags().StringVar(&opts.ssoStartURL, "sso-start-url", "", "IAM Identity Center start URL; scans every account assigned to --sso-role-name using the token cached by 'cloudsift login'")
+	cmd.Flags().StringVar(&opts.ssoRegion, "sso-region", "us-east-1", "Region of the IAM Identity Center instance")
+	cmd.Flags().StringVar(&opts.ssoRoleName, "sso-role-name", "", "IAM Identity Center permission set to use in each account (requires --sso-start-url)")
+	cmd.Flags().BoolVar(&opts.requireReadOnly, "require-read-only", false, "Abort the scan unless the scan credentials are verified to be read-only")
+	cmd.Flags().StringVar(&opts.scannerDaysUnused, "scanner-days-unused", "", "Comma-separated per-scanner overrides of --days-unused in SCANNER=DAYS format (e.g. ebs-snapshots=180,elastic-ips=30)")
+	cmd.Flags().BoolVar(&opts.trimDetails, "trim-details", false, "Keep only a curated set of detail fields in memory and in reports once each scanner task finishes; full details are written to per-account files under output/details")
+	cmd.Flags().BoolVar(&opts.iamLastAccessed, "iam-last-accessed", false, "Confirm unused IAM roles with IAM access advisor service last accessed data before reporting them (slower, requires iam:GenerateServiceLastAccessedDetails)")
+	cmd.Flags().BoolVar(&opts.accessAnalyzer, "access-analyzer", false, "Import active IAM Access Analyzer unused access findings into the IAM results, deduplicated by ARN")
+	cmd.Flags().IntVar(&opts.emrIdleHours, "emr-idle-hours", 4, "Hours an EMR cluster may wait without steps before it is reported as idle")
+	cmd.Flags().BoolVar(&opts.securityHub, "security-hub", false, "Publish findings to AWS Security Hub as custom findings in ASFF format and archive resolved ones (requires securityhub:GetFindings and securityhub:BatchImportFindings)")
+	cmd.Flags().StringVar(&opts.securityHubRegion, "security-hub-region", "us-east-1", "Region findings are imported into with --security-hub")
+	cmd.Flags().BoolVar(&opts.opsCenter, "ops-center", false, "Open AWS Systems Manager OpsCenter OpsItems for findings rated at least --ops-center-min-severity (requires ssm:CreateOpsItem)")
+	cmd.Flags().StringVar(&opts.opsCenterRegion, "ops-center-region", "us-east-1", "Region OpsItems are opened in with --ops-center")
+	cmd.Flags().StringVar(&opts.opsCenterMinSeverity, "ops-center-min-severity", "HIGH", "Lowest finding severity OpsItems are opened for (INFORMATIONAL, LOW, MEDIUM, HIGH)")
+	cmd.Flags().BoolVar(&opts.prefetchPrices, "prefetch-prices", false, "Resolve the prices of all accounts' instances, volumes and databases once in parallel before scanning")
+	cmd.Flags().BoolVar(&opts.includeSuspendedAccounts, "include-suspended-accounts", false, "Scan organization accounts that are suspended or pending closure instead of skipping them")
+	cmd.Flags().BoolVar(&opts.includeMetricSamples, "include-metric-samples", false, "Keep the raw CloudWatch datapoints behind each finding in its details as evidence")
+	cmd.Flags().IntVar(&opts.confirmationScans, "confirmation-scans", 1, "Only report findings that appeared in this many consecutive scans recorded in --history-dir")
+	cmd.Flags().BoolVar(&opts.includeManagedResources, "include-managed-resources", false, "Report AWS-managed resources such as service-linked roles and default VPCs, which are excluded by default")
+	cmd.Flags().BoolVar(&opts.accountSpend, "account-spend", false, "Fetch each account's month-to-date spend from Cost Explorer and show identified waste as a percentage of spend (requires ce:GetCostAndUsage)")
+	cmd.Flags().StringVar(&opts.spendSummaryFile, "spend-summary-file", "", "CSV of month-to-date spend per account, such as a Cost and Usage Report summary, used instead of Cost Explorer with --account-spend")
+	cmd.Flags().BoolVar(&opts.delegatedAdmin, "delegated-admin", false, "List organization accounts with the current credentials of a delegated administrator account instead of assuming --organization-role (requires --scanner-role)")
+	cmd.Flags().StringVar(&opts.accountsFile, "accounts-file", "", "Account list (local path or s3://bucket/key) used when organization accounts cannot be listed, or instead of Organizations without --organization-role and --delegated-admin (requires --scanner-role)")
+	cmd.Flags().StringVar(&opts.dynamoDBTable, "dynamodb-table", "", "DynamoDB table to write each finding to as an item keyed by account (pk) and resource ID#scan time (sk) (requires dynamodb:BatchWriteItem)")
+	cmd.Flags().StringVar(&opts.dynamoDBRegion, "dynamodb-region", "us-east-1", "Region of the --dynamodb-table")
+	cmd.Flags().IntVar(&opts.maxAPICalls, "max-api-calls", 0, "Maximum number of AWS API calls of the scan. Once exceeded, further calls fail, the remaining scanners stop and the findings so far are reported (0 for no limit)")
+	cmd.Flags().StringVar(&opts.reasonVerbosity, "reason-verbosity", "normal", "Detail of the reasons of findings in all outputs: summary (first reason without metric values), normal or debug (with thresholds and metric values)")
+	cmd.Flags().StringVar(&opts.accountBucket, "account-bucket", "", "Template of the bucket each account's results are written to with --output=s3-account, e.g. cloudsift-{{.AccountID}} (fields: AccountID, AccountName, Team, Environment)")
+	cmd.Flags().StringVar(&opts.accountPrefix, "account-prefix", "", "Template of the key prefix of each account's results with --output=s3-account, e.g. finops/{{.Environment}} (same fields as --account-bucket)")
+	cmd.Flags().IntVar(&opts.gracePeriodDays, "grace-period-days", 0, "Never report resources created within this many days, whatever their metrics, as newly provisioned resources look idle while they ramp up (0 to disable)")
+	cmd.Flags().IntVar(&opts.maxWorkersPerAccount, "max-workers-per-account", 0, "Maximum number of scanner tasks run at the same time in an account, e.g. 1 to scan accounts with low API quotas sequentially (0 for no limit beyond --max-workers)")
+	cmd.Flags().BoolVar(&opts.excludeWarmInstances, "exclude-warm-instances", false, "Exclude instances kept stopped in Auto Scaling warm pools and hibernated instances from EC2 idle detection instead of reporting them with a distinct reason")
+	cmd.Flags().StringVar(&opts.provider, "provider", "aws", "Cloud provider to scan (aws, gcp)")
+	cmd.Flags().StringVar(&opts.gcpProjects, "gcp-projects", "", "Comma-separated list of GCP project IDs to scan with --provider=gcp")
+	cmd.Flags().StringVar(&opts.gcpCredentialsFile, "gcp-credentials-file", "", "GCP service account key, authorized user, external account or impersonated service account credentials file (default: application default credentials)")
+	cmd.Flags().BoolVar(&opts.computeOptimizer, "compute-optimizer", false, "Import AWS Compute Optimizer findings on over-provisioned EC2 instances, EBS volumes and Lambda functions into the results, deduplicated by ARN")
+	cmd.Flags().StringVar(&opts.costAllocationTags, "cost-allocation-tags", "", "Comma-separated tag keys the estimated savings are grouped by in the JSON and HTML output for chargeback (e.g. CostCenter,Team)")
+	cmd.Flags().BoolVar(&opts.planOnly, "plan-only", false, "Write the account, region and scanner tasks the scan would run, with resource counts if --count-resources is set, to --plan-file as JSON without scanning")
+	cmd.Flags().StringVar(&opts.planFile, "plan-file", "scan-plan.json", "Path the scan plan of --plan-only is written to")
+	cmd.Flags().BoolVar(&opts.csvPerAccount, "csv-per-account", false, "Write a CSV file per account to reports/csv instead of one combined reports/scan_results.csv with --output-format csv")
+	cmd.Flags().BoolVar(&opts.edgeZones, "edge-zones", false, "Price EC2 instances and EBS volumes in opted-in Local Zones and Wavelength Zones at the prices of their zone instead of their parent region, and label their findings with the zone type")
+	cmd.Flags().BoolVar(&opts.excludeManagementAccount, "exclude-management-account", false, "Skip the management account of the organization, whose findings are otherwise marked as management account findings")
+	cmd.Flags().StringVar(&opts.notifySlackWebhook, "notify-slack-webhook", "", "Slack incoming webhook URL the scan summary is posted to when the scan finishes: potential monthly savings, findings by resource type and a link to the results with --output s3")
+	cmd.Flags().StringVar(&opts.historyDir, "history-dir", "", "Directory to record the metrics and findings of each scan in, listed and compared by the history command, checked for anomalies by the anomalies command and read by --confirmation-scans")
+	registerCompletions(cmd)
+	cmd.Flags().StringVar(&opts.prometheusFile, "prometheus-file", "", "Write the findings and savings by account and resource type and the scan metrics to this file in Prometheus text format when the scan finishes, e.g. for the node_exporter textfile collector")
+	cmd.Flags().StringVar(&opts.prometheusPushgatewayURL, "prometheus-pushgateway-url", "", "Prometheus Pushgateway URL the findings, savings and scan metrics are pushed to under job cloudsift when the scan finishes")
+	cmd.Flags().StringVar(&opts.prometheusListenAddress, "prometheus-listen-address", "", "Address to serve live worker pool metrics and, once the scan finishes, its findings and savings on /metrics while the scan runs, e.g. :9101")
+	cmd.Flags().StringVar(&opts.compareTo, "compare-to", "", "JSON results of a previous scan (a file, or a directory read like the diff command does) to compare the findings with in the HTML report, showing new, resolved and unchanged findings by resource type")
+	cmd.Flags().StringVar(&opts.gcpImpersonateServiceAccount, "gcp-impersonate-service-account", "", "GCP service account to impersonate with the GCP credentials, e.g. scanner@my-project.iam.gserviceaccount.com")
 
 	return cmd
 }
 
 type scanResult struct {
-	AccountID   string                             `json:"account_id"`
-	AccountName string                             `json:"account_name"`
-	Results     map[string]awsinternal.ScanResults `json:"results"` // Map of scanner name to results
+	AccountID          string                             `json:"account_id"`
+	AccountName        string                             `json:"account_name"`
+	AccountTeam        string                             `json:"account_team,omitempty"`
+	AccountEnvironment string                             `json:"account_environment,omitempty"`
+	ManagementAccount  bool                               `json:"management_account,omitempty"`
+	ScannedAt          time.Time                          `json:"scanned_at"`
+	Currency           string                             `json:"currency"` // Currency of all cost figures
+	Results            map[string]awsinternal.ScanResults `json:"results"`  // Map of scanner name to results
+	Errors             []scanError                        `json:"errors,omitempty"`
+	Spend              *awsinternal.AccountSpend          `json:"spend,omitempty"`           // Month-to-date spend in the report currency
+	CostAllocation     []awsinternal.TagCostAllocation    `json:"cost_allocation,omitempty"` // Savings grouped by the cost allocation tags
+}
+
+// skippedAccount records an account that was not scanned and why
+type skippedAccount struct {
+	AccountID   string
+	AccountName string
+	Reason      string
+}
+
+// scanError records a scanner task that failed, including the stack trace if it panicked
+type scanError struct {
+	Scanner string `json:"scanner"`
+	Region  string `json:"region"`
+	Error   string `json:"error"`
+	Stack   string `json:"stack,omitempty"`
+}
+
+// iamScannerLabels are the labels of the scanners of global IAM resources
+var iamScannerLabels = map[string]bool{
+	"IAM Roles":              true,
+	"IAM Users":              true,
+	"IAM Instance Profiles":  true,
+	"IAM Identity Providers": true,
 }
 
 // isIAMScanner returns true if the scanner is for IAM resources
 func isIAMScanner(scanner awsinternal.Scanner) bool {
-	return scanner.Label() == "IAM Roles" || scanner.Label() == "IAM Users"
+	return iamScannerLabels[scanner.Label()]
+}
+
+// isMultiRegionScanner returns true if the scanner compares resources across regions
+func isMultiRegionScanner(scanner awsinternal.Scanner) bool {
+	multiRegion, ok := scanner.(awsinternal.MultiRegionScanner)
+	return ok && multiRegion.MultiRegion()
+}
+
+// reportReasonTemplates returns the configured reason templates for the HTML report
+func reportReasonTemplates() []html.ReasonTemplate {
+	templates := make([]html.ReasonTemplate, 0, len(config.Config.ScanReasonTemplates))
+	for _, rt := range config.Config.ScanReasonTemplates {
+		templates = append(templates, html.ReasonTemplate{
+			ResourceType: rt.ResourceType,
+			Match:        rt.Match,
+			Template:     rt.Template,
+		})
+	}
+	return templates
+}
+
+// parseScannerDaysUnused parses per-scanner days unused overrides in SCANNER=DAYS format
+func parseScannerDaysUnused(value string) (map[string]int, error) {
+	overrides := make(map[string]int)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid days unused override %q: expected SCANNER=DAYS", entry)
+		}
+		days, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid days unused override %q: %w", entry, err)
+		}
+		overrides[strings.TrimSpace(parts[0])] = days
+	}
+	return overrides, nil
+}
+
+// parseTagKeys parses comma-separated tag keys, dropping empty and duplicate keys
+func parseTagKeys(value string) []string {
+	var keys []string
+	for _, key := range strings.Split(value, ",") {
+		key = strings.TrimSpace(key)
+		if key != "" && !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
 }
 
 func getScanners(scannerList string) ([]awsinternal.Scanner, []string, error) {
@@ -285,13 +1151,19 @@ func getScanners(scannerList string) ([]awsinternal.Scanner, []string, error) {
 
 	// If no scanners specified, get all available scanners
 	if scannerList == "" {
-		// If no scanners specified, get all available scanners
+		// If no scanners specified, get all available scanners, or the scanners enabled in the config
 		names := awsinternal.DefaultRegistry.ListScanners()
+		if len(config.Config.ScanEnabledScanners) > 0 {
+			names = config.Config.ScanEnabledScanners
+		}
 		if len(names) == 0 {
 			return nil, nil, fmt.Errorf("no scanners available in registry")
 		}
 
 		for _, name := range names {
+			if scannerDisabled(name) {
+				continue
+			}
 			scanner, err := awsinternal.DefaultRegistry.GetScanner(name)
 			if err != nil {
 				return nil, nil, fmt.Errorf("failed to get scanner '%s': %w", name, err)
@@ -310,15 +1182,137 @@ func getScanners(scannerList string) ([]awsinternal.Scanner, []string, error) {
 			invalidScanners = append(invalidScanners, name)
 			continue
 		}
+		// Scanners disabled in the config are never run, even when selected
+		if scannerDisabled(name) {
+			logging.Warn("Skipping scanner disabled in the config", map[string]interface{}{
+				"scanner": name,
+			})
+			continue
+		}
 		scanners = append(scanners, scanner)
 	}
 
 	return scanners, invalidScanners, nil
 }
 
+// scannerArgumentName returns the argument name of a scanner selected by argument name or
+// alias, or name itself if no scanner has it
+func scannerArgumentName(name string) string {
+	if scanner, err := awsinternal.DefaultRegistry.GetScanner(name); err == nil {
+		return scanner.ArgumentName()
+	}
+	return name
+}
+
+// scannerDisabled reports whether a scanner is disabled in the config
+func scannerDisabled(name string) bool {
+	for _, disabled := range config.Config.ScanDisabledScanners {
+		if scannerArgumentName(disabled) == scannerArgumentName(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// scannerExcluded reports whether a scanner is excluded from an account in the config
+func scannerExcluded(accountID, name string) bool {
+	for _, excluded := range config.Config.ScanAccountScannerExclusions[accountID] {
+		if scannerArgumentName(excluded) == scannerArgumentName(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateScannerSelection checks that the scanners named in the config enable and disable
+// lists and account exclusions exist
+func validateScannerSelection() error {
+	for _, name := range config.Config.ScanEnabledScanners {
+		if _, err := awsinternal.DefaultRegistry.GetScanner(name); err != nil {
+			return fmt.Errorf("invalid scanner in enabled scanners: %s", name)
+		}
+	}
+	for _, name := range config.Config.ScanDisabledScanners {
+		if _, err := awsinternal.DefaultRegistry.GetScanner(name); err != nil {
+			return fmt.Errorf("invalid scanner in disabled scanners: %s", name)
+		}
+	}
+	for accountID, names := range config.Config.ScanAccountScannerExclusions {
+		for _, name := range names {
+			if _, err := awsinternal.DefaultRegistry.GetScanner(name); err != nil {
+				return fmt.Errorf("invalid scanner in exclusions of account %s: %s", accountID, name)
+			}
+		}
+	}
+	return nil
+}
+
+// verifyReadOnly checks that the credentials used for each account cannot modify resources.
+// Credentials that can are reported loudly, and abort the scan when read-only credentials
+// are required. Verification failures only abort the scan when read-only is required. The
+// accounts are verified concurrently in a pre-pass, so large organizations do not wait for
+// one policy simulation after another.
+func verifyReadOnly(accounts []awsinternal.Account, accountSessions map[string]*session.Session, required bool) error {
+	reports := make([]*awsinternal.ReadOnlyReport, len(accounts))
+	errs := make([]error, len(accounts))
+	tasks := make([]worker.Task, 0, len(accounts))
+	for i, account := range accounts {
+		tasks = append(tasks, func(ctx context.Context) error {
+			reports[i], errs[i] = awsinternal.VerifyReadOnly(accountSessions[account.ID])
+			return nil
+		})
+	}
+	runPrePass(tasks)
+
+	verified := make(map[string]bool)
+	for i, account := range accounts {
+		report, err := reports[i], errs[i]
+		if err != nil {
+			if required {
+				return fmt.Errorf("failed to verify read-only credentials for account %s: %w", account.ID, err)
+			}
+			logging.Debug("Unable to verify that scan credentials are read-only", map[string]interface{}{
+				"account_id": account.ID,
+				"error":      err.Error(),
+			})
+			continue
+		}
+		if verified[report.PrincipalARN] {
+			continue
+		}
+		verified[report.PrincipalARN] = true
+
+		if report.ReadOnly() {
+			logging.Debug("Verified scan credentials are read-only", map[string]interface{}{
+				"account_id": account.ID,
+				"principal":  report.PrincipalARN,
+			})
+			continue
+		}
+
+		if required {
+			return fmt.Errorf("credentials for account %s (%s) are not read-only: allowed %s",
+				account.ID, report.PrincipalARN, strings.Join(report.AllowedActions, ", "))
+		}
+		logging.Warn("SCAN CREDENTIALS ARE NOT READ-ONLY: use a read-only role for scanning", map[string]interface{}{
+			"account_id":           account.ID,
+			"principal":            report.PrincipalARN,
+			"allowed_actions":      report.AllowedActions,
+			"permissions_boundary": report.HasPermissionBoundary,
+		})
+		if !report.HasPermissionBoundary {
+			logging.Warn("Scan principal with write access has no permissions boundary", map[string]interface{}{
+				"account_id": account.ID,
+				"principal":  report.PrincipalARN,
+			})
+		}
+	}
+	return nil
+}
+
 func runScan(cmd *cobra.Command, opts *scanOptions) error {
-	// Validate S3 access first if using S3 output
-	if opts.output == "s3" {
+	// Validate S3 access first if using S3 output, a plan-only run writes nothing there
+	if opts.output == "s3" && !opts.planOnly {
 		if opts.bucket == "" {
 			return fmt.Errorf("S3 bucket not specified. Use --bucket flag to specify the S3 bucket")
 		}
@@ -354,10 +1348,29 @@ func runScan(cmd *cobra.Command, opts *scanOptions) error {
 	var baseSession *session.Session
 	var accounts []awsinternal.Account
 
+	// With IAM Identity Center, accounts come from the permission set assignments of the
+	// signed-in user and sessions use the permission set instead of scanner roles
+	useSSO := opts.ssoStartURL != ""
+	if useSSO {
+		accounts, err = awsinternal.ListSSOAccounts(opts.ssoStartURL, opts.ssoRegion)
+		if err != nil {
+			return fmt.Errorf("failed to list IAM Identity Center accounts: %w", err)
+		}
+		if len(accounts) == 0 {
+			return fmt.Errorf("no accounts are assigned to the signed-in IAM Identity Center user")
+		}
+	}
+
 	// Create a session with organization role for cost estimator
 	var costEstimatorSession *session.Session
 	var costErr error
-	if opts.organizationRole != "" {
+	if useSSO {
+		costEstimatorSession, costErr = awsinternal.NewSSOSession(opts.ssoStartURL, opts.ssoRegion, accounts[0].ID, opts.ssoRoleName, "us-east-1")
+		if costErr != nil {
+			logging.Error("Failed to create cost estimator session", costErr, nil)
+			return nil // Return nil to continue without failing
+		}
+	} else if opts.organizationRole != "" {
 		costEstimatorSession, costErr = awsinternal.GetSessionChain(opts.organizationRole, "", "", "us-east-1")
 		if costErr != nil {
 			logging.Error("Failed to create cost estimator session with org role", costErr, map[string]interface{}{
@@ -384,8 +1397,16 @@ func runScan(cmd *cobra.Command, opts *scanOptions) error {
 		logging.Error("Failed to initialize cost estimator", err, nil)
 		return nil // Return nil to continue without failing
 	}
+	if awsinternal.DefaultCostEstimator != nil {
+		if err := awsinternal.DefaultCostEstimator.SetCostMultipliers(config.Config.ScanCostOverrides); err != nil {
+			return fmt.Errorf("invalid cost overrides: %w", err)
+		}
+	}
 
-	if opts.organizationRole != "" && opts.scannerRole != "" {
+	if useSSO {
+		// Account sessions are created per permission set below
+		baseSession = costEstimatorSession
+	} else if opts.organizationRole != "" && opts.scannerRole != "" {
 		logging.Info("Creating organization session", map[string]interface{}{
 			"organization_role": opts.organizationRole,
 			"scanner_role":      opts.scannerRole,
@@ -415,20 +1436,47 @@ func runScan(cmd *cobra.Command, opts *scanOptions) error {
 	}
 
 	// Get accounts
-	if opts.organizationRole != "" && opts.scannerRole != "" {
-		accounts, err = awsinternal.ListAccountsWithSession(baseSession)
+	if useSSO {
+		logging.Debug("Using IAM Identity Center accounts", map[string]interface{}{
+			"account_count": len(accounts),
+		})
+	} else if (opts.organizationRole != "" || opts.delegatedAdmin) && opts.scannerRole != "" {
+		accounts, err = awsinternal.CachedAccounts(awsinternal.AccountCacheFile,
+			awsinternal.AccountCacheKey(config.Config.Profile, opts.organizationRole),
+			config.Config.AccountsCacheTTL, config.Config.RefreshAccounts,
+			func() ([]awsinternal.Account, error) {
+				return awsinternal.ListAccountsWithSession(baseSession)
+			})
 		if err != nil {
 			logging.Error("Failed to list organization accounts", err, map[string]interface{}{
 				"organization_role": opts.organizationRole,
+				"delegated_admin":   opts.delegatedAdmin,
 			})
-			// Fall back to current account
-			logging.Info("Falling back to current account")
-			accounts, err = awsinternal.ListCurrentAccount(baseSession)
-			if err != nil {
-				logging.Error("Failed to get current account", err, nil)
-				return nil // Return nil to continue without failing
+			if opts.accountsFile != "" {
+				// Fall back to the account list
+				logging.Info("Falling back to account list", map[string]interface{}{
+					"accounts_file": opts.accountsFile,
+				})
+				accounts, err = awsinternal.LoadAccountList(baseSession, opts.accountsFile)
+				if err != nil {
+					return fmt.Errorf("failed to load account list: %w", err)
+				}
+			} else {
+				// Fall back to current account
+				logging.Info("Falling back to current account")
+				accounts, err = awsinternal.ListCurrentAccount(baseSession)
+				if err != nil {
+					logging.Error("Failed to get current account", err, nil)
+					return nil // Return nil to continue without failing
+				}
 			}
 		}
+	} else if opts.accountsFile != "" && opts.scannerRole != "" {
+		// Scan the listed accounts without the Organizations API
+		accounts, err = awsinternal.LoadAccountList(baseSession, opts.accountsFile)
+		if err != nil {
+			return fmt.Errorf("failed to load account list: %w", err)
+		}
 	} else {
 		// Get current account only
 		accounts, err = awsinternal.ListCurrentAccount(baseSession)
@@ -438,6 +1486,21 @@ func runScan(cmd *cobra.Command, opts *scanOptions) error {
 		}
 	}
 
+	// Apply friendly names, teams and environments from the account mappings
+	accounts = awsinternal.ApplyAccountMappings(accounts, config.Config.AccountMappings)
+
+	// Findings of the management account are marked, since remediation rules differ there. Its
+	// exclusion requires knowing which account it is.
+	accounts, err = awsinternal.MarkManagementAccount(baseSession, accounts, opts.excludeManagementAccount)
+	if err != nil {
+		return fmt.Errorf("refusing to scan with --exclude-management-account: %w", err)
+	}
+
+	// Output overrides matching organizational units need the units containing each account
+	if overridesMatchOrganizationalUnits(config.Config.ScanOutputOverrides) {
+		accounts = awsinternal.SetOrganizationalUnits(baseSession, accounts)
+	}
+
 	// Filter accounts by specified account IDs
 	if opts.accounts != "" {
 		requestedAccounts := strings.Split(opts.accounts, ",")
@@ -478,15 +1541,66 @@ func runScan(cmd *cobra.Command, opts *scanOptions) error {
 		}
 	}
 
+	// Skip suspended accounts and accounts pending closure, since roles cannot be assumed in
+	// them, unless they are included intentionally
+	var skippedAccounts []skippedAccount
+	if !opts.includeSuspendedAccounts {
+		var activeAccounts []awsinternal.Account
+		for _, account := range accounts {
+			if account.Active() {
+				activeAccounts = append(activeAccounts, account)
+				continue
+			}
+			skippedAccounts = append(skippedAccounts, skippedAccount{
+				AccountID:   account.ID,
+				AccountName: account.Name,
+				Reason:      fmt.Sprintf("account status is %s", account.Status),
+			})
+		}
+		accounts = activeAccounts
+	}
+
+	// Skip the management account of the organization if scanning it is against policy
+	var skippedManagement []skippedAccount
+	accounts, skippedManagement = filterManagementAccount(accounts, opts.excludeManagementAccount)
+	skippedAccounts = append(skippedAccounts, skippedManagement...)
+
 	// Create sessions for each account
 	accountSessions := make(map[string]*session.Session)
 	var authenticatedAccounts []awsinternal.Account // Track accounts that successfully authenticated
 	for _, account := range accounts {
-		if opts.organizationRole != "" && opts.scannerRole != "" {
+		if useSSO {
+			scanSession, err := awsinternal.NewSSOSession(opts.ssoStartURL, opts.ssoRegion, account.ID, opts.ssoRoleName, "")
+			if err == nil {
+				_, err = sts.New(scanSession, awsinternal.STSConfig(scanSession)).GetCallerIdentity(&sts.GetCallerIdentityInput{})
+			}
+			if err != nil {
+				logging.Warn("Failed to use permission set", map[string]interface{}{
+					"error":          err.Error(),
+					"account_id":     account.ID,
+					"account_name":   account.Name,
+					"permission_set": opts.ssoRoleName,
+				})
+				skippedAccounts = append(skippedAccounts, skippedAccount{
+					AccountID:   account.ID,
+					AccountName: account.Name,
+					Reason:      fmt.Sprintf("failed to use permission set: %v", err),
+				})
+				continue // Skip this account
+			}
+			logging.Info("Successfully used permission set", map[string]interface{}{
+				"account_id":     account.ID,
+				"account_name":   account.Name,
+				"permission_set": opts.ssoRoleName,
+			})
+
+			accountSessions[account.ID] = scanSession
+			authenticatedAccounts = append(authenticatedAccounts, account)
+		} else if opts.scannerRole != "" && (opts.organizationRole != "" || opts.delegatedAdmin || opts.accountsFile != "") {
 			// Assume scanner role in target account using org session
-			scannerRoleARN := fmt.Sprintf("arn:aws:iam::%s:role/%s", account.ID, opts.scannerRole)
-			scannerCreds := stscreds.NewCredentials(baseSession, scannerRoleARN)
-			scanSession, err := session.NewSession(aws.NewConfig().WithCredentials(scannerCreds))
+			scannerRoleARN := awsinternal.RoleARN(aws.StringValue(baseSession.Config.Region), account.ID, opts.scannerRole)
+			scannerCreds := awsinternal.AssumeRoleCredentials(baseSession, scannerRoleARN)
+			scanSession, err := session.NewSession(awsinternal.SessionConfig().WithCredentials(scannerCreds))
 			if err != nil {
 				logging.Warn("Failed to assume scanner role", map[string]interface{}{
 					"error":        err.Error(),
@@ -494,11 +1608,16 @@ func runScan(cmd *cobra.Command, opts *scanOptions) error {
 					"account_name": account.Name,
 					"role_arn":     scannerRoleARN,
 				})
+				skippedAccounts = append(skippedAccounts, skippedAccount{
+					AccountID:   account.ID,
+					AccountName: account.Name,
+					Reason:      fmt.Sprintf("failed to assume scanner role: %v", err),
+				})
 				continue // Skip this account
 			}
 
 			// Verify scanner role assumption
-			stsSvc := sts.New(scanSession)
+			stsSvc := sts.New(scanSession, awsinternal.STSConfig(scanSession))
 			identity, err := stsSvc.GetCallerIdentity(&sts.GetCallerIdentityInput{})
 			if err != nil {
 				logging.Warn("Failed to verify scanner role assumption", map[string]interface{}{
@@ -507,6 +1626,11 @@ func runScan(cmd *cobra.Command, opts *scanOptions) error {
 					"account_name": account.Name,
 					"role_arn":     scannerRoleARN,
 				})
+				skippedAccounts = append(skippedAccounts, skippedAccount{
+					AccountID:   account.ID,
+					AccountName: account.Name,
+					Reason:      fmt.Sprintf("failed to assume scanner role: %v", err),
+				})
 				continue // Skip this account
 			}
 			logging.Info("Successfully assumed scanner role", map[string]interface{}{
@@ -525,16 +1649,35 @@ func runScan(cmd *cobra.Command, opts *scanOptions) error {
 	}
 
 	if len(accountSessions) == 0 {
+		reportSkippedAccounts(skippedAccounts)
 		logging.Warn("No valid sessions created for any accounts, scan will be skipped", nil)
 		return nil
 	}
 
 	// Use only authenticated accounts from here on
 	accounts = authenticatedAccounts
+	accountIDs := make([]string, 0, len(accounts))
+	for _, account := range accounts {
+		accountIDs = append(accountIDs, account.ID)
+	}
+
+	// Scanners share intermediate data per account and region for the duration of the run
+	scanContexts := awsinternal.NewScanContexts()
+
+	// Count the API calls of the scan from here on and stop calling once over the budget
+	apiCalls := awsinternal.NewAPICallTracker(int64(opts.maxAPICalls))
+	apiCalls.Attach(baseSession)
+	for _, accountSession := range accountSessions {
+		apiCalls.Attach(accountSession)
+	}
+	awsinternal.SetScanAPICallTracker(apiCalls)
+	defer awsinternal.SetScanAPICallTracker(nil)
+	var budgetSkippedTasks int64
 
 	// Get and validate regions
 	var regions []string
-	if opts.regions == "" {
+	explicitRegions := opts.regions != "" && opts.regions != awsinternal.AllEnabledRegions
+	if !explicitRegions {
 		// If no regions specified, get all available regions
 		regions, err = awsinternal.GetAvailableRegions(accountSessions[accounts[0].ID])
 		if err != nil {
@@ -552,20 +1695,130 @@ func runScan(cmd *cobra.Command, opts *scanOptions) error {
 		}
 	}
 
+	// FIPS endpoints only exist in some regions, so requested regions without them are
+	// refused and other regions are left out
+	if config.Config.UseFIPSEndpoints {
+		if config.Config.STSRegion != "" {
+			if err := awsinternal.ValidateFIPSEndpoints([]string{"sts"}, []string{config.Config.STSRegion}); err != nil {
+				return err
+			}
+		}
+		if explicitRegions {
+			if err := awsinternal.ValidateFIPSEndpoints(awsinternal.FIPSRegionServices, regions); err != nil {
+				return err
+			}
+		} else {
+			var skipped []string
+			regions, skipped = awsinternal.FIPSRegions(regions)
+			if len(skipped) > 0 {
+				logging.Info("Skipping regions without FIPS endpoints", map[string]interface{}{
+					"regions": skipped,
+				})
+			}
+		}
+	}
+
+	// Only write the tasks the scan would run for review, without verifying credentials, looking
+	// up prices or exchange rates, or scanning
+	if opts.planOnly {
+		var resourceCounts *awsinternal.ResourceCounts
+		if opts.countResources {
+			resourceCounts = countResources(accounts, accountSessions, regions, scanners)
+		}
+		var emptyTasks map[string]bool
+		if opts.skipEmptyRegions {
+			emptyTasks = findEmptyTasks(accounts, accountSessions, regions, scanners, resourceCounts, scanContexts)
+		}
+		plan := buildScanPlan(scanTasks(accounts, regions, scanners, emptyTasks), regions, scanners, resourceCounts)
+		if err := writeScanPlan(plan, opts.planFile); err != nil {
+			return err
+		}
+		fmt.Printf("Scan plan of %d tasks in %d accounts written to %s\n", plan.TotalTasks, plan.Accounts, opts.planFile)
+		return nil
+	}
+
+	// Verify the scan credentials cannot modify resources
+	if err := verifyReadOnly(accounts, accountSessions, opts.requireReadOnly); err != nil {
+		return err
+	}
+
 	// Initialize results map
 	accountResults := make(map[string]*scanResult)
 	for _, account := range accounts {
 		accountResults[account.ID] = &scanResult{
-			AccountID:   account.ID,
-			AccountName: account.Name,
-			Results:     make(map[string]awsinternal.ScanResults),
+			AccountID:          account.ID,
+			AccountName:        account.Name,
+			AccountTeam:        account.Team,
+			AccountEnvironment: account.Environment,
+			ManagementAccount:  account.Management,
+			Results:            make(map[string]awsinternal.ScanResults),
+		}
+	}
+
+	// Stream full result details to disk and only keep trimmed details in memory
+	var detailsSpool *output.DetailsSpool
+	if opts.trimDetails {
+		detailsSpool, err = output.NewDetailsSpool(filepath.Join("output", "details", time.Now().Format("2006-01-02T15-04-05")))
+		if err != nil {
+			return err
 		}
 	}
 
+	// Resolve the exchange rate used to report costs in the requested currency
+	converter, err := newCurrencyConverter(opts)
+	if err != nil {
+		return err
+	}
+
+	// Load reviewer annotations so triage decisions carry over into this report
+	var annotationStore annotations.Store
+	if opts.annotationsFile != "" {
+		annotationStore, err = annotations.Load(opts.annotationsFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Compile the list of AWS-managed resources excluded from results
+	var managedResources *awsinternal.ManagedResourceMatcher
+	if !opts.includeManagedResources {
+		managedResources, err = awsinternal.NewManagedResourceMatcher(config.Config.ScanManagedResources)
+		if err != nil {
+			return fmt.Errorf("invalid managed resources: %w", err)
+		}
+	}
+
+	// Load the scan history used to confirm findings across consecutive scans from the
+	// recorded runs
+	if opts.confirmationScans > 1 && opts.historyDir == "" {
+		return fmt.Errorf("--confirmation-scans requires --history-dir")
+	}
+	var historyStore *history.Store
+	historyScope := history.NewScope()
+	if opts.confirmationScans > 1 {
+		historyStore, err = history.LoadStore(opts.historyDir)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Parse the business hours window used for utilization analysis
+	var businessHours *awsinternal.BusinessHours
+	if opts.businessHours != "" {
+		businessHours, err = awsinternal.ParseBusinessHours(opts.businessHours, opts.businessHoursTimezone)
+		if err != nil {
+			return err
+		}
+	}
+	if opts.schedulingPlan != "" && businessHours == nil {
+		return fmt.Errorf("--scheduling-plan requires --business-hours")
+	}
+
 	// Create tasks for each scanner+region+account combination
-	var tasks []worker.Task
+	var tasks []worker.KeyedTask
 	var resultsMutex sync.Mutex
 	progressMap := newScannerProgressMap()
+	timings := &taskTimings{}
 	actualTasks := 0
 
 	// Initialize shared worker pool
@@ -573,11 +1826,13 @@ func runScan(cmd *cobra.Command, opts *scanOptions) error {
 		return fmt.Errorf("failed to initialize worker pool: %w", err)
 	}
 	workerPool := worker.GetSharedPool()
+	accountLimiter := worker.NewKeyedLimiter(config.Config.ScanMaxWorkersPerAccount, config.Config.ScanAccountMaxWorkers)
 
 	// Log scan start with configuration
-	var scannerNames []string
+	var scannerNames, scannerArgs []string
 	for _, s := range scanners {
 		scannerNames = append(scannerNames, s.Label())
+		scannerArgs = append(scannerArgs, s.ArgumentName())
 	}
 
 	// Convert accounts to the format expected by the logger
@@ -592,6 +1847,33 @@ func runScan(cmd *cobra.Command, opts *scanOptions) error {
 	startTime := time.Now()
 	logging.ScanStart(scannerNames, accountInfo, regions)
 
+	// Results record when they were scanned, so remediation can refuse stale ones
+	for _, result := range accountResults {
+		result.ScannedAt = startTime.UTC()
+	}
+
+	// Optionally count resources up front so progress reflects resources rather than tasks
+	var resourceCounts *awsinternal.ResourceCounts
+	progress := &overallProgress{unit: "tasks"}
+	if opts.countResources {
+		resourceCounts = countResources(accounts, accountSessions, regions, scanners)
+		progress.unit = "resources"
+	}
+	progressMap.overall = progress
+	progressMap.observer = opts.observer
+
+	// Optionally prune region/scanner combinations that contain no resources
+	var emptyTasks map[string]bool
+	if opts.skipEmptyRegions {
+		emptyTasks = findEmptyTasks(accounts, accountSessions, regions, scanners, resourceCounts, scanContexts)
+		addEmptyTasksToScope(historyScope, emptyTasks, scanners)
+	}
+
+	// Optionally resolve the prices of all accounts once before the scanners need them
+	if opts.prefetchPrices {
+		prefetchPrices(accounts, accountSessions, regions, scanners)
+	}
+
 	// Start progress logger
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -620,6 +1902,12 @@ func runScan(cmd *cobra.Command, opts *scanOptions) error {
 						// Log header with detailed worker stats
 						logging.Progress(fmt.Sprintf("Pending Scanners (Workers: %d active (%d%% utilized), %d idle of %d total):",
 							activeWorkers, int(utilization), freeWorkers, maxWorkers), nil)
+						logging.Progress(fmt.Sprintf("  Overall: %.1f%% complete (%d/%d %s)",
+							progress.percent(),
+							atomic.LoadInt64(&progress.completed),
+							atomic.LoadInt64(&progress.total),
+							progress.unit,
+						), nil)
 
 						// Sort scanners by account ID and scanner name for consistent output
 						sort.Slice(running, func(i, j int) bool {
@@ -632,7 +1920,7 @@ func runScan(cmd *cobra.Command, opts *scanOptions) error {
 						// Log each scanner on its own line
 						for _, prog := range running {
 							region := prog.Region
-							if region == "us-east-1" && (prog.Scanner == "IAM Roles" || prog.Scanner == "IAM Users") {
+							if region == "us-east-1" && iamScannerLabels[prog.Scanner] {
 								region = "global"
 							}
 
@@ -662,158 +1950,206 @@ func runScan(cmd *cobra.Command, opts *scanOptions) error {
 		}
 	}()
 
-	for _, scanner := range scanners {
-		// For IAM scanners, we only need to scan us-east-1 since IAM is global
-		scanRegions := regions
-		if isIAMScanner(scanner) {
-			scanRegions = []string{"us-east-1"}
+	for _, task := range scanTasks(accounts, regions, scanners, emptyTasks) {
+		actualTasks++
+		scanner := task.scanner
+		region := task.region
+		account := task.account
+
+		// Weight the task by its expected resource count when known
+		weight := int64(1)
+		if resourceCounts != nil {
+			if count, ok := resourceCounts.Get(account.ID, region, scanner.ArgumentName()); ok {
+				weight = int64(count)
+			}
 		}
+		progress.add(weight)
 
-		for _, region := range scanRegions {
-			for _, account := range accounts {
-				actualTasks++
-				scanner := scanner // Create new variable for closure
-				region := region
-				account := account
-
-				tasks = append(tasks, worker.Task(func(ctx context.Context) error {
-					// For IAM scanners, always log region as "global"
-					logRegion := region
-					if isIAMScanner(scanner) {
-						logRegion = "global"
-					}
-					logging.ScannerStart(scanner.Label(), account.ID, account.Name, logRegion)
-
-					// Start tracking scanner progress
-					progressMap.startScanner(account.ID, account.Name, logRegion, scanner.Label())
-					defer progressMap.completeScanner(account.ID, logRegion, scanner.Label())
+		tasks = append(tasks, worker.KeyedTask{Key: account.ID, Task: func(ctx context.Context) (err error) {
+			// For IAM and multi-region scanners, log region as the global label of the partition
+			logRegion := region
+			if isIAMScanner(scanner) || isMultiRegionScanner(scanner) {
+				logRegion = awsinternal.GlobalRegionLabel(awsinternal.RegionPartition(region))
+			}
 
-					// Get the account's base session and create regional session
-					scanSession := accountSessions[account.ID]
-					regionSession, err := awsinternal.GetSessionInRegion(scanSession, region)
-					if err != nil {
-						logging.ScannerError(scanner.Label(), account.ID, account.Name, logRegion, err)
-						return fmt.Errorf("failed to create regional session for account %s: %w", account.ID, err)
+			// Complete the scanner once the task counts towards the overall progress
+			defer progressMap.completeScanner(account.ID, logRegion, scanner.Label())
+			defer progress.complete(weight)
+
+			// Record the wall-clock duration of the task
+			taskStart := time.Now()
+			defer func() {
+				timings.record(taskTiming{
+					Scanner:     scanner.Label(),
+					AccountID:   account.ID,
+					AccountName: account.Name,
+					Region:      logRegion,
+					Duration:    time.Since(taskStart),
+					Failed:      err != nil,
+				})
+				if err != nil {
+					failure := scanError{
+						Scanner: scanner.Label(),
+						Region:  logRegion,
+						Error:   err.Error(),
 					}
-					logging.Debug("Created regional session", map[string]interface{}{
-						"region": region,
-					})
-
-					results, err := scanner.Scan(awsinternal.ScanOptions{
-						Region:     region,
-						DaysUnused: opts.daysUnused,
-						Session:    regionSession,
-					})
-					if err != nil {
-						logging.ScannerError(scanner.Label(), account.ID, account.Name, logRegion, err)
-						return err
+					var panicErr *worker.PanicError
+					if errors.As(err, &panicErr) {
+						failure.Stack = panicErr.Stack
 					}
+					resultsMutex.Lock()
+					accountResults[account.ID].Errors = append(accountResults[account.ID].Errors, failure)
+					resultsMutex.Unlock()
+				}
+			}()
+			// A panicking scanner fails only this task, the rest of the scan continues
+			defer worker.Recover(&err)
+
+			// Once the API call budget is exceeded, the remaining tasks are skipped
+			if apiCalls.Exceeded() {
+				atomic.AddInt64(&budgetSkippedTasks, 1)
+				return nil
+			}
+			logging.ScannerStart(scanner.Label(), account.ID, account.Name, logRegion)
 
-					// Filter results based on ignore list
-					var filteredResults awsinternal.ScanResults
-					for _, result := range results {
-						// Check if resource ID is in ignore list
-						shouldIgnore := false
-						for _, ignoreID := range config.Config.ScanIgnoreResourceIDs {
-							if strings.EqualFold(result.ResourceID, ignoreID) {
-								logging.Debug("Ignoring resource by ID", map[string]interface{}{
-									"resource_id": result.ResourceID,
-									"scanner":     scanner.Label(),
-									"account_id":  account.ID,
-									"region":      logRegion,
-								})
-								shouldIgnore = true
-								break
-							}
-						}
+			// Start tracking scanner progress
+			progressMap.startScanner(account.ID, account.Name, logRegion, scanner.Label())
 
-						// Check if resource name is in ignore list
-						if !shouldIgnore {
-							for _, ignoreName := range config.Config.ScanIgnoreResourceNames {
-								if strings.EqualFold(result.ResourceName, ignoreName) {
-									logging.Debug("Ignoring resource by name", map[string]interface{}{
-										"resource_name": result.ResourceName,
-										"scanner":       scanner.Label(),
-										"account_id":    account.ID,
-										"region":        logRegion,
-									})
-									shouldIgnore = true
-									break
-								}
-							}
-						}
+			// Get the account's base session and create regional session
+			scanSession := accountSessions[account.ID]
+			regionSession, err := awsinternal.GetSessionInRegion(scanSession, region)
+			if err != nil {
+				logging.ScannerError(scanner.Label(), account.ID, account.Name, logRegion, err)
+				return fmt.Errorf("failed to create regional session for account %s: %w", account.ID, err)
+			}
+			logging.Debug("Created regional session", map[string]interface{}{
+				"region": region,
+			})
 
-						// Check if any resource tags match ignore list
-						if !shouldIgnore && len(result.Tags) > 0 {
-							for ignoreKey, ignoreValue := range config.Config.ScanIgnoreTags {
-								// Convert tag key and value to lowercase for case-insensitive comparison
-								for tagKey, tagValue := range result.Tags {
-									if strings.EqualFold(tagKey, ignoreKey) && strings.EqualFold(tagValue, ignoreValue) {
-										logging.Debug("Ignoring resource by tag", map[string]interface{}{
-											"resource_id": result.ResourceID,
-											"tag_key":     ignoreKey,
-											"tag_value":   ignoreValue,
-											"scanner":     scanner.Label(),
-											"account_id":  account.ID,
-											"region":      logRegion,
-										})
-										shouldIgnore = true
-										break
-									}
-								}
-								if shouldIgnore {
-									break
-								}
-							}
-						}
+			// Apply the scanner's days unused override, if any
+			daysUnused := opts.daysUnused
+			if override, ok := config.Config.ScanScannerDaysUnused[scanner.ArgumentName()]; ok {
+				daysUnused = override
+			}
 
-						if !shouldIgnore {
-							filteredResults = append(filteredResults, result)
-						}
-					}
+			results, err := scanner.Scan(awsinternal.ScanOptions{
+				Region:               region,
+				DaysUnused:           daysUnused,
+				Session:              regionSession,
+				AccountID:            account.ID,
+				KnownAccountIDs:      accountIDs,
+				Regions:              regions,
+				ExcludeASGInstances:  opts.excludeASGInstances,
+				ExcludeSpotInstances: opts.excludeSpotInstances,
+				ExcludeWarmInstances: opts.excludeWarmInstances,
+				BusinessHours:        businessHours,
+				MarketplaceRates:     config.Config.ScanMarketplaceRates,
+				IAMLastAccessed:      opts.iamLastAccessed,
+				EMRIdleHours:         opts.emrIdleHours,
+				IncludeMetricSamples: opts.includeMetricSamples,
+				EdgeZones:            opts.edgeZones,
+				Contexts:             scanContexts,
+				Scanners:             scannerArgs,
+			})
+			if err != nil {
+				logging.ScannerError(scanner.Label(), account.ID, account.Name, logRegion, err)
+				return err
+			}
+			awsinternal.AddSuggestedRemediation(scanner, results, region)
 
-					// Update result count with filtered results
-					progressMap.updateResultCount(account.ID, logRegion, scanner.Label(), len(filteredResults))
+			// Only scanners that completed cover their findings in the history. Multi-region
+			// scanners report resources of all the regions they inspect.
+			scopeRegion := logRegion
+			if isMultiRegionScanner(scanner) {
+				scopeRegion = ""
+			}
+			historyScope.Add(account.ID, scanner.Label(), scopeRegion)
+
+			// Filter results based on ignore list
+			var filteredResults awsinternal.ScanResults
+			for _, result := range results {
+				if message, fields := ignoredResult(result, managedResources, opts.gracePeriodDays); message != "" {
+					fields["scanner"] = scanner.Label()
+					fields["account_id"] = account.ID
+					fields["region"] = logRegion
+					logging.Debug(message, fields)
+					continue
+				}
+				filteredResults = append(filteredResults, result)
+			}
 
-					// Add account and region info to each result
-					for i := range filteredResults {
-						if filteredResults[i].Details == nil {
-							filteredResults[i].Details = make(map[string]interface{})
-						}
-						filteredResults[i].AccountID = account.ID
-						filteredResults[i].AccountName = account.Name
-						// For IAM scanners, set region as "global", otherwise use actual region
-						if isIAMScanner(scanner) {
-							filteredResults[i].Details["region"] = "global"
-						} else {
-							filteredResults[i].Details["region"] = region
-						}
-					}
+			// Update result count with filtered results
+			progressMap.updateResultCount(account.ID, logRegion, scanner.Label(), len(filteredResults))
 
-					// Safely append results
-					resultsMutex.Lock()
-					if accountResults[account.ID].Results[scanner.Label()] == nil {
-						accountResults[account.ID].Results[scanner.Label()] = filteredResults
-					} else {
-						accountResults[account.ID].Results[scanner.Label()] = append(accountResults[account.ID].Results[scanner.Label()], filteredResults...)
-					}
-					resultsMutex.Unlock()
+			// Add account and region info to each result
+			for i := range filteredResults {
+				if filteredResults[i].Details == nil {
+					filteredResults[i].Details = make(map[string]interface{})
+				}
+				filteredResults[i].AccountID = account.ID
+				filteredResults[i].AccountName = account.Name
+				filteredResults[i].AccountTeam = account.Team
+				filteredResults[i].AccountEnv = account.Environment
+				filteredResults[i].Management = account.Management
+				// For IAM scanners, set region as the global label of the partition. Multi-region
+				// scanners report the region of each resource, otherwise use actual region.
+				if isIAMScanner(scanner) {
+					filteredResults[i].Details["region"] = logRegion
+				} else if !isMultiRegionScanner(scanner) {
+					filteredResults[i].Details["region"] = region
+				}
+			}
+			warnMalformedResults(scanner.Label(), account.ID, logRegion, filteredResults)
 
-					// Log completion with results
-					resultInterfaces := make([]interface{}, len(filteredResults))
-					for i, r := range filteredResults {
-						resultInterfaces[i] = r
-					}
-					logging.ScannerComplete(scanner.Label(), account.ID, account.Name, logRegion, resultInterfaces)
+			if detailsSpool != nil {
+				if err := detailsSpool.Write(account.ID, filteredResults); err != nil {
+					logging.Error("Failed to write result details", err, map[string]interface{}{
+						"account_id": account.ID,
+						"scanner":    scanner.Label(),
+					})
+				}
+			}
 
-					return nil
-				}))
+			// Safely append results
+			resultsMutex.Lock()
+			if accountResults[account.ID].Results[scanner.Label()] == nil {
+				accountResults[account.ID].Results[scanner.Label()] = filteredResults
+			} else {
+				accountResults[account.ID].Results[scanner.Label()] = append(accountResults[account.ID].Results[scanner.Label()], filteredResults...)
+			}
+			resultsMutex.Unlock()
+
+			// Log completion with results
+			resultInterfaces := make([]interface{}, len(filteredResults))
+			for i, r := range filteredResults {
+				resultInterfaces[i] = r
 			}
+			logging.ScannerComplete(scanner.Label(), account.ID, account.Name, logRegion, resultInterfaces)
+
+			return nil
+		}})
+	}
+
+	// Serve the metrics of the running scan, and of the finished scan once they are stored
+	var finishedSummary atomic.Pointer[metrics.ScanSummary]
+	if opts.prometheusListenAddress != "" {
+		server, err := servePrometheusMetrics(opts.prometheusListenAddress, workerPool, len(tasks), &finishedSummary)
+		if err != nil {
+			return err
 		}
+		defer server.Close()
 	}
 
-	// Execute tasks using the worker pool
-	workerPool.ExecuteTasks(tasks)
+	// Execute tasks using the worker pool, limiting the concurrent tasks of each account
+	workerPool.ExecuteKeyedTasks(tasks, accountLimiter)
+
+	if detailsSpool != nil {
+		if err := detailsSpool.Close(); err != nil {
+			logging.Error("Failed to close result details files", err, nil)
+		} else {
+			fmt.Printf("Full result details written to %s\n", detailsSpool.Dir())
+		}
+	}
 
 	// Verify task count matches expected scans
 	metrics := workerPool.GetMetrics()
@@ -829,7 +2165,445 @@ func runScan(cmd *cobra.Command, opts *scanOptions) error {
 		"worker_utilization": float64(metrics.PeakWorkers) / float64(config.Config.MaxWorkers) * 100,
 	})
 
-	// Output results
+	// Log the API calls of the scan and what the billed ones cost
+	apiCallFields := map[string]interface{}{
+		"total_calls":        apiCalls.Total(),
+		"estimated_cost_usd": fmt.Sprintf("%.4f", apiCalls.EstimatedCost()),
+	}
+	for _, count := range apiCalls.Services() {
+		apiCallFields["calls_"+count.Service] = count.Calls
+	}
+	logging.Info("API call metrics", apiCallFields)
+	if apiCalls.Exceeded() {
+		logging.Warn("API call budget exceeded, the scan is incomplete", map[string]interface{}{
+			"max_api_calls": apiCalls.Budget(),
+			"skipped_tasks": atomic.LoadInt64(&budgetSkippedTasks),
+		})
+	}
+
+	// Log the slowest tasks so that scanners or accounts dominating the runtime stand out
+	slowestTasks := timings.slowest(slowestTaskCount)
+	for i, timing := range slowestTasks {
+		logging.Info("Slow task", map[string]interface{}{
+			"rank":         i + 1,
+			"scanner":      timing.Scanner,
+			"account_id":   timing.AccountID,
+			"account_name": timing.AccountName,
+			"region":       timing.Region,
+			"duration_ms":  timing.Duration.Milliseconds(),
+			"failed":       timing.Failed,
+		})
+	}
+
+	// Merge IAM Access Analyzer unused access findings into the IAM results
+	if opts.accessAnalyzer {
+		importAccessAnalyzerFindings(accounts, accountSessions, regions, accountResults)
+	}
+
+	// Merge AWS Compute Optimizer over-provisioning findings into the results
+	if opts.computeOptimizer {
+		importComputeOptimizerFindings(workerPool, accounts, accountSessions, regions, accountResults, func(result awsinternal.ScanResult) bool {
+			message, fields := ignoredResult(result, managedResources, opts.gracePeriodDays)
+			if message != "" {
+				fields["source"] = "compute_optimizer"
+				fields["account_id"] = result.AccountID
+				logging.Debug(message, fields)
+			}
+			return message == ""
+		})
+	}
+
+	// Merge reviewer annotations into the findings
+	if annotationStore != nil {
+		annotated := 0
+		for _, accountResult := range accountResults {
+			for _, scannerResults := range accountResult.Results {
+				annotated += annotationStore.Apply(scannerResults)
+			}
+		}
+		logging.Info("Applied annotations", map[string]interface{}{
+			"annotated_results": annotated,
+		})
+	}
+
+	// Classify the reasons into reason codes, once all reasons are merged
+	for _, accountResult := range accountResults {
+		for _, scannerResults := range accountResult.Results {
+			awsinternal.ApplyReasonCodes(scannerResults)
+		}
+	}
+
+	// Rewrite the reasons for the configured verbosity, once all reasons are merged
+	if opts.reasonVerbosity != awsinternal.ReasonVerbosityNormal {
+		daysUnusedByLabel := make(map[string]int, len(scanners))
+		for _, scanner := range scanners {
+			daysUnused := opts.daysUnused
+			if override, ok := config.Config.ScanScannerDaysUnused[scanner.ArgumentName()]; ok {
+				daysUnused = override
+			}
+			daysUnusedByLabel[scanner.Label()] = daysUnused
+		}
+		for _, accountResult := range accountResults {
+			for label, scannerResults := range accountResult.Results {
+				daysUnused, ok := daysUnusedByLabel[label]
+				if !ok {
+					daysUnused = opts.daysUnused
+				}
+				awsinternal.ApplyReasonVerbosity(scannerResults, opts.reasonVerbosity, daysUnused)
+			}
+		}
+	}
+
+	// Add the findings to the scan history and hold back those not yet confirmed by enough
+	// consecutive scans. Without previous runs every finding is reported. Held back findings
+	// are still recorded with the run, so they count toward their streak in the next scan.
+	var unconfirmed []awsinternal.ScanResult
+	if historyStore != nil {
+		var allResults []awsinternal.ScanResult
+		for _, accountResult := range accountResults {
+			for _, scannerResults := range accountResult.Results {
+				allResults = append(allResults, scannerResults...)
+			}
+		}
+		historyStore.Record(allResults, historyScope, startTime)
+
+		if historyStore.Existed() {
+			for _, accountResult := range accountResults {
+				for label, scannerResults := range accountResult.Results {
+					for _, result := range scannerResults {
+						if historyStore.Consecutive(result) < opts.confirmationScans {
+							unconfirmed = append(unconfirmed, result)
+						}
+					}
+					accountResult.Results[label] = historyStore.Confirm(scannerResults, opts.confirmationScans)
+				}
+			}
+			logging.Info("Held back unconfirmed findings", map[string]interface{}{
+				"confirmation_scans": opts.confirmationScans,
+				"held_findings":      len(unconfirmed),
+			})
+		}
+	}
+
+	// Convert costs into the report currency
+	for _, accountResult := range accountResults {
+		accountResult.Currency = converter.Currency
+		for _, scannerResults := range accountResult.Results {
+			converter.ConvertResults(scannerResults)
+		}
+	}
+	converter.ConvertResults(unconfirmed)
+
+	// Add each account's month-to-date spend, so waste can be put into proportion
+	var accountSpend map[string]awsinternal.AccountSpend
+	if opts.accountSpend || opts.spendSummaryFile != "" {
+		accountSpend = loadAccountSpend(opts.spendSummaryFile, baseSession, converter)
+		for accountID, accountResult := range accountResults {
+			if spend, ok := accountSpend[accountID]; ok {
+				accountResult.Spend = &spend
+			}
+		}
+	}
+
+	// Group each account's savings by the cost allocation tags, so chargeback can be computed
+	// from the results
+	addCostAllocation(accountResults, config.Config.ScanCostAllocationTags)
+
+	// Derive the growth of the waste the forecast is projected with from the recorded runs
+	// and this scan
+	var wasteGrowth float64
+	if opts.historyDir != "" {
+		summary := anomaly.NewSummary(startTime, converter.Currency)
+		for accountID, accountResult := range accountResults {
+			for _, scannerResults := range accountResult.Results {
+				summary.Add(accountID, accountResult.AccountName, scannerResults)
+			}
+		}
+		summaries, err := anomaly.LoadSummaries(opts.historyDir)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			logging.Error("Error loading scan runs", err, map[string]interface{}{
+				"history_dir": opts.historyDir,
+			})
+		} else if growth, ok := anomaly.SavingsGrowth(append(summaries, summary)); ok {
+			wasteGrowth = growth
+		}
+	}
+
+	// Project the waste of the findings if they are not remediated
+	var allFindings []awsinternal.ScanResult
+	for _, accountResult := range accountResults {
+		for _, scannerResults := range accountResult.Results {
+			allFindings = append(allFindings, scannerResults...)
+		}
+	}
+	forecast := awsinternal.ForecastWaste(awsinternal.TotalMonthlyWaste(allFindings), wasteGrowth)
+	if forecast.MonthlyWaste > 0 {
+		fields := map[string]interface{}{
+			"currency":       converter.Currency,
+			"monthly_waste":  fmt.Sprintf("%.2f", forecast.MonthlyWaste),
+			"monthly_growth": fmt.Sprintf("%.1f%%", forecast.MonthlyGrowth*100),
+		}
+		for _, projection := range forecast.Projections {
+			fields[fmt.Sprintf("waste_%d_months", projection.Months)] = fmt.Sprintf("%.2f", projection.Waste)
+		}
+		logging.Info("Projected waste if findings are not remediated", fields)
+	}
+
+	// Total the EBS storage still billed for stopped instances, tracked on its own by finance
+	if stoppedStorage := awsinternal.SummarizeStoppedInstanceStorage(allFindings); stoppedStorage.Instances > 0 {
+		logging.Info("EBS storage of stopped instances", map[string]interface{}{
+			"currency":     converter.Currency,
+			"instances":    stoppedStorage.Instances,
+			"volumes":      stoppedStorage.Volumes,
+			"size_gb":      stoppedStorage.SizeGB,
+			"monthly_cost": fmt.Sprintf("%.2f", stoppedStorage.MonthlyCost),
+		})
+	}
+
+	// Write the instance scheduling plan for scheduled stop/start candidates
+	if opts.schedulingPlan != "" {
+		var allResults []awsinternal.ScanResult
+		for _, accountResult := range accountResults {
+			for _, scannerResults := range accountResult.Results {
+				allResults = append(allResults, scannerResults...)
+			}
+		}
+		plan := output.BuildSchedulingPlan(allResults, businessHours)
+		if err := output.WriteSchedulingPlan(plan, opts.schedulingPlan); err != nil {
+			logging.Error("Error writing scheduling plan", err, map[string]interface{}{
+				"output_path": opts.schedulingPlan,
+			})
+		} else {
+			fmt.Printf("Scheduling plan for %d instances written to %s\n", len(plan.Instances), opts.schedulingPlan)
+		}
+	}
+
+	// Hand the results to the service driving the scan
+	if opts.observer != nil {
+		for accountID, result := range accountResults {
+			data, err := json.Marshal(result)
+			if err != nil {
+				logging.Error("Error marshaling scan results", err, map[string]interface{}{
+					"account_id": accountID,
+				})
+				continue
+			}
+			opts.observer.AccountResults(accountID, result.AccountName, data)
+		}
+	}
+
+	// Calculate scan metrics for the HTML report
+	duration := time.Since(startTime).Seconds()
+	reportMetrics := html.ScanMetrics{
+		CompletedScans:     metrics.CompletedTasks,
+		FailedScans:        metrics.FailedTasks,
+		TotalRunTime:       duration,
+		AvgScansPerSecond:  float64(metrics.CompletedTasks) / duration,
+		CompletedAt:        time.Now(),
+		PeakWorkers:        metrics.PeakWorkers,
+		MaxWorkers:         config.Config.MaxWorkers,
+		WorkerUtilization:  float64(metrics.PeakWorkers) / float64(config.Config.MaxWorkers) * 100,
+		AvgExecutionTimeMs: metrics.AverageExecutionMs,
+		TasksPerSecond:     float64(metrics.CompletedTasks) / float64(metrics.AverageExecutionMs) * 1000,
+		APICalls:           apiCalls.Total(),
+		APICallCost:        apiCalls.EstimatedCost(),
+		APIBudgetExceeded:  apiCalls.Exceeded(),
+	}
+	for _, skipped := range skippedAccounts {
+		reportMetrics.SkippedAccounts = append(reportMetrics.SkippedAccounts, html.SkippedAccount{
+			AccountID:   skipped.AccountID,
+			AccountName: skipped.AccountName,
+			Reason:      skipped.Reason,
+		})
+	}
+	for _, timing := range slowestTasks {
+		reportMetrics.SlowestTasks = append(reportMetrics.SlowestTasks, html.TaskTiming{
+			Scanner:     timing.Scanner,
+			AccountID:   timing.AccountID,
+			AccountName: timing.AccountName,
+			Region:      timing.Region,
+			DurationMs:  timing.Duration.Milliseconds(),
+			Failed:      timing.Failed,
+		})
+	}
+
+	reportOptions := html.ReportOptions{
+		Language:           opts.reportLanguage,
+		Currency:           converter.Currency,
+		CurrencySymbol:     converter.Symbol(),
+		ReasonTemplates:    reportReasonTemplates(),
+		AccountSpend:       accountSpend,
+		WasteGrowth:        wasteGrowth,
+		CostAllocationTags: config.Config.ScanCostAllocationTags,
+		PreviousScan:       opts.previousScan,
+	}
+
+	// Record the metrics, findings and pricing cache of the run for the history and anomalies commands
+	if opts.historyDir != "" {
+		var prices map[string]float64
+		if awsinternal.DefaultCostEstimator != nil {
+			prices = awsinternal.DefaultCostEstimator.PriceCache()
+		}
+		recordHistoryRun(opts.historyDir, startTime, converter.Currency, accountResults, unconfirmed, historyScope, prices, reportMetrics)
+	}
+
+	// Export the findings, savings and run metrics to Prometheus and the monitoring services
+	summary := scanSummary(accountResults, reportMetrics, converter.Currency)
+	finishedSummary.Store(&summary)
+	exportPrometheusMetrics(opts, summary)
+	pushMetrics(config.Config.ScanMetricsExporters, summary)
+
+	// Output results, writing accounts with an output override to their own destination
+	defaultResults := writeOutputOverrides(opts, accounts, accountResults, reportMetrics, reportOptions)
+	if len(defaultResults) > 0 || len(accountResults) == 0 {
+		writeResults(opts, defaultResults, accountSessions, reportMetrics, reportOptions)
+	}
+
+	// Publish findings to AWS Security Hub
+	if opts.securityHub {
+		publishSecurityHubFindings(accountSessions, opts.securityHubRegion, accountResults, scanners, regions)
+	}
+
+	// Open OpsCenter OpsItems for severe findings
+	if opts.opsCenter {
+		openOpsItems(accountSessions, opts.opsCenterRegion, opts.opsCenterMinSeverity, accountResults)
+	}
+
+	// Write findings to the DynamoDB table
+	if opts.dynamoDBTable != "" {
+		writeDynamoDBFindings(baseSession, opts.dynamoDBTable, opts.dynamoDBRegion, accountResults, startTime)
+	}
+
+	// Services driving the scan get the results instead
+	if opts.observer == nil {
+		printScanSummary(os.Stdout, accountResults, converter.Symbol())
+	}
+	notifySlack(opts, accountResults, converter.Symbol())
+
+	reportSkippedAccounts(skippedAccounts)
+	logging.ScanComplete(len(accountResults))
+	return nil
+}
+
+// filterManagementAccount returns the accounts without the management account of the
+// organization if it is excluded, and the skipped management account
+func filterManagementAccount(accounts []awsinternal.Account, exclude bool) ([]awsinternal.Account, []skippedAccount) {
+	var scanned []awsinternal.Account
+	var skipped []skippedAccount
+	for _, account := range accounts {
+		if !account.Management {
+			scanned = append(scanned, account)
+			continue
+		}
+		if exclude {
+			skipped = append(skipped, skippedAccount{
+				AccountID:   account.ID,
+				AccountName: account.Name,
+				Reason:      "management account of the organization is excluded",
+			})
+			continue
+		}
+		logging.Info("Scanning the management account of the organization, its findings are marked", map[string]interface{}{
+			"account_id":   account.ID,
+			"account_name": account.Name,
+		})
+		scanned = append(scanned, account)
+	}
+	return scanned, skipped
+}
+
+// addCostAllocation groups the savings of each account's findings by the values of the tag keys
+func addCostAllocation(accountResults map[string]*scanResult, tagKeys []string) {
+	if len(tagKeys) == 0 {
+		return
+	}
+	for _, accountResult := range accountResults {
+		var results []awsinternal.ScanResult
+		for _, scannerResults := range accountResult.Results {
+			results = append(results, scannerResults...)
+		}
+		accountResult.CostAllocation = awsinternal.SummarizeCostAllocation(results, tagKeys)
+	}
+}
+
+// ignoredResult returns why a result is left out of the scan, as a log message with its
+// fields, or an empty message if it is kept. Results of AWS-managed resources, of resources
+// created within the grace period and on the ignore lists of the config are left out.
+func ignoredResult(result awsinternal.ScanResult, managedResources *awsinternal.ManagedResourceMatcher, gracePeriodDays int) (string, map[string]interface{}) {
+	// Exclude resources created and managed by AWS
+	if managedResources != nil {
+		if rule, ok := managedResources.Match(result); ok {
+			return "Ignoring AWS-managed resource", map[string]interface{}{
+				"resource_id": result.ResourceID,
+				"rule":        rule.Description,
+			}
+		}
+	}
+
+	// Exclude resources created within the grace period
+	if awsinternal.WithinGracePeriod(result, gracePeriodDays, time.Now()) {
+		return "Ignoring recently created resource", map[string]interface{}{
+			"resource_id":       result.ResourceID,
+			"grace_period_days": gracePeriodDays,
+		}
+	}
+
+	// Check the ignore lists of the config
+	if rule := ignoreRule(result); rule != "" {
+		return "Ignoring resource by " + rule, map[string]interface{}{
+			"resource_id":   result.ResourceID,
+			"resource_name": result.ResourceName,
+		}
+	}
+	return "", nil
+}
+
+// ignoreRule returns the ignore list of the config matching a result, e.g. "ID" for a
+// resource ID in the ignored resource IDs, or an empty string if none matches
+func ignoreRule(result awsinternal.ScanResult) string {
+	for _, ignoreID := range config.Config.ScanIgnoreResourceIDs {
+		if strings.EqualFold(result.ResourceID, ignoreID) {
+			return "ID"
+		}
+	}
+	for _, ignoreName := range config.Config.ScanIgnoreResourceNames {
+		if strings.EqualFold(result.ResourceName, ignoreName) {
+			return "name"
+		}
+	}
+	// Tag keys and values are compared case-insensitively
+	for ignoreKey, ignoreValue := range config.Config.ScanIgnoreTags {
+		for tagKey, tagValue := range result.Tags {
+			if strings.EqualFold(tagKey, ignoreKey) && strings.EqualFold(tagValue, ignoreValue) {
+				return "tag"
+			}
+		}
+	}
+	return ""
+}
+
+// newCurrencyConverter returns the converter of costs into the report currency, using the
+// exchange rate of the flags or else of the config
+func newCurrencyConverter(opts *scanOptions) (*currency.Converter, error) {
+	var rateProvider currency.RateProvider
+	switch {
+	case opts.exchangeRate > 0:
+		rateProvider = currency.StaticRateProvider{opts.currency: opts.exchangeRate}
+	case opts.exchangeRateURL != "":
+		rateProvider = &currency.HTTPRateProvider{URL: opts.exchangeRateURL}
+	default:
+		rateProvider = currency.StaticRateProvider(config.Config.ScanExchangeRates)
+	}
+	converter, err := currency.NewConverter(opts.currency, rateProvider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up currency conversion: %w", err)
+	}
+	return converter, nil
+}
+
+// writeResults writes the results of the accounts to the output of the scan. The account
+// sessions are used to write each account's results to its own bucket.
+func writeResults(opts *scanOptions, accountResults map[string]*scanResult, accountSessions map[string]*session.Session, metrics html.ScanMetrics, reportOptions html.ReportOptions) {
 	switch opts.output {
 	case "filesystem":
 		switch opts.outputFormat {
@@ -861,28 +2635,32 @@ func runScan(cmd *cobra.Command, opts *scanOptions) error {
 				}
 			}
 
-			// Calculate scan metrics
-			duration := time.Since(startTime).Seconds()
-			metrics := html.ScanMetrics{
-				CompletedScans:     metrics.CompletedTasks,
-				FailedScans:        metrics.FailedTasks,
-				TotalRunTime:       duration,
-				AvgScansPerSecond:  float64(metrics.CompletedTasks) / duration,
-				CompletedAt:        time.Now(),
-				PeakWorkers:        metrics.PeakWorkers,
-				MaxWorkers:         config.Config.MaxWorkers,
-				WorkerUtilization:  float64(metrics.PeakWorkers) / float64(config.Config.MaxWorkers) * 100,
-				AvgExecutionTimeMs: metrics.AverageExecutionMs,
-				TasksPerSecond:     float64(metrics.CompletedTasks) / float64(metrics.AverageExecutionMs) * 1000,
-			}
-
 			outputPath := "reports/scan_report.html"
-			if err := html.WriteHTML(allResults, outputPath, metrics); err != nil {
+			reportOptions.PreviousScan = reportOptions.PreviousScan.ForAccounts(reportAccounts(accountResults))
+			if err := html.WriteHTMLWithOptions(allResults, outputPath, metrics, reportOptions); err != nil {
 				logging.Error("Error writing HTML output", err, map[string]interface{}{
 					"output_path": outputPath,
 				})
 			}
 			fmt.Printf("HTML report written to %s\n", outputPath)
+		case "csv":
+			writeCSVResults(accountResults, reportOptions.Currency, opts.csvPerAccount)
+		case "markdown":
+			var allResults []awsinternal.ScanResult
+			for _, accountResult := range accountResults {
+				for _, scannerResults := range accountResult.Results {
+					allResults = append(allResults, scannerResults...)
+				}
+			}
+
+			outputPath := filepath.Join("reports", "scan_summary.md")
+			if err := output.WriteMarkdownFile(allResults, reportOptions.CurrencySymbol, outputPath); err != nil {
+				logging.Error("Error writing Markdown output", err, map[string]interface{}{
+					"output_path": outputPath,
+				})
+				return
+			}
+			fmt.Printf("Markdown summary written to %s\n", outputPath)
 		}
 	case "s3":
 		writer := output.NewWriter(output.Config{
@@ -895,9 +2673,17 @@ func runScan(cmd *cobra.Command, opts *scanOptions) error {
 		// Write results for each account
 		for accountID, result := range accountResults {
 			outputData := scanResult{
-				AccountID:   accountID,
-				AccountName: accounts[0].Name,
-				Results:     result.Results,
+				AccountID:          accountID,
+				AccountName:        result.AccountName,
+				AccountTeam:        result.AccountTeam,
+				AccountEnvironment: result.AccountEnvironment,
+				ManagementAccount:  result.ManagementAccount,
+				ScannedAt:          result.ScannedAt,
+				Currency:           result.Currency,
+				Results:            result.Results,
+				Errors:             result.Errors,
+				Spend:              result.Spend,
+				CostAllocation:     result.CostAllocation,
 			}
 
 			data, err := json.Marshal(outputData)
@@ -921,28 +2707,723 @@ func runScan(cmd *cobra.Command, opts *scanOptions) error {
 				"bucket":     opts.bucket,
 			})
 		}
+	case "s3-account":
+		// Write each account's results to its own bucket with the session scanning it
+		for accountID, result := range accountResults {
+			location := output.AccountLocation{
+				AccountID:   accountID,
+				AccountName: result.AccountName,
+				Team:        result.AccountTeam,
+				Environment: result.AccountEnvironment,
+			}
+			bucket, err := output.RenderAccountLocation(opts.accountBucket, location)
+			if err != nil {
+				logging.Error("Error rendering account bucket", err, map[string]interface{}{
+					"account_id": accountID,
+				})
+				continue
+			}
+			prefix, err := output.RenderAccountLocation(opts.accountPrefix, location)
+			if err != nil {
+				logging.Error("Error rendering account prefix", err, map[string]interface{}{
+					"account_id": accountID,
+				})
+				continue
+			}
+
+			writer := output.NewWriter(output.Config{
+				Type:     output.S3,
+				S3Bucket: bucket,
+				S3Region: opts.bucketRegion,
+				S3Prefix: prefix,
+				Session:  accountSessions[accountID],
+			})
+			if err := writer.Write(accountID, result); err != nil {
+				logging.Error("Error writing scan results to account bucket", err, map[string]interface{}{
+					"account_id": accountID,
+					"bucket":     bucket,
+					"prefix":     prefix,
+				})
+				continue
+			}
+
+			logging.Info("Successfully wrote scan results to account bucket", map[string]interface{}{
+				"account_id": accountID,
+				"bucket":     bucket,
+				"prefix":     prefix,
+			})
+		}
 	}
 
-	logging.ScanComplete(len(accountResults))
-	return nil
+}
+
+// writeCSVResults writes the findings of the accounts as CSV, to a file per account under
+// reports/csv or to a combined reports/scan_results.csv, with costs in the report currency
+func writeCSVResults(accountResults map[string]*scanResult, currency string, perAccount bool) {
+	if perAccount {
+		for accountID, accountResult := range accountResults {
+			var results []awsinternal.ScanResult
+			for _, scannerResults := range accountResult.Results {
+				results = append(results, scannerResults...)
+			}
+			outputPath := filepath.Join("reports", "csv", accountID+".csv")
+			if err := output.WriteCSVFile(results, currency, outputPath); err != nil {
+				logging.Error("Error writing CSV output", err, map[string]interface{}{
+					"account_id":  accountID,
+					"output_path": outputPath,
+				})
+			}
+		}
+		fmt.Printf("CSV results of %d accounts written to %s\n", len(accountResults), filepath.Join("reports", "csv"))
+		return
+	}
+
+	var allResults []awsinternal.ScanResult
+	for _, accountResult := range accountResults {
+		for _, scannerResults := range accountResult.Results {
+			allResults = append(allResults, scannerResults...)
+		}
+	}
+	outputPath := filepath.Join("reports", "scan_results.csv")
+	if err := output.WriteCSVFile(allResults, currency, outputPath); err != nil {
+		logging.Error("Error writing CSV output", err, map[string]interface{}{
+			"output_path": outputPath,
+		})
+		return
+	}
+	fmt.Printf("CSV results written to %s\n", outputPath)
+}
+
+// recordHistoryRun writes the metrics and findings of a scan as a run of the history directory,
+// with a dated snapshot of the pricing cache if prices is not nil. Unconfirmed findings, held
+// back by --confirmation-scans, are recorded apart from the totals, and scope is the part of
+// the accounts the scan covered.
+func recordHistoryRun(dir string, startTime time.Time, currency string, accountResults map[string]*scanResult, unconfirmed []awsinternal.ScanResult, scope *history.Scope, prices map[string]float64, metrics html.ScanMetrics) {
+	accounts := make(map[string]string, len(accountResults))
+	var findings []history.Finding
+	for accountID, accountResult := range accountResults {
+		accounts[accountID] = accountResult.AccountName
+		for _, scannerResults := range accountResult.Results {
+			for _, result := range scannerResults {
+				findings = append(findings, history.NewFinding(result))
+			}
+		}
+	}
+	for _, result := range unconfirmed {
+		finding := history.NewFinding(result)
+		finding.Unconfirmed = true
+		findings = append(findings, finding)
+	}
+	run := history.NewRun(startTime, metrics.CompletedAt, currency, accounts, findings, scope, history.RunMetrics{
+		CompletedTasks:    metrics.CompletedScans,
+		FailedTasks:       metrics.FailedScans,
+		RunTimeSeconds:    metrics.TotalRunTime,
+		PeakWorkers:       metrics.PeakWorkers,
+		SkippedAccounts:   len(metrics.SkippedAccounts),
+		APICalls:          metrics.APICalls,
+		APICallCost:       metrics.APICallCost,
+		APIBudgetExceeded: metrics.APIBudgetExceeded,
+	})
+
+	path, err := history.WriteRun(dir, run, findings)
+	if err != nil {
+		logging.Error("Error recording scan run", err, map[string]interface{}{
+			"history_dir": dir,
+		})
+		return
+	}
+	if prices != nil {
+		if err := history.WritePriceCache(dir, run.ID, prices); err != nil {
+			logging.Error("Error recording pricing cache of scan run", err, map[string]interface{}{
+				"history_dir": dir,
+			})
+		}
+	}
+	logging.Info("Recorded scan run", map[string]interface{}{
+		"path": path,
+	})
+}
+
+// loadAccountSpend returns the month-to-date spend of the accounts in the report currency,
+// from the spend summary file if set or else from Cost Explorer. Spend that cannot be loaded
+// is left out of the report.
+func loadAccountSpend(spendSummaryFile string, sess *session.Session, converter *currency.Converter) map[string]awsinternal.AccountSpend {
+	var spend map[string]awsinternal.AccountSpend
+	var err error
+	if spendSummaryFile != "" {
+		spend, err = awsinternal.LoadSpendSummary(spendSummaryFile, time.Now())
+	} else {
+		spend, err = awsinternal.GetMonthToDateSpend(sess, time.Now())
+	}
+	if err != nil {
+		logging.Error("Failed to load account spend", err, map[string]interface{}{
+			"spend_summary_file": spendSummaryFile,
+		})
+		return nil
+	}
+
+	for accountID, accountSpend := range spend {
+		accountSpend.MonthToDate *= converter.Rate
+		accountSpend.Projected *= converter.Rate
+		spend[accountID] = accountSpend
+	}
+	return spend
+}
+
+// reportSkippedAccounts prints the accounts that were not scanned with the reason
+func reportSkippedAccounts(skippedAccounts []skippedAccount) {
+	if len(skippedAccounts) == 0 {
+		return
+	}
+	logging.Warn("Some accounts were not scanned", map[string]interface{}{
+		"skipped_accounts": len(skippedAccounts),
+	})
+	fmt.Printf("%d accounts were not scanned:\n", len(skippedAccounts))
+	for _, skipped := range skippedAccounts {
+		fmt.Printf("  %s (%s): %s\n", skipped.AccountName, skipped.AccountID, skipped.Reason)
+	}
+}
+
+// warnMalformedResults logs a warning when a scanner produced results missing fields the
+// reports rely on, with the problems found and an example resource
+func warnMalformedResults(scanner, accountID, region string, results awsinternal.ScanResults) {
+	malformed := 0
+	var problems []string
+	example := ""
+	for _, result := range results {
+		resultProblems := awsinternal.ValidateResult(result)
+		if len(resultProblems) == 0 {
+			continue
+		}
+		if malformed == 0 {
+			example = result.ResourceID
+		}
+		malformed++
+		for _, problem := range resultProblems {
+			if !slices.Contains(problems, problem) {
+				problems = append(problems, problem)
+			}
+		}
+	}
+	if malformed == 0 {
+		return
+	}
+	logging.Warn("Scanner produced malformed results", map[string]interface{}{
+		"scanner":         scanner,
+		"account_id":      accountID,
+		"region":          region,
+		"malformed_count": malformed,
+		"problems":        strings.Join(problems, ", "),
+		"example":         example,
+	})
+}
+
+// publishSecurityHubFindings imports the findings of every account into Security Hub using
+// the account's own session, archiving the findings the scan resolved. Failures are logged
+// so the scan output is still written.
+func publishSecurityHubFindings(accountSessions map[string]*session.Session, region string, accountResults map[string]*scanResult, scanners []awsinternal.Scanner, regions []string) {
+	total := 0
+	for accountID, accountResult := range accountResults {
+		var results []awsinternal.ScanResult
+		for _, scannerResults := range accountResult.Results {
+			results = append(results, scannerResults...)
+		}
+
+		scope := securityHubScope(accountID, accountResult, scanners, regions, region)
+		imported, err := output.PublishSecurityHubFindings(accountSessions[accountID], region, accountID, results, scope)
+		total += imported
+		if err != nil {
+			logging.Error("Failed to publish findings to Security Hub", err, map[string]interface{}{
+				"account_id": accountID,
+				"region":     region,
+			})
+			continue
+		}
+		logging.Info("Published findings to Security Hub", map[string]interface{}{
+			"account_id": accountID,
+			"region":     region,
+			"findings":   imported,
+		})
+	}
+	fmt.Printf("%d findings published to Security Hub in %s\n", total, region)
+}
+
+// securityHubScope returns the resource types and regions the scan of an account covered.
+// The findings of scanners excluded from the account, or that failed in any of its regions,
+// are kept. Global resources are reported in the import region.
+func securityHubScope(accountID string, accountResult *scanResult, scanners []awsinternal.Scanner, regions []string, importRegion string) output.SecurityHubScope {
+	scanned := make(map[string]bool, len(scanners))
+	for _, scanner := range scanners {
+		if !scannerExcluded(accountID, scanner.ArgumentName()) {
+			scanned[scanner.Label()] = true
+		}
+	}
+	failed := make(map[string]bool, len(accountResult.Errors))
+	for _, scanErr := range accountResult.Errors {
+		failed[scanErr.Scanner] = true
+	}
+	scannedRegions := map[string]bool{importRegion: true}
+	for _, region := range regions {
+		scannedRegions[region] = true
+	}
+	return func(resourceType, region string) bool {
+		return scanned[resourceType] && !failed[resourceType] && scannedRegions[region]
+	}
+}
+
+// openOpsItems opens OpsItems for the severe findings of every account using the account's
+// own session. Failures are logged so the scan output is still written.
+func openOpsItems(accountSessions map[string]*session.Session, region, minSeverity string, accountResults map[string]*scanResult) {
+	total := 0
+	for accountID, accountResult := range accountResults {
+		var results []awsinternal.ScanResult
+		for _, scannerResults := range accountResult.Results {
+			results = append(results, scannerResults...)
+		}
+		if len(results) == 0 {
+			continue
+		}
+
+		opened, err := output.OpenOpsItems(accountSessions[accountID], region, minSeverity, results)
+		total += opened
+		if err != nil {
+			logging.Error("Failed to open OpsItems", err, map[string]interface{}{
+				"account_id": accountID,
+				"region":     region,
+			})
+			continue
+		}
+		logging.Info("Opened OpsItems", map[string]interface{}{
+			"account_id": accountID,
+			"region":     region,
+			"ops_items":  opened,
+		})
+	}
+	fmt.Printf("%d OpsItems opened in %s\n", total, region)
+}
+
+// writeDynamoDBFindings writes the findings of all accounts to a DynamoDB table with the base
+// session, so the table can live in a central account
+func writeDynamoDBFindings(sess *session.Session, table, region string, accountResults map[string]*scanResult, scanTime time.Time) {
+	total := 0
+	for accountID, accountResult := range accountResults {
+		var results []awsinternal.ScanResult
+		for _, scannerResults := range accountResult.Results {
+			results = append(results, scannerResults...)
+		}
+		if len(results) == 0 {
+			continue
+		}
+
+		written, err := output.WriteDynamoDBFindings(sess, table, region, results, accountResult.Currency, scanTime)
+		total += written
+		if err != nil {
+			logging.Error("Failed to write findings to DynamoDB", err, map[string]interface{}{
+				"account_id": accountID,
+				"table":      table,
+			})
+			continue
+		}
+		logging.Info("Wrote findings to DynamoDB", map[string]interface{}{
+			"account_id": accountID,
+			"table":      table,
+			"findings":   written,
+		})
+	}
+	fmt.Printf("%d findings written to DynamoDB table %s\n", total, table)
+}
+
+// importAccessAnalyzerFindings collects unused access findings from the analyzers of every
+// account and region and merges them into the results of the accounts owning the resources.
+// Organization analyzers report findings for member accounts, so findings are grouped by
+// resource owner rather than by the account they were read from.
+func importAccessAnalyzerFindings(accounts []awsinternal.Account, accountSessions map[string]*session.Session, regions []string, accountResults map[string]*scanResult) {
+	findingsByAccount := make(map[string][]awsinternal.AccessAnalyzerFinding)
+	for _, account := range accounts {
+		for _, region := range regions {
+			findings, err := awsinternal.ListUnusedAccessFindings(accountSessions[account.ID], region)
+			if err != nil {
+				logging.Warn("Failed to list IAM Access Analyzer findings", map[string]interface{}{
+					"account_id": account.ID,
+					"region":     region,
+					"error":      err.Error(),
+				})
+				continue
+			}
+			for _, finding := range findings {
+				findingsByAccount[finding.AccountID] = append(findingsByAccount[finding.AccountID], finding)
+			}
+		}
+	}
+
+	imported := 0
+	for accountID, findings := range findingsByAccount {
+		accountResult, ok := accountResults[accountID]
+		if !ok {
+			continue // Resource owner is not part of this scan
+		}
+		added := awsinternal.MergeAccessAnalyzerFindings(accountResult.Results, findings)
+
+		// Add account info to the results created from findings
+		for _, scannerResults := range accountResult.Results {
+			for i := range scannerResults {
+				if scannerResults[i].AccountID != "" {
+					continue
+				}
+				scannerResults[i].AccountID = accountResult.AccountID
+				scannerResults[i].AccountName = accountResult.AccountName
+				scannerResults[i].AccountTeam = accountResult.AccountTeam
+				scannerResults[i].AccountEnv = accountResult.AccountEnvironment
+				scannerResults[i].Management = accountResult.ManagementAccount
+			}
+		}
+		imported += added
+	}
+
+	logging.Info("Imported IAM Access Analyzer findings", map[string]interface{}{
+		"accounts":      len(findingsByAccount),
+		"added_results": imported,
+	})
+}
+
+// importComputeOptimizerFindings collects the over-provisioning findings of Compute Optimizer
+// in every account and region on the worker pool and merges them into the results of the
+// accounts owning the resources. Results created from findings are only kept if keep returns
+// true, so they are filtered like the results of the scanners.
+func importComputeOptimizerFindings(pool *worker.Pool, accounts []awsinternal.Account, accountSessions map[string]*session.Session, regions []string, accountResults map[string]*scanResult, keep func(awsinternal.ScanResult) bool) {
+	findingsByAccount := make(map[string][]awsinternal.ComputeOptimizerFinding)
+	var findingsMutex sync.Mutex
+	var tasks []worker.Task
+	for _, account := range accounts {
+		for _, region := range regions {
+			account := account
+			region := region
+			tasks = append(tasks, worker.Task(func(ctx context.Context) error {
+				findings, err := awsinternal.ListComputeOptimizerFindings(accountSessions[account.ID], region)
+				if err != nil {
+					logging.Warn("Failed to list Compute Optimizer findings", map[string]interface{}{
+						"account_id": account.ID,
+						"region":     region,
+						"error":      err.Error(),
+					})
+					return err
+				}
+				findingsMutex.Lock()
+				defer findingsMutex.Unlock()
+				for _, finding := range findings {
+					findingsByAccount[finding.AccountID] = append(findingsByAccount[finding.AccountID], finding)
+				}
+				return nil
+			}))
+		}
+	}
+	pool.ExecuteTasks(tasks)
+
+	imported := 0
+	for accountID, findings := range findingsByAccount {
+		accountResult, ok := accountResults[accountID]
+		if !ok {
+			continue // Resource owner is not part of this scan
+		}
+		added := awsinternal.MergeComputeOptimizerFindings(accountResult.Results, findings)
+
+		// Add account info to the results created from findings, and filter them like the
+		// results of the scanners
+		for label, scannerResults := range accountResult.Results {
+			var kept awsinternal.ScanResults
+			for _, result := range scannerResults {
+				if result.AccountID != "" {
+					kept = append(kept, result)
+					continue
+				}
+				result.AccountID = accountResult.AccountID
+				result.AccountName = accountResult.AccountName
+				result.AccountTeam = accountResult.AccountTeam
+				result.AccountEnv = accountResult.AccountEnvironment
+				result.Management = accountResult.ManagementAccount
+				if keep(result) {
+					kept = append(kept, result)
+				} else {
+					added--
+				}
+			}
+			accountResult.Results[label] = kept
+		}
+		imported += added
+	}
+
+	logging.Info("Imported Compute Optimizer findings", map[string]interface{}{
+		"accounts":      len(findingsByAccount),
+		"added_results": imported,
+	})
+}
+
+// scanTask is an account, region and scanner combination run by a scan
+type scanTask struct {
+	account awsinternal.Account
+	region  string
+	scanner awsinternal.Scanner
+}
+
+// scanTasks returns the account, region and scanner combinations a scan runs. Scanners
+// excluded from an account and combinations found empty are skipped.
+func scanTasks(accounts []awsinternal.Account, regions []string, scanners []awsinternal.Scanner, emptyTasks map[string]bool) []scanTask {
+	var tasks []scanTask
+	for _, scanner := range scanners {
+		// IAM is global, so IAM scanners only scan the home region of each partition. Multi-region
+		// scanners also run once per partition and inspect its regions themselves.
+		scanRegions := regions
+		if isIAMScanner(scanner) || isMultiRegionScanner(scanner) {
+			scanRegions = awsinternal.GlobalRegions(regions)
+		}
+
+		for _, region := range scanRegions {
+			for _, account := range accounts {
+				if scannerExcluded(account.ID, scanner.ArgumentName()) {
+					logging.Debug("Skipping scanner excluded from account", map[string]interface{}{
+						"scanner":    scanner.Label(),
+						"account_id": account.ID,
+						"region":     region,
+					})
+					continue
+				}
+				if emptyTasks[fmt.Sprintf("%s:%s:%s", account.ID, region, scanner.ArgumentName())] {
+					logging.Debug("Skipping empty region", map[string]interface{}{
+						"scanner":    scanner.Label(),
+						"account_id": account.ID,
+						"region":     region,
+					})
+					continue
+				}
+				tasks = append(tasks, scanTask{account: account, region: region, scanner: scanner})
+			}
+		}
+	}
+	return tasks
+}
+
+// addEmptyTasksToScope adds the scanners skipped in regions without resources to the history
+// scope, so findings of resources deleted since the previous scan are dropped
+func addEmptyTasksToScope(scope *history.Scope, emptyTasks map[string]bool, scanners []awsinternal.Scanner) {
+	labels := make(map[string]string)
+	for _, scanner := range scanners {
+		labels[scanner.ArgumentName()] = scanner.Label()
+	}
+	for task := range emptyTasks {
+		// Tasks are keyed by account:region:scanner
+		parts := strings.SplitN(task, ":", 3)
+		if len(parts) == 3 {
+			scope.Add(parts[0], labels[parts[2]], parts[1])
+		}
+	}
+}
+
+// countResources runs the Resource Groups Tagging API pre-pass for every account and region
+func countResources(accounts []awsinternal.Account, accountSessions map[string]*session.Session, regions []string, scanners []awsinternal.Scanner) *awsinternal.ResourceCounts {
+	counts := awsinternal.NewResourceCounts()
+
+	var scannerNames []string
+	for _, scanner := range scanners {
+		if !isIAMScanner(scanner) {
+			scannerNames = append(scannerNames, scanner.ArgumentName())
+		}
+	}
+
+	var tasks []worker.Task
+	for _, account := range accounts {
+		for _, region := range regions {
+			account := account
+			region := region
+			tasks = append(tasks, worker.Task(func(ctx context.Context) error {
+				regionCounts, err := awsinternal.CountResources(accountSessions[account.ID], region, scannerNames)
+				if err != nil {
+					logging.Warn("Failed to count resources, progress will fall back to task counts", map[string]interface{}{
+						"error":      err.Error(),
+						"account_id": account.ID,
+						"region":     region,
+					})
+					return err
+				}
+				for scanner, count := range regionCounts {
+					counts.Set(account.ID, region, scanner, count)
+				}
+				return nil
+			}))
+		}
+	}
+
+	runPrePass(tasks)
+
+	logging.Info("Resource count pre-pass complete", map[string]interface{}{
+		"accounts":        len(accounts),
+		"regions":         len(regions),
+		"total_resources": counts.Total(),
+	})
+
+	return counts
+}
+
+// prefetchPrices lists the priced resources of every account and region in a lightweight
+// inventory pass, then resolves each distinct price once in parallel. Scanners then hit the
+// shared price cache instead of fetching the same prices concurrently.
+func prefetchPrices(accounts []awsinternal.Account, accountSessions map[string]*session.Session, regions []string, scanners []awsinternal.Scanner) {
+	if awsinternal.DefaultCostEstimator == nil {
+		return
+	}
+
+	inventory := awsinternal.NewPriceInventory()
+	var tasks []worker.Task
+	for _, account := range accounts {
+		var scannerNames []string
+		for _, scanner := range scanners {
+			if awsinternal.HasPriceInventory(scanner.ArgumentName()) && !scannerExcluded(account.ID, scanner.ArgumentName()) {
+				scannerNames = append(scannerNames, scanner.ArgumentName())
+			}
+		}
+		if len(scannerNames) == 0 {
+			continue
+		}
+
+		for _, region := range regions {
+			account := account
+			region := region
+			tasks = append(tasks, worker.Task(func(ctx context.Context) error {
+				if err := awsinternal.CollectPriceInventory(accountSessions[account.ID], region, scannerNames, inventory); err != nil {
+					logging.Warn("Failed to collect price inventory, scanners will fetch prices as needed", map[string]interface{}{
+						"error":      err.Error(),
+						"account_id": account.ID,
+						"region":     region,
+					})
+					return err
+				}
+				return nil
+			}))
+		}
+	}
+	if len(tasks) == 0 {
+		return
+	}
+	runPrePass(tasks)
+
+	tasks = nil
+	for _, costConfig := range inventory.Configs() {
+		costConfig := costConfig
+		tasks = append(tasks, worker.Task(func(ctx context.Context) error {
+			if err := awsinternal.DefaultCostEstimator.PrefetchPrice(costConfig); err != nil {
+				logging.Warn("Failed to prefetch price", map[string]interface{}{
+					"error":         err.Error(),
+					"resource_type": costConfig.ResourceType,
+					"region":        costConfig.Region,
+					"resource_size": costConfig.ResourceSize,
+				})
+				return err
+			}
+			return nil
+		}))
+	}
+	runPrePass(tasks)
+
+	logging.Info("Price prefetch complete", map[string]interface{}{
+		"accounts": len(accounts),
+		"regions":  len(regions),
+		"prices":   inventory.Len(),
+	})
+}
+
+// findEmptyTasks returns the account:region:scanner combinations that contain no resources.
+// A positive tagging API count keeps a combination without further calls; otherwise scanners
+// implementing ResourceProber are probed with a lightweight Describe call. Global IAM scanners
+// and scanners that cannot be probed are never skipped. Probes share the scan contexts of the
+// run, so what they list is reused by the scanners.
+func findEmptyTasks(accounts []awsinternal.Account, accountSessions map[string]*session.Session, regions []string, scanners []awsinternal.Scanner, resourceCounts *awsinternal.ResourceCounts, scanContexts *awsinternal.ScanContexts) map[string]bool {
+	empty := make(map[string]bool)
+	var emptyMutex sync.Mutex
+
+	var tasks []worker.Task
+	for _, scanner := range scanners {
+		prober, ok := scanner.(awsinternal.ResourceProber)
+		if !ok || isIAMScanner(scanner) {
+			continue
+		}
+
+		for _, account := range accounts {
+			if scannerExcluded(account.ID, scanner.ArgumentName()) {
+				continue
+			}
+			for _, region := range regions {
+				if resourceCounts != nil {
+					if count, ok := resourceCounts.Get(account.ID, region, scanner.ArgumentName()); ok && count > 0 {
+						continue
+					}
+				}
+
+				scanner := scanner
+				prober := prober
+				account := account
+				region := region
+				tasks = append(tasks, worker.Task(func(ctx context.Context) error {
+					hasResources, err := prober.HasResources(awsinternal.ScanOptions{
+						Region:    region,
+						Session:   accountSessions[account.ID],
+						AccountID: account.ID,
+						Contexts:  scanContexts,
+					})
+					if err != nil {
+						// Keep the combination when the probe fails so nothing is missed
+						logging.Debug("Failed to probe region for resources", map[string]interface{}{
+							"error":      err.Error(),
+							"scanner":    scanner.Label(),
+							"account_id": account.ID,
+							"region":     region,
+						})
+						return err
+					}
+					if !hasResources {
+						emptyMutex.Lock()
+						empty[fmt.Sprintf("%s:%s:%s", account.ID, region, scanner.ArgumentName())] = true
+						emptyMutex.Unlock()
+					}
+					return nil
+				}))
+			}
+		}
+	}
+
+	runPrePass(tasks)
+
+	logging.Info("Empty region detection complete", map[string]interface{}{
+		"probed":  len(tasks),
+		"skipped": len(empty),
+	})
+
+	return empty
+}
+
+// runPrePass executes pre-scan tasks on a dedicated pool so they do not skew the
+// scan's worker pool metrics
+func runPrePass(tasks []worker.Task) {
+	pool := worker.NewPool(config.Config.MaxWorkers)
+	pool.Start()
+	pool.ExecuteTasks(tasks)
+	pool.Stop()
 }
 
 // getRoleARN returns the full ARN for a role. If the input is already an ARN, returns it as is.
 func getRoleARN(sess *session.Session, roleName string) (string, error) {
 	// If it's already an ARN, return it
-	if strings.HasPrefix(roleName, "arn:aws:iam::") {
+	if awsinternal.IsRoleARN(roleName) {
 		return roleName, nil
 	}
 
 	// Get the account ID using STS
-	stsClient := sts.New(sess)
+	stsClient := sts.New(sess, awsinternal.STSConfig(sess))
 	result, err := stsClient.GetCallerIdentity(&sts.GetCallerIdentityInput{})
 	if err != nil {
 		return "", fmt.Errorf("failed to get account ID: %w", err)
 	}
 
 	// Construct the role ARN
-	return fmt.Sprintf("arn:aws:iam::%s:role/%s", *result.Account, roleName), nil
+	return awsinternal.CallerRoleARN(result, roleName), nil
 }
 
 // getSessionWithOrgRole creates an AWS session and assumes the organization role if specified
@@ -969,20 +3450,19 @@ func getSessionWithOrgRole(region, orgRole string) (*session.Session, error) {
 		}
 
 		// Assume the role
-		result, err := sts.New(sess).AssumeRole(input)
+		result, err := sts.New(sess, awsinternal.STSConfig(sess)).AssumeRole(input)
 		if err != nil {
 			return nil, fmt.Errorf("failed to assume role: %w", err)
 		}
 
 		// Create new session with temporary credentials
-		sess, err = session.NewSession(&aws.Config{
-			Region: aws.String(region),
-			Credentials: credentials.NewStaticCredentials(
+		sess, err = session.NewSession(awsinternal.SessionConfig().
+			WithRegion(region).
+			WithCredentials(credentials.NewStaticCredentials(
 				*result.Credentials.AccessKeyId,
 				*result.Credentials.SecretAccessKey,
 				*result.Credentials.SessionToken,
-			),
-		})
+			)))
 		if err != nil {
 			return nil, fmt.Errorf("failed to create session with assumed role: %w", err)
 		}
@@ -2,20 +2,40 @@ package scan
 
 import (
 	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
 	"fmt"
 	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/client"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/lightsail"
 	"github.com/aws/aws-sdk-go/service/organizations"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/securityhub"
 	"github.com/aws/aws-sdk-go/service/sts"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -24,8 +44,17 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/undefinedlabs/go-mpatch"
 
+	"cloudsift/internal/anomaly"
 	awsinternal "cloudsift/internal/aws"
+	"cloudsift/internal/aws/utils"
 	"cloudsift/internal/config"
+	"cloudsift/internal/gcp"
+	"cloudsift/internal/history"
+	"cloudsift/internal/metrics"
+	"cloudsift/internal/notifications"
+	"cloudsift/internal/output"
+	"cloudsift/internal/output/html"
+	"cloudsift/internal/worker"
 )
 
 // Mock AWS services
@@ -161,6 +190,321 @@ func TestNewScanCmd(t *testing.T) {
 	daysUnusedFlag := flags.Lookup("days-unused")
 	assert.NotNil(t, daysUnusedFlag)
 	assert.Equal(t, "int", daysUnusedFlag.Value.Type())
+
+	countResourcesFlag := flags.Lookup("count-resources")
+	assert.NotNil(t, countResourcesFlag)
+	assert.Equal(t, "bool", countResourcesFlag.Value.Type())
+
+	skipEmptyRegionsFlag := flags.Lookup("skip-empty-regions")
+	assert.NotNil(t, skipEmptyRegionsFlag)
+	assert.Equal(t, "bool", skipEmptyRegionsFlag.Value.Type())
+
+	excludeAsgInstancesFlag := flags.Lookup("exclude-asg-instances")
+	assert.NotNil(t, excludeAsgInstancesFlag)
+	assert.Equal(t, "bool", excludeAsgInstancesFlag.Value.Type())
+
+	excludeSpotInstancesFlag := flags.Lookup("exclude-spot-instances")
+	assert.NotNil(t, excludeSpotInstancesFlag)
+	assert.Equal(t, "bool", excludeSpotInstancesFlag.Value.Type())
+
+	businessHoursFlag := flags.Lookup("business-hours")
+	assert.NotNil(t, businessHoursFlag)
+	assert.Equal(t, "string", businessHoursFlag.Value.Type())
+
+	businessHoursTimezoneFlag := flags.Lookup("business-hours-timezone")
+	assert.NotNil(t, businessHoursTimezoneFlag)
+	assert.Equal(t, "string", businessHoursTimezoneFlag.Value.Type())
+
+	schedulingPlanFlag := flags.Lookup("scheduling-plan")
+	assert.NotNil(t, schedulingPlanFlag)
+	assert.Equal(t, "string", schedulingPlanFlag.Value.Type())
+
+	reportLanguageFlag := flags.Lookup("report-language")
+	assert.NotNil(t, reportLanguageFlag)
+	assert.Equal(t, "string", reportLanguageFlag.Value.Type())
+
+	currencyFlag := flags.Lookup("currency")
+	assert.NotNil(t, currencyFlag)
+	assert.Equal(t, "string", currencyFlag.Value.Type())
+
+	exchangeRateFlag := flags.Lookup("exchange-rate")
+	assert.NotNil(t, exchangeRateFlag)
+	assert.Equal(t, "float64", exchangeRateFlag.Value.Type())
+
+	exchangeRateUrlFlag := flags.Lookup("exchange-rate-url")
+	assert.NotNil(t, exchangeRateUrlFlag)
+	assert.Equal(t, "string", exchangeRateUrlFlag.Value.Type())
+
+	annotationsFileFlag := flags.Lookup("annotations-file")
+	assert.NotNil(t, annotationsFileFlag)
+	assert.Equal(t, "string", annotationsFileFlag.Value.Type())
+
+	ssoStartUrlFlag := flags.Lookup("sso-start-url")
+	assert.NotNil(t, ssoStartUrlFlag)
+	assert.Equal(t, "string", ssoStartUrlFlag.Value.Type())
+
+	ssoRegionFlag := flags.Lookup("sso-region")
+	assert.NotNil(t, ssoRegionFlag)
+	assert.Equal(t, "string", ssoRegionFlag.Value.Type())
+
+	ssoRoleNameFlag := flags.Lookup("sso-role-name")
+	assert.NotNil(t, ssoRoleNameFlag)
+	assert.Equal(t, "string", ssoRoleNameFlag.Value.Type())
+
+	requireReadOnlyFlag := flags.Lookup("require-read-only")
+	assert.NotNil(t, requireReadOnlyFlag)
+	assert.Equal(t, "bool", requireReadOnlyFlag.Value.Type())
+
+	scannerDaysUnusedFlag := flags.Lookup("scanner-days-unused")
+	assert.NotNil(t, scannerDaysUnusedFlag)
+	assert.Equal(t, "string", scannerDaysUnusedFlag.Value.Type())
+
+	trimDetailsFlag := flags.Lookup("trim-details")
+	assert.NotNil(t, trimDetailsFlag)
+	assert.Equal(t, "bool", trimDetailsFlag.Value.Type())
+
+	iamLastAccessedFlag := flags.Lookup("iam-last-accessed")
+	assert.NotNil(t, iamLastAccessedFlag)
+	assert.Equal(t, "bool", iamLastAccessedFlag.Value.Type())
+
+	accessAnalyzerFlag := flags.Lookup("access-analyzer")
+	assert.NotNil(t, accessAnalyzerFlag)
+	assert.Equal(t, "bool", accessAnalyzerFlag.Value.Type())
+
+	emrIdleHoursFlag := flags.Lookup("emr-idle-hours")
+	assert.NotNil(t, emrIdleHoursFlag)
+	assert.Equal(t, "int", emrIdleHoursFlag.Value.Type())
+
+	securityHubFlag := flags.Lookup("security-hub")
+	assert.NotNil(t, securityHubFlag)
+	assert.Equal(t, "bool", securityHubFlag.Value.Type())
+
+	securityHubRegionFlag := flags.Lookup("security-hub-region")
+	assert.NotNil(t, securityHubRegionFlag)
+	assert.Equal(t, "string", securityHubRegionFlag.Value.Type())
+
+	opsCenterFlag := flags.Lookup("ops-center")
+	assert.NotNil(t, opsCenterFlag)
+	assert.Equal(t, "bool", opsCenterFlag.Value.Type())
+
+	opsCenterRegionFlag := flags.Lookup("ops-center-region")
+	assert.NotNil(t, opsCenterRegionFlag)
+	assert.Equal(t, "string", opsCenterRegionFlag.Value.Type())
+
+	opsCenterMinSeverityFlag := flags.Lookup("ops-center-min-severity")
+	assert.NotNil(t, opsCenterMinSeverityFlag)
+	assert.Equal(t, "string", opsCenterMinSeverityFlag.Value.Type())
+
+	prefetchPricesFlag := flags.Lookup("prefetch-prices")
+	assert.NotNil(t, prefetchPricesFlag)
+	assert.Equal(t, "bool", prefetchPricesFlag.Value.Type())
+
+	includeSuspendedAccountsFlag := flags.Lookup("include-suspended-accounts")
+	assert.NotNil(t, includeSuspendedAccountsFlag)
+	assert.Equal(t, "bool", includeSuspendedAccountsFlag.Value.Type())
+
+	includeMetricSamplesFlag := flags.Lookup("include-metric-samples")
+	assert.NotNil(t, includeMetricSamplesFlag)
+	assert.Equal(t, "bool", includeMetricSamplesFlag.Value.Type())
+
+	confirmationScansFlag := flags.Lookup("confirmation-scans")
+	assert.NotNil(t, confirmationScansFlag)
+	assert.Equal(t, "int", confirmationScansFlag.Value.Type())
+
+	includeManagedResourcesFlag := flags.Lookup("include-managed-resources")
+	assert.NotNil(t, includeManagedResourcesFlag)
+	assert.Equal(t, "bool", includeManagedResourcesFlag.Value.Type())
+
+	accountSpendFlag := flags.Lookup("account-spend")
+	assert.NotNil(t, accountSpendFlag)
+	assert.Equal(t, "bool", accountSpendFlag.Value.Type())
+
+	spendSummaryFileFlag := flags.Lookup("spend-summary-file")
+	assert.NotNil(t, spendSummaryFileFlag)
+	assert.Equal(t, "string", spendSummaryFileFlag.Value.Type())
+
+	delegatedAdminFlag := flags.Lookup("delegated-admin")
+	assert.NotNil(t, delegatedAdminFlag)
+	assert.Equal(t, "bool", delegatedAdminFlag.Value.Type())
+
+	accountsFileFlag := flags.Lookup("accounts-file")
+	assert.NotNil(t, accountsFileFlag)
+	assert.Equal(t, "string", accountsFileFlag.Value.Type())
+
+	dynamodbTableFlag := flags.Lookup("dynamodb-table")
+	assert.NotNil(t, dynamodbTableFlag)
+	assert.Equal(t, "string", dynamodbTableFlag.Value.Type())
+
+	dynamodbRegionFlag := flags.Lookup("dynamodb-region")
+	assert.NotNil(t, dynamodbRegionFlag)
+	assert.Equal(t, "string", dynamodbRegionFlag.Value.Type())
+
+	maxApiCallsFlag := flags.Lookup("max-api-calls")
+	assert.NotNil(t, maxApiCallsFlag)
+	assert.Equal(t, "int", maxApiCallsFlag.Value.Type())
+
+	reasonVerbosityFlag := flags.Lookup("reason-verbosity")
+	assert.NotNil(t, reasonVerbosityFlag)
+	assert.Equal(t, "string", reasonVerbosityFlag.Value.Type())
+
+	accountBucketFlag := flags.Lookup("account-bucket")
+	assert.NotNil(t, accountBucketFlag)
+	assert.Equal(t, "string", accountBucketFlag.Value.Type())
+
+	accountPrefixFlag := flags.Lookup("account-prefix")
+	assert.NotNil(t, accountPrefixFlag)
+	assert.Equal(t, "string", accountPrefixFlag.Value.Type())
+
+	gracePeriodDaysFlag := flags.Lookup("grace-period-days")
+	assert.NotNil(t, gracePeriodDaysFlag)
+	assert.Equal(t, "int", gracePeriodDaysFlag.Value.Type())
+
+	maxWorkersPerAccountFlag := flags.Lookup("max-workers-per-account")
+	assert.NotNil(t, maxWorkersPerAccountFlag)
+	assert.Equal(t, "int", maxWorkersPerAccountFlag.Value.Type())
+
+	excludeWarmInstancesFlag := flags.Lookup("exclude-warm-instances")
+	assert.NotNil(t, excludeWarmInstancesFlag)
+	assert.Equal(t, "bool", excludeWarmInstancesFlag.Value.Type())
+
+	providerFlag := flags.Lookup("provider")
+	assert.NotNil(t, providerFlag)
+	assert.Equal(t, "string", providerFlag.Value.Type())
+
+	gcpProjectsFlag := flags.Lookup("gcp-projects")
+	assert.NotNil(t, gcpProjectsFlag)
+	assert.Equal(t, "string", gcpProjectsFlag.Value.Type())
+
+	gcpCredentialsFileFlag := flags.Lookup("gcp-credentials-file")
+	assert.NotNil(t, gcpCredentialsFileFlag)
+	assert.Equal(t, "string", gcpCredentialsFileFlag.Value.Type())
+
+	computeOptimizerFlag := flags.Lookup("compute-optimizer")
+	assert.NotNil(t, computeOptimizerFlag)
+	assert.Equal(t, "bool", computeOptimizerFlag.Value.Type())
+
+	costAllocationTagsFlag := flags.Lookup("cost-allocation-tags")
+	assert.NotNil(t, costAllocationTagsFlag)
+	assert.Equal(t, "string", costAllocationTagsFlag.Value.Type())
+
+	planOnlyFlag := flags.Lookup("plan-only")
+	assert.NotNil(t, planOnlyFlag)
+	assert.Equal(t, "bool", planOnlyFlag.Value.Type())
+
+	planFileFlag := flags.Lookup("plan-file")
+	assert.NotNil(t, planFileFlag)
+	assert.Equal(t, "string", planFileFlag.Value.Type())
+
+	csvPerAccountFlag := flags.Lookup("csv-per-account")
+	assert.NotNil(t, csvPerAccountFlag)
+	assert.Equal(t, "bool", csvPerAccountFlag.Value.Type())
+
+	edgeZonesFlag := flags.Lookup("edge-zones")
+	assert.NotNil(t, edgeZonesFlag)
+	assert.Equal(t, "bool", edgeZonesFlag.Value.Type())
+
+	excludeManagementAccountFlag := flags.Lookup("exclude-management-account")
+	assert.NotNil(t, excludeManagementAccountFlag)
+	assert.Equal(t, "bool", excludeManagementAccountFlag.Value.Type())
+
+	notifySlackWebhookFlag := flags.Lookup("notify-slack-webhook")
+	assert.NotNil(t, notifySlackWebhookFlag)
+	assert.Equal(t, "string", notifySlackWebhookFlag.Value.Type())
+
+	historyDirFlag := flags.Lookup("history-dir")
+	assert.NotNil(t, historyDirFlag)
+	assert.Equal(t, "string", historyDirFlag.Value.Type())
+
+	prometheusFileFlag := flags.Lookup("prometheus-file")
+	assert.NotNil(t, prometheusFileFlag)
+	assert.Equal(t, "string", prometheusFileFlag.Value.Type())
+
+	prometheusPushgatewayUrlFlag := flags.Lookup("prometheus-pushgateway-url")
+	assert.NotNil(t, prometheusPushgatewayUrlFlag)
+	assert.Equal(t, "string", prometheusPushgatewayUrlFlag.Value.Type())
+
+	prometheusListenAddressFlag := flags.Lookup("prometheus-listen-address")
+	assert.NotNil(t, prometheusListenAddressFlag)
+	assert.Equal(t, "string", prometheusListenAddressFlag.Value.Type())
+
+	compareToFlag := flags.Lookup("compare-to")
+	assert.NotNil(t, compareToFlag)
+	assert.Equal(t, "string", compareToFlag.Value.Type())
+
+	gcpImpersonateServiceAccountFlag := flags.Lookup("gcp-impersonate-service-account")
+	assert.NotNil(t, gcpImpersonateServiceAccountFlag)
+	assert.Equal(t, "string", gcpImpersonateServiceAccountFlag.Value.Type())
+}
+
+// probingScanner is a test scanner that implements ResourceProber
+type probingScanner struct {
+	testScanner
+	regionsWithResources map[string]bool
+}
+
+func (s *probingScanner) HasResources(opts awsinternal.ScanOptions) (bool, error) {
+	return s.regionsWithResources[opts.Region], nil
+}
+
+// TestFindEmptyTasks tests detection of empty region/scanner combinations
+func TestFindEmptyTasks(t *testing.T) {
+	originalMaxWorkers := config.Config.MaxWorkers
+	config.Config.MaxWorkers = 2
+	defer func() { config.Config.MaxWorkers = originalMaxWorkers }()
+
+	accounts := []awsinternal.Account{{ID: "123456789012", Name: "test"}}
+	sessions := map[string]*session.Session{"123456789012": {Config: &aws.Config{}}}
+	regions := []string{"us-east-1", "us-west-2", "eu-west-1"}
+
+	prober := &probingScanner{
+		testScanner:          testScanner{argumentName: "probe", label: "Probe"},
+		regionsWithResources: map[string]bool{"us-east-1": true},
+	}
+	plain := &testScanner{argumentName: "plain", label: "Plain"}
+
+	// eu-west-1 has tagged resources, so it is kept without probing
+	counts := awsinternal.NewResourceCounts()
+	counts.Set("123456789012", "eu-west-1", "probe", 3)
+
+	empty := findEmptyTasks(accounts, sessions, regions, []awsinternal.Scanner{prober, plain}, counts, nil)
+	assert.Equal(t, map[string]bool{"123456789012:us-west-2:probe": true}, empty)
+}
+
+// TestOverallProgress tests weighted progress tracking
+func TestOverallProgress(t *testing.T) {
+	progress := &overallProgress{unit: "resources"}
+	assert.Equal(t, 0.0, progress.percent())
+
+	progress.add(30)
+	progress.add(10)
+	progress.add(0)
+	progress.complete(10)
+	assert.InDelta(t, 25.0, progress.percent(), 0.001)
+
+	progress.complete(30)
+	progress.complete(0)
+	assert.InDelta(t, 100.0, progress.percent(), 0.001)
+}
+
+func TestTaskTimingsSlowest(t *testing.T) {
+	timings := &taskTimings{}
+	assert.Empty(t, timings.slowest(slowestTaskCount))
+
+	for i := 1; i <= 25; i++ {
+		timings.record(taskTiming{
+			Scanner:  fmt.Sprintf("scanner-%d", i),
+			Region:   "us-west-2",
+			Duration: time.Duration(i) * time.Millisecond,
+		})
+	}
+
+	slowest := timings.slowest(slowestTaskCount)
+	assert.Len(t, slowest, slowestTaskCount)
+	assert.Equal(t, "scanner-25", slowest[0].Scanner)
+	assert.Equal(t, "scanner-6", slowest[len(slowest)-1].Scanner)
+	for i := 1; i < len(slowest); i++ {
+		assert.GreaterOrEqual(t, slowest[i-1].Duration, slowest[i].Duration)
+	}
 }
 
 // TestGetScanners tests the getScanners function
@@ -384,6 +728,35 @@ func TestGetRoleARN(t *testing.T) {
 			expected:  "arn:aws:iam::123456789012:role/TestRole",
 			expectErr: false,
 		},
+		{
+			name:      "role name only - GovCloud caller",
+			roleName:  "TestRole",
+			accountID: "123456789012",
+			setupMocks: func() {
+				mockSTS.ExpectedCalls = nil
+				mockSTS.On("GetCallerIdentity", &sts.GetCallerIdentityInput{}).Return(
+					&sts.GetCallerIdentityOutput{
+						Account: aws.String("123456789012"),
+						Arn:     aws.String("arn:aws-us-gov:iam::123456789012:user/testuser"),
+					}, nil)
+			},
+			expected:  "arn:aws-us-gov:iam::123456789012:role/TestRole",
+			expectErr: false,
+		},
+		{
+			name:       "GovCloud ARN",
+			roleName:   "arn:aws-us-gov:iam::123456789012:role/TestRole",
+			setupMocks: func() {},
+			expected:   "arn:aws-us-gov:iam::123456789012:role/TestRole",
+			expectErr:  false,
+		},
+		{
+			name:       "China ARN",
+			roleName:   "arn:aws-cn:iam::123456789012:role/TestRole",
+			setupMocks: func() {},
+			expected:   "arn:aws-cn:iam::123456789012:role/TestRole",
+			expectErr:  false,
+		},
 		{
 			name:     "role name only - error",
 			roleName: "TestRole",
@@ -414,6 +787,12 @@ func TestGetRoleARN(t *testing.T) {
 			}
 		})
 	}
+
+	// Scanner roles are assumed in the partition of the scanned regions
+	assert.Equal(t, "arn:aws-us-gov:iam::123456789012:role/Scanner", awsinternal.RoleARN("us-gov-east-1", "123456789012", "Scanner"))
+	assert.Equal(t, "arn:aws-cn:iam::123456789012:role/Scanner", awsinternal.RoleARN("cn-north-1", "123456789012", "Scanner"))
+	assert.Equal(t, "arn:aws:iam::123456789012:role/Scanner", awsinternal.RoleARN("eu-west-1", "123456789012", "Scanner"))
+	assert.False(t, awsinternal.IsRoleARN("Scanner"))
 }
 
 // TestValidateS3Access tests the validateS3Access function
@@ -1428,3 +1807,2396 @@ func TestScanIntegration(t *testing.T) {
 		})
 	}
 }
+
+func TestParseScannerDaysUnused(t *testing.T) {
+	overrides, err := parseScannerDaysUnused("ebs-snapshots=180, elastic-ips=30")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"ebs-snapshots": 180, "elastic-ips": 30}, overrides)
+
+	overrides, err = parseScannerDaysUnused("")
+	require.NoError(t, err)
+	assert.Empty(t, overrides)
+
+	_, err = parseScannerDaysUnused("ebs-snapshots")
+	assert.Error(t, err)
+
+	_, err = parseScannerDaysUnused("ebs-snapshots=many")
+	assert.Error(t, err)
+}
+
+// TestScannerSelectionConfig tests the scanner enable and disable lists and account exclusions
+func TestScannerSelectionConfig(t *testing.T) {
+	originalRegistry := awsinternal.DefaultRegistry
+	originalConfig := *config.Config
+	defer func() {
+		awsinternal.DefaultRegistry = originalRegistry
+		*config.Config = originalConfig
+	}()
+
+	testRegistry := awsinternal.NewScannerRegistry()
+	awsinternal.DefaultRegistry = testRegistry
+	testRegistry.RegisterScanner(&testScanner{argumentName: "scanner1", label: "Scanner 1"})
+	testRegistry.RegisterScanner(&testScanner{argumentName: "scanner2", label: "Scanner 2"})
+	testRegistry.RegisterScanner(&testScanner{argumentName: "scanner3", label: "Scanner 3"})
+
+	config.Config.ScanEnabledScanners = []string{"scanner1", "scanner2"}
+	config.Config.ScanDisabledScanners = []string{"scanner2"}
+	config.Config.ScanAccountScannerExclusions = map[string][]string{"123456789012": {"scanner1"}}
+	require.NoError(t, validateScannerSelection())
+
+	// Enabled scanners replace the default, disabled scanners are dropped
+	scanners, invalidScanners, err := getScanners("")
+	require.NoError(t, err)
+	assert.Empty(t, invalidScanners)
+	require.Len(t, scanners, 1)
+	assert.Equal(t, "scanner1", scanners[0].ArgumentName())
+
+	// Disabled scanners are dropped even when selected
+	scanners, invalidScanners, err = getScanners("scanner2,scanner3")
+	require.NoError(t, err)
+	assert.Empty(t, invalidScanners)
+	require.Len(t, scanners, 1)
+	assert.Equal(t, "scanner3", scanners[0].ArgumentName())
+
+	assert.True(t, scannerExcluded("123456789012", "scanner1"))
+	assert.False(t, scannerExcluded("123456789012", "scanner3"))
+	assert.False(t, scannerExcluded("098765432109", "scanner1"))
+
+	config.Config.ScanAccountScannerExclusions = map[string][]string{"123456789012": {"unknown"}}
+	assert.Error(t, validateScannerSelection())
+}
+
+// TestScannerAliases tests that scanners can be selected, disabled and excluded by alias
+func TestScannerAliases(t *testing.T) {
+	originalRegistry := awsinternal.DefaultRegistry
+	originalConfig := *config.Config
+	defer func() {
+		awsinternal.DefaultRegistry = originalRegistry
+		*config.Config = originalConfig
+	}()
+
+	// The RDS scanner is also registered as rds-instances
+	scanner, err := originalRegistry.GetScanner("rds-instances")
+	require.NoError(t, err)
+	assert.Equal(t, "rds", scanner.ArgumentName())
+	assert.NotContains(t, originalRegistry.ListScanners(), "rds-instances")
+
+	testRegistry := awsinternal.NewScannerRegistry()
+	awsinternal.DefaultRegistry = testRegistry
+	testRegistry.RegisterScanner(&testScanner{argumentName: "scanner1", label: "Scanner 1"})
+	testRegistry.RegisterScanner(&testScanner{argumentName: "scanner2", label: "Scanner 2"})
+	testRegistry.RegisterAlias("first", "scanner1")
+
+	config.Config.ScanEnabledScanners = nil
+	config.Config.ScanDisabledScanners = nil
+	scanners, invalidScanners, err := getScanners("first")
+	require.NoError(t, err)
+	assert.Empty(t, invalidScanners)
+	require.Len(t, scanners, 1)
+	assert.Equal(t, "scanner1", scanners[0].ArgumentName())
+
+	config.Config.ScanDisabledScanners = []string{"first"}
+	scanners, _, err = getScanners("")
+	require.NoError(t, err)
+	require.Len(t, scanners, 1)
+	assert.Equal(t, "scanner2", scanners[0].ArgumentName())
+
+	config.Config.ScanAccountScannerExclusions = map[string][]string{"123456789012": {"scanner1"}}
+	assert.True(t, scannerExcluded("123456789012", "first"))
+}
+
+// TestGlobalRegions tests that IAM scanners run once per partition of the scanned regions
+func TestGlobalRegions(t *testing.T) {
+	assert.Equal(t, []string{"us-east-1"}, awsinternal.GlobalRegions(nil))
+	assert.Equal(t, []string{"us-east-1"}, awsinternal.GlobalRegions([]string{"eu-west-1", "us-west-2"}))
+	assert.Equal(t, []string{"us-east-1", "us-gov-west-1"}, awsinternal.GlobalRegions([]string{"us-gov-east-1", "eu-west-1", "us-gov-west-1"}))
+	assert.Equal(t, "us-east-1", awsinternal.PartitionHomeRegion("eu-west-1"))
+	assert.Equal(t, "cn-north-1", awsinternal.PartitionHomeRegion("cn-northwest-1"))
+
+	assert.Equal(t, "global", awsinternal.GlobalRegionLabel(awsinternal.RegionPartition("us-east-1")))
+	assert.Equal(t, "global-aws-us-gov", awsinternal.GlobalRegionLabel(awsinternal.RegionPartition("us-gov-west-1")))
+	assert.True(t, awsinternal.IsGlobalRegionLabel("global-aws-us-gov"))
+	assert.False(t, awsinternal.IsGlobalRegionLabel("us-east-1"))
+}
+
+func TestSTSConfig(t *testing.T) {
+	originalRegion := config.Config.STSRegion
+	defer func() { config.Config.STSRegion = originalRegion }()
+
+	sess := &session.Session{Config: aws.NewConfig().WithRegion("eu-west-1")}
+
+	config.Config.STSRegion = ""
+	cfg := awsinternal.STSConfig(sess)
+	assert.Equal(t, "eu-west-1", aws.StringValue(cfg.Region))
+	assert.Equal(t, endpoints.RegionalSTSEndpoint, cfg.STSRegionalEndpoint)
+	assert.Equal(t, "us-east-1", aws.StringValue(awsinternal.STSConfig(&session.Session{Config: aws.NewConfig()}).Region))
+
+	config.Config.STSRegion = "eu-central-1"
+	assert.Equal(t, "eu-central-1", aws.StringValue(awsinternal.STSConfig(sess).Region))
+}
+
+func TestHTTPClient(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "ok")
+	}))
+	defer server.Close()
+
+	// The server certificate is only trusted once it is in the CA bundle
+	client, err := awsinternal.NewHTTPClient(awsinternal.HTTPClientOptions{})
+	require.NoError(t, err)
+	_, err = client.Get(server.URL)
+	assert.Error(t, err)
+
+	bundle := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(bundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0600))
+	client, err = awsinternal.NewHTTPClient(awsinternal.HTTPClientOptions{CABundle: bundle, RequestTimeout: 5 * time.Second})
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Second, client.Timeout)
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// Requests go through the proxy, except those to instance metadata endpoints
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.String())
+	}))
+	defer proxy.Close()
+	client, err = awsinternal.NewHTTPClient(awsinternal.HTTPClientOptions{HTTPSProxy: proxy.URL})
+	require.NoError(t, err)
+	resp, err = client.Get("http://ec2.us-east-1.amazonaws.com/")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, []string{"http://ec2.us-east-1.amazonaws.com/"}, proxied)
+
+	transport := client.Transport.(*http.Transport)
+	metadataReq, _ := http.NewRequest(http.MethodGet, "http://169.254.169.254/latest/api/token", nil)
+	proxyURL, err := transport.Proxy(metadataReq)
+	require.NoError(t, err)
+	assert.Nil(t, proxyURL)
+
+	emptyBundle := filepath.Join(t.TempDir(), "empty.pem")
+	require.NoError(t, os.WriteFile(emptyBundle, []byte("not a certificate"), 0600))
+	for _, opts := range []awsinternal.HTTPClientOptions{
+		{HTTPSProxy: "proxy.example.com:3128"},
+		{CABundle: filepath.Join(t.TempDir(), "missing.pem")},
+		{CABundle: emptyBundle},
+		{RequestTimeout: -time.Second},
+	} {
+		_, err := awsinternal.NewHTTPClient(opts)
+		assert.Error(t, err, "options %+v", opts)
+	}
+
+	// Base sessions have no request timeout unless one is configured for them
+	t.Cleanup(func() {
+		require.NoError(t, awsinternal.ConfigureHTTPClient(awsinternal.HTTPClientOptions{RequestTimeout: awsinternal.DefaultRequestTimeout}))
+	})
+	require.NoError(t, awsinternal.ConfigureHTTPClient(awsinternal.HTTPClientOptions{RequestTimeout: awsinternal.DefaultRequestTimeout}))
+	assert.Equal(t, awsinternal.DefaultRequestTimeout, awsinternal.HTTPClient().Timeout)
+	assert.Zero(t, awsinternal.BaseHTTPClient().Timeout)
+	assert.Zero(t, awsinternal.SessionConfig().HTTPClient.Timeout)
+	require.NoError(t, awsinternal.ConfigureHTTPClient(awsinternal.HTTPClientOptions{RequestTimeout: time.Minute, BaseRequestTimeout: time.Minute}))
+	assert.Equal(t, time.Minute, awsinternal.BaseHTTPClient().Timeout)
+	assert.Error(t, awsinternal.ConfigureHTTPClient(awsinternal.HTTPClientOptions{BaseRequestTimeout: -time.Second}))
+}
+
+func TestFIPSEndpoints(t *testing.T) {
+	original := config.Config.UseFIPSEndpoints
+	defer func() { config.Config.UseFIPSEndpoints = original }()
+
+	assert.True(t, awsinternal.FIPSEndpointSupported("ec2", "us-east-1"))
+	assert.True(t, awsinternal.FIPSEndpointSupported("sts", "us-west-2"))
+	assert.False(t, awsinternal.FIPSEndpointSupported("sts", "ca-central-1"))
+	assert.True(t, awsinternal.FIPSEndpointSupported("iam", "us-east-1"))
+	assert.True(t, awsinternal.FIPSEndpointSupported("ec2", "us-gov-west-1"))
+	assert.False(t, awsinternal.FIPSEndpointSupported("ec2", "eu-west-1"))
+	assert.False(t, awsinternal.FIPSEndpointSupported("api.pricing", "us-east-1"))
+
+	err := awsinternal.ValidateFIPSEndpoints([]string{"ec2", "sts"}, []string{"us-east-1", "eu-west-1"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no FIPS endpoints for ec2 in eu-west-1, sts in eu-west-1;")
+	supported, unsupported := awsinternal.FIPSRegions([]string{"us-west-2", "eu-central-1", "us-gov-east-1"})
+	assert.Equal(t, []string{"us-west-2", "us-gov-east-1"}, supported)
+	assert.Equal(t, []string{"eu-central-1"}, unsupported)
+
+	config.Config.UseFIPSEndpoints = false
+	assert.Equal(t, endpoints.FIPSEndpointStateUnset, awsinternal.SessionConfig().UseFIPSEndpoint)
+	assert.Equal(t, endpoints.FIPSEndpointStateUnset, awsinternal.OptionalFIPSConfig("api.pricing", "us-east-1").UseFIPSEndpoint)
+
+	config.Config.UseFIPSEndpoints = true
+	assert.Equal(t, endpoints.FIPSEndpointStateEnabled, awsinternal.SessionConfig().UseFIPSEndpoint)
+	assert.Equal(t, endpoints.FIPSEndpointStateDisabled, awsinternal.OptionalFIPSConfig("api.pricing", "us-east-1").UseFIPSEndpoint)
+
+	// Requests to services without a FIPS endpoint in the region of the session are refused
+	sess, err := awsinternal.NewSession("", "us-east-1")
+	require.NoError(t, err)
+	ec2Client := ec2.New(sess)
+	assert.Equal(t, "https://ec2-fips.us-east-1.amazonaws.com", ec2Client.Endpoint)
+	req, _ := ec2Client.DescribeRegionsRequest(&ec2.DescribeRegionsInput{})
+	assert.NoError(t, req.Build())
+	req, _ = lightsail.New(sess).GetInstancesRequest(&lightsail.GetInstancesInput{})
+	err = req.Build()
+	var awsErr awserr.Error
+	require.ErrorAs(t, err, &awsErr)
+	assert.Equal(t, awsinternal.ErrCodeFIPSEndpointUnavailable, awsErr.Code())
+}
+
+func TestPrintScanSummary(t *testing.T) {
+	withCost := func(resourceType string, monthlyRate float64) awsinternal.ScanResult {
+		return awsinternal.ScanResult{
+			ResourceType: resourceType,
+			Cost:         map[string]interface{}{"total": &awsinternal.CostBreakdown{MonthlyRate: monthlyRate}},
+		}
+	}
+	accountResults := map[string]*scanResult{
+		"222222222222": {
+			AccountID:   "222222222222",
+			AccountName: "dev",
+			Results: map[string]awsinternal.ScanResults{
+				"EBS Volumes": {withCost("EBS Volumes", 8), withCost("EBS Volumes", 2)},
+				"IAM Roles":   {{ResourceType: "IAM Roles", Cost: awsinternal.NoCost(awsinternal.NoCostFree)}},
+			},
+		},
+		"111111111111": {
+			AccountID:   "111111111111",
+			AccountName: "111111111111",
+			Results: map[string]awsinternal.ScanResults{
+				"NAT Gateways": {withCost("NAT Gateways", 32.85)},
+				"Elastic IPs":  {withCost("Elastic IPs", 3.65)},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	printScanSummary(&buf, accountResults, "€")
+	assert.Equal(t, `
+Scan summary:
+ACCOUNT             FINDINGS  MONTHLY SAVINGS
+111111111111        2         €36.50
+dev (222222222222)  3         €10.00
+TOTAL               5         €46.50
+
+Top 3 resource types by monthly savings:
+RESOURCE TYPE  FINDINGS  MONTHLY SAVINGS
+NAT Gateways   1         €32.85
+EBS Volumes    2         €10.00
+Elastic IPs    1         €3.65
+`, buf.String())
+
+	lowConfidence := withCost("RDS Instances", 120)
+	lowConfidence.Details = map[string]interface{}{"metrics_status": "metrics unavailable", "confidence": "low"}
+	throttled := withCost("RDS Instances", 30)
+	throttled.Details = map[string]interface{}{"metrics_status": "metrics incomplete"}
+	accountResults["111111111111"].Results["RDS Instances"] = awsinternal.ScanResults{lowConfidence, throttled}
+	buf.Reset()
+	printScanSummary(&buf, accountResults, "€")
+	assert.Contains(t, buf.String(), "TOTAL               7         €46.50\n")
+	assert.Contains(t, buf.String(), "2 low confidence findings with €150.00/month are not counted")
+	assert.Equal(t, 46.5, notificationSummary(accountResults, "€", "").MonthlySavings)
+	assert.Equal(t, 150.0, notificationSummary(accountResults, "€", "").UnverifiedSavings)
+
+	// The metrics and Markdown summaries count the same savings
+	var metricsSavings float64
+	for _, group := range scanSummary(accountResults, html.ScanMetrics{}, "EUR").Groups {
+		metricsSavings += group.MonthlySavings
+	}
+	assert.InDelta(t, 46.5, metricsSavings, 0.001)
+	var allResults []awsinternal.ScanResult
+	for _, result := range accountResults {
+		for _, scannerResults := range result.Results {
+			allResults = append(allResults, scannerResults...)
+		}
+	}
+	buf.Reset()
+	require.NoError(t, output.WriteMarkdown(&buf, allResults, "€"))
+	assert.Contains(t, buf.String(), "estimated savings of **€46.50/month**")
+
+	buf.Reset()
+	printScanSummary(&buf, map[string]*scanResult{"111111111111": {AccountID: "111111111111"}}, "$")
+	assert.NotContains(t, buf.String(), "Top")
+	assert.Contains(t, buf.String(), "TOTAL         0         $0.00")
+}
+
+func TestManagedResourceMatcher(t *testing.T) {
+	matcher, err := awsinternal.NewManagedResourceMatcher([]config.ManagedResourceRule{{
+		ResourceType: "EBS Snapshots",
+		Details:      map[string]string{"description": "*created by the AWS Backup service*"},
+	}})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		result  awsinternal.ScanResult
+		managed bool
+	}{
+		{"service-linked role", awsinternal.ScanResult{ResourceType: "IAM Roles", ResourceName: "AWSServiceRoleForSupport", ResourceID: "arn:aws:iam::123456789012:role/aws-service-role/support.amazonaws.com/AWSServiceRoleForSupport"}, true},
+		{"reserved role", awsinternal.ScanResult{ResourceType: "IAM Roles", ResourceName: "AWSReservedSSO_Admin_0123", ResourceID: "arn:aws:iam::123456789012:role/aws-reserved/sso.amazonaws.com/AWSReservedSSO_Admin_0123"}, true},
+		{"customer role", awsinternal.ScanResult{ResourceType: "IAM Roles", ResourceName: "deploy", ResourceID: "arn:aws:iam::123456789012:role/deploy"}, false},
+		{"default VPC", awsinternal.ScanResult{ResourceType: "VPCs", ResourceID: "vpc-1", Details: map[string]interface{}{"is_default": true}}, true},
+		{"custom VPC", awsinternal.ScanResult{ResourceType: "VPCs", ResourceID: "vpc-2", Details: map[string]interface{}{"is_default": false}}, false},
+		{"configured rule", awsinternal.ScanResult{ResourceType: "EBS Snapshots", ResourceID: "snap-1", Details: map[string]interface{}{"description": "This snapshot is created by the AWS Backup service."}}, true},
+		{"rule of another resource type", awsinternal.ScanResult{ResourceType: "EBS Volumes", ResourceID: "vol-1", Details: map[string]interface{}{"description": "This snapshot is created by the AWS Backup service."}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, managed := matcher.Match(tt.result)
+			assert.Equal(t, tt.managed, managed)
+		})
+	}
+
+	_, err = awsinternal.NewManagedResourceMatcher([]config.ManagedResourceRule{{ResourceType: "VPCs"}})
+	assert.Error(t, err)
+}
+
+func TestLoadAccountList(t *testing.T) {
+	dir := t.TempDir()
+
+	jsonFile := filepath.Join(dir, "accounts.json")
+	require.NoError(t, os.WriteFile(jsonFile, []byte(`[
+		{"id": "111111111111", "name": "prod"},
+		{"id": "222222222222", "status": "suspended"}
+	]`), 0644))
+	accounts, err := awsinternal.LoadAccountList(nil, jsonFile)
+	require.NoError(t, err)
+	assert.Equal(t, []awsinternal.Account{
+		{ID: "111111111111", Name: "prod"},
+		{ID: "222222222222", Name: "222222222222", Status: "SUSPENDED"},
+	}, accounts)
+	assert.False(t, accounts[1].Active())
+
+	csvFile := filepath.Join(dir, "accounts.csv")
+	require.NoError(t, os.WriteFile(csvFile, []byte("account_id,account_name\n111111111111,prod\n333333333333,dev\n"), 0644))
+	accounts, err = awsinternal.LoadAccountList(nil, csvFile)
+	require.NoError(t, err)
+	assert.Equal(t, []awsinternal.Account{
+		{ID: "111111111111", Name: "prod"},
+		{ID: "333333333333", Name: "dev"},
+	}, accounts)
+
+	invalidFile := filepath.Join(dir, "invalid.csv")
+	require.NoError(t, os.WriteFile(invalidFile, []byte("id,name\n1234,prod\n"), 0644))
+	_, err = awsinternal.LoadAccountList(nil, invalidFile)
+	assert.ErrorContains(t, err, "line 2")
+
+	_, err = awsinternal.LoadAccountList(nil, "s3://bucket-without-key")
+	assert.ErrorContains(t, err, "invalid S3 location")
+}
+
+func TestDynamoDBFindingItem(t *testing.T) {
+	scanTime := time.Date(2024, 3, 1, 6, 0, 0, 0, time.UTC)
+	item, err := output.DynamoDBFindingItem(awsinternal.ScanResult{
+		ResourceType: "EBS Volumes",
+		ResourceName: "data",
+		ResourceID:   "vol-0123456789abcdef0",
+		AccountID:    "123456789012",
+		AccountName:  "prod",
+		Reason:       "Volume is unattached",
+		Tags:         map[string]string{"team": "data"},
+		Details:      map[string]interface{}{"region": "us-east-1", "size": 100},
+		Cost:         map[string]interface{}{"total": &awsinternal.CostBreakdown{MonthlyRate: 8}},
+	}, "EUR", scanTime)
+	require.NoError(t, err)
+
+	assert.Equal(t, "123456789012", aws.StringValue(item["pk"].S))
+	assert.Equal(t, "vol-0123456789abcdef0#EBS Volumes#2024-03-01T06:00:00Z", aws.StringValue(item["sk"].S))
+	assert.Equal(t, "us-east-1", aws.StringValue(item["region"].S))
+	assert.Equal(t, "8.0000", aws.StringValue(item["monthly_cost"].N))
+	assert.Equal(t, "EUR", aws.StringValue(item["currency"].S))
+	assert.Equal(t, "LOW", aws.StringValue(item["severity"].S))
+	assert.Equal(t, "data", aws.StringValue(item["tags"].M["team"].S))
+	assert.JSONEq(t, `{"region": "us-east-1", "size": 100}`, aws.StringValue(item["details"].S))
+	assert.NotContains(t, item, "account_team")
+	assert.NotContains(t, item, "details_truncated")
+
+	// Details over the item size limit are trimmed to their scalar fields, then dropped
+	samples := make([]float64, 200000)
+	for i := range samples {
+		samples[i] = 12.5
+	}
+	item, err = output.DynamoDBFindingItem(awsinternal.ScanResult{
+		ResourceType: "EC2 Instances",
+		ResourceID:   "i-1",
+		AccountID:    "123456789012",
+		Details:      map[string]interface{}{"region": "us-east-1", "cpu_samples": samples},
+	}, "USD", scanTime)
+	require.NoError(t, err)
+	assert.True(t, aws.BoolValue(item["details_truncated"].BOOL))
+	assert.JSONEq(t, `{"region": "us-east-1"}`, aws.StringValue(item["details"].S))
+	item, err = output.DynamoDBFindingItem(awsinternal.ScanResult{
+		ResourceType: "EC2 Instances",
+		ResourceID:   "i-1",
+		AccountID:    "123456789012",
+		Details:      map[string]interface{}{"region": "us-east-1", "note": strings.Repeat("x", 500*1024)},
+	}, "USD", scanTime)
+	require.NoError(t, err)
+	assert.True(t, aws.BoolValue(item["details_truncated"].BOOL))
+	assert.NotContains(t, item, "details")
+}
+
+// remediatingScanner is a test scanner suggesting a remediation command
+type remediatingScanner struct {
+	testScanner
+	template string
+}
+
+func (s *remediatingScanner) RemediationTemplate() string {
+	return s.template
+}
+
+func TestAddSuggestedRemediation(t *testing.T) {
+	scanner := &remediatingScanner{
+		testScanner: testScanner{argumentName: "test-load-balancers", label: "Test Load Balancers"},
+		template:    `{{if eq (index .Details "type") "classic"}}aws elb delete-load-balancer --load-balancer-name {{.ResourceID}}{{else}}aws elbv2 delete-load-balancer --load-balancer-arn {{.ResourceID}}{{end}} --region {{.Region}}`,
+	}
+	results := awsinternal.ScanResults{
+		{ResourceID: "legacy web", Details: map[string]interface{}{"type": "classic"}},
+		{ResourceID: "arn:aws:elasticloadbalancing:eu-west-1:123456789012:loadbalancer/app/web/1", Details: map[string]interface{}{"type": "application", "region": "eu-west-1"}},
+	}
+
+	awsinternal.AddSuggestedRemediation(scanner, results, "us-east-1")
+	assert.Equal(t, "aws elb delete-load-balancer --load-balancer-name 'legacy web' --region us-east-1", results[0].Details[awsinternal.RemediationDetail])
+	assert.Equal(t, "aws elbv2 delete-load-balancer --load-balancer-arn arn:aws:elasticloadbalancing:eu-west-1:123456789012:loadbalancer/app/web/1 --region eu-west-1", results[1].Details[awsinternal.RemediationDetail])
+
+	// Scanners without a template suggest nothing
+	plain := awsinternal.ScanResults{{ResourceID: "vol-1"}}
+	awsinternal.AddSuggestedRemediation(&testScanner{argumentName: "test", label: "Test"}, plain, "us-east-1")
+	assert.NotContains(t, plain[0].Details, awsinternal.RemediationDetail)
+}
+
+func TestScanContext(t *testing.T) {
+	contexts := awsinternal.NewScanContexts()
+	opts := awsinternal.ScanOptions{AccountID: "123456789012", Region: "us-east-1", Contexts: contexts}
+	assert.Same(t, opts.ScanContext(), contexts.Get("123456789012", "us-east-1"))
+	assert.NotSame(t, opts.ScanContext(), contexts.Get("123456789012", "eu-west-1"))
+
+	// Concurrent lookups compute the value once
+	var computed int32
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := opts.ScanContext().LoadOrCompute("images", func() (interface{}, error) {
+				atomic.AddInt32(&computed, 1)
+				return []string{"ami-1"}, nil
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"ami-1"}, value)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), computed)
+
+	// Failed computations are not cached
+	scanContext := contexts.Get("123456789012", "eu-west-1")
+	_, err := scanContext.LoadOrCompute("images", func() (interface{}, error) {
+		return nil, fmt.Errorf("throttled")
+	})
+	assert.Error(t, err)
+	_, ok := scanContext.Load("images")
+	assert.False(t, ok)
+	scanContext.Store("images", []string{"ami-2"})
+	value, ok := scanContext.Load("images")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"ami-2"}, value)
+
+	// Without scan contexts nothing is cached
+	opts.Contexts = nil
+	assert.Nil(t, opts.ScanContext())
+	value, err = opts.ScanContext().LoadOrCompute("images", func() (interface{}, error) {
+		return "computed", nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "computed", value)
+}
+
+// stubbedSession returns a session whose requests succeed without calling AWS
+func stubbedSession(t *testing.T) *session.Session {
+	sess, err := session.NewSession(aws.NewConfig().
+		WithRegion("us-east-1").
+		WithCredentials(credentials.NewStaticCredentials("id", "secret", "")))
+	require.NoError(t, err)
+	sess.Handlers.Send.Clear()
+	sess.Handlers.Send.PushBack(func(r *request.Request) {
+		r.HTTPResponse = &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(""))}
+	})
+	sess.Handlers.Unmarshal.Clear()
+	sess.Handlers.UnmarshalMeta.Clear()
+	sess.Handlers.ValidateResponse.Clear()
+	return sess
+}
+
+func TestAPICallTracker(t *testing.T) {
+	sess := stubbedSession(t)
+	tracker := awsinternal.NewAPICallTracker(4)
+	tracker.Attach(sess)
+	tracker.Attach(sess) // Attaching twice counts calls once
+
+	cw := cloudwatch.New(sess)
+	_, err := cw.GetMetricData(&cloudwatch.GetMetricDataInput{
+		StartTime:         aws.Time(time.Now().Add(-time.Hour)),
+		EndTime:           aws.Time(time.Now()),
+		MetricDataQueries: []*cloudwatch.MetricDataQuery{{Id: aws.String("a")}, {Id: aws.String("b")}},
+	})
+	require.NoError(t, err)
+	_, err = cw.ListMetrics(&cloudwatch.ListMetricsInput{})
+	require.NoError(t, err)
+	_, err = sts.New(sess).GetCallerIdentity(&sts.GetCallerIdentityInput{})
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(3), tracker.Total())
+	assert.Equal(t, []awsinternal.APICallCount{{Service: "monitoring", Calls: 2}, {Service: "sts", Calls: 1}}, tracker.Services())
+	assert.InDelta(t, 3*0.01/1000, tracker.EstimatedCost(), 1e-9)
+	assert.False(t, tracker.Exceeded())
+
+	// Calls over the budget fail
+	_, err = cw.ListMetrics(&cloudwatch.ListMetricsInput{})
+	require.NoError(t, err)
+	_, err = cw.ListMetrics(&cloudwatch.ListMetricsInput{})
+	assert.True(t, awsinternal.IsAPICallBudgetExceeded(fmt.Errorf("failed to list metrics: %w", err)))
+	assert.True(t, tracker.Exceeded())
+
+	// Regional sessions of scanners are tracked while a tracker is set
+	awsinternal.SetScanAPICallTracker(tracker)
+	defer awsinternal.SetScanAPICallTracker(nil)
+	regional, err := awsinternal.GetSessionInRegion(sess, "eu-west-1")
+	require.NoError(t, err)
+	_, err = sts.New(regional).GetCallerIdentity(&sts.GetCallerIdentityInput{})
+	assert.True(t, awsinternal.IsAPICallBudgetExceeded(err))
+}
+
+func TestApplyReasonVerbosity(t *testing.T) {
+	reason := "Very low CPU utilization (1.25%) in the last 30 days.\nVery low network activity (in: 0.10 KB/s, out: 0.02 KB/s) in the last 30 days."
+	newResults := func() awsinternal.ScanResults {
+		return awsinternal.ScanResults{{
+			ResourceID: "i-1",
+			Reason:     reason,
+			Details:    map[string]interface{}{"cpu_average": 1.25, "volume_count": 2, "state": "running"},
+		}}
+	}
+
+	normal := newResults()
+	awsinternal.ApplyReasonVerbosity(normal, awsinternal.ReasonVerbosityNormal, 30)
+	assert.Equal(t, reason, normal[0].Reason)
+
+	summary := newResults()
+	awsinternal.ApplyReasonVerbosity(summary, awsinternal.ReasonVerbositySummary, 30)
+	assert.Equal(t, "Very low CPU utilization in the last 30 days.", summary[0].Reason)
+
+	debug := newResults()
+	awsinternal.ApplyReasonVerbosity(debug, awsinternal.ReasonVerbosityDebug, 30)
+	assert.Equal(t, reason+"\nThreshold: days_unused=30\nSignals: cpu_average=1.25, volume_count=2", debug[0].Reason)
+
+	assert.NoError(t, awsinternal.ValidateReasonVerbosity("debug"))
+	assert.Error(t, awsinternal.ValidateReasonVerbosity("verbose"))
+}
+
+func TestSummarizeStoppedInstanceStorage(t *testing.T) {
+	stopped := func(accountID string, monthlyRate float64, sizes ...int64) awsinternal.ScanResult {
+		volumes := make([]map[string]interface{}, 0, len(sizes))
+		for _, size := range sizes {
+			volumes = append(volumes, map[string]interface{}{"VolumeType": "gp3", "SizeGB": size})
+		}
+		return awsinternal.ScanResult{
+			ResourceType: "EC2 Instances",
+			AccountID:    accountID,
+			AccountName:  "account-" + accountID,
+			Details:      map[string]interface{}{"state": "stopped", "ebs_volumes": volumes},
+			Cost:         map[string]interface{}{"total": &awsinternal.CostBreakdown{MonthlyRate: monthlyRate}},
+		}
+	}
+	running := stopped("111111111111", 50, 100)
+	running.Details["state"] = "running"
+	accelerated := stopped("111111111111", 8, 100)
+	accelerated.ResourceType = "Accelerated Instances"
+
+	summary := awsinternal.SummarizeStoppedInstanceStorage([]awsinternal.ScanResult{
+		stopped("111111111111", 8, 100),
+		stopped("222222222222", 12, 100, 50),
+		stopped("222222222222", 4, 50),
+		running,
+		accelerated,
+	})
+	assert.Equal(t, 3, summary.Instances)
+	assert.Equal(t, 4, summary.Volumes)
+	assert.Equal(t, int64(300), summary.SizeGB)
+	assert.InDelta(t, 24, summary.MonthlyCost, 0.001)
+	require.Len(t, summary.Accounts, 2)
+	assert.Equal(t, awsinternal.StoppedInstanceStorageAccount{
+		AccountID: "222222222222", AccountName: "account-222222222222", Instances: 2, Volumes: 3, SizeGB: 200, MonthlyCost: 16,
+	}, summary.Accounts[0])
+	assert.Equal(t, "111111111111", summary.Accounts[1].AccountID)
+}
+
+func TestSavingsTotalsLeaveOutLowConfidence(t *testing.T) {
+	// Details of results reported without the evidence that their resources are unused
+	unverified := map[string]map[string]interface{}{
+		"low confidence": {"confidence": awsinternal.LowConfidence},
+		// Throttled metrics are left out of the totals by their status alone
+		"metrics incomplete":  {"metrics_status": utils.MetricsIncomplete},
+		"metrics unavailable": {"metrics_status": utils.MetricsUnavailable},
+	}
+
+	for name, details := range unverified {
+		t.Run(name, func(t *testing.T) {
+			result := func(resourceID string, monthlyRate float64, details map[string]interface{}) awsinternal.ScanResult {
+				resultDetails := map[string]interface{}{
+					"region":      "us-east-1",
+					"state":       "stopped",
+					"ebs_volumes": []map[string]interface{}{{"VolumeType": "gp3", "SizeGB": int64(100)}},
+				}
+				for k, v := range details {
+					resultDetails[k] = v
+				}
+				return awsinternal.ScanResult{
+					ResourceType: "EC2 Instances",
+					ResourceID:   resourceID,
+					AccountID:    "111111111111",
+					AccountName:  "prod",
+					Tags:         map[string]string{"CostCenter": "1234"},
+					Details:      resultDetails,
+					Cost:         map[string]interface{}{"total": &awsinternal.CostBreakdown{MonthlyRate: monthlyRate, YearlyRate: monthlyRate * 12}},
+				}
+			}
+			results := awsinternal.ScanResults{result("i-1", 10, nil), result("i-2", 90, details)}
+			require.True(t, results[1].LowConfidence())
+			assert.Nil(t, results[1].SavingsBreakdown())
+
+			// The header totals count the finding but not its cost
+			summary := output.SummarizeResults(results)
+			assert.Equal(t, 2, summary.Findings)
+			assert.InDelta(t, 10, summary.MonthlySavings, 0.001)
+			assert.InDelta(t, 90, summary.UnverifiedSavings, 0.001)
+
+			// Waste forecast
+			assert.InDelta(t, 10, awsinternal.TotalMonthlyWaste(results), 0.001)
+
+			// Cost allocation
+			allocations := awsinternal.SummarizeCostAllocation(results, []string{"CostCenter"})
+			require.Len(t, allocations, 1)
+			assert.Equal(t, []awsinternal.TagCostAllocationGroup{
+				{Value: "1234", Findings: 2, MonthlySavings: 10, YearlySavings: 120},
+			}, allocations[0].Groups)
+
+			// EBS storage of stopped instances
+			storage := awsinternal.SummarizeStoppedInstanceStorage(results)
+			assert.Equal(t, 1, storage.Instances)
+			assert.InDelta(t, 10, storage.MonthlyCost, 0.001)
+
+			// Anomaly summaries
+			anomalySummary := anomaly.NewSummary(time.Now(), "USD")
+			anomalySummary.Add("111111111111", "prod", results)
+			assert.Equal(t, 2, anomalySummary.Accounts["111111111111"].Resources)
+			assert.InDelta(t, 10, anomalySummary.Accounts["111111111111"].MonthlySavings, 0.001)
+
+			// History run totals keep the cost of the finding apart
+			findings := []history.Finding{history.NewFinding(results[0]), history.NewFinding(results[1])}
+			assert.True(t, findings[1].LowConfidence)
+			assert.InDelta(t, 90, findings[1].MonthlyCost, 0.001)
+			run := history.NewRun(time.Now(), time.Now(), "USD", map[string]string{"111111111111": "prod"}, findings, history.NewScope(), history.RunMetrics{})
+			assert.Equal(t, 2, run.Findings)
+			assert.InDelta(t, 10, run.MonthlySavings, 0.001)
+			assert.InDelta(t, 10, run.AccountTotals["111111111111"].MonthlySavings, 0.001)
+			diff := history.DiffRuns(nil, findings)
+			require.Len(t, diff.ResourceTypes, 1)
+			assert.InDelta(t, 10, diff.ResourceTypes[0].CurrentSavings, 0.001)
+		})
+	}
+}
+
+func TestRenderAccountLocation(t *testing.T) {
+	location := output.AccountLocation{
+		AccountID:   "123456789012",
+		AccountName: "payments-prod",
+		Team:        "payments",
+		Environment: "prod",
+	}
+
+	bucket, err := output.RenderAccountLocation("cloudsift-{{.AccountID}}", location)
+	assert.NoError(t, err)
+	assert.Equal(t, "cloudsift-123456789012", bucket)
+
+	prefix, err := output.RenderAccountLocation("finops/{{.Team}}/{{.Environment}}", location)
+	assert.NoError(t, err)
+	assert.Equal(t, "finops/payments/prod", prefix)
+
+	prefix, err = output.RenderAccountLocation("", location)
+	assert.NoError(t, err)
+	assert.Equal(t, "", prefix)
+
+	_, err = output.RenderAccountLocation("cloudsift-{{.Unknown}}", location)
+	assert.Error(t, err)
+	_, err = output.RenderAccountLocation("cloudsift-{{.AccountID", location)
+	assert.Error(t, err)
+}
+
+func TestWithinGracePeriod(t *testing.T) {
+	now := time.Date(2025, 6, 15, 12, 0, 0, 0, time.UTC)
+	recent := now.Add(-3 * 24 * time.Hour)
+	old := now.Add(-30 * 24 * time.Hour)
+
+	tests := []struct {
+		name    string
+		details map[string]interface{}
+		days    int
+		want    bool
+	}{
+		{"recent RFC3339 string", map[string]interface{}{"created_at": recent.Format(time.RFC3339)}, 7, true},
+		{"old RFC3339 string", map[string]interface{}{"created_at": old.Format(time.RFC3339)}, 7, false},
+		{"recent time", map[string]interface{}{"creation_time": recent}, 7, true},
+		{"recent time pointer", map[string]interface{}{"creation_date": &recent}, 7, true},
+		{"launch time of a restarted instance", map[string]interface{}{"launch_time": recent.Format(time.RFC3339)}, 7, false},
+		{"disabled", map[string]interface{}{"created_at": recent.Format(time.RFC3339)}, 0, false},
+		{"no creation time", map[string]interface{}{"state": "available"}, 7, false},
+		{"unparseable creation time", map[string]interface{}{"created": "yesterday"}, 7, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := awsinternal.ScanResult{ResourceID: "r-1", Details: tt.details}
+			assert.Equal(t, tt.want, awsinternal.WithinGracePeriod(result, tt.days, now))
+		})
+	}
+}
+
+func TestValidateResult(t *testing.T) {
+	valid := awsinternal.ScanResult{
+		ResourceType: "EBS Volumes",
+		ResourceID:   "vol-1",
+		AccountID:    "123456789012",
+		Details:      map[string]interface{}{"region": "us-east-1"},
+		Cost:         map[string]interface{}{"total": &awsinternal.CostBreakdown{MonthlyRate: 8}},
+	}
+	assert.Empty(t, awsinternal.ValidateResult(valid))
+
+	free := valid
+	free.Cost = awsinternal.NoCost(awsinternal.NoCostFree)
+	assert.Empty(t, awsinternal.ValidateResult(free))
+
+	assert.Equal(t, []string{
+		"missing resource ID",
+		"missing resource type",
+		"missing account ID",
+		"missing region detail",
+		"missing cost or no-cost marker",
+	}, awsinternal.ValidateResult(awsinternal.ScanResult{}))
+
+	nilBreakdown := valid
+	nilBreakdown.Cost = map[string]interface{}{"total": (*awsinternal.CostBreakdown)(nil)}
+	assert.Equal(t, []string{"missing cost or no-cost marker"}, awsinternal.ValidateResult(nilBreakdown))
+}
+
+func TestApplyReasonCodes(t *testing.T) {
+	results := awsinternal.ScanResults{
+		{
+			ResourceID: "i-1",
+			Reason:     "Very low CPU utilization (1.20%) in the last 30 days.\nVery low network activity (in: 0.10 KB/s, out: 0.02 KB/s) in the last 30 days.\nVery low CPU utilization in the last 30 days.",
+			Details:    map[string]interface{}{},
+		},
+		{
+			ResourceID: "snap-1",
+			Reason:     "Snapshot is 2 years old.\nSnapshot is shared publicly.",
+			Details:    map[string]interface{}{"reason_codes": []string{"snapshot_public"}},
+		},
+		{
+			ResourceID: "vpc-1",
+			Reason:     "Route tables not associated with any subnet: rtb-1.\nThreshold: days_unused=30",
+		},
+		{
+			ResourceID: "x-1",
+			Reason:     "Something no code describes.",
+			Details:    map[string]interface{}{},
+		},
+	}
+	awsinternal.ApplyReasonCodes(results)
+
+	assert.Equal(t, []string{"low_cpu_utilization", "low_network_activity"}, results[0].Details["reason_codes"])
+	assert.Equal(t, []string{"snapshot_public", "old_snapshot"}, results[1].Details["reason_codes"])
+	assert.Equal(t, []string{"stale_route_table"}, results[2].Details["reason_codes"])
+	assert.NotContains(t, results[3].Details, "reason_codes")
+}
+
+func TestClassifyScannerReasons(t *testing.T) {
+	// One line of each reason scanners report, and lines that share a prefix with a reason
+	// without being one
+	reasons := []struct {
+		line string
+		code string
+	}{
+		{"Very low CPU utilization (1.20%) in the last 30 days.", "low_cpu_utilization"},
+		{"Candidate for scheduled stop/start: CPU averages 40.00% during business hours (Mon-Fri 08:00-18:00 UTC) but only 0.50% outside them in the last 30 days.", "scheduling_candidate"},
+		{"Very low network activity (in: 0.10 KB/s, out: 0.02 KB/s) in the last 30 days.", "low_network_activity"},
+		{"Very low network traffic (1.50 MB) in the last 30 days.", "low_network_activity"},
+		{"No network traffic in the last 30 days.", "low_network_activity"},
+		{"Very low I/O activity (reads: 0.10 IOPS, writes: 0.20 IOPS) in the last 30 days.", "low_io_activity"},
+		{"Very low activity (reads: 0.01/sec, writes: 0.02/sec) in the last 30 days.", "low_activity"},
+		{"Very low activity: 0.10 searches/hour, 0.20 indexes/hour with 1000 documents in the last 30 days.", "low_activity"},
+		{"Low read capacity utilization (2.00%) in the last 30 days.", "low_activity"},
+		{"Low write capacity utilization (2.00%) in the last 30 days.", "low_activity"},
+		{"Large table (12.50 GB) with low activity.", "low_activity"},
+		{"Low storage utilization (3.00% used).", "low_storage_utilization"},
+		{"Instance has been stopped for 45 days.", "stopped_instance"},
+		{"Lightsail instance is stopped but its bundle is still billed.", "stopped_instance"},
+		{"Lightsail database is stopped but its bundle is still billed.", "stopped_instance"},
+		{"Non-running state: stopped", "not_running"},
+		{"No active database connections", "no_database_connections"},
+		{"No database connections in the last 30 days.", "no_database_connections"},
+		{"Empty table with no read/write activity in the last 30 days.", "idle_table"},
+		{"Table has data but no read/write activity in the last 30 days.", "idle_table"},
+		{"Provisioned capacity (5 RCU, 5 WCU) unused and not decreased in 30 days.", "idle_provisioned_capacity"},
+		{"Cluster has data but no search, index, or delete activity in the last 30 days.", "idle_search_cluster"},
+		{"Cluster is empty with no search, index, or delete activity in the last 30 days.", "idle_search_cluster"},
+		{"Cluster has no search traffic in the last 30 days.", "idle_search_cluster"},
+		{"Volume has not been used in 45 days", "unused_volume"},
+		{"Volume has been idle 99.5% of the time in the last 30 days.", "unused_volume"},
+		{"Very low read activity (0.50 ops/day) in the last 30 days.", "unused_volume"},
+		{"Very low write activity (0.50 ops/day) in the last 30 days.", "unused_volume"},
+		{"No traffic recorded during the threshold period of 30 days", "low_gateway_traffic"},
+		{"Very low traffic variation (0.01) over 30 days", "low_gateway_traffic"},
+		{"NAT Gateway has no traffic in the last 30 days", "low_gateway_traffic"},
+		{"NAT Gateway has minimal traffic (1.50 MB) in the last 30 days", "low_gateway_traffic"},
+		{"NAT Gateway has outbound traffic only, no inbound traffic in the last 30 days", "low_gateway_traffic"},
+		{"NAT Gateway has inbound traffic only, no outbound traffic in the last 30 days", "low_gateway_traffic"},
+		{"Not associated with any resource (EC2 Instance or ENI)", "unattached_resource"},
+		{"Associated with stopped instance i-1", "unattached_resource"},
+		{"No resources attached", "unattached_resource"},
+		{"AMI has not been used by any instances for 90 days and has 8.00 GB in associated snapshots", "unused_ami"},
+		{"2 remaining snapshots are only kept for this AMI.", "unused_ami"},
+		{"Copy of web (ami-1) kept in 3 regions, but instances only launch from it in us-east-1.", "redundant_ami_copy"},
+		{"No instances launched from this copy in eu-west-1; its 8 GB of snapshots are redundant.", "redundant_ami_copy"},
+		{"Source volume was deleted. Snapshot has not been used in 30 days.", "orphaned_snapshot"},
+		{"Multiple snapshots exist for volume vol-1: 2 newer snapshots supersede this one.", "redundant_snapshot"},
+		{"Snapshot is 2 years old.", "old_snapshot"},
+		{"Bucket has 12 incomplete multipart uploads older than 7 days (1.50 GB).", "incomplete_multipart_uploads"},
+		{"Instance type p3.2xlarge carries 1 NVIDIA V100 GPU.", "accelerator_charges"},
+		{"1 Elastic GPU(s) attached, billed separately from the instance.", "accelerator_charges"},
+		{"1 Elastic Inference accelerator(s) attached, billed separately from the instance.", "accelerator_charges"},
+		{"Idle instance incurs Marketplace software charges of $0.1200/hour on top of EC2 costs.", "accelerator_charges"},
+		{"No requests in the last 30 days.", "no_requests"},
+		{"No deployments in the last 30 days.", "no_deployments"},
+		{"Project has never been built.", "unused_build_project"},
+		{"No builds in the last 30 days.", "unused_build_project"},
+		{"Fleet keeps 2 instances of reserved capacity but no project built on it in the last 30 days.", "idle_reserved_fleet"},
+		{"Pipeline has never been executed.", "unused_pipeline"},
+		{"No pipeline executions in the last 30 days.", "unused_pipeline"},
+		{"Dev endpoint has been running for 72 hours and is billed per DPU-hour.", "long_running_dev_endpoint"},
+		{"Crawler has never run.", "unused_crawler"},
+		{"Crawler has not run in the last 30 days.", "unused_crawler"},
+		{"Job has not run in the last 30 days but keeps 1.50 KB of bookmark state.", "stale_job_bookmark"},
+		{"Cluster has been waiting for 12 hours and no steps were ever submitted.", "idle_emr_cluster"},
+		{"Cluster has been waiting for 12 hours without new steps.", "idle_emr_cluster"},
+		{"No queries in the last 30 days but 1.50 GB of query results are still stored.", "stored_query_results"},
+		{"Metric stream sends to a deleted Firehose delivery stream.", "broken_metric_stream"},
+		{"Metric stream sends to a Firehose delivery stream that is DELETING.", "broken_metric_stream"},
+		{"Subscription filter delivers to a deleted Lambda function.", "broken_log_subscription"},
+		{"All invocations of the Lambda function the subscription filter delivers to failed in the last 30 days.", "broken_log_subscription"},
+		{"The Firehose delivery stream the subscription filter delivers to delivered no records to S3 in the last 30 days.", "broken_log_subscription"},
+		{"Subscription filter duplicates errors with the same pattern and destination.", "duplicate_log_subscription"},
+		{"Contributor Insights rule matched no log events in the last 30 days.", "idle_contributor_insights_rule"},
+		{"AWS Compute Optimizer reports over-provisioning: t3.small is recommended instead of t3.large.", "overprovisioned_resource"},
+		{"Role has never been used.", "unused_role"},
+		{"Role has not been used in 90 days.", "unused_role"},
+		{"IAM Access Analyzer reports the role as unused.", "unused_role"},
+		{"User has never logged in to the console", "unused_console_access"},
+		{"User has not logged in to the console in 90 days", "unused_console_access"},
+		{"IAM Access Analyzer reports an unused console password.", "unused_console_access"},
+		{"User has never used access keys", "unused_access_key"},
+		{"User has not used access keys in 90 days", "unused_access_key"},
+		{"IAM Access Analyzer reports an unused access key.", "unused_access_key"},
+		{"IAM Access Analyzer reports unused permissions.", "unused_permissions"},
+		{"OIDC provider created 120 days ago is not trusted by any role.", "unused_identity_provider"},
+		{"SAML provider created 120 days ago is not trusted by any role.", "unused_identity_provider"},
+		{"Snapshot is shared publicly.", "snapshot_public"},
+		{"Snapshot is shared with unknown accounts: 999999999999.", "snapshot_shared_unknown_account"},
+		{"Role has no attached policies.", "role_without_policies"},
+		{"VPC has no EC2 Instances or ENIs", "empty_vpc"},
+		{"Route tables not associated with any subnet: rtb-1.", "stale_route_table"},
+		{"Egress-only internet gateways without routes: eigw-1.", "unrouted_gateway"},
+		{"Carrier gateways without routes: cagw-1.", "unrouted_gateway"},
+		{"Internet gateways not attached to a VPC: igw-1.", "detached_gateway"},
+		{"Egress-only internet gateways not attached to a VPC: eigw-1.", "detached_gateway"},
+		{"Virtual private gateways not attached to a VPC: vgw-1.", "detached_gateway"},
+		{"DHCP option sets not used by any VPC: dopt-1.", "unused_dhcp_options"},
+		{"No lifecycle rule aborts incomplete multipart uploads.", "missing_multipart_lifecycle_rule"},
+		{"Query results (1.50 GB) are not expired by a lifecycle rule.", "unexpired_query_results"},
+		{"Last crawl did not succeed (FAILED).", "failed_crawler"},
+		{"Composite alarm references deleted alarms: cpu-high.", "broken_composite_alarm"},
+		{"Composite alarm has no actions and is not used by another composite alarm.", "unused_composite_alarm"},
+		{"AMI cannot be launched because its backing snapshots were deleted: snap-1.", "broken_ami"},
+		{"Instance profile created 90 days ago is not used by any EC2 instance.", "unattached_instance_profile"},
+		{"Instance profile has no role.", "unattached_instance_profile"},
+		{"Instance is kept in the warm pool of Auto Scaling group web (Warmed:Stopped).", "warm_pool_instance"},
+		{"Instance has been hibernated for 12 days", "hibernated_instance"},
+		{"Schedule has been disabled since 2026-01-02.", "stale_schedule"},
+		{"Schedule ended on 2026-01-02 and does not run again.", "stale_schedule"},
+		{"One-time schedule ran on 2026-01-02 and was not deleted.", "stale_schedule"},
+		{"Schedule targets a deleted resource.", "broken_schedule"},
+		{"Scheduled rule targets only deleted resources.", "broken_schedule"},
+		{"Scheduled rule is disabled.", "disabled_scheduled_rule"},
+		{"Scheduled rule duplicates schedule nightly with the same expression and target.", "migrated_scheduled_rule"},
+		{"CloudWatch metrics unavailable, usage could not be verified.", "metrics_unavailable"},
+		{"High JVM memory pressure (92.00%).", ""},
+		{"Table experienced 12 throttled requests per hour on average.", ""},
+		{"Its snapshot cost is counted by the AMIs finding of the copy.", ""},
+		{"Instance type t3.micro has no accelerators.", ""},
+		{"Bucket has versioning enabled.", ""},
+		{"Query results location is not set.", ""},
+		{"Copy of the snapshot in us-east-1.", ""},
+		{"NAT Gateway has 2 routes.", ""},
+	}
+	covered := make(map[string]bool)
+	for _, reason := range reasons {
+		var want []string
+		if reason.code != "" {
+			want = []string{reason.code}
+			covered[reason.code] = true
+		}
+		assert.Equal(t, want, awsinternal.ClassifyReason(reason.line), reason.line)
+	}
+	for _, code := range awsinternal.ReasonCodes {
+		assert.True(t, covered[code.Code], "no reason of code %s", code.Code)
+	}
+}
+
+func TestClassifyRDSReasons(t *testing.T) {
+	// An idle, stopped RDS instance without any metric datapoints has every RDS reason
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		switch r.Form.Get("Action") {
+		case "DescribeDBInstances":
+			fmt.Fprint(w, `<DescribeDBInstancesResponse xmlns="http://rds.amazonaws.com/doc/2014-10-31/"><DescribeDBInstancesResult><DBInstances><DBInstance>`+
+				`<DBInstanceIdentifier>db-1</DBInstanceIdentifier><DBInstanceArn>arn:aws:rds:us-east-1:123456789012:db:db-1</DBInstanceArn>`+
+				`<DBInstanceStatus>stopped</DBInstanceStatus><DBInstanceClass>db.t3.micro</DBInstanceClass><Engine>mysql</Engine>`+
+				`<InstanceCreateTime>2024-01-01T00:00:00Z</InstanceCreateTime></DBInstance></DBInstances></DescribeDBInstancesResult></DescribeDBInstancesResponse>`)
+		case "GetMetricData":
+			fmt.Fprint(w, `<GetMetricDataResponse xmlns="http://monitoring.amazonaws.com/doc/2010-08-01/"><GetMetricDataResult><MetricDataResults/></GetMetricDataResult></GetMetricDataResponse>`)
+		default:
+			// Prices cannot be looked up, so the instance is reported without an estimate
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"__type":"ValidationException","message":"not available"}`)
+		}
+	}))
+	defer server.Close()
+
+	sess, err := session.NewSession(&aws.Config{
+		Region:      aws.String("us-east-1"),
+		Endpoint:    aws.String(server.URL),
+		Credentials: credentials.NewStaticCredentials("AKID", "SECRET", ""),
+		MaxRetries:  aws.Int(0),
+	})
+	require.NoError(t, err)
+	previousEstimator := awsinternal.DefaultCostEstimator
+	awsinternal.DefaultCostEstimator, err = awsinternal.NewCostEstimator(sess, filepath.Join(t.TempDir(), "costs.json"))
+	require.NoError(t, err)
+	defer func() { awsinternal.DefaultCostEstimator = previousEstimator }()
+
+	scanner, err := awsinternal.DefaultRegistry.GetScanner("rds")
+	require.NoError(t, err)
+	results, err := scanner.Scan(awsinternal.ScanOptions{Session: sess, Region: "us-east-1", DaysUnused: 30, AccountID: "123456789012"})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+
+	// Each reason is a line of its own, so every one of them gets a code
+	assert.Len(t, strings.Split(results[0].Reason, "\n"), 4)
+	assert.Equal(t, []string{"no_database_connections", "low_cpu_utilization", "low_io_activity", "stopped_instance"},
+		awsinternal.ClassifyReason(results[0].Reason))
+}
+
+func TestKeyedLimiter(t *testing.T) {
+	limiter := worker.NewKeyedLimiter(1, map[string]int{"222222222222": 2, "333333333333": 0})
+
+	assert.Equal(t, 1, limiter.Limit("111111111111"))
+	assert.Equal(t, 2, limiter.Limit("222222222222"))
+	assert.Equal(t, 0, limiter.Limit("333333333333"))
+
+	// The queued tasks of an account wait longer than the task timeout, which only starts
+	// once a task runs
+	pool := worker.NewPool(3)
+	pool.SetTaskTimeout(100 * time.Millisecond)
+	pool.Start()
+	defer pool.Stop()
+
+	var running, maxRunning int32
+	var mu sync.Mutex
+	var order []string
+	var tasks []worker.KeyedTask
+	for i := 0; i < 5; i++ {
+		tasks = append(tasks, worker.KeyedTask{Key: "111111111111", Task: func(ctx context.Context) error {
+			n := atomic.AddInt32(&running, 1)
+			for {
+				m := atomic.LoadInt32(&maxRunning)
+				if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+					break
+				}
+			}
+			defer atomic.AddInt32(&running, -1)
+			select {
+			case <-time.After(60 * time.Millisecond):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			mu.Lock()
+			order = append(order, "111111111111")
+			mu.Unlock()
+			return nil
+		}})
+	}
+	// Unlimited accounts keep the other workers busy while the limited account waits
+	for i := 0; i < 3; i++ {
+		tasks = append(tasks, worker.KeyedTask{Key: "333333333333", Task: func(ctx context.Context) error {
+			mu.Lock()
+			order = append(order, "333333333333")
+			mu.Unlock()
+			return nil
+		}})
+	}
+	pool.ExecuteKeyedTasks(tasks, limiter)
+
+	metrics := pool.GetMetrics()
+	assert.Equal(t, int64(8), metrics.CompletedTasks)
+	assert.Equal(t, int64(0), metrics.FailedTasks)
+	assert.Equal(t, int32(1), maxRunning)
+	require.Len(t, order, 8)
+	assert.Equal(t, []string{"333333333333", "333333333333", "333333333333"}, order[:3])
+}
+
+func TestGCPScanners(t *testing.T) {
+	old := time.Now().Add(-60 * 24 * time.Hour).UTC().Format(time.RFC3339)
+	recent := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			require.NoError(t, r.ParseForm())
+			assert.Equal(t, "urn:ietf:params:oauth:grant-type:jwt-bearer", r.Form.Get("grant_type"))
+			assert.Len(t, strings.Split(r.Form.Get("assertion"), "."), 3)
+			fmt.Fprint(w, `{"access_token":"token-1","expires_in":3600}`)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer token-1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error":{"message":"missing token"}}`)
+			return
+		}
+
+		var body interface{}
+		switch r.URL.Path {
+		case "/compute/v1/projects/p1/aggregated/disks":
+			body = map[string]interface{}{"items": map[string]interface{}{
+				"zones/us-central1-a": map[string]interface{}{"disks": []map[string]interface{}{
+					{"id": "1", "name": "unattached", "zone": server.URL + "/zones/us-central1-a", "type": server.URL + "/diskTypes/pd-ssd", "sizeGb": "100", "creationTimestamp": old, "selfLink": "disk-1"},
+					{"id": "2", "name": "attached", "zone": server.URL + "/zones/us-central1-a", "type": server.URL + "/diskTypes/pd-ssd", "sizeGb": "10", "creationTimestamp": old, "users": []string{"instance"}, "selfLink": "disk-2"},
+					{"id": "3", "name": "detached-recently", "zone": server.URL + "/zones/us-central1-a", "type": server.URL + "/diskTypes/pd-standard", "sizeGb": "10", "creationTimestamp": old, "lastDetachTimestamp": recent, "selfLink": "disk-3"},
+					{"id": "4", "name": "stopped-boot", "zone": server.URL + "/zones/us-central1-a", "type": server.URL + "/diskTypes/pd-balanced", "sizeGb": "20", "creationTimestamp": old, "users": []string{"stopped"}, "selfLink": "disk-4"},
+				}},
+				"zones/europe-west1-b": map[string]interface{}{"warning": map[string]interface{}{"code": "NO_RESULTS_ON_PAGE"}},
+			}}
+		case "/compute/v1/projects/p1/aggregated/addresses":
+			body = map[string]interface{}{"items": map[string]interface{}{
+				"regions/us-central1": map[string]interface{}{"addresses": []map[string]interface{}{
+					{"id": "10", "name": "reserved", "region": server.URL + "/regions/us-central1", "address": "34.1.2.3", "addressType": "EXTERNAL", "status": "RESERVED", "creationTimestamp": old},
+					{"id": "11", "name": "in-use", "region": server.URL + "/regions/us-central1", "address": "34.1.2.4", "addressType": "EXTERNAL", "status": "IN_USE", "creationTimestamp": old},
+					{"id": "12", "name": "internal", "region": server.URL + "/regions/us-central1", "address": "10.0.0.2", "addressType": "INTERNAL", "status": "RESERVED", "creationTimestamp": old},
+				}},
+			}}
+		case "/compute/v1/projects/p1/aggregated/instances":
+			body = map[string]interface{}{"items": map[string]interface{}{
+				"zones/us-central1-a": map[string]interface{}{"instances": []map[string]interface{}{
+					{"id": "20", "name": "idle", "zone": server.URL + "/zones/us-central1-a", "machineType": server.URL + "/machineTypes/e2-small", "status": "RUNNING", "creationTimestamp": old},
+					{"id": "21", "name": "busy", "zone": server.URL + "/zones/us-central1-a", "machineType": server.URL + "/machineTypes/e2-small", "status": "RUNNING", "creationTimestamp": old},
+					{"id": "22", "name": "stopped", "zone": server.URL + "/zones/us-central1-a", "machineType": server.URL + "/machineTypes/e2-small", "status": "TERMINATED", "creationTimestamp": old, "lastStopTimestamp": old,
+						"disks": []map[string]interface{}{{"source": "disk-4", "diskSizeGb": "20", "boot": true}}},
+				}},
+			}}
+		case "/v3/projects/p1/timeSeries":
+			value := 0.01
+			if strings.Contains(r.URL.Query().Get("filter"), `"21"`) {
+				value = 0.5
+			}
+			body = map[string]interface{}{"timeSeries": []map[string]interface{}{
+				{"points": []map[string]interface{}{{"value": map[string]interface{}{"doubleValue": value}}}},
+			}}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(body))
+	}))
+	defer server.Close()
+
+	// Service account key exchanging a signed assertion for a token
+	creds, err := gcp.FindCredentials(nil, writeGCPServiceAccountKey(t, server.URL+"/token"), "")
+	require.NoError(t, err)
+	client := gcp.NewClient(creds)
+	client.ComputeEndpoint = server.URL + "/compute/v1"
+	client.MonitoringEndpoint = server.URL + "/v3"
+
+	scanners, err := getGCPScanners("")
+	require.NoError(t, err)
+	require.Len(t, scanners, 3)
+	_, err = getGCPScanners("persistent-disks,ebs-volumes")
+	assert.ErrorContains(t, err, "ebs-volumes")
+
+	found := make(map[string]awsinternal.ScanResult)
+	for _, scanner := range scanners {
+		results, err := scanner.Scan(gcp.ScanOptions{Project: "p1", DaysUnused: 30, Client: client})
+		require.NoError(t, err, scanner.ArgumentName())
+		for _, result := range results {
+			found[result.ResourceName] = result
+		}
+	}
+
+	assert.ElementsMatch(t, []string{"unattached", "reserved", "idle", "stopped"}, func() []string {
+		var names []string
+		for name := range found {
+			names = append(names, name)
+		}
+		return names
+	}())
+	assert.Equal(t, "us-central1", found["unattached"].Details["region"])
+	// Monthly rates are 30 days of the hourly rate of the monthly list price, as for AWS
+	assert.Equal(t, 16.77, found["unattached"].Cost["total"].(*awsinternal.CostBreakdown).MonthlyRate)
+	assert.Equal(t, 1.97, found["stopped"].Cost["total"].(*awsinternal.CostBreakdown).MonthlyRate)
+	assert.Equal(t, "Very low CPU utilization (1.00%) in the last 30 days.", found["idle"].Reason)
+	assert.Equal(t, awsinternal.NoCostNotEstimated, found["idle"].Cost[awsinternal.NoCostKey])
+	assert.Equal(t, "Not associated with any resource", found["reserved"].Reason)
+	for name, result := range found {
+		result.AccountID = "p1" // Set by the scan
+		assert.Empty(t, awsinternal.ValidateResult(result), name)
+	}
+}
+
+// writeGCPServiceAccountKey writes a service account key getting its tokens from tokenURL
+func writeGCPServiceAccountKey(t *testing.T, tokenURL string) string {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	credsFile := filepath.Join(t.TempDir(), "key.json")
+	credsJSON, err := json.Marshal(map[string]string{
+		"type":         "service_account",
+		"client_email": "scanner@p1.iam.gserviceaccount.com",
+		"private_key":  string(keyPEM),
+		"token_uri":    tokenURL,
+	})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(credsFile, credsJSON, 0600))
+	return credsFile
+}
+
+// roundTripFunc is an HTTP transport of a function
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestGCPTokenCaching(t *testing.T) {
+	var fetches int32
+	expiresIn := 3600
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&fetches, 1)
+		fmt.Fprintf(w, `{"access_token":"token-%d","expires_in":%d}`, n, expiresIn)
+	}))
+	defer server.Close()
+
+	creds, err := gcp.FindCredentials(nil, writeGCPServiceAccountKey(t, server.URL), "")
+	require.NoError(t, err)
+
+	// Tokens are cached until shortly before they expire
+	for i := 0; i < 3; i++ {
+		token, err := creds.Token()
+		require.NoError(t, err)
+		assert.Equal(t, "token-1", token)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&fetches))
+
+	// Tokens about to expire are refreshed
+	expiresIn = 5
+	creds, err = gcp.FindCredentials(nil, writeGCPServiceAccountKey(t, server.URL), "")
+	require.NoError(t, err)
+	first, err := creds.Token()
+	require.NoError(t, err)
+	second, err := creds.Token()
+	require.NoError(t, err)
+	assert.NotEqual(t, first, second)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&fetches))
+}
+
+func TestGCPExternalAccountCredentials(t *testing.T) {
+	var impersonations int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/subject":
+			assert.Equal(t, "Bearer ci", r.Header.Get("Authorization"))
+			fmt.Fprint(w, `{"value":"oidc-token"}`)
+		case "/sts":
+			require.NoError(t, r.ParseForm())
+			assert.Equal(t, "urn:ietf:params:oauth:grant-type:token-exchange", r.Form.Get("grant_type"))
+			assert.Equal(t, "oidc-token", r.Form.Get("subject_token"))
+			assert.Equal(t, "urn:ietf:params:oauth:token-type:jwt", r.Form.Get("subject_token_type"))
+			assert.Equal(t, "//iam.googleapis.com/projects/1/locations/global/workloadIdentityPools/ci/providers/ci", r.Form.Get("audience"))
+			assert.Equal(t, "https://www.googleapis.com/auth/cloud-platform", r.Form.Get("scope"), "impersonation needs the cloud-platform scope")
+			fmt.Fprint(w, `{"access_token":"federated","expires_in":3600}`)
+		case "/v1/projects/-/serviceAccounts/scanner@p1.iam.gserviceaccount.com:generateAccessToken":
+			atomic.AddInt32(&impersonations, 1)
+			assert.Equal(t, "Bearer federated", r.Header.Get("Authorization"))
+			var body struct {
+				Scope []string `json:"scope"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, []string{"https://www.googleapis.com/auth/cloud-platform.read-only"}, body.Scope)
+			fmt.Fprintf(w, `{"accessToken":"impersonated","expireTime":%q}`, time.Now().Add(time.Hour).UTC().Format(time.RFC3339))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	credsFile := filepath.Join(t.TempDir(), "external.json")
+	credsJSON, err := json.Marshal(map[string]interface{}{
+		"type":                              "external_account",
+		"audience":                          "//iam.googleapis.com/projects/1/locations/global/workloadIdentityPools/ci/providers/ci",
+		"subject_token_type":                "urn:ietf:params:oauth:token-type:jwt",
+		"token_url":                         server.URL + "/sts",
+		"service_account_impersonation_url": server.URL + "/v1/projects/-/serviceAccounts/scanner@p1.iam.gserviceaccount.com:generateAccessToken",
+		"credential_source": map[string]interface{}{
+			"url":     server.URL + "/subject",
+			"headers": map[string]string{"Authorization": "Bearer ci"},
+			"format":  map[string]string{"type": "json", "subject_token_field_name": "value"},
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(credsFile, credsJSON, 0600))
+
+	creds, err := gcp.FindCredentials(nil, credsFile, "")
+	require.NoError(t, err)
+	assert.Equal(t, credsFile+" impersonating scanner@p1.iam.gserviceaccount.com", creds.Source)
+	for i := 0; i < 2; i++ {
+		token, err := creds.Token()
+		require.NoError(t, err)
+		assert.Equal(t, "impersonated", token)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&impersonations), "impersonated tokens are cached until they expire")
+
+	// Unknown credential types are refused when loading the credentials
+	require.NoError(t, os.WriteFile(credsFile, []byte(`{"type":"api_key"}`), 0600))
+	_, err = gcp.FindCredentials(nil, credsFile, "")
+	assert.ErrorContains(t, err, `unknown credential type: "api_key"`)
+}
+
+func TestGCPImpersonateServiceAccount(t *testing.T) {
+	var impersonations int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			fmt.Fprint(w, `{"access_token":"source","expires_in":3600}`)
+		case "/v1/projects/-/serviceAccounts/scanner@p1.iam.gserviceaccount.com:generateAccessToken":
+			atomic.AddInt32(&impersonations, 1)
+			assert.Equal(t, "Bearer source", r.Header.Get("Authorization"))
+			fmt.Fprintf(w, `{"accessToken":"impersonated","expireTime":%q}`, time.Now().Add(time.Hour).UTC().Format(time.RFC3339))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	// The IAM Credentials API is reached at its public endpoint, which the test client routes
+	// to the server
+	client := &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		if r.URL.Host == "iamcredentials.googleapis.com" {
+			r.URL.Scheme = "http"
+			r.URL.Host = strings.TrimPrefix(server.URL, "http://")
+		}
+		return http.DefaultTransport.RoundTrip(r)
+	})}
+
+	credsFile := writeGCPServiceAccountKey(t, server.URL+"/token")
+	creds, err := gcp.FindCredentials(client, credsFile, "scanner@p1.iam.gserviceaccount.com")
+	require.NoError(t, err)
+	assert.Equal(t, credsFile+" impersonating scanner@p1.iam.gserviceaccount.com", creds.Source)
+	for i := 0; i < 2; i++ {
+		token, err := creds.Token()
+		require.NoError(t, err)
+		assert.Equal(t, "impersonated", token)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&impersonations))
+
+	// Without a credentials file there is nothing to impersonate with
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", "")
+	t.Setenv("CLOUDSDK_CONFIG", t.TempDir())
+	_, err = gcp.FindCredentials(client, "", "scanner@p1.iam.gserviceaccount.com")
+	assert.ErrorContains(t, err, "needs a credentials file")
+}
+
+func TestGCPAggregatedListPaging(t *testing.T) {
+	var pages []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			fmt.Fprint(w, `{"access_token":"token","expires_in":3600}`)
+			return
+		}
+		pageToken := r.URL.Query().Get("pageToken")
+		pages = append(pages, pageToken)
+		switch pageToken {
+		case "":
+			fmt.Fprint(w, `{"items":{"zones/us-central1-a":{"disks":[{"id":"1","name":"first"}]}},"nextPageToken":"page-2"}`)
+		case "page-2":
+			fmt.Fprint(w, `{"items":{"zones/us-central1-b":{"disks":[{"id":"2","name":"second"}]},"zones/us-east1-b":{"warning":{"code":"NO_RESULTS_ON_PAGE"}}}}`)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	creds, err := gcp.FindCredentials(nil, writeGCPServiceAccountKey(t, server.URL+"/token"), "")
+	require.NoError(t, err)
+	client := gcp.NewClient(creds)
+	client.HTTPClient = server.Client()
+	client.ComputeEndpoint = server.URL + "/compute/v1"
+
+	disks, err := client.Disks(context.Background(), "p1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"", "page-2"}, pages)
+	var names []string
+	for _, disk := range disks {
+		names = append(names, disk.Name)
+	}
+	assert.Equal(t, []string{"first", "second"}, names)
+}
+
+func TestValidateGCPOptions(t *testing.T) {
+	assert.ErrorContains(t, validateGCPOptions(&scanOptions{}), "--gcp-projects")
+	assert.NoError(t, validateGCPOptions(&scanOptions{gcpProjects: "p1", output: "filesystem"}))
+	assert.ErrorContains(t, validateGCPOptions(&scanOptions{gcpProjects: "p1", output: "s3-account"}), "--output=s3-account")
+	assert.ErrorContains(t, validateGCPOptions(&scanOptions{gcpProjects: "p1", securityHub: true}), "--security-hub")
+}
+
+func TestMergeComputeOptimizerFindings(t *testing.T) {
+	instanceCost := map[string]interface{}{"total": &awsinternal.CostBreakdown{MonthlyRate: 140}}
+	results := map[string]awsinternal.ScanResults{
+		"EC2 Instances": {{
+			ResourceType: "EC2 Instances",
+			ResourceID:   "i-0123456789abcdef0",
+			AccountID:    "123456789012",
+			Reason:       "Very low CPU utilization",
+			Details:      map[string]interface{}{"region": "us-east-1"},
+			Cost:         instanceCost,
+		}},
+	}
+	findings := []awsinternal.ComputeOptimizerFinding{
+		{
+			ResourceARN:    "arn:aws:ec2:us-east-1:123456789012:instance/i-0123456789abcdef0",
+			ResourceType:   "EC2 Instances",
+			AccountID:      "123456789012",
+			Finding:        "Overprovisioned",
+			ReasonCodes:    []string{"CPUOverprovisioned"},
+			Current:        "m5.xlarge",
+			Recommendation: "m5.large",
+			MonthlySavings: 70,
+		},
+		{
+			ResourceARN:    "arn:aws:lambda:us-east-1:123456789012:function:resize-images",
+			ResourceType:   "Lambda Functions",
+			ResourceName:   "resize-images",
+			AccountID:      "123456789012",
+			Finding:        "NotOptimized",
+			Current:        "3008 MB",
+			Recommendation: "1024 MB",
+			MonthlySavings: 12.5,
+		},
+		{
+			// Duplicate findings are merged once
+			ResourceARN:    "arn:aws:lambda:us-east-1:123456789012:function:resize-images",
+			ResourceType:   "Lambda Functions",
+			AccountID:      "123456789012",
+			Current:        "3008 MB",
+			Recommendation: "1024 MB",
+			MonthlySavings: 12.5,
+		},
+		{
+			ResourceARN:  "arn:aws:ec2:us-east-1:123456789012:volume/vol-0123456789abcdef0",
+			ResourceType: "EBS Volumes",
+			AccountID:    "123456789012",
+			Current:      "io1, 500 GiB, 10000 IOPS",
+		},
+	}
+
+	added := awsinternal.MergeComputeOptimizerFindings(results, findings)
+	assert.Equal(t, 2, added)
+
+	instance := results["EC2 Instances"][0]
+	assert.Len(t, results["EC2 Instances"], 1)
+	assert.Equal(t, "Very low CPU utilization\nAWS Compute Optimizer reports over-provisioning: m5.large is recommended instead of m5.xlarge.", instance.Reason)
+	assert.Equal(t, "m5.large", instance.Details["compute_optimizer_recommendation"])
+	assert.Equal(t, []string{"CPUOverprovisioned"}, instance.Details["compute_optimizer_reason_codes"])
+	assert.Equal(t, 140.0, output.MonthlyCost(instance), "the cost of existing results is kept")
+
+	if assert.Len(t, results["Lambda Functions"], 1) {
+		function := results["Lambda Functions"][0]
+		assert.Equal(t, "resize-images", function.ResourceName)
+		assert.Equal(t, "us-east-1", function.Details["region"])
+		assert.Equal(t, "compute_optimizer", function.Details["source"])
+		assert.Equal(t, 12.5, output.MonthlyCost(function))
+		assert.Equal(t, []string{"overprovisioned_resource"}, awsinternal.ClassifyReason(function.Reason))
+	}
+
+	if assert.Len(t, results["EBS Volumes"], 1) {
+		volume := results["EBS Volumes"][0]
+		assert.Equal(t, "vol-0123456789abcdef0", volume.ResourceID, "new results are reported by resource ID like the scanners report them")
+		assert.Equal(t, "arn:aws:ec2:us-east-1:123456789012:volume/vol-0123456789abcdef0", volume.Details["arn"])
+		assert.Equal(t, "vol-0123456789abcdef0", volume.ResourceName)
+		assert.Equal(t, awsinternal.NoCost(awsinternal.NoCostNotEstimated), volume.Cost)
+	}
+}
+
+func TestIgnoredResult(t *testing.T) {
+	originalIDs := config.Config.ScanIgnoreResourceIDs
+	originalTags := config.Config.ScanIgnoreTags
+	defer func() {
+		config.Config.ScanIgnoreResourceIDs = originalIDs
+		config.Config.ScanIgnoreTags = originalTags
+	}()
+	config.Config.ScanIgnoreResourceIDs = []string{"vol-0123456789abcdef0"}
+	config.Config.ScanIgnoreTags = map[string]string{"keep": "true"}
+
+	// Results created from Compute Optimizer findings match the ignore lists by resource ID
+	// and tags like the results of the scanners
+	results := map[string]awsinternal.ScanResults{}
+	awsinternal.MergeComputeOptimizerFindings(results, []awsinternal.ComputeOptimizerFinding{
+		{ResourceARN: "arn:aws:ec2:us-east-1:123456789012:volume/vol-0123456789abcdef0", ResourceType: "EBS Volumes", AccountID: "123456789012"},
+		{ResourceARN: "arn:aws:ec2:us-east-1:123456789012:instance/i-0123456789abcdef0", ResourceType: "EC2 Instances", AccountID: "123456789012", Tags: map[string]string{"Keep": "True"}},
+		{ResourceARN: "arn:aws:ec2:us-east-1:123456789012:instance/i-0fedcba9876543210", ResourceType: "EC2 Instances", AccountID: "123456789012"},
+	})
+
+	message, _ := ignoredResult(results["EBS Volumes"][0], nil, 0)
+	assert.Equal(t, "Ignoring resource by ID", message)
+	message, _ = ignoredResult(results["EC2 Instances"][0], nil, 0)
+	assert.Equal(t, "Ignoring resource by tag", message)
+	message, fields := ignoredResult(results["EC2 Instances"][1], nil, 0)
+	assert.Empty(t, message)
+	assert.Nil(t, fields)
+}
+
+func TestHTMLReportEscapesReasons(t *testing.T) {
+	reportPath := filepath.Join(t.TempDir(), "report.html")
+	results := []awsinternal.ScanResult{{
+		ResourceType: "EBS Volumes",
+		ResourceID:   "vol-0123456789abcdef0",
+		ResourceName: "data",
+		AccountID:    "123456789012",
+		Reason:       "Volume has not been used.\nTagged <script>alert(1)</script>",
+		Details:      map[string]interface{}{"region": "us-east-1"},
+	}}
+	err := html.WriteHTMLWithOptions(results, reportPath, html.ScanMetrics{}, html.ReportOptions{
+		ReasonTemplates: []html.ReasonTemplate{{
+			Match:    "^Tagged",
+			Template: `{{ .Reason }} <a href="https://wiki.example.com/ebs">Runbook</a>`,
+		}},
+	})
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(reportPath)
+	require.NoError(t, err)
+	report := string(data)
+	assert.NotContains(t, report, "<script>alert(1)</script>")
+	assert.Contains(t, report, "Tagged &lt;script&gt;alert(1)&lt;/script&gt;")
+	assert.Contains(t, report, `<a href="https://wiki.example.com/ebs">Runbook</a>`, "template text is kept as HTML")
+}
+
+func TestAddCostAllocation(t *testing.T) {
+	assert.Equal(t, []string{"CostCenter", "Team"}, parseTagKeys(" CostCenter,,Team,CostCenter"))
+	assert.Nil(t, parseTagKeys(""))
+
+	withCost := func(tags map[string]string, monthlyRate float64) awsinternal.ScanResult {
+		return awsinternal.ScanResult{
+			Tags: tags,
+			Cost: map[string]interface{}{"total": &awsinternal.CostBreakdown{MonthlyRate: monthlyRate, YearlyRate: monthlyRate * 12}},
+		}
+	}
+	accountResults := map[string]*scanResult{
+		"111111111111": {
+			AccountID: "111111111111",
+			Results: map[string]awsinternal.ScanResults{
+				"EBS Volumes": {
+					withCost(map[string]string{"CostCenter": "1234"}, 10),
+					withCost(map[string]string{"costcenter": "1234"}, 5),
+					withCost(map[string]string{"CostCenter": "5678"}, 20),
+				},
+				"Elastic IPs": {withCost(nil, 3.65)},
+				"IAM Roles":   {{Tags: map[string]string{"CostCenter": "5678"}, Cost: awsinternal.NoCost(awsinternal.NoCostFree)}},
+			},
+		},
+	}
+
+	addCostAllocation(accountResults, nil)
+	assert.Nil(t, accountResults["111111111111"].CostAllocation)
+
+	addCostAllocation(accountResults, []string{"CostCenter"})
+	assert.Equal(t, []awsinternal.TagCostAllocation{{
+		TagKey: "CostCenter",
+		Groups: []awsinternal.TagCostAllocationGroup{
+			{Value: "5678", Findings: 2, MonthlySavings: 20, YearlySavings: 240},
+			{Value: "1234", Findings: 2, MonthlySavings: 15, YearlySavings: 180},
+			{Value: "", Findings: 1, MonthlySavings: 3.65, YearlySavings: 43.8},
+		},
+	}}, accountResults["111111111111"].CostAllocation)
+
+	data, err := json.Marshal(accountResults["111111111111"])
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"cost_allocation":[{"tag_key":"CostCenter","groups":[{"value":"5678","findings":2,"monthly_savings":20,"yearly_savings":240}`)
+}
+
+func TestBuildScanPlan(t *testing.T) {
+	originalExclusions := config.Config.ScanAccountScannerExclusions
+	config.Config.ScanAccountScannerExclusions = map[string][]string{"210987654321": {"plain"}}
+	defer func() { config.Config.ScanAccountScannerExclusions = originalExclusions }()
+
+	accounts := []awsinternal.Account{{ID: "123456789012", Name: "prod"}, {ID: "210987654321", Name: "dev"}}
+	regions := []string{"us-east-1", "eu-west-1"}
+	plain := &testScanner{argumentName: "plain", label: "Plain"}
+	roles := &testScanner{argumentName: "iam-roles", label: "IAM Roles"}
+	emptyTasks := map[string]bool{"123456789012:eu-west-1:plain": true}
+
+	counts := awsinternal.NewResourceCounts()
+	counts.Set("123456789012", "us-east-1", "plain", 4)
+
+	plan := buildScanPlan(scanTasks(accounts, regions, []awsinternal.Scanner{plain, roles}, emptyTasks), regions, []awsinternal.Scanner{plain, roles}, counts)
+	four := 4
+	assert.Equal(t, 2, plan.Accounts)
+	assert.Equal(t, []string{"plain", "iam-roles"}, plan.Scanners)
+	assert.Equal(t, 3, plan.TotalTasks)
+	assert.Equal(t, &four, plan.EstimatedResources)
+	assert.Equal(t, []plannedTask{
+		{AccountID: "123456789012", AccountName: "prod", Region: "us-east-1", Scanner: "plain", Label: "Plain", EstimatedResources: &four},
+		{AccountID: "123456789012", AccountName: "prod", Region: "global", Scanner: "iam-roles", Label: "IAM Roles"},
+		{AccountID: "210987654321", AccountName: "dev", Region: "global", Scanner: "iam-roles", Label: "IAM Roles"},
+	}, plan.Tasks)
+
+	path := filepath.Join(t.TempDir(), "plans", "scan-plan.json")
+	require.NoError(t, writeScanPlan(plan, path))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var written scanPlan
+	require.NoError(t, json.Unmarshal(data, &written))
+	assert.Equal(t, plan.Tasks, written.Tasks)
+}
+
+func TestWriteCSVResults(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	accountResults := map[string]*scanResult{
+		"123456789012": {
+			AccountID: "123456789012",
+			Results: map[string]awsinternal.ScanResults{
+				"ebs-volumes": {{
+					ResourceType: "EBS Volumes",
+					ResourceName: "data, old",
+					ResourceID:   "vol-2",
+					AccountID:    "123456789012",
+					AccountName:  "prod",
+					Reason:       "Volume is unattached.\nVolume is 2 years old.",
+					Details:      map[string]interface{}{"region": "us-east-1"},
+					Cost:         map[string]interface{}{"total": &awsinternal.CostBreakdown{MonthlyRate: 8.5}},
+				}, {
+					ResourceType: "EBS Volumes",
+					ResourceName: "=HYPERLINK(\"https://example.com\")",
+					ResourceID:   "vol-1",
+					AccountID:    "123456789012",
+					AccountName:  "prod",
+					Reason:       "Volume is unattached.",
+					Details:      map[string]interface{}{"region": "us-east-1"},
+					Cost:         awsinternal.NoCost(awsinternal.NoCostNotEstimated),
+				}},
+			},
+		},
+		"210987654321": {
+			AccountID: "210987654321",
+			Results: map[string]awsinternal.ScanResults{
+				"iam-roles": {{
+					ResourceType: "IAM Roles",
+					ResourceName: "deploy",
+					ResourceID:   "arn:aws:iam::210987654321:role/deploy",
+					AccountID:    "210987654321",
+					AccountName:  "dev",
+					Reason:       "Role has not been used in 120 days.",
+					Details:      map[string]interface{}{"region": "global"},
+					Cost:         awsinternal.NoCost(awsinternal.NoCostFree),
+				}},
+			},
+		},
+	}
+
+	writeCSVResults(accountResults, "EUR", false)
+	data, err := os.ReadFile(filepath.Join("reports", "scan_results.csv"))
+	require.NoError(t, err)
+	assert.Equal(t, "account_id,account_name,region,resource_type,resource_name,resource_id,reason,monthly_cost,currency\n"+
+		"123456789012,prod,us-east-1,EBS Volumes,\"'=HYPERLINK(\"\"https://example.com\"\")\",vol-1,Volume is unattached.,,\n"+
+		"123456789012,prod,us-east-1,EBS Volumes,\"data, old\",vol-2,Volume is unattached.; Volume is 2 years old.,8.50,EUR\n"+
+		"210987654321,dev,global,IAM Roles,deploy,arn:aws:iam::210987654321:role/deploy,Role has not been used in 120 days.,,\n",
+		string(data), "cells starting like a formula are quoted")
+
+	writeCSVResults(accountResults, "", true)
+	data, err = os.ReadFile(filepath.Join("reports", "csv", "210987654321.csv"))
+	require.NoError(t, err)
+	assert.Equal(t, "account_id,account_name,region,resource_type,resource_name,resource_id,reason,monthly_cost,currency\n"+
+		"210987654321,dev,global,IAM Roles,deploy,arn:aws:iam::210987654321:role/deploy,Role has not been used in 120 days.,,\n",
+		string(data))
+	_, err = os.Stat(filepath.Join("reports", "csv", "123456789012.csv"))
+	assert.NoError(t, err)
+}
+
+func TestFilterManagementAccount(t *testing.T) {
+	accounts := []awsinternal.Account{
+		{ID: "111111111111", Name: "management", Management: true},
+		{ID: "222222222222", Name: "prod"},
+	}
+
+	scanned, skipped := filterManagementAccount(accounts, false)
+	assert.Equal(t, accounts, scanned)
+	assert.Empty(t, skipped)
+
+	scanned, skipped = filterManagementAccount(accounts, true)
+	assert.Equal(t, []awsinternal.Account{{ID: "222222222222", Name: "prod"}}, scanned)
+	assert.Equal(t, []skippedAccount{{
+		AccountID:   "111111111111",
+		AccountName: "management",
+		Reason:      "management account of the organization is excluded",
+	}}, skipped)
+}
+
+func TestMarkManagementAccountRequired(t *testing.T) {
+	accounts := []awsinternal.Account{{ID: "222222222222", Name: "prod"}}
+
+	marked, err := awsinternal.MarkManagementAccount(nil, accounts, false)
+	assert.NoError(t, err)
+	assert.Equal(t, accounts, marked)
+
+	_, err = awsinternal.MarkManagementAccount(nil, accounts, true)
+	assert.ErrorIs(t, err, awsinternal.ErrManagementAccountUnknown)
+}
+
+func TestTrimDetails(t *testing.T) {
+	launched := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	size := int64(100)
+	details := map[string]interface{}{
+		"region":       "us-east-1",
+		"size_gb":      &size,
+		"launch_time":  launched,
+		"tags":         map[string]string{"team": "data"},
+		"reason_codes": []string{"idle"},
+		"datapoints":   []float64{1, 2, 3},
+		"attachments":  []map[string]interface{}{{"instance_id": "i-1"}},
+		"raw":          struct{ Name string }{"vol"},
+	}
+
+	trimmed := output.TrimDetails(details)
+	assert.Equal(t, map[string]interface{}{
+		"region":       "us-east-1",
+		"size_gb":      &size,
+		"launch_time":  launched,
+		"tags":         map[string]string{"team": "data"},
+		"reason_codes": []string{"idle"},
+	}, trimmed)
+	assert.Len(t, details, 8, "the details must not be modified")
+	assert.Nil(t, output.TrimDetails(nil))
+}
+
+func TestDetailsSpool(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "details")
+	spool, err := output.NewDetailsSpool(dir)
+	require.NoError(t, err)
+	assert.Equal(t, dir, spool.Dir())
+
+	newResults := func(ids ...string) []awsinternal.ScanResult {
+		results := make([]awsinternal.ScanResult, 0, len(ids))
+		for _, id := range ids {
+			results = append(results, awsinternal.ScanResult{
+				ResourceType: "EBS Volumes",
+				ResourceID:   id,
+				Details:      map[string]interface{}{"region": "us-east-1", "datapoints": []float64{1, 2}},
+			})
+		}
+		return results
+	}
+	readRecords := func(accountID string) []map[string]interface{} {
+		f, err := os.Open(filepath.Join(dir, accountID+".jsonl.gz"))
+		require.NoError(t, err)
+		defer f.Close()
+		gz, err := gzip.NewReader(f)
+		require.NoError(t, err)
+		var records []map[string]interface{}
+		decoder := json.NewDecoder(gz)
+		for {
+			var record map[string]interface{}
+			if err := decoder.Decode(&record); err != nil {
+				break
+			}
+			records = append(records, record)
+		}
+		return records
+	}
+
+	first := newResults("vol-1", "vol-2")
+	require.NoError(t, spool.Write("111111111111", first))
+	assert.Equal(t, map[string]interface{}{"region": "us-east-1"}, first[0].Details, "details are trimmed once written")
+
+	// Finished tasks are on disk before the spool is closed
+	records := readRecords("111111111111")
+	require.Len(t, records, 2)
+	assert.Equal(t, "vol-1", records[0]["resource_id"])
+	assert.Equal(t, []interface{}{1.0, 2.0}, records[0]["details"].(map[string]interface{})["datapoints"])
+
+	require.NoError(t, spool.Write("111111111111", newResults("vol-3")))
+	require.NoError(t, spool.Write("222222222222", newResults("vol-4")))
+	require.NoError(t, spool.Close())
+
+	assert.Len(t, readRecords("111111111111"), 3)
+	records = readRecords("222222222222")
+	require.Len(t, records, 1)
+	assert.Equal(t, "EBS Volumes", records[0]["resource_type"])
+}
+
+func TestSecurityHubFindings(t *testing.T) {
+	observedAt := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	result := func(resourceType, resourceID, region string) awsinternal.ScanResult {
+		return awsinternal.ScanResult{
+			ResourceType: resourceType,
+			ResourceID:   resourceID,
+			AccountID:    "111111111111",
+			Details:      map[string]interface{}{"region": region},
+		}
+	}
+	previous := func(r awsinternal.ScanResult) *securityhub.AwsSecurityFinding {
+		return output.SecurityHubFinding(r, "us-east-1", time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	}
+
+	stillUnused := result("EBS Volumes", "vol-1", "eu-west-1")
+	resolved := result("EBS Volumes", "vol-2", "eu-west-1")
+	failedScanner := result("RDS Instances", "db-1", "eu-west-1")
+	otherRegion := result("EBS Volumes", "vol-3", "ap-south-1")
+	globalRole := result("IAM Roles", "arn:aws:iam::111111111111:role/old", "global")
+	active := make(map[string]*securityhub.AwsSecurityFinding)
+	for _, r := range []awsinternal.ScanResult{stillUnused, resolved, failedScanner, otherRegion, globalRole} {
+		finding := previous(r)
+		active[aws.StringValue(finding.Id)] = finding
+	}
+
+	accountResult := &scanResult{
+		AccountID: "111111111111",
+		Errors:    []scanError{{Scanner: "RDS Instances", Region: "eu-west-1", Error: "throttled"}},
+	}
+	scanners := []awsinternal.Scanner{
+		&testScanner{argumentName: "ebs-volumes", label: "EBS Volumes"},
+		&testScanner{argumentName: "rds", label: "RDS Instances"},
+		&testScanner{argumentName: "iam-roles", label: "IAM Roles"},
+	}
+	scope := securityHubScope("111111111111", accountResult, scanners, []string{"eu-west-1"}, "us-east-1")
+
+	findings := output.SecurityHubFindings([]awsinternal.ScanResult{stillUnused}, "us-east-1", observedAt, active, scope)
+	states := make(map[string]string)
+	for _, finding := range findings {
+		states[aws.StringValue(finding.Resources[0].Id)] = aws.StringValue(finding.RecordState)
+	}
+	assert.Equal(t, map[string]string{
+		"vol-1":                              securityhub.RecordStateActive,
+		"vol-2":                              securityhub.RecordStateArchived,
+		"arn:aws:iam::111111111111:role/old": securityhub.RecordStateArchived,
+	}, states, "findings of failed scanners and unscanned regions are kept")
+
+	assert.Equal(t, "2026-01-01T00:00:00Z", aws.StringValue(findings[0].CreatedAt), "the creation time of an active finding is kept")
+	assert.Equal(t, "2026-03-04T05:06:07Z", aws.StringValue(findings[0].UpdatedAt))
+	assert.Equal(t, "2026-03-04T05:06:07Z", aws.StringValue(findings[1].UpdatedAt))
+	assert.Equal(t, securityhub.RecordStateActive, aws.StringValue(active[aws.StringValue(findings[1].Id)].RecordState), "active findings are not modified")
+
+	fresh := output.SecurityHubFindings([]awsinternal.ScanResult{result("EBS Volumes", "vol-9", "eu-west-1")}, "us-east-1", observedAt, nil, scope)
+	require.Len(t, fresh, 1)
+	assert.Equal(t, "2026-03-04T05:06:07Z", aws.StringValue(fresh[0].CreatedAt))
+}
+
+func TestWriteMarkdownSummary(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	volume := func(id string, monthly float64) awsinternal.ScanResult {
+		return awsinternal.ScanResult{
+			ResourceType: "EBS Volumes",
+			ResourceName: id,
+			ResourceID:   id,
+			AccountID:    "123456789012",
+			AccountName:  "prod",
+			Details:      map[string]interface{}{"region": "us-east-1"},
+			Cost:         map[string]interface{}{"total": &awsinternal.CostBreakdown{MonthlyRate: monthly}},
+		}
+	}
+	var volumes awsinternal.ScanResults
+	for i := 0; i < 25; i++ {
+		volumes = append(volumes, volume(fmt.Sprintf("vol-%02d", i), float64(i)))
+	}
+	volumes = append(volumes, volume("vol-large", 1234.5))
+	accountResults := map[string]*scanResult{
+		"123456789012": {AccountID: "123456789012", Results: map[string]awsinternal.ScanResults{"ebs-volumes": volumes}},
+		"210987654321": {AccountID: "210987654321", Results: map[string]awsinternal.ScanResults{"iam-roles": {{
+			ResourceType: "IAM Roles",
+			ResourceName: "deploy",
+			ResourceID:   "arn:aws:iam::210987654321:role/deploy",
+			AccountID:    "210987654321",
+			AccountName:  "dev",
+			Details:      map[string]interface{}{"region": "global"},
+			Cost:         awsinternal.NoCost(awsinternal.NoCostFree),
+		}}}},
+	}
+
+	writeResults(&scanOptions{output: "filesystem", outputFormat: "markdown"}, accountResults, nil, html.ScanMetrics{},
+		html.ReportOptions{CurrencySymbol: "€"})
+	data, err := os.ReadFile(filepath.Join("reports", "scan_summary.md"))
+	require.NoError(t, err)
+	summary := string(data)
+
+	assert.Contains(t, summary, "**27 findings** in 2 accounts, estimated savings of **€1,534.50/month**")
+	assert.Contains(t, summary, "| EBS Volumes | 26 | €1,534.50 |\n| IAM Roles | 1 | €0.00 |")
+	assert.Contains(t, summary, "### Top 20 resources by monthly savings")
+	assert.Contains(t, summary, "| 123456789012 | us-east-1 | EBS Volumes | `vol-large` | €1,234.50 |\n| 123456789012 | us-east-1 | EBS Volumes | `vol-24` | €24.00 |")
+	assert.Contains(t, summary, "`vol-06`")
+	assert.NotContains(t, summary, "`vol-05`")
+	assert.Contains(t, summary, "| prod (123456789012) | 26 | €1,534.50 |\n| dev (210987654321) | 1 | €0.00 |")
+}
+
+func TestNotifySlack(t *testing.T) {
+	var attempts atomic.Int32
+	var message map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&message))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	accountResults := map[string]*scanResult{
+		"123456789012": {
+			AccountID: "123456789012",
+			Results: map[string]awsinternal.ScanResults{
+				"ebs-volumes": {
+					{ResourceType: "EBS Volumes", Cost: map[string]interface{}{"total": &awsinternal.CostBreakdown{MonthlyRate: 10}}},
+					{ResourceType: "EBS Volumes", Cost: map[string]interface{}{"total": &awsinternal.CostBreakdown{MonthlyRate: 2.5}}},
+				},
+				"iam-roles": {{ResourceType: "IAM Roles", Cost: awsinternal.NoCost(awsinternal.NoCostFree)}},
+			},
+			Errors: []scanError{{Scanner: "EC2 Instances", Region: "us-east-1", Error: "access denied"}},
+		},
+	}
+	opts := &scanOptions{output: "s3", bucket: "reports", bucketRegion: "eu-west-1"}
+	summary := notificationSummary(accountResults, "€", s3ReportURL(opts, time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, 1, summary.Accounts)
+	assert.Equal(t, 3, summary.Findings)
+	assert.Equal(t, 1, summary.Errors)
+	assert.Equal(t, 12.5, summary.MonthlySavings)
+	assert.Equal(t, []notifications.ResourceTypeSummary{
+		{ResourceType: "EBS Volumes", Findings: 2, MonthlySavings: 12.5},
+		{ResourceType: "IAM Roles", Findings: 1},
+	}, summary.ResourceTypes)
+	assert.Equal(t, "https://s3.console.aws.amazon.com/s3/buckets/reports?prefix=2026%2F03%2F04%2F&region=eu-west-1", summary.ReportURL)
+	assert.Empty(t, s3ReportURL(&scanOptions{output: "filesystem"}, time.Now()))
+
+	notifier := notifications.NewSlackNotifier(server.URL)
+	notifier.RetryDelay = time.Millisecond
+	require.NoError(t, notifier.Notify(summary))
+	assert.Equal(t, int32(2), attempts.Load())
+	assert.Equal(t, "CloudSift scan finished: 3 findings in 1 accounts, €12.50/month in potential savings", message["text"])
+	blocks := message["blocks"].([]interface{})
+	require.Len(t, blocks, 4)
+	assert.Equal(t, "*Findings by resource type*\n• EBS Volumes: 2 (€12.50/month)\n• IAM Roles: 1 (€0.00/month)",
+		blocks[2].(map[string]interface{})["text"].(map[string]interface{})["text"])
+	assert.Equal(t, summary.ReportURL, blocks[3].(map[string]interface{})["accessory"].(map[string]interface{})["url"])
+
+	// Rejected posts are not retried
+	rejecting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		http.Error(w, "invalid_payload", http.StatusBadRequest)
+	}))
+	defer rejecting.Close()
+	attempts.Store(0)
+	notifier = notifications.NewSlackNotifier(rejecting.URL)
+	notifier.RetryDelay = time.Millisecond
+	assert.ErrorContains(t, notifier.Notify(summary), "invalid_payload")
+	assert.Equal(t, int32(1), attempts.Load())
+}
+
+func TestWriteOutputOverrides(t *testing.T) {
+	t.Chdir(t.TempDir())
+	require.NoError(t, os.WriteFile("blocked", nil, 0644))
+
+	previous := config.Config.ScanOutputOverrides
+	t.Cleanup(func() { config.Config.ScanOutputOverrides = previous })
+	config.Config.ScanOutputOverrides = []config.OutputOverride{
+		{Accounts: []string{"111111111111"}, Format: "csv", Output: "filesystem", Directory: "team-a"},
+		{OrganizationalUnits: []string{"ou-ab12-parent"}, Format: "json", Output: "filesystem", Directory: "team-b"},
+		{Accounts: []string{"222222222222", "444444444444"}, Output: "filesystem", Directory: filepath.Join("blocked", "reports")},
+	}
+	require.NoError(t, validateOutputOverrides(config.Config.ScanOutputOverrides))
+
+	accounts := []awsinternal.Account{
+		{ID: "111111111111"},
+		{ID: "222222222222", OrganizationalUnits: []string{"ou-ab12-child", "ou-ab12-parent"}},
+		{ID: "333333333333", OrganizationalUnits: []string{"ou-ab12-other"}},
+		{ID: "444444444444"},
+	}
+	accountResults := make(map[string]*scanResult)
+	for _, account := range accounts {
+		accountResults[account.ID] = &scanResult{
+			AccountID: account.ID,
+			Results: map[string]awsinternal.ScanResults{
+				"ebs-volumes": {{
+					ResourceType: "EBS Volumes",
+					ResourceID:   "vol-" + account.ID,
+					AccountID:    account.ID,
+					Reason:       "Volume is unattached.",
+					Details:      map[string]interface{}{"region": "us-east-1"},
+				}},
+			},
+		}
+	}
+
+	remaining := writeOutputOverrides(&scanOptions{outputFormat: "markdown"}, accounts, accountResults, html.ScanMetrics{}, html.ReportOptions{})
+
+	// Unmatched accounts and accounts of overrides that could not be written are left to the
+	// scan output
+	var remainingIDs []string
+	for accountID := range remaining {
+		remainingIDs = append(remainingIDs, accountID)
+	}
+	assert.ElementsMatch(t, []string{"333333333333", "444444444444"}, remainingIDs)
+
+	data, err := os.ReadFile(filepath.Join("team-a", "scan_results.csv"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "vol-111111111111")
+	assert.NotContains(t, string(data), "vol-222222222222")
+
+	// Accounts match organizational units at any depth, and the first matching override applies
+	files, err := filepath.Glob(filepath.Join("team-b", "*", "*", "*", "222222222222", "*.json.gz"))
+	require.NoError(t, err)
+	assert.Len(t, files, 1)
+}
+
+func TestValidateOutputOverrides(t *testing.T) {
+	tests := []struct {
+		name     string
+		override config.OutputOverride
+		wantErr  string
+	}{
+		{"no accounts", config.OutputOverride{Output: "filesystem", Directory: "reports"}, "needs accounts or organizational_units"},
+		{"invalid format", config.OutputOverride{Accounts: []string{"111111111111"}, Format: "xml", Output: "filesystem", Directory: "reports"}, "invalid format"},
+		{"no directory", config.OutputOverride{Accounts: []string{"111111111111"}, Output: "filesystem"}, "needs a directory"},
+		{"no bucket", config.OutputOverride{OrganizationalUnits: []string{"ou-ab12-34cd56ef"}, Output: "s3"}, "needs a bucket"},
+		{"invalid output", config.OutputOverride{Accounts: []string{"111111111111"}, Output: "s3-account"}, "invalid output"},
+		{"s3", config.OutputOverride{OrganizationalUnits: []string{"ou-ab12-34cd56ef"}, Format: "html", Output: "s3", Bucket: "reports"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateOutputOverrides([]config.OutputOverride{tt.override})
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestRecordHistoryRun(t *testing.T) {
+	dir := t.TempDir()
+	startTime := time.Date(2024, 3, 1, 6, 0, 0, 0, time.UTC)
+
+	accountResults := map[string]*scanResult{
+		"123456789012": {
+			AccountID: "123456789012",
+			Results: map[string]awsinternal.ScanResults{
+				"ebs-volumes": {{
+					ResourceType: "EBS Volumes",
+					ResourceID:   "vol-1",
+					AccountID:    "123456789012",
+					Reason:       "Volume is unattached.",
+					Details:      map[string]interface{}{"region": "us-east-1"},
+					Cost:         map[string]interface{}{"total": &awsinternal.CostBreakdown{MonthlyRate: 8.5}},
+				}},
+			},
+		},
+	}
+	unconfirmed := []awsinternal.ScanResult{{
+		ResourceType: "EBS Volumes",
+		ResourceID:   "vol-2",
+		AccountID:    "123456789012",
+		Details:      map[string]interface{}{"region": "us-east-1"},
+		Cost:         map[string]interface{}{"total": &awsinternal.CostBreakdown{MonthlyRate: 4}},
+	}}
+	scope := history.NewScope()
+	scope.Add("123456789012", "EBS Volumes", "us-east-1")
+	prices := map[string]float64{"EBS:us-east-1:gp3": 0.08}
+	recordHistoryRun(dir, startTime, "EUR", accountResults, unconfirmed, scope, prices, html.ScanMetrics{
+		CompletedScans:  12,
+		FailedScans:     1,
+		TotalRunTime:    90,
+		CompletedAt:     startTime.Add(90 * time.Second),
+		SkippedAccounts: []html.SkippedAccount{{AccountID: "210987654321"}},
+		APICalls:        300,
+	})
+
+	runs, err := history.ListRuns(dir)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, "20240301T060000Z", runs[0].ID)
+	assert.Equal(t, "EUR", runs[0].Currency)
+	assert.Equal(t, 1, runs[0].Findings)
+	assert.InDelta(t, 8.5, runs[0].MonthlySavings, 0.001)
+	assert.Equal(t, map[string][]string{"123456789012/EBS Volumes": {"us-east-1"}}, runs[0].Scope)
+	require.Contains(t, runs[0].AccountTotals, "123456789012")
+	assert.Equal(t, map[string]int{"EBS Volumes": 1}, runs[0].AccountTotals["123456789012"].FindingsByType)
+	assert.Equal(t, history.RunMetrics{
+		CompletedTasks:  12,
+		FailedTasks:     1,
+		RunTimeSeconds:  90,
+		SkippedAccounts: 1,
+		APICalls:        300,
+	}, runs[0].Metrics)
+
+	findings, err := history.LoadFindings(dir, runs[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, []history.Finding{{
+		AccountID:    "123456789012",
+		Region:       "us-east-1",
+		ResourceType: "EBS Volumes",
+		ResourceID:   "vol-1",
+		Reason:       "Volume is unattached.",
+		MonthlyCost:  8.5,
+	}, {
+		AccountID:    "123456789012",
+		Region:       "us-east-1",
+		ResourceType: "EBS Volumes",
+		ResourceID:   "vol-2",
+		MonthlyCost:  4,
+		Unconfirmed:  true,
+	}}, findings)
+
+	// The pricing cache is kept with the run
+	snapshot, err := history.LoadPriceCache(dir, runs[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, prices, snapshot)
+}
+
+func TestCompleteScanners(t *testing.T) {
+	cmd := NewScanCmd()
+	complete := completeScanners("")
+
+	names, directive := complete(cmd, nil, "ebs-")
+	assert.Equal(t, []string{"ebs-snapshots", "ebs-volumes"}, names)
+	assert.Equal(t, cobra.ShellCompDirectiveNoSpace|cobra.ShellCompDirectiveNoFileComp, directive)
+
+	// Scanners already listed are left out and the listed ones are kept as prefix
+	names, _ = complete(cmd, nil, "ebs-volumes,ebs")
+	assert.Equal(t, []string{"ebs-volumes,ebs-snapshots"}, names)
+
+	names, _ = completeScanners("=")(cmd, nil, "elastic-ips=30,elastic")
+	assert.Empty(t, names)
+	names, _ = completeScanners("=")(cmd, nil, "elastic")
+	assert.Equal(t, []string{"elastic-ips="}, names)
+
+	require.NoError(t, cmd.Flags().Set("provider", "gcp"))
+	names, _ = complete(cmd, nil, "")
+	assert.NotContains(t, names, "ebs-volumes")
+	assert.NotEmpty(t, names)
+}
+
+func TestCompareToReport(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	volume := func(accountID, id string, monthlyCost float64) awsinternal.ScanResult {
+		return awsinternal.ScanResult{
+			ResourceType: "EBS Volumes",
+			ResourceName: id,
+			ResourceID:   id,
+			AccountID:    accountID,
+			Details:      map[string]interface{}{"region": "us-east-1"},
+			Cost:         map[string]interface{}{"total": &awsinternal.CostBreakdown{MonthlyRate: monthlyCost}},
+		}
+	}
+	previous := []map[string]interface{}{
+		{"account_id": "123456789012", "results": map[string]awsinternal.ScanResults{
+			"ebs-volumes": {volume("123456789012", "vol-1", 10), volume("123456789012", "vol-2", 20)},
+		}},
+		// Accounts missing from the scan are left out of the comparison
+		{"account_id": "210987654321", "results": map[string]awsinternal.ScanResults{
+			"ebs-volumes": {volume("210987654321", "vol-9", 90)},
+		}},
+	}
+	data, err := json.Marshal(previous)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile("previous.json", data, 0644))
+
+	previousScan, err := loadPreviousScan("previous.json")
+	require.NoError(t, err)
+	require.Len(t, previousScan.Findings, 3)
+	_, err = loadPreviousScan("missing.json")
+	assert.ErrorContains(t, err, "invalid --compare-to")
+	none, err := loadPreviousScan("")
+	require.NoError(t, err)
+	assert.Nil(t, none)
+
+	accountResults := map[string]*scanResult{
+		"123456789012": {AccountID: "123456789012", Results: map[string]awsinternal.ScanResults{
+			"ebs-volumes": {volume("123456789012", "vol-2", 20), volume("123456789012", "vol-3", 50)},
+			"iam-roles": {{
+				ResourceType: "IAM Roles",
+				ResourceName: "deploy",
+				ResourceID:   "arn:aws:iam::123456789012:role/deploy",
+				AccountID:    "123456789012",
+				Details:      map[string]interface{}{"region": "global"},
+				Cost:         awsinternal.NoCost(awsinternal.NoCostFree),
+			}},
+		}},
+	}
+	writeResults(&scanOptions{output: "filesystem", outputFormat: "html"}, accountResults, nil, html.ScanMetrics{},
+		html.ReportOptions{PreviousScan: previousScan})
+	data, err = os.ReadFile(filepath.Join("reports", "scan_report.html"))
+	require.NoError(t, err)
+	report := string(data)
+
+	assert.Contains(t, report, "Changes Since Previous Scan")
+	assert.Contains(t, report, "Compared with previous.json: 2 findings before, 3 now.")
+	assert.Contains(t, report, `<div class="stat-value trend-up">▲ &#43;$40.00</div>`)
+	// The new IAM role has no cost, so its trend follows the number of findings
+	assert.Contains(t, report, `<td>IAM Roles</td>
+                            <td data-value="0">0</td>
+                            <td data-value="1">1</td>
+                            <td data-value="1">1</td>
+                            <td data-value="0">0</td>
+                            <td data-value="0">0</td>
+                            <td data-value="0"><span class="trend-up">▲</span> &#43;$0.00</td>`)
+	assert.Contains(t, report, `<td data-value="40"><span class="trend-up">▲</span> &#43;$40.00</td>`)
+
+	// Without a previous scan the comparison is left out
+	writeResults(&scanOptions{output: "filesystem", outputFormat: "html"}, accountResults, nil, html.ScanMetrics{}, html.ReportOptions{})
+	data, err = os.ReadFile(filepath.Join("reports", "scan_report.html"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "Changes Since Previous Scan")
+}
+
+func TestExportPrometheusMetrics(t *testing.T) {
+	completedAt := time.Date(2024, 3, 1, 6, 1, 30, 0, time.UTC)
+	volume := func(id string, monthlyCost float64) awsinternal.ScanResult {
+		return awsinternal.ScanResult{
+			ResourceType: "EBS Volumes",
+			ResourceID:   id,
+			Cost:         map[string]interface{}{"total": &awsinternal.CostBreakdown{MonthlyRate: monthlyCost}},
+		}
+	}
+	accountResults := map[string]*scanResult{
+		"123456789012": {
+			AccountID:   "123456789012",
+			AccountName: "prod \"eu\"",
+			Results: map[string]awsinternal.ScanResults{
+				"ebs-volumes": {volume("vol-1", 8.5), volume("vol-2", 1.5)},
+				"iam-roles":   {{ResourceType: "IAM Roles", ResourceID: "role-1"}},
+			},
+		},
+	}
+	summary := scanSummary(accountResults, html.ScanMetrics{
+		CompletedScans: 12,
+		FailedScans:    1,
+		TotalRunTime:   90,
+		CompletedAt:    completedAt,
+		MaxWorkers:     8,
+	}, "EUR")
+	require.Len(t, summary.Groups, 2)
+	assert.Equal(t, "EBS Volumes", summary.Groups[0].ResourceType)
+
+	var pushed string
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, "/metrics/job/cloudsift", r.URL.Path)
+		body, _ := io.ReadAll(r.Body)
+		pushed = string(body)
+	}))
+	defer gateway.Close()
+
+	path := filepath.Join(t.TempDir(), "textfile", "cloudsift.prom")
+	exportPrometheusMetrics(&scanOptions{prometheusFile: path, prometheusPushgatewayURL: gateway.URL + "/"}, summary)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, string(data), pushed)
+	assert.Contains(t, pushed, "# TYPE cloudsift_findings gauge\n")
+	assert.Contains(t, pushed, `cloudsift_findings{account_id="123456789012",account_name="prod \"eu\"",resource_type="EBS Volumes"} 2`+"\n")
+	assert.Contains(t, pushed, `cloudsift_monthly_savings{account_id="123456789012",account_name="prod \"eu\"",resource_type="EBS Volumes",currency="EUR"} 10`+"\n")
+	assert.Contains(t, pushed, `cloudsift_scan_tasks{state="failed"} 1`+"\n")
+	assert.Contains(t, pushed, "cloudsift_scan_last_completed_timestamp_seconds 1.70927289e+09\n")
+
+	pool := worker.NewPool(2)
+	var finished atomic.Pointer[metrics.ScanSummary]
+	server, err := servePrometheusMetrics("127.0.0.1:0", pool, 5, &finished)
+	require.NoError(t, err)
+	defer server.Close()
+
+	scrape := func() string {
+		resp, err := http.Get("http://" + server.Address() + "/metrics")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", resp.Header.Get("Content-Type"))
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return string(body)
+	}
+	body := scrape()
+	assert.Contains(t, body, "cloudsift_worker_pool_planned_tasks 5\n")
+	assert.NotContains(t, body, "cloudsift_findings")
+
+	finished.Store(&summary)
+	assert.Contains(t, scrape(), `cloudsift_findings{account_id="123456789012",account_name="prod \"eu\"",resource_type="IAM Roles"} 1`)
+}
+
+func TestPushMetrics(t *testing.T) {
+	summary := metrics.ScanSummary{
+		CompletedAt:     time.Date(2024, 3, 1, 6, 0, 0, 0, time.UTC),
+		DurationSeconds: 90,
+		Currency:        "USD",
+		Groups: []metrics.FindingsGroup{
+			{AccountID: "123456789012", AccountName: "prod", ResourceType: "EBS Volumes", Findings: 2, MonthlySavings: 10},
+		},
+	}
+
+	requests := make(map[string]*http.Request)
+	bodies := make(map[string]string)
+	var mu sync.Mutex
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		requests[r.URL.Path] = r
+		bodies[r.URL.Path] = string(body)
+		mu.Unlock()
+		if r.URL.Path == "/failing" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer api.Close()
+
+	t.Setenv("DD_API_KEY", "dd-key")
+	t.Setenv("NR_KEY", "nr-key")
+	exporters := []config.MetricsExporter{
+		{Type: "datadog", URL: api.URL + "/api/v2/series", Tags: []string{"team:finops"}},
+		{Type: "newrelic", URL: api.URL + "/metric/v1", APIKeyEnv: "NR_KEY", Tags: []string{"env:prod"}},
+		// A failing exporter does not stop the others
+		{Type: "datadog", URL: api.URL + "/failing"},
+	}
+	require.NoError(t, validateMetricsExporters(exporters))
+	pushMetrics(exporters, summary)
+
+	require.Contains(t, requests, "/api/v2/series")
+	assert.Equal(t, "dd-key", requests["/api/v2/series"].Header.Get("DD-API-KEY"))
+	var datadog struct {
+		Series []struct {
+			Metric string
+			Type   int
+			Points []struct {
+				Timestamp int64
+				Value     float64
+			}
+			Tags []string
+		}
+	}
+	require.NoError(t, json.Unmarshal([]byte(bodies["/api/v2/series"]), &datadog))
+	assert.Equal(t, "cloudsift.findings", datadog.Series[0].Metric)
+	assert.Equal(t, 3, datadog.Series[0].Type)
+	assert.Equal(t, int64(1709272800), datadog.Series[0].Points[0].Timestamp)
+	assert.Equal(t, 2.0, datadog.Series[0].Points[0].Value)
+	assert.Equal(t, []string{"team:finops", "account_id:123456789012", "account_name:prod", "resource_type:EBS Volumes"}, datadog.Series[0].Tags)
+	assert.Contains(t, bodies["/api/v2/series"], `"metric":"cloudsift.scan.duration_seconds","type":3,"points":[{"timestamp":1709272800,"value":90}]`)
+
+	require.Contains(t, requests, "/metric/v1")
+	assert.Equal(t, "nr-key", requests["/metric/v1"].Header.Get("Api-Key"))
+	assert.Contains(t, bodies["/metric/v1"], `"common":{"timestamp":1709272800000,"attributes":{"env":"prod"}}`)
+	assert.Contains(t, bodies["/metric/v1"], `{"name":"cloudsift.monthly_savings","type":"gauge","value":10,"attributes":{"account_id":"123456789012","account_name":"prod","currency":"USD","resource_type":"EBS Volumes"}}`)
+	assert.Contains(t, requests, "/failing")
+
+	assert.EqualError(t, validateMetricsExporters([]config.MetricsExporter{{Type: "statsd"}}),
+		`invalid type of metrics exporter 1: "statsd" (datadog or newrelic)`)
+	assert.EqualError(t, validateMetricsExporters([]config.MetricsExporter{{Type: "newrelic", APIKeyEnv: "NR_KEY", Region: "ap"}}),
+		`invalid region of metrics exporter 1: "ap" (us or eu)`)
+	t.Setenv("NEW_RELIC_LICENSE_KEY", "")
+	assert.EqualError(t, validateMetricsExporters([]config.MetricsExporter{{Type: "newrelic"}}),
+		"API key of newrelic metrics exporter 1 is not set in NEW_RELIC_LICENSE_KEY")
+	assert.Equal(t, "https://api.datadoghq.eu/api/v2/series", metrics.DatadogURL("datadoghq.eu"))
+	assert.Equal(t, "https://metric-api.eu.newrelic.com/metric/v1", metrics.NewRelicURL("eu"))
+}
+
+func TestHistoryConfirmation(t *testing.T) {
+	dir := t.TempDir()
+	finding := func(accountID, region, id string) awsinternal.ScanResult {
+		return awsinternal.ScanResult{
+			AccountID:    accountID,
+			ResourceType: "EBS Volumes",
+			ResourceID:   id,
+			Details:      map[string]interface{}{"region": region},
+		}
+	}
+	scope := func(regions ...string) *history.Scope {
+		scope := history.NewScope()
+		for _, region := range regions {
+			scope.Add("111111111111", "EBS Volumes", region)
+		}
+		return scope
+	}
+	east, west := finding("111111111111", "us-east-1", "vol-east"), finding("111111111111", "us-west-2", "vol-west")
+	start := time.Date(2024, 3, 1, 6, 0, 0, 0, time.UTC)
+
+	// Findings are confirmed once they appear in enough consecutive recorded runs, including
+	// the runs they were held back in
+	store, err := history.LoadStore(filepath.Join(dir, "missing"))
+	require.NoError(t, err)
+	assert.False(t, store.Existed())
+	for i := 0; i < 2; i++ {
+		run := []history.Finding{history.NewFinding(east), history.NewFinding(west)}
+		run[0].Unconfirmed = i == 0
+		_, err := history.WriteRun(dir, history.NewRun(start.AddDate(0, 0, i), start.AddDate(0, 0, i), "USD", nil, run,
+			scope("us-east-1", "us-west-2"), history.RunMetrics{}), run)
+		require.NoError(t, err)
+	}
+	store, err = history.LoadStore(dir)
+	require.NoError(t, err)
+	assert.True(t, store.Existed())
+	assert.Len(t, store.Confirm(awsinternal.ScanResults{east, west}, 2), 2)
+	assert.Empty(t, store.Confirm(awsinternal.ScanResults{east, west}, 3))
+
+	// A scan of one region leaves the findings of the other region unchanged
+	store.Record([]awsinternal.ScanResult{east}, scope("us-east-1"), start.AddDate(0, 0, 2))
+	assert.Equal(t, 3, store.Consecutive(east))
+	require.Contains(t, store.Findings, history.Key(west))
+	assert.Equal(t, 2, store.Findings[history.Key(west)].Consecutive)
+
+	// The other region's streak continues when it is scanned again
+	store.Record([]awsinternal.ScanResult{east, west}, scope("us-east-1", "us-west-2"), start.AddDate(0, 0, 3))
+	assert.Equal(t, 4, store.Consecutive(east))
+	assert.Equal(t, 3, store.Consecutive(west))
+	confirmed := store.Confirm(awsinternal.ScanResults{east, west}, 3)
+	require.Len(t, confirmed, 2)
+	assert.Equal(t, 3, confirmed[1].Details["consecutive_scans"])
+
+	// A finding missing from a scan covering it loses its streak
+	store.Record([]awsinternal.ScanResult{west}, scope("us-east-1", "us-west-2"), start.AddDate(0, 0, 4))
+	assert.NotContains(t, store.Findings, history.Key(east))
+	store.Record([]awsinternal.ScanResult{east, west}, scope("us-east-1", "us-west-2"), start.AddDate(0, 0, 5))
+	assert.Equal(t, 1, store.Consecutive(east))
+	assert.Equal(t, start.AddDate(0, 0, 5), store.Findings[history.Key(east)].FirstSeen)
+
+	// Scopes of multi-region scanners cover all regions, and other accounts are not covered
+	other := finding("222222222222", "us-east-1", "vol-other")
+	otherScope := history.NewScope()
+	otherScope.Add("222222222222", "EBS Volumes", "us-east-1")
+	store.Record([]awsinternal.ScanResult{other}, otherScope, start.AddDate(0, 0, 6))
+	assert.Contains(t, store.Findings, history.Key(west))
+	allRegions := history.NewScope()
+	allRegions.Add("111111111111", "EBS Volumes", "")
+	store.Record(nil, allRegions, start.AddDate(0, 0, 7))
+	assert.NotContains(t, store.Findings, history.Key(west))
+	assert.Contains(t, store.Findings, history.Key(other))
+}
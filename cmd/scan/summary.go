@@ -0,0 +1,131 @@
+package scan
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"cloudsift/internal/logging"
+	"cloudsift/internal/notifications"
+	"cloudsift/internal/output"
+)
+
+// summaryTopResourceTypes is the number of resource types with the highest savings listed in
+// the scan summary
+const summaryTopResourceTypes = 3
+
+// summarizeAccounts summarizes the results of the accounts of a scan, from which the scan
+// summary, its notifications and its metrics are derived
+func summarizeAccounts(accountResults map[string]*scanResult) output.Summary {
+	accounts := make([]output.SummaryAccount, 0, len(accountResults))
+	for accountID, result := range accountResults {
+		account := output.SummaryAccount{AccountID: accountID, AccountName: result.AccountName}
+		for _, scannerResults := range result.Results {
+			account.Results = append(account.Results, scannerResults...)
+		}
+		accounts = append(accounts, account)
+	}
+	return output.Summarize(accounts)
+}
+
+// printScanSummary prints the findings count and estimated monthly savings of each account,
+// their totals and the resource types with the highest savings, so short terminal runs do not
+// need the report opened. The cost of low confidence findings is left out of the savings and
+// printed apart.
+func printScanSummary(w io.Writer, accountResults map[string]*scanResult, currencySymbol string) {
+	summary := summarizeAccounts(accountResults)
+	accounts := append([]output.SummaryGroup(nil), summary.Accounts...)
+	sort.Slice(accounts, func(i, j int) bool {
+		return accounts[i].AccountID < accounts[j].AccountID
+	})
+
+	formatSavings := func(savings float64) string {
+		return fmt.Sprintf("%s%.2f", currencySymbol, savings)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\nScan summary:")
+	fmt.Fprintln(tw, "ACCOUNT\tFINDINGS\tMONTHLY SAVINGS")
+	for _, account := range accounts {
+		fmt.Fprintf(tw, "%s\t%d\t%s\n", account.Account(), account.Findings, formatSavings(account.MonthlySavings))
+	}
+	fmt.Fprintf(tw, "TOTAL\t%d\t%s\n", summary.Findings, formatSavings(summary.MonthlySavings))
+	tw.Flush()
+	if summary.UnverifiedFindings > 0 {
+		fmt.Fprintf(w, "%d low confidence findings with %s/month are not counted, their usage could not be verified\n",
+			summary.UnverifiedFindings, formatSavings(summary.UnverifiedSavings))
+	}
+
+	types := summary.ResourceTypes
+	if len(types) == 0 {
+		return
+	}
+	if len(types) > summaryTopResourceTypes {
+		types = types[:summaryTopResourceTypes]
+	}
+
+	fmt.Fprintf(w, "\nTop %d resource types by monthly savings:\n", len(types))
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "RESOURCE TYPE\tFINDINGS\tMONTHLY SAVINGS")
+	for _, group := range types {
+		fmt.Fprintf(tw, "%s\t%d\t%s\n", group.ResourceType, group.Findings, formatSavings(group.MonthlySavings))
+	}
+	tw.Flush()
+}
+
+// notificationSummary summarizes the results of the accounts for scan notifications, with the
+// resource types by highest savings. The cost of low confidence findings is summed apart.
+func notificationSummary(accountResults map[string]*scanResult, currencySymbol, reportURL string) notifications.Summary {
+	scanSummary := summarizeAccounts(accountResults)
+	summary := notifications.Summary{
+		Accounts:          len(scanSummary.Accounts),
+		Findings:          scanSummary.Findings,
+		MonthlySavings:    scanSummary.MonthlySavings,
+		UnverifiedSavings: scanSummary.UnverifiedSavings,
+		CurrencySymbol:    currencySymbol,
+		ReportURL:         reportURL,
+	}
+	for _, result := range accountResults {
+		summary.Errors += len(result.Errors)
+	}
+	for _, group := range scanSummary.ResourceTypes {
+		summary.ResourceTypes = append(summary.ResourceTypes, notifications.ResourceTypeSummary{
+			ResourceType:   group.ResourceType,
+			Findings:       group.Findings,
+			MonthlySavings: group.MonthlySavings,
+		})
+	}
+	return summary
+}
+
+// s3ReportURL returns the S3 console URL of the results written today with --output s3, or ""
+// for other outputs, whose results cannot be linked
+func s3ReportURL(opts *scanOptions, now time.Time) string {
+	if opts.output != "s3" {
+		return ""
+	}
+	query := url.Values{"prefix": {now.Format("2006/01/02") + "/"}}
+	if opts.bucketRegion != "" {
+		query.Set("region", opts.bucketRegion)
+	}
+	return fmt.Sprintf("https://s3.console.aws.amazon.com/s3/buckets/%s?%s", url.PathEscape(opts.bucket), query.Encode())
+}
+
+// notifySlack posts the summary of the scan to the Slack webhook. Failures are logged rather
+// than failing a scan whose results were written.
+func notifySlack(opts *scanOptions, accountResults map[string]*scanResult, currencySymbol string) {
+	if opts.notifySlackWebhook == "" {
+		return
+	}
+	summary := notificationSummary(accountResults, currencySymbol, s3ReportURL(opts, time.Now()))
+	if err := notifications.NewSlackNotifier(opts.notifySlackWebhook).Notify(summary); err != nil {
+		logging.Error("Failed to post scan summary to Slack", err, nil)
+		return
+	}
+	logging.Info("Posted scan summary to Slack", map[string]interface{}{
+		"findings": summary.Findings,
+	})
+}
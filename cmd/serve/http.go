@@ -0,0 +1,132 @@
+package serve
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"cloudsift/internal/logging"
+)
+
+// resultsResponse is the body of GET /api/v1/results: the results of each account of the
+// latest completed scan, as written by scan --output-format json
+type resultsResponse struct {
+	ScanID     string            `json:"scan_id"`
+	Source     string            `json:"source"`
+	StartedAt  time.Time         `json:"started_at"`
+	FinishedAt time.Time         `json:"finished_at"`
+	Accounts   []json.RawMessage `json:"accounts"`
+}
+
+// healthResponse is the body of GET /healthz
+type healthResponse struct {
+	Status            string     `json:"status"`
+	ScanRunning       bool       `json:"scan_running"`
+	LastScanID        string     `json:"last_scan_id,omitempty"`
+	LastScanAt        *time.Time `json:"last_scan_at,omitempty"` // Finish time of the latest completed scan
+	NextScheduledScan *time.Time `json:"next_scheduled_scan,omitempty"`
+}
+
+// errorResponse is the body of failed requests
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// httpHandler returns the HTTP API of the server
+func (s *server) httpHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/results", s.handleResults)
+	mux.HandleFunc("/healthz", s.handleHealth)
+	return mux
+}
+
+// handleResults serves the results of the latest completed scan
+func (s *server) handleResults(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "method not allowed"})
+		return
+	}
+	s.mu.Lock()
+	run := s.latest
+	s.mu.Unlock()
+	if run == nil {
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: "no scan has completed yet"})
+		return
+	}
+
+	run.mu.Lock()
+	response := resultsResponse{
+		ScanID:     run.id,
+		Source:     run.source,
+		StartedAt:  run.startedAt,
+		FinishedAt: run.finishedAt,
+		Accounts:   make([]json.RawMessage, 0, len(run.accounts)),
+	}
+	for _, account := range run.accounts {
+		response.Accounts = append(response.Accounts, account.ResultsJson)
+	}
+	run.mu.Unlock()
+	writeJSON(w, http.StatusOK, response)
+}
+
+// handleHealth reports that the server is up, with the state of its scans
+func (s *server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	response := healthResponse{Status: "ok", ScanRunning: s.running}
+	if s.latest != nil {
+		s.latest.mu.Lock()
+		response.LastScanID = s.latest.id
+		finishedAt := s.latest.finishedAt
+		response.LastScanAt = &finishedAt
+		s.latest.mu.Unlock()
+	}
+	if !s.next.IsZero() {
+		next := s.next
+		response.NextScheduledScan = &next
+	}
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, response)
+}
+
+// writeJSON writes a JSON response with the status code
+func writeJSON(w http.ResponseWriter, statusCode int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		logging.Debug("Failed to write HTTP response", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+}
+
+// serveHTTP serves the HTTP API of the server on the address until the context is done. The
+// address is bound before returning, so it fails right away if it is in use.
+func serveHTTP(ctx context.Context, address string, srv *server) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", address, err)
+	}
+	httpServer := &http.Server{Handler: srv.httpHandler(), ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		<-ctx.Done()
+		logging.Info("Stopping HTTP server", nil)
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = httpServer.Shutdown(shutdownCtx)
+	}()
+	go func() {
+		if err := httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logging.Error("HTTP server stopped", err, nil)
+		}
+	}()
+
+	logging.Info("Serving HTTP API", map[string]interface{}{
+		"address": listener.Addr().String(),
+	})
+	return nil
+}
@@ -0,0 +1,239 @@
+package serve
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloudsift/internal/logging"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+)
+
+// queueWaitSeconds is how long a receive waits for scan requests to arrive
+const queueWaitSeconds = 20
+
+// queueRetryDelay is how long the listener waits after a failed receive, or before receiving
+// again while a scan requested over gRPC runs
+const queueRetryDelay = 10 * time.Second
+
+// queueVisibilityTimeout is how long a request stays hidden from other receivers after each
+// heartbeat while its scan runs
+const queueVisibilityTimeout = 5 * time.Minute
+
+// queueHeartbeatInterval is how often the visibility of a request is extended while its scan
+// runs, well within the visibility timeout
+const queueHeartbeatInterval = time.Minute
+
+// scanMessage is a scan request received from the queue, e.g.
+// {"account_id":"123456789012","scanners":["ebs-volumes"],"regions":["us-east-1"]}
+type scanMessage struct {
+	AccountID  string   `json:"account_id"`
+	Accounts   []string `json:"accounts"`
+	Scanners   []string `json:"scanners"`
+	Regions    []string `json:"regions"`
+	DaysUnused int      `json:"days_unused"`
+}
+
+// args converts a scan request to the flags of the scan command
+func (m scanMessage) args() ([]string, error) {
+	accounts := m.Accounts
+	if m.AccountID != "" {
+		accounts = append([]string{m.AccountID}, accounts...)
+	}
+	if len(accounts) == 0 {
+		return nil, errors.New("scan request does not specify an account")
+	}
+
+	args := []string{"--accounts", strings.Join(accounts, ",")}
+	if len(m.Scanners) > 0 {
+		args = append(args, "--scanners", strings.Join(m.Scanners, ","))
+	}
+	if len(m.Regions) > 0 {
+		args = append(args, "--regions", strings.Join(m.Regions, ","))
+	}
+	if m.DaysUnused > 0 {
+		args = append(args, "--days-unused", strconv.Itoa(m.DaysUnused))
+	}
+	return args, nil
+}
+
+// queueRegion returns the region of an SQS queue URL, e.g. us-east-1 for
+// https://sqs.us-east-1.amazonaws.com/123456789012/cloudsift-scans, or "" if it has none
+func queueRegion(queueURL string) string {
+	parsed, err := url.Parse(queueURL)
+	if err != nil {
+		return ""
+	}
+	labels := strings.Split(parsed.Hostname(), ".")
+	if len(labels) < 3 || labels[0] != "sqs" {
+		return ""
+	}
+	return labels[1]
+}
+
+// queueListener runs the scans requested on an SQS queue, one at a time alongside the scans
+// requested over gRPC
+type queueListener struct {
+	client    sqsiface.SQSAPI
+	queueURL  string
+	server    *server
+	heartbeat time.Duration // Interval of visibility heartbeats, queueHeartbeatInterval if zero
+}
+
+// listen receives scan requests until the context is done. A request is kept hidden from
+// other receivers while its scan runs and deleted from the queue once the scan completes;
+// requests whose scan fails become visible again after the visibility timeout, so they are
+// retried or moved to the queue's dead-letter queue. Requests deferred because another scan
+// runs are made visible again right away. Requests that cannot be parsed are deleted.
+func (l *queueListener) listen(ctx context.Context) {
+	logging.Info("Listening for scan requests on SQS queue", map[string]interface{}{
+		"queue_url": l.queueURL,
+	})
+	for ctx.Err() == nil {
+		output, err := l.client.ReceiveMessageWithContext(ctx, &sqs.ReceiveMessageInput{
+			QueueUrl:            aws.String(l.queueURL),
+			MaxNumberOfMessages: aws.Int64(1),
+			WaitTimeSeconds:     aws.Int64(queueWaitSeconds),
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			logging.Error("Failed to receive scan requests", err, map[string]interface{}{
+				"queue_url": l.queueURL,
+			})
+			sleep(ctx, queueRetryDelay)
+			continue
+		}
+
+		for _, message := range output.Messages {
+			if !l.handle(ctx, message) {
+				sleep(ctx, queueRetryDelay)
+			}
+		}
+	}
+}
+
+// handle runs the scan requested by a message and deletes the message if it should not be
+// received again. It returns false if the scan could not start because another one runs.
+func (l *queueListener) handle(ctx context.Context, message *sqs.Message) bool {
+	messageID := aws.StringValue(message.MessageId)
+
+	var request scanMessage
+	args, err := func() ([]string, error) {
+		if err := json.Unmarshal([]byte(aws.StringValue(message.Body)), &request); err != nil {
+			return nil, fmt.Errorf("invalid scan request: %w", err)
+		}
+		return request.args()
+	}()
+	if err != nil {
+		logging.Error("Discarding scan request", err, map[string]interface{}{
+			"message_id": messageID,
+		})
+		l.delete(message)
+		return true
+	}
+
+	run, err := l.server.start(args, "SQS")
+	if errors.Is(err, errScanRunning) {
+		logging.Info("Deferring scan request while another scan runs", map[string]interface{}{
+			"message_id": messageID,
+		})
+		l.changeVisibility(message, 0)
+		return false
+	}
+	if err != nil {
+		logging.Error("Failed to start requested scan", err, map[string]interface{}{
+			"message_id": messageID,
+		})
+		return true
+	}
+
+	// Scans can outlast the queue's visibility timeout, which would deliver the request again
+	stopHeartbeat := l.keepHidden(message)
+	err = run.wait(ctx)
+	stopHeartbeat()
+	if err != nil {
+		return true
+	}
+	logging.Info("Completed scan requested over SQS", map[string]interface{}{
+		"message_id": messageID,
+		"scan_id":    run.id,
+	})
+	l.delete(message)
+	return true
+}
+
+// keepHidden extends the visibility of a message at each heartbeat until the returned
+// function is called
+func (l *queueListener) keepHidden(message *sqs.Message) func() {
+	interval := l.heartbeat
+	if interval == 0 {
+		interval = queueHeartbeatInterval
+	}
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				l.changeVisibility(message, queueVisibilityTimeout)
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// changeVisibility hides a message from receivers for a duration, or makes it visible again
+// if the duration is zero
+func (l *queueListener) changeVisibility(message *sqs.Message, timeout time.Duration) {
+	_, err := l.client.ChangeMessageVisibility(&sqs.ChangeMessageVisibilityInput{
+		QueueUrl:          aws.String(l.queueURL),
+		ReceiptHandle:     message.ReceiptHandle,
+		VisibilityTimeout: aws.Int64(int64(timeout.Seconds())),
+	})
+	if err != nil {
+		logging.Error("Failed to change visibility of scan request", err, map[string]interface{}{
+			"message_id": aws.StringValue(message.MessageId),
+		})
+	}
+}
+
+// delete removes a handled message from the queue
+func (l *queueListener) delete(message *sqs.Message) {
+	_, err := l.client.DeleteMessage(&sqs.DeleteMessageInput{
+		QueueUrl:      aws.String(l.queueURL),
+		ReceiptHandle: message.ReceiptHandle,
+	})
+	if err != nil {
+		logging.Error("Failed to delete scan request", err, map[string]interface{}{
+			"message_id": aws.StringValue(message.MessageId),
+		})
+	}
+}
+
+// sleep waits for a duration or until the context is done
+func sleep(ctx context.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
@@ -0,0 +1,51 @@
+package serve
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"cloudsift/internal/logging"
+	"cloudsift/internal/schedule"
+)
+
+// runSchedule starts a scan with the configuration of the server each time the schedule
+// fires, until the context is done. A scan is skipped while another one runs.
+func (s *server) runSchedule(ctx context.Context, cron *schedule.Cron) {
+	for {
+		next := cron.Next(time.Now())
+		if next.IsZero() {
+			logging.Warn("Scan schedule never fires, no scans are scheduled", map[string]interface{}{
+				"schedule": cron.String(),
+			})
+			return
+		}
+		s.mu.Lock()
+		s.next = next
+		s.mu.Unlock()
+		logging.Info("Next scheduled scan", map[string]interface{}{
+			"at": next.Format(time.RFC3339),
+		})
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		s.startScheduled()
+	}
+}
+
+// startScheduled starts a scan with the configuration of the server
+func (s *server) startScheduled() {
+	_, err := s.start(nil, "schedule")
+	if errors.Is(err, errScanRunning) {
+		logging.Warn("Skipping scheduled scan, a scan is already running", nil)
+		return
+	}
+	if err != nil {
+		logging.Error("Failed to start scheduled scan", err, nil)
+	}
+}
@@ -0,0 +1,160 @@
+package serve
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"cloudsift/internal/api/scanpb"
+	awsinternal "cloudsift/internal/aws"
+	"cloudsift/internal/config"
+	"cloudsift/internal/logging"
+	"cloudsift/internal/schedule"
+
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+)
+
+type serveOptions struct {
+	grpcAddress      string
+	sqsQueueURL      string
+	httpAddress      string // Address of the HTTP API, empty to not serve it
+	schedule         string // Cron expression of the scheduled scans
+	scheduleTimezone string // IANA timezone the schedule is evaluated in
+	scanOnStart      bool   // Run a scan right away instead of waiting for the schedule
+}
+
+// NewServeCmd creates and returns the serve command
+func NewServeCmd() *cobra.Command {
+	opts := &serveOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve the scan engine over gRPC and HTTP, and run scheduled scans",
+		Long: `Serve the scan engine over gRPC so a separate GUI or orchestration service can drive scans
+remotely. The ScanService (see internal/api/scanpb/scan.proto) starts scans, streams their
+progress and returns their results. Scans use the configuration of the server and run one at
+a time. The server stops on interrupt.
+
+With --sqs-queue-url the server also runs the scans requested on an SQS queue, such as a
+provisioning pipeline requesting a scan of the account of a deprovisioned project. Each
+message is a JSON object with the account to scan and optionally the scanners and regions:
+{"account_id":"123456789012","scanners":["ebs-volumes"],"regions":["us-east-1"]}
+
+With --schedule the server runs scans with its configuration on a cron schedule, e.g. every
+day at 06:00 with "0 6 * * *", so it can run as a long-lived deployment instead of a cron job.
+A scheduled scan is skipped while another scan runs.
+
+With --http-address the server also serves an HTTP API:
+  GET /api/v1/results  Results of each account of the latest completed scan
+  GET /healthz         Whether a scan runs, and when the last scan completed and the next runs
+Set --grpc-address to "" to serve only the HTTP API.`,
+		Example: `  # Serve on the default address
+  cloudsift serve
+
+  # Serve on all interfaces
+  cloudsift serve --grpc-address :50051
+
+  # Also run the scans requested on an SQS queue
+  cloudsift serve --sqs-queue-url https://sqs.us-east-1.amazonaws.com/123456789012/cloudsift-scans
+
+  # Scan every day at 06:00 UTC and serve the latest results over HTTP
+  cloudsift serve --grpc-address "" --http-address :8080 --schedule "0 6 * * *" --scan-on-start`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			return serve(ctx, opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.grpcAddress, "grpc-address", "localhost:50051", "Address the gRPC server listens on, empty to not serve gRPC")
+	cmd.Flags().StringVar(&opts.sqsQueueURL, "sqs-queue-url", "", "URL of an SQS queue to receive scan requests from")
+	cmd.Flags().StringVar(&opts.httpAddress, "http-address", "", "Address to serve the HTTP API on (GET /api/v1/results, GET /healthz), e.g. :8080")
+	cmd.Flags().StringVar(&opts.schedule, "schedule", "", "Cron expression to run scans on with the configuration of the server, e.g. \"0 6 * * *\" or @daily")
+	cmd.Flags().StringVar(&opts.scheduleTimezone, "schedule-timezone", "UTC", "IANA timezone the schedule is evaluated in, e.g. Europe/Berlin")
+	cmd.Flags().BoolVar(&opts.scanOnStart, "scan-on-start", false, "Run a scan when the server starts, so results are available before the first scheduled scan")
+
+	return cmd
+}
+
+// serve hosts the scan service, the HTTP API, the scan schedule and the scan request queue,
+// as enabled by the options, until the context is done
+func serve(ctx context.Context, opts *serveOptions) error {
+	if opts.grpcAddress == "" && opts.httpAddress == "" {
+		return errors.New("--grpc-address or --http-address is required")
+	}
+	var cron *schedule.Cron
+	if opts.schedule != "" {
+		location, err := time.LoadLocation(opts.scheduleTimezone)
+		if err != nil {
+			return fmt.Errorf("invalid --schedule-timezone: %w", err)
+		}
+		if cron, err = schedule.ParseCron(opts.schedule, location); err != nil {
+			return fmt.Errorf("invalid --schedule: %w", err)
+		}
+	}
+
+	var listener net.Listener
+	if opts.grpcAddress != "" {
+		var err error
+		if listener, err = net.Listen("tcp", opts.grpcAddress); err != nil {
+			return fmt.Errorf("failed to listen on %s: %w", opts.grpcAddress, err)
+		}
+	}
+
+	srv := newServer()
+	if opts.sqsQueueURL != "" {
+		sess, err := awsinternal.NewSession(config.Config.Profile, queueRegion(opts.sqsQueueURL))
+		if err != nil {
+			if listener != nil {
+				listener.Close()
+			}
+			return fmt.Errorf("failed to create session for SQS queue: %w", err)
+		}
+		queue := &queueListener{client: sqs.New(sess), queueURL: opts.sqsQueueURL, server: srv}
+		go queue.listen(ctx)
+	}
+	if opts.httpAddress != "" {
+		if err := serveHTTP(ctx, opts.httpAddress, srv); err != nil {
+			if listener != nil {
+				listener.Close()
+			}
+			return err
+		}
+	}
+	if opts.scanOnStart {
+		srv.startScheduled()
+	}
+	if cron != nil {
+		go srv.runSchedule(ctx, cron)
+	}
+
+	if listener == nil {
+		<-ctx.Done()
+		return nil
+	}
+
+	grpcServer := grpc.NewServer()
+	scanpb.RegisterScanServiceServer(grpcServer, srv)
+
+	go func() {
+		<-ctx.Done()
+		logging.Info("Stopping gRPC server", nil)
+		grpcServer.GracefulStop()
+	}()
+
+	logging.Info("Serving scan engine over gRPC", map[string]interface{}{
+		"address": listener.Addr().String(),
+	})
+	if err := grpcServer.Serve(listener); err != nil {
+		return fmt.Errorf("failed to serve gRPC: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,376 @@
+package serve
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"cloudsift/cmd/scan"
+	"cloudsift/internal/api/scanpb"
+	"cloudsift/internal/schedule"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newTestClient serves a server over an in-memory connection and returns a client
+func newTestClient(t *testing.T, srv *server) scanpb.ScanServiceClient {
+	listener := bufconn.Listen(1024 * 1024)
+	grpcServer := grpc.NewServer()
+	scanpb.RegisterScanServiceServer(grpcServer, srv)
+	go func() {
+		_ = grpcServer.Serve(listener)
+	}()
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return scanpb.NewScanServiceClient(conn)
+}
+
+// streamEvents collects the progress events of a scan until it finishes
+func streamEvents(t *testing.T, client scanpb.ScanServiceClient, scanID string) []*scanpb.ProgressEvent {
+	stream, err := client.StreamProgress(context.Background(), &scanpb.StreamProgressRequest{ScanId: scanID})
+	require.NoError(t, err)
+	var events []*scanpb.ProgressEvent
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			return events
+		}
+		require.NoError(t, err)
+		events = append(events, event)
+	}
+}
+
+func TestScanService(t *testing.T) {
+	release := make(chan struct{})
+	var gotArgs []string
+	srv := newServer()
+	srv.runScan = func(args []string, observer scan.Observer) error {
+		gotArgs = args
+		observer.ScanProgress(scan.ProgressEvent{AccountID: "123456789012", Region: "us-east-1", Scanner: "EBS Volumes", Total: 1, Unit: "tasks"})
+		<-release
+		observer.ScanProgress(scan.ProgressEvent{AccountID: "123456789012", Region: "us-east-1", Scanner: "EBS Volumes", Completed: true, ResultCount: 2, Done: 1, Total: 1, Unit: "tasks"})
+		observer.AccountResults("123456789012", "prod", []byte(`{"account_id":"123456789012"}`))
+		return nil
+	}
+	client := newTestClient(t, srv)
+
+	started, err := client.StartScan(context.Background(), &scanpb.StartScanRequest{
+		Scanners:   []string{"ebs-volumes", "ec2-instances"},
+		Regions:    []string{"us-east-1"},
+		DaysUnused: 30,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, started.ScanId)
+
+	// Only one scan runs at a time
+	_, err = client.StartScan(context.Background(), &scanpb.StartScanRequest{})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	results, err := client.GetResults(context.Background(), &scanpb.GetResultsRequest{ScanId: started.ScanId})
+	require.NoError(t, err)
+	assert.Equal(t, scanpb.ScanState_SCAN_STATE_RUNNING, results.State)
+	assert.Empty(t, results.Accounts)
+
+	close(release)
+	events := streamEvents(t, client, started.ScanId)
+	require.Len(t, events, 3)
+	assert.Equal(t, "EBS Volumes", events[0].Scanner)
+	assert.False(t, events[0].ScannerCompleted)
+	assert.True(t, events[1].ScannerCompleted)
+	assert.Equal(t, int32(2), events[1].ResultCount)
+	assert.Equal(t, scanpb.ScanState_SCAN_STATE_COMPLETED, events[2].State)
+	assert.Equal(t, int64(1), events[2].Completed)
+
+	assert.Equal(t, []string{"--scanners", "ebs-volumes,ec2-instances", "--regions", "us-east-1", "--days-unused", "30"}, gotArgs)
+
+	results, err = client.GetResults(context.Background(), &scanpb.GetResultsRequest{ScanId: started.ScanId})
+	require.NoError(t, err)
+	assert.Equal(t, scanpb.ScanState_SCAN_STATE_COMPLETED, results.State)
+	require.Len(t, results.Accounts, 1)
+	assert.Equal(t, "prod", results.Accounts[0].AccountName)
+	assert.JSONEq(t, `{"account_id":"123456789012"}`, string(results.Accounts[0].ResultsJson))
+}
+
+func TestScanServiceFailedScan(t *testing.T) {
+	srv := newServer()
+	srv.runScan = func(args []string, observer scan.Observer) error {
+		return errors.New("no credentials")
+	}
+	client := newTestClient(t, srv)
+
+	started, err := client.StartScan(context.Background(), &scanpb.StartScanRequest{})
+	require.NoError(t, err)
+
+	events := streamEvents(t, client, started.ScanId)
+	require.Len(t, events, 1)
+	assert.Equal(t, scanpb.ScanState_SCAN_STATE_FAILED, events[0].State)
+	assert.Equal(t, "no credentials", events[0].Error)
+
+	_, err = client.GetResults(context.Background(), &scanpb.GetResultsRequest{ScanId: "unknown"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+// fakeQueue serves scan requests to the queue listener and records the deleted messages
+type fakeQueue struct {
+	sqsiface.SQSAPI
+	mu         sync.Mutex
+	messages   []*sqs.Message
+	deleted    []string
+	visibility []string      // Receipt handle and timeout of each visibility change
+	drained    chan struct{} // Closed once all messages were received
+}
+
+func (q *fakeQueue) ChangeMessageVisibility(input *sqs.ChangeMessageVisibilityInput) (*sqs.ChangeMessageVisibilityOutput, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.visibility = append(q.visibility, fmt.Sprintf("%s=%d", aws.StringValue(input.ReceiptHandle), aws.Int64Value(input.VisibilityTimeout)))
+	return &sqs.ChangeMessageVisibilityOutput{}, nil
+}
+
+// visibilityChanges returns the visibility changes made so far
+func (q *fakeQueue) visibilityChanges() []string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return append([]string(nil), q.visibility...)
+}
+
+func (q *fakeQueue) ReceiveMessageWithContext(ctx aws.Context, input *sqs.ReceiveMessageInput, opts ...request.Option) (*sqs.ReceiveMessageOutput, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.messages) == 0 {
+		close(q.drained)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	message := q.messages[0]
+	q.messages = q.messages[1:]
+	return &sqs.ReceiveMessageOutput{Messages: []*sqs.Message{message}}, nil
+}
+
+func (q *fakeQueue) DeleteMessage(input *sqs.DeleteMessageInput) (*sqs.DeleteMessageOutput, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.deleted = append(q.deleted, aws.StringValue(input.ReceiptHandle))
+	return &sqs.DeleteMessageOutput{}, nil
+}
+
+func TestQueueListener(t *testing.T) {
+	queue := &fakeQueue{
+		drained: make(chan struct{}),
+		messages: []*sqs.Message{
+			{MessageId: aws.String("1"), ReceiptHandle: aws.String("valid"), Body: aws.String(`{"account_id":"123456789012","scanners":["ebs-volumes"],"regions":["us-east-1","eu-west-1"],"days_unused":30}`)},
+			{MessageId: aws.String("2"), ReceiptHandle: aws.String("failed"), Body: aws.String(`{"account_id":"210987654321"}`)},
+			{MessageId: aws.String("3"), ReceiptHandle: aws.String("no-account"), Body: aws.String(`{"scanners":["ebs-volumes"]}`)},
+			{MessageId: aws.String("4"), ReceiptHandle: aws.String("invalid"), Body: aws.String(`not json`)},
+		},
+	}
+
+	var gotArgs [][]string
+	srv := newServer()
+	srv.runScan = func(args []string, observer scan.Observer) error {
+		gotArgs = append(gotArgs, args)
+		if args[1] == "210987654321" {
+			return errors.New("access denied")
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		(&queueListener{client: queue, queueURL: "https://sqs.us-east-1.amazonaws.com/123456789012/scans", server: srv}).listen(ctx)
+		close(done)
+	}()
+	<-queue.drained
+	cancel()
+	<-done
+
+	assert.Equal(t, [][]string{
+		{"--accounts", "123456789012", "--scanners", "ebs-volumes", "--regions", "us-east-1,eu-west-1", "--days-unused", "30"},
+		{"--accounts", "210987654321"},
+	}, gotArgs)
+
+	// Failed scans are left on the queue to be retried
+	assert.Equal(t, []string{"valid", "no-account", "invalid"}, queue.deleted)
+}
+
+func TestQueueVisibility(t *testing.T) {
+	queue := &fakeQueue{}
+	release := make(chan struct{})
+	srv := newServer()
+	srv.runScan = func(args []string, observer scan.Observer) error {
+		<-release
+		return nil
+	}
+	listener := &queueListener{client: queue, queueURL: "https://sqs.us-east-1.amazonaws.com/123456789012/scans", server: srv, heartbeat: 5 * time.Millisecond}
+	message := func(handle string) *sqs.Message {
+		return &sqs.Message{MessageId: aws.String(handle), ReceiptHandle: aws.String(handle), Body: aws.String(`{"account_id":"123456789012"}`)}
+	}
+
+	// The request stays hidden while its scan runs
+	handled := make(chan bool)
+	go func() {
+		handled <- listener.handle(context.Background(), message("running"))
+	}()
+	require.Eventually(t, func() bool {
+		return len(queue.visibilityChanges()) >= 2
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, "running=300", queue.visibilityChanges()[0])
+
+	// Requests deferred while it runs are made visible again
+	assert.False(t, listener.handle(context.Background(), message("deferred")))
+	assert.Contains(t, queue.visibilityChanges(), "deferred=0")
+
+	close(release)
+	assert.True(t, <-handled)
+	assert.Equal(t, []string{"running"}, queue.deleted)
+	changes := len(queue.visibilityChanges())
+	time.Sleep(20 * time.Millisecond)
+	assert.Len(t, queue.visibilityChanges(), changes, "heartbeats stop once the scan completes")
+}
+
+func TestQueueRegion(t *testing.T) {
+	assert.Equal(t, "eu-west-1", queueRegion("https://sqs.eu-west-1.amazonaws.com/123456789012/scans"))
+	assert.Equal(t, "", queueRegion("http://localhost:4566/000000000000/scans"))
+}
+
+func TestCronSchedule(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	// Friday
+	now := time.Date(2024, 3, 1, 7, 30, 0, 0, time.UTC)
+
+	for _, tt := range []struct {
+		expr     string
+		location *time.Location
+		want     time.Time
+	}{
+		{"0 6 * * *", time.UTC, time.Date(2024, 3, 2, 6, 0, 0, 0, time.UTC)},
+		{"@hourly", time.UTC, time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)},
+		{"*/20 7 * * *", time.UTC, time.Date(2024, 3, 1, 7, 40, 0, 0, time.UTC)},
+		{"0 6 * * MON-FRI", time.UTC, time.Date(2024, 3, 4, 6, 0, 0, 0, time.UTC)},
+		{"0 0 29 feb *", time.UTC, time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// Both day fields restricted: either matches
+		{"0 12 15 * 0", time.UTC, time.Date(2024, 3, 3, 12, 0, 0, 0, time.UTC)},
+		{"30 8 * * 7", time.UTC, time.Date(2024, 3, 3, 8, 30, 0, 0, time.UTC)},
+		// 08:45 in Berlin is 07:45 UTC
+		{"45 8 * * *", berlin, time.Date(2024, 3, 1, 7, 45, 0, 0, time.UTC)},
+	} {
+		cron, err := schedule.ParseCron(tt.expr, tt.location)
+		require.NoError(t, err, tt.expr)
+		assert.True(t, tt.want.Equal(cron.Next(now)), "%s: got %s, want %s", tt.expr, cron.Next(now), tt.want)
+	}
+
+	never, err := schedule.ParseCron("0 0 30 2 *", time.UTC)
+	require.NoError(t, err)
+	assert.True(t, never.Next(now).IsZero())
+
+	for _, expr := range []string{"0 6 * *", "60 * * * *", "0 6 * * 1-", "*/0 * * * *", "0 6 5-1 * *", "@often", "0 6 * * funday"} {
+		_, err := schedule.ParseCron(expr, time.UTC)
+		assert.Error(t, err, expr)
+	}
+}
+
+func TestHTTPAPI(t *testing.T) {
+	srv := newServer()
+	srv.runScan = func(args []string, observer scan.Observer) error {
+		observer.AccountResults("123456789012", "prod", []byte(`{"account_id":"123456789012","results":{}}`))
+		return nil
+	}
+	api := httptest.NewServer(srv.httpHandler())
+	defer api.Close()
+
+	get := func(path string, body interface{}) int {
+		resp, err := http.Get(api.URL + path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(body))
+		return resp.StatusCode
+	}
+
+	var health healthResponse
+	assert.Equal(t, http.StatusOK, get("/healthz", &health))
+	assert.Equal(t, "ok", health.Status)
+	assert.Nil(t, health.LastScanAt)
+	var failure errorResponse
+	assert.Equal(t, http.StatusNotFound, get("/api/v1/results", &failure))
+	assert.Equal(t, "no scan has completed yet", failure.Error)
+
+	srv.startScheduled()
+	srv.mu.Lock()
+	run := srv.scans[srv.order[0]]
+	srv.mu.Unlock()
+	require.NoError(t, run.wait(context.Background()))
+	require.Eventually(t, func() bool {
+		srv.mu.Lock()
+		defer srv.mu.Unlock()
+		return srv.latest == run
+	}, time.Second, time.Millisecond)
+
+	var results resultsResponse
+	assert.Equal(t, http.StatusOK, get("/api/v1/results", &results))
+	assert.Equal(t, run.id, results.ScanID)
+	assert.Equal(t, "schedule", results.Source)
+	require.Len(t, results.Accounts, 1)
+	assert.JSONEq(t, `{"account_id":"123456789012","results":{}}`, string(results.Accounts[0]))
+
+	assert.Equal(t, http.StatusOK, get("/healthz", &health))
+	assert.Equal(t, run.id, health.LastScanID)
+	assert.NotNil(t, health.LastScanAt)
+	assert.False(t, health.ScanRunning)
+
+	resp, err := http.Post(api.URL+"/api/v1/results", "application/json", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
+
+func TestPruneScans(t *testing.T) {
+	srv := newServer()
+	srv.runScan = func(args []string, observer scan.Observer) error {
+		return nil
+	}
+	for i := 0; i < maxRetainedScans+5; i++ {
+		run, err := srv.start([]string{}, "gRPC")
+		require.NoError(t, err)
+		require.NoError(t, run.wait(context.Background()))
+		require.Eventually(t, func() bool {
+			srv.mu.Lock()
+			defer srv.mu.Unlock()
+			return !srv.running
+		}, time.Second, time.Millisecond)
+	}
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	assert.Len(t, srv.scans, maxRetainedScans)
+	assert.Len(t, srv.order, maxRetainedScans)
+	assert.Same(t, srv.latest, srv.scans[srv.order[len(srv.order)-1]])
+}
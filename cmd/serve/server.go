@@ -0,0 +1,349 @@
+package serve
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"cloudsift/cmd/scan"
+	"cloudsift/internal/api/scanpb"
+	"cloudsift/internal/config"
+	"cloudsift/internal/logging"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxRetainedScans is the number of finished scans whose progress and results are kept, so a
+// long running server does not keep the results of every scan in memory. The latest completed
+// scan is always kept.
+const maxRetainedScans = 20
+
+// scanRun records the progress and results of a scan started through the service
+type scanRun struct {
+	id         string
+	source     string // What requested the scan: gRPC, SQS or schedule
+	startedAt  time.Time
+	mu         sync.Mutex
+	state      scanpb.ScanState
+	err        string
+	finishedAt time.Time
+	events     []*scanpb.ProgressEvent
+	accounts   []*scanpb.AccountResults
+	updated    chan struct{} // Closed and replaced whenever the run changes
+}
+
+func newScanRun(id, source string) *scanRun {
+	return &scanRun{
+		id:        id,
+		source:    source,
+		startedAt: time.Now(),
+		state:     scanpb.ScanState_SCAN_STATE_RUNNING,
+		updated:   make(chan struct{}),
+	}
+}
+
+// ScanProgress implements scan.Observer interface
+func (r *scanRun) ScanProgress(event scan.ProgressEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, &scanpb.ProgressEvent{
+		ScanId:           r.id,
+		State:            scanpb.ScanState_SCAN_STATE_RUNNING,
+		AccountId:        event.AccountID,
+		AccountName:      event.AccountName,
+		Region:           event.Region,
+		Scanner:          event.Scanner,
+		ScannerCompleted: event.Completed,
+		ResultCount:      int32(event.ResultCount),
+		Completed:        event.Done,
+		Total:            event.Total,
+		Unit:             event.Unit,
+	})
+	r.notify()
+}
+
+// AccountResults implements scan.Observer interface
+func (r *scanRun) AccountResults(accountID, accountName string, data []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.accounts = append(r.accounts, &scanpb.AccountResults{
+		AccountId:   accountID,
+		AccountName: accountName,
+		ResultsJson: data,
+	})
+}
+
+// finish records the outcome of the scan and sends the final progress event
+func (r *scanRun) finish(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.state = scanpb.ScanState_SCAN_STATE_COMPLETED
+	r.finishedAt = time.Now()
+	if err != nil {
+		r.state = scanpb.ScanState_SCAN_STATE_FAILED
+		r.err = err.Error()
+	}
+	final := &scanpb.ProgressEvent{ScanId: r.id, State: r.state, Error: r.err}
+	if len(r.events) > 0 {
+		last := r.events[len(r.events)-1]
+		final.Total = last.Total
+		final.Completed = last.Total
+		final.Unit = last.Unit
+	}
+	r.events = append(r.events, final)
+	r.notify()
+}
+
+// wait blocks until the scan finishes or the context is done and returns the error the scan
+// failed with
+func (r *scanRun) wait(ctx context.Context) error {
+	for {
+		r.mu.Lock()
+		state, runErr, updated := r.state, r.err, r.updated
+		r.mu.Unlock()
+		if state != scanpb.ScanState_SCAN_STATE_RUNNING {
+			if runErr != "" {
+				return errors.New(runErr)
+			}
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-updated:
+		}
+	}
+}
+
+// notify wakes the streams waiting for changes. The caller must hold the lock.
+func (r *scanRun) notify() {
+	close(r.updated)
+	r.updated = make(chan struct{})
+}
+
+// eventsSince returns the events after the first n, whether the scan finished and a channel
+// closed on the next change
+func (r *scanRun) eventsSince(n int) ([]*scanpb.ProgressEvent, bool, <-chan struct{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	events := append([]*scanpb.ProgressEvent(nil), r.events[n:]...)
+	return events, r.state != scanpb.ScanState_SCAN_STATE_RUNNING, r.updated
+}
+
+// server implements the scan service around the scan command. Scans share the global
+// configuration, so one scan runs at a time.
+type server struct {
+	scanpb.UnimplementedScanServiceServer
+
+	mu      sync.Mutex
+	scans   map[string]*scanRun
+	order   []string // IDs of the scans, oldest first
+	running bool
+	latest  *scanRun  // Latest completed scan, nil until a scan completes
+	next    time.Time // Next scheduled scan, zero without a schedule
+
+	// runScan runs the scan command with the given arguments
+	runScan func(args []string, observer scan.Observer) error
+}
+
+func newServer() *server {
+	return &server{
+		scans:   make(map[string]*scanRun),
+		runScan: executeScan,
+	}
+}
+
+// executeScan runs the scan command, restoring the global configuration the command's flags
+// override once it returns
+func executeScan(args []string, observer scan.Observer) error {
+	saved := *config.Config
+	defer func() { *config.Config = saved }()
+
+	cmd := scan.NewScanCmdWithObserver(observer)
+	// Without arguments cobra would parse the arguments of the serve command
+	if args == nil {
+		args = []string{}
+	}
+	cmd.SetArgs(args)
+	cmd.SilenceUsage = true
+	return cmd.Execute()
+}
+
+// scanArgs converts a request to the flags of the scan command
+func scanArgs(req *scanpb.StartScanRequest) []string {
+	var args []string
+	if len(req.Scanners) > 0 {
+		args = append(args, "--scanners", strings.Join(req.Scanners, ","))
+	}
+	if len(req.Regions) > 0 {
+		args = append(args, "--regions", strings.Join(req.Regions, ","))
+	}
+	if len(req.Accounts) > 0 {
+		args = append(args, "--accounts", strings.Join(req.Accounts, ","))
+	}
+	if req.OrganizationRole != "" {
+		args = append(args, "--organization-role", req.OrganizationRole)
+	}
+	if req.ScannerRole != "" {
+		args = append(args, "--scanner-role", req.ScannerRole)
+	}
+	if req.DaysUnused > 0 {
+		args = append(args, "--days-unused", strconv.Itoa(int(req.DaysUnused)))
+	}
+	return args
+}
+
+// newScanID returns a random scan ID
+func newScanID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate scan ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// errScanRunning is returned when a scan is requested while another one runs
+var errScanRunning = errors.New("a scan is already running")
+
+// start runs the scan command with the given arguments in the background, unless a scan is
+// already running. The source of the request is logged.
+func (s *server) start(args []string, source string) (*scanRun, error) {
+	id, err := newScanID()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	if s.running {
+		s.mu.Unlock()
+		return nil, errScanRunning
+	}
+	s.running = true
+	run := newScanRun(id, source)
+	s.scans[id] = run
+	s.order = append(s.order, id)
+	s.prune()
+	s.mu.Unlock()
+
+	logging.Info("Starting scan requested over "+source, map[string]interface{}{
+		"scan_id": id,
+		"args":    args,
+	})
+	go func() {
+		err := s.runScan(args, run)
+		if err != nil {
+			logging.Error("Scan requested over "+source+" failed", err, map[string]interface{}{
+				"scan_id": id,
+			})
+		}
+		run.finish(err)
+
+		s.mu.Lock()
+		s.running = false
+		if err == nil {
+			s.latest = run
+		}
+		s.mu.Unlock()
+	}()
+
+	return run, nil
+}
+
+// prune forgets the oldest finished scans beyond maxRetainedScans, except the latest completed
+// scan. The caller must hold the lock.
+func (s *server) prune() {
+	excess := len(s.order) - maxRetainedScans
+	if excess <= 0 {
+		return
+	}
+	kept := s.order[:0]
+	for _, id := range s.order {
+		run := s.scans[id]
+		run.mu.Lock()
+		finished := run.state != scanpb.ScanState_SCAN_STATE_RUNNING
+		run.mu.Unlock()
+		if excess > 0 && finished && run != s.latest {
+			delete(s.scans, id)
+			excess--
+			continue
+		}
+		kept = append(kept, id)
+	}
+	s.order = kept
+}
+
+// StartScan implements scanpb.ScanServiceServer interface
+func (s *server) StartScan(ctx context.Context, req *scanpb.StartScanRequest) (*scanpb.StartScanResponse, error) {
+	run, err := s.start(scanArgs(req), "gRPC")
+	if errors.Is(err, errScanRunning) {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &scanpb.StartScanResponse{ScanId: run.id}, nil
+}
+
+// getScan returns a scan by ID
+func (s *server) getScan(id string) (*scanRun, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	run, ok := s.scans[id]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "scan %q not found", id)
+	}
+	return run, nil
+}
+
+// StreamProgress implements scanpb.ScanServiceServer interface
+func (s *server) StreamProgress(req *scanpb.StreamProgressRequest, stream scanpb.ScanService_StreamProgressServer) error {
+	run, err := s.getScan(req.ScanId)
+	if err != nil {
+		return err
+	}
+
+	sent := 0
+	for {
+		events, done, updated := run.eventsSince(sent)
+		for _, event := range events {
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+		}
+		sent += len(events)
+		if done {
+			return nil
+		}
+
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case <-updated:
+		}
+	}
+}
+
+// GetResults implements scanpb.ScanServiceServer interface
+func (s *server) GetResults(ctx context.Context, req *scanpb.GetResultsRequest) (*scanpb.GetResultsResponse, error) {
+	run, err := s.getScan(req.ScanId)
+	if err != nil {
+		return nil, err
+	}
+
+	run.mu.Lock()
+	defer run.mu.Unlock()
+	return &scanpb.GetResultsResponse{
+		ScanId:   run.id,
+		State:    run.state,
+		Error:    run.err,
+		Accounts: append([]*scanpb.AccountResults(nil), run.accounts...),
+	}, nil
+}
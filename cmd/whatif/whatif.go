@@ -0,0 +1,197 @@
+package whatif
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	awsinternal "cloudsift/internal/aws"
+	"cloudsift/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+// Hours used to turn hourly rates into monthly and yearly costs, matching the scan reports
+const (
+	hoursPerMonth = 24 * 30
+	hoursPerYear  = 24 * 365
+)
+
+// paymentOptions maps payment option flag values to the names used by the AWS APIs
+var paymentOptions = map[string]string{
+	"no-upfront":      awsinternal.PaymentNoUpfront,
+	"partial-upfront": awsinternal.PaymentPartialUpfront,
+	"all-upfront":     awsinternal.PaymentAllUpfront,
+}
+
+type whatifOptions struct {
+	from          string
+	to            string
+	region        string
+	count         int
+	reserved      bool
+	savingsPlans  bool
+	term          string
+	paymentOption string
+}
+
+// comparison holds the hourly rates of both instance types under one pricing model. Err is set
+// when either rate is unavailable.
+type comparison struct {
+	Pricing    string
+	FromHourly float64
+	ToHourly   float64
+	Err        error
+}
+
+// NewWhatIfCmd creates and returns the whatif command
+func NewWhatIfCmd() *cobra.Command {
+	opts := &whatifOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "whatif",
+		Short: "Compare the cost of migrating instances to another instance type",
+		Long: `Compare the cost of migrating EC2 instances from one instance type to another.
+Rates are AWS list prices of shared Linux capacity from the AWS Pricing API, cached with the
+scan prices. On-demand rates are always compared; Reserved Instance and Savings Plan rates
+are compared on request. Upfront fees are spread over the hours of the term.`,
+		Example: `  # Compare on-demand costs of 12 instances
+  cloudsift whatif --from r5.xlarge --to r6g.xlarge --region us-east-1 --count 12
+
+  # Also compare 3 year all upfront Reserved Instance and Savings Plan rates
+  cloudsift whatif --from m5.large --to m7g.large --reserved --savings-plans --term 3yr --payment-option all-upfront`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if opts.from == "" || opts.to == "" {
+				return fmt.Errorf("--from and --to are required")
+			}
+			if opts.count <= 0 {
+				return fmt.Errorf("--count must be greater than 0")
+			}
+			paymentOption, ok := paymentOptions[opts.paymentOption]
+			if !ok {
+				return fmt.Errorf("invalid --payment-option %q: must be no-upfront, partial-upfront or all-upfront", opts.paymentOption)
+			}
+			if err := awsinternal.ValidateCommitment(opts.term, paymentOption); err != nil {
+				return err
+			}
+
+			sess, err := awsinternal.NewSession(config.Config.Profile, "us-east-1")
+			if err != nil {
+				return fmt.Errorf("failed to create session: %w", err)
+			}
+			ce, err := awsinternal.NewCostEstimator(sess, "cache/costs.json")
+			if err != nil {
+				return fmt.Errorf("failed to create cost estimator: %w", err)
+			}
+
+			comparisons, err := compare(ce, opts, paymentOption)
+			if err != nil {
+				return err
+			}
+			printComparisons(os.Stdout, opts, comparisons)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.from, "from", "", "Current instance type (e.g. r5.xlarge)")
+	cmd.Flags().StringVar(&opts.to, "to", "", "Instance type to migrate to (e.g. r6g.xlarge)")
+	cmd.Flags().StringVar(&opts.region, "region", "us-east-1", "Region of the instances")
+	cmd.Flags().IntVar(&opts.count, "count", 1, "Number of instances")
+	cmd.Flags().BoolVar(&opts.reserved, "reserved", false, "Also compare standard Reserved Instance rates")
+	cmd.Flags().BoolVar(&opts.savingsPlans, "savings-plans", false, "Also compare Compute and EC2 Instance Savings Plan rates")
+	cmd.Flags().StringVar(&opts.term, "term", "1yr", "Reserved Instance and Savings Plan term (1yr or 3yr)")
+	cmd.Flags().StringVar(&opts.paymentOption, "payment-option", "no-upfront", "Reserved Instance and Savings Plan payment option (no-upfront, partial-upfront or all-upfront)")
+
+	return cmd
+}
+
+// compare looks up the rates of both instance types under each requested pricing model. Only
+// a missing on-demand rate is an error, since not every commitment is offered for every type.
+func compare(ce *awsinternal.CostEstimator, opts *whatifOptions, paymentOption string) ([]comparison, error) {
+	onDemand := comparison{Pricing: "On-Demand"}
+	for _, rate := range []struct {
+		instanceType string
+		hourly       *float64
+	}{
+		{opts.from, &onDemand.FromHourly},
+		{opts.to, &onDemand.ToHourly},
+	} {
+		costs, err := ce.CalculateCost(awsinternal.ResourceCostConfig{
+			ResourceType: "EC2",
+			ResourceSize: rate.instanceType,
+			Region:       opts.region,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get on-demand price of %s: %w", rate.instanceType, err)
+		}
+		*rate.hourly = costs.HourlyRate
+	}
+	comparisons := []comparison{onDemand}
+
+	commitment := fmt.Sprintf("%s %s", opts.term, paymentOption)
+	if opts.reserved {
+		comparisons = append(comparisons, compareRates("Reserved Instance "+commitment, opts, func(instanceType string) (float64, error) {
+			return ce.EC2ReservedHourlyRate(instanceType, opts.region, opts.term, paymentOption)
+		}))
+	}
+	if opts.savingsPlans {
+		for _, planType := range []string{awsinternal.SavingsPlanCompute, awsinternal.SavingsPlanEC2Instance} {
+			planType := planType
+			comparisons = append(comparisons, compareRates(planType+" Savings Plan "+commitment, opts, func(instanceType string) (float64, error) {
+				return ce.EC2SavingsPlanHourlyRate(instanceType, opts.region, planType, opts.term, paymentOption)
+			}))
+		}
+	}
+	return comparisons, nil
+}
+
+// compareRates looks up the rates of both instance types with a rate function
+func compareRates(pricing string, opts *whatifOptions, rate func(instanceType string) (float64, error)) comparison {
+	result := comparison{Pricing: pricing}
+	result.FromHourly, result.Err = rate(opts.from)
+	if result.Err != nil {
+		return result
+	}
+	result.ToHourly, result.Err = rate(opts.to)
+	return result
+}
+
+// printComparisons writes the comparisons as a table with the costs of all instances
+func printComparisons(w io.Writer, opts *whatifOptions, comparisons []comparison) {
+	fmt.Fprintf(w, "%d x %s -> %s in %s (Linux, shared tenancy)\n\n", opts.count, opts.from, opts.to, opts.region)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "PRICING\t%s ($/HR)\t%s ($/HR)\t%s ($/MO)\t%s ($/MO)\tDIFFERENCE ($/MO)\tDIFFERENCE ($/YR)\tCHANGE\n",
+		opts.from, opts.to, opts.from, opts.to)
+	count := float64(opts.count)
+	for _, c := range comparisons {
+		if c.Err != nil {
+			fmt.Fprintf(tw, "%s\tn/a\tn/a\tn/a\tn/a\tn/a\tn/a\tn/a\n", c.Pricing)
+			continue
+		}
+		fromMonthly := c.FromHourly * hoursPerMonth * count
+		toMonthly := c.ToHourly * hoursPerMonth * count
+		change := "n/a"
+		if c.FromHourly > 0 {
+			change = fmt.Sprintf("%+.1f%%", (c.ToHourly-c.FromHourly)/c.FromHourly*100)
+		}
+		fmt.Fprintf(tw, "%s\t%.4f\t%.4f\t%.2f\t%.2f\t%+.2f\t%+.2f\t%s\n",
+			c.Pricing, c.FromHourly, c.ToHourly, fromMonthly, toMonthly,
+			toMonthly-fromMonthly, (c.ToHourly-c.FromHourly)*hoursPerYear*count, change)
+	}
+	tw.Flush()
+
+	unavailable := false
+	for _, c := range comparisons {
+		if c.Err == nil {
+			continue
+		}
+		if !unavailable {
+			fmt.Fprintln(w)
+			unavailable = true
+		}
+		fmt.Fprintf(w, "%s unavailable: %v\n", c.Pricing, c.Err)
+	}
+}
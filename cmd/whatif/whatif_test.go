@@ -0,0 +1,47 @@
+package whatif
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareRates(t *testing.T) {
+	opts := &whatifOptions{from: "r5.xlarge", to: "r6g.xlarge"}
+	rates := map[string]float64{"r5.xlarge": 0.252, "r6g.xlarge": 0.2016}
+
+	result := compareRates("Reserved Instance 1yr No Upfront", opts, func(instanceType string) (float64, error) {
+		return rates[instanceType], nil
+	})
+	assert.NoError(t, result.Err)
+	assert.Equal(t, 0.252, result.FromHourly)
+	assert.Equal(t, 0.2016, result.ToHourly)
+
+	result = compareRates("Reserved Instance 1yr No Upfront", opts, func(instanceType string) (float64, error) {
+		if instanceType == "r6g.xlarge" {
+			return 0, fmt.Errorf("no offering")
+		}
+		return rates[instanceType], nil
+	})
+	assert.Error(t, result.Err)
+}
+
+func TestPrintComparisons(t *testing.T) {
+	var buf bytes.Buffer
+	opts := &whatifOptions{from: "r5.xlarge", to: "r6g.xlarge", region: "us-east-1", count: 12}
+	printComparisons(&buf, opts, []comparison{
+		{Pricing: "On-Demand", FromHourly: 0.252, ToHourly: 0.2016},
+		{Pricing: "Compute Savings Plan 1yr No Upfront", Err: fmt.Errorf("no rate found")},
+	})
+
+	output := buf.String()
+	assert.Contains(t, output, "12 x r5.xlarge -> r6g.xlarge in us-east-1")
+	// 12 instances for 720 hours: 2177.28 and 1741.82 per month
+	assert.Contains(t, output, "2177.28")
+	assert.Contains(t, output, "1741.82")
+	assert.Contains(t, output, "-435.46")
+	assert.Contains(t, output, "-20.0%")
+	assert.Contains(t, output, "Compute Savings Plan 1yr No Upfront unavailable: no rate found")
+}
@@ -0,0 +1,81 @@
+package annotations
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"cloudsift/internal/aws"
+	"cloudsift/internal/logging"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Triage statuses a reviewer can assign to a finding
+const (
+	StatusAcknowledged   = "acknowledged"
+	StatusPlannedRemoval = "planned_removal"
+	StatusFalsePositive  = "false_positive"
+)
+
+var validStatuses = map[string]bool{
+	StatusAcknowledged:   true,
+	StatusPlannedRemoval: true,
+	StatusFalsePositive:  true,
+}
+
+// Store holds annotations keyed by lower-cased resource ID
+type Store map[string]*aws.Annotation
+
+// Load reads an annotations file mapping resource IDs to annotations, e.g.
+//
+//	vol-0123456789abcdef0:
+//	  status: planned_removal
+//	  note: Scheduled for deletion after the Q3 migration
+//	  author: jdoe
+//
+// JSON files are accepted as well since JSON is valid YAML.
+func Load(path string) (Store, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read annotations file: %w", err)
+	}
+
+	var raw map[string]*aws.Annotation
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse annotations file: %w", err)
+	}
+
+	store := make(Store, len(raw))
+	for resourceID, annotation := range raw {
+		if annotation == nil {
+			continue
+		}
+		annotation.Status = strings.ToLower(strings.TrimSpace(annotation.Status))
+		if !validStatuses[annotation.Status] {
+			return nil, fmt.Errorf("invalid annotation status %q for %s: must be one of %s, %s or %s",
+				annotation.Status, resourceID, StatusAcknowledged, StatusPlannedRemoval, StatusFalsePositive)
+		}
+		store[strings.ToLower(resourceID)] = annotation
+	}
+
+	logging.Info("Loaded annotations", map[string]interface{}{
+		"path":        path,
+		"annotations": len(store),
+	})
+
+	return store, nil
+}
+
+// Apply attaches annotations to matching scan results in place and returns the number of
+// results that were annotated
+func (s Store) Apply(results aws.ScanResults) int {
+	annotated := 0
+	for i := range results {
+		if annotation, ok := s[strings.ToLower(results[i].ResourceID)]; ok {
+			results[i].Annotation = annotation
+			annotated++
+		}
+	}
+	return annotated
+}
@@ -0,0 +1,148 @@
+package anomaly
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Metrics compared between scans
+const (
+	MetricResources      = "unused_resources"
+	MetricMonthlySavings = "monthly_savings"
+)
+
+// Anomaly is a sudden change of a metric of an account between two scans
+type Anomaly struct {
+	AccountID   string
+	AccountName string
+	Metric      string
+	Previous    float64
+	Current     float64
+}
+
+// Change returns the absolute change of the metric
+func (a Anomaly) Change() float64 {
+	return a.Current - a.Previous
+}
+
+// RelativeChange returns the change relative to the previous value, or +Inf if the metric
+// was previously zero
+func (a Anomaly) RelativeChange() float64 {
+	if a.Previous == 0 {
+		return math.Inf(1)
+	}
+	return a.Change() / a.Previous
+}
+
+// Detector finds anomalies between the summaries of two consecutive scans
+type Detector interface {
+	// Name returns the name the detector is selected by
+	Name() string
+
+	// Detect returns the anomalies of the current scan compared to the previous one
+	Detect(previous, current *Summary) []Anomaly
+}
+
+// DetectorConfig configures the sensitivity of detectors
+type DetectorConfig struct {
+	Sensitivity      float64 // Relative change flagged as an anomaly, e.g. 0.5 for 50%
+	MinResources     int     // Minimum change of the unused resource count flagged as an anomaly
+	MinSavingsChange float64 // Minimum change of the monthly savings flagged as an anomaly
+}
+
+// DefaultDetectorConfig flags changes by at least half of the previous value
+var DefaultDetectorConfig = DetectorConfig{
+	Sensitivity:      0.5,
+	MinResources:     5,
+	MinSavingsChange: 50,
+}
+
+// detectorFactories creates the available detectors by name
+var detectorFactories = map[string]func(DetectorConfig) Detector{}
+
+// RegisterDetector makes a detector available by name
+func RegisterDetector(name string, factory func(DetectorConfig) Detector) {
+	detectorFactories[name] = factory
+}
+
+// DetectorNames returns the names of the available detectors in order
+func DetectorNames() []string {
+	names := make([]string, 0, len(detectorFactories))
+	for name := range detectorFactories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewDetector creates a detector by name
+func NewDetector(name string, cfg DetectorConfig) (Detector, error) {
+	factory, ok := detectorFactories[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown detector %q: must be one of %s", name, strings.Join(DetectorNames(), ", "))
+	}
+	return factory(cfg), nil
+}
+
+func init() {
+	RegisterDetector("threshold", func(cfg DetectorConfig) Detector {
+		return &ThresholdDetector{Config: cfg}
+	})
+}
+
+// ThresholdDetector flags metrics that changed by at least the sensitivity relative to the
+// previous scan and by at least the minimum absolute change. Accounts missing from either
+// scan are not compared.
+type ThresholdDetector struct {
+	Config DetectorConfig
+}
+
+// Name implements Detector interface
+func (d *ThresholdDetector) Name() string {
+	return "threshold"
+}
+
+// Detect implements Detector interface
+func (d *ThresholdDetector) Detect(previous, current *Summary) []Anomaly {
+	var anomalies []Anomaly
+	for accountID, account := range current.Accounts {
+		before, ok := previous.Accounts[accountID]
+		if !ok {
+			continue
+		}
+		for _, metric := range []struct {
+			name      string
+			previous  float64
+			current   float64
+			minChange float64
+		}{
+			{MetricResources, float64(before.Resources), float64(account.Resources), float64(d.Config.MinResources)},
+			{MetricMonthlySavings, before.MonthlySavings, account.MonthlySavings, d.Config.MinSavingsChange},
+		} {
+			anomaly := Anomaly{
+				AccountID:   accountID,
+				AccountName: account.AccountName,
+				Metric:      metric.name,
+				Previous:    metric.previous,
+				Current:     metric.current,
+			}
+			if math.Abs(anomaly.Change()) < metric.minChange || anomaly.Change() == 0 {
+				continue
+			}
+			if math.Abs(anomaly.RelativeChange()) < d.Config.Sensitivity {
+				continue
+			}
+			anomalies = append(anomalies, anomaly)
+		}
+	}
+
+	sort.Slice(anomalies, func(i, j int) bool {
+		if anomalies[i].AccountID != anomalies[j].AccountID {
+			return anomalies[i].AccountID < anomalies[j].AccountID
+		}
+		return anomalies[i].Metric > anomalies[j].Metric
+	})
+	return anomalies
+}
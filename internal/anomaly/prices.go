@@ -0,0 +1,41 @@
+package anomaly
+
+import (
+	"math"
+	"sort"
+)
+
+// PriceChange is a change of a cached price between two scans, explaining savings that changed
+// without a change of the unused resources
+type PriceChange struct {
+	Key      string // Key of the price in the pricing cache
+	Previous float64
+	Current  float64
+}
+
+// RelativeChange returns the change relative to the previous price, or +Inf if it was zero
+func (c PriceChange) RelativeChange() float64 {
+	if c.Previous == 0 {
+		return math.Inf(1)
+	}
+	return (c.Current - c.Previous) / c.Previous
+}
+
+// PriceChanges returns the prices cached by both scans that changed, largest relative change
+// first. Prices cached by only one of the scans are not compared.
+func PriceChanges(previous, current map[string]float64) []PriceChange {
+	var changes []PriceChange
+	for key, price := range current {
+		if previousPrice, ok := previous[key]; ok && previousPrice != price {
+			changes = append(changes, PriceChange{Key: key, Previous: previousPrice, Current: price})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		a, b := math.Abs(changes[i].RelativeChange()), math.Abs(changes[j].RelativeChange())
+		if a != b {
+			return a > b
+		}
+		return changes[i].Key < changes[j].Key
+	})
+	return changes
+}
@@ -0,0 +1,79 @@
+package anomaly
+
+import (
+	"time"
+
+	"cloudsift/internal/aws"
+	"cloudsift/internal/history"
+)
+
+// AccountSummary summarizes the findings of an account in one scan
+type AccountSummary struct {
+	AccountName     string         `json:"account_name"`
+	Resources       int            `json:"resources"`         // Unused resources found
+	ResourcesByType map[string]int `json:"resources_by_type"` // Unused resources by resource type
+	MonthlySavings  float64        `json:"monthly_savings"`   // Monthly cost of the unused resources
+}
+
+// Summary summarizes the findings of a scan per account
+type Summary struct {
+	RunID     string                     `json:"run_id"` // ID of the recorded run summarized
+	ScannedAt time.Time                  `json:"scanned_at"`
+	Currency  string                     `json:"currency"`
+	Accounts  map[string]*AccountSummary `json:"accounts"` // Keyed by account ID
+}
+
+// NewSummary creates an empty summary of a scan
+func NewSummary(scannedAt time.Time, currency string) *Summary {
+	return &Summary{
+		ScannedAt: scannedAt.UTC(),
+		Currency:  currency,
+		Accounts:  make(map[string]*AccountSummary),
+	}
+}
+
+// Add adds the findings of a scanner in an account to the summary. The cost of low confidence
+// findings is left out of the savings.
+func (s *Summary) Add(accountID, accountName string, results aws.ScanResults) {
+	account, ok := s.Accounts[accountID]
+	if !ok {
+		account = &AccountSummary{AccountName: accountName, ResourcesByType: make(map[string]int)}
+		s.Accounts[accountID] = account
+	}
+	for _, result := range results {
+		account.Resources++
+		account.ResourcesByType[result.ResourceType]++
+		if costs := result.SavingsBreakdown(); costs != nil {
+			account.MonthlySavings += costs.MonthlyRate
+		}
+	}
+}
+
+// RunSummary summarizes the findings of a recorded scan run per account
+func RunSummary(run *history.Run) *Summary {
+	summary := NewSummary(run.StartedAt, run.Currency)
+	summary.RunID = run.ID
+	for accountID, totals := range run.AccountTotals {
+		summary.Accounts[accountID] = &AccountSummary{
+			AccountName:     totals.AccountName,
+			Resources:       totals.Findings,
+			ResourcesByType: totals.FindingsByType,
+			MonthlySavings:  totals.MonthlySavings,
+		}
+	}
+	return summary
+}
+
+// LoadSummaries reads the summaries of the scan runs recorded in a history directory, oldest
+// first
+func LoadSummaries(dir string) ([]*Summary, error) {
+	runs, err := history.ListRuns(dir)
+	if err != nil {
+		return nil, err
+	}
+	summaries := make([]*Summary, len(runs))
+	for i, run := range runs {
+		summaries[i] = RunSummary(run)
+	}
+	return summaries, nil
+}
@@ -0,0 +1,59 @@
+package anomaly
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// minTrendSpan is the shortest history a savings trend is derived from, as changes from
+	// one day to the next would mostly extrapolate noise
+	minTrendSpan = 7 * 24 * time.Hour
+
+	// maxMonthlyGrowth caps the derived growth rate, so a short history of quickly growing
+	// waste does not compound into implausible projections
+	maxMonthlyGrowth = 0.5
+
+	// averageMonth is the length of an average month
+	averageMonth = 365 * 24 * time.Hour / 12
+)
+
+// MonthlySavings returns the monthly savings of all accounts of the summary
+func (s *Summary) MonthlySavings() float64 {
+	total := 0.0
+	for _, account := range s.Accounts {
+		total += account.MonthlySavings
+	}
+	return total
+}
+
+// SavingsGrowth returns the compound monthly growth rate of the total monthly savings from the
+// oldest to the latest of the summaries, which must be sorted oldest first as returned by
+// LoadSummaries. Summaries in another currency than the latest are ignored. It returns false
+// if the history spans less than a week or the oldest scan found no savings.
+func SavingsGrowth(summaries []*Summary) (float64, bool) {
+	if len(summaries) < 2 {
+		return 0, false
+	}
+	latest := summaries[len(summaries)-1]
+
+	var oldest *Summary
+	for _, summary := range summaries[:len(summaries)-1] {
+		if summary.Currency == latest.Currency {
+			oldest = summary
+			break
+		}
+	}
+	if oldest == nil {
+		return 0, false
+	}
+
+	span := latest.ScannedAt.Sub(oldest.ScannedAt)
+	start := oldest.MonthlySavings()
+	if span < minTrendSpan || start <= 0 {
+		return 0, false
+	}
+
+	growth := math.Pow(latest.MonthlySavings()/start, float64(averageMonth)/float64(span)) - 1
+	return math.Min(growth, maxMonthlyGrowth), true
+}
@@ -0,0 +1,4 @@
+// Package scanpb holds the gRPC service driving the scan engine, generated from scan.proto.
+package scanpb
+
+//go:generate protoc -I .. --go_out=.. --go_opt=paths=source_relative --go-grpc_out=.. --go-grpc_opt=paths=source_relative scanpb/scan.proto
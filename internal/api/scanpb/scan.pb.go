@@ -0,0 +1,676 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v5.29.3
+// source: scanpb/scan.proto
+
+package scanpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// ScanState is the state of a scan
+type ScanState int32
+
+const (
+	ScanState_SCAN_STATE_UNSPECIFIED ScanState = 0
+	ScanState_SCAN_STATE_RUNNING     ScanState = 1
+	ScanState_SCAN_STATE_COMPLETED   ScanState = 2
+	ScanState_SCAN_STATE_FAILED      ScanState = 3
+)
+
+// Enum value maps for ScanState.
+var (
+	ScanState_name = map[int32]string{
+		0: "SCAN_STATE_UNSPECIFIED",
+		1: "SCAN_STATE_RUNNING",
+		2: "SCAN_STATE_COMPLETED",
+		3: "SCAN_STATE_FAILED",
+	}
+	ScanState_value = map[string]int32{
+		"SCAN_STATE_UNSPECIFIED": 0,
+		"SCAN_STATE_RUNNING":     1,
+		"SCAN_STATE_COMPLETED":   2,
+		"SCAN_STATE_FAILED":      3,
+	}
+)
+
+func (x ScanState) Enum() *ScanState {
+	p := new(ScanState)
+	*p = x
+	return p
+}
+
+func (x ScanState) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ScanState) Descriptor() protoreflect.EnumDescriptor {
+	return file_scanpb_scan_proto_enumTypes[0].Descriptor()
+}
+
+func (ScanState) Type() protoreflect.EnumType {
+	return &file_scanpb_scan_proto_enumTypes[0]
+}
+
+func (x ScanState) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ScanState.Descriptor instead.
+func (ScanState) EnumDescriptor() ([]byte, []int) {
+	return file_scanpb_scan_proto_rawDescGZIP(), []int{0}
+}
+
+// StartScanRequest selects what to scan. Empty fields fall back to the server's configuration,
+// like the flags of the scan command.
+type StartScanRequest struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Scanners         []string               `protobuf:"bytes,1,rep,name=scanners,proto3" json:"scanners,omitempty"`
+	Regions          []string               `protobuf:"bytes,2,rep,name=regions,proto3" json:"regions,omitempty"`
+	Accounts         []string               `protobuf:"bytes,3,rep,name=accounts,proto3" json:"accounts,omitempty"`
+	OrganizationRole string                 `protobuf:"bytes,4,opt,name=organization_role,json=organizationRole,proto3" json:"organization_role,omitempty"`
+	ScannerRole      string                 `protobuf:"bytes,5,opt,name=scanner_role,json=scannerRole,proto3" json:"scanner_role,omitempty"`
+	DaysUnused       int32                  `protobuf:"varint,6,opt,name=days_unused,json=daysUnused,proto3" json:"days_unused,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *StartScanRequest) Reset() {
+	*x = StartScanRequest{}
+	mi := &file_scanpb_scan_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartScanRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartScanRequest) ProtoMessage() {}
+
+func (x *StartScanRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_scanpb_scan_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartScanRequest.ProtoReflect.Descriptor instead.
+func (*StartScanRequest) Descriptor() ([]byte, []int) {
+	return file_scanpb_scan_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *StartScanRequest) GetScanners() []string {
+	if x != nil {
+		return x.Scanners
+	}
+	return nil
+}
+
+func (x *StartScanRequest) GetRegions() []string {
+	if x != nil {
+		return x.Regions
+	}
+	return nil
+}
+
+func (x *StartScanRequest) GetAccounts() []string {
+	if x != nil {
+		return x.Accounts
+	}
+	return nil
+}
+
+func (x *StartScanRequest) GetOrganizationRole() string {
+	if x != nil {
+		return x.OrganizationRole
+	}
+	return ""
+}
+
+func (x *StartScanRequest) GetScannerRole() string {
+	if x != nil {
+		return x.ScannerRole
+	}
+	return ""
+}
+
+func (x *StartScanRequest) GetDaysUnused() int32 {
+	if x != nil {
+		return x.DaysUnused
+	}
+	return 0
+}
+
+type StartScanResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ScanId        string                 `protobuf:"bytes,1,opt,name=scan_id,json=scanId,proto3" json:"scan_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartScanResponse) Reset() {
+	*x = StartScanResponse{}
+	mi := &file_scanpb_scan_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartScanResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartScanResponse) ProtoMessage() {}
+
+func (x *StartScanResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_scanpb_scan_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartScanResponse.ProtoReflect.Descriptor instead.
+func (*StartScanResponse) Descriptor() ([]byte, []int) {
+	return file_scanpb_scan_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *StartScanResponse) GetScanId() string {
+	if x != nil {
+		return x.ScanId
+	}
+	return ""
+}
+
+type StreamProgressRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ScanId        string                 `protobuf:"bytes,1,opt,name=scan_id,json=scanId,proto3" json:"scan_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamProgressRequest) Reset() {
+	*x = StreamProgressRequest{}
+	mi := &file_scanpb_scan_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamProgressRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamProgressRequest) ProtoMessage() {}
+
+func (x *StreamProgressRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_scanpb_scan_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamProgressRequest.ProtoReflect.Descriptor instead.
+func (*StreamProgressRequest) Descriptor() ([]byte, []int) {
+	return file_scanpb_scan_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *StreamProgressRequest) GetScanId() string {
+	if x != nil {
+		return x.ScanId
+	}
+	return ""
+}
+
+// ProgressEvent reports a scanner task starting or completing, or the scan finishing
+type ProgressEvent struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	ScanId           string                 `protobuf:"bytes,1,opt,name=scan_id,json=scanId,proto3" json:"scan_id,omitempty"`
+	State            ScanState              `protobuf:"varint,2,opt,name=state,proto3,enum=cloudsift.v1.ScanState" json:"state,omitempty"`
+	AccountId        string                 `protobuf:"bytes,3,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	AccountName      string                 `protobuf:"bytes,4,opt,name=account_name,json=accountName,proto3" json:"account_name,omitempty"`
+	Region           string                 `protobuf:"bytes,5,opt,name=region,proto3" json:"region,omitempty"`
+	Scanner          string                 `protobuf:"bytes,6,opt,name=scanner,proto3" json:"scanner,omitempty"`
+	ScannerCompleted bool                   `protobuf:"varint,7,opt,name=scanner_completed,json=scannerCompleted,proto3" json:"scanner_completed,omitempty"`
+	ResultCount      int32                  `protobuf:"varint,8,opt,name=result_count,json=resultCount,proto3" json:"result_count,omitempty"` // Results found by the scanner, once completed
+	Completed        int64                  `protobuf:"varint,9,opt,name=completed,proto3" json:"completed,omitempty"`                        // Completed units of the scan
+	Total            int64                  `protobuf:"varint,10,opt,name=total,proto3" json:"total,omitempty"`                               // Total units of the scan
+	Unit             string                 `protobuf:"bytes,11,opt,name=unit,proto3" json:"unit,omitempty"`                                  // Unit of progress, tasks or resources
+	Error            string                 `protobuf:"bytes,12,opt,name=error,proto3" json:"error,omitempty"`                                // Why the scan failed
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *ProgressEvent) Reset() {
+	*x = ProgressEvent{}
+	mi := &file_scanpb_scan_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProgressEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProgressEvent) ProtoMessage() {}
+
+func (x *ProgressEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_scanpb_scan_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProgressEvent.ProtoReflect.Descriptor instead.
+func (*ProgressEvent) Descriptor() ([]byte, []int) {
+	return file_scanpb_scan_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ProgressEvent) GetScanId() string {
+	if x != nil {
+		return x.ScanId
+	}
+	return ""
+}
+
+func (x *ProgressEvent) GetState() ScanState {
+	if x != nil {
+		return x.State
+	}
+	return ScanState_SCAN_STATE_UNSPECIFIED
+}
+
+func (x *ProgressEvent) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *ProgressEvent) GetAccountName() string {
+	if x != nil {
+		return x.AccountName
+	}
+	return ""
+}
+
+func (x *ProgressEvent) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+func (x *ProgressEvent) GetScanner() string {
+	if x != nil {
+		return x.Scanner
+	}
+	return ""
+}
+
+func (x *ProgressEvent) GetScannerCompleted() bool {
+	if x != nil {
+		return x.ScannerCompleted
+	}
+	return false
+}
+
+func (x *ProgressEvent) GetResultCount() int32 {
+	if x != nil {
+		return x.ResultCount
+	}
+	return 0
+}
+
+func (x *ProgressEvent) GetCompleted() int64 {
+	if x != nil {
+		return x.Completed
+	}
+	return 0
+}
+
+func (x *ProgressEvent) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *ProgressEvent) GetUnit() string {
+	if x != nil {
+		return x.Unit
+	}
+	return ""
+}
+
+func (x *ProgressEvent) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type GetResultsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ScanId        string                 `protobuf:"bytes,1,opt,name=scan_id,json=scanId,proto3" json:"scan_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetResultsRequest) Reset() {
+	*x = GetResultsRequest{}
+	mi := &file_scanpb_scan_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetResultsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetResultsRequest) ProtoMessage() {}
+
+func (x *GetResultsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_scanpb_scan_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetResultsRequest.ProtoReflect.Descriptor instead.
+func (*GetResultsRequest) Descriptor() ([]byte, []int) {
+	return file_scanpb_scan_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetResultsRequest) GetScanId() string {
+	if x != nil {
+		return x.ScanId
+	}
+	return ""
+}
+
+type GetResultsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ScanId        string                 `protobuf:"bytes,1,opt,name=scan_id,json=scanId,proto3" json:"scan_id,omitempty"`
+	State         ScanState              `protobuf:"varint,2,opt,name=state,proto3,enum=cloudsift.v1.ScanState" json:"state,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	Accounts      []*AccountResults      `protobuf:"bytes,4,rep,name=accounts,proto3" json:"accounts,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetResultsResponse) Reset() {
+	*x = GetResultsResponse{}
+	mi := &file_scanpb_scan_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetResultsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetResultsResponse) ProtoMessage() {}
+
+func (x *GetResultsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_scanpb_scan_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetResultsResponse.ProtoReflect.Descriptor instead.
+func (*GetResultsResponse) Descriptor() ([]byte, []int) {
+	return file_scanpb_scan_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetResultsResponse) GetScanId() string {
+	if x != nil {
+		return x.ScanId
+	}
+	return ""
+}
+
+func (x *GetResultsResponse) GetState() ScanState {
+	if x != nil {
+		return x.State
+	}
+	return ScanState_SCAN_STATE_UNSPECIFIED
+}
+
+func (x *GetResultsResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *GetResultsResponse) GetAccounts() []*AccountResults {
+	if x != nil {
+		return x.Accounts
+	}
+	return nil
+}
+
+// AccountResults holds the results of an account in the format of the JSON output
+type AccountResults struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AccountId     string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	AccountName   string                 `protobuf:"bytes,2,opt,name=account_name,json=accountName,proto3" json:"account_name,omitempty"`
+	ResultsJson   []byte                 `protobuf:"bytes,3,opt,name=results_json,json=resultsJson,proto3" json:"results_json,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AccountResults) Reset() {
+	*x = AccountResults{}
+	mi := &file_scanpb_scan_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AccountResults) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AccountResults) ProtoMessage() {}
+
+func (x *AccountResults) ProtoReflect() protoreflect.Message {
+	mi := &file_scanpb_scan_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AccountResults.ProtoReflect.Descriptor instead.
+func (*AccountResults) Descriptor() ([]byte, []int) {
+	return file_scanpb_scan_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *AccountResults) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *AccountResults) GetAccountName() string {
+	if x != nil {
+		return x.AccountName
+	}
+	return ""
+}
+
+func (x *AccountResults) GetResultsJson() []byte {
+	if x != nil {
+		return x.ResultsJson
+	}
+	return nil
+}
+
+var File_scanpb_scan_proto protoreflect.FileDescriptor
+
+const file_scanpb_scan_proto_rawDesc = "" +
+	"\n" +
+	"\x11scanpb/scan.proto\x12\fcloudsift.v1\"\xd5\x01\n" +
+	"\x10StartScanRequest\x12\x1a\n" +
+	"\bscanners\x18\x01 \x03(\tR\bscanners\x12\x18\n" +
+	"\aregions\x18\x02 \x03(\tR\aregions\x12\x1a\n" +
+	"\baccounts\x18\x03 \x03(\tR\baccounts\x12+\n" +
+	"\x11organization_role\x18\x04 \x01(\tR\x10organizationRole\x12!\n" +
+	"\fscanner_role\x18\x05 \x01(\tR\vscannerRole\x12\x1f\n" +
+	"\vdays_unused\x18\x06 \x01(\x05R\n" +
+	"daysUnused\",\n" +
+	"\x11StartScanResponse\x12\x17\n" +
+	"\ascan_id\x18\x01 \x01(\tR\x06scanId\"0\n" +
+	"\x15StreamProgressRequest\x12\x17\n" +
+	"\ascan_id\x18\x01 \x01(\tR\x06scanId\"\xf9\x02\n" +
+	"\rProgressEvent\x12\x17\n" +
+	"\ascan_id\x18\x01 \x01(\tR\x06scanId\x12-\n" +
+	"\x05state\x18\x02 \x01(\x0e2\x17.cloudsift.v1.ScanStateR\x05state\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x03 \x01(\tR\taccountId\x12!\n" +
+	"\faccount_name\x18\x04 \x01(\tR\vaccountName\x12\x16\n" +
+	"\x06region\x18\x05 \x01(\tR\x06region\x12\x18\n" +
+	"\ascanner\x18\x06 \x01(\tR\ascanner\x12+\n" +
+	"\x11scanner_completed\x18\a \x01(\bR\x10scannerCompleted\x12!\n" +
+	"\fresult_count\x18\b \x01(\x05R\vresultCount\x12\x1c\n" +
+	"\tcompleted\x18\t \x01(\x03R\tcompleted\x12\x14\n" +
+	"\x05total\x18\n" +
+	" \x01(\x03R\x05total\x12\x12\n" +
+	"\x04unit\x18\v \x01(\tR\x04unit\x12\x14\n" +
+	"\x05error\x18\f \x01(\tR\x05error\",\n" +
+	"\x11GetResultsRequest\x12\x17\n" +
+	"\ascan_id\x18\x01 \x01(\tR\x06scanId\"\xac\x01\n" +
+	"\x12GetResultsResponse\x12\x17\n" +
+	"\ascan_id\x18\x01 \x01(\tR\x06scanId\x12-\n" +
+	"\x05state\x18\x02 \x01(\x0e2\x17.cloudsift.v1.ScanStateR\x05state\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\x128\n" +
+	"\baccounts\x18\x04 \x03(\v2\x1c.cloudsift.v1.AccountResultsR\baccounts\"u\n" +
+	"\x0eAccountResults\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\x12!\n" +
+	"\faccount_name\x18\x02 \x01(\tR\vaccountName\x12!\n" +
+	"\fresults_json\x18\x03 \x01(\fR\vresultsJson*p\n" +
+	"\tScanState\x12\x1a\n" +
+	"\x16SCAN_STATE_UNSPECIFIED\x10\x00\x12\x16\n" +
+	"\x12SCAN_STATE_RUNNING\x10\x01\x12\x18\n" +
+	"\x14SCAN_STATE_COMPLETED\x10\x02\x12\x15\n" +
+	"\x11SCAN_STATE_FAILED\x10\x032\x82\x02\n" +
+	"\vScanService\x12L\n" +
+	"\tStartScan\x12\x1e.cloudsift.v1.StartScanRequest\x1a\x1f.cloudsift.v1.StartScanResponse\x12T\n" +
+	"\x0eStreamProgress\x12#.cloudsift.v1.StreamProgressRequest\x1a\x1b.cloudsift.v1.ProgressEvent0\x01\x12O\n" +
+	"\n" +
+	"GetResults\x12\x1f.cloudsift.v1.GetResultsRequest\x1a .cloudsift.v1.GetResultsResponseB\x1fZ\x1dcloudsift/internal/api/scanpbb\x06proto3"
+
+var (
+	file_scanpb_scan_proto_rawDescOnce sync.Once
+	file_scanpb_scan_proto_rawDescData []byte
+)
+
+func file_scanpb_scan_proto_rawDescGZIP() []byte {
+	file_scanpb_scan_proto_rawDescOnce.Do(func() {
+		file_scanpb_scan_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_scanpb_scan_proto_rawDesc), len(file_scanpb_scan_proto_rawDesc)))
+	})
+	return file_scanpb_scan_proto_rawDescData
+}
+
+var file_scanpb_scan_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_scanpb_scan_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_scanpb_scan_proto_goTypes = []any{
+	(ScanState)(0),                // 0: cloudsift.v1.ScanState
+	(*StartScanRequest)(nil),      // 1: cloudsift.v1.StartScanRequest
+	(*StartScanResponse)(nil),     // 2: cloudsift.v1.StartScanResponse
+	(*StreamProgressRequest)(nil), // 3: cloudsift.v1.StreamProgressRequest
+	(*ProgressEvent)(nil),         // 4: cloudsift.v1.ProgressEvent
+	(*GetResultsRequest)(nil),     // 5: cloudsift.v1.GetResultsRequest
+	(*GetResultsResponse)(nil),    // 6: cloudsift.v1.GetResultsResponse
+	(*AccountResults)(nil),        // 7: cloudsift.v1.AccountResults
+}
+var file_scanpb_scan_proto_depIdxs = []int32{
+	0, // 0: cloudsift.v1.ProgressEvent.state:type_name -> cloudsift.v1.ScanState
+	0, // 1: cloudsift.v1.GetResultsResponse.state:type_name -> cloudsift.v1.ScanState
+	7, // 2: cloudsift.v1.GetResultsResponse.accounts:type_name -> cloudsift.v1.AccountResults
+	1, // 3: cloudsift.v1.ScanService.StartScan:input_type -> cloudsift.v1.StartScanRequest
+	3, // 4: cloudsift.v1.ScanService.StreamProgress:input_type -> cloudsift.v1.StreamProgressRequest
+	5, // 5: cloudsift.v1.ScanService.GetResults:input_type -> cloudsift.v1.GetResultsRequest
+	2, // 6: cloudsift.v1.ScanService.StartScan:output_type -> cloudsift.v1.StartScanResponse
+	4, // 7: cloudsift.v1.ScanService.StreamProgress:output_type -> cloudsift.v1.ProgressEvent
+	6, // 8: cloudsift.v1.ScanService.GetResults:output_type -> cloudsift.v1.GetResultsResponse
+	6, // [6:9] is the sub-list for method output_type
+	3, // [3:6] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_scanpb_scan_proto_init() }
+func file_scanpb_scan_proto_init() {
+	if File_scanpb_scan_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_scanpb_scan_proto_rawDesc), len(file_scanpb_scan_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_scanpb_scan_proto_goTypes,
+		DependencyIndexes: file_scanpb_scan_proto_depIdxs,
+		EnumInfos:         file_scanpb_scan_proto_enumTypes,
+		MessageInfos:      file_scanpb_scan_proto_msgTypes,
+	}.Build()
+	File_scanpb_scan_proto = out.File
+	file_scanpb_scan_proto_goTypes = nil
+	file_scanpb_scan_proto_depIdxs = nil
+}
@@ -0,0 +1,183 @@
+package aws
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/accessanalyzer"
+
+	"cloudsift/internal/logging"
+)
+
+// accessAnalyzerReasons describes each unused access finding type as a scan reason
+var accessAnalyzerReasons = map[string]string{
+	accessanalyzer.FindingTypeUnusedIamrole:          "IAM Access Analyzer reports the role as unused.",
+	accessanalyzer.FindingTypeUnusedIamuserAccessKey: "IAM Access Analyzer reports an unused access key.",
+	accessanalyzer.FindingTypeUnusedIamuserPassword:  "IAM Access Analyzer reports an unused console password.",
+	accessanalyzer.FindingTypeUnusedPermission:       "IAM Access Analyzer reports unused permissions.",
+}
+
+// AccessAnalyzerFinding is an active unused access finding of IAM Access Analyzer
+type AccessAnalyzerFinding struct {
+	ID           string    `json:"id"`
+	Type         string    `json:"type"`     // e.g. UnusedIAMRole or UnusedPermission
+	ResourceARN  string    `json:"resource"` // ARN of the IAM role or user
+	ResourceType string    `json:"resource_type"`
+	AccountID    string    `json:"account_id"` // Account owning the resource
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// ListUnusedAccessFindings returns the active findings of all unused access analyzers in a
+// region. Organization analyzers return findings for every member account, so callers should
+// group findings by AccountID.
+func ListUnusedAccessFindings(sess *session.Session, region string) ([]AccessAnalyzerFinding, error) {
+	client := accessanalyzer.New(sess, aws.NewConfig().WithRegion(region))
+
+	var analyzerARNs []string
+	for _, analyzerType := range []string{accessanalyzer.TypeAccountUnusedAccess, accessanalyzer.TypeOrganizationUnusedAccess} {
+		err := client.ListAnalyzersPages(&accessanalyzer.ListAnalyzersInput{
+			Type: aws.String(analyzerType),
+		}, func(page *accessanalyzer.ListAnalyzersOutput, lastPage bool) bool {
+			for _, analyzer := range page.Analyzers {
+				if aws.StringValue(analyzer.Status) == accessanalyzer.AnalyzerStatusActive {
+					analyzerARNs = append(analyzerARNs, aws.StringValue(analyzer.Arn))
+				}
+			}
+			return true
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list access analyzers: %w", err)
+		}
+	}
+
+	var findings []AccessAnalyzerFinding
+	for _, analyzerARN := range analyzerARNs {
+		err := client.ListFindingsV2Pages(&accessanalyzer.ListFindingsV2Input{
+			AnalyzerArn: aws.String(analyzerARN),
+			Filter: map[string]*accessanalyzer.Criterion{
+				"status": {Eq: aws.StringSlice([]string{accessanalyzer.FindingStatusActive})},
+			},
+		}, func(page *accessanalyzer.ListFindingsV2Output, lastPage bool) bool {
+			for _, finding := range page.Findings {
+				findings = append(findings, AccessAnalyzerFinding{
+					ID:           aws.StringValue(finding.Id),
+					Type:         aws.StringValue(finding.FindingType),
+					ResourceARN:  aws.StringValue(finding.Resource),
+					ResourceType: aws.StringValue(finding.ResourceType),
+					AccountID:    aws.StringValue(finding.ResourceOwnerAccount),
+					UpdatedAt:    aws.TimeValue(finding.UpdatedAt),
+				})
+			}
+			return true
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list findings of analyzer %s: %w", analyzerARN, err)
+		}
+	}
+
+	logging.Debug("Listed IAM Access Analyzer findings", map[string]interface{}{
+		"region":    region,
+		"analyzers": len(analyzerARNs),
+		"findings":  len(findings),
+	})
+
+	return findings, nil
+}
+
+// accessAnalyzerScannerLabel returns the label of the scanner whose results cover the
+// resource of a finding
+func accessAnalyzerScannerLabel(finding AccessAnalyzerFinding) string {
+	if finding.ResourceType == "AWS::IAM::User" || strings.Contains(finding.ResourceARN, ":user/") {
+		return "IAM Users"
+	}
+	return "IAM Roles"
+}
+
+// MergeAccessAnalyzerFindings merges unused access findings of an account into its scan
+// results keyed by scanner label. Findings for a resource CloudSift already reported are
+// added to the existing result, other findings create new results. Resources are matched
+// by ARN, and findings are deduplicated by ID. It returns the number of results added.
+func MergeAccessAnalyzerFindings(results map[string]ScanResults, findings []AccessAnalyzerFinding) int {
+	// Index existing results by ARN
+	type resultRef struct {
+		label string
+		index int
+	}
+	byARN := make(map[string]resultRef)
+	for label, scannerResults := range results {
+		for i, result := range scannerResults {
+			byARN[strings.ToLower(result.ResourceID)] = resultRef{label: label, index: i}
+		}
+	}
+
+	added := 0
+	seen := make(map[string]bool)
+	for _, finding := range findings {
+		if seen[finding.ID] || finding.ResourceARN == "" {
+			continue
+		}
+		seen[finding.ID] = true
+
+		key := strings.ToLower(finding.ResourceARN)
+		ref, ok := byARN[key]
+		if !ok {
+			label := accessAnalyzerScannerLabel(finding)
+			name := finding.ResourceARN[strings.LastIndex(finding.ResourceARN, "/")+1:]
+			results[label] = append(results[label], ScanResult{
+				ResourceType: label,
+				ResourceID:   finding.ResourceARN,
+				ResourceName: name,
+				Details: map[string]interface{}{
+					"region": GlobalRegionLabel(arnPartition(finding.ResourceARN)),
+					"source": "access_analyzer",
+				},
+			})
+			ref = resultRef{label: label, index: len(results[label]) - 1}
+			byARN[key] = ref
+			added++
+		}
+
+		result := &results[ref.label][ref.index]
+		if result.Details == nil {
+			result.Details = make(map[string]interface{})
+		}
+		// Account and organization analyzers can both report the same resource
+		findingTypes, _ := result.Details["access_analyzer_findings"].([]string)
+		if containsString(findingTypes, finding.Type) {
+			continue
+		}
+		result.Details["access_analyzer_findings"] = append(findingTypes, finding.Type)
+
+		reason, ok := accessAnalyzerReasons[finding.Type]
+		if !ok {
+			reason = fmt.Sprintf("IAM Access Analyzer reports %s.", finding.Type)
+		}
+		if result.Reason == "" {
+			result.Reason = reason
+		} else {
+			result.Reason += "\n" + reason
+		}
+	}
+	return added
+}
+
+func containsString(slice []string, item string) bool {
+	for _, s := range slice {
+		if s == item {
+			return true
+		}
+	}
+	return false
+}
+
+// arnPartition returns the partition of an ARN, or an empty string if it is not an ARN
+func arnPartition(arn string) string {
+	parts := strings.SplitN(arn, ":", 3)
+	if len(parts) < 3 || parts[0] != "arn" {
+		return ""
+	}
+	return parts[1]
+}
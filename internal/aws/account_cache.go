@@ -0,0 +1,92 @@
+package aws
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"cloudsift/internal/logging"
+)
+
+// AccountCacheFile caches the accounts listed with the Organizations API, next to the price cache
+const AccountCacheFile = "cache/accounts.json"
+
+// cachedAccountList is the account list of an organization source with the time it was listed
+type cachedAccountList struct {
+	ListedAt time.Time          `json:"listed_at"`
+	Accounts []accountListEntry `json:"accounts"`
+}
+
+// AccountCacheKey identifies the source accounts are listed from, so listing with another
+// profile or organization role does not reuse the accounts of a different organization
+func AccountCacheKey(profile, organizationRole string) string {
+	return profile + "/" + organizationRole
+}
+
+// CachedAccounts returns the accounts cached under a key in the cache file if they were listed
+// less than ttl ago, and otherwise lists them and caches the result. A ttl of 0 or refresh
+// always lists the accounts. Listing errors are returned and not cached; cache errors are
+// logged and fall back to listing.
+func CachedAccounts(cacheFile, key string, ttl time.Duration, refresh bool, list func() ([]Account, error)) ([]Account, error) {
+	cache := make(map[string]cachedAccountList)
+	if data, err := os.ReadFile(cacheFile); err == nil {
+		if err := json.Unmarshal(data, &cache); err != nil {
+			logging.Warn("Ignoring invalid account cache", map[string]interface{}{
+				"cache_file": cacheFile,
+				"error":      err.Error(),
+			})
+			cache = make(map[string]cachedAccountList)
+		}
+	}
+
+	if cached, ok := cache[key]; ok && ttl > 0 && !refresh && time.Since(cached.ListedAt) < ttl {
+		accounts := make([]Account, 0, len(cached.Accounts))
+		for _, entry := range cached.Accounts {
+			accounts = append(accounts, Account{ID: entry.ID, Name: entry.Name, Status: entry.Status})
+		}
+		logging.Info("Using cached account list", map[string]interface{}{
+			"cache_file":    cacheFile,
+			"account_count": len(accounts),
+			"listed_at":     cached.ListedAt.Format(time.RFC3339),
+		})
+		return accounts, nil
+	}
+
+	accounts, err := list()
+	if err != nil {
+		return nil, err
+	}
+	if ttl <= 0 {
+		return accounts, nil
+	}
+
+	entries := make([]accountListEntry, 0, len(accounts))
+	for _, account := range accounts {
+		entries = append(entries, accountListEntry{ID: account.ID, Name: account.Name, Status: account.Status})
+	}
+	cache[key] = cachedAccountList{ListedAt: time.Now().UTC(), Accounts: entries}
+	if err := writeAccountCache(cacheFile, cache); err != nil {
+		logging.Warn("Failed to write account cache", map[string]interface{}{
+			"cache_file": cacheFile,
+			"error":      err.Error(),
+		})
+	}
+	return accounts, nil
+}
+
+// writeAccountCache writes the cached account lists
+func writeAccountCache(cacheFile string, cache map[string]cachedAccountList) error {
+	if err := os.MkdirAll(filepath.Dir(cacheFile), 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal account cache: %w", err)
+	}
+	if err := os.WriteFile(cacheFile, data, 0644); err != nil {
+		return fmt.Errorf("failed to write account cache: %w", err)
+	}
+	return nil
+}
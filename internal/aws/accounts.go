@@ -1,13 +1,26 @@
 package aws
 
 import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/organizations"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 	"github.com/aws/aws-sdk-go/service/sts"
 
+	"cloudsift/internal/config"
 	"cloudsift/internal/logging"
 )
 
@@ -18,8 +31,39 @@ const (
 
 // Account represents an AWS account
 type Account struct {
-	ID   string
-	Name string
+	ID          string
+	Name        string
+	Team        string // Owning team from the account mappings, if configured
+	Environment string // Environment from the account mappings, if configured
+	Status      string // Organizations status (ACTIVE, SUSPENDED or PENDING_CLOSURE), empty outside an organization
+	Management  bool   // Management account of the organization, where remediation rules often differ
+
+	// OrganizationalUnits are the IDs of the organizational units containing the account,
+	// nearest first. They are only listed when output overrides match organizational units.
+	OrganizationalUnits []string
+}
+
+// Active reports whether an account can be scanned. Suspended accounts and accounts pending
+// closure are still listed by Organizations, but roles can no longer be assumed in them.
+func (a Account) Active() bool {
+	return a.Status == "" || a.Status == organizations.AccountStatusActive
+}
+
+// ApplyAccountMappings overrides account names and sets teams and environments from the
+// configured account mappings
+func ApplyAccountMappings(accounts []Account, mappings map[string]config.AccountMapping) []Account {
+	for i, account := range accounts {
+		mapping, ok := mappings[account.ID]
+		if !ok {
+			continue
+		}
+		if mapping.Name != "" {
+			accounts[i].Name = mapping.Name
+		}
+		accounts[i].Team = mapping.Team
+		accounts[i].Environment = mapping.Environment
+	}
+	return accounts
 }
 
 // ListAccounts attempts to list all accounts in the organization, falling back to current account if not in an org
@@ -61,7 +105,10 @@ func tryListOrganizationAccounts(organizationRole string) ([]Account, error) {
 	return ListAccountsWithSession(sess)
 }
 
-// ListAccountsWithSession lists accounts using an existing session
+// ListAccountsWithSession lists accounts using an existing session, either of the management
+// account or of a delegated administrator account. A delegated administrator can only list
+// accounts if the management account attached a delegation policy allowing
+// organizations:ListAccounts to the organization.
 func ListAccountsWithSession(sess *session.Session) ([]Account, error) {
 	svc := organizations.New(sess)
 	input := &organizations.ListAccountsInput{}
@@ -70,14 +117,19 @@ func ListAccountsWithSession(sess *session.Session) ([]Account, error) {
 	err := svc.ListAccountsPages(input, func(page *organizations.ListAccountsOutput, lastPage bool) bool {
 		for _, account := range page.Accounts {
 			accounts = append(accounts, Account{
-				ID:   aws.StringValue(account.Id),
-				Name: aws.StringValue(account.Name),
+				ID:     aws.StringValue(account.Id),
+				Name:   aws.StringValue(account.Name),
+				Status: aws.StringValue(account.Status),
 			})
 		}
 		return !lastPage
 	})
 
 	if err != nil {
+		var awsErr awserr.Error
+		if errors.As(err, &awsErr) && awsErr.Code() == organizations.ErrCodeAccessDeniedException {
+			return nil, fmt.Errorf("failed to list organization accounts, the account must be the management account or a delegated administrator allowed organizations:ListAccounts by the organization's delegation policy: %w", err)
+		}
 		return nil, fmt.Errorf("failed to list organization accounts: %w", err)
 	}
 
@@ -87,6 +139,95 @@ func ListAccountsWithSession(sess *session.Session) ([]Account, error) {
 	return accounts, nil
 }
 
+// ErrManagementAccountUnknown is returned when the management account must be known, to exclude
+// it from the scan, but the organization cannot be described.
+var ErrManagementAccountUnknown = errors.New("management account of the organization cannot be determined")
+
+// MarkManagementAccount marks the management account of the organization among the accounts.
+// Any account of the organization can look it up. Outside an organization no account is marked.
+// If the organization cannot be described, no account is marked, unless required is set, in
+// which case ErrManagementAccountUnknown is returned so the management account is never scanned
+// by mistake.
+func MarkManagementAccount(sess *session.Session, accounts []Account, required bool) ([]Account, error) {
+	if sess == nil {
+		if required {
+			return accounts, fmt.Errorf("%w: no session", ErrManagementAccountUnknown)
+		}
+		return accounts, nil
+	}
+	output, err := organizations.New(sess).DescribeOrganization(&organizations.DescribeOrganizationInput{})
+	if err != nil {
+		var awsErr awserr.Error
+		if errors.As(err, &awsErr) && awsErr.Code() == organizations.ErrCodeAWSOrganizationsNotInUseException {
+			// Outside an organization there is no management account
+			return accounts, nil
+		}
+		if required {
+			return accounts, fmt.Errorf("%w: %w", ErrManagementAccountUnknown, err)
+		}
+		logging.Debug("Could not describe the organization to find its management account", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return accounts, nil
+	}
+
+	managementAccountID := aws.StringValue(output.Organization.MasterAccountId)
+	for i := range accounts {
+		accounts[i].Management = accounts[i].ID == managementAccountID
+	}
+	return accounts, nil
+}
+
+// SetOrganizationalUnits sets the organizational units containing each account, walking up
+// from the account to the root of the organization. The parent of each organizational unit is
+// listed once. Accounts whose parents cannot be listed are left without organizational units.
+func SetOrganizationalUnits(sess *session.Session, accounts []Account) []Account {
+	if sess == nil {
+		return accounts
+	}
+	svc := organizations.New(sess)
+
+	// Parent organizational unit by child ID, empty for children of the root
+	parents := make(map[string]string)
+	parentOf := func(childID string) (string, error) {
+		if parent, ok := parents[childID]; ok {
+			return parent, nil
+		}
+		output, err := svc.ListParents(&organizations.ListParentsInput{ChildId: aws.String(childID)})
+		if err != nil {
+			return "", err
+		}
+		parent := ""
+		if len(output.Parents) > 0 && aws.StringValue(output.Parents[0].Type) == organizations.ParentTypeOrganizationalUnit {
+			parent = aws.StringValue(output.Parents[0].Id)
+		}
+		parents[childID] = parent
+		return parent, nil
+	}
+
+	for i := range accounts {
+		var units []string
+		for childID := accounts[i].ID; ; {
+			parent, err := parentOf(childID)
+			if err != nil {
+				logging.Warn("Could not list the organizational units of account", map[string]interface{}{
+					"account_id": accounts[i].ID,
+					"error":      err.Error(),
+				})
+				units = nil
+				break
+			}
+			if parent == "" {
+				break
+			}
+			units = append(units, parent)
+			childID = parent
+		}
+		accounts[i].OrganizationalUnits = units
+	}
+	return accounts
+}
+
 // getCurrentAccountID gets the current account ID using STS
 func getCurrentAccountID(sess *session.Session) (string, error) {
 	stsSvc := sts.New(sess)
@@ -139,3 +280,151 @@ func ListCurrentAccount(sess *session.Session) ([]Account, error) {
 		},
 	}, nil
 }
+
+// accountListEntry is an account in a JSON account list
+type accountListEntry struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Status string `json:"status"`
+}
+
+// LoadAccountList reads the accounts to scan from a local file or an S3 object given as
+// s3://bucket/key, for organizations whose accounts cannot be listed with the Organizations
+// API. Files ending in .json hold an array of objects with id, name and optional status;
+// other files are CSV with a header naming an id or account_id column and optional name and
+// status columns.
+func LoadAccountList(sess *session.Session, location string) ([]Account, error) {
+	var data []byte
+	var err error
+	if strings.HasPrefix(location, "s3://") {
+		data, err = readS3Object(sess, location)
+	} else {
+		data, err = os.ReadFile(location)
+		if err != nil {
+			err = fmt.Errorf("failed to read account list: %w", err)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var accounts []Account
+	if strings.EqualFold(path.Ext(location), ".json") {
+		accounts, err = parseJSONAccountList(data)
+	} else {
+		accounts, err = parseCSVAccountList(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(accounts) == 0 {
+		return nil, fmt.Errorf("account list %s is empty", location)
+	}
+
+	logging.Info("Loaded account list", map[string]interface{}{
+		"location":      location,
+		"account_count": len(accounts),
+	})
+	return accounts, nil
+}
+
+// readS3Object reads an object given as s3://bucket/key from the region of its bucket
+func readS3Object(sess *session.Session, location string) ([]byte, error) {
+	bucket, key, ok := strings.Cut(strings.TrimPrefix(location, "s3://"), "/")
+	if !ok || bucket == "" || key == "" {
+		return nil, fmt.Errorf("invalid S3 location %q: must be s3://bucket/key", location)
+	}
+
+	region, err := s3manager.GetBucketRegion(context.Background(), sess, bucket, organizationsRegion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get region of bucket %s: %w", bucket, err)
+	}
+	output, err := s3.New(sess, aws.NewConfig().WithRegion(region)).GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account list from %s: %w", location, err)
+	}
+	defer output.Body.Close()
+
+	data, err := io.ReadAll(output.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read account list from %s: %w", location, err)
+	}
+	return data, nil
+}
+
+// parseJSONAccountList parses an array of accounts
+func parseJSONAccountList(data []byte) ([]Account, error) {
+	var entries []accountListEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse account list: %w", err)
+	}
+
+	accounts := make([]Account, 0, len(entries))
+	for i, entry := range entries {
+		account, err := newListedAccount(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid account %d in account list: %w", i+1, err)
+		}
+		accounts = append(accounts, account)
+	}
+	return accounts, nil
+}
+
+// parseCSVAccountList parses accounts from CSV with a header
+func parseCSVAccountList(data []byte) ([]Account, error) {
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse account list: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	idColumn, ok := columns["id"]
+	if !ok {
+		if idColumn, ok = columns["account_id"]; !ok {
+			return nil, fmt.Errorf("account list has no id or account_id column")
+		}
+	}
+	field := func(record []string, names ...string) string {
+		for _, name := range names {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+		}
+		return ""
+	}
+
+	accounts := make([]Account, 0, len(records)-1)
+	for line, record := range records[1:] {
+		account, err := newListedAccount(accountListEntry{
+			ID:     strings.TrimSpace(record[idColumn]),
+			Name:   field(record, "name", "account_name"),
+			Status: field(record, "status"),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("invalid account on line %d of account list: %w", line+2, err)
+		}
+		accounts = append(accounts, account)
+	}
+	return accounts, nil
+}
+
+// newListedAccount validates an account of an account list, naming it by its ID if unnamed
+func newListedAccount(entry accountListEntry) (Account, error) {
+	if len(entry.ID) != 12 || strings.Trim(entry.ID, "0123456789") != "" {
+		return Account{}, fmt.Errorf("account ID %q must be 12 digits", entry.ID)
+	}
+	account := Account{ID: entry.ID, Name: entry.Name, Status: strings.ToUpper(entry.Status)}
+	if account.Name == "" {
+		account.Name = account.ID
+	}
+	return account, nil
+}
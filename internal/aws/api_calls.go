@@ -0,0 +1,146 @@
+package aws
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/costexplorer"
+)
+
+// ErrCodeAPICallBudgetExceeded is the error code of calls refused once the budget is exceeded
+const ErrCodeAPICallBudgetExceeded = "APICallBudgetExceeded"
+
+// apiCallHandlerName names the request handler counting API calls
+const apiCallHandlerName = "cloudsift.APICallTracker"
+
+// Prices in USD of the API calls that are billed. Other calls made by scans are free.
+const (
+	cloudWatchPricePerMetric  = 0.01 / 1000 // GetMetricData, per metric requested
+	cloudWatchPricePerRequest = 0.01 / 1000 // Other CloudWatch API requests
+	costExplorerPricePerCall  = 0.01        // Cost Explorer API requests
+)
+
+// APICallTracker counts the AWS API calls made through the sessions it is attached to, per
+// service, and refuses further calls once an optional budget is exceeded
+type APICallTracker struct {
+	budget   int64 // Maximum number of calls, 0 for no limit
+	total    int64
+	exceeded int32
+
+	mu       sync.Mutex
+	services map[string]int64 // Calls by service
+	cost     float64          // Estimated cost of the calls in USD
+}
+
+// APICallCount is the number of API calls made to a service
+type APICallCount struct {
+	Service string `json:"service"`
+	Calls   int64  `json:"calls"`
+}
+
+// NewAPICallTracker creates a tracker allowing the given number of API calls, 0 for no limit
+func NewAPICallTracker(budget int64) *APICallTracker {
+	return &APICallTracker{
+		budget:   budget,
+		services: make(map[string]int64),
+	}
+}
+
+// Attach counts the API calls of a session. Attaching a nil tracker or attaching a tracker
+// twice has no effect.
+func (t *APICallTracker) Attach(sess *session.Session) {
+	if t == nil || sess == nil {
+		return
+	}
+	sess.Handlers.Validate.Remove(request.NamedHandler{Name: apiCallHandlerName})
+	sess.Handlers.Validate.PushFrontNamed(request.NamedHandler{Name: apiCallHandlerName, Fn: t.track})
+}
+
+// track counts a request, or fails it if the budget is exceeded. Requests are counted once,
+// including requests retried after throttling.
+func (t *APICallTracker) track(r *request.Request) {
+	total := atomic.AddInt64(&t.total, 1)
+	if t.budget > 0 && total > t.budget {
+		atomic.StoreInt32(&t.exceeded, 1)
+		r.Error = awserr.New(ErrCodeAPICallBudgetExceeded,
+			fmt.Sprintf("the scan exceeded its budget of %d AWS API calls", t.budget), nil)
+		return
+	}
+
+	cost := 0.0
+	switch r.ClientInfo.ServiceName {
+	case cloudwatch.ServiceName:
+		cost = cloudWatchPricePerRequest
+		if input, ok := r.Params.(*cloudwatch.GetMetricDataInput); ok {
+			cost = cloudWatchPricePerMetric * float64(len(input.MetricDataQueries))
+		}
+	case costexplorer.ServiceName:
+		cost = costExplorerPricePerCall
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.services[r.ClientInfo.ServiceName]++
+	t.cost += cost
+}
+
+// Total returns the number of API calls made, including calls refused over the budget
+func (t *APICallTracker) Total() int64 {
+	return atomic.LoadInt64(&t.total)
+}
+
+// Exceeded reports whether calls were refused because the budget was exceeded
+func (t *APICallTracker) Exceeded() bool {
+	return atomic.LoadInt32(&t.exceeded) == 1
+}
+
+// Budget returns the maximum number of API calls, 0 for no limit
+func (t *APICallTracker) Budget() int64 {
+	return t.budget
+}
+
+// EstimatedCost returns the estimated cost in USD of the billed API calls made
+func (t *APICallTracker) EstimatedCost() float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.cost
+}
+
+// Services returns the API calls made per service, most calls first
+func (t *APICallTracker) Services() []APICallCount {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	counts := make([]APICallCount, 0, len(t.services))
+	for service, calls := range t.services {
+		counts = append(counts, APICallCount{Service: service, Calls: calls})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Calls != counts[j].Calls {
+			return counts[i].Calls > counts[j].Calls
+		}
+		return counts[i].Service < counts[j].Service
+	})
+	return counts
+}
+
+// IsAPICallBudgetExceeded reports whether an error is caused by an exceeded API call budget
+func IsAPICallBudgetExceeded(err error) bool {
+	var aerr awserr.Error
+	return errors.As(err, &aerr) && aerr.Code() == ErrCodeAPICallBudgetExceeded
+}
+
+// scanAPICallTracker is attached to the regional sessions of scanners, if set
+var scanAPICallTracker atomic.Pointer[APICallTracker]
+
+// SetScanAPICallTracker sets the tracker attached to the regional sessions created by
+// GetSessionInRegion from now on. Nil stops attaching a tracker.
+func SetScanAPICallTracker(t *APICallTracker) {
+	scanAPICallTracker.Store(t)
+}
@@ -0,0 +1,158 @@
+package aws
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// BusinessHours describes a weekly window during which workloads are expected to be active,
+// e.g. "Mon-Fri 08:00-18:00" in the Europe/Berlin timezone
+type BusinessHours struct {
+	Days        [7]bool // Indexed by time.Weekday
+	StartMinute int     // Minutes after midnight at which the window opens
+	EndMinute   int     // Minutes after midnight at which the window closes
+	Location    *time.Location
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// ParseBusinessHours parses a business hours specification of the form
+// "<days> <HH:MM>-<HH:MM>" where days is a comma-separated list of days or day ranges,
+// e.g. "Mon-Fri 08:00-18:00" or "Mon,Wed,Fri 09:00-17:00". The timezone is an IANA
+// name such as "Europe/Berlin"; an empty timezone means UTC.
+func ParseBusinessHours(spec, timezone string) (*BusinessHours, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != 2 {
+		return nil, fmt.Errorf("invalid business hours %q: expected format \"Mon-Fri 08:00-18:00\"", spec)
+	}
+
+	bh := &BusinessHours{Location: time.UTC}
+	if timezone != "" {
+		loc, err := time.LoadLocation(timezone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid business hours timezone %q: %w", timezone, err)
+		}
+		bh.Location = loc
+	}
+
+	for _, part := range strings.Split(strings.ToLower(fields[0]), ",") {
+		bounds := strings.SplitN(part, "-", 2)
+		first, ok := weekdayNames[bounds[0]]
+		if !ok {
+			return nil, fmt.Errorf("invalid business hours day %q", bounds[0])
+		}
+		last := first
+		if len(bounds) == 2 {
+			if last, ok = weekdayNames[bounds[1]]; !ok {
+				return nil, fmt.Errorf("invalid business hours day %q", bounds[1])
+			}
+		}
+		for day := first; ; day = (day + 1) % 7 {
+			bh.Days[day] = true
+			if day == last {
+				break
+			}
+		}
+	}
+
+	times := strings.SplitN(fields[1], "-", 2)
+	if len(times) != 2 {
+		return nil, fmt.Errorf("invalid business hours time range %q", fields[1])
+	}
+	var err error
+	if bh.StartMinute, err = parseClock(times[0]); err != nil {
+		return nil, err
+	}
+	if bh.EndMinute, err = parseClock(times[1]); err != nil {
+		return nil, err
+	}
+	if bh.EndMinute <= bh.StartMinute {
+		return nil, fmt.Errorf("invalid business hours time range %q: end must be after start", fields[1])
+	}
+
+	return bh, nil
+}
+
+// parseClock converts "HH:MM" into minutes after midnight
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid business hours time %q: %w", value, err)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Contains returns true if the given time falls inside the business hours window
+func (bh *BusinessHours) Contains(t time.Time) bool {
+	local := t.In(bh.Location)
+	if !bh.Days[local.Weekday()] {
+		return false
+	}
+	minute := local.Hour()*60 + local.Minute()
+	return minute >= bh.StartMinute && minute < bh.EndMinute
+}
+
+// WeeklyHours returns the number of business hours in a week
+func (bh *BusinessHours) WeeklyHours() float64 {
+	days := 0
+	for _, enabled := range bh.Days {
+		if enabled {
+			days++
+		}
+	}
+	return float64(days) * float64(bh.EndMinute-bh.StartMinute) / 60
+}
+
+// OffHoursFraction returns the fraction of the week that falls outside business hours
+func (bh *BusinessHours) OffHoursFraction() float64 {
+	return 1 - bh.WeeklyHours()/(7*24)
+}
+
+// String returns a human-readable description of the window, e.g. "mon,tue 08:00-18:00 UTC"
+func (bh *BusinessHours) String() string {
+	return fmt.Sprintf("%s %s-%s %s", bh.Weekdays(), bh.StartClock(), bh.EndClock(), bh.Location)
+}
+
+// Weekdays returns the business days in AWS Instance Scheduler period format, e.g. "mon,tue,wed"
+func (bh *BusinessHours) Weekdays() string {
+	var days []string
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if bh.Days[day] {
+			days = append(days, strings.ToLower(day.String()[:3]))
+		}
+	}
+	return strings.Join(days, ",")
+}
+
+// StartClock returns the start of the window as "HH:MM"
+func (bh *BusinessHours) StartClock() string {
+	return fmt.Sprintf("%02d:%02d", bh.StartMinute/60, bh.StartMinute%60)
+}
+
+// EndClock returns the end of the window as "HH:MM"
+func (bh *BusinessHours) EndClock() string {
+	return fmt.Sprintf("%02d:%02d", bh.EndMinute/60, bh.EndMinute%60)
+}
+
+// StartCron returns a cron expression that fires when the window opens
+func (bh *BusinessHours) StartCron() string {
+	return bh.cron(bh.StartMinute)
+}
+
+// StopCron returns a cron expression that fires when the window closes
+func (bh *BusinessHours) StopCron() string {
+	return bh.cron(bh.EndMinute)
+}
+
+func (bh *BusinessHours) cron(minute int) string {
+	return fmt.Sprintf("%d %d * * %s", minute%60, minute/60, strings.ToUpper(bh.Weekdays()))
+}
@@ -0,0 +1,211 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"cloudsift/internal/logging"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/pricing"
+	"github.com/aws/aws-sdk-go/service/savingsplans"
+)
+
+// Commitment payment options, as named by the Pricing and Savings Plans APIs
+const (
+	PaymentNoUpfront      = savingsplans.SavingsPlanPaymentOptionNoUpfront
+	PaymentPartialUpfront = savingsplans.SavingsPlanPaymentOptionPartialUpfront
+	PaymentAllUpfront     = savingsplans.SavingsPlanPaymentOptionAllUpfront
+)
+
+// Savings Plan types covering EC2 instances
+const (
+	SavingsPlanCompute     = savingsplans.SavingsPlanTypeCompute
+	SavingsPlanEC2Instance = savingsplans.SavingsPlanTypeEc2instance
+)
+
+// commitmentTermYears maps commitment terms to their length in years
+var commitmentTermYears = map[string]int{
+	"1yr": 1,
+	"3yr": 3,
+}
+
+// ValidateCommitment returns an error if a commitment term or payment option is unknown
+func ValidateCommitment(term, paymentOption string) error {
+	if _, ok := commitmentTermYears[term]; !ok {
+		return fmt.Errorf("invalid term %q: must be 1yr or 3yr", term)
+	}
+	switch paymentOption {
+	case PaymentNoUpfront, PaymentPartialUpfront, PaymentAllUpfront:
+		return nil
+	}
+	return fmt.Errorf("invalid payment option %q: must be %s, %s or %s", paymentOption, PaymentNoUpfront, PaymentPartialUpfront, PaymentAllUpfront)
+}
+
+// cachedPrice returns a cached price
+func (ce *CostEstimator) cachedPrice(cacheKey string) (float64, bool) {
+	ce.cacheLock.RLock()
+	defer ce.cacheLock.RUnlock()
+	price, ok := ce.priceCache[cacheKey]
+	return price, ok
+}
+
+// cachePrice caches a price and persists the cache
+func (ce *CostEstimator) cachePrice(cacheKey string, price float64) {
+	ce.cacheLock.Lock()
+	ce.priceCache[cacheKey] = price
+	ce.cacheLock.Unlock()
+
+	if err := ce.saveCache(); err != nil {
+		logging.Error("Failed to save cache", err, map[string]interface{}{
+			"cache_file": ce.cacheFile,
+			"cache_key":  cacheKey,
+		})
+	}
+}
+
+// EC2ReservedHourlyRate returns the effective hourly rate of a standard Reserved Instance of
+// shared Linux capacity. Upfront fees are spread over the hours of the term.
+func (ce *CostEstimator) EC2ReservedHourlyRate(instanceType, region, term, paymentOption string) (float64, error) {
+	if err := ValidateCommitment(term, paymentOption); err != nil {
+		return 0, err
+	}
+	cacheKey := fmt.Sprintf("EC2Reserved:%s:%s:%s:%s", region, instanceType, term, paymentOption)
+	if price, ok := ce.cachedPrice(cacheKey); ok {
+		return price, nil
+	}
+
+	location, ok := regionToLocation[region]
+	if !ok {
+		return 0, fmt.Errorf("unknown region: %s", region)
+	}
+
+	if err := ce.rateLimiter.Wait(context.Background()); err != nil {
+		return 0, fmt.Errorf("rate limiter interrupted: %w", err)
+	}
+	result, err := ce.pricingClient.GetProducts(&pricing.GetProductsInput{
+		ServiceCode: aws.String("AmazonEC2"),
+		Filters:     ec2PriceFilters(instanceType, location),
+	})
+	if err != nil {
+		ce.rateLimiter.OnFailure()
+		return 0, fmt.Errorf("failed to get pricing: %w", err)
+	}
+	ce.rateLimiter.OnSuccess()
+
+	termHours := float64(commitmentTermYears[term]) * 365 * 24
+	for _, priceData := range result.PriceList {
+		jsonBytes, err := json.Marshal(priceData)
+		if err != nil {
+			continue
+		}
+		var data struct {
+			Terms struct {
+				Reserved map[string]struct {
+					TermAttributes  map[string]string `json:"termAttributes"`
+					PriceDimensions map[string]struct {
+						Unit         string            `json:"unit"`
+						PricePerUnit map[string]string `json:"pricePerUnit"`
+					} `json:"priceDimensions"`
+				} `json:"Reserved"`
+			} `json:"terms"`
+		}
+		if err := json.Unmarshal(jsonBytes, &data); err != nil {
+			continue
+		}
+
+		for _, offer := range data.Terms.Reserved {
+			if offer.TermAttributes["LeaseContractLength"] != term ||
+				offer.TermAttributes["PurchaseOption"] != paymentOption ||
+				offer.TermAttributes["OfferingClass"] != "standard" {
+				continue
+			}
+
+			var rate float64
+			for _, dimension := range offer.PriceDimensions {
+				price, err := strconv.ParseFloat(dimension.PricePerUnit["USD"], 64)
+				if err != nil {
+					continue
+				}
+				switch dimension.Unit {
+				case "Hrs":
+					rate += price
+				case "Quantity":
+					// Upfront fee
+					rate += price / termHours
+				}
+			}
+
+			ce.cachePrice(cacheKey, rate)
+			return rate, nil
+		}
+	}
+
+	return 0, fmt.Errorf("no %s %s Reserved Instance offering found for %s in %s", term, paymentOption, instanceType, region)
+}
+
+// EC2SavingsPlanHourlyRate returns the hourly rate of shared Linux capacity of an instance
+// type covered by a Savings Plan
+func (ce *CostEstimator) EC2SavingsPlanHourlyRate(instanceType, region, planType, term, paymentOption string) (float64, error) {
+	if err := ValidateCommitment(term, paymentOption); err != nil {
+		return 0, err
+	}
+	cacheKey := fmt.Sprintf("EC2SavingsPlan:%s:%s:%s:%s:%s", region, instanceType, planType, term, paymentOption)
+	if price, ok := ce.cachedPrice(cacheKey); ok {
+		return price, nil
+	}
+
+	input := &savingsplans.DescribeSavingsPlansOfferingRatesInput{
+		Products:                  aws.StringSlice([]string{savingsplans.SavingsPlanProductTypeEc2}),
+		ServiceCodes:              aws.StringSlice([]string{savingsplans.SavingsPlanRateServiceCodeAmazonEc2}),
+		SavingsPlanTypes:          aws.StringSlice([]string{planType}),
+		SavingsPlanPaymentOptions: aws.StringSlice([]string{paymentOption}),
+		Operations:                aws.StringSlice([]string{"RunInstances"}),
+		Filters: []*savingsplans.SavingsPlanOfferingRateFilterElement{
+			{Name: aws.String(savingsplans.SavingsPlanRateFilterAttributeRegion), Values: aws.StringSlice([]string{region})},
+			{Name: aws.String(savingsplans.SavingsPlanRateFilterAttributeInstanceType), Values: aws.StringSlice([]string{instanceType})},
+			{Name: aws.String(savingsplans.SavingsPlanRateFilterAttributeProductDescription), Values: aws.StringSlice([]string{"Linux/UNIX"})},
+			{Name: aws.String(savingsplans.SavingsPlanRateFilterAttributeTenancy), Values: aws.StringSlice([]string{"shared"})},
+		},
+	}
+	termSeconds := int64(commitmentTermYears[term]) * 365 * 24 * 60 * 60
+
+	for {
+		if err := ce.rateLimiter.Wait(context.Background()); err != nil {
+			return 0, fmt.Errorf("rate limiter interrupted: %w", err)
+		}
+		output, err := ce.savingsPlansClient.DescribeSavingsPlansOfferingRates(input)
+		if err != nil {
+			ce.rateLimiter.OnFailure()
+			return 0, fmt.Errorf("failed to get Savings Plans rates: %w", err)
+		}
+		ce.rateLimiter.OnSuccess()
+
+		for _, rate := range output.SearchResults {
+			if rate.SavingsPlanOffering == nil || aws.Int64Value(rate.SavingsPlanOffering.DurationSeconds) != termSeconds {
+				continue
+			}
+			// Only the instance usage itself, not dedicated hosts or unused reservations
+			if !strings.Contains(aws.StringValue(rate.UsageType), "BoxUsage") {
+				continue
+			}
+			price, err := strconv.ParseFloat(aws.StringValue(rate.Rate), 64)
+			if err != nil {
+				continue
+			}
+
+			ce.cachePrice(cacheKey, price)
+			return price, nil
+		}
+
+		if aws.StringValue(output.NextToken) == "" {
+			break
+		}
+		input.NextToken = output.NextToken
+	}
+
+	return 0, fmt.Errorf("no %s %s %s Savings Plan rate found for %s in %s", term, paymentOption, planType, instanceType, region)
+}
@@ -0,0 +1,351 @@
+package aws
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/computeoptimizer"
+
+	"cloudsift/internal/logging"
+)
+
+// Labels of the scanners whose results cover the resources of Compute Optimizer findings.
+// CloudSift has no Lambda scanner, so Lambda findings always create results of their own.
+const (
+	computeOptimizerInstanceLabel = "EC2 Instances"
+	computeOptimizerVolumeLabel   = "EBS Volumes"
+	computeOptimizerLambdaLabel   = "Lambda Functions"
+)
+
+// ComputeOptimizerFinding is an over-provisioned resource reported by AWS Compute Optimizer,
+// with its top ranked recommendation
+type ComputeOptimizerFinding struct {
+	ResourceARN    string            `json:"resource"`
+	ResourceType   string            `json:"resource_type"` // Label of the scanner covering the resource
+	ResourceName   string            `json:"resource_name"`
+	AccountID      string            `json:"account_id"`
+	Finding        string            `json:"finding"`                // e.g. Overprovisioned or NotOptimized
+	ReasonCodes    []string          `json:"reason_codes,omitempty"` // e.g. CPUOverprovisioned
+	Current        string            `json:"current"`                // Current instance type, volume configuration or memory size
+	Recommendation string            `json:"recommendation"`         // Recommended instance type, volume configuration or memory size
+	MonthlySavings float64           `json:"monthly_savings"`        // Estimated monthly savings of the recommendation in USD
+	Tags           map[string]string `json:"tags,omitempty"`
+}
+
+// ListComputeOptimizerFindings returns the over-provisioned EC2 instances, EBS volumes and
+// Lambda functions Compute Optimizer reports in a region. Accounts that have not opted in to
+// Compute Optimizer return an OptInRequiredException error.
+func ListComputeOptimizerFindings(sess *session.Session, region string) ([]ComputeOptimizerFinding, error) {
+	client := computeoptimizer.New(sess, aws.NewConfig().WithRegion(region))
+
+	var findings []ComputeOptimizerFinding
+
+	// Recommendations other than Lambda ones have no pagination helpers
+	instanceInput := &computeoptimizer.GetEC2InstanceRecommendationsInput{
+		Filters: []*computeoptimizer.Filter{{
+			Name:   aws.String(computeoptimizer.FilterNameFinding),
+			Values: aws.StringSlice([]string{computeoptimizer.FindingOverprovisioned}),
+		}},
+	}
+	for {
+		page, err := client.GetEC2InstanceRecommendations(instanceInput)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get EC2 instance recommendations: %w", err)
+		}
+		for _, rec := range page.InstanceRecommendations {
+			finding := ComputeOptimizerFinding{
+				ResourceARN:  aws.StringValue(rec.InstanceArn),
+				ResourceType: computeOptimizerInstanceLabel,
+				ResourceName: aws.StringValue(rec.InstanceName),
+				AccountID:    aws.StringValue(rec.AccountId),
+				Finding:      aws.StringValue(rec.Finding),
+				ReasonCodes:  aws.StringValueSlice(rec.FindingReasonCodes),
+				Current:      aws.StringValue(rec.CurrentInstanceType),
+				Tags:         computeOptimizerTags(rec.Tags),
+			}
+			if option := topInstanceOption(rec.RecommendationOptions); option != nil {
+				finding.Recommendation = aws.StringValue(option.InstanceType)
+				finding.MonthlySavings = estimatedMonthlySavings(option.SavingsOpportunity)
+			}
+			findings = append(findings, finding)
+		}
+		if aws.StringValue(page.NextToken) == "" {
+			break
+		}
+		instanceInput.NextToken = page.NextToken
+	}
+
+	volumeInput := &computeoptimizer.GetEBSVolumeRecommendationsInput{
+		Filters: []*computeoptimizer.EBSFilter{{
+			Name:   aws.String(computeoptimizer.EBSFilterNameFinding),
+			Values: aws.StringSlice([]string{computeoptimizer.EBSFindingNotOptimized}),
+		}},
+	}
+	for {
+		page, err := client.GetEBSVolumeRecommendations(volumeInput)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get EBS volume recommendations: %w", err)
+		}
+		for _, rec := range page.VolumeRecommendations {
+			option := topVolumeOption(rec.VolumeRecommendationOptions)
+			// Volumes are also not optimized when they are under-provisioned, so only
+			// recommendations that save money count as over-provisioned
+			if option == nil || estimatedMonthlySavings(option.SavingsOpportunity) <= 0 {
+				continue
+			}
+			findings = append(findings, ComputeOptimizerFinding{
+				ResourceARN:    aws.StringValue(rec.VolumeArn),
+				ResourceType:   computeOptimizerVolumeLabel,
+				AccountID:      aws.StringValue(rec.AccountId),
+				Finding:        aws.StringValue(rec.Finding),
+				Current:        formatVolumeConfiguration(rec.CurrentConfiguration),
+				Recommendation: formatVolumeConfiguration(option.Configuration),
+				MonthlySavings: estimatedMonthlySavings(option.SavingsOpportunity),
+				Tags:           computeOptimizerTags(rec.Tags),
+			})
+		}
+		if aws.StringValue(page.NextToken) == "" {
+			break
+		}
+		volumeInput.NextToken = page.NextToken
+	}
+
+	err := client.GetLambdaFunctionRecommendationsPages(&computeoptimizer.GetLambdaFunctionRecommendationsInput{
+		Filters: []*computeoptimizer.LambdaFunctionRecommendationFilter{{
+			Name:   aws.String(computeoptimizer.LambdaFunctionRecommendationFilterNameFindingReasonCode),
+			Values: aws.StringSlice([]string{computeoptimizer.LambdaFunctionRecommendationFindingReasonCodeMemoryOverprovisioned}),
+		}},
+	}, func(page *computeoptimizer.GetLambdaFunctionRecommendationsOutput, lastPage bool) bool {
+		for _, rec := range page.LambdaFunctionRecommendations {
+			functionARN := unqualifiedFunctionARN(aws.StringValue(rec.FunctionArn))
+			finding := ComputeOptimizerFinding{
+				ResourceARN:  functionARN,
+				ResourceType: computeOptimizerLambdaLabel,
+				ResourceName: functionARN[strings.LastIndex(functionARN, ":")+1:],
+				AccountID:    aws.StringValue(rec.AccountId),
+				Finding:      aws.StringValue(rec.Finding),
+				ReasonCodes:  aws.StringValueSlice(rec.FindingReasonCodes),
+				Current:      fmt.Sprintf("%d MB", aws.Int64Value(rec.CurrentMemorySize)),
+				Tags:         computeOptimizerTags(rec.Tags),
+			}
+			if option := topMemoryOption(rec.MemorySizeRecommendationOptions); option != nil {
+				finding.Recommendation = fmt.Sprintf("%d MB", aws.Int64Value(option.MemorySize))
+				finding.MonthlySavings = estimatedMonthlySavings(option.SavingsOpportunity)
+			}
+			findings = append(findings, finding)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Lambda function recommendations: %w", err)
+	}
+
+	logging.Debug("Listed Compute Optimizer findings", map[string]interface{}{
+		"region":   region,
+		"findings": len(findings),
+	})
+
+	return findings, nil
+}
+
+// computeOptimizerTags returns the tags of a recommended resource as a map
+func computeOptimizerTags(tags []*computeoptimizer.Tag) map[string]string {
+	if len(tags) == 0 {
+		return nil
+	}
+	result := make(map[string]string, len(tags))
+	for _, tag := range tags {
+		result[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+	}
+	return result
+}
+
+// topInstanceOption returns the recommendation option ranked first
+func topInstanceOption(options []*computeoptimizer.InstanceRecommendationOption) *computeoptimizer.InstanceRecommendationOption {
+	var top *computeoptimizer.InstanceRecommendationOption
+	for _, option := range options {
+		if top == nil || aws.Int64Value(option.Rank) < aws.Int64Value(top.Rank) {
+			top = option
+		}
+	}
+	return top
+}
+
+// topVolumeOption returns the recommendation option ranked first
+func topVolumeOption(options []*computeoptimizer.VolumeRecommendationOption) *computeoptimizer.VolumeRecommendationOption {
+	var top *computeoptimizer.VolumeRecommendationOption
+	for _, option := range options {
+		if top == nil || aws.Int64Value(option.Rank) < aws.Int64Value(top.Rank) {
+			top = option
+		}
+	}
+	return top
+}
+
+// topMemoryOption returns the recommendation option ranked first
+func topMemoryOption(options []*computeoptimizer.LambdaFunctionMemoryRecommendationOption) *computeoptimizer.LambdaFunctionMemoryRecommendationOption {
+	var top *computeoptimizer.LambdaFunctionMemoryRecommendationOption
+	for _, option := range options {
+		if top == nil || aws.Int64Value(option.Rank) < aws.Int64Value(top.Rank) {
+			top = option
+		}
+	}
+	return top
+}
+
+// estimatedMonthlySavings returns the estimated monthly savings of an option, or 0 if there
+// is no estimate
+func estimatedMonthlySavings(opportunity *computeoptimizer.SavingsOpportunity) float64 {
+	if opportunity == nil || opportunity.EstimatedMonthlySavings == nil {
+		return 0
+	}
+	return aws.Float64Value(opportunity.EstimatedMonthlySavings.Value)
+}
+
+// formatVolumeConfiguration describes a volume configuration, e.g. "gp3, 100 GiB, 3000 IOPS"
+func formatVolumeConfiguration(cfg *computeoptimizer.VolumeConfiguration) string {
+	if cfg == nil {
+		return ""
+	}
+	parts := []string{aws.StringValue(cfg.VolumeType), fmt.Sprintf("%d GiB", aws.Int64Value(cfg.VolumeSize))}
+	if iops := aws.Int64Value(cfg.VolumeBaselineIOPS); iops > 0 {
+		parts = append(parts, fmt.Sprintf("%d IOPS", iops))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// unqualifiedFunctionARN removes the version or alias of a Lambda function ARN, since
+// Compute Optimizer reports each version of a function separately
+func unqualifiedFunctionARN(functionARN string) string {
+	parts := strings.Split(functionARN, ":")
+	if len(parts) > 7 {
+		return strings.Join(parts[:7], ":")
+	}
+	return functionARN
+}
+
+// computeOptimizerReason describes a Compute Optimizer finding as a scan reason
+func computeOptimizerReason(finding ComputeOptimizerFinding) string {
+	return fmt.Sprintf("AWS Compute Optimizer reports over-provisioning: %s is recommended instead of %s.", finding.Recommendation, finding.Current)
+}
+
+// computeOptimizerResultARN returns the ARN of the resource of a scan result, built from the
+// ID of results whose scanners report resource IDs rather than ARNs
+func computeOptimizerResultARN(result ScanResult) string {
+	if strings.HasPrefix(result.ResourceID, "arn:") {
+		return result.ResourceID
+	}
+	var resource string
+	switch result.ResourceType {
+	case computeOptimizerInstanceLabel:
+		resource = "instance/"
+	case computeOptimizerVolumeLabel:
+		resource = "volume/"
+	default:
+		return ""
+	}
+	region, _ := result.Details["region"].(string)
+	partition, ok := endpoints.PartitionForRegion(endpoints.DefaultPartitions(), region)
+	if !ok || result.AccountID == "" {
+		return ""
+	}
+	return fmt.Sprintf("arn:%s:ec2:%s:%s:%s%s", partition.ID(), region, result.AccountID, resource, result.ResourceID)
+}
+
+// MergeComputeOptimizerFindings merges the Compute Optimizer findings of an account into its
+// scan results keyed by scanner label. Findings for a resource CloudSift already reported are
+// added to the existing result, keeping its cost, while other findings create new results
+// costed at their estimated savings. Resources are matched and findings deduplicated by ARN.
+// New EC2 instance and EBS volume results are reported by resource ID like the scanners
+// report them, so ignore lists and annotations match them. It returns the number of results
+// added.
+func MergeComputeOptimizerFindings(results map[string]ScanResults, findings []ComputeOptimizerFinding) int {
+	// Index existing results by ARN
+	type resultRef struct {
+		label string
+		index int
+	}
+	byARN := make(map[string]resultRef)
+	for label, scannerResults := range results {
+		for i, result := range scannerResults {
+			if arn := computeOptimizerResultARN(result); arn != "" {
+				byARN[strings.ToLower(arn)] = resultRef{label: label, index: i}
+			}
+		}
+	}
+
+	added := 0
+	seen := make(map[string]bool)
+	for _, finding := range findings {
+		key := strings.ToLower(finding.ResourceARN)
+		if seen[key] || finding.ResourceARN == "" {
+			continue
+		}
+		seen[key] = true
+
+		ref, ok := byARN[key]
+		if !ok {
+			label := finding.ResourceType
+			resourceID := finding.ResourceARN
+			if label == computeOptimizerInstanceLabel || label == computeOptimizerVolumeLabel {
+				resourceID = finding.ResourceARN[strings.LastIndex(finding.ResourceARN, "/")+1:]
+			}
+			name := finding.ResourceName
+			if name == "" {
+				name = finding.ResourceARN[strings.LastIndex(finding.ResourceARN, "/")+1:]
+			}
+			cost := NoCost(NoCostNotEstimated)
+			if finding.MonthlySavings > 0 {
+				cost = map[string]interface{}{
+					"total": &CostBreakdown{
+						HourlyRate:  finding.MonthlySavings / 730,
+						DailyRate:   finding.MonthlySavings / 730 * 24,
+						MonthlyRate: finding.MonthlySavings,
+						YearlyRate:  finding.MonthlySavings * 12,
+					},
+				}
+			}
+			parts := strings.SplitN(finding.ResourceARN, ":", 5)
+			region := ""
+			if len(parts) == 5 {
+				region = parts[3]
+			}
+			results[label] = append(results[label], ScanResult{
+				ResourceType: label,
+				ResourceID:   resourceID,
+				ResourceName: name,
+				Tags:         finding.Tags,
+				Details: map[string]interface{}{
+					"region": region,
+					"arn":    finding.ResourceARN,
+					"source": "compute_optimizer",
+				},
+				Cost: cost,
+			})
+			ref = resultRef{label: label, index: len(results[label]) - 1}
+			added++
+		}
+
+		result := &results[ref.label][ref.index]
+		if result.Details == nil {
+			result.Details = make(map[string]interface{})
+		}
+		result.Details["compute_optimizer_finding"] = finding.Finding
+		if len(finding.ReasonCodes) > 0 {
+			result.Details["compute_optimizer_reason_codes"] = finding.ReasonCodes
+		}
+		result.Details["compute_optimizer_recommendation"] = finding.Recommendation
+		result.Details["compute_optimizer_monthly_savings"] = finding.MonthlySavings
+
+		reason := computeOptimizerReason(finding)
+		if result.Reason == "" {
+			result.Reason = reason
+		} else {
+			result.Reason += "\n" + reason
+		}
+	}
+	return added
+}
@@ -0,0 +1,73 @@
+package aws
+
+import (
+	"sort"
+	"strings"
+)
+
+// TagCostAllocation is the estimated savings of the findings grouped by the values of a tag
+// key, so the savings can be charged back to the teams or cost centers the resources belong to
+type TagCostAllocation struct {
+	TagKey string                   `json:"tag_key"`
+	Groups []TagCostAllocationGroup `json:"groups"` // Highest monthly savings first
+}
+
+// TagCostAllocationGroup is the estimated savings of the findings with a value of a tag key
+type TagCostAllocationGroup struct {
+	Value          string  `json:"value"` // Empty for findings without the tag
+	Findings       int     `json:"findings"`
+	MonthlySavings float64 `json:"monthly_savings"`
+	YearlySavings  float64 `json:"yearly_savings"`
+}
+
+// SummarizeCostAllocation groups the estimated savings of the findings by the values of each
+// tag key. Tag keys are compared case-insensitively, and findings without the tag are grouped
+// under an empty value. The cost of low confidence findings is left out like in the savings
+// totals.
+func SummarizeCostAllocation(results []ScanResult, tagKeys []string) []TagCostAllocation {
+	var allocations []TagCostAllocation
+	for _, tagKey := range tagKeys {
+		groups := make(map[string]*TagCostAllocationGroup)
+		for _, result := range results {
+			value := tagValue(result.Tags, tagKey)
+			group, ok := groups[value]
+			if !ok {
+				group = &TagCostAllocationGroup{Value: value}
+				groups[value] = group
+			}
+			group.Findings++
+			if costs := result.SavingsBreakdown(); costs != nil {
+				group.MonthlySavings += costs.MonthlyRate
+				group.YearlySavings += costs.YearlyRate
+			}
+		}
+
+		allocation := TagCostAllocation{TagKey: tagKey, Groups: make([]TagCostAllocationGroup, 0, len(groups))}
+		for _, group := range groups {
+			allocation.Groups = append(allocation.Groups, *group)
+		}
+		sort.Slice(allocation.Groups, func(i, j int) bool {
+			a, b := allocation.Groups[i], allocation.Groups[j]
+			if a.MonthlySavings != b.MonthlySavings {
+				return a.MonthlySavings > b.MonthlySavings
+			}
+			return a.Value < b.Value
+		})
+		allocations = append(allocations, allocation)
+	}
+	return allocations
+}
+
+// tagValue returns the value of a tag key compared case-insensitively, or "" if the tag is not
+// set
+func tagValue(tags map[string]string, key string) string {
+	if value, ok := tags[key]; ok {
+		return value
+	}
+	for tagKey, value := range tags {
+		if strings.EqualFold(tagKey, key) {
+			return value
+		}
+	}
+	return ""
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/pricing"
+	"github.com/aws/aws-sdk-go/service/savingsplans"
 )
 
 // CostBreakdown represents the cost of a resource over different time periods
@@ -36,12 +37,19 @@ type ResourceCostConfig struct {
 	Region        string
 	CreationTime  time.Time
 	VolumeType    string  // Volume type for EBS (e.g., "gp2", "gp3", "io1")
+	IOPS          int64   // Provisioned IOPS for EBS (io1, io2, gp3)
+	Throughput    int64   // Provisioned throughput in MiB/s for EBS (gp3)
 	LBType        string  // Load balancer type (e.g., "application", "network")
 	ProcessedGB   float64 // Processed GB for load balancers
-	InstanceCount int64   // Instance count for OpenSearch
-	StorageSize   int64   // Storage size for OpenSearch
+	InstanceCount int64   // Data node count for OpenSearch
+	StorageSize   int64   // Storage size in GB of all OpenSearch data nodes
 	MultiAZ       bool    // Multi-AZ for RDS
 	Engine        string  // Database engine for RDS
+
+	ReadCapacityUnits  int64 // Provisioned read capacity units for DynamoDB, including global secondary indexes
+	WriteCapacityUnits int64 // Provisioned write capacity units for DynamoDB, including global secondary indexes
+
+	PricingRegionCode string // Pricing region code of the Local Zone or Wavelength Zone of EC2 and EBS resources, priced instead of Region
 }
 
 // AWS region to location name mapping for pricing API
@@ -161,12 +169,16 @@ var regionToLocation = map[string]string{
 
 // CostEstimator handles AWS resource cost calculations with caching
 type CostEstimator struct {
-	pricingClient *pricing.Pricing
-	cacheFile     string
-	priceCache    map[string]float64
-	cacheLock     sync.RWMutex
-	saveLock      sync.Mutex
-	rateLimiter   *RateLimiter
+	pricingClient      *pricing.Pricing
+	savingsPlansClient *savingsplans.SavingsPlans
+	cacheFile          string
+	priceCache         map[string]float64
+	cacheLock          sync.RWMutex
+	saveLock           sync.Mutex
+	rateLimiter        *RateLimiter
+
+	costMultipliers map[string]float64
+	multipliersLock sync.RWMutex
 }
 
 // DefaultCostEstimator is the default cost estimator instance
@@ -197,13 +209,13 @@ func NewCostEstimator(sess *session.Session, cacheFile string) (*CostEstimator,
 		return nil, fmt.Errorf("failed to create cache directory: %w", err)
 	}
 
-	// Create pricing client with explicit config to ensure region is set to us-east-1 (required for pricing API)
-	cfg := aws.NewConfig().WithRegion("us-east-1")
+	// Create pricing clients in us-east-1 (required for pricing API), on FIPS endpoints where available
 	ce := &CostEstimator{
-		pricingClient: pricing.New(sess, cfg),
-		cacheFile:     cacheFile,
-		priceCache:    make(map[string]float64),
-		rateLimiter:   NewRateLimiter(&config.DefaultRateLimitConfig), // Use default rate limit config
+		pricingClient:      pricing.New(sess, OptionalFIPSConfig("api.pricing", "us-east-1")),
+		savingsPlansClient: savingsplans.New(sess, OptionalFIPSConfig("savingsplans", "us-east-1")),
+		cacheFile:          cacheFile,
+		priceCache:         make(map[string]float64),
+		rateLimiter:        NewRateLimiter(&config.DefaultRateLimitConfig), // Use default rate limit config
 	}
 
 	if err := ce.loadCache(); err != nil {
@@ -245,6 +257,18 @@ func (ce *CostEstimator) loadCache() error {
 	return nil
 }
 
+// PriceCache returns a copy of the cached prices, keyed like the cache file
+func (ce *CostEstimator) PriceCache() map[string]float64 {
+	ce.cacheLock.RLock()
+	defer ce.cacheLock.RUnlock()
+
+	cache := make(map[string]float64, len(ce.priceCache))
+	for k, v := range ce.priceCache {
+		cache[k] = v
+	}
+	return cache
+}
+
 func (ce *CostEstimator) saveCache() error {
 	ce.saveLock.Lock()
 	defer ce.saveLock.Unlock()
@@ -287,14 +311,72 @@ func (ce *CostEstimator) saveCache() error {
 	return nil
 }
 
-func (ce *CostEstimator) getAWSPrice(resourceType, region string, config ResourceCostConfig) (float64, error) {
-	var resourceSizeStr string
-	if resourceType == "EBSVolumes" || resourceType == "EBSSnapshots" {
-		resourceSizeStr = config.VolumeType
-	} else {
-		resourceSizeStr = fmt.Sprintf("%v", config.ResourceSize)
+// ec2PriceFilters returns the Pricing API filters of shared Linux on-demand capacity of an
+// instance type in a location
+func ec2PriceFilters(instanceType, location string) []*pricing.Filter {
+	return []*pricing.Filter{
+		{
+			Type:  aws.String("TERM_MATCH"),
+			Field: aws.String("operatingSystem"),
+			Value: aws.String("Linux"),
+		},
+		{
+			Type:  aws.String("TERM_MATCH"),
+			Field: aws.String("instanceType"),
+			Value: aws.String(instanceType),
+		},
+		{
+			Type:  aws.String("TERM_MATCH"),
+			Field: aws.String("location"),
+			Value: aws.String(location),
+		},
+		{
+			Type:  aws.String("TERM_MATCH"),
+			Field: aws.String("tenancy"),
+			Value: aws.String("Shared"),
+		},
+		{
+			Type:  aws.String("TERM_MATCH"),
+			Field: aws.String("preInstalledSw"),
+			Value: aws.String("NA"),
+		},
+		{
+			Type:  aws.String("TERM_MATCH"),
+			Field: aws.String("capacityStatus"),
+			Value: aws.String("Used"),
+		},
+		{
+			Type:  aws.String("TERM_MATCH"),
+			Field: aws.String("servicecode"),
+			Value: aws.String("AmazonEC2"),
+		},
+		{
+			Type:  aws.String("TERM_MATCH"),
+			Field: aws.String("productFamily"),
+			Value: aws.String("Compute Instance"),
+		},
 	}
-	cacheKey := fmt.Sprintf("%s:%s:%s", resourceType, region, resourceSizeStr)
+}
+
+// zonePriceFilters returns Pricing API filters with the location filter replaced by the
+// pricing region code of a Local Zone or Wavelength Zone, e.g. us-west-2-lax-1
+func zonePriceFilters(filters []*pricing.Filter, regionCode string) []*pricing.Filter {
+	zoneFilters := make([]*pricing.Filter, 0, len(filters))
+	for _, filter := range filters {
+		if aws.StringValue(filter.Field) == "location" {
+			filter = &pricing.Filter{
+				Type:  aws.String("TERM_MATCH"),
+				Field: aws.String("regionCode"),
+				Value: aws.String(regionCode),
+			}
+		}
+		zoneFilters = append(zoneFilters, filter)
+	}
+	return zoneFilters
+}
+
+func (ce *CostEstimator) getAWSPrice(resourceType, region string, config ResourceCostConfig) (float64, error) {
+	cacheKey := priceCacheKey(resourceType, region, config)
 	ce.cacheLock.RLock()
 	if price, ok := ce.priceCache[cacheKey]; ok {
 		ce.cacheLock.RUnlock()
@@ -316,53 +398,70 @@ func (ce *CostEstimator) getAWSPrice(resourceType, region string, config Resourc
 		if !ok {
 			return 0, fmt.Errorf("invalid resource size type for EC2: %T", config.ResourceSize)
 		}
+		filters = ec2PriceFilters(instanceType, location)
+	case "EBSVolumes":
+		_, ok := config.ResourceSize.(int64)
+		if !ok {
+			return 0, fmt.Errorf("invalid resource size type for %s: %T", resourceType, config.ResourceSize)
+		}
 		filters = []*pricing.Filter{
 			{
 				Type:  aws.String("TERM_MATCH"),
-				Field: aws.String("operatingSystem"),
-				Value: aws.String("Linux"),
+				Field: aws.String("servicecode"),
+				Value: aws.String("AmazonEC2"),
 			},
 			{
 				Type:  aws.String("TERM_MATCH"),
-				Field: aws.String("instanceType"),
-				Value: aws.String(instanceType),
+				Field: aws.String("productFamily"),
+				Value: aws.String("Storage"),
+			},
+			{
+				Type:  aws.String("TERM_MATCH"),
+				Field: aws.String("volumeApiName"),
+				Value: aws.String(config.VolumeType),
 			},
 			{
 				Type:  aws.String("TERM_MATCH"),
 				Field: aws.String("location"),
 				Value: aws.String(location),
 			},
+		}
+	case "EBSIOPS", "EBSIOPSTier2", "EBSIOPSTier3":
+		// Provisioned IOPS are billed per IOPS-month, in tiers for io2
+		group := map[string]string{
+			"EBSIOPS":      "EBS IOPS",
+			"EBSIOPSTier2": "EBS IOPS Tier 2",
+			"EBSIOPSTier3": "EBS IOPS Tier 3",
+		}[resourceType]
+		filters = []*pricing.Filter{
 			{
 				Type:  aws.String("TERM_MATCH"),
-				Field: aws.String("tenancy"),
-				Value: aws.String("Shared"),
+				Field: aws.String("servicecode"),
+				Value: aws.String("AmazonEC2"),
 			},
 			{
 				Type:  aws.String("TERM_MATCH"),
-				Field: aws.String("preInstalledSw"),
-				Value: aws.String("NA"),
+				Field: aws.String("productFamily"),
+				Value: aws.String("System Operation"),
 			},
 			{
 				Type:  aws.String("TERM_MATCH"),
-				Field: aws.String("capacityStatus"),
-				Value: aws.String("Used"),
+				Field: aws.String("group"),
+				Value: aws.String(group),
 			},
 			{
 				Type:  aws.String("TERM_MATCH"),
-				Field: aws.String("servicecode"),
-				Value: aws.String("AmazonEC2"),
+				Field: aws.String("volumeApiName"),
+				Value: aws.String(config.VolumeType),
 			},
 			{
 				Type:  aws.String("TERM_MATCH"),
-				Field: aws.String("productFamily"),
-				Value: aws.String("Compute Instance"),
+				Field: aws.String("location"),
+				Value: aws.String(location),
 			},
 		}
-	case "EBSVolumes":
-		_, ok := config.ResourceSize.(int64)
-		if !ok {
-			return 0, fmt.Errorf("invalid resource size type for %s: %T", resourceType, config.ResourceSize)
-		}
+	case "EBSThroughput":
+		// Provisioned throughput is billed per MiB/s-month
 		filters = []*pricing.Filter{
 			{
 				Type:  aws.String("TERM_MATCH"),
@@ -372,7 +471,7 @@ func (ce *CostEstimator) getAWSPrice(resourceType, region string, config Resourc
 			{
 				Type:  aws.String("TERM_MATCH"),
 				Field: aws.String("productFamily"),
-				Value: aws.String("Storage"),
+				Value: aws.String("Provisioned Throughput"),
 			},
 			{
 				Type:  aws.String("TERM_MATCH"),
@@ -494,9 +593,8 @@ func (ce *CostEstimator) getAWSPrice(resourceType, region string, config Resourc
 			return totalPrice, nil
 		}
 	case "DynamoDB":
-		// For DynamoDB we need to check both storage and throughput costs
-		// First get storage cost per GB
-		storageFilters := []*pricing.Filter{
+		// Table storage is billed per GB-month, provisioned capacity is priced separately
+		filters = []*pricing.Filter{
 			{
 				Type:  aws.String("TERM_MATCH"),
 				Field: aws.String("servicecode"),
@@ -514,43 +612,22 @@ func (ce *CostEstimator) getAWSPrice(resourceType, region string, config Resourc
 			},
 		}
 
-		// Get storage price per GB
-		storagePrice, err := ce.getPriceFromAPI(storageFilters)
+		price, err := ce.getPriceFromAPI(filters)
 		if err != nil {
 			return 0, fmt.Errorf("failed to get DynamoDB storage price: %w", err)
 		}
 
-		// Get write capacity price
-		writeFilters := []*pricing.Filter{
-			{
-				Type:  aws.String("TERM_MATCH"),
-				Field: aws.String("servicecode"),
-				Value: aws.String("AmazonDynamoDB"),
-			},
-			{
-				Type:  aws.String("TERM_MATCH"),
-				Field: aws.String("location"),
-				Value: aws.String(location),
-			},
-			{
-				Type:  aws.String("TERM_MATCH"),
-				Field: aws.String("productFamily"),
-				Value: aws.String("Provisioned IOPS"),
-			},
-			{
-				Type:  aws.String("TERM_MATCH"),
-				Field: aws.String("group"),
-				Value: aws.String("DDB-WriteUnits"),
-			},
-		}
-
-		writePrice, err := ce.getPriceFromAPI(writeFilters)
-		if err != nil {
-			return 0, fmt.Errorf("failed to get DynamoDB write capacity price: %w", err)
+		ce.cacheLock.Lock()
+		ce.priceCache[cacheKey] = price
+		ce.cacheLock.Unlock()
+		return price, nil
+	case "DynamoDBReadCapacity", "DynamoDBWriteCapacity":
+		// Provisioned read and write capacity units are billed per unit-hour
+		group := "DDB-ReadUnits"
+		if resourceType == "DynamoDBWriteCapacity" {
+			group = "DDB-WriteUnits"
 		}
-
-		// Get read capacity price
-		readFilters := []*pricing.Filter{
+		filters = []*pricing.Filter{
 			{
 				Type:  aws.String("TERM_MATCH"),
 				Field: aws.String("servicecode"),
@@ -569,33 +646,23 @@ func (ce *CostEstimator) getAWSPrice(resourceType, region string, config Resourc
 			{
 				Type:  aws.String("TERM_MATCH"),
 				Field: aws.String("group"),
-				Value: aws.String("DDB-ReadUnits"),
+				Value: aws.String(group),
 			},
 		}
 
-		readPrice, err := ce.getPriceFromAPI(readFilters)
+		price, err := ce.getPriceFromAPI(filters)
 		if err != nil {
-			return 0, fmt.Errorf("failed to get DynamoDB read capacity price: %w", err)
+			return 0, fmt.Errorf("failed to get DynamoDB capacity price: %w", err)
 		}
 
-		// Calculate total cost based on table size and provisioned capacity
-		tableSizeGB := float64(config.ResourceSize.(int64)) / (1024 * 1024 * 1024)       // Convert bytes to GB
-		totalCost := (storagePrice * tableSizeGB) + (writePrice * 25) + (readPrice * 25) // Assume minimum 25 WCU and RCU
-
 		ce.cacheLock.Lock()
-		ce.priceCache[cacheKey] = totalCost
+		ce.priceCache[cacheKey] = price
 		ce.cacheLock.Unlock()
-
-		return totalCost, nil
-	case "OpenSearch":
-		// For OpenSearch we need to calculate both instance and storage costs
-		// First get instance price
-		instanceType, ok := config.ResourceSize.(string)
-		if !ok {
-			return 0, fmt.Errorf("invalid resource size type for OpenSearch: %T", config.ResourceSize)
-		}
-
-		instanceFilters := []*pricing.Filter{
+		return price, nil
+	case "OpenSearch", "OpenSearchStorage":
+		// OpenSearch is billed per instance-hour of the data nodes and per GB-month of their
+		// EBS storage, priced separately as domains differ in instance count and storage size
+		filters := []*pricing.Filter{
 			{
 				Type:  aws.String("TERM_MATCH"),
 				Field: aws.String("servicecode"),
@@ -606,64 +673,41 @@ func (ce *CostEstimator) getAWSPrice(resourceType, region string, config Resourc
 				Field: aws.String("location"),
 				Value: aws.String(location),
 			},
-			{
+		}
+		if resourceType == "OpenSearch" {
+			instanceType, ok := config.ResourceSize.(string)
+			if !ok {
+				return 0, fmt.Errorf("invalid resource size type for OpenSearch: %T", config.ResourceSize)
+			}
+			filters = append(filters, &pricing.Filter{
 				Type:  aws.String("TERM_MATCH"),
 				Field: aws.String("instanceType"),
 				Value: aws.String(instanceType),
-			},
-			{
-				Type:  aws.String("TERM_MATCH"),
-				Field: aws.String("usagetype"),
-				Value: aws.String(fmt.Sprintf("%s-BoxUsage:%s", strings.ToUpper(strings.Split(region, "-")[0]), instanceType)),
-			},
-		}
-
-		// Get instance price per hour
-		instancePrice, err := ce.getPriceFromAPI(instanceFilters)
-		if err != nil {
-			return 0, fmt.Errorf("failed to get OpenSearch instance price: %w", err)
-		}
-
-		// Get storage price
-		storageFilters := []*pricing.Filter{
-			{
-				Type:  aws.String("TERM_MATCH"),
-				Field: aws.String("servicecode"),
-				Value: aws.String("AmazonES"),
-			},
-			{
-				Type:  aws.String("TERM_MATCH"),
-				Field: aws.String("location"),
-				Value: aws.String(location),
-			},
-			{
-				Type:  aws.String("TERM_MATCH"),
-				Field: aws.String("volumeType"),
-				Value: aws.String(config.VolumeType),
-			},
-			{
-				Type:  aws.String("TERM_MATCH"),
-				Field: aws.String("productFamily"),
-				Value: aws.String("Storage"),
-			},
+			})
+		} else {
+			filters = append(filters,
+				&pricing.Filter{
+					Type:  aws.String("TERM_MATCH"),
+					Field: aws.String("volumeType"),
+					Value: aws.String(config.VolumeType),
+				},
+				&pricing.Filter{
+					Type:  aws.String("TERM_MATCH"),
+					Field: aws.String("productFamily"),
+					Value: aws.String("Storage"),
+				},
+			)
 		}
 
-		// Get storage price per GB per month
-		storagePrice, err := ce.getPriceFromAPI(storageFilters)
+		price, err := ce.getPriceFromAPI(filters)
 		if err != nil {
-			return 0, fmt.Errorf("failed to get OpenSearch storage price: %w", err)
+			return 0, fmt.Errorf("failed to get %s price: %w", resourceType, err)
 		}
 
-		// Calculate total cost
-		// Storage price is per GB per month, convert to per hour
-		storagePricePerHour := (storagePrice * float64(config.StorageSize)) / (24 * 30) // Approximate month to 30 days
-		totalCost := (instancePrice * float64(config.InstanceCount)) + storagePricePerHour
-
 		ce.cacheLock.Lock()
-		ce.priceCache[cacheKey] = totalCost
+		ce.priceCache[cacheKey] = price
 		ce.cacheLock.Unlock()
-
-		return totalCost, nil
+		return price, nil
 	case "RDS":
 		// Extract instance class from resource size string
 		instanceClass, ok := config.ResourceSize.(string)
@@ -813,6 +857,64 @@ func (ce *CostEstimator) getAWSPrice(resourceType, region string, config Resourc
 		// Elastic IPs have a flat rate of $0.005 per hour when not attached
 		hourlyRate := roundCost(0.005) // $0.005 per hour
 		return hourlyRate, nil
+	case "EMR":
+		// EMR bills a per-instance-hour charge on top of the EC2 price of each cluster instance
+		instanceType, ok := config.ResourceSize.(string)
+		if !ok {
+			return 0, fmt.Errorf("invalid resource size type for EMR: %T", config.ResourceSize)
+		}
+		filters := []*pricing.Filter{
+			{
+				Type:  aws.String("TERM_MATCH"),
+				Field: aws.String("servicecode"),
+				Value: aws.String("ElasticMapReduce"),
+			},
+			{
+				Type:  aws.String("TERM_MATCH"),
+				Field: aws.String("location"),
+				Value: aws.String(location),
+			},
+			{
+				Type:  aws.String("TERM_MATCH"),
+				Field: aws.String("instanceType"),
+				Value: aws.String(instanceType),
+			},
+			{
+				Type:  aws.String("TERM_MATCH"),
+				Field: aws.String("softwareType"),
+				Value: aws.String("EMR"),
+			},
+		}
+
+		// Get the EMR charge of the instance type
+		price, err := ce.getPriceFromAPI(filters)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get EMR price: %w", err)
+		}
+
+		ce.cacheLock.Lock()
+		ce.priceCache[cacheKey] = price
+		ce.cacheLock.Unlock()
+		return price, nil
+	case "GlueDPU":
+		// Glue ETL jobs and dev endpoints are billed $0.44 per DPU-hour in most regions
+		return 0.44, nil
+	case "Lightsail":
+		// Lightsail bundles have a fixed monthly price, which the Lightsail API returns
+		// with the bundle, so the ResourceSize holds the monthly price in USD
+		monthlyPrice, ok := config.ResourceSize.(float64)
+		if !ok {
+			return 0, fmt.Errorf("invalid resource size type for Lightsail: %T", config.ResourceSize)
+		}
+		return monthlyPrice, nil
+	case "CloudWatch":
+		// CloudWatch monitoring features are billed per rule, alarm or metric update at flat
+		// list prices, so the scanner computes the monthly price in USD as the ResourceSize
+		monthlyPrice, ok := config.ResourceSize.(float64)
+		if !ok {
+			return 0, fmt.Errorf("invalid resource size type for CloudWatch: %T", config.ResourceSize)
+		}
+		return monthlyPrice, nil
 	case "NATGateway":
 		// NAT Gateways have a flat hourly rate based on region
 		// Pricing varies by region, but we'll use a standard rate as fallback
@@ -851,10 +953,61 @@ func (ce *CostEstimator) getAWSPrice(resourceType, region string, config Resourc
 		}
 
 		return hourlyRate, nil
+	case "S3Storage":
+		// S3 storage is billed per GB-month by storage class. The VolumeType holds the S3
+		// storage class (e.g. STANDARD) of the stored data.
+		volumeType, ok := s3StorageVolumeTypes[config.VolumeType]
+		if !ok {
+			volumeType = s3StorageVolumeTypes["STANDARD"]
+		}
+		filters := []*pricing.Filter{
+			{
+				Type:  aws.String("TERM_MATCH"),
+				Field: aws.String("servicecode"),
+				Value: aws.String("AmazonS3"),
+			},
+			{
+				Type:  aws.String("TERM_MATCH"),
+				Field: aws.String("location"),
+				Value: aws.String(location),
+			},
+			{
+				Type:  aws.String("TERM_MATCH"),
+				Field: aws.String("productFamily"),
+				Value: aws.String("Storage"),
+			},
+			{
+				Type:  aws.String("TERM_MATCH"),
+				Field: aws.String("volumeType"),
+				Value: aws.String(volumeType),
+			},
+		}
+
+		// Get the GB-month price of the storage class
+		price, err := ce.getPriceFromAPI(filters)
+		if err != nil {
+			logging.Error("Failed to get S3 storage price, using default", err, map[string]interface{}{
+				"region":  region,
+				"filters": filters,
+			})
+			// Default S3 Standard price if pricing API fails
+			return 0.023, nil
+		}
+
+		ce.cacheLock.Lock()
+		ce.priceCache[cacheKey] = price
+		ce.cacheLock.Unlock()
+		return price, nil
 	default:
 		cacheKey = fmt.Sprintf("%s:%s", resourceType, region)
 	}
 
+	// Resources in Local Zones and Wavelength Zones are priced by their zone rather than the
+	// location of the parent region
+	if config.PricingRegionCode != "" {
+		filters = zonePriceFilters(filters, config.PricingRegionCode)
+	}
+
 	input := &pricing.GetProductsInput{
 		ServiceCode: aws.String("AmazonEC2"),
 		Filters:     filters,
@@ -1077,8 +1230,243 @@ func roundCost(cost float64) float64 {
 	return math.Round(cost*10000) / 10000
 }
 
-// CalculateCost calculates the cost for a given resource
+// EBS gp3 volumes include a baseline of IOPS and throughput at no additional cost
+const (
+	gp3BaselineIOPS       = 3000
+	gp3BaselineThroughput = 125 // MiB/s
+)
+
+// billableEBSPerformance returns the provisioned IOPS and throughput of an EBS volume
+// that are billed on top of storage. io1 and io2 bill every provisioned IOPS, while gp3
+// only bills IOPS and throughput above its free baseline.
+func billableEBSPerformance(volumeType string, iops, throughput int64) (int64, int64) {
+	switch volumeType {
+	case "io1", "io2":
+		return iops, 0
+	case "gp3":
+		return max(iops-gp3BaselineIOPS, 0), max(throughput-gp3BaselineThroughput, 0)
+	default:
+		return 0, 0
+	}
+}
+
+// io2 volumes bill the provisioned IOPS above 32,000 and above 64,000 at lower prices
+var io2IOPSTiers = []struct {
+	resourceType string
+	upTo         int64 // Highest IOPS billed at the price of the tier, 0 for no limit
+}{
+	{"EBSIOPS", 32000},
+	{"EBSIOPSTier2", 64000},
+	{"EBSIOPSTier3", 0},
+}
+
+// ebsIOPSTier is a number of provisioned IOPS billed at the price of one resource type
+type ebsIOPSTier struct {
+	resourceType string
+	iops         int64
+}
+
+// billableIOPSTiers splits the billable IOPS of an EBS volume into the tiers they are priced
+// by. Only io2 has tiers; other volume types bill all IOPS at one price.
+func billableIOPSTiers(volumeType string, iops int64) []ebsIOPSTier {
+	if volumeType != "io2" {
+		return []ebsIOPSTier{{resourceType: "EBSIOPS", iops: iops}}
+	}
+	var tiers []ebsIOPSTier
+	var billed int64
+	for _, tier := range io2IOPSTiers {
+		tierIOPS := iops - billed
+		if tier.upTo > 0 {
+			tierIOPS = min(tierIOPS, tier.upTo-billed)
+		}
+		if tierIOPS <= 0 {
+			break
+		}
+		tiers = append(tiers, ebsIOPSTier{resourceType: tier.resourceType, iops: tierIOPS})
+		billed += tierIOPS
+	}
+	return tiers
+}
+
+// provisionedPerformanceCost returns the monthly cost of provisioned IOPS and throughput
+// for an EBS volume. Components whose price cannot be determined are left out so that
+// the storage cost is still reported.
+func (ce *CostEstimator) provisionedPerformanceCost(config ResourceCostConfig) float64 {
+	iops, throughput := billableEBSPerformance(config.VolumeType, config.IOPS, config.Throughput)
+
+	var monthlyCost float64
+	if iops > 0 {
+		for _, tier := range billableIOPSTiers(config.VolumeType, iops) {
+			price, err := ce.getAWSPrice(tier.resourceType, config.Region, config)
+			if err != nil {
+				logging.Warn("Failed to get EBS IOPS price, excluding IOPS from cost", map[string]interface{}{
+					"region":      config.Region,
+					"volume_type": config.VolumeType,
+					"tier":        tier.resourceType,
+					"error":       err.Error(),
+				})
+				continue
+			}
+			monthlyCost += float64(tier.iops) * price // Price per IOPS-month
+		}
+	}
+	if throughput > 0 {
+		price, err := ce.getAWSPrice("EBSThroughput", config.Region, config)
+		if err != nil {
+			logging.Warn("Failed to get EBS throughput price, excluding throughput from cost", map[string]interface{}{
+				"region":      config.Region,
+				"volume_type": config.VolumeType,
+				"error":       err.Error(),
+			})
+		} else {
+			monthlyCost += float64(throughput) * price // Price per MiB/s-month
+		}
+	}
+	return monthlyCost
+}
+
+// provisionedCapacityCost returns the monthly cost of the provisioned read and write capacity
+// units of a DynamoDB table. Capacity without a price is excluded from the cost.
+func (ce *CostEstimator) provisionedCapacityCost(config ResourceCostConfig) float64 {
+	var monthlyCost float64
+	for _, capacity := range []struct {
+		resourceType string
+		units        int64
+	}{
+		{"DynamoDBReadCapacity", config.ReadCapacityUnits},
+		{"DynamoDBWriteCapacity", config.WriteCapacityUnits},
+	} {
+		if capacity.units <= 0 {
+			continue
+		}
+		price, err := ce.getAWSPrice(capacity.resourceType, config.Region, config)
+		if err != nil {
+			logging.Warn("Failed to get DynamoDB capacity price, excluding it from cost", map[string]interface{}{
+				"region":        config.Region,
+				"resource_type": capacity.resourceType,
+				"error":         err.Error(),
+			})
+			continue
+		}
+		monthlyCost += float64(capacity.units) * price * 730 // Price per unit-hour
+	}
+	return monthlyCost
+}
+
+// openSearchStorageCost returns the monthly cost of the EBS storage of the data nodes of an
+// OpenSearch domain. Storage without a price is excluded from the cost.
+func (ce *CostEstimator) openSearchStorageCost(config ResourceCostConfig) float64 {
+	if config.StorageSize <= 0 {
+		return 0
+	}
+	price, err := ce.getAWSPrice("OpenSearchStorage", config.Region, config)
+	if err != nil {
+		logging.Warn("Failed to get OpenSearch storage price, excluding storage from cost", map[string]interface{}{
+			"region":      config.Region,
+			"volume_type": config.VolumeType,
+			"error":       err.Error(),
+		})
+		return 0
+	}
+	return float64(config.StorageSize) * price // Price per GB-month
+}
+
+// s3StorageVolumeTypes maps S3 storage classes to the volumeType attribute of the Pricing API
+var s3StorageVolumeTypes = map[string]string{
+	"STANDARD":            "Standard",
+	"REDUCED_REDUNDANCY":  "Reduced Redundancy",
+	"STANDARD_IA":         "Standard - Infrequent Access",
+	"ONEZONE_IA":          "One Zone - Infrequent Access",
+	"INTELLIGENT_TIERING": "Intelligent-Tiering Frequent Access",
+	"GLACIER_IR":          "Glacier Instant Retrieval",
+	"GLACIER":             "Amazon Glacier",
+	"DEEP_ARCHIVE":        "Glacier Deep Archive",
+}
+
+// resourceServices maps resource types to the service they are billed under, so cost
+// multipliers can be configured per service as well as per resource type
+var resourceServices = map[string]string{
+	"EC2":                   "ec2",
+	"EBSVolumes":            "ec2",
+	"EBSSnapshots":          "ec2",
+	"ElasticIP":             "ec2",
+	"NATGateway":            "ec2",
+	"elb":                   "elb",
+	"DynamoDB":              "dynamodb",
+	"DynamoDBReadCapacity":  "dynamodb",
+	"DynamoDBWriteCapacity": "dynamodb",
+	"OpenSearch":            "opensearch",
+	"OpenSearchStorage":     "opensearch",
+	"RDS":                   "rds",
+	"S3Storage":             "s3",
+	"Lightsail":             "lightsail",
+	"CloudWatch":            "cloudwatch",
+	"GlueDPU":               "glue",
+	"EMR":                   "emr",
+}
+
+// SetCostMultipliers sets the multipliers applied to list prices, e.g. to reflect negotiated
+// discounts. Keys are resource types (e.g. "EBSVolumes"), services (e.g. "ec2") or "default",
+// matched case-insensitively in that order of precedence.
+func (ce *CostEstimator) SetCostMultipliers(multipliers map[string]float64) error {
+	normalized := make(map[string]float64, len(multipliers))
+	for key, multiplier := range multipliers {
+		if multiplier <= 0 {
+			return fmt.Errorf("invalid cost multiplier for %s: %v", key, multiplier)
+		}
+		normalized[strings.ToLower(key)] = multiplier
+	}
+
+	ce.multipliersLock.Lock()
+	ce.costMultipliers = normalized
+	ce.multipliersLock.Unlock()
+
+	if len(normalized) > 0 {
+		logging.Info("Using cost multipliers", map[string]interface{}{
+			"multipliers": normalized,
+		})
+	}
+	return nil
+}
+
+// costMultiplier returns the multiplier applied to the list price of a resource type
+func (ce *CostEstimator) costMultiplier(resourceType string) float64 {
+	ce.multipliersLock.RLock()
+	defer ce.multipliersLock.RUnlock()
+
+	for _, key := range []string{strings.ToLower(resourceType), resourceServices[resourceType], "default"} {
+		if multiplier, ok := ce.costMultipliers[key]; ok {
+			return multiplier
+		}
+	}
+	return 1
+}
+
+// CalculateCost calculates the cost for a given resource, applying any configured cost multiplier
 func (ce *CostEstimator) CalculateCost(config ResourceCostConfig) (*CostBreakdown, error) {
+	costs, err := ce.calculateListCost(config)
+	if err != nil {
+		return nil, err
+	}
+
+	multiplier := ce.costMultiplier(config.ResourceType)
+	if multiplier == 1 {
+		return costs, nil
+	}
+
+	costs.HourlyRate = roundCost(costs.HourlyRate * multiplier)
+	costs.DailyRate = roundCost(costs.DailyRate * multiplier)
+	costs.MonthlyRate = roundCost(costs.MonthlyRate * multiplier)
+	costs.YearlyRate = roundCost(costs.YearlyRate * multiplier)
+	if costs.Lifetime != nil {
+		lifetime := *costs.Lifetime * multiplier
+		costs.Lifetime = &lifetime
+	}
+	return costs, nil
+}
+
+// calculateListCost calculates the cost for a given resource at AWS list prices
+func (ce *CostEstimator) calculateListCost(config ResourceCostConfig) (*CostBreakdown, error) {
 	logging.Debug("Calculating cost", map[string]interface{}{
 		"resource_type": config.ResourceType,
 		"region":        config.Region,
@@ -1097,8 +1485,8 @@ func (ce *CostEstimator) CalculateCost(config ResourceCostConfig) (*CostBreakdow
 	// Calculate base price based on resource type
 	var hourlyPrice float64
 	switch config.ResourceType {
-	case "EC2":
-		// For EC2, price is already per hour
+	case "EC2", "EMR":
+		// For EC2 and EMR, price is already per hour
 		hourlyPrice = pricePerUnit
 	case "EBSVolumes", "EBSSnapshots":
 		// For storage resources, we only care about size and rates
@@ -1107,11 +1495,33 @@ func (ce *CostEstimator) CalculateCost(config ResourceCostConfig) (*CostBreakdow
 			return nil, fmt.Errorf("invalid resource size type for cost calculation: %T", config.ResourceSize)
 		}
 		monthlyPrice := float64(size) * pricePerUnit // Price per GB-month
-		hourlyPrice = monthlyPrice / 730             // Convert to hourly (730 hours in a month)
+		if config.ResourceType == "EBSVolumes" {
+			monthlyPrice += ce.provisionedPerformanceCost(config)
+		}
+		hourlyPrice = monthlyPrice / 730 // Convert to hourly (730 hours in a month)
 		dailyPrice := hourlyPrice * 24
 		monthlyPrice = dailyPrice * 30 // Approximate
 		yearlyPrice := dailyPrice * 365
 
+		return &CostBreakdown{
+			HourlyRate:   roundCost(hourlyPrice),
+			DailyRate:    roundCost(dailyPrice),
+			MonthlyRate:  roundCost(monthlyPrice),
+			YearlyRate:   roundCost(yearlyPrice),
+			HoursRunning: nil,
+			Lifetime:     nil,
+		}, nil
+	case "S3Storage":
+		// For S3, the size is the stored data in GB, which may be fractional
+		size, ok := config.ResourceSize.(float64)
+		if !ok {
+			return nil, fmt.Errorf("invalid resource size type for cost calculation: %T", config.ResourceSize)
+		}
+		hourlyPrice = size * pricePerUnit / 730 // Price per GB-month, 730 hours in a month
+		dailyPrice := hourlyPrice * 24
+		monthlyPrice := dailyPrice * 30 // Approximate
+		yearlyPrice := dailyPrice * 365
+
 		return &CostBreakdown{
 			HourlyRate:   roundCost(hourlyPrice),
 			DailyRate:    roundCost(dailyPrice),
@@ -1153,10 +1563,16 @@ func (ce *CostEstimator) CalculateCost(config ResourceCostConfig) (*CostBreakdow
 			Lifetime:     nil, // Lifetime will be calculated by the application
 		}, nil
 	case "DynamoDB":
-		// For DynamoDB, price is already per hour
-		hourlyPrice = pricePerUnit
+		// Storage is priced per GB-month, sized in bytes
+		size, ok := config.ResourceSize.(int64)
+		if !ok {
+			return nil, fmt.Errorf("invalid resource size type for cost calculation: %T", config.ResourceSize)
+		}
+		monthlyPrice := float64(size) / (1024 * 1024 * 1024) * pricePerUnit
+		monthlyPrice += ce.provisionedCapacityCost(config)
+		hourlyPrice = monthlyPrice / 730 // Convert to hourly (730 hours in a month)
 		dailyPrice := hourlyPrice * 24
-		monthlyPrice := dailyPrice * 30 // Approximate
+		monthlyPrice = dailyPrice * 30 // Approximate
 		yearlyPrice := dailyPrice * 365
 
 		return &CostBreakdown{
@@ -1168,8 +1584,12 @@ func (ce *CostEstimator) CalculateCost(config ResourceCostConfig) (*CostBreakdow
 			Lifetime:     nil, // Lifetime will be calculated by the application
 		}, nil
 	case "OpenSearch":
-		// For OpenSearch, price is already per hour
-		hourlyPrice = pricePerUnit
+		// Price is per instance-hour, and the storage of all data nodes is priced per GB-month
+		instanceCount := config.InstanceCount
+		if instanceCount <= 0 {
+			instanceCount = 1
+		}
+		hourlyPrice = pricePerUnit*float64(instanceCount) + ce.openSearchStorageCost(config)/730
 		dailyPrice := hourlyPrice * 24
 		monthlyPrice := dailyPrice * 30 // Approximate
 		yearlyPrice := dailyPrice * 365
@@ -1208,6 +1628,16 @@ func (ce *CostEstimator) CalculateCost(config ResourceCostConfig) (*CostBreakdow
 			HoursRunning: &hours,
 			Lifetime:     &lifetime,
 		}, nil
+	case "Lightsail", "CloudWatch":
+		// Lightsail bundles and CloudWatch monitoring features are priced per month
+		hourlyPrice = pricePerUnit / 730 // 730 hours in a month
+	case "GlueDPU":
+		// For Glue, the size is the number of DPUs, which may be fractional
+		dpus, ok := config.ResourceSize.(float64)
+		if !ok {
+			return nil, fmt.Errorf("invalid resource size type for cost calculation: %T", config.ResourceSize)
+		}
+		hourlyPrice = dpus * pricePerUnit
 	case "NATGateway":
 		// For NAT Gateway, price is already per hour
 		hourlyPrice = pricePerUnit
@@ -0,0 +1,131 @@
+package aws
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+
+	"cloudsift/internal/config"
+	"cloudsift/internal/logging"
+)
+
+// ErrCodeFIPSEndpointUnavailable is the error code of requests refused because their service
+// has no FIPS endpoint in their region
+const ErrCodeFIPSEndpointUnavailable = "FIPSEndpointUnavailable"
+
+// FIPSRegionServices are the services every scan calls in each scanned region, validated
+// before scanning with FIPS endpoints. STS is only called in the STS region.
+var FIPSRegionServices = []string{"ec2"}
+
+// FIPSEndpointSupported returns true if AWS publishes a FIPS endpoint of a service in a
+// region. Global services count if their partition endpoint has a FIPS variant, and GovCloud
+// services whose standard endpoint is FIPS validated count as well.
+func FIPSEndpointSupported(service, region string) bool {
+	resolver := endpoints.DefaultResolver()
+	fips := func(o *endpoints.Options) {
+		o.UseFIPSEndpoint = endpoints.FIPSEndpointStateEnabled
+		o.StrictMatching = true
+	}
+	if _, err := resolver.EndpointFor(service, region, fips); err == nil {
+		return true
+	}
+
+	// Global services such as IAM only have an endpoint for the whole partition
+	if partition, ok := endpoints.PartitionForRegion(endpoints.DefaultPartitions(), region); ok {
+		if _, err := resolver.EndpointFor(service, partition.ID()+"-global", fips); err == nil {
+			return true
+		}
+	}
+
+	// Without strict matching, services whose standard endpoint is the FIPS endpoint resolve
+	// both to the same URL, while others resolve to a FIPS hostname that may not exist
+	fipsEndpoint, err := resolver.EndpointFor(service, region, func(o *endpoints.Options) {
+		o.UseFIPSEndpoint = endpoints.FIPSEndpointStateEnabled
+	})
+	if err != nil {
+		return false
+	}
+	standardEndpoint, err := resolver.EndpointFor(service, region, endpoints.StrictMatchingOption)
+	return err == nil && fipsEndpoint.URL == standardEndpoint.URL
+}
+
+// ValidateFIPSEndpoints returns an error listing the combinations of services and regions
+// without FIPS endpoints
+func ValidateFIPSEndpoints(services, regions []string) error {
+	var unsupported []string
+	for _, region := range regions {
+		for _, service := range services {
+			if !FIPSEndpointSupported(service, region) {
+				unsupported = append(unsupported, fmt.Sprintf("%s in %s", service, region))
+			}
+		}
+	}
+	if len(unsupported) > 0 {
+		sort.Strings(unsupported)
+		return fmt.Errorf("no FIPS endpoints for %s; FIPS endpoints are only available in US, Canada and GovCloud regions", strings.Join(unsupported, ", "))
+	}
+	return nil
+}
+
+// FIPSRegions splits regions into those with FIPS endpoints of the services called in each
+// scanned region and those without
+func FIPSRegions(regions []string) (supported, unsupported []string) {
+	for _, region := range regions {
+		if ValidateFIPSEndpoints(FIPSRegionServices, []string{region}) == nil {
+			supported = append(supported, region)
+		} else {
+			unsupported = append(unsupported, region)
+		}
+	}
+	return supported, unsupported
+}
+
+// fipsFallbackWarnings records the services already warned about falling back to their
+// standard endpoint
+var fipsFallbackWarnings sync.Map
+
+// OptionalFIPSConfig returns the client config of a service in a region that is used even
+// without a FIPS endpoint, such as the Pricing API. With FIPS endpoints enabled, the client
+// uses the FIPS endpoint where there is one and otherwise the standard endpoint.
+func OptionalFIPSConfig(service, region string) *aws.Config {
+	cfg := aws.NewConfig().WithRegion(region)
+	if !config.Config.UseFIPSEndpoints || FIPSEndpointSupported(service, region) {
+		return cfg
+	}
+	if _, warned := fipsFallbackWarnings.LoadOrStore(service+"/"+region, true); !warned {
+		logging.Warn("No FIPS endpoint available, using the standard endpoint", map[string]interface{}{
+			"service": service,
+			"region":  region,
+		})
+	}
+	return cfg.WithUseFIPSEndpoint(false)
+}
+
+// attachFIPSValidation refuses requests of a session to services without a FIPS endpoint in
+// their region when FIPS endpoints are enabled, rather than sending them to a standard
+// endpoint or to a FIPS hostname that does not exist
+func attachFIPSValidation(sess *session.Session) {
+	if !config.Config.UseFIPSEndpoints {
+		return
+	}
+	sess.Handlers.Validate.PushBackNamed(request.NamedHandler{
+		Name: "cloudsift.FIPSEndpointValidation",
+		Fn: func(r *request.Request) {
+			if r.Config.UseFIPSEndpoint != endpoints.FIPSEndpointStateEnabled {
+				return
+			}
+			region := aws.StringValue(r.Config.Region)
+			if !FIPSEndpointSupported(r.ClientInfo.ServiceName, region) {
+				r.Error = awserr.New(ErrCodeFIPSEndpointUnavailable,
+					fmt.Sprintf("%s has no FIPS endpoint in %s", r.ClientInfo.ServiceName, region), nil)
+			}
+		},
+	})
+}
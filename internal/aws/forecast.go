@@ -0,0 +1,53 @@
+package aws
+
+// ForecastHorizons are the numbers of months the waste of current findings is projected over
+var ForecastHorizons = []int{3, 6, 12}
+
+// WasteProjection is the cumulative waste of the current findings over a number of months
+type WasteProjection struct {
+	Months int     `json:"months"`
+	Waste  float64 `json:"waste"`
+}
+
+// WasteForecast projects the cumulative cost of the current findings if they are not remediated
+type WasteForecast struct {
+	MonthlyWaste  float64           `json:"monthly_waste"`  // Monthly cost of the current findings
+	MonthlyGrowth float64           `json:"monthly_growth"` // Monthly growth rate of the waste, 0 for a flat projection
+	Projections   []WasteProjection `json:"projections"`    // Cumulative waste for each of ForecastHorizons
+}
+
+// ForecastWaste projects a monthly waste over ForecastHorizons. Without growth the projection
+// is the monthly rate times the months; with growth the waste of each month compounds by the
+// monthly growth rate, which is bounded below by -1 as waste cannot turn negative.
+func ForecastWaste(monthlyWaste, monthlyGrowth float64) WasteForecast {
+	if monthlyGrowth < -1 {
+		monthlyGrowth = -1
+	}
+	forecast := WasteForecast{
+		MonthlyWaste:  monthlyWaste,
+		MonthlyGrowth: monthlyGrowth,
+		Projections:   make([]WasteProjection, 0, len(ForecastHorizons)),
+	}
+
+	cumulative, month, waste := 0.0, 0, monthlyWaste
+	for _, horizon := range ForecastHorizons {
+		for ; month < horizon; month++ {
+			cumulative += waste
+			waste *= 1 + monthlyGrowth
+		}
+		forecast.Projections = append(forecast.Projections, WasteProjection{Months: horizon, Waste: cumulative})
+	}
+	return forecast
+}
+
+// TotalMonthlyWaste returns the monthly cost of scan results, leaving out low confidence results
+// like the savings totals
+func TotalMonthlyWaste(results []ScanResult) float64 {
+	total := 0.0
+	for _, result := range results {
+		if costs := result.SavingsBreakdown(); costs != nil {
+			total += costs.MonthlyRate
+		}
+	}
+	return total
+}
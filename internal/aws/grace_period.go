@@ -0,0 +1,39 @@
+package aws
+
+import "time"
+
+// creationTimeDetails are the details scanners report the creation time of a resource in. The
+// launch time of an instance is not one of them: it is reset whenever a stopped instance is
+// started, so instances report the attach time of their root volume as created_at instead.
+var creationTimeDetails = []string{"created_at", "creation_date", "creation_time", "created_time", "created"}
+
+// CreationTime returns the creation time a scanner reported for a resource, if any
+func CreationTime(result ScanResult) (time.Time, bool) {
+	for _, key := range creationTimeDetails {
+		switch v := result.Details[key].(type) {
+		case time.Time:
+			if !v.IsZero() {
+				return v, true
+			}
+		case *time.Time:
+			if v != nil && !v.IsZero() {
+				return *v, true
+			}
+		case string:
+			if t, err := time.Parse(time.RFC3339, v); err == nil {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// WithinGracePeriod returns whether a resource was created less than days ago. Resources
+// without a creation time are never within the grace period.
+func WithinGracePeriod(result ScanResult, days int, now time.Time) bool {
+	if days <= 0 {
+		return false
+	}
+	created, ok := CreationTime(result)
+	return ok && now.Sub(created) < time.Duration(days)*24*time.Hour
+}
@@ -0,0 +1,132 @@
+package aws
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// DefaultRequestTimeout is the timeout of AWS API requests, slightly less than the worker
+// pool timeout
+const DefaultRequestTimeout = 25 * time.Second
+
+// HTTPClientOptions configures the HTTP client of all AWS sessions
+type HTTPClientOptions struct {
+	// HTTPSProxy is the URL of the proxy of all requests except those to hosts of NO_PROXY.
+	// When empty, the HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables are used.
+	HTTPSProxy string
+
+	// CABundle is a PEM file of certificates trusted in addition to the system roots, e.g.
+	// the certificate of a proxy intercepting TLS
+	CABundle string
+
+	// RequestTimeout is the timeout of each request (0 disables it)
+	RequestTimeout time.Duration
+
+	// BaseRequestTimeout is the timeout of each request of base sessions, created from a
+	// profile, SSO or an assumed role before they are bound to a region (0 disables it)
+	BaseRequestTimeout time.Duration
+}
+
+// metadataHosts are the instance metadata endpoints that serve credentials on EC2 and GCE,
+// which are never reached through a proxy
+const metadataHosts = "169.254.169.254,fd00:ec2::254,metadata.google.internal"
+
+// sessionHTTPClient is the HTTP client of regional sessions, if configured
+var sessionHTTPClient atomic.Pointer[http.Client]
+
+// baseHTTPClient is the HTTP client of base sessions, if configured
+var baseHTTPClient atomic.Pointer[http.Client]
+
+// NewHTTPClient creates an HTTP client using the proxy, CA bundle and timeout of the options
+func NewHTTPClient(opts HTTPClientOptions) (*http.Client, error) {
+	if opts.RequestTimeout < 0 {
+		return nil, fmt.Errorf("request timeout must not be negative")
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if opts.HTTPSProxy != "" {
+		proxyURL, err := url.Parse(opts.HTTPSProxy)
+		if err != nil || proxyURL.Scheme == "" || proxyURL.Host == "" {
+			return nil, fmt.Errorf("invalid HTTPS proxy %q: must be a URL such as http://proxy.example.com:3128", opts.HTTPSProxy)
+		}
+		noProxy := os.Getenv("NO_PROXY")
+		if noProxy == "" {
+			noProxy = os.Getenv("no_proxy")
+		}
+		proxyFunc := (&httpproxy.Config{
+			HTTPSProxy: proxyURL.String(),
+			HTTPProxy:  proxyURL.String(),
+			NoProxy:    strings.Trim(noProxy+","+metadataHosts, ","),
+		}).ProxyFunc()
+		transport.Proxy = func(req *http.Request) (*url.URL, error) {
+			return proxyFunc(req.URL)
+		}
+	}
+
+	if opts.CABundle != "" {
+		pem, err := os.ReadFile(opts.CABundle)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no PEM certificates found in CA bundle %s", opts.CABundle)
+		}
+		transport.TLSClientConfig = &tls.Config{
+			RootCAs:    pool,
+			MinVersion: tls.VersionTLS12,
+		}
+	}
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   opts.RequestTimeout,
+	}, nil
+}
+
+// ConfigureHTTPClient sets the HTTP clients used by all sessions created afterwards
+func ConfigureHTTPClient(opts HTTPClientOptions) error {
+	client, err := NewHTTPClient(opts)
+	if err != nil {
+		return err
+	}
+	if opts.BaseRequestTimeout < 0 {
+		return fmt.Errorf("request timeout must not be negative")
+	}
+	base := *client
+	base.Timeout = opts.BaseRequestTimeout
+	sessionHTTPClient.Store(client)
+	baseHTTPClient.Store(&base)
+	return nil
+}
+
+// HTTPClient returns the HTTP client of regional sessions, which uses the proxy of the
+// environment and the default request timeout unless ConfigureHTTPClient was called
+func HTTPClient() *http.Client {
+	if client := sessionHTTPClient.Load(); client != nil {
+		return client
+	}
+	return &http.Client{Timeout: DefaultRequestTimeout}
+}
+
+// BaseHTTPClient returns the HTTP client of base sessions. Unlike regional sessions, their
+// requests have no timeout unless one is configured, as role assumption and organization
+// calls made through them may legitimately take longer.
+func BaseHTTPClient() *http.Client {
+	if client := baseHTTPClient.Load(); client != nil {
+		return client
+	}
+	return &http.Client{}
+}
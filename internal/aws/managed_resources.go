@@ -0,0 +1,118 @@
+package aws
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"cloudsift/internal/config"
+)
+
+// DefaultManagedResourceRules match resources AWS creates and manages in every account, which
+// cannot or should not be cleaned up by their owners
+var DefaultManagedResourceRules = []config.ManagedResourceRule{
+	{
+		ResourceType: "IAM Roles",
+		ID:           "arn:*:iam::*:role/aws-service-role/*",
+		Description:  "Service-linked role managed by an AWS service",
+	},
+	{
+		ResourceType: "IAM Roles",
+		Name:         "AWSServiceRoleFor*",
+		Description:  "Service-linked role managed by an AWS service",
+	},
+	{
+		ResourceType: "IAM Roles",
+		ID:           "arn:*:iam::*:role/aws-reserved/*",
+		Description:  "Reserved role managed by AWS, such as IAM Identity Center permission set roles",
+	},
+	{
+		ResourceType: "VPCs",
+		Details:      map[string]string{"is_default": "true"},
+		Description:  "Default VPC created by AWS in each region",
+	},
+	{
+		ResourceType: "Security Groups",
+		Details:      map[string]string{"GroupName": "default"},
+		Description:  "Default security group created by AWS in each VPC",
+	},
+}
+
+// ManagedResourceMatcher matches scan results against managed resource rules
+type ManagedResourceMatcher struct {
+	rules    []config.ManagedResourceRule
+	patterns []compiledManagedResourceRule
+}
+
+// compiledManagedResourceRule holds the compiled patterns of a rule
+type compiledManagedResourceRule struct {
+	name    *regexp.Regexp
+	id      *regexp.Regexp
+	details map[string]*regexp.Regexp
+}
+
+// compileWildcard compiles a case-insensitive pattern where * matches any characters
+func compileWildcard(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	expr := strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*")
+	return regexp.Compile("(?i)^" + expr + "$")
+}
+
+// NewManagedResourceMatcher compiles the built-in rules and the given additional rules
+func NewManagedResourceMatcher(extra []config.ManagedResourceRule) (*ManagedResourceMatcher, error) {
+	matcher := &ManagedResourceMatcher{}
+	for i, rule := range append(append([]config.ManagedResourceRule{}, DefaultManagedResourceRules...), extra...) {
+		if rule.Name == "" && rule.ID == "" && len(rule.Details) == 0 {
+			return nil, fmt.Errorf("managed resource rule %d must set name, id or details", i+1)
+		}
+
+		var compiled compiledManagedResourceRule
+		var err error
+		if compiled.name, err = compileWildcard(rule.Name); err != nil {
+			return nil, fmt.Errorf("invalid name pattern %q: %w", rule.Name, err)
+		}
+		if compiled.id, err = compileWildcard(rule.ID); err != nil {
+			return nil, fmt.Errorf("invalid id pattern %q: %w", rule.ID, err)
+		}
+		compiled.details = make(map[string]*regexp.Regexp, len(rule.Details))
+		for key, pattern := range rule.Details {
+			if compiled.details[key], err = compileWildcard(pattern); err != nil {
+				return nil, fmt.Errorf("invalid %s pattern %q: %w", key, pattern, err)
+			}
+		}
+
+		matcher.rules = append(matcher.rules, rule)
+		matcher.patterns = append(matcher.patterns, compiled)
+	}
+	return matcher, nil
+}
+
+// Match returns the first rule matching a scan result
+func (m *ManagedResourceMatcher) Match(result ScanResult) (config.ManagedResourceRule, bool) {
+	for i, rule := range m.rules {
+		if rule.ResourceType != "" && !strings.EqualFold(rule.ResourceType, result.ResourceType) {
+			continue
+		}
+		patterns := m.patterns[i]
+		if patterns.name != nil && !patterns.name.MatchString(result.ResourceName) {
+			continue
+		}
+		if patterns.id != nil && !patterns.id.MatchString(result.ResourceID) {
+			continue
+		}
+		matched := true
+		for key, pattern := range patterns.details {
+			value, ok := result.Details[key]
+			if !ok || !pattern.MatchString(fmt.Sprint(value)) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return rule, true
+		}
+	}
+	return config.ManagedResourceRule{}, false
+}
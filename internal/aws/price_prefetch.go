@@ -0,0 +1,197 @@
+package aws
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/rds"
+)
+
+// scannerPriceInventories maps scanners to the inventory of the resources they price, so the
+// inventory pass only lists resources of the scanners that run
+var scannerPriceInventories = map[string]string{
+	"ec2-instances":         "ec2",
+	"marketplace-instances": "ec2",
+	"accelerated-instances": "ec2",
+	"ebs-volumes":           "ebs",
+	"rds":                   "rds",
+}
+
+// priceInventoryCollectors list the resources of an inventory in a region
+var priceInventoryCollectors = map[string]func(sess *session.Session, region string, inventory *PriceInventory) error{
+	"ec2": collectEC2Prices,
+	"ebs": collectEBSPrices,
+	"rds": collectRDSPrices,
+}
+
+// PriceInventory holds the distinct priced configurations of resources seen across accounts
+// and regions, keyed like the price cache so each price is resolved once
+type PriceInventory struct {
+	configs map[string]ResourceCostConfig
+	mu      sync.Mutex
+}
+
+// NewPriceInventory creates an empty PriceInventory
+func NewPriceInventory() *PriceInventory {
+	return &PriceInventory{
+		configs: make(map[string]ResourceCostConfig),
+	}
+}
+
+// priceCacheKey returns the price cache key of a resource type in a region, or in the Local
+// Zone or Wavelength Zone it is priced by
+func priceCacheKey(resourceType, region string, config ResourceCostConfig) string {
+	var resourceSizeStr string
+	if resourceType == "EBSVolumes" || resourceType == "EBSSnapshots" || resourceType == "EBSIOPS" || resourceType == "EBSIOPSTier2" || resourceType == "EBSIOPSTier3" || resourceType == "EBSThroughput" || resourceType == "S3Storage" || resourceType == "OpenSearchStorage" {
+		resourceSizeStr = config.VolumeType
+	} else if resourceType == "DynamoDB" || resourceType == "DynamoDBReadCapacity" || resourceType == "DynamoDBWriteCapacity" {
+		resourceSizeStr = "" // Priced per GB or unit regardless of the table
+	} else {
+		resourceSizeStr = fmt.Sprintf("%v", config.ResourceSize)
+	}
+	if config.PricingRegionCode != "" {
+		region = config.PricingRegionCode // Priced by the Local Zone or Wavelength Zone
+	}
+	return fmt.Sprintf("%s:%s:%s", resourceType, region, resourceSizeStr)
+}
+
+// Add records a priced configuration. Configurations sharing a price keep the highest
+// provisioned IOPS and throughput, so those prices are resolved as well.
+func (pi *PriceInventory) Add(config ResourceCostConfig) {
+	key := priceCacheKey(config.ResourceType, config.Region, config)
+
+	pi.mu.Lock()
+	defer pi.mu.Unlock()
+	if existing, ok := pi.configs[key]; ok {
+		if existing.IOPS > config.IOPS {
+			config.IOPS = existing.IOPS
+		}
+		if existing.Throughput > config.Throughput {
+			config.Throughput = existing.Throughput
+		}
+	}
+	pi.configs[key] = config
+}
+
+// Len returns the number of distinct priced configurations
+func (pi *PriceInventory) Len() int {
+	pi.mu.Lock()
+	defer pi.mu.Unlock()
+	return len(pi.configs)
+}
+
+// Configs returns the distinct priced configurations ordered by price cache key
+func (pi *PriceInventory) Configs() []ResourceCostConfig {
+	pi.mu.Lock()
+	defer pi.mu.Unlock()
+
+	keys := make([]string, 0, len(pi.configs))
+	for key := range pi.configs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	configs := make([]ResourceCostConfig, 0, len(keys))
+	for _, key := range keys {
+		configs = append(configs, pi.configs[key])
+	}
+	return configs
+}
+
+// HasPriceInventory reports whether a scanner prices resources found by the inventory pass
+func HasPriceInventory(scannerName string) bool {
+	_, ok := scannerPriceInventories[scannerName]
+	return ok
+}
+
+// CollectPriceInventory lists the resources of the given scanners in a region and adds their
+// priced configurations to the inventory. Only the attributes that determine prices are read.
+func CollectPriceInventory(sess *session.Session, region string, scannerNames []string, inventory *PriceInventory) error {
+	regionSession, err := GetSessionInRegion(sess, region)
+	if err != nil {
+		return fmt.Errorf("failed to create regional session: %w", err)
+	}
+
+	// Scanners sharing an inventory, such as the EC2 scanners, list resources once
+	collected := make(map[string]bool)
+	for _, name := range scannerNames {
+		inventoryName, ok := scannerPriceInventories[name]
+		if !ok || collected[inventoryName] {
+			continue
+		}
+		collected[inventoryName] = true
+
+		if err := priceInventoryCollectors[inventoryName](regionSession, region, inventory); err != nil {
+			return fmt.Errorf("failed to collect %s prices: %w", inventoryName, err)
+		}
+	}
+	return nil
+}
+
+// collectEC2Prices adds the instance types of running and stopped instances
+func collectEC2Prices(sess *session.Session, region string, inventory *PriceInventory) error {
+	input := &ec2.DescribeInstancesInput{
+		Filters: []*ec2.Filter{{
+			Name:   aws.String("instance-state-name"),
+			Values: aws.StringSlice([]string{ec2.InstanceStateNameRunning, ec2.InstanceStateNameStopped}),
+		}},
+		MaxResults: aws.Int64(1000),
+	}
+	return ec2.New(sess).DescribeInstancesPages(input, func(page *ec2.DescribeInstancesOutput, lastPage bool) bool {
+		for _, reservation := range page.Reservations {
+			for _, instance := range reservation.Instances {
+				inventory.Add(ResourceCostConfig{
+					ResourceType: "EC2",
+					ResourceSize: aws.StringValue(instance.InstanceType),
+					Region:       region,
+				})
+			}
+		}
+		return true
+	})
+}
+
+// collectEBSPrices adds the volume types and provisioned performance of volumes
+func collectEBSPrices(sess *session.Session, region string, inventory *PriceInventory) error {
+	return ec2.New(sess).DescribeVolumesPages(&ec2.DescribeVolumesInput{}, func(page *ec2.DescribeVolumesOutput, lastPage bool) bool {
+		for _, volume := range page.Volumes {
+			inventory.Add(ResourceCostConfig{
+				ResourceType: "EBSVolumes",
+				ResourceSize: aws.Int64Value(volume.Size),
+				Region:       region,
+				VolumeType:   aws.StringValue(volume.VolumeType),
+				IOPS:         aws.Int64Value(volume.Iops),
+				Throughput:   aws.Int64Value(volume.Throughput),
+			})
+		}
+		return true
+	})
+}
+
+// collectRDSPrices adds the instance classes of database instances
+func collectRDSPrices(sess *session.Session, region string, inventory *PriceInventory) error {
+	return rds.New(sess).DescribeDBInstancesPages(&rds.DescribeDBInstancesInput{}, func(page *rds.DescribeDBInstancesOutput, lastPage bool) bool {
+		for _, instance := range page.DBInstances {
+			inventory.Add(ResourceCostConfig{
+				ResourceType: "RDS",
+				ResourceSize: aws.StringValue(instance.DBInstanceClass),
+				Region:       region,
+				StorageSize:  aws.Int64Value(instance.AllocatedStorage),
+				VolumeType:   aws.StringValue(instance.StorageType),
+				Engine:       aws.StringValue(instance.Engine),
+			})
+		}
+		return true
+	})
+}
+
+// PrefetchPrice resolves and caches the list prices of a priced configuration, so scanners
+// pricing the same configuration hit the cache
+func (ce *CostEstimator) PrefetchPrice(config ResourceCostConfig) error {
+	_, err := ce.calculateListCost(config)
+	return err
+}
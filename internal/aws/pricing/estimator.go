@@ -46,10 +46,9 @@ func NewCostEstimator(sess *session.Session, cacheFile string) (*CostEstimator,
 		return nil, fmt.Errorf("failed to create price cache: %w", err)
 	}
 
-	// Create pricing client with explicit config to ensure region is us-east-1 (required for pricing API)
-	cfg := awssdk.NewConfig().WithRegion("us-east-1")
+	// Create pricing client in us-east-1 (required for pricing API), on a FIPS endpoint where available
 	ce := &CostEstimator{
-		pricingClient: pricing.New(sess, cfg),
+		pricingClient: pricing.New(sess, internalaws.OptionalFIPSConfig("api.pricing", "us-east-1")),
 		priceCache:    pc,
 		rateLimiter:   internalaws.NewRateLimiter(&config.DefaultRateLimitConfig),
 		calculators: map[string]interface{}{
@@ -0,0 +1,99 @@
+package aws
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/sts"
+)
+
+// mutatingActions are destructive actions on the resources CloudSift scans. Scan credentials
+// should not be allowed to perform any of them.
+var mutatingActions = []string{
+	"ec2:TerminateInstances",
+	"ec2:StopInstances",
+	"ec2:DeleteVolume",
+	"ec2:DeleteSnapshot",
+	"ec2:DeregisterImage",
+	"ec2:ReleaseAddress",
+	"ec2:DeleteNatGateway",
+	"ec2:DeleteSecurityGroup",
+	"ec2:DeleteVpc",
+	"elasticloadbalancing:DeleteLoadBalancer",
+	"rds:DeleteDBInstance",
+	"dynamodb:DeleteTable",
+	"es:DeleteDomain",
+	"iam:DeleteRole",
+	"iam:DeleteUser",
+}
+
+// ReadOnlyReport describes whether the credentials of a session can modify resources
+type ReadOnlyReport struct {
+	PrincipalARN          string   // IAM role or user the credentials belong to
+	AllowedActions        []string // Mutating actions the principal is allowed to perform
+	HasPermissionBoundary bool     // Whether the principal has a permissions boundary attached
+}
+
+// ReadOnly returns true if the principal is not allowed any mutating action
+func (r *ReadOnlyReport) ReadOnly() bool {
+	return len(r.AllowedActions) == 0
+}
+
+// VerifyReadOnly checks whether the credentials of a session are read-only by simulating
+// destructive actions against the principal's IAM policies. The principal needs
+// iam:SimulatePrincipalPolicy and iam:GetRole (or iam:GetUser), which ReadOnlyAccess grants.
+func VerifyReadOnly(sess *session.Session) (*ReadOnlyReport, error) {
+	// IAM is global, so pin a region in case the session has none
+	cfg := aws.NewConfig().WithRegion("us-east-1")
+
+	identity, err := sts.New(sess, STSConfig(sess)).GetCallerIdentity(&sts.GetCallerIdentityInput{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get caller identity: %w", err)
+	}
+
+	iamClient := iam.New(sess, cfg)
+	report := &ReadOnlyReport{}
+
+	// Resolve the IAM principal behind the caller identity. Assumed role ARNs do not include
+	// the role path, so look the role up to get its full ARN.
+	callerARN := aws.StringValue(identity.Arn)
+	switch {
+	case strings.Contains(callerARN, ":assumed-role/"):
+		roleName := strings.Split(strings.SplitN(callerARN, ":assumed-role/", 2)[1], "/")[0]
+		role, err := iamClient.GetRole(&iam.GetRoleInput{RoleName: aws.String(roleName)})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get role %s: %w", roleName, err)
+		}
+		report.PrincipalARN = aws.StringValue(role.Role.Arn)
+		report.HasPermissionBoundary = role.Role.PermissionsBoundary != nil
+	case strings.Contains(callerARN, ":user/"):
+		user, err := iamClient.GetUser(&iam.GetUserInput{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user: %w", err)
+		}
+		report.PrincipalARN = aws.StringValue(user.User.Arn)
+		report.HasPermissionBoundary = user.User.PermissionsBoundary != nil
+	default:
+		return nil, fmt.Errorf("cannot verify permissions of principal %s", callerARN)
+	}
+
+	err = iamClient.SimulatePrincipalPolicyPages(&iam.SimulatePrincipalPolicyInput{
+		PolicySourceArn: aws.String(report.PrincipalARN),
+		ActionNames:     aws.StringSlice(mutatingActions),
+	}, func(page *iam.SimulatePolicyResponse, lastPage bool) bool {
+		for _, result := range page.EvaluationResults {
+			if aws.StringValue(result.EvalDecision) == iam.PolicyEvaluationDecisionTypeAllowed {
+				report.AllowedActions = append(report.AllowedActions, aws.StringValue(result.EvalActionName))
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to simulate principal policy for %s: %w", report.PrincipalARN, err)
+	}
+
+	return report, nil
+}
@@ -0,0 +1,176 @@
+package aws
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Reason code categories
+const (
+	ReasonCategoryCost     = "cost"     // The resource is billed without being used
+	ReasonCategorySecurity = "security" // The resource widens access or exposure without being used
+	ReasonCategoryHygiene  = "hygiene"  // The resource is leftover configuration
+)
+
+// ReasonCode identifies a kind of finding reason, so automation can map findings to runbooks
+// without parsing the reason text
+type ReasonCode struct {
+	Code        string `json:"code"`
+	Category    string `json:"category"`
+	Description string `json:"description"`
+	match       *regexp.Regexp
+}
+
+// reasonCode returns a reason code matching the reason lines that start with one of the
+// patterns
+func reasonCode(code, category, description string, patterns ...string) ReasonCode {
+	return ReasonCode{
+		Code:        code,
+		Category:    category,
+		Description: description,
+		match:       regexp.MustCompile(`^(?:` + strings.Join(patterns, "|") + `)`),
+	}
+}
+
+// ReasonCodes is the taxonomy of the reasons scanners report, matched against each line of a
+// reason. Codes are stable; descriptions may be reworded.
+var ReasonCodes = []ReasonCode{
+	// Cost
+	reasonCode("low_cpu_utilization", ReasonCategoryCost, "CPU utilization stayed below the idle threshold", "Very low CPU utilization"),
+	reasonCode("scheduling_candidate", ReasonCategoryCost, "CPU is only used during business hours, so the resource can be stopped outside them", "Candidate for scheduled stop/start"),
+	reasonCode("low_network_activity", ReasonCategoryCost, "Network traffic stayed below the idle threshold or was absent", "Very low network activity", "Very low network traffic", "No network traffic"),
+	reasonCode("low_io_activity", ReasonCategoryCost, "Disk I/O stayed below the idle threshold", "Very low I/O activity"),
+	reasonCode("low_activity", ReasonCategoryCost, "Reads, writes or searches stayed below the idle threshold", "Very low activity", "Low read capacity utilization", "Low write capacity utilization", `Large table \(.+\) with low activity`),
+	reasonCode("low_storage_utilization", ReasonCategoryCost, "Provisioned storage is mostly unused", "Low storage utilization"),
+	reasonCode("stopped_instance", ReasonCategoryCost, "The instance is stopped while its storage is still billed", "Instance has been stopped for", "Lightsail instance is stopped", "Lightsail database is stopped"),
+	reasonCode("not_running", ReasonCategoryCost, "The resource is in a non-running state", "Non-running state"),
+	reasonCode("no_database_connections", ReasonCategoryCost, "The database had no connections", "No active database connections", "No database connections"),
+	reasonCode("idle_table", ReasonCategoryCost, "The table had no reads or writes", "Empty table with no read/write activity", "Table has data but no read/write activity"),
+	reasonCode("idle_provisioned_capacity", ReasonCategoryCost, "The table's provisioned capacity was not consumed or decreased, so it was billed for nothing", `Provisioned capacity \(`),
+	reasonCode("idle_search_cluster", ReasonCategoryCost, "The search cluster had no search traffic", "Cluster has data but no search", "Cluster is empty with no search", "Cluster has no search traffic"),
+	reasonCode("unused_volume", ReasonCategoryCost, "The volume has not been read from or written to", "Volume has not been used", "Volume has been idle", "Very low read activity", "Very low write activity"),
+	reasonCode("low_gateway_traffic", ReasonCategoryCost, "The load balancer or NAT gateway had little or no traffic", "No traffic recorded", "Very low traffic variation", "NAT Gateway has (?:no|minimal|outbound|inbound) traffic"),
+	reasonCode("unattached_resource", ReasonCategoryCost, "The resource is not attached to anything that uses it", "Not associated with any resource", "Associated with stopped instance", "No resources attached"),
+	reasonCode("unused_ami", ReasonCategoryCost, "The AMI is not used by any instance and keeps its snapshots billed", "AMI has not been used", `\d+ remaining snapshots are only kept for this AMI`),
+	reasonCode("redundant_ami_copy", ReasonCategoryCost, "Copies of the AMI are kept in regions no instance launches it in", `Copy of .+ kept in \d+ regions`, "No instances launched from this copy"),
+	reasonCode("orphaned_snapshot", ReasonCategoryCost, "The snapshot's source volume was deleted", "Source volume was deleted"),
+	reasonCode("redundant_snapshot", ReasonCategoryCost, "Newer snapshots exist for the same volume", "Multiple snapshots exist for volume"),
+	reasonCode("old_snapshot", ReasonCategoryCost, "The snapshot is older than the retention threshold", `Snapshot is .+ old\.`),
+	reasonCode("incomplete_multipart_uploads", ReasonCategoryCost, "Incomplete multipart uploads are still stored", `Bucket has \d+ incomplete multipart uploads`),
+	reasonCode("accelerator_charges", ReasonCategoryCost, "The idle instance carries accelerators or software billed on top of it", `Instance type \S+ carries `, `\d+ Elastic (?:GPU|Inference accelerator)\(s\) attached`, "Idle instance incurs Marketplace software charges"),
+	reasonCode("no_requests", ReasonCategoryCost, "The resource received no requests", "No requests in the last"),
+	reasonCode("no_deployments", ReasonCategoryCost, "The environment has not been deployed or reconfigured recently", "No deployments in the last"),
+	reasonCode("unused_build_project", ReasonCategoryCost, "The build project has not been built recently or ever", "Project has never been built", "No builds in the last"),
+	reasonCode("idle_reserved_fleet", ReasonCategoryCost, "Reserved build capacity is not used by any project", "Fleet keeps"),
+	reasonCode("unused_pipeline", ReasonCategoryCost, "The pipeline has not been executed recently or ever", "Pipeline has never been executed", "No pipeline executions"),
+	reasonCode("long_running_dev_endpoint", ReasonCategoryCost, "The Glue dev endpoint keeps running and is billed per DPU-hour", "Dev endpoint has been running"),
+	reasonCode("unused_crawler", ReasonCategoryCost, "The Glue crawler has not run recently or ever", "Crawler has never run", "Crawler has not run"),
+	reasonCode("stale_job_bookmark", ReasonCategoryCost, "The Glue job no longer runs but keeps bookmark state", "Job has not run"),
+	reasonCode("idle_emr_cluster", ReasonCategoryCost, "The EMR cluster is waiting without steps", "Cluster has been waiting"),
+	reasonCode("stored_query_results", ReasonCategoryCost, "Query results of an unused workgroup are still stored", "No queries in the last"),
+	reasonCode("broken_metric_stream", ReasonCategoryCost, "The metric stream sends to a deleted or inactive delivery stream", "Metric stream sends to"),
+	reasonCode("broken_log_subscription", ReasonCategoryCost, "The subscription filter delivers to a deleted destination or one that fails every delivery", "Subscription filter delivers to", "All invocations of the Lambda function the subscription filter", "The Firehose delivery stream the subscription filter"),
+	reasonCode("duplicate_log_subscription", ReasonCategoryCost, "The subscription filter delivers the same log events as another filter of the log group", "Subscription filter duplicates"),
+	reasonCode("idle_contributor_insights_rule", ReasonCategoryCost, "The Contributor Insights rule matched no log events", "Contributor Insights rule matched no log events"),
+	reasonCode("overprovisioned_resource", ReasonCategoryCost, "AWS Compute Optimizer recommends a smaller instance type, volume configuration or memory size", "AWS Compute Optimizer reports"),
+
+	// Security
+	reasonCode("unused_role", ReasonCategorySecurity, "The IAM role has not been used recently or ever", "Role has never been used", "Role has not been used", "IAM Access Analyzer reports the role as unused"),
+	reasonCode("unused_console_access", ReasonCategorySecurity, "The IAM user's console password has not been used recently or ever", "User has never logged in to the console", "User has not logged in to the console", "IAM Access Analyzer reports an unused console password"),
+	reasonCode("unused_access_key", ReasonCategorySecurity, "The IAM user's access keys have not been used recently or ever", "User has never used access keys", "User has not used access keys", "IAM Access Analyzer reports an unused access key"),
+	reasonCode("unused_permissions", ReasonCategorySecurity, "The principal has permissions it does not use", "IAM Access Analyzer reports unused permissions"),
+	reasonCode("unused_identity_provider", ReasonCategorySecurity, "The OIDC or SAML identity provider is not trusted by any role", `(?:OIDC|SAML) provider created \d+ days ago is not trusted`),
+	reasonCode("snapshot_public", ReasonCategorySecurity, "The snapshot is shared publicly", "Snapshot is shared publicly"),
+	reasonCode("snapshot_shared_unknown_account", ReasonCategorySecurity, "The snapshot is shared with accounts outside the organization", "Snapshot is shared with unknown accounts"),
+
+	// Hygiene
+	reasonCode("role_without_policies", ReasonCategoryHygiene, "The IAM role has no attached policies", "Role has no attached policies"),
+	reasonCode("empty_vpc", ReasonCategoryHygiene, "The VPC has no instances or network interfaces", "VPC has no EC2 Instances or ENIs"),
+	reasonCode("stale_route_table", ReasonCategoryHygiene, "Route tables are not associated with any subnet", "Route tables not associated with any subnet"),
+	reasonCode("unrouted_gateway", ReasonCategoryHygiene, "Gateways have no routes pointing to them", "Egress-only internet gateways without routes", "Carrier gateways without routes"),
+	reasonCode("detached_gateway", ReasonCategoryHygiene, "Gateways are not attached to a VPC", "Internet gateways not attached to a VPC", "Egress-only internet gateways not attached to a VPC", "Virtual private gateways not attached to a VPC"),
+	reasonCode("unused_dhcp_options", ReasonCategoryHygiene, "DHCP option sets are not used by any VPC", "DHCP option sets not used by any VPC"),
+	reasonCode("missing_multipart_lifecycle_rule", ReasonCategoryHygiene, "No lifecycle rule aborts incomplete multipart uploads", "No lifecycle rule aborts incomplete multipart uploads"),
+	reasonCode("unexpired_query_results", ReasonCategoryHygiene, "Query results are not expired by a lifecycle rule", `Query results \(.+\) are not expired by a lifecycle rule`),
+	reasonCode("failed_crawler", ReasonCategoryHygiene, "The last crawl of the Glue crawler failed", "Last crawl did not succeed"),
+	reasonCode("broken_composite_alarm", ReasonCategoryHygiene, "The composite alarm references deleted alarms", "Composite alarm references deleted alarms"),
+	reasonCode("unused_composite_alarm", ReasonCategoryHygiene, "The composite alarm has no actions and is not used by another composite alarm", "Composite alarm has no actions"),
+	reasonCode("broken_ami", ReasonCategoryHygiene, "The AMI cannot be launched because its backing snapshots were deleted", "AMI cannot be launched"),
+	reasonCode("unattached_instance_profile", ReasonCategoryHygiene, "The instance profile is not associated with any EC2 instance", "Instance profile created", "Instance profile has no role"),
+	reasonCode("warm_pool_instance", ReasonCategoryHygiene, "The instance is kept in an Auto Scaling warm pool to scale out quickly", "Instance is kept in the warm pool"),
+	reasonCode("hibernated_instance", ReasonCategoryHygiene, "The instance is hibernated, keeping its memory on its root volume to resume quickly", "Instance has been hibernated for"),
+	reasonCode("stale_schedule", ReasonCategoryHygiene, "The EventBridge schedule is disabled, ended or already ran once", "Schedule has been disabled since", "Schedule ended on", "One-time schedule ran on"),
+	reasonCode("broken_schedule", ReasonCategoryHygiene, "The schedule or scheduled rule targets deleted resources", "Schedule targets a deleted resource", "Scheduled rule targets only deleted resources"),
+	reasonCode("disabled_scheduled_rule", ReasonCategoryHygiene, "The scheduled EventBridge rule is disabled", "Scheduled rule is disabled"),
+	reasonCode("migrated_scheduled_rule", ReasonCategoryHygiene, "The scheduled rule runs the same target as the EventBridge schedule it was migrated to", "Scheduled rule duplicates schedule"),
+	reasonCode("metrics_unavailable", ReasonCategoryHygiene, "Usage could not be verified because CloudWatch metrics are unavailable", "CloudWatch metrics unavailable"),
+}
+
+// ClassifyReason returns the codes of the lines of a reason, in the order of the lines and
+// without duplicates. Lines no code matches are skipped.
+func ClassifyReason(reason string) []string {
+	var codes []string
+	for _, line := range strings.Split(reason, "\n") {
+		line = strings.TrimSpace(line)
+		for _, code := range ReasonCodes {
+			if code.match.MatchString(line) {
+				if !slices.Contains(codes, code.Code) {
+					codes = append(codes, code.Code)
+				}
+				break
+			}
+		}
+	}
+	return codes
+}
+
+// ApplyReasonCodes adds the codes of the reasons of scan results to their reason_codes detail,
+// keeping the codes scanners already set
+func ApplyReasonCodes(results ScanResults) {
+	for i := range results {
+		codes, _ := results[i].Details["reason_codes"].([]string)
+		for _, code := range ClassifyReason(results[i].Reason) {
+			if !slices.Contains(codes, code) {
+				codes = append(codes, code)
+			}
+		}
+		if len(codes) == 0 {
+			continue
+		}
+		if results[i].Details == nil {
+			results[i].Details = make(map[string]interface{})
+		}
+		results[i].Details["reason_codes"] = codes
+	}
+}
+
+// ReasonCodesOf returns the reason codes of a scan result. They are taken from its
+// reason_codes detail, which holds a list of strings or, in results read back from JSON, a
+// list of values, and are otherwise classified from its reason.
+func ReasonCodesOf(result ScanResult) []string {
+	switch codes := result.Details["reason_codes"].(type) {
+	case []string:
+		return codes
+	case []interface{}:
+		var strs []string
+		for _, code := range codes {
+			if s, ok := code.(string); ok {
+				strs = append(strs, s)
+			}
+		}
+		return strs
+	}
+	return ClassifyReason(result.Reason)
+}
+
+// ReasonCodeCategory returns the category of a reason code, or an empty string for unknown
+// codes
+func ReasonCodeCategory(code string) string {
+	for _, c := range ReasonCodes {
+		if c.Code == code {
+			return c.Category
+		}
+	}
+	return ""
+}
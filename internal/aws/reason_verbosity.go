@@ -0,0 +1,79 @@
+package aws
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Reason verbosity levels
+const (
+	ReasonVerbositySummary = "summary" // First reason only, without metric values
+	ReasonVerbosityNormal  = "normal"  // Reasons as produced by the scanners
+	ReasonVerbosityDebug   = "debug"   // Reasons followed by the thresholds and metric values
+)
+
+// ReasonVerbosities lists the valid reason verbosity levels
+var ReasonVerbosities = []string{ReasonVerbositySummary, ReasonVerbosityNormal, ReasonVerbosityDebug}
+
+// ValidateReasonVerbosity returns an error if a reason verbosity level is not valid
+func ValidateReasonVerbosity(verbosity string) error {
+	for _, valid := range ReasonVerbosities {
+		if verbosity == valid {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid reason verbosity %q, must be one of: %s", verbosity, strings.Join(ReasonVerbosities, ", "))
+}
+
+// metricValues matches the parenthesized metric values of reasons, e.g. " (2.50%)" or
+// " (in: 0.12 KB/s, out: 0.03 KB/s)"
+var metricValues = regexp.MustCompile(`\s*\([^()]*\d[^()]*\)`)
+
+// SummarizeReason returns the first line of a reason without its parenthesized metric values
+func SummarizeReason(reason string) string {
+	line, _, _ := strings.Cut(reason, "\n")
+	return strings.TrimSpace(metricValues.ReplaceAllString(line, ""))
+}
+
+// ApplyReasonVerbosity rewrites the reasons of scan results for a verbosity level. Summary
+// keeps the first reason without its metric values; debug appends the days unused threshold
+// and the numeric details of the result, which hold the metric values the scanner found.
+// Normal leaves the reasons unchanged.
+func ApplyReasonVerbosity(results ScanResults, verbosity string, daysUnused int) {
+	for i := range results {
+		switch verbosity {
+		case ReasonVerbositySummary:
+			results[i].Reason = SummarizeReason(results[i].Reason)
+		case ReasonVerbosityDebug:
+			lines := []string{results[i].Reason, fmt.Sprintf("Threshold: days_unused=%d", daysUnused)}
+			if signals := numericDetails(results[i].Details); signals != "" {
+				lines = append(lines, "Signals: "+signals)
+			}
+			results[i].Reason = strings.TrimPrefix(strings.Join(lines, "\n"), "\n")
+		}
+	}
+}
+
+// numericDetails formats the numeric details of a result as sorted key=value pairs
+func numericDetails(details map[string]interface{}) string {
+	var pairs []string
+	for key, value := range details {
+		var formatted string
+		switch v := value.(type) {
+		case int:
+			formatted = strconv.Itoa(v)
+		case int64:
+			formatted = strconv.FormatInt(v, 10)
+		case float64:
+			formatted = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			continue
+		}
+		pairs = append(pairs, key+"="+formatted)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ", ")
+}
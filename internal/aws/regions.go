@@ -2,13 +2,22 @@ package aws
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/sts"
 )
 
+// AllEnabledRegions is the regions value scanning all regions enabled for the account, which
+// is also the default. Local Zones and Wavelength Zones are not regions: their resources are
+// scanned with their parent region.
+const AllEnabledRegions = "all-enabled"
+
 // GetAvailableRegions returns a list of regions that are enabled for the account
 func GetAvailableRegions(sess *session.Session) ([]string, error) {
 	// Start with us-east-1 to get the region list
@@ -47,6 +56,10 @@ func ValidateRegions(sess *session.Session, requestedRegions []string) error {
 	// Check each requested region
 	for _, region := range requestedRegions {
 		if !regionMap[region] {
+			if parent := parentRegion(region, availableRegions); parent != "" {
+				return fmt.Errorf("'%s' is a Local Zone or Wavelength Zone, not a region. Its resources are scanned with its parent region %s, and priced by the zone with --edge-zones",
+					region, parent)
+			}
 			return fmt.Errorf("region '%s' is not available in this account. Available regions: %s",
 				region, strings.Join(availableRegions, ", "))
 		}
@@ -54,3 +67,99 @@ func ValidateRegions(sess *session.Session, requestedRegions []string) error {
 
 	return nil
 }
+
+// parentRegion returns the region of regions a Local Zone or Wavelength Zone name belongs
+// to, e.g. us-west-2 for us-west-2-lax-1a, or "" if the name is not a zone of any of them
+func parentRegion(zone string, regions []string) string {
+	for _, region := range regions {
+		if strings.HasPrefix(zone, region+"-") {
+			return region
+		}
+	}
+	return ""
+}
+
+// partitionHomeRegions are the regions global services such as IAM are scanned in, by partition
+var partitionHomeRegions = map[string]string{
+	endpoints.AwsPartitionID:      "us-east-1",
+	endpoints.AwsUsGovPartitionID: "us-gov-west-1",
+	endpoints.AwsCnPartitionID:    "cn-north-1",
+	endpoints.AwsIsoPartitionID:   "us-iso-east-1",
+	endpoints.AwsIsoBPartitionID:  "us-isob-east-1",
+}
+
+// RegionPartition returns the ID of the partition of a region. Unknown regions are assumed
+// to be in the standard partition.
+func RegionPartition(region string) string {
+	if partition, ok := endpoints.PartitionForRegion(endpoints.DefaultPartitions(), region); ok {
+		return partition.ID()
+	}
+	return endpoints.AwsPartitionID
+}
+
+// RoleARN returns the ARN of a role in an account, in the partition of a region, so roles of
+// GovCloud and China accounts are assumed by their own ARNs
+func RoleARN(region, accountID, roleName string) string {
+	return fmt.Sprintf("arn:%s:iam::%s:role/%s", RegionPartition(region), accountID, roleName)
+}
+
+// CallerRoleARN returns the ARN of a role in the account of a caller identity, in the partition
+// of the caller
+func CallerRoleARN(identity *sts.GetCallerIdentityOutput, roleName string) string {
+	partition := endpoints.AwsPartitionID
+	if parsed, err := arn.Parse(aws.StringValue(identity.Arn)); err == nil {
+		partition = parsed.Partition
+	}
+	return fmt.Sprintf("arn:%s:iam::%s:role/%s", partition, aws.StringValue(identity.Account), roleName)
+}
+
+// IsRoleARN reports whether a role is given by its IAM ARN, in any partition, rather than by
+// its name
+func IsRoleARN(role string) bool {
+	parsed, err := arn.Parse(role)
+	return err == nil && parsed.Service == "iam"
+}
+
+// PartitionHomeRegion returns the home region of the partition of a region, where global
+// services such as IAM and S3 bucket listing are called. Partitions without a known home
+// region use the region itself.
+func PartitionHomeRegion(region string) string {
+	if homeRegion, ok := partitionHomeRegions[RegionPartition(region)]; ok {
+		return homeRegion
+	}
+	return region
+}
+
+// GlobalRegions returns the home region of each partition of the given regions, so global
+// services are scanned once per partition. Without regions the standard partition is scanned.
+func GlobalRegions(regions []string) []string {
+	seen := make(map[string]bool)
+	var homeRegions []string
+	for _, region := range regions {
+		// Partitions without a known home region use the first scanned region
+		homeRegion := PartitionHomeRegion(region)
+		if !seen[homeRegion] {
+			seen[homeRegion] = true
+			homeRegions = append(homeRegions, homeRegion)
+		}
+	}
+	if len(homeRegions) == 0 {
+		return []string{partitionHomeRegions[endpoints.AwsPartitionID]}
+	}
+	sort.Strings(homeRegions)
+	return homeRegions
+}
+
+// GlobalRegionLabel returns the region label of results of global services in a partition:
+// "global" in the standard partition and "global-<partition>" in others, e.g. "global-aws-us-gov"
+func GlobalRegionLabel(partition string) string {
+	if partition == "" || partition == endpoints.AwsPartitionID {
+		return "global"
+	}
+	return "global-" + partition
+}
+
+// IsGlobalRegionLabel reports whether a region label is the label of a global service
+func IsGlobalRegionLabel(region string) bool {
+	return region == "global" || strings.HasPrefix(region, "global-")
+}
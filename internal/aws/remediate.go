@@ -0,0 +1,253 @@
+package aws
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/cloudwatch/cloudwatchiface"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/aws/aws-sdk-go/service/elb"
+	"github.com/aws/aws-sdk-go/service/elb/elbiface"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/aws/aws-sdk-go/service/elbv2/elbv2iface"
+)
+
+// RemediationClients are the clients deletions in one account and region are made with
+type RemediationClients struct {
+	EC2        ec2iface.EC2API
+	ELB        elbiface.ELBAPI
+	ELBV2      elbv2iface.ELBV2API
+	CloudWatch cloudwatchiface.CloudWatchAPI
+}
+
+// ErrResourceInUse is returned by the checks of remediation targets when the live state of a
+// resource shows it is used again since it was scanned
+var ErrResourceInUse = errors.New("resource is in use again")
+
+// remediationIdleWindow is how far back load balancers are checked for traffic before being
+// deleted
+const remediationIdleWindow = 24 * time.Hour
+
+// NewRemediationClients creates the remediation clients of a regional session
+func NewRemediationClients(sess *session.Session) RemediationClients {
+	return RemediationClients{
+		EC2:        ec2.New(sess),
+		ELB:        elb.New(sess),
+		ELBV2:      elbv2.New(sess),
+		CloudWatch: cloudwatch.New(sess),
+	}
+}
+
+// RemediationTarget is a kind of resource found by scans that can be deleted
+type RemediationTarget struct {
+	Name          string   // Name of the flag opting in to deleting the resources
+	Description   string   // Plural description of the resources
+	ResourceTypes []string // Labels of the scanners reporting the resources
+
+	// Action returns the API action that deletes the resource of a result
+	Action func(result ScanResult) string
+
+	// Check re-checks the live state of the resource of a result before it is deleted,
+	// returning an error wrapping ErrResourceInUse if it is no longer unused. Nil if the
+	// resource is deleted without a check or Delete checks it itself.
+	Check func(clients RemediationClients, result ScanResult) error
+
+	// Delete deletes the resource of a result, returning a note on what else it did, such as
+	// the ID of a snapshot taken before. Like Check, it returns an error wrapping
+	// ErrResourceInUse if it finds the resource used again.
+	Delete func(clients RemediationClients, result ScanResult) (string, error)
+}
+
+// RemediationTargets are the kinds of resources the remediate command can delete
+var RemediationTargets = []RemediationTarget{
+	{
+		Name:          "ebs-volumes",
+		Description:   "unused EBS volumes, after taking a snapshot of each",
+		ResourceTypes: []string{"EBS Volumes"},
+		Action:        func(ScanResult) string { return "ec2:DeleteVolume" },
+		Check:         checkVolumeAvailable,
+		Delete:        deleteVolume,
+	},
+	{
+		Name:          "ebs-snapshots",
+		Description:   "unused EBS snapshots",
+		ResourceTypes: []string{"EBS Snapshots"},
+		Action:        func(ScanResult) string { return "ec2:DeleteSnapshot" },
+		Delete: func(clients RemediationClients, result ScanResult) (string, error) {
+			_, err := clients.EC2.DeleteSnapshot(&ec2.DeleteSnapshotInput{SnapshotId: aws.String(result.ResourceID)})
+			return "", err
+		},
+	},
+	{
+		Name:          "elastic-ips",
+		Description:   "unassociated Elastic IPs and those of stopped instances",
+		ResourceTypes: []string{"Elastic IPs"},
+		Action:        func(ScanResult) string { return "ec2:ReleaseAddress" },
+		Delete:        releaseAddress,
+	},
+	{
+		Name:          "amis",
+		Description:   "unused AMIs and AMI copies (their snapshots are kept)",
+		ResourceTypes: []string{"AMIs", "AMI Copies"},
+		Action:        func(ScanResult) string { return "ec2:DeregisterImage" },
+		Delete: func(clients RemediationClients, result ScanResult) (string, error) {
+			_, err := clients.EC2.DeregisterImage(&ec2.DeregisterImageInput{ImageId: aws.String(result.ResourceID)})
+			return "", err
+		},
+	},
+	{
+		Name:          "load-balancers",
+		Description:   "unused classic and application, network and gateway load balancers",
+		ResourceTypes: []string{"Load Balancers"},
+		Action:        func(ScanResult) string { return "elasticloadbalancing:DeleteLoadBalancer" },
+		Check:         checkLoadBalancerIdle,
+		Delete: func(clients RemediationClients, result ScanResult) (string, error) {
+			// Classic load balancers are identified by name, the others by ARN
+			if lbType, _ := result.Details["type"].(string); lbType == "classic" {
+				_, err := clients.ELB.DeleteLoadBalancer(&elb.DeleteLoadBalancerInput{LoadBalancerName: aws.String(result.ResourceID)})
+				return "", err
+			}
+			_, err := clients.ELBV2.DeleteLoadBalancer(&elbv2.DeleteLoadBalancerInput{LoadBalancerArn: aws.String(result.ResourceID)})
+			return "", err
+		},
+	},
+}
+
+// RemediationTargetForType returns the remediation target of the results of a scanner
+func RemediationTargetForType(resourceType string) (RemediationTarget, bool) {
+	for _, target := range RemediationTargets {
+		for _, t := range target.ResourceTypes {
+			if t == resourceType {
+				return target, true
+			}
+		}
+	}
+	return RemediationTarget{}, false
+}
+
+// deleteVolume snapshots a volume before deleting it, like the suggested remediation, so it
+// can be restored. The volume can be deleted while the snapshot is still pending.
+func deleteVolume(clients RemediationClients, result ScanResult) (string, error) {
+	snapshot, err := clients.EC2.CreateSnapshot(&ec2.CreateSnapshotInput{
+		VolumeId:    aws.String(result.ResourceID),
+		Description: aws.String(fmt.Sprintf("Before deleting unused volume %s (cloudsift remediate, %s)", result.ResourceID, time.Now().UTC().Format("2006-01-02"))),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to snapshot volume before deleting it: %w", err)
+	}
+	snapshotID := aws.StringValue(snapshot.SnapshotId)
+
+	if _, err := clients.EC2.DeleteVolume(&ec2.DeleteVolumeInput{VolumeId: aws.String(result.ResourceID)}); err != nil {
+		return "snapshot " + snapshotID, err
+	}
+	return "snapshot " + snapshotID, nil
+}
+
+// releaseAddress releases an Elastic IP if it is still unassociated or associated with a
+// stopped instance, disassociating it first in the latter case. The address and its instance
+// are described again as the scan results may be stale.
+func releaseAddress(clients RemediationClients, result ScanResult) (string, error) {
+	addresses, err := clients.EC2.DescribeAddresses(&ec2.DescribeAddressesInput{AllocationIds: []*string{aws.String(result.ResourceID)}})
+	if err != nil {
+		return "", fmt.Errorf("failed to describe address: %w", err)
+	}
+	if len(addresses.Addresses) == 0 {
+		return "", fmt.Errorf("address %s not found", result.ResourceID)
+	}
+	address := addresses.Addresses[0]
+
+	var note string
+	if associationID := aws.StringValue(address.AssociationId); associationID != "" {
+		instanceID := aws.StringValue(address.InstanceId)
+		if instanceID == "" {
+			return "", fmt.Errorf("%w: address is associated with network interface %s", ErrResourceInUse, aws.StringValue(address.NetworkInterfaceId))
+		}
+		instances, err := clients.EC2.DescribeInstances(&ec2.DescribeInstancesInput{InstanceIds: []*string{aws.String(instanceID)}})
+		if err != nil {
+			return "", fmt.Errorf("failed to describe instance of address: %w", err)
+		}
+		var state string
+		for _, reservation := range instances.Reservations {
+			for _, instance := range reservation.Instances {
+				if instance.State != nil {
+					state = aws.StringValue(instance.State.Name)
+				}
+			}
+		}
+		if state != ec2.InstanceStateNameStopped {
+			return "", fmt.Errorf("%w: address is associated with instance %s, which is %s", ErrResourceInUse, instanceID, state)
+		}
+
+		// Addresses of stopped instances are disassociated before they can be released
+		if _, err := clients.EC2.DisassociateAddress(&ec2.DisassociateAddressInput{AssociationId: aws.String(associationID)}); err != nil {
+			return "", fmt.Errorf("failed to disassociate address: %w", err)
+		}
+		note = fmt.Sprintf("disassociated from %s", instanceID)
+	}
+	_, err = clients.EC2.ReleaseAddress(&ec2.ReleaseAddressInput{AllocationId: aws.String(result.ResourceID)})
+	return note, err
+}
+
+// checkVolumeAvailable checks that a volume is still not attached to any instance
+func checkVolumeAvailable(clients RemediationClients, result ScanResult) error {
+	output, err := clients.EC2.DescribeVolumes(&ec2.DescribeVolumesInput{VolumeIds: []*string{aws.String(result.ResourceID)}})
+	if err != nil {
+		return fmt.Errorf("failed to describe volume: %w", err)
+	}
+	if len(output.Volumes) == 0 {
+		return fmt.Errorf("volume %s not found", result.ResourceID)
+	}
+	if state := aws.StringValue(output.Volumes[0].State); state != ec2.VolumeStateAvailable {
+		return fmt.Errorf("%w: volume is %s", ErrResourceInUse, state)
+	}
+	return nil
+}
+
+// checkLoadBalancerIdle checks that a load balancer had no requests or, for network and
+// gateway load balancers, processed no bytes within the idle window
+func checkLoadBalancerIdle(clients RemediationClients, result ScanResult) error {
+	lbType, _ := result.Details["type"].(string)
+	namespace, metric, dimension := "AWS/ApplicationELB", "RequestCount", "LoadBalancer"
+	// Metrics of load balancers other than classic ones are dimensioned by the end of the ARN,
+	// such as app/my-alb/1234567890
+	value := result.ResourceID
+	if i := strings.Index(value, ":loadbalancer/"); i >= 0 {
+		value = value[i+len(":loadbalancer/"):]
+	}
+	switch lbType {
+	case "classic":
+		namespace, dimension = "AWS/ELB", "LoadBalancerName"
+	case "network":
+		namespace, metric = "AWS/NetworkELB", "ProcessedBytes"
+	case "gateway":
+		namespace, metric = "AWS/GatewayELB", "ProcessedBytes"
+	}
+
+	endTime := time.Now()
+	output, err := clients.CloudWatch.GetMetricStatistics(&cloudwatch.GetMetricStatisticsInput{
+		Namespace:  aws.String(namespace),
+		MetricName: aws.String(metric),
+		Dimensions: []*cloudwatch.Dimension{{Name: aws.String(dimension), Value: aws.String(value)}},
+		StartTime:  aws.Time(endTime.Add(-remediationIdleWindow)),
+		EndTime:    aws.Time(endTime),
+		Period:     aws.Int64(int64(remediationIdleWindow.Seconds())),
+		Statistics: []*string{aws.String(cloudwatch.StatisticSum)},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get %s metrics: %w", metric, err)
+	}
+	var total float64
+	for _, point := range output.Datapoints {
+		total += aws.Float64Value(point.Sum)
+	}
+	if total > 0 {
+		return fmt.Errorf("%w: %s was %.0f in the last %.0f hours", ErrResourceInUse, metric, total, remediationIdleWindow.Hours())
+	}
+	return nil
+}
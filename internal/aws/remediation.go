@@ -0,0 +1,113 @@
+package aws
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"text/template"
+
+	"cloudsift/internal/logging"
+)
+
+// RemediationDetail is the result detail holding the suggested remediation command
+const RemediationDetail = "suggested_remediation"
+
+// Remediator is optionally implemented by scanners that suggest an AWS CLI command removing
+// or stopping the resources they report. The template is a text/template executed with
+// RemediationData; an empty output suggests no command for the result.
+type Remediator interface {
+	RemediationTemplate() string
+}
+
+// RemediationData is the data remediation templates are executed with. All values are quoted
+// for POSIX shells where needed, so they can be used as arguments as they are.
+type RemediationData struct {
+	ResourceID   string
+	ResourceName string
+	AccountID    string
+	Region       string
+	Details      map[string]string // Scalar details of the result
+}
+
+// shellSafe matches arguments that need no quoting in POSIX shells
+var shellSafe = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// shellQuote quotes an argument for POSIX shells if needed
+func shellQuote(s string) string {
+	if shellSafe.MatchString(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+}
+
+// remediationTemplates caches the parsed templates by scanner label
+var remediationTemplates sync.Map
+
+// remediationTemplate returns the parsed remediation template of a scanner
+func remediationTemplate(scanner Scanner, text string) (*template.Template, error) {
+	if cached, ok := remediationTemplates.Load(scanner.Label()); ok {
+		return cached.(*template.Template), nil
+	}
+	tmpl, err := template.New(scanner.ArgumentName()).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid remediation template of %s: %w", scanner.Label(), err)
+	}
+	remediationTemplates.Store(scanner.Label(), tmpl)
+	return tmpl, nil
+}
+
+// newRemediationData prepares the template data of a scan result found in a region
+func newRemediationData(result ScanResult, region string) RemediationData {
+	if detailRegion, ok := result.Details["region"].(string); ok && detailRegion != "" && !IsGlobalRegionLabel(detailRegion) {
+		region = detailRegion
+	}
+	data := RemediationData{
+		ResourceID:   shellQuote(result.ResourceID),
+		ResourceName: shellQuote(result.ResourceName),
+		AccountID:    shellQuote(result.AccountID),
+		Region:       shellQuote(region),
+		Details:      make(map[string]string, len(result.Details)),
+	}
+	for key, value := range result.Details {
+		switch value.(type) {
+		case string, bool, int, int64, float64:
+			data.Details[key] = shellQuote(fmt.Sprint(value))
+		}
+	}
+	return data
+}
+
+// AddSuggestedRemediation sets the suggested remediation command of each result of a scanner
+// in a region, if the scanner implements Remediator
+func AddSuggestedRemediation(scanner Scanner, results ScanResults, region string) {
+	remediator, ok := scanner.(Remediator)
+	if !ok || len(results) == 0 {
+		return
+	}
+	tmpl, err := remediationTemplate(scanner, remediator.RemediationTemplate())
+	if err != nil {
+		logging.Error("Failed to parse remediation template", err, nil)
+		return
+	}
+
+	for i := range results {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, newRemediationData(results[i], region)); err != nil {
+			logging.Error("Failed to render remediation command", err, map[string]interface{}{
+				"scanner":     scanner.Label(),
+				"resource_id": results[i].ResourceID,
+			})
+			continue
+		}
+		command := strings.TrimSpace(buf.String())
+		if command == "" {
+			continue
+		}
+		if results[i].Details == nil {
+			results[i].Details = make(map[string]interface{})
+		}
+		results[i].Details[RemediationDetail] = command
+	}
+}
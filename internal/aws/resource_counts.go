@@ -0,0 +1,158 @@
+package aws
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi"
+
+	"cloudsift/internal/logging"
+)
+
+// scannerResourceTypes maps scanner argument names to the Resource Groups Tagging API
+// resource types ("service:resource") that the scanner inspects
+var scannerResourceTypes = map[string][]string{
+	"amis":            {"ec2:image"},
+	"dynamodb":        {"dynamodb:table"},
+	"ebs-snapshots":   {"ec2:snapshot"},
+	"ebs-volumes":     {"ec2:volume"},
+	"ec2-instances":   {"ec2:instance"},
+	"elastic-ips":     {"ec2:elastic-ip"},
+	"load-balancers":  {"elasticloadbalancing:loadbalancer"},
+	"nat-gateways":    {"ec2:natgateway"},
+	"opensearch":      {"es:domain"},
+	"rds":             {"rds:db"},
+	"security-groups": {"ec2:security-group"},
+	"vpcs":            {"ec2:vpc"},
+}
+
+// ResourceTypesForScanner returns the tagging API resource types inspected by a scanner.
+// Scanners without a mapping (e.g. global IAM scanners) return nil.
+func ResourceTypesForScanner(argumentName string) []string {
+	return scannerResourceTypes[argumentName]
+}
+
+// ResourceCounts holds resource counts per account, region and scanner gathered by the
+// tagging API pre-pass. Combinations that were not counted are reported as unknown.
+type ResourceCounts struct {
+	counts map[string]int // key is accountID:region:scanner
+	mu     sync.RWMutex
+}
+
+// NewResourceCounts creates an empty ResourceCounts
+func NewResourceCounts() *ResourceCounts {
+	return &ResourceCounts{
+		counts: make(map[string]int),
+	}
+}
+
+// Set records the resource count for an account/region/scanner combination
+func (rc *ResourceCounts) Set(accountID, region, scanner string, count int) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.counts[fmt.Sprintf("%s:%s:%s", accountID, region, scanner)] = count
+}
+
+// Get returns the resource count for an account/region/scanner combination and whether it is known
+func (rc *ResourceCounts) Get(accountID, region, scanner string) (int, bool) {
+	rc.mu.RLock()
+	defer rc.mu.RUnlock()
+	count, ok := rc.counts[fmt.Sprintf("%s:%s:%s", accountID, region, scanner)]
+	return count, ok
+}
+
+// Empty returns true if the combination was counted and no resources were found.
+// Note that the tagging API only returns resources that are or have been tagged,
+// so an empty count is a strong hint rather than proof that nothing exists.
+func (rc *ResourceCounts) Empty(accountID, region, scanner string) bool {
+	count, ok := rc.Get(accountID, region, scanner)
+	return ok && count == 0
+}
+
+// Total returns the sum of all known resource counts
+func (rc *ResourceCounts) Total() int {
+	rc.mu.RLock()
+	defer rc.mu.RUnlock()
+	total := 0
+	for _, count := range rc.counts {
+		total += count
+	}
+	return total
+}
+
+// resourceTypeFromARN extracts the "service:resource" type from a resource ARN, e.g.
+// arn:aws:ec2:us-east-1:123456789012:volume/vol-123 -> ec2:volume
+// arn:aws:rds:us-east-1:123456789012:db:mydb         -> rds:db
+func resourceTypeFromARN(arn string) string {
+	parts := strings.SplitN(arn, ":", 6)
+	if len(parts) < 6 {
+		return ""
+	}
+	resource := parts[5]
+	if i := strings.IndexAny(resource, "/:"); i >= 0 {
+		resource = resource[:i]
+	}
+	return parts[2] + ":" + resource
+}
+
+// CountResources uses the Resource Groups Tagging API to count the resources in a region
+// for each of the given scanners. Only scanners with a known resource type mapping are
+// included in the returned map.
+func CountResources(sess *session.Session, region string, scanners []string) (map[string]int, error) {
+	typeToScanners := make(map[string][]string)
+	var filters []*string
+	for _, scanner := range scanners {
+		for _, resourceType := range scannerResourceTypes[scanner] {
+			if _, exists := typeToScanners[resourceType]; !exists {
+				filters = append(filters, aws.String(resourceType))
+			}
+			typeToScanners[resourceType] = append(typeToScanners[resourceType], scanner)
+		}
+	}
+
+	counts := make(map[string]int)
+	if len(filters) == 0 {
+		return counts, nil
+	}
+
+	// Initialize every mapped scanner so that zero counts are reported as known
+	for _, mapped := range typeToScanners {
+		for _, scanner := range mapped {
+			counts[scanner] = 0
+		}
+	}
+
+	regionSession, err := GetSessionInRegion(sess, region)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create regional session: %w", err)
+	}
+
+	svc := resourcegroupstaggingapi.New(regionSession)
+	input := &resourcegroupstaggingapi.GetResourcesInput{
+		ResourceTypeFilters: filters,
+		ResourcesPerPage:    aws.Int64(100),
+	}
+
+	err = svc.GetResourcesPages(input, func(page *resourcegroupstaggingapi.GetResourcesOutput, lastPage bool) bool {
+		for _, mapping := range page.ResourceTagMappingList {
+			resourceType := resourceTypeFromARN(aws.StringValue(mapping.ResourceARN))
+			for _, scanner := range typeToScanners[resourceType] {
+				counts[scanner]++
+			}
+		}
+		return !lastPage
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get resources: %w", err)
+	}
+
+	logging.Debug("Counted resources via tagging API", map[string]interface{}{
+		"region": region,
+		"counts": counts,
+	})
+
+	return counts, nil
+}
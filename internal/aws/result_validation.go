@@ -0,0 +1,38 @@
+package aws
+
+// ValidateResult returns the problems of a scan result missing the fields reports rely on: the
+// resource ID and type, the account ID, the region detail and a cost, which is either a cost
+// breakdown or a NoCost marker. It is meant for results with the account and region filled
+// in by the scan.
+func ValidateResult(result ScanResult) []string {
+	var problems []string
+	if result.ResourceID == "" {
+		problems = append(problems, "missing resource ID")
+	}
+	if result.ResourceType == "" {
+		problems = append(problems, "missing resource type")
+	}
+	if result.AccountID == "" {
+		problems = append(problems, "missing account ID")
+	}
+	if region, _ := result.Details["region"].(string); region == "" {
+		problems = append(problems, "missing region detail")
+	}
+	if !hasCost(result.Cost) {
+		problems = append(problems, "missing cost or no-cost marker")
+	}
+	return problems
+}
+
+// hasCost returns whether the Cost of a result holds a cost breakdown or a NoCost marker
+func hasCost(cost map[string]interface{}) bool {
+	if reason, ok := cost[NoCostKey].(string); ok && reason != "" {
+		return true
+	}
+	for _, value := range cost {
+		if breakdown, ok := value.(*CostBreakdown); ok && breakdown != nil {
+			return true
+		}
+	}
+	return false
+}
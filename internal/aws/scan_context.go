@@ -0,0 +1,91 @@
+package aws
+
+import (
+	"sync"
+)
+
+// ScanContext caches intermediate data shared by the scanners of an account and region, such
+// as the AMIs owned by the account, so scanners needing the same data make the API calls once.
+// Scanners run concurrently and in no particular order, so data is looked up with
+// LoadOrCompute: the first scanner needing it fetches it, and the others wait for and reuse
+// the result. A nil ScanContext caches nothing.
+type ScanContext struct {
+	entries sync.Map // Key -> *scanContextEntry
+}
+
+// scanContextEntry holds a value of a scan context, guarded while it is computed
+type scanContextEntry struct {
+	mu       sync.Mutex
+	computed bool
+	value    interface{}
+}
+
+// Load returns the value published under a key
+func (c *ScanContext) Load(key string) (interface{}, bool) {
+	if c == nil {
+		return nil, false
+	}
+	e, ok := c.entries.Load(key)
+	if !ok {
+		return nil, false
+	}
+	entry := e.(*scanContextEntry)
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	return entry.value, entry.computed
+}
+
+// Store publishes a value under a key, replacing any previous value
+func (c *ScanContext) Store(key string, value interface{}) {
+	if c == nil {
+		return
+	}
+	e, _ := c.entries.LoadOrStore(key, &scanContextEntry{})
+	entry := e.(*scanContextEntry)
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	entry.value, entry.computed = value, true
+}
+
+// LoadOrCompute returns the value published under a key, or computes and publishes it.
+// Concurrent callers of the same key wait for a single computation. Errors are returned to
+// the computing caller only and not cached, so a later caller computes the value again.
+func (c *ScanContext) LoadOrCompute(key string, compute func() (interface{}, error)) (interface{}, error) {
+	if c == nil {
+		return compute()
+	}
+	e, _ := c.entries.LoadOrStore(key, &scanContextEntry{})
+	entry := e.(*scanContextEntry)
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	if entry.computed {
+		return entry.value, nil
+	}
+
+	value, err := compute()
+	if err != nil {
+		return nil, err
+	}
+	entry.value, entry.computed = value, true
+	return value, nil
+}
+
+// ScanContexts holds the scan context of each account and region of a scan run
+type ScanContexts struct {
+	contexts sync.Map // "account/region" -> *ScanContext
+}
+
+// NewScanContexts creates the scan contexts of a scan run
+func NewScanContexts() *ScanContexts {
+	return &ScanContexts{}
+}
+
+// Get returns the scan context of an account and region. Nil scan contexts return a nil
+// context, which caches nothing.
+func (c *ScanContexts) Get(accountID, region string) *ScanContext {
+	if c == nil {
+		return nil
+	}
+	scanContext, _ := c.contexts.LoadOrStore(accountID+"/"+region, &ScanContext{})
+	return scanContext.(*ScanContext)
+}
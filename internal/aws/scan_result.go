@@ -7,11 +7,64 @@ type ScanResult struct {
 	ResourceID   string                 `json:"resource_id"`
 	AccountID    string                 `json:"account_id"`
 	AccountName  string                 `json:"account_name"`
+	AccountTeam  string                 `json:"account_team,omitempty"`
+	AccountEnv   string                 `json:"account_environment,omitempty"`
+	Management   bool                   `json:"management_account,omitempty"` // Found in the management account of the organization
 	Reason       string                 `json:"reason"`
 	Tags         map[string]string      `json:"tags"`
 	Details      map[string]interface{} `json:"details"`
 	Cost         map[string]interface{} `json:"cost"`
+	Annotation   *Annotation            `json:"annotation,omitempty"`
+}
+
+// NoCostKey is the Cost key marking a result whose resource has no cost estimate, with the
+// reason there is none
+const NoCostKey = "no_cost"
+
+// Reasons results have no cost estimate
+const (
+	NoCostFree         = "free"          // The resource is not billed
+	NoCostNotEstimated = "not estimated" // The resource is billed by usage the scanner does not estimate
+	NoCostCountedOnce  = "counted once"  // The cost is counted by another scanner's finding for the same resource
+)
+
+// NoCost returns the Cost of a result without a cost estimate, e.g. because the resource is
+// free or its cost depends on usage the scanner does not estimate
+func NoCost(reason string) map[string]interface{} {
+	return map[string]interface{}{NoCostKey: reason}
+}
+
+// LowConfidence is recorded as confidence in the details of results reported without the
+// evidence that the resource is unused, e.g. because its metrics could not be retrieved
+const LowConfidence = "low"
+
+// LowConfidence reports whether the result was reported without the evidence that the
+// resource is unused, including results whose metrics timed out or were throttled and carry
+// a metrics_status. Its cost is an upper bound and is not counted in savings totals.
+func (r ScanResult) LowConfidence() bool {
+	confidence, _ := r.Details["confidence"].(string)
+	status, _ := r.Details["metrics_status"].(string)
+	return confidence == LowConfidence || status != ""
+}
+
+// SavingsBreakdown returns the cost a result counts toward savings totals: its total cost, or
+// nil for results without a cost estimate and low confidence results, whose resources may
+// still be in use
+func (r ScanResult) SavingsBreakdown() *CostBreakdown {
+	if r.LowConfidence() {
+		return nil
+	}
+	costs, _ := r.Cost["total"].(*CostBreakdown)
+	return costs
 }
 
 // ScanResults is a slice of ScanResult
 type ScanResults []ScanResult
+
+// Annotation is a reviewer's triage decision about a finding, carried over between scans
+type Annotation struct {
+	Status    string `json:"status" yaml:"status"`                     // acknowledged, planned_removal or false_positive
+	Note      string `json:"note,omitempty" yaml:"note,omitempty"`     // Free-form reviewer note
+	Author    string `json:"author,omitempty" yaml:"author,omitempty"` // Reviewer who made the decision
+	UpdatedAt string `json:"updated_at,omitempty" yaml:"updated_at,omitempty"`
+}
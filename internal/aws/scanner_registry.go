@@ -2,6 +2,7 @@ package aws
 
 import (
 	"fmt"
+	"slices"
 	"sort"
 	"sync"
 
@@ -14,6 +15,39 @@ type ScanOptions struct {
 	DaysUnused int              // Number of days a resource must be unused to be reported
 	Session    *session.Session // AWS session to use for scanning (already configured with necessary role chain)
 	AccountID  string           // AWS Account ID for the session
+
+	KnownAccountIDs []string // IDs of all accounts being scanned, used to recognize cross-account sharing
+	Regions         []string // All regions being scanned, used by scanners comparing resources across regions
+
+	ExcludeASGInstances  bool // Skip EC2 instances managed by Auto Scaling groups
+	ExcludeSpotInstances bool // Skip EC2 Spot instances, including Spot fleet members
+	ExcludeWarmInstances bool // Skip EC2 instances in Auto Scaling warm pools and hibernated instances
+
+	BusinessHours *BusinessHours // Business hours window for utilization analysis (nil disables)
+
+	MarketplaceRates map[string]float64 // Hourly Marketplace software charges keyed by product code
+
+	IAMLastAccessed bool // Confirm unused IAM roles with IAM access advisor service last accessed data
+
+	EMRIdleHours int // Hours an EMR cluster may wait without steps before it is reported
+
+	IncludeMetricSamples bool // Keep the raw CloudWatch datapoints of findings in their details
+
+	EdgeZones bool // Price EC2 instances and EBS volumes in opted-in Local Zones and Wavelength Zones by their zone
+
+	Contexts *ScanContexts // Intermediate data shared between the scanners of the scan run (nil shares nothing)
+
+	Scanners []string // Argument names of the scanners of the scan run, so resources are left to more specific scanners
+}
+
+// RunsScanner returns whether the scanner with an argument name is part of the scan run
+func (o ScanOptions) RunsScanner(argumentName string) bool {
+	return slices.Contains(o.Scanners, argumentName)
+}
+
+// ScanContext returns the scan context shared by the scanners of the account and region
+func (o ScanOptions) ScanContext() *ScanContext {
+	return o.Contexts.Get(o.AccountID, o.Region)
 }
 
 // Scanner interface defines methods that must be implemented by resource scanners
@@ -23,9 +57,23 @@ type Scanner interface {
 	Scan(opts ScanOptions) (ScanResults, error)
 }
 
+// ResourceProber is optionally implemented by scanners that can cheaply check whether a
+// region contains any resources they would inspect, allowing empty regions to be skipped
+type ResourceProber interface {
+	HasResources(opts ScanOptions) (bool, error)
+}
+
+// MultiRegionScanner is optionally implemented by scanners that compare resources across
+// regions. They run once per account and partition, in the partition's home region, and
+// inspect the scanned regions of the partition themselves.
+type MultiRegionScanner interface {
+	MultiRegion() bool
+}
+
 // ScannerRegistry manages available scanners
 type ScannerRegistry struct {
 	scanners map[string]Scanner
+	aliases  map[string]string // Alias to argument name
 	mu       sync.RWMutex
 }
 
@@ -33,6 +81,7 @@ type ScannerRegistry struct {
 func NewScannerRegistry() *ScannerRegistry {
 	return &ScannerRegistry{
 		scanners: make(map[string]Scanner),
+		aliases:  make(map[string]string),
 	}
 }
 
@@ -43,11 +92,22 @@ func (r *ScannerRegistry) RegisterScanner(scanner Scanner) {
 	r.scanners[scanner.ArgumentName()] = scanner
 }
 
-// GetScanner retrieves a scanner by argument name
+// RegisterAlias registers another name a scanner can be selected by. Aliases are not
+// listed by ListScanners.
+func (r *ScannerRegistry) RegisterAlias(alias, argumentName string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.aliases[alias] = argumentName
+}
+
+// GetScanner retrieves a scanner by argument name or alias
 func (r *ScannerRegistry) GetScanner(argumentName string) (Scanner, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
+	if name, ok := r.aliases[argumentName]; ok {
+		argumentName = name
+	}
 	scanner, ok := r.scanners[argumentName]
 	if !ok {
 		return nil, fmt.Errorf("scanner %s not found", argumentName)
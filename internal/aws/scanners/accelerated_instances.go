@@ -0,0 +1,383 @@
+package scanners
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	awslib "cloudsift/internal/aws"
+	"cloudsift/internal/aws/utils"
+	"cloudsift/internal/logging"
+	"cloudsift/internal/worker"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// AcceleratedInstanceScanner scans for idle or long-stopped EC2 instances carrying GPUs,
+// Inferentia/Trainium chips, FPGAs or attached Elastic Inference accelerators. The accelerator
+// premium dominates the cost of these instances. The EC2 instance scanner does not report
+// them when this scanner runs, so they are not counted twice.
+type AcceleratedInstanceScanner struct {
+	ec2Scanner EC2InstanceScanner
+}
+
+func init() {
+	awslib.DefaultRegistry.RegisterScanner(&AcceleratedInstanceScanner{})
+}
+
+// ArgumentName implements Scanner interface
+func (s *AcceleratedInstanceScanner) ArgumentName() string {
+	return "accelerated-instances"
+}
+
+// Label implements Scanner interface
+func (s *AcceleratedInstanceScanner) Label() string {
+	return "Accelerated Instances"
+}
+
+// RemediationTemplate implements Remediator interface. Accelerated instances are stopped
+// rather than terminated, keeping their volumes.
+func (s *AcceleratedInstanceScanner) RemediationTemplate() string {
+	return "aws ec2 stop-instances --instance-ids {{.ResourceID}} --region {{.Region}}"
+}
+
+// acceleratedFamilies are the instance families whose instance types carry accelerators
+var acceleratedFamilies = []string{"p", "g", "inf", "trn", "dl", "f", "vt"}
+
+// isAcceleratedFamily reports whether an instance type belongs to an accelerated family
+func isAcceleratedFamily(instanceType string) bool {
+	family := strings.SplitN(instanceType, ".", 2)[0]
+	// The family name ends before its generation number, e.g. "inf" in "inf2"
+	if i := strings.IndexFunc(family, func(r rune) bool { return r >= '0' && r <= '9' }); i > 0 {
+		family = family[:i]
+	}
+	for _, accelerated := range acceleratedFamilies {
+		if family == accelerated {
+			return true
+		}
+	}
+	return false
+}
+
+// hasAttachedAccelerators reports whether an instance has Elastic Inference accelerators or
+// Elastic GPUs attached
+func hasAttachedAccelerators(instance *ec2.Instance) bool {
+	return len(instance.ElasticInferenceAcceleratorAssociations) > 0 || len(instance.ElasticGpuAssociations) > 0
+}
+
+// reportsAcceleratedInstance returns whether the scanner reports an instance if it is idle
+// or long stopped, given the accelerators of instance types, so the EC2 instance scanner
+// leaves it to it
+func reportsAcceleratedInstance(instance *ec2.Instance, accelerators map[string][]string) bool {
+	state := aws.StringValue(instance.State.Name)
+	if state != ec2.InstanceStateNameRunning && state != ec2.InstanceStateNameStopped {
+		return false
+	}
+	return len(accelerators[aws.StringValue(instance.InstanceType)]) > 0 || hasAttachedAccelerators(instance)
+}
+
+// HasResources implements ResourceProber interface
+func (s *AcceleratedInstanceScanner) HasResources(opts awslib.ScanOptions) (bool, error) {
+	sess, err := awslib.GetSessionInRegion(opts.Session, opts.Region)
+	if err != nil {
+		return false, fmt.Errorf("failed to create regional session: %w", err)
+	}
+
+	found := false
+	err = ec2.New(sess).DescribeInstancesPages(&ec2.DescribeInstancesInput{
+		MaxResults: aws.Int64(1000),
+	}, func(page *ec2.DescribeInstancesOutput, lastPage bool) bool {
+		for _, reservation := range page.Reservations {
+			for _, instance := range reservation.Instances {
+				if isAcceleratedFamily(aws.StringValue(instance.InstanceType)) || hasAttachedAccelerators(instance) {
+					found = true
+					return false
+				}
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to describe instances: %w", err)
+	}
+	return found, nil
+}
+
+// describeAccelerators returns the accelerators of instance types, e.g. "4 x NVIDIA A10G",
+// keyed by instance type. Instance types without accelerators are omitted.
+func describeAccelerators(ec2Client *ec2.EC2, instanceTypes []string) (map[string][]string, error) {
+	accelerators := make(map[string][]string)
+	// DescribeInstanceTypes accepts up to 100 instance types per request
+	for start := 0; start < len(instanceTypes); start += 100 {
+		end := start + 100
+		if end > len(instanceTypes) {
+			end = len(instanceTypes)
+		}
+		err := ec2Client.DescribeInstanceTypesPages(&ec2.DescribeInstanceTypesInput{
+			InstanceTypes: aws.StringSlice(instanceTypes[start:end]),
+		}, func(page *ec2.DescribeInstanceTypesOutput, lastPage bool) bool {
+			for _, info := range page.InstanceTypes {
+				var devices []string
+				add := func(count *int64, manufacturer, name *string, kind string) {
+					device := strings.TrimSpace(aws.StringValue(manufacturer) + " " + aws.StringValue(name))
+					devices = append(devices, fmt.Sprintf("%d x %s %s", aws.Int64Value(count), device, kind))
+				}
+				if info.GpuInfo != nil {
+					for _, gpu := range info.GpuInfo.Gpus {
+						add(gpu.Count, gpu.Manufacturer, gpu.Name, "GPU")
+					}
+				}
+				if info.InferenceAcceleratorInfo != nil {
+					for _, accelerator := range info.InferenceAcceleratorInfo.Accelerators {
+						add(accelerator.Count, accelerator.Manufacturer, accelerator.Name, "inference accelerator")
+					}
+				}
+				if info.NeuronInfo != nil {
+					for _, device := range info.NeuronInfo.NeuronDevices {
+						add(device.Count, aws.String("AWS"), device.Name, "Neuron device")
+					}
+				}
+				if info.FpgaInfo != nil {
+					for _, fpga := range info.FpgaInfo.Fpgas {
+						add(fpga.Count, fpga.Manufacturer, fpga.Name, "FPGA")
+					}
+				}
+				if len(devices) > 0 {
+					accelerators[aws.StringValue(info.InstanceType)] = devices
+				}
+			}
+			return true
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe instance types: %w", err)
+		}
+	}
+	return accelerators, nil
+}
+
+// stoppedSince returns the time an instance was stopped from its state transition reason,
+// e.g. "User initiated (2024-02-27 11:51:43 GMT)"
+func stoppedSince(instance *ec2.Instance) (time.Time, bool) {
+	reason := aws.StringValue(instance.StateTransitionReason)
+	if !strings.Contains(reason, "(") || !strings.Contains(reason, ")") {
+		return time.Time{}, false
+	}
+	timeStr := strings.TrimSpace(strings.Split(strings.Split(reason, "(")[1], ")")[0])
+	stopTime, err := time.Parse("2006-01-02 15:04:05 MST", timeStr)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return stopTime, true
+}
+
+// Scan implements Scanner interface
+func (s *AcceleratedInstanceScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, error) {
+	sess, err := awslib.GetSessionInRegion(opts.Session, opts.Region)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create regional session: %w", err)
+	}
+
+	logging.Info("Starting accelerated instance scan", map[string]interface{}{
+		"account_id": opts.AccountID,
+		"region":     opts.Region,
+	})
+
+	clients := utils.CreateServiceClients(sess)
+	ec2Client := ec2.New(sess)
+
+	input := &ec2.DescribeInstancesInput{
+		Filters: []*ec2.Filter{{
+			Name:   aws.String("instance-state-name"),
+			Values: aws.StringSlice([]string{ec2.InstanceStateNameRunning, ec2.InstanceStateNameStopped}),
+		}},
+		MaxResults: aws.Int64(1000),
+	}
+
+	var instances []*ec2.Instance
+	instanceTypes := make(map[string]bool)
+	err = ec2Client.DescribeInstancesPages(input, func(page *ec2.DescribeInstancesOutput, lastPage bool) bool {
+		for _, reservation := range page.Reservations {
+			for _, instance := range reservation.Instances {
+				instances = append(instances, instance)
+				instanceTypes[aws.StringValue(instance.InstanceType)] = true
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe instances: %w", err)
+	}
+
+	typeNames := make([]string, 0, len(instanceTypes))
+	for instanceType := range instanceTypes {
+		typeNames = append(typeNames, instanceType)
+	}
+	accelerators, err := describeAccelerators(ec2Client, typeNames)
+	if err != nil {
+		return nil, err
+	}
+
+	var results awslib.ScanResults
+	var resultsMutex sync.Mutex
+	var tasks []worker.Task
+	endTime := time.Now().UTC()
+	metricStartTime := endTime.Add(-time.Duration(opts.DaysUnused) * 24 * time.Hour)
+
+	for _, instance := range instances {
+		instance := instance
+		if !reportsAcceleratedInstance(instance, accelerators) {
+			continue
+		}
+		instanceType := aws.StringValue(instance.InstanceType)
+		devices := accelerators[instanceType]
+
+		tasks = append(tasks, func(ctx context.Context) error {
+			instanceID := aws.StringValue(instance.InstanceId)
+			state := aws.StringValue(instance.State.Name)
+
+			// Skip elastic workloads that scale by design when requested
+			if reason := s.ec2Scanner.excludedReason(instance, opts); reason != "" {
+				logging.Debug("Skipping excluded instance", map[string]interface{}{
+					"instance_id": instanceID,
+					"reason":      reason,
+				})
+				return nil
+			}
+
+			var reasons []string
+			recorder := utils.NewMetricRecorder(opts.IncludeMetricSamples)
+			if state == ec2.InstanceStateNameStopped {
+				stopTime, ok := stoppedSince(instance)
+				if !ok || time.Since(stopTime).Hours()/24 < float64(opts.DaysUnused) {
+					return nil
+				}
+				reasons = append(reasons, fmt.Sprintf("Instance has been stopped for %s", utils.FormatTimeDifference(time.Now(), &stopTime)))
+			} else {
+				// Only analyze instances that are old enough based on days_unused
+				if time.Since(*instance.LaunchTime).Hours()/24 < float64(opts.DaysUnused) {
+					return nil
+				}
+				usageReasons, err := s.ec2Scanner.analyzeInstanceUsage(clients.CloudWatch, instance, metricStartTime, endTime, opts.DaysUnused, recorder)
+				if err != nil {
+					logging.Error("Failed to analyze instance usage", err, map[string]interface{}{
+						"instance_id": instanceID,
+					})
+					return nil
+				}
+				if len(usageReasons) == 0 {
+					return nil
+				}
+				reasons = append(reasons, usageReasons...)
+			}
+
+			var eiAccelerators []string
+			for _, association := range instance.ElasticInferenceAcceleratorAssociations {
+				eiAccelerators = append(eiAccelerators, aws.StringValue(association.ElasticInferenceAcceleratorArn))
+			}
+			var elasticGpus []string
+			for _, association := range instance.ElasticGpuAssociations {
+				elasticGpus = append(elasticGpus, aws.StringValue(association.ElasticGpuId))
+			}
+
+			if len(devices) > 0 {
+				reasons = append(reasons, fmt.Sprintf("Instance type %s carries %s.", instanceType, strings.Join(devices, ", ")))
+			}
+			if len(eiAccelerators) > 0 {
+				reasons = append(reasons, fmt.Sprintf("%d Elastic Inference accelerator(s) attached, billed separately from the instance.", len(eiAccelerators)))
+			}
+			if len(elasticGpus) > 0 {
+				reasons = append(reasons, fmt.Sprintf("%d Elastic GPU(s) attached, billed separately from the instance.", len(elasticGpus)))
+			}
+
+			name := instanceID
+			tags := make(map[string]string)
+			for _, tag := range instance.Tags {
+				tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+				if aws.StringValue(tag.Key) == "Name" {
+					name = aws.StringValue(tag.Value)
+				}
+			}
+
+			hoursRunning := time.Since(*instance.LaunchTime).Hours()
+			details := map[string]interface{}{
+				"instance_id":   instanceID,
+				"instance_type": instanceType,
+				"launch_time":   instance.LaunchTime.Format(time.RFC3339),
+				"hours_running": hoursRunning,
+				"state":         state,
+				"region":        opts.Region,
+				"tags":          tags,
+			}
+			if createdAt, ok := instanceCreatedAt(instance); ok {
+				details["created_at"] = createdAt.Format(time.RFC3339)
+			}
+			if len(devices) > 0 {
+				details["accelerators"] = devices
+			}
+			if len(eiAccelerators) > 0 {
+				details["elastic_inference_accelerators"] = eiAccelerators
+			}
+			if len(elasticGpus) > 0 {
+				details["elastic_gpus"] = elasticGpus
+			}
+			recorder.AddTo(details)
+
+			var costDetails map[string]interface{}
+			if awslib.DefaultCostEstimator != nil {
+				costs, err := awslib.DefaultCostEstimator.CalculateCost(awslib.ResourceCostConfig{
+					ResourceType: "EC2",
+					ResourceSize: instanceType,
+					Region:       opts.Region,
+					CreationTime: *instance.LaunchTime,
+				})
+				if err != nil {
+					logging.Error("Failed to calculate EC2 instance costs", err, map[string]interface{}{
+						"instance_id": instanceID,
+					})
+				} else if costs != nil {
+					if state == ec2.InstanceStateNameStopped {
+						// Stopped instances are not billed for compute; keep the rate paid
+						// once the instance is started again
+						details["hourly_rate_when_running"] = costs.HourlyRate
+					} else {
+						costDetails = map[string]interface{}{
+							"total": costs,
+						}
+					}
+				}
+			}
+
+			resultsMutex.Lock()
+			results = append(results, awslib.ScanResult{
+				ResourceType: s.Label(),
+				ResourceID:   instanceID,
+				ResourceName: name,
+				Details:      details,
+				Cost:         costDetails,
+				Reason:       strings.Join(reasons, "\n"),
+			})
+			resultsMutex.Unlock()
+
+			logging.Info("Found unused accelerated instance", map[string]interface{}{
+				"instance_id":   instanceID,
+				"name":          name,
+				"instance_type": instanceType,
+				"state":         state,
+			})
+			return nil
+		})
+	}
+
+	worker.GetSharedPool().ExecuteTasks(tasks)
+
+	logging.Info("Completed accelerated instance scan", map[string]interface{}{
+		"account_id":       opts.AccountID,
+		"region":           opts.Region,
+		"unused_instances": len(results),
+	})
+
+	return results, nil
+}
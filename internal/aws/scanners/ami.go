@@ -4,14 +4,12 @@ import (
 	"context"
 	"fmt"
 	"strings"
-	"sync"
 	"time"
 
 	awslib "cloudsift/internal/aws"
 	"cloudsift/internal/aws/utils"
 	"cloudsift/internal/config"
 	"cloudsift/internal/logging"
-	"cloudsift/internal/worker"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ec2"
@@ -34,6 +32,48 @@ func (s *AMIScanner) Label() string {
 	return "AMIs"
 }
 
+// RemediationTemplate implements Remediator interface. Deregistering the AMI leaves its
+// snapshots, which are reported by the snapshot scanner.
+func (s *AMIScanner) RemediationTemplate() string {
+	return "aws ec2 deregister-image --image-id {{.ResourceID}} --region {{.Region}}"
+}
+
+// ownedImagesKey is the scan context key of the AMIs owned by the account in the region
+const ownedImagesKey = "ec2/owned-images"
+
+// ownedImages returns the AMIs owned by the account in the region of the EC2 client. They are
+// described once per scan context and shared by the AMI and snapshot scanners, so callers
+// must not modify them.
+func ownedImages(ctx context.Context, scanContext *awslib.ScanContext, ec2Client *ec2.EC2) ([]*ec2.Image, error) {
+	images, err := scanContext.LoadOrCompute(ownedImagesKey, func() (interface{}, error) {
+		output, err := ec2Client.DescribeImagesWithContext(ctx, &ec2.DescribeImagesInput{
+			Owners: []*string{aws.String("self")},
+		})
+		if err != nil {
+			return nil, err
+		}
+		return output.Images, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return images.([]*ec2.Image), nil
+}
+
+// imageSnapshotReferences returns the IDs of the AMIs referencing each snapshot
+func imageSnapshotReferences(images []*ec2.Image) map[string][]string {
+	references := make(map[string][]string)
+	for _, image := range images {
+		for _, blockDevice := range image.BlockDeviceMappings {
+			if blockDevice.Ebs != nil && blockDevice.Ebs.SnapshotId != nil {
+				snapshotID := aws.StringValue(blockDevice.Ebs.SnapshotId)
+				references[snapshotID] = append(references[snapshotID], aws.StringValue(image.ImageId))
+			}
+		}
+	}
+	return references
+}
+
 // amiTask represents a single AMI to analyze
 type amiTask struct {
 	ami         *ec2.Image
@@ -56,11 +96,8 @@ func (t *amiTask) processAMI(ctx context.Context) (*awslib.ScanResult, error) {
 		"ami_name": amiName,
 	})
 
-	// Check if AMI is in use by any EC2 instances
-	if err := t.rateLimiter.Wait(ctx); err != nil {
-		return nil, fmt.Errorf("rate limit wait error: %w", err)
-	}
-
+	// Check if AMI is in use by any EC2 instances, ForEachResourceParallel waited for the
+	// rate limiter
 	instances, err := t.ec2Client.DescribeInstancesWithContext(ctx, &ec2.DescribeInstancesInput{
 		Filters: []*ec2.Filter{
 			{
@@ -264,31 +301,14 @@ func (s *AMIScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, error) {
 	}
 	rateLimiter := awslib.GetGlobalRegistry().GetRateLimiter(rateLimiterKey, rateConfig)
 
-	// Get the shared worker pool
-	pool := worker.GetSharedPool()
-
-	// Channel for errors
-	errorChan := make(chan error, 1)
-
-	// WaitGroup to track all submitted tasks
-	var wg sync.WaitGroup
-
-	// Process AMIs in chunks to avoid memory issues
-	var results awslib.ScanResults
-	var resultsMutex sync.Mutex
-
 	ctx := context.Background()
 
-	// Describe AMIs owned by this account
-	input := &ec2.DescribeImagesInput{
-		Owners: []*string{aws.String("self")},
-	}
-
+	// Describe AMIs owned by this account, unless another scanner already did
 	if err := rateLimiter.Wait(ctx); err != nil {
 		return nil, fmt.Errorf("rate limit wait error: %w", err)
 	}
 
-	images, err := ec2Client.DescribeImagesWithContext(ctx, input)
+	images, err := ownedImages(ctx, opts.ScanContext(), ec2Client)
 	if err != nil {
 		if strings.Contains(err.Error(), "Throttling:") {
 			rateLimiter.OnFailure()
@@ -298,9 +318,9 @@ func (s *AMIScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, error) {
 	}
 	rateLimiter.OnSuccess()
 
-	// Process each AMI
-	for _, ami := range images.Images {
-		wg.Add(1)
+	// Process the AMIs in parallel
+	now := time.Now()
+	results, err := utils.ForEachResourceParallel(images, rateLimiter, func(ctx context.Context, ami *ec2.Image) (*awslib.ScanResult, error) {
 		task := &amiTask{
 			ami:         ami,
 			ec2Client:   ec2Client,
@@ -308,47 +328,13 @@ func (s *AMIScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, error) {
 			region:      opts.Region,
 			scanner:     s,
 			opts:        opts,
-			now:         time.Now(),
+			now:         now,
 			rateLimiter: rateLimiter,
 		}
-
-		// Submit task to worker pool
-		pool.Submit(func(ctx context.Context) error {
-			defer wg.Done()
-
-			result, err := task.processAMI(ctx)
-			if err != nil {
-				select {
-				case errorChan <- err:
-				default:
-					logging.Error("Failed to process AMI", err, map[string]interface{}{
-						"ami_id":  aws.StringValue(task.ami.ImageId),
-						"account": opts.AccountID,
-						"region":  opts.Region,
-					})
-				}
-				return err
-			}
-
-			if result != nil {
-				resultsMutex.Lock()
-				results = append(results, *result)
-				resultsMutex.Unlock()
-			}
-
-			return nil
-		})
-	}
-
-	// Wait for all tasks to complete
-	wg.Wait()
-
-	// Check for any errors
-	select {
-	case err := <-errorChan:
+		return task.processAMI(ctx)
+	})
+	if err != nil {
 		return nil, fmt.Errorf("error processing AMIs: %w", err)
-	default:
 	}
-
 	return results, nil
 }
@@ -0,0 +1,368 @@
+package scanners
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	awslib "cloudsift/internal/aws"
+	"cloudsift/internal/logging"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// AMICopyScanner finds AMIs copied across regions that no instance launches from, while
+// other copies of the same image are launched elsewhere. Each redundant copy keeps a full
+// set of EBS snapshots in its region. The AMI scanner reports the same copies as unused, so
+// when it runs, copies are reported for their copy relationship only and their snapshot cost
+// is counted once, by the AMI scanner.
+type AMICopyScanner struct{}
+
+func init() {
+	awslib.DefaultRegistry.RegisterScanner(&AMICopyScanner{})
+}
+
+// ArgumentName implements Scanner interface
+func (s *AMICopyScanner) ArgumentName() string {
+	return "ami-copies"
+}
+
+// Label implements Scanner interface
+func (s *AMICopyScanner) Label() string {
+	return "AMI Copies"
+}
+
+// RemediationTemplate implements Remediator interface. Redundant copies are deregistered in
+// the region they were copied to.
+func (s *AMICopyScanner) RemediationTemplate() string {
+	return "aws ec2 deregister-image --image-id {{.ResourceID}} --region {{.Region}}"
+}
+
+// MultiRegion implements MultiRegionScanner interface
+func (s *AMICopyScanner) MultiRegion() bool {
+	return true
+}
+
+// copiedImagePattern matches the description CopyImage gives copies when none is provided,
+// e.g. "[Copied ami-0abc from us-east-1] ..."
+var copiedImagePattern = regexp.MustCompile(`^\[Copied (ami-[0-9a-f]+) from ([a-z0-9-]+)\]`)
+
+// regionalImage is an AMI owned by the account in one of the scanned regions
+type regionalImage struct {
+	image        *ec2.Image
+	region       string
+	sourceID     string // Image the AMI was copied from, if it is a copy
+	sourceRegion string
+}
+
+// imageSource returns the image and region an AMI was copied from, if it is a copy
+func imageSource(image *ec2.Image) (string, string) {
+	match := copiedImagePattern.FindStringSubmatch(aws.StringValue(image.Description))
+	if match == nil {
+		return "", ""
+	}
+	return match[1], match[2]
+}
+
+// originImageID follows the copy chain of an AMI back to the first image still owned
+func originImageID(images map[string]*regionalImage, imageID string) string {
+	seen := make(map[string]bool)
+	for !seen[imageID] {
+		seen[imageID] = true
+		image, ok := images[imageID]
+		if !ok || image.sourceID == "" {
+			return imageID
+		}
+		if _, ok := images[image.sourceID]; !ok {
+			return imageID
+		}
+		imageID = image.sourceID
+	}
+	return imageID
+}
+
+// partitionRegions returns the scanned regions in the partition of a region
+func partitionRegions(opts awslib.ScanOptions) []string {
+	partition := awslib.RegionPartition(opts.Region)
+	var regions []string
+	for _, region := range opts.Regions {
+		if awslib.RegionPartition(region) == partition {
+			regions = append(regions, region)
+		}
+	}
+	if len(regions) == 0 {
+		regions = []string{opts.Region}
+	}
+	sort.Strings(regions)
+	return regions
+}
+
+// Scan implements Scanner interface
+func (s *AMICopyScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, error) {
+	regions := partitionRegions(opts)
+	logging.Info("Starting AMI copy scan", map[string]interface{}{
+		"account_id": opts.AccountID,
+		"regions":    regions,
+	})
+
+	images := make(map[string]*regionalImage)
+	launchedImages := make(map[string]bool)
+	var mu sync.Mutex
+	var scanErr error
+
+	// List the account's images and the images its instances were launched from per region.
+	// The scanner already runs on the shared worker pool, so regions are listed on their own
+	// goroutines rather than pool tasks it would wait for.
+	var wg sync.WaitGroup
+	for _, region := range regions {
+		region := region
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			regionImages, launched, err := s.listRegion(opts, region)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if scanErr == nil {
+					scanErr = fmt.Errorf("failed to list AMIs in %s: %w", region, err)
+				}
+				return
+			}
+			for _, image := range regionImages {
+				images[aws.StringValue(image.image.ImageId)] = image
+			}
+			for imageID := range launched {
+				launchedImages[imageID] = true
+			}
+		}()
+	}
+	wg.Wait()
+	if scanErr != nil {
+		return nil, scanErr
+	}
+
+	// Group copies of the same image by the image they originate from
+	groups := make(map[string][]*regionalImage)
+	for imageID := range images {
+		origin := originImageID(images, imageID)
+		groups[origin] = append(groups[origin], images[imageID])
+	}
+
+	now := time.Now()
+	var results awslib.ScanResults
+	for origin, group := range groups {
+		if len(group) < 2 {
+			continue
+		}
+		sort.Slice(group, func(i, j int) bool {
+			return group[i].region < group[j].region
+		})
+
+		copyRegions := make(map[string]bool)
+		launchRegions := make(map[string]bool)
+		for _, image := range group {
+			copyRegions[image.region] = true
+			if s.launched(image, launchedImages, now, opts.DaysUnused) {
+				launchRegions[image.region] = true
+			}
+		}
+		// Images launched nowhere are reported by the AMI scanner
+		if len(launchRegions) == 0 || len(copyRegions) <= len(launchRegions) {
+			continue
+		}
+
+		for _, image := range group {
+			imageID := aws.StringValue(image.image.ImageId)
+			if imageID == origin || launchRegions[image.region] {
+				continue
+			}
+			creationDate, err := time.Parse(time.RFC3339, aws.StringValue(image.image.CreationDate))
+			if err != nil || now.Sub(creationDate).Hours()/24 < float64(opts.DaysUnused) {
+				continue
+			}
+			results = append(results, s.result(opts, image, images[origin], sortedKeys(copyRegions), sortedKeys(launchRegions), creationDate, now))
+		}
+	}
+
+	logging.Info("Completed AMI copy scan", map[string]interface{}{
+		"account_id":       opts.AccountID,
+		"regions":          len(regions),
+		"redundant_copies": len(results),
+	})
+
+	return results, nil
+}
+
+// listRegion returns the AMIs owned by the account in a region and the IDs of the images
+// its instances were launched from
+func (s *AMICopyScanner) listRegion(opts awslib.ScanOptions, region string) ([]*regionalImage, map[string]bool, error) {
+	sess, err := awslib.GetSessionInRegion(opts.Session, region)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create regional session: %w", err)
+	}
+	ec2Client := ec2.New(sess)
+
+	ownedInRegion, err := ownedImages(context.Background(), opts.Contexts.Get(opts.AccountID, region), ec2Client)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to describe images: %w", err)
+	}
+	var images []*regionalImage
+	for _, image := range ownedInRegion {
+		sourceID, sourceRegion := imageSource(image)
+		images = append(images, &regionalImage{
+			image:        image,
+			region:       region,
+			sourceID:     sourceID,
+			sourceRegion: sourceRegion,
+		})
+	}
+
+	launched := make(map[string]bool)
+	err = ec2Client.DescribeInstancesPages(&ec2.DescribeInstancesInput{
+		Filters: []*ec2.Filter{{
+			Name:   aws.String("instance-state-name"),
+			Values: aws.StringSlice([]string{ec2.InstanceStateNamePending, ec2.InstanceStateNameRunning, ec2.InstanceStateNameStopping, ec2.InstanceStateNameStopped}),
+		}},
+		MaxResults: aws.Int64(1000),
+	}, func(page *ec2.DescribeInstancesOutput, lastPage bool) bool {
+		for _, reservation := range page.Reservations {
+			for _, instance := range reservation.Instances {
+				launched[aws.StringValue(instance.ImageId)] = true
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to describe instances: %w", err)
+	}
+
+	return images, launched, nil
+}
+
+// launched returns true if an instance exists from the image or it was launched from within
+// the days unused window
+func (s *AMICopyScanner) launched(image *regionalImage, launchedImages map[string]bool, now time.Time, daysUnused int) bool {
+	if launchedImages[aws.StringValue(image.image.ImageId)] {
+		return true
+	}
+	lastLaunched, err := time.Parse(time.RFC3339, aws.StringValue(image.image.LastLaunchedTime))
+	return err == nil && now.Sub(lastLaunched).Hours()/24 < float64(daysUnused)
+}
+
+// result builds the scan result of a redundant copy, costed by the snapshots it keeps
+func (s *AMICopyScanner) result(opts awslib.ScanOptions, image, origin *regionalImage, copyRegions, launchRegions []string, creationDate, now time.Time) awslib.ScanResult {
+	imageID := aws.StringValue(image.image.ImageId)
+	originID := aws.StringValue(origin.image.ImageId)
+
+	var totalSize int64
+	var totalCosts *awslib.CostBreakdown
+	var snapshots []map[string]interface{}
+	for _, blockDevice := range image.image.BlockDeviceMappings {
+		if blockDevice.Ebs == nil || blockDevice.Ebs.SnapshotId == nil {
+			continue
+		}
+		size := aws.Int64Value(blockDevice.Ebs.VolumeSize)
+		totalSize += size
+		volumeType := aws.StringValue(blockDevice.Ebs.VolumeType)
+		if volumeType == "" {
+			volumeType = "gp2" // Default to gp2 if not specified
+		}
+		snapshots = append(snapshots, map[string]interface{}{
+			"snapshot_id": aws.StringValue(blockDevice.Ebs.SnapshotId),
+			"device_name": aws.StringValue(blockDevice.DeviceName),
+			"volume_size": size,
+		})
+
+		if awslib.DefaultCostEstimator == nil {
+			continue
+		}
+		costs, err := awslib.DefaultCostEstimator.CalculateCost(awslib.ResourceCostConfig{
+			ResourceType: "EBSSnapshots",
+			ResourceSize: size,
+			Region:       image.region,
+			CreationTime: creationDate,
+			VolumeType:   volumeType,
+		})
+		if err != nil {
+			logging.Error("Failed to calculate snapshot costs", err, map[string]interface{}{
+				"account_id":  opts.AccountID,
+				"region":      image.region,
+				"snapshot_id": aws.StringValue(blockDevice.Ebs.SnapshotId),
+			})
+			continue
+		}
+		if totalCosts == nil {
+			totalCosts = costs
+		} else {
+			totalCosts.HourlyRate += costs.HourlyRate
+			totalCosts.DailyRate += costs.DailyRate
+			totalCosts.MonthlyRate += costs.MonthlyRate
+			totalCosts.YearlyRate += costs.YearlyRate
+		}
+	}
+	if totalCosts != nil {
+		lifetime := totalCosts.HourlyRate * now.Sub(creationDate).Hours()
+		totalCosts.Lifetime = &lifetime
+	}
+
+	tags := make(map[string]string)
+	for _, tag := range image.image.Tags {
+		tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+	}
+	name := aws.StringValue(image.image.Name)
+	if name == "" {
+		name = imageID
+	}
+
+	reasons := []string{
+		fmt.Sprintf("Copy of %s (%s) kept in %d regions, but instances only launch from it in %s.",
+			originID, origin.region, len(copyRegions), strings.Join(launchRegions, ", ")),
+		fmt.Sprintf("No instances launched from this copy in %s; its %d GB of snapshots are redundant.", image.region, totalSize),
+	}
+
+	var cost map[string]interface{}
+	if totalCosts != nil {
+		cost = map[string]interface{}{"total": totalCosts}
+	}
+	if opts.RunsScanner("amis") {
+		cost = awslib.NoCost(awslib.NoCostCountedOnce)
+		reasons = append(reasons, "Its snapshot cost is counted by the AMIs finding of the copy.")
+	}
+
+	return awslib.ScanResult{
+		ResourceType: s.Label(),
+		ResourceName: name,
+		ResourceID:   imageID,
+		Reason:       strings.Join(reasons, "\n"),
+		Tags:         tags,
+		Details: map[string]interface{}{
+			"account_id":             opts.AccountID,
+			"region":                 image.region,
+			"origin_image_id":        originID,
+			"origin_region":          origin.region,
+			"source_image_id":        image.sourceID,
+			"source_region":          image.sourceRegion,
+			"copy_regions":           copyRegions,
+			"launch_regions":         launchRegions,
+			"creation_date":          creationDate.Format(time.RFC3339),
+			"snapshots":              snapshots,
+			"total_snapshot_size_gb": totalSize,
+		},
+		Cost: cost,
+	}
+}
+
+// sortedKeys returns the keys of a set in order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,232 @@
+package scanners
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	awslib "cloudsift/internal/aws"
+	"cloudsift/internal/logging"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// AMIOrphanScanner finds AMIs stranded by cleaning up their snapshots: AMIs whose backing
+// snapshots were deleted can no longer be launched. Snapshots left behind by deregistered
+// AMIs are reported by the EBS snapshot scanner.
+type AMIOrphanScanner struct{}
+
+func init() {
+	awslib.DefaultRegistry.RegisterScanner(&AMIOrphanScanner{})
+}
+
+// ArgumentName implements Scanner interface
+func (s *AMIOrphanScanner) ArgumentName() string {
+	return "ami-orphans"
+}
+
+// Label implements Scanner interface
+func (s *AMIOrphanScanner) Label() string {
+	return "AMI Orphans"
+}
+
+// RemediationTemplate implements Remediator interface. AMIs whose snapshots were deleted are
+// deregistered.
+func (s *AMIOrphanScanner) RemediationTemplate() string {
+	return "aws ec2 deregister-image --image-id {{.ResourceID}} --region {{.Region}}"
+}
+
+// orphanTypeImageMissingSnapshots is the orphan_type detail of AMIs whose snapshots were
+// deleted
+const orphanTypeImageMissingSnapshots = "image_missing_snapshots"
+
+// HasResources implements ResourceProber interface
+func (s *AMIOrphanScanner) HasResources(opts awslib.ScanOptions) (bool, error) {
+	sess, err := awslib.GetSessionInRegion(opts.Session, opts.Region)
+	if err != nil {
+		return false, fmt.Errorf("failed to create regional session: %w", err)
+	}
+
+	output, err := ec2.New(sess).DescribeSnapshots(&ec2.DescribeSnapshotsInput{
+		OwnerIds:   []*string{aws.String("self")},
+		MaxResults: aws.Int64(5),
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to describe snapshots: %w", err)
+	}
+	return len(output.Snapshots) > 0, nil
+}
+
+// snapshotCosts returns the storage costs of a snapshot since it was created
+func (s *AMIOrphanScanner) snapshotCosts(opts awslib.ScanOptions, snapshotID string, sizeGB int64, volumeType string, created, now time.Time) *awslib.CostBreakdown {
+	if awslib.DefaultCostEstimator == nil {
+		return nil
+	}
+	if volumeType == "" {
+		volumeType = "gp2" // Default to gp2 if not specified
+	}
+	costs, err := awslib.DefaultCostEstimator.CalculateCost(awslib.ResourceCostConfig{
+		ResourceType: "EBSSnapshots",
+		ResourceSize: sizeGB,
+		Region:       opts.Region,
+		CreationTime: created,
+		VolumeType:   volumeType,
+	})
+	if err != nil {
+		logging.Error("Failed to calculate snapshot costs", err, map[string]interface{}{
+			"account_id":  opts.AccountID,
+			"region":      opts.Region,
+			"snapshot_id": snapshotID,
+		})
+		return nil
+	}
+	lifetime := costs.HourlyRate * now.Sub(created).Hours()
+	costs.Lifetime = &lifetime
+	return costs
+}
+
+// Scan implements Scanner interface
+func (s *AMIOrphanScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, error) {
+	sess, err := awslib.GetSessionInRegion(opts.Session, opts.Region)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create regional session: %w", err)
+	}
+	ec2Client := ec2.New(sess)
+
+	logging.Debug("Starting AMI orphan scan", map[string]interface{}{
+		"account_id": opts.AccountID,
+		"region":     opts.Region,
+	})
+
+	images, err := ownedImages(context.Background(), opts.ScanContext(), ec2Client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe AMIs: %w", err)
+	}
+
+	snapshots := make(map[string]*ec2.Snapshot)
+	err = ec2Client.DescribeSnapshotsPages(&ec2.DescribeSnapshotsInput{
+		OwnerIds: []*string{aws.String("self")},
+	}, func(page *ec2.DescribeSnapshotsOutput, lastPage bool) bool {
+		for _, snapshot := range page.Snapshots {
+			snapshots[aws.StringValue(snapshot.SnapshotId)] = snapshot
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe snapshots: %w", err)
+	}
+
+	now := time.Now()
+	var results awslib.ScanResults
+
+	// AMIs whose snapshots were deleted can no longer be launched, and their remaining
+	// snapshots are only kept for them
+	for _, image := range images {
+		if result := s.imageMissingSnapshots(opts, image, snapshots, now); result != nil {
+			results = append(results, *result)
+		}
+	}
+
+	logging.Debug("AMI orphan scan completed", map[string]interface{}{
+		"account_id": opts.AccountID,
+		"region":     opts.Region,
+		"images":     len(images),
+		"snapshots":  len(snapshots),
+		"orphans":    len(results),
+	})
+
+	return results, nil
+}
+
+// imageMissingSnapshots returns a scan result if snapshots backing an available AMI were
+// deleted. Snapshots of AMIs that are still pending are not created yet, and failed or
+// deregistered AMIs are not launchable anyway.
+func (s *AMIOrphanScanner) imageMissingSnapshots(opts awslib.ScanOptions, image *ec2.Image, snapshots map[string]*ec2.Snapshot, now time.Time) *awslib.ScanResult {
+	if aws.StringValue(image.State) != ec2.ImageStateAvailable {
+		return nil
+	}
+	imageID := aws.StringValue(image.ImageId)
+	creationDate, _ := time.Parse(time.RFC3339, aws.StringValue(image.CreationDate))
+
+	var missing []string
+	var remaining []map[string]interface{}
+	var totalCosts *awslib.CostBreakdown
+	for _, blockDevice := range image.BlockDeviceMappings {
+		if blockDevice.Ebs == nil || blockDevice.Ebs.SnapshotId == nil {
+			continue
+		}
+		snapshotID := aws.StringValue(blockDevice.Ebs.SnapshotId)
+		snapshot, ok := snapshots[snapshotID]
+		if !ok {
+			missing = append(missing, snapshotID)
+			continue
+		}
+
+		remaining = append(remaining, map[string]interface{}{
+			"snapshot_id": snapshotID,
+			"device_name": aws.StringValue(blockDevice.DeviceName),
+			"volume_size": aws.Int64Value(snapshot.VolumeSize),
+		})
+		costs := s.snapshotCosts(opts, snapshotID, aws.Int64Value(snapshot.VolumeSize), aws.StringValue(blockDevice.Ebs.VolumeType), aws.TimeValue(snapshot.StartTime), now)
+		if costs == nil {
+			continue
+		}
+		if totalCosts == nil {
+			totalCosts = costs
+		} else {
+			totalCosts.HourlyRate += costs.HourlyRate
+			totalCosts.DailyRate += costs.DailyRate
+			totalCosts.MonthlyRate += costs.MonthlyRate
+			totalCosts.YearlyRate += costs.YearlyRate
+			lifetime := *totalCosts.Lifetime + *costs.Lifetime
+			totalCosts.Lifetime = &lifetime
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	tags := make(map[string]string)
+	for _, tag := range image.Tags {
+		tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+	}
+	resourceName := aws.StringValue(image.Name)
+	if resourceName == "" {
+		resourceName = imageID
+	}
+	if name, ok := tags["Name"]; ok {
+		resourceName = name
+	}
+
+	reasons := []string{
+		fmt.Sprintf("AMI cannot be launched because its backing snapshots were deleted: %s.", strings.Join(missing, ", ")),
+	}
+	if len(remaining) > 0 {
+		reasons = append(reasons, fmt.Sprintf("%d remaining snapshots are only kept for this AMI.", len(remaining)))
+	}
+
+	var cost map[string]interface{}
+	if totalCosts != nil {
+		cost = map[string]interface{}{"total": totalCosts}
+	}
+
+	return &awslib.ScanResult{
+		ResourceType: s.Label(),
+		ResourceName: resourceName,
+		ResourceID:   imageID,
+		Reason:       strings.Join(reasons, "\n"),
+		Tags:         tags,
+		Details: map[string]interface{}{
+			"orphan_type":         orphanTypeImageMissingSnapshots,
+			"missing_snapshots":   missing,
+			"remaining_snapshots": remaining,
+			"creation_date":       creationDate.Format(time.RFC3339),
+			"state":               aws.StringValue(image.State),
+			"account_id":          opts.AccountID,
+			"region":              opts.Region,
+		},
+		Cost: cost,
+	}
+}
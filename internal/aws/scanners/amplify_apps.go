@@ -0,0 +1,202 @@
+package scanners
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	awslib "cloudsift/internal/aws"
+	"cloudsift/internal/aws/utils"
+	"cloudsift/internal/logging"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/amplify"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+)
+
+// AmplifyAppScanner scans for Amplify Hosting apps without builds or requests
+type AmplifyAppScanner struct{}
+
+func init() {
+	awslib.DefaultRegistry.RegisterScanner(&AmplifyAppScanner{})
+}
+
+// ArgumentName implements Scanner interface
+func (s *AmplifyAppScanner) ArgumentName() string {
+	return "amplify-apps"
+}
+
+// Label implements Scanner interface
+func (s *AmplifyAppScanner) Label() string {
+	return "Amplify Apps"
+}
+
+// RemediationTemplate implements Remediator interface. Deleting the app also deletes its
+// branches and hosting.
+func (s *AmplifyAppScanner) RemediationTemplate() string {
+	return `aws amplify delete-app --app-id {{index .Details "app_id"}} --region {{.Region}}`
+}
+
+// HasResources implements ResourceProber interface
+func (s *AmplifyAppScanner) HasResources(opts awslib.ScanOptions) (bool, error) {
+	if !utils.ServiceAvailable(amplify.EndpointsID, opts.Region) {
+		return false, nil
+	}
+	sess, err := awslib.GetSessionInRegion(opts.Session, opts.Region)
+	if err != nil {
+		return false, fmt.Errorf("failed to create regional session: %w", err)
+	}
+
+	output, err := amplify.New(sess).ListApps(&amplify.ListAppsInput{MaxResults: aws.Int64(1)})
+	if err != nil {
+		return false, fmt.Errorf("failed to list Amplify apps: %w", err)
+	}
+	return len(output.Apps) > 0, nil
+}
+
+// lastBuild returns the start time of the most recent job across all branches of an app,
+// and the number of branches
+func (s *AmplifyAppScanner) lastBuild(client *amplify.Amplify, appID string) (*time.Time, int, error) {
+	var branches []string
+	err := client.ListBranchesPages(&amplify.ListBranchesInput{
+		AppId: aws.String(appID),
+	}, func(page *amplify.ListBranchesOutput, lastPage bool) bool {
+		for _, branch := range page.Branches {
+			branches = append(branches, aws.StringValue(branch.BranchName))
+		}
+		return true
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list branches: %w", err)
+	}
+
+	var last *time.Time
+	for _, branch := range branches {
+		// Jobs are listed most recent first
+		output, err := client.ListJobs(&amplify.ListJobsInput{
+			AppId:      aws.String(appID),
+			BranchName: aws.String(branch),
+			MaxResults: aws.Int64(1),
+		})
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to list jobs of branch %s: %w", branch, err)
+		}
+		for _, job := range output.JobSummaries {
+			if job.StartTime != nil && (last == nil || job.StartTime.After(*last)) {
+				last = job.StartTime
+			}
+		}
+	}
+	return last, len(branches), nil
+}
+
+// Scan implements Scanner interface
+func (s *AmplifyAppScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, error) {
+	if !utils.ServiceAvailable(amplify.EndpointsID, opts.Region) {
+		return nil, nil
+	}
+
+	sess, err := awslib.GetSessionInRegion(opts.Session, opts.Region)
+	if err != nil {
+		logging.Error("Failed to create regional session", err, map[string]interface{}{
+			"region": opts.Region,
+		})
+		return nil, fmt.Errorf("failed to create regional session: %w", err)
+	}
+	client := amplify.New(sess)
+	cwClient := cloudwatch.New(sess)
+
+	var apps []*amplify.App
+	err = client.ListAppsPages(&amplify.ListAppsInput{}, func(page *amplify.ListAppsOutput, lastPage bool) bool {
+		apps = append(apps, page.Apps...)
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Amplify apps: %w", err)
+	}
+
+	endTime := time.Now().UTC()
+	startTime := endTime.Add(-time.Duration(opts.DaysUnused) * 24 * time.Hour)
+
+	var results awslib.ScanResults
+	for _, app := range apps {
+		appID := aws.StringValue(app.AppId)
+		createdAt := aws.TimeValue(app.CreateTime)
+		if createdAt.After(startTime) {
+			continue
+		}
+
+		lastBuild, branchCount, err := s.lastBuild(client, appID)
+		if err != nil {
+			logging.Error("Failed to get Amplify app builds", err, map[string]interface{}{
+				"app_id": appID,
+			})
+			continue
+		}
+		if lastBuild != nil && lastBuild.After(startTime) {
+			continue
+		}
+
+		details := map[string]interface{}{
+			"account_id":     opts.AccountID,
+			"region":         opts.Region,
+			"app_id":         appID,
+			"platform":       aws.StringValue(app.Platform),
+			"repository":     aws.StringValue(app.Repository),
+			"default_domain": aws.StringValue(app.DefaultDomain),
+			"branch_count":   branchCount,
+			"created_at":     createdAt.Format(time.RFC3339),
+		}
+		reasons := []string{fmt.Sprintf("No builds in the last %d days.", opts.DaysUnused)}
+		if lastBuild != nil {
+			details["last_build"] = lastBuild.Format(time.RFC3339)
+		}
+
+		recorder := utils.NewMetricRecorder(opts.IncludeMetricSamples)
+		requests, err := utils.GetResourceMetrics(cwClient, utils.MetricConfig{
+			Namespace:     "AWS/AmplifyHosting",
+			ResourceID:    appID,
+			DimensionName: "App",
+			MetricName:    "Requests",
+			Statistic:     "Sum",
+			StartTime:     startTime,
+			EndTime:       endTime,
+			Period:        86400, // 1 day
+			Recorder:      recorder,
+		})
+		switch {
+		case err != nil:
+			logging.Error("Failed to get Amplify app requests", err, map[string]interface{}{
+				"app_id": appID,
+			})
+			if !utils.MetricsDegraded(err) {
+				continue
+			}
+			utils.MarkMetricsDegraded(details, err)
+			reasons = append(reasons, utils.MetricsUnavailableReason)
+		case requests > 0:
+			// Apps serving traffic are in use even if they are no longer built
+			continue
+		default:
+			reasons = append(reasons, fmt.Sprintf("No requests in the last %d days.", opts.DaysUnused))
+		}
+		recorder.AddTo(details)
+
+		tags := make(map[string]string, len(app.Tags))
+		for key, value := range app.Tags {
+			tags[key] = aws.StringValue(value)
+		}
+
+		results = append(results, awslib.ScanResult{
+			ResourceType: s.Label(),
+			ResourceName: aws.StringValue(app.Name),
+			ResourceID:   aws.StringValue(app.AppArn),
+			Reason:       strings.Join(reasons, "\n"),
+			Details:      details,
+			Cost:         awslib.NoCost(awslib.NoCostNotEstimated),
+			Tags:         tags,
+		})
+	}
+
+	return results, nil
+}
@@ -0,0 +1,344 @@
+package scanners
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	awslib "cloudsift/internal/aws"
+	"cloudsift/internal/logging"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/athena"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+)
+
+const (
+	// athenaMinResultsBytes is the size of stored query results from which a result location
+	// without a lifecycle expiration rule is reported (1 GB)
+	athenaMinResultsBytes = 1024 * 1024 * 1024
+
+	// athenaMaxResultObjects caps the number of result objects listed per workgroup, so huge
+	// result locations do not stall the scan. Sizes of larger locations are lower bounds.
+	athenaMaxResultObjects = 100000
+)
+
+// AthenaWorkgroupScanner scans for Athena workgroups whose query results pile up in S3, either
+// because the workgroup is no longer queried or because no lifecycle rule expires them
+type AthenaWorkgroupScanner struct{}
+
+func init() {
+	awslib.DefaultRegistry.RegisterScanner(&AthenaWorkgroupScanner{})
+}
+
+// ArgumentName implements Scanner interface
+func (s *AthenaWorkgroupScanner) ArgumentName() string {
+	return "athena-workgroups"
+}
+
+// Label implements Scanner interface
+func (s *AthenaWorkgroupScanner) Label() string {
+	return "Athena Workgroups"
+}
+
+// RemediationTemplate implements Remediator interface. The deletion fails while the workgroup
+// still has saved queries or prepared statements, so they are not removed without review.
+func (s *AthenaWorkgroupScanner) RemediationTemplate() string {
+	return "aws athena delete-work-group --work-group {{.ResourceID}} --region {{.Region}}"
+}
+
+// HasResources implements ResourceProber interface
+func (s *AthenaWorkgroupScanner) HasResources(opts awslib.ScanOptions) (bool, error) {
+	sess, err := awslib.GetSessionInRegion(opts.Session, opts.Region)
+	if err != nil {
+		return false, fmt.Errorf("failed to create regional session: %w", err)
+	}
+
+	// Every region has the primary workgroup, so only regions with queries are of interest
+	output, err := athena.New(sess).ListQueryExecutions(&athena.ListQueryExecutionsInput{
+		MaxResults: aws.Int64(1),
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to list Athena query executions: %w", err)
+	}
+	return len(output.QueryExecutionIds) > 0, nil
+}
+
+// lastQuery returns the submission time of the most recent query of a workgroup, or nil if
+// the workgroup has no query history
+func (s *AthenaWorkgroupScanner) lastQuery(client *athena.Athena, workgroup string) (*time.Time, error) {
+	// Query executions are listed most recent first
+	output, err := client.ListQueryExecutions(&athena.ListQueryExecutionsInput{
+		WorkGroup:  aws.String(workgroup),
+		MaxResults: aws.Int64(1),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list query executions: %w", err)
+	}
+	if len(output.QueryExecutionIds) == 0 {
+		return nil, nil
+	}
+
+	execution, err := client.GetQueryExecution(&athena.GetQueryExecutionInput{
+		QueryExecutionId: output.QueryExecutionIds[0],
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get query execution: %w", err)
+	}
+	if execution.QueryExecution == nil || execution.QueryExecution.Status == nil {
+		return nil, nil
+	}
+	return execution.QueryExecution.Status.SubmissionDateTime, nil
+}
+
+// queryResults holds the size of the query results stored under an output location
+type queryResults struct {
+	objects    int
+	totalBytes int64
+	staleBytes int64 // Bytes of results written before the unused threshold
+	truncated  bool  // Listing stopped at athenaMaxResultObjects
+}
+
+// resultsSize sums the size of the objects under a result location prefix
+func resultsSize(client *s3.S3, bucket, prefix string, cutoff time.Time) (queryResults, error) {
+	var results queryResults
+	err := client.ListObjectsV2Pages(&s3.ListObjectsV2Input{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(prefix),
+	}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, object := range page.Contents {
+			size := aws.Int64Value(object.Size)
+			results.objects++
+			results.totalBytes += size
+			if aws.TimeValue(object.LastModified).Before(cutoff) {
+				results.staleBytes += size
+			}
+		}
+		if results.objects >= athenaMaxResultObjects && !lastPage {
+			results.truncated = true
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return results, fmt.Errorf("failed to list objects: %w", err)
+	}
+	return results, nil
+}
+
+// hasExpirationRule returns true if the bucket has an enabled lifecycle rule that expires
+// the objects under the prefix
+func hasExpirationRule(client *s3.S3, bucket, prefix string) (bool, error) {
+	output, err := client.GetBucketLifecycleConfiguration(&s3.GetBucketLifecycleConfigurationInput{
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == "NoSuchLifecycleConfiguration" {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get lifecycle configuration: %w", err)
+	}
+
+	for _, rule := range output.Rules {
+		if aws.StringValue(rule.Status) != s3.ExpirationStatusEnabled || rule.Expiration == nil {
+			continue
+		}
+		rulePrefix := aws.StringValue(rule.Prefix)
+		if rule.Filter != nil {
+			if rule.Filter.And != nil || rule.Filter.Tag != nil {
+				continue
+			}
+			rulePrefix += aws.StringValue(rule.Filter.Prefix)
+		}
+		if strings.HasPrefix(prefix, rulePrefix) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Scan implements Scanner interface
+func (s *AthenaWorkgroupScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, error) {
+	sess, err := awslib.GetSessionInRegion(opts.Session, opts.Region)
+	if err != nil {
+		logging.Error("Failed to create regional session", err, map[string]interface{}{
+			"region": opts.Region,
+		})
+		return nil, fmt.Errorf("failed to create regional session: %w", err)
+	}
+	client := athena.New(sess)
+
+	var workgroups []string
+	err = client.ListWorkGroupsPages(&athena.ListWorkGroupsInput{}, func(page *athena.ListWorkGroupsOutput, lastPage bool) bool {
+		for _, workgroup := range page.WorkGroups {
+			if aws.StringValue(workgroup.State) == athena.WorkGroupStateEnabled {
+				workgroups = append(workgroups, aws.StringValue(workgroup.Name))
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Athena workgroups: %w", err)
+	}
+
+	cutoff := time.Now().UTC().Add(-time.Duration(opts.DaysUnused) * 24 * time.Hour)
+
+	// Workgroups sharing a result location are reported once, by the first workgroup
+	seenLocations := make(map[string]bool)
+
+	var results awslib.ScanResults
+	for _, name := range workgroups {
+		output, err := client.GetWorkGroup(&athena.GetWorkGroupInput{WorkGroup: aws.String(name)})
+		if err != nil {
+			logging.Error("Failed to get Athena workgroup", err, map[string]interface{}{
+				"workgroup": name,
+			})
+			continue
+		}
+		workgroup := output.WorkGroup
+		configuration := workgroup.Configuration
+		if configuration == nil || configuration.ResultConfiguration == nil {
+			continue
+		}
+		location := aws.StringValue(configuration.ResultConfiguration.OutputLocation)
+		if location == "" || seenLocations[location] {
+			continue
+		}
+		seenLocations[location] = true
+
+		locationURL, err := url.Parse(location)
+		if err != nil || locationURL.Scheme != "s3" {
+			logging.Warn("Invalid Athena output location", map[string]interface{}{
+				"workgroup": name,
+				"location":  location,
+			})
+			continue
+		}
+		bucket := locationURL.Host
+		prefix := strings.TrimPrefix(locationURL.Path, "/")
+
+		lastQuery, err := s.lastQuery(client, name)
+		if err != nil {
+			logging.Error("Failed to get Athena workgroup queries", err, map[string]interface{}{
+				"workgroup": name,
+			})
+			continue
+		}
+		idle := lastQuery == nil || lastQuery.Before(cutoff)
+
+		// The result bucket may be located in another region than the workgroup
+		bucketRegion, err := s3manager.GetBucketRegion(context.Background(), sess, bucket, opts.Region)
+		if err != nil {
+			logging.Error("Failed to get Athena result bucket region", err, map[string]interface{}{
+				"workgroup": name,
+				"bucket":    bucket,
+			})
+			continue
+		}
+		s3Client := s3.New(sess, aws.NewConfig().WithRegion(bucketRegion))
+
+		size, err := resultsSize(s3Client, bucket, prefix, cutoff)
+		if err != nil {
+			logging.Error("Failed to get Athena query results size", err, map[string]interface{}{
+				"workgroup": name,
+				"location":  location,
+			})
+			continue
+		}
+		if size.totalBytes == 0 {
+			continue
+		}
+
+		hasRule, err := hasExpirationRule(s3Client, bucket, prefix)
+		if err != nil {
+			logging.Warn("Failed to check result bucket lifecycle rules", map[string]interface{}{
+				"bucket": bucket,
+				"error":  err.Error(),
+			})
+			hasRule = true // Do not report a missing rule that could not be checked
+		}
+
+		totalGB := float64(size.totalBytes) / (1024 * 1024 * 1024)
+		staleGB := float64(size.staleBytes) / (1024 * 1024 * 1024)
+
+		var reasons []string
+		if idle && size.staleBytes > 0 {
+			reasons = append(reasons, fmt.Sprintf("No queries in the last %d days but %.2f GB of query results are still stored.", opts.DaysUnused, staleGB))
+		}
+		if !hasRule && size.totalBytes >= athenaMinResultsBytes {
+			reasons = append(reasons, fmt.Sprintf("Query results (%.2f GB) are not expired by a lifecycle rule.", totalGB))
+		}
+		if len(reasons) == 0 {
+			continue
+		}
+
+		details := map[string]interface{}{
+			"account_id":            opts.AccountID,
+			"region":                opts.Region,
+			"output_location":       location,
+			"bucket_region":         bucketRegion,
+			"enforce_configuration": aws.BoolValue(configuration.EnforceWorkGroupConfiguration),
+			"result_objects":        size.objects,
+			"results_size_gb":       totalGB,
+			"stale_results_size_gb": staleGB,
+			"has_expiration_rule":   hasRule,
+			"created_at":            aws.TimeValue(workgroup.CreationTime).Format(time.RFC3339),
+		}
+		if size.truncated {
+			details["results_size_truncated"] = true
+		}
+		if lastQuery != nil {
+			details["last_query"] = lastQuery.Format(time.RFC3339)
+		}
+		if !hasRule {
+			details["recommended_lifecycle_rule"] = map[string]interface{}{
+				"ID":         "expire-athena-query-results",
+				"Status":     "Enabled",
+				"Filter":     map[string]interface{}{"Prefix": prefix},
+				"Expiration": map[string]interface{}{"Days": opts.DaysUnused},
+			}
+		}
+
+		// Query results are stored in S3 Standard, so the cost is the storage of the results
+		var costDetails map[string]interface{}
+		if awslib.DefaultCostEstimator != nil {
+			costs, err := awslib.DefaultCostEstimator.CalculateCost(awslib.ResourceCostConfig{
+				ResourceType: "S3Storage",
+				ResourceSize: totalGB,
+				VolumeType:   s3.StorageClassStandard,
+				Region:       bucketRegion,
+			})
+			if err != nil {
+				logging.Error("Failed to calculate Athena query results costs", err, map[string]interface{}{
+					"workgroup": name,
+				})
+			} else {
+				costDetails = map[string]interface{}{
+					"total": costs,
+				}
+			}
+		}
+
+		results = append(results, awslib.ScanResult{
+			ResourceType: s.Label(),
+			ResourceName: name,
+			ResourceID:   name,
+			Reason:       strings.Join(reasons, "\n"),
+			Details:      details,
+			Cost:         costDetails,
+		})
+	}
+
+	logging.Info("Completed Athena workgroup scan", map[string]interface{}{
+		"account_id": opts.AccountID,
+		"region":     opts.Region,
+		"workgroups": len(workgroups),
+		"reported":   len(results),
+	})
+
+	return results, nil
+}
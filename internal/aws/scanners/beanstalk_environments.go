@@ -0,0 +1,236 @@
+package scanners
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	awslib "cloudsift/internal/aws"
+	"cloudsift/internal/aws/utils"
+	"cloudsift/internal/logging"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/elasticbeanstalk"
+)
+
+// ElasticBeanstalkScanner scans for Elastic Beanstalk web server environments without
+// deployments or requests
+type ElasticBeanstalkScanner struct{}
+
+func init() {
+	awslib.DefaultRegistry.RegisterScanner(&ElasticBeanstalkScanner{})
+}
+
+// ArgumentName implements Scanner interface
+func (s *ElasticBeanstalkScanner) ArgumentName() string {
+	return "beanstalk-environments"
+}
+
+// Label implements Scanner interface
+func (s *ElasticBeanstalkScanner) Label() string {
+	return "Elastic Beanstalk Environments"
+}
+
+// RemediationTemplate implements Remediator interface. Terminating the environment also
+// terminates its instances and load balancer; the application and its versions are kept.
+func (s *ElasticBeanstalkScanner) RemediationTemplate() string {
+	return `aws elasticbeanstalk terminate-environment --environment-id {{index .Details "environment_id"}} --region {{.Region}}`
+}
+
+// HasResources implements ResourceProber interface
+func (s *ElasticBeanstalkScanner) HasResources(opts awslib.ScanOptions) (bool, error) {
+	if !utils.ServiceAvailable(elasticbeanstalk.EndpointsID, opts.Region) {
+		return false, nil
+	}
+	sess, err := awslib.GetSessionInRegion(opts.Session, opts.Region)
+	if err != nil {
+		return false, fmt.Errorf("failed to create regional session: %w", err)
+	}
+
+	output, err := elasticbeanstalk.New(sess).DescribeEnvironments(&elasticbeanstalk.DescribeEnvironmentsInput{
+		IncludeDeleted: aws.Bool(false),
+		MaxRecords:     aws.Int64(1),
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to describe Elastic Beanstalk environments: %w", err)
+	}
+	return len(output.Environments) > 0, nil
+}
+
+// beanstalkRequestMetric returns the CloudWatch metric counting the requests of an
+// environment's load balancer, named by its ARN for application and network load balancers
+// and by its name for classic load balancers
+func beanstalkRequestMetric(loadBalancer string) (namespace, metricName, dimensionName, dimensionValue string) {
+	if !strings.HasPrefix(loadBalancer, "arn:") {
+		return "AWS/ELB", "RequestCount", "LoadBalancerName", loadBalancer
+	}
+	shortName := strings.TrimPrefix(getLoadBalancerShortName(loadBalancer), "loadbalancer/")
+	if strings.HasPrefix(shortName, "net/") {
+		return "AWS/NetworkELB", "NewFlowCount", "LoadBalancer", shortName
+	}
+	return "AWS/ApplicationELB", "RequestCount", "LoadBalancer", shortName
+}
+
+// Scan implements Scanner interface. Single instance and worker environments have no load
+// balancer to measure requests with; their instances are covered by the EC2 scanners.
+func (s *ElasticBeanstalkScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, error) {
+	if !utils.ServiceAvailable(elasticbeanstalk.EndpointsID, opts.Region) {
+		return nil, nil
+	}
+
+	sess, err := awslib.GetSessionInRegion(opts.Session, opts.Region)
+	if err != nil {
+		logging.Error("Failed to create regional session", err, map[string]interface{}{
+			"region": opts.Region,
+		})
+		return nil, fmt.Errorf("failed to create regional session: %w", err)
+	}
+	client := elasticbeanstalk.New(sess)
+	cwClient := cloudwatch.New(sess)
+
+	var environments []*elasticbeanstalk.EnvironmentDescription
+	input := &elasticbeanstalk.DescribeEnvironmentsInput{IncludeDeleted: aws.Bool(false)}
+	for {
+		output, err := client.DescribeEnvironments(input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe Elastic Beanstalk environments: %w", err)
+		}
+		environments = append(environments, output.Environments...)
+		if aws.StringValue(output.NextToken) == "" {
+			break
+		}
+		input.NextToken = output.NextToken
+	}
+
+	endTime := time.Now().UTC()
+	startTime := endTime.Add(-time.Duration(opts.DaysUnused) * 24 * time.Hour)
+
+	var results awslib.ScanResults
+	for _, environment := range environments {
+		environmentID := aws.StringValue(environment.EnvironmentId)
+		if aws.StringValue(environment.Status) != elasticbeanstalk.EnvironmentStatusReady {
+			continue
+		}
+		if environment.Tier != nil && aws.StringValue(environment.Tier.Name) != "WebServer" {
+			continue
+		}
+		// Environments are updated by every deployment and configuration change
+		updatedAt := aws.TimeValue(environment.DateUpdated)
+		if aws.TimeValue(environment.DateCreated).After(startTime) || updatedAt.After(startTime) {
+			continue
+		}
+
+		resources, err := client.DescribeEnvironmentResources(&elasticbeanstalk.DescribeEnvironmentResourcesInput{
+			EnvironmentId: environment.EnvironmentId,
+		})
+		if err != nil {
+			logging.Error("Failed to describe Elastic Beanstalk environment resources", err, map[string]interface{}{
+				"environment_id": environmentID,
+			})
+			continue
+		}
+		var loadBalancers, instanceIDs []string
+		if description := resources.EnvironmentResources; description != nil {
+			for _, loadBalancer := range description.LoadBalancers {
+				loadBalancers = append(loadBalancers, aws.StringValue(loadBalancer.Name))
+			}
+			for _, instance := range description.Instances {
+				instanceIDs = append(instanceIDs, aws.StringValue(instance.Id))
+			}
+		}
+		if len(loadBalancers) == 0 {
+			continue
+		}
+
+		details := map[string]interface{}{
+			"account_id":       opts.AccountID,
+			"region":           opts.Region,
+			"environment_id":   environmentID,
+			"application_name": aws.StringValue(environment.ApplicationName),
+			"version_label":    aws.StringValue(environment.VersionLabel),
+			"platform":         aws.StringValue(environment.SolutionStackName),
+			"cname":            aws.StringValue(environment.CNAME),
+			"health":           aws.StringValue(environment.Health),
+			"load_balancers":   loadBalancers,
+			"instance_ids":     instanceIDs,
+			"created_at":       aws.TimeValue(environment.DateCreated).Format(time.RFC3339),
+			"last_updated":     updatedAt.Format(time.RFC3339),
+		}
+		reasons := []string{fmt.Sprintf("No deployments in the last %d days.", opts.DaysUnused)}
+
+		recorder := utils.NewMetricRecorder(opts.IncludeMetricSamples)
+		var requests float64
+		for _, loadBalancer := range loadBalancers {
+			namespace, metricName, dimensionName, dimensionValue := beanstalkRequestMetric(loadBalancer)
+			var count float64
+			count, err = utils.GetResourceMetrics(cwClient, utils.MetricConfig{
+				Namespace:     namespace,
+				ResourceID:    dimensionValue,
+				DimensionName: dimensionName,
+				MetricName:    metricName,
+				Statistic:     "Sum",
+				StartTime:     startTime,
+				EndTime:       endTime,
+				Period:        86400, // 1 day
+				Recorder:      recorder,
+			})
+			if err != nil {
+				break
+			}
+			requests += count
+		}
+		switch {
+		case err != nil:
+			logging.Error("Failed to get Elastic Beanstalk environment requests", err, map[string]interface{}{
+				"environment_id": environmentID,
+			})
+			if !utils.MetricsDegraded(err) {
+				continue
+			}
+			utils.MarkMetricsDegraded(details, err)
+			reasons = append(reasons, utils.MetricsUnavailableReason)
+		case requests > 0:
+			// Environments serving traffic are in use even if they are no longer deployed
+			continue
+		default:
+			reasons = append(reasons, fmt.Sprintf("No requests in the last %d days.", opts.DaysUnused))
+		}
+		recorder.AddTo(details)
+
+		tags := make(map[string]string)
+		tagsOutput, err := client.ListTagsForResource(&elasticbeanstalk.ListTagsForResourceInput{
+			ResourceArn: environment.EnvironmentArn,
+		})
+		if err != nil {
+			logging.Debug("Failed to list Elastic Beanstalk environment tags", map[string]interface{}{
+				"environment_id": environmentID,
+				"error":          err.Error(),
+			})
+		} else {
+			for _, tag := range tagsOutput.ResourceTags {
+				tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+			}
+		}
+
+		results = append(results, awslib.ScanResult{
+			ResourceType: s.Label(),
+			ResourceName: aws.StringValue(environment.EnvironmentName),
+			ResourceID:   aws.StringValue(environment.EnvironmentArn),
+			Reason:       strings.Join(reasons, "\n"),
+			Tags:         tags,
+			Details:      details,
+			// The instances and load balancer are billed, and priced, as EC2 and ELB resources
+			Cost: awslib.NoCost(awslib.NoCostNotEstimated),
+		})
+	}
+
+	logging.Debug("Scanned Elastic Beanstalk environments", map[string]interface{}{
+		"account_id":   opts.AccountID,
+		"region":       opts.Region,
+		"environments": len(environments),
+		"idle":         len(results),
+	})
+
+	return results, nil
+}
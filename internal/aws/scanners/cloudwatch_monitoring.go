@@ -0,0 +1,377 @@
+package scanners
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	awslib "cloudsift/internal/aws"
+	"cloudsift/internal/aws/utils"
+	"cloudsift/internal/logging"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/firehose"
+)
+
+// CloudWatch list prices in USD of the monitoring features reported by the scanner
+const (
+	contributorInsightsRulePrice = 0.50         // Per rule per month
+	compositeAlarmPrice          = 0.50         // Per composite alarm per month
+	metricStreamUpdatePrice      = 0.003 / 1000 // Per metric update
+)
+
+// Monitoring features, reported in the feature detail of findings
+const (
+	featureMetricStream   = "metric_stream"
+	featureInsightRule    = "contributor_insights_rule"
+	featureCompositeAlarm = "composite_alarm"
+)
+
+// alarmRuleChild matches the alarms an alarm rule references, by name or ARN, quoted or not
+var alarmRuleChild = regexp.MustCompile(`\b(?:ALARM|OK|INSUFFICIENT_DATA)\s*\(\s*"?([^")]+?)"?\s*\)`)
+
+// CloudWatchMonitoringScanner scans for CloudWatch monitoring features that are billed monthly
+// but deliver nothing: metric streams to deleted or inactive Firehose delivery streams,
+// Contributor Insights rules without matching log events and composite alarms referencing
+// deleted alarms or without actions
+type CloudWatchMonitoringScanner struct{}
+
+func init() {
+	awslib.DefaultRegistry.RegisterScanner(&CloudWatchMonitoringScanner{})
+}
+
+// ArgumentName implements Scanner interface
+func (s *CloudWatchMonitoringScanner) ArgumentName() string {
+	return "cloudwatch-monitoring"
+}
+
+// Label implements Scanner interface
+func (s *CloudWatchMonitoringScanner) Label() string {
+	return "CloudWatch Monitoring"
+}
+
+// RemediationTemplate implements Remediator interface
+func (s *CloudWatchMonitoringScanner) RemediationTemplate() string {
+	return `{{$feature := index .Details "feature"}}` +
+		`{{if eq $feature "metric_stream"}}aws cloudwatch delete-metric-stream --name {{.ResourceName}} --region {{.Region}}` +
+		`{{else if eq $feature "contributor_insights_rule"}}aws cloudwatch delete-insight-rules --rule-names {{.ResourceName}} --region {{.Region}}` +
+		`{{else if eq $feature "composite_alarm"}}aws cloudwatch delete-alarms --alarm-names {{.ResourceName}} --region {{.Region}}{{end}}`
+}
+
+// monitoringCost calculates the cost of a monitoring feature from its monthly price
+func monitoringCost(monthlyPrice float64, region string, createdAt time.Time) map[string]interface{} {
+	if awslib.DefaultCostEstimator == nil || monthlyPrice == 0 {
+		return nil
+	}
+	costs, err := awslib.DefaultCostEstimator.CalculateCost(awslib.ResourceCostConfig{
+		ResourceType: "CloudWatch",
+		ResourceSize: monthlyPrice,
+		Region:       region,
+		CreationTime: createdAt,
+	})
+	if err != nil {
+		logging.Error("Failed to calculate CloudWatch costs", err, map[string]interface{}{
+			"region": region,
+		})
+		return nil
+	}
+	return map[string]interface{}{
+		"total": costs,
+	}
+}
+
+// Scan implements Scanner interface
+func (s *CloudWatchMonitoringScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, error) {
+	sess, err := awslib.GetSessionInRegion(opts.Session, opts.Region)
+	if err != nil {
+		logging.Error("Failed to create regional session", err, map[string]interface{}{
+			"region": opts.Region,
+		})
+		return nil, fmt.Errorf("failed to create regional session: %w", err)
+	}
+	cwClient := cloudwatch.New(sess)
+	endTime := time.Now().UTC()
+	startTime := endTime.Add(-time.Duration(opts.DaysUnused) * 24 * time.Hour)
+
+	logging.Debug("Starting CloudWatch monitoring scan", map[string]interface{}{
+		"account_id": opts.AccountID,
+		"region":     opts.Region,
+	})
+
+	streams, err := s.scanMetricStreams(opts, cwClient, firehose.New(sess), startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := s.scanInsightRules(opts, cwClient, startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+	alarms, err := s.scanCompositeAlarms(opts, cwClient)
+	if err != nil {
+		return nil, err
+	}
+
+	var results awslib.ScanResults
+	results = append(results, streams...)
+	results = append(results, rules...)
+	results = append(results, alarms...)
+
+	logging.Debug("CloudWatch monitoring scan completed", map[string]interface{}{
+		"account_id": opts.AccountID,
+		"region":     opts.Region,
+		"findings":   len(results),
+	})
+
+	return results, nil
+}
+
+// deliveryStreamStatus returns the status of the Firehose delivery stream a metric stream
+// sends to, "DELETED" if it no longer exists, or "" if it cannot be checked because it belongs
+// to another account or region
+func (s *CloudWatchMonitoringScanner) deliveryStreamStatus(opts awslib.ScanOptions, client *firehose.Firehose, firehoseArn string) (string, error) {
+	parsed, err := arn.Parse(firehoseArn)
+	if err != nil || parsed.AccountID != opts.AccountID || parsed.Region != opts.Region {
+		return "", nil
+	}
+	name := strings.TrimPrefix(parsed.Resource, "deliverystream/")
+
+	output, err := client.DescribeDeliveryStream(&firehose.DescribeDeliveryStreamInput{
+		DeliveryStreamName: aws.String(name),
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == firehose.ErrCodeResourceNotFoundException {
+			return "DELETED", nil
+		}
+		return "", fmt.Errorf("failed to describe delivery stream: %w", err)
+	}
+	return aws.StringValue(output.DeliveryStreamDescription.DeliveryStreamStatus), nil
+}
+
+// scanMetricStreams reports running metric streams whose Firehose delivery stream was deleted
+// or is not active, so the metric updates they are billed for are never delivered
+func (s *CloudWatchMonitoringScanner) scanMetricStreams(opts awslib.ScanOptions, cwClient *cloudwatch.CloudWatch, firehoseClient *firehose.Firehose, startTime, endTime time.Time) (awslib.ScanResults, error) {
+	var streams []*cloudwatch.MetricStreamEntry
+	err := cwClient.ListMetricStreamsPages(&cloudwatch.ListMetricStreamsInput{}, func(page *cloudwatch.ListMetricStreamsOutput, lastPage bool) bool {
+		streams = append(streams, page.Entries...)
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list metric streams: %w", err)
+	}
+
+	var results awslib.ScanResults
+	for _, stream := range streams {
+		name := aws.StringValue(stream.Name)
+		if aws.StringValue(stream.State) != "running" {
+			continue
+		}
+
+		status, err := s.deliveryStreamStatus(opts, firehoseClient, aws.StringValue(stream.FirehoseArn))
+		if err != nil {
+			logging.Error("Failed to check metric stream destination", err, map[string]interface{}{
+				"metric_stream": name,
+				"firehose_arn":  aws.StringValue(stream.FirehoseArn),
+			})
+			continue
+		}
+		if status == "" || status == firehose.DeliveryStreamStatusActive || status == firehose.DeliveryStreamStatusCreating {
+			continue
+		}
+
+		reason := fmt.Sprintf("Metric stream sends to a Firehose delivery stream that is %s.", strings.ToLower(strings.ReplaceAll(status, "_", " ")))
+		if status == "DELETED" {
+			reason = "Metric stream sends to a deleted Firehose delivery stream."
+		}
+
+		details := map[string]interface{}{
+			"feature":         featureMetricStream,
+			"account_id":      opts.AccountID,
+			"region":          opts.Region,
+			"firehose_arn":    aws.StringValue(stream.FirehoseArn),
+			"firehose_status": status,
+			"output_format":   aws.StringValue(stream.OutputFormat),
+			"created_at":      aws.TimeValue(stream.CreationDate).Format(time.RFC3339),
+		}
+
+		// Metric streams are billed per metric update, whether or not they are delivered
+		monthlyPrice := 0.0
+		dailyUpdates, err := utils.GetResourceMetrics(cwClient, utils.MetricConfig{
+			Namespace:     "AWS/CloudWatch/MetricStreams",
+			ResourceID:    name,
+			DimensionName: "MetricStreamName",
+			MetricName:    "MetricUpdate",
+			Statistic:     "Sum",
+			StartTime:     startTime,
+			EndTime:       endTime,
+			Period:        86400,
+		})
+		if err != nil {
+			logging.Debug("Failed to get metric stream updates", map[string]interface{}{
+				"metric_stream": name,
+				"error":         err.Error(),
+			})
+		} else {
+			details["daily_metric_updates"] = dailyUpdates
+			monthlyPrice = dailyUpdates * 30 * metricStreamUpdatePrice
+		}
+
+		results = append(results, awslib.ScanResult{
+			ResourceType: s.Label(),
+			ResourceName: name,
+			ResourceID:   aws.StringValue(stream.Arn),
+			Reason:       reason,
+			Details:      details,
+			Cost:         monitoringCost(monthlyPrice, opts.Region, aws.TimeValue(stream.CreationDate)),
+		})
+	}
+	return results, nil
+}
+
+// scanInsightRules reports enabled Contributor Insights rules that matched no log events
+func (s *CloudWatchMonitoringScanner) scanInsightRules(opts awslib.ScanOptions, cwClient *cloudwatch.CloudWatch, startTime, endTime time.Time) (awslib.ScanResults, error) {
+	var rules []*cloudwatch.InsightRule
+	err := cwClient.DescribeInsightRulesPages(&cloudwatch.DescribeInsightRulesInput{}, func(page *cloudwatch.DescribeInsightRulesOutput, lastPage bool) bool {
+		rules = append(rules, page.InsightRules...)
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe Contributor Insights rules: %w", err)
+	}
+
+	var results awslib.ScanResults
+	for _, rule := range rules {
+		name := aws.StringValue(rule.Name)
+		if aws.StringValue(rule.State) != "ENABLED" {
+			continue
+		}
+
+		report, err := cwClient.GetInsightRuleReport(&cloudwatch.GetInsightRuleReportInput{
+			RuleName:  aws.String(name),
+			StartTime: aws.Time(startTime),
+			EndTime:   aws.Time(endTime),
+			Period:    aws.Int64(86400),
+			Metrics:   []*string{aws.String("SampleCount")},
+		})
+		if err != nil {
+			logging.Error("Failed to get Contributor Insights rule report", err, map[string]interface{}{
+				"rule_name": name,
+			})
+			continue
+		}
+
+		matched := 0.0
+		for _, datapoint := range report.MetricDatapoints {
+			matched += aws.Float64Value(datapoint.SampleCount)
+		}
+		if matched > 0 || len(report.Contributors) > 0 {
+			continue
+		}
+
+		results = append(results, awslib.ScanResult{
+			ResourceType: s.Label(),
+			ResourceName: name,
+			ResourceID:   name,
+			Reason:       fmt.Sprintf("Contributor Insights rule matched no log events in the last %d days.", opts.DaysUnused),
+			Details: map[string]interface{}{
+				"feature":      featureInsightRule,
+				"account_id":   opts.AccountID,
+				"region":       opts.Region,
+				"managed_rule": aws.BoolValue(rule.ManagedRule),
+				"schema":       aws.StringValue(rule.Schema),
+			},
+			// Rules have no creation date, so the lifetime cost covers the window without matches
+			Cost: monitoringCost(contributorInsightsRulePrice, opts.Region, startTime),
+		})
+	}
+	return results, nil
+}
+
+// scanCompositeAlarms reports composite alarms whose rule references deleted alarms, and
+// composite alarms that take no action and are not referenced by another composite alarm
+func (s *CloudWatchMonitoringScanner) scanCompositeAlarms(opts awslib.ScanOptions, cwClient *cloudwatch.CloudWatch) (awslib.ScanResults, error) {
+	var composites []*cloudwatch.CompositeAlarm
+	alarms := make(map[string]bool) // Names and ARNs of all alarms
+	err := cwClient.DescribeAlarmsPages(&cloudwatch.DescribeAlarmsInput{
+		AlarmTypes: aws.StringSlice([]string{cloudwatch.AlarmTypeCompositeAlarm, cloudwatch.AlarmTypeMetricAlarm}),
+	}, func(page *cloudwatch.DescribeAlarmsOutput, lastPage bool) bool {
+		for _, alarm := range page.MetricAlarms {
+			alarms[aws.StringValue(alarm.AlarmName)] = true
+			alarms[aws.StringValue(alarm.AlarmArn)] = true
+		}
+		for _, alarm := range page.CompositeAlarms {
+			alarms[aws.StringValue(alarm.AlarmName)] = true
+			alarms[aws.StringValue(alarm.AlarmArn)] = true
+			composites = append(composites, alarm)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe alarms: %w", err)
+	}
+
+	// Composite alarms referenced by another composite alarm act through it
+	children := make(map[string][]string)
+	referenced := make(map[string]bool)
+	for _, alarm := range composites {
+		for _, match := range alarmRuleChild.FindAllStringSubmatch(aws.StringValue(alarm.AlarmRule), -1) {
+			children[aws.StringValue(alarm.AlarmName)] = append(children[aws.StringValue(alarm.AlarmName)], match[1])
+			referenced[match[1]] = true
+		}
+	}
+
+	var results awslib.ScanResults
+	for _, alarm := range composites {
+		name := aws.StringValue(alarm.AlarmName)
+
+		var deleted []string
+		for _, child := range children[name] {
+			if !alarms[child] {
+				deleted = append(deleted, child)
+			}
+		}
+		sort.Strings(deleted)
+
+		hasActions := aws.BoolValue(alarm.ActionsEnabled) &&
+			len(alarm.AlarmActions)+len(alarm.OKActions)+len(alarm.InsufficientDataActions) > 0
+		unused := !hasActions && !referenced[name] && !referenced[aws.StringValue(alarm.AlarmArn)]
+
+		var reasons []string
+		if len(deleted) > 0 {
+			reasons = append(reasons, fmt.Sprintf("Composite alarm references deleted alarms: %s.", strings.Join(deleted, ", ")))
+		}
+		if unused {
+			reasons = append(reasons, "Composite alarm has no actions and is not used by another composite alarm.")
+		}
+		if len(reasons) == 0 {
+			continue
+		}
+
+		details := map[string]interface{}{
+			"feature":         featureCompositeAlarm,
+			"account_id":      opts.AccountID,
+			"region":          opts.Region,
+			"alarm_rule":      aws.StringValue(alarm.AlarmRule),
+			"state":           aws.StringValue(alarm.StateValue),
+			"actions_enabled": aws.BoolValue(alarm.ActionsEnabled),
+			"updated_at":      aws.TimeValue(alarm.AlarmConfigurationUpdatedTimestamp).Format(time.RFC3339),
+		}
+		if len(deleted) > 0 {
+			details["deleted_children"] = deleted
+		}
+
+		results = append(results, awslib.ScanResult{
+			ResourceType: s.Label(),
+			ResourceName: name,
+			ResourceID:   aws.StringValue(alarm.AlarmArn),
+			Reason:       strings.Join(reasons, "\n"),
+			Details:      details,
+			Cost:         monitoringCost(compositeAlarmPrice, opts.Region, aws.TimeValue(alarm.AlarmConfigurationUpdatedTimestamp)),
+		})
+	}
+	return results, nil
+}
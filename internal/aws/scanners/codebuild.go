@@ -0,0 +1,277 @@
+package scanners
+
+import (
+	"fmt"
+	"time"
+
+	awslib "cloudsift/internal/aws"
+	"cloudsift/internal/logging"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/codebuild"
+)
+
+// codebuildBatchSize is the maximum number of names accepted by the CodeBuild batch APIs
+const codebuildBatchSize = 100
+
+// CodeBuildScanner scans for CodeBuild projects without builds and reserved capacity fleets
+// whose projects no longer build. Fleets are billed for their base capacity while they are
+// provisioned, whether or not builds run on them.
+type CodeBuildScanner struct{}
+
+func init() {
+	awslib.DefaultRegistry.RegisterScanner(&CodeBuildScanner{})
+}
+
+// ArgumentName implements Scanner interface
+func (s *CodeBuildScanner) ArgumentName() string {
+	return "codebuild"
+}
+
+// Label implements Scanner interface
+func (s *CodeBuildScanner) Label() string {
+	return "CodeBuild Resources"
+}
+
+// RemediationTemplate implements Remediator interface. Projects and reserved capacity fleets
+// are deleted with their own commands.
+func (s *CodeBuildScanner) RemediationTemplate() string {
+	return `{{if eq (index .Details "codebuild_resource_type") "fleet"}}aws codebuild delete-fleet --arn {{.ResourceID}}{{else}}aws codebuild delete-project --name {{.ResourceName}}{{end}} --region {{.Region}}`
+}
+
+// HasResources implements ResourceProber interface
+func (s *CodeBuildScanner) HasResources(opts awslib.ScanOptions) (bool, error) {
+	sess, err := awslib.GetSessionInRegion(opts.Session, opts.Region)
+	if err != nil {
+		return false, fmt.Errorf("failed to create regional session: %w", err)
+	}
+
+	output, err := codebuild.New(sess).ListProjects(&codebuild.ListProjectsInput{})
+	if err != nil {
+		return false, fmt.Errorf("failed to list CodeBuild projects: %w", err)
+	}
+	return len(output.Projects) > 0, nil
+}
+
+// projects returns all CodeBuild projects of the region
+func (s *CodeBuildScanner) projects(client *codebuild.CodeBuild) ([]*codebuild.Project, error) {
+	var names []*string
+	err := client.ListProjectsPages(&codebuild.ListProjectsInput{}, func(page *codebuild.ListProjectsOutput, lastPage bool) bool {
+		names = append(names, page.Projects...)
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list CodeBuild projects: %w", err)
+	}
+
+	var projects []*codebuild.Project
+	for i := 0; i < len(names); i += codebuildBatchSize {
+		end := i + codebuildBatchSize
+		if end > len(names) {
+			end = len(names)
+		}
+		output, err := client.BatchGetProjects(&codebuild.BatchGetProjectsInput{Names: names[i:end]})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get CodeBuild projects: %w", err)
+		}
+		projects = append(projects, output.Projects...)
+	}
+	return projects, nil
+}
+
+// fleets returns all CodeBuild reserved capacity fleets of the region
+func (s *CodeBuildScanner) fleets(client *codebuild.CodeBuild) ([]*codebuild.Fleet, error) {
+	var arns []*string
+	err := client.ListFleetsPages(&codebuild.ListFleetsInput{}, func(page *codebuild.ListFleetsOutput, lastPage bool) bool {
+		arns = append(arns, page.Fleets...)
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list CodeBuild fleets: %w", err)
+	}
+
+	var fleets []*codebuild.Fleet
+	for i := 0; i < len(arns); i += codebuildBatchSize {
+		end := i + codebuildBatchSize
+		if end > len(arns) {
+			end = len(arns)
+		}
+		output, err := client.BatchGetFleets(&codebuild.BatchGetFleetsInput{Names: arns[i:end]})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get CodeBuild fleets: %w", err)
+		}
+		fleets = append(fleets, output.Fleets...)
+	}
+	return fleets, nil
+}
+
+// lastBuild returns the start time of the most recent build of a project, or nil if the
+// project was never built
+func (s *CodeBuildScanner) lastBuild(client *codebuild.CodeBuild, projectName string) (*time.Time, error) {
+	// Builds are listed most recent first
+	output, err := client.ListBuildsForProject(&codebuild.ListBuildsForProjectInput{
+		ProjectName: aws.String(projectName),
+		SortOrder:   aws.String(codebuild.SortOrderTypeDescending),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list builds: %w", err)
+	}
+	if len(output.Ids) == 0 {
+		return nil, nil
+	}
+
+	builds, err := client.BatchGetBuilds(&codebuild.BatchGetBuildsInput{Ids: output.Ids[:1]})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get build: %w", err)
+	}
+	if len(builds.Builds) == 0 {
+		return nil, nil
+	}
+	return builds.Builds[0].StartTime, nil
+}
+
+// codebuildTags converts CodeBuild tags to a map
+func codebuildTags(tags []*codebuild.Tag) map[string]string {
+	result := make(map[string]string, len(tags))
+	for _, tag := range tags {
+		result[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+	}
+	return result
+}
+
+// Scan implements Scanner interface
+func (s *CodeBuildScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, error) {
+	sess, err := awslib.GetSessionInRegion(opts.Session, opts.Region)
+	if err != nil {
+		logging.Error("Failed to create regional session", err, map[string]interface{}{
+			"region": opts.Region,
+		})
+		return nil, fmt.Errorf("failed to create regional session: %w", err)
+	}
+	client := codebuild.New(sess)
+
+	projects, err := s.projects(client)
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := time.Now().UTC().Add(-time.Duration(opts.DaysUnused) * 24 * time.Hour)
+
+	// Fleets with a project that built in the threshold period are in use
+	activeFleets := make(map[string]bool)
+	fleetProjects := make(map[string][]string)
+
+	var results awslib.ScanResults
+	for _, project := range projects {
+		name := aws.StringValue(project.Name)
+		var fleetArn string
+		if project.Environment != nil && project.Environment.Fleet != nil {
+			fleetArn = aws.StringValue(project.Environment.Fleet.FleetArn)
+			fleetProjects[fleetArn] = append(fleetProjects[fleetArn], name)
+		}
+
+		lastBuild, err := s.lastBuild(client, name)
+		if err != nil {
+			logging.Error("Failed to get CodeBuild project builds", err, map[string]interface{}{
+				"project_name": name,
+			})
+			if fleetArn != "" {
+				// Do not report the fleet of a project whose builds could not be checked
+				activeFleets[fleetArn] = true
+			}
+			continue
+		}
+		if lastBuild != nil && lastBuild.After(cutoff) {
+			if fleetArn != "" {
+				activeFleets[fleetArn] = true
+			}
+			continue
+		}
+
+		createdAt := aws.TimeValue(project.Created)
+		if createdAt.After(cutoff) {
+			continue
+		}
+
+		details := map[string]interface{}{
+			"account_id":              opts.AccountID,
+			"region":                  opts.Region,
+			"codebuild_resource_type": "project",
+			"created_at":              createdAt.Format(time.RFC3339),
+		}
+		if project.Environment != nil {
+			details["compute_type"] = aws.StringValue(project.Environment.ComputeType)
+		}
+		if fleetArn != "" {
+			details["fleet_arn"] = fleetArn
+		}
+
+		reason := "Project has never been built."
+		if lastBuild != nil {
+			details["last_build"] = lastBuild.Format(time.RFC3339)
+			reason = fmt.Sprintf("No builds in the last %d days.", opts.DaysUnused)
+		}
+
+		results = append(results, awslib.ScanResult{
+			ResourceType: s.Label(),
+			ResourceName: name,
+			ResourceID:   aws.StringValue(project.Arn),
+			Reason:       reason,
+			Details:      details,
+			Cost:         awslib.NoCost(awslib.NoCostNotEstimated),
+			Tags:         codebuildTags(project.Tags),
+		})
+	}
+
+	fleets, err := s.fleets(client)
+	if err != nil {
+		// Fleets are only available in some regions, so projects are still reported
+		logging.Warn("Failed to get CodeBuild fleets", map[string]interface{}{
+			"region": opts.Region,
+			"error":  err.Error(),
+		})
+	}
+	for _, fleet := range fleets {
+		arn := aws.StringValue(fleet.Arn)
+		createdAt := aws.TimeValue(fleet.Created)
+		if activeFleets[arn] || createdAt.After(cutoff) {
+			continue
+		}
+		if fleet.Status == nil || aws.StringValue(fleet.Status.StatusCode) != codebuild.FleetStatusCodeActive {
+			continue
+		}
+
+		reason := fmt.Sprintf("Fleet keeps %d instances of reserved capacity but no project built on it in the last %d days.", aws.Int64Value(fleet.BaseCapacity), opts.DaysUnused)
+		details := map[string]interface{}{
+			"account_id":              opts.AccountID,
+			"region":                  opts.Region,
+			"codebuild_resource_type": "fleet",
+			"base_capacity":           aws.Int64Value(fleet.BaseCapacity),
+			"compute_type":            aws.StringValue(fleet.ComputeType),
+			"environment_type":        aws.StringValue(fleet.EnvironmentType),
+			"overflow_behavior":       aws.StringValue(fleet.OverflowBehavior),
+			"projects":                fleetProjects[arn],
+			"created_at":              createdAt.Format(time.RFC3339),
+		}
+
+		results = append(results, awslib.ScanResult{
+			ResourceType: s.Label(),
+			ResourceName: aws.StringValue(fleet.Name),
+			ResourceID:   arn,
+			Reason:       reason,
+			Details:      details,
+			Cost:         awslib.NoCost(awslib.NoCostNotEstimated),
+			Tags:         codebuildTags(fleet.Tags),
+		})
+	}
+
+	logging.Info("Completed CodeBuild scan", map[string]interface{}{
+		"account_id": opts.AccountID,
+		"region":     opts.Region,
+		"projects":   len(projects),
+		"fleets":     len(fleets),
+		"reported":   len(results),
+	})
+
+	return results, nil
+}
@@ -0,0 +1,135 @@
+package scanners
+
+import (
+	"fmt"
+	"time"
+
+	awslib "cloudsift/internal/aws"
+	"cloudsift/internal/logging"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/codepipeline"
+)
+
+// CodePipelineScanner scans for CodePipeline pipelines without executions
+type CodePipelineScanner struct{}
+
+func init() {
+	awslib.DefaultRegistry.RegisterScanner(&CodePipelineScanner{})
+}
+
+// ArgumentName implements Scanner interface
+func (s *CodePipelineScanner) ArgumentName() string {
+	return "codepipeline-pipelines"
+}
+
+// Label implements Scanner interface
+func (s *CodePipelineScanner) Label() string {
+	return "CodePipeline Pipelines"
+}
+
+// RemediationTemplate implements Remediator interface
+func (s *CodePipelineScanner) RemediationTemplate() string {
+	return "aws codepipeline delete-pipeline --name {{.ResourceID}} --region {{.Region}}"
+}
+
+// HasResources implements ResourceProber interface
+func (s *CodePipelineScanner) HasResources(opts awslib.ScanOptions) (bool, error) {
+	sess, err := awslib.GetSessionInRegion(opts.Session, opts.Region)
+	if err != nil {
+		return false, fmt.Errorf("failed to create regional session: %w", err)
+	}
+
+	output, err := codepipeline.New(sess).ListPipelines(&codepipeline.ListPipelinesInput{})
+	if err != nil {
+		return false, fmt.Errorf("failed to list pipelines: %w", err)
+	}
+	return len(output.Pipelines) > 0, nil
+}
+
+// lastExecution returns the most recent execution of a pipeline, or nil if it never ran
+func (s *CodePipelineScanner) lastExecution(client *codepipeline.CodePipeline, pipelineName string) (*codepipeline.PipelineExecutionSummary, error) {
+	// Executions are listed most recent first
+	output, err := client.ListPipelineExecutions(&codepipeline.ListPipelineExecutionsInput{
+		PipelineName: aws.String(pipelineName),
+		MaxResults:   aws.Int64(1),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pipeline executions: %w", err)
+	}
+	if len(output.PipelineExecutionSummaries) == 0 {
+		return nil, nil
+	}
+	return output.PipelineExecutionSummaries[0], nil
+}
+
+// Scan implements Scanner interface
+func (s *CodePipelineScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, error) {
+	sess, err := awslib.GetSessionInRegion(opts.Session, opts.Region)
+	if err != nil {
+		logging.Error("Failed to create regional session", err, map[string]interface{}{
+			"region": opts.Region,
+		})
+		return nil, fmt.Errorf("failed to create regional session: %w", err)
+	}
+	client := codepipeline.New(sess)
+
+	var pipelines []*codepipeline.PipelineSummary
+	err = client.ListPipelinesPages(&codepipeline.ListPipelinesInput{}, func(page *codepipeline.ListPipelinesOutput, lastPage bool) bool {
+		pipelines = append(pipelines, page.Pipelines...)
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pipelines: %w", err)
+	}
+
+	cutoff := time.Now().UTC().Add(-time.Duration(opts.DaysUnused) * 24 * time.Hour)
+
+	var results awslib.ScanResults
+	for _, pipeline := range pipelines {
+		name := aws.StringValue(pipeline.Name)
+		createdAt := aws.TimeValue(pipeline.Created)
+		if createdAt.After(cutoff) {
+			continue
+		}
+
+		execution, err := s.lastExecution(client, name)
+		if err != nil {
+			logging.Error("Failed to get pipeline executions", err, map[string]interface{}{
+				"pipeline_name": name,
+			})
+			continue
+		}
+		if execution != nil && aws.TimeValue(execution.StartTime).After(cutoff) {
+			continue
+		}
+
+		details := map[string]interface{}{
+			"account_id":     opts.AccountID,
+			"region":         opts.Region,
+			"pipeline_type":  aws.StringValue(pipeline.PipelineType),
+			"execution_mode": aws.StringValue(pipeline.ExecutionMode),
+			"version":        aws.Int64Value(pipeline.Version),
+			"created_at":     createdAt.Format(time.RFC3339),
+			"updated_at":     aws.TimeValue(pipeline.Updated).Format(time.RFC3339),
+		}
+
+		reason := "Pipeline has never been executed."
+		if execution != nil {
+			details["last_execution"] = aws.TimeValue(execution.StartTime).Format(time.RFC3339)
+			details["last_execution_status"] = aws.StringValue(execution.Status)
+			reason = fmt.Sprintf("No pipeline executions in the last %d days.", opts.DaysUnused)
+		}
+
+		results = append(results, awslib.ScanResult{
+			ResourceType: s.Label(),
+			ResourceName: name,
+			ResourceID:   name,
+			Reason:       reason,
+			Details:      details,
+			Cost:         awslib.NoCost(awslib.NoCostNotEstimated),
+		})
+	}
+
+	return results, nil
+}
@@ -2,6 +2,7 @@ package scanners
 
 import (
 	"fmt"
+	"math"
 	"strings"
 	"time"
 
@@ -31,8 +32,30 @@ func (s *DynamoDBScanner) Label() string {
 	return "DynamoDB Tables"
 }
 
+// RemediationTemplate implements Remediator interface. Tables are deleted without a backup, so
+// create one first if the data may be needed.
+func (s *DynamoDBScanner) RemediationTemplate() string {
+	return "aws dynamodb delete-table --table-name {{.ResourceName}} --region {{.Region}}"
+}
+
+// HasResources implements ResourceProber interface
+func (s *DynamoDBScanner) HasResources(opts awslib.ScanOptions) (bool, error) {
+	sess, err := awslib.GetSessionInRegion(opts.Session, opts.Region)
+	if err != nil {
+		return false, fmt.Errorf("failed to create regional session: %w", err)
+	}
+
+	output, err := dynamodb.New(sess).ListTables(&dynamodb.ListTablesInput{
+		Limit: aws.Int64(1),
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to list DynamoDB tables: %w", err)
+	}
+	return len(output.TableNames) > 0, nil
+}
+
 // getTableMetrics retrieves CloudWatch metrics for a DynamoDB table
-func (s *DynamoDBScanner) getTableMetrics(cwClient *cloudwatch.CloudWatch, tableName string, startTime, endTime time.Time) (map[string]float64, error) {
+func (s *DynamoDBScanner) getTableMetrics(cwClient *cloudwatch.CloudWatch, tableName string, startTime, endTime time.Time, recorder *utils.MetricRecorder) (map[string]float64, error) {
 	metrics := []utils.MetricConfig{
 		{
 			Namespace:     "AWS/DynamoDB",
@@ -63,6 +86,10 @@ func (s *DynamoDBScanner) getTableMetrics(cwClient *cloudwatch.CloudWatch, table
 		},
 	}
 
+	for i := range metrics {
+		metrics[i].Recorder = recorder
+	}
+
 	results, err := utils.GetResourceMetricsData(cwClient, metrics)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get metrics: %w", err)
@@ -122,6 +149,66 @@ func (s *DynamoDBScanner) determineUnusedReasons(metrics map[string]float64, ite
 	return reasons
 }
 
+// provisionedCapacity returns the provisioned read and write capacity units of a table and
+// its global secondary indexes, or zero for on-demand tables
+func provisionedCapacity(table *dynamodb.TableDescription) (int64, int64) {
+	if table.BillingModeSummary != nil && aws.StringValue(table.BillingModeSummary.BillingMode) == dynamodb.BillingModePayPerRequest {
+		return 0, 0
+	}
+	var read, write int64
+	if table.ProvisionedThroughput != nil {
+		read += aws.Int64Value(table.ProvisionedThroughput.ReadCapacityUnits)
+		write += aws.Int64Value(table.ProvisionedThroughput.WriteCapacityUnits)
+	}
+	for _, index := range table.GlobalSecondaryIndexes {
+		if index.ProvisionedThroughput != nil {
+			read += aws.Int64Value(index.ProvisionedThroughput.ReadCapacityUnits)
+			write += aws.Int64Value(index.ProvisionedThroughput.WriteCapacityUnits)
+		}
+	}
+	return read, write
+}
+
+// idleProvisionedReason reports a provisioned table whose capacity was neither consumed nor
+// decreased during the threshold period, so it was billed for the whole period
+func (s *DynamoDBScanner) idleProvisionedReason(metrics map[string]float64, table *dynamodb.TableDescription, read, write int64, startTime time.Time, opts awslib.ScanOptions) string {
+	if read+write == 0 || metrics["read_throughput"] > 0 || metrics["write_throughput"] > 0 {
+		return ""
+	}
+	if table.ProvisionedThroughput != nil {
+		if lastDecrease := table.ProvisionedThroughput.LastDecreaseDateTime; lastDecrease != nil && lastDecrease.After(startTime) {
+			return ""
+		}
+	}
+	return fmt.Sprintf("Provisioned capacity (%d RCU, %d WCU) unused and not decreased in %d days.", read, write, opts.DaysUnused)
+}
+
+// calculateTableCost returns the cost of the storage and provisioned capacity of a table,
+// and the monthly cost of the storage alone
+func (s *DynamoDBScanner) calculateTableCost(table *dynamodb.TableDescription, read, write int64, region string) (*awslib.CostBreakdown, float64, error) {
+	costConfig := awslib.ResourceCostConfig{
+		ResourceType: "DynamoDB",
+		ResourceSize: aws.Int64Value(table.TableSizeBytes),
+		Region:       region,
+		CreationTime: aws.TimeValue(table.CreationDateTime),
+	}
+	storageCost, err := awslib.DefaultCostEstimator.CalculateCost(costConfig)
+	if err != nil {
+		return nil, 0, err
+	}
+	if read+write == 0 {
+		return storageCost, storageCost.MonthlyRate, nil
+	}
+
+	costConfig.ReadCapacityUnits = read
+	costConfig.WriteCapacityUnits = write
+	totalCost, err := awslib.DefaultCostEstimator.CalculateCost(costConfig)
+	if err != nil {
+		return nil, 0, err
+	}
+	return totalCost, storageCost.MonthlyRate, nil
+}
+
 // Scan implements Scanner interface
 func (s *DynamoDBScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, error) {
 	// Get regional session
@@ -170,21 +257,34 @@ func (s *DynamoDBScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, err
 		}
 
 		// Get table metrics
-		metrics, err := s.getTableMetrics(cwClient, *tableName, startTime, endTime)
+		recorder := utils.NewMetricRecorder(opts.IncludeMetricSamples)
+		metrics, err := s.getTableMetrics(cwClient, *tableName, startTime, endTime, recorder)
+		metricsErr, metricsUnavailable := err, utils.MetricsDegraded(err)
 		if err != nil {
 			logging.Error("Failed to get table metrics", err, map[string]interface{}{
 				"table_name": *tableName,
 			})
-			continue
+			if !metricsUnavailable {
+				continue
+			}
 		}
 
 		// Determine if table is unused/underutilized
-		reasons := s.determineUnusedReasons(metrics,
-			aws.Int64Value(tableDesc.Table.ItemCount),
-			aws.Int64Value(tableDesc.Table.TableSizeBytes),
-			aws.Int64Value(tableDesc.Table.ProvisionedThroughput.ReadCapacityUnits),
-			aws.Int64Value(tableDesc.Table.ProvisionedThroughput.WriteCapacityUnits),
-			opts)
+		provisionedRead, provisionedWrite := provisionedCapacity(tableDesc.Table)
+		var reasons []string
+		if metricsUnavailable {
+			reasons = []string{utils.MetricsUnavailableReason}
+		} else {
+			if reason := s.idleProvisionedReason(metrics, tableDesc.Table, provisionedRead, provisionedWrite, startTime, opts); reason != "" {
+				reasons = append(reasons, reason)
+			}
+			reasons = append(reasons, s.determineUnusedReasons(metrics,
+				aws.Int64Value(tableDesc.Table.ItemCount),
+				aws.Int64Value(tableDesc.Table.TableSizeBytes),
+				aws.Int64Value(tableDesc.Table.ProvisionedThroughput.ReadCapacityUnits),
+				aws.Int64Value(tableDesc.Table.ProvisionedThroughput.WriteCapacityUnits),
+				opts)...)
+		}
 
 		if len(reasons) > 0 {
 			details := map[string]interface{}{
@@ -203,15 +303,27 @@ func (s *DynamoDBScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, err
 			} else {
 				details["BillingMode"] = "PROVISIONED" // Default billing mode
 			}
+			if metricsUnavailable {
+				utils.MarkMetricsDegraded(details, metricsErr)
+			}
+			recorder.AddTo(details)
 
 			// Add provisioned throughput if available
 			if tableDesc.Table.ProvisionedThroughput != nil {
 				details["ProvisionedRead"] = aws.Int64Value(tableDesc.Table.ProvisionedThroughput.ReadCapacityUnits)
 				details["ProvisionedWrite"] = aws.Int64Value(tableDesc.Table.ProvisionedThroughput.WriteCapacityUnits)
+				if lastDecrease := tableDesc.Table.ProvisionedThroughput.LastDecreaseDateTime; lastDecrease != nil {
+					details["LastDecreaseDateTime"] = lastDecrease.Format(time.RFC3339)
+				}
 			} else {
 				details["ProvisionedRead"] = 0
 				details["ProvisionedWrite"] = 0
 			}
+			if len(tableDesc.Table.GlobalSecondaryIndexes) > 0 {
+				// Index capacity is billed like table capacity
+				details["TotalProvisionedRead"] = provisionedRead
+				details["TotalProvisionedWrite"] = provisionedWrite
+			}
 
 			result := awslib.ScanResult{
 				ResourceType: s.Label(),
@@ -219,6 +331,20 @@ func (s *DynamoDBScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, err
 				ResourceID:   *tableName,
 				Reason:       strings.Join(reasons, "\n"),
 				Details:      details,
+				Cost:         awslib.NoCost(awslib.NoCostNotEstimated),
+			}
+
+			// On-demand tables are only charged for storage while idle
+			if cost, storageMonthlyCost, err := s.calculateTableCost(tableDesc.Table, provisionedRead, provisionedWrite, opts.Region); err != nil {
+				logging.Error("Failed to calculate cost", err, map[string]interface{}{
+					"table_name": *tableName,
+				})
+			} else if cost != nil {
+				result.Cost = map[string]interface{}{
+					"total": cost,
+				}
+				details["storage_monthly_cost"] = storageMonthlyCost
+				details["provisioned_capacity_monthly_cost"] = math.Max(cost.MonthlyRate-storageMonthlyCost, 0)
 			}
 
 			results = append(results, result)
@@ -1,7 +1,9 @@
 package scanners
 
 import (
+	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	awslib "cloudsift/internal/aws"
@@ -29,33 +31,140 @@ func (s *EBSSnapshotScanner) Label() string {
 	return "EBS Snapshots"
 }
 
-// calculateSnapshotCosts calculates the cost of storing an EBS snapshot
-func (s *EBSSnapshotScanner) calculateSnapshotCosts(sizeGiB int64, hoursRunning float64) *awslib.CostBreakdown {
-	// EBS snapshot pricing is typically around $0.05 per GB-month
-	// This is an approximation as prices vary by region
-	const gbMonthRate = 0.05
+// RemediationTemplate implements Remediator interface
+func (s *EBSSnapshotScanner) RemediationTemplate() string {
+	return "aws ec2 delete-snapshot --snapshot-id {{.ResourceID}} --region {{.Region}}"
+}
 
-	// Calculate GB-month cost
-	gbMonth := float64(sizeGiB) * gbMonthRate
+// HasResources implements ResourceProber interface
+func (s *EBSSnapshotScanner) HasResources(opts awslib.ScanOptions) (bool, error) {
+	sess, err := awslib.GetSessionInRegion(opts.Session, opts.Region)
+	if err != nil {
+		return false, fmt.Errorf("failed to create regional session: %w", err)
+	}
 
-	// Convert to daily/hourly rates
-	hourlyRate := gbMonth / (30 * 24) // Approximate month to 30 days
-	dailyRate := gbMonth / 30
-	monthlyRate := gbMonth
-	yearlyRate := gbMonth * 12
+	output, err := ec2.New(sess).DescribeSnapshots(&ec2.DescribeSnapshotsInput{
+		OwnerIds:   []*string{aws.String("self")},
+		MaxResults: aws.Int64(5),
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to describe snapshots: %w", err)
+	}
+	return len(output.Snapshots) > 0, nil
+}
+
+// Reason codes for snapshots that are exposed outside the scanned accounts
+const (
+	reasonCodeSnapshotPublic        = "snapshot_public"
+	reasonCodeSnapshotSharedUnknown = "snapshot_shared_unknown_account"
+)
+
+// snapshotSharing returns whether a snapshot has public launch permissions and the accounts
+// outside knownAccounts it is shared with
+func (s *EBSSnapshotScanner) snapshotSharing(svc *ec2.EC2, snapshotID string, knownAccounts map[string]bool) (bool, []string, error) {
+	output, err := svc.DescribeSnapshotAttribute(&ec2.DescribeSnapshotAttributeInput{
+		SnapshotId: aws.String(snapshotID),
+		Attribute:  aws.String(ec2.SnapshotAttributeNameCreateVolumePermission),
+	})
+	if err != nil {
+		return false, nil, fmt.Errorf("failed to describe snapshot attribute: %w", err)
+	}
+
+	public := false
+	var unknownAccounts []string
+	for _, permission := range output.CreateVolumePermissions {
+		if aws.StringValue(permission.Group) == ec2.PermissionGroupAll {
+			public = true
+		}
+		if userID := aws.StringValue(permission.UserId); userID != "" && !knownAccounts[userID] {
+			unknownAccounts = append(unknownAccounts, userID)
+		}
+	}
+	return public, unknownAccounts, nil
+}
+
+// supersededSnapshotCount is the number of newer snapshots of the same volume from which a
+// snapshot is reported as superseded
+const supersededSnapshotCount = 3
+
+// calculateSnapshotCosts calculates the cost of storing an EBS snapshot since it was created,
+// priced per GB-month of snapshot storage in the region
+func (s *EBSSnapshotScanner) calculateSnapshotCosts(opts awslib.ScanOptions, snapshotID string, sizeGiB int64, volumeType string, created, now time.Time) *awslib.CostBreakdown {
+	if awslib.DefaultCostEstimator == nil {
+		return nil
+	}
+	costs, err := awslib.DefaultCostEstimator.CalculateCost(awslib.ResourceCostConfig{
+		ResourceType: "EBSSnapshots",
+		ResourceSize: sizeGiB,
+		Region:       opts.Region,
+		CreationTime: created,
+		VolumeType:   volumeType,
+	})
+	if err != nil {
+		logging.Error("Failed to calculate snapshot costs", err, map[string]interface{}{
+			"account_id":  opts.AccountID,
+			"region":      opts.Region,
+			"snapshot_id": snapshotID,
+		})
+		return nil
+	}
 
 	// Calculate lifetime cost
-	lifetime := float64(int(hourlyRate*hoursRunning*100+0.5)) / 100
+	hoursRunning := now.Sub(created).Hours()
+	lifetime := float64(int(costs.HourlyRate*hoursRunning*100+0.5)) / 100
 	hours := float64(int(hoursRunning*100+0.5)) / 100
+	costs.Lifetime = &lifetime
+	costs.HoursRunning = &hours
+	return costs
+}
 
-	return &awslib.CostBreakdown{
-		HourlyRate:   hourlyRate,
-		DailyRate:    dailyRate,
-		MonthlyRate:  monthlyRate,
-		YearlyRate:   yearlyRate,
-		Lifetime:     &lifetime,
-		HoursRunning: &hours,
+// existingVolumeTypes returns the types of the volumes that still exist, keyed by volume ID.
+// The volumes are looked up with a filter, as looking up deleted volume IDs directly fails
+// the whole batch.
+func (s *EBSSnapshotScanner) existingVolumeTypes(svc *ec2.EC2, volumeIDs []string) (map[string]string, error) {
+	volumeTypes := make(map[string]string)
+	// Split into batches of 200 (filter value limit)
+	for i := 0; i < len(volumeIDs); i += 200 {
+		end := min(i+200, len(volumeIDs))
+		err := svc.DescribeVolumesPages(&ec2.DescribeVolumesInput{
+			Filters: []*ec2.Filter{{
+				Name:   aws.String("volume-id"),
+				Values: aws.StringSlice(volumeIDs[i:end]),
+			}},
+		}, func(page *ec2.DescribeVolumesOutput, lastPage bool) bool {
+			for _, volume := range page.Volumes {
+				volumeTypes[aws.StringValue(volume.VolumeId)] = aws.StringValue(volume.VolumeType)
+			}
+			return true
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe volumes: %w", err)
+		}
 	}
+	return volumeTypes, nil
+}
+
+// newerSnapshotCounts returns the number of snapshots of the same volume started after each
+// snapshot, keyed by snapshot ID
+func newerSnapshotCounts(snapshots []*ec2.Snapshot) map[string]int {
+	byVolume := make(map[string][]*ec2.Snapshot)
+	for _, snapshot := range snapshots {
+		if volumeID := aws.StringValue(snapshot.VolumeId); volumeID != "" {
+			byVolume[volumeID] = append(byVolume[volumeID], snapshot)
+		}
+	}
+
+	counts := make(map[string]int)
+	for _, volumeSnapshots := range byVolume {
+		for _, snapshot := range volumeSnapshots {
+			for _, other := range volumeSnapshots {
+				if aws.TimeValue(other.StartTime).After(aws.TimeValue(snapshot.StartTime)) {
+					counts[aws.StringValue(snapshot.SnapshotId)]++
+				}
+			}
+		}
+	}
+	return counts
 }
 
 // Scan implements Scanner interface
@@ -83,182 +192,184 @@ func (s *EBSSnapshotScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults,
 		MaxResults: nil,                           // Ensure we don't limit results per page
 	}
 
-	var results awslib.ScanResults
-	volumeSnapshots := make(map[string][]string)
-	volumeTypesCache := make(map[string]string) // Cache for volume types
+	// Sharing with any of the scanned accounts is expected
+	knownAccounts := map[string]bool{opts.AccountID: true}
+	for _, accountID := range opts.KnownAccountIDs {
+		knownAccounts[accountID] = true
+	}
+
+	// AMIs using a snapshot are noted in its details. The AMIs are usually already described
+	// by the AMI scanners of the account and region.
+	var imageReferences map[string][]string
+	if images, err := ownedImages(context.Background(), opts.ScanContext(), svc); err != nil {
+		logging.Debug("Failed to describe AMIs referencing snapshots", map[string]interface{}{
+			"account_id": opts.AccountID,
+			"region":     opts.Region,
+			"error":      err.Error(),
+		})
+	} else {
+		imageReferences = imageSnapshotReferences(images)
+	}
 
 	// Track timing for operations
 	scanStart := time.Now()
-	var snapshotsProcessed int
-	var volumeLookups int
-	var costCalculations int
 
+	// All snapshots are collected first, as recent snapshots supersede older ones of the same
+	// volume
+	var snapshots []*ec2.Snapshot
 	err = svc.DescribeSnapshotsPages(input, func(page *ec2.DescribeSnapshotsOutput, lastPage bool) bool {
-		// Batch collect volume IDs that need lookup
-		volumesToLookup := make([]*string, 0)
-		snapshotsToProcess := make([]*ec2.Snapshot, 0)
-
-		logging.Debug("Processing snapshot page", map[string]interface{}{
-			"account_id":   opts.AccountID,
-			"region":       opts.Region,
-			"page_size":    len(page.Snapshots),
-			"is_last_page": lastPage,
-		})
+		snapshots = append(snapshots, page.Snapshots...)
+		return true
+	})
+	if err != nil {
+		logging.Error("Failed to describe snapshots", err, nil)
+		return nil, fmt.Errorf("failed to describe snapshots: %w", err)
+	}
 
-		for _, snapshot := range page.Snapshots {
-			snapshotsProcessed++
-			// Calculate age of snapshot
-			age := time.Since(*snapshot.StartTime)
-			ageInDays := int(age.Hours() / 24)
+	now := time.Now()
+	newerSnapshots := newerSnapshotCounts(snapshots)
+
+	// Only lookup volumes for snapshots we'll actually process
+	var snapshotsToProcess []*ec2.Snapshot
+	var volumeIDs []string
+	seenVolumes := make(map[string]bool)
+	for _, snapshot := range snapshots {
+		ageInDays := int(now.Sub(aws.TimeValue(snapshot.StartTime)).Hours() / 24)
+		if ageInDays < opts.DaysUnused {
+			continue
+		}
+		if volumeID := aws.StringValue(snapshot.VolumeId); volumeID != "" && !seenVolumes[volumeID] {
+			seenVolumes[volumeID] = true
+			volumeIDs = append(volumeIDs, volumeID)
+		}
+		snapshotsToProcess = append(snapshotsToProcess, snapshot)
+	}
 
-			// Skip if snapshot is not old enough
-			if ageInDays < opts.DaysUnused {
-				continue
-			}
+	// Rather than report every snapshot as one of a deleted volume, fail the scan if the
+	// volumes cannot be described
+	volumeTypes, err := s.existingVolumeTypes(svc, volumeIDs)
+	if err != nil {
+		return nil, err
+	}
 
-			// Only lookup volumes for snapshots we'll actually process
-			if volID := aws.StringValue(snapshot.VolumeId); volID != "" {
-				if _, exists := volumeTypesCache[volID]; !exists {
-					volumesToLookup = append(volumesToLookup, snapshot.VolumeId)
-				}
-			}
-			snapshotsToProcess = append(snapshotsToProcess, snapshot)
+	var results awslib.ScanResults
+	for _, snapshot := range snapshotsToProcess {
+		snapshotID := aws.StringValue(snapshot.SnapshotId)
+		volumeID := aws.StringValue(snapshot.VolumeId)
+
+		// Convert AWS tags to map
+		tags := make(map[string]string)
+		for _, tag := range snapshot.Tags {
+			tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
 		}
 
-		// Batch lookup volume types if needed
-		if len(volumesToLookup) > 0 {
-			// Split into batches of 200 (DescribeVolumes limit)
-			for i := 0; i < len(volumesToLookup); i += 200 {
-				end := i + 200
-				if end > len(volumesToLookup) {
-					end = len(volumesToLookup)
-				}
-				batch := volumesToLookup[i:end]
+		// Get resource name from tags or use description/snapshot ID
+		resourceName := aws.StringValue(snapshot.Description)
+		if resourceName == "" {
+			resourceName = snapshotID
+		}
 
-				volumeLookups++
-				volumeInput := &ec2.DescribeVolumesInput{
-					VolumeIds: batch,
-				}
-				volumeOutput, err := svc.DescribeVolumes(volumeInput)
-				if err != nil {
-					// Don't treat this as an error - the volume might have been deleted
-					// Just log it as debug information
-					logging.Debug("Some volumes not found during batch lookup", map[string]interface{}{
-						"account_id": opts.AccountID,
-						"region":     opts.Region,
-						"batch_size": len(batch),
-						"error":      err.Error(),
-					})
-				}
+		// Get volume type or use default
+		volumeType, volumeExists := volumeTypes[volumeID]
+		if volumeType == "" {
+			volumeType = "gp2" // Default to gp2 if we can't determine the volume type
+		}
 
-				// Process any volumes we did find
-				if volumeOutput != nil {
-					for _, vol := range volumeOutput.Volumes {
-						volumeTypesCache[aws.StringValue(vol.VolumeId)] = aws.StringValue(vol.VolumeType)
-					}
-				}
-			}
+		// Calculate age of snapshot
+		ageInDays := int(now.Sub(*snapshot.StartTime).Hours() / 24)
+		ageString := utils.FormatTimeDifference(now, snapshot.StartTime)
+
+		details := map[string]interface{}{
+			"snapshot_id":   snapshotID,
+			"description":   aws.StringValue(snapshot.Description),
+			"volume_id":     volumeID,
+			"volume_size":   aws.Int64Value(snapshot.VolumeSize),
+			"start_time":    snapshot.StartTime.Format(time.RFC3339),
+			"encrypted":     aws.BoolValue(snapshot.Encrypted),
+			"owner_id":      aws.StringValue(snapshot.OwnerId),
+			"progress":      aws.StringValue(snapshot.Progress),
+			"state":         aws.StringValue(snapshot.State),
+			"state_message": aws.StringValue(snapshot.StateMessage),
+			"tags":          tags,
+			"volume_type":   volumeType,
+			"account_id":    opts.AccountID,
+			"region":        opts.Region,
+			"hours_running": now.Sub(*snapshot.StartTime).Hours(),
 		}
 
-		// Process filtered snapshots
-		for _, snapshot := range snapshotsToProcess {
-			// Convert AWS tags to map
-			tags := make(map[string]string)
-			for _, tag := range snapshot.Tags {
-				tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
-			}
+		reasons := []string{}
+		var reasonCodes []string
+
+		// Check for snapshots exposed outside the scanned accounts first, as these are
+		// a security issue as well as a cost issue
+		public, unknownAccounts, err := s.snapshotSharing(svc, snapshotID, knownAccounts)
+		if err != nil {
+			logging.Debug("Failed to check snapshot sharing", map[string]interface{}{
+				"account_id":  opts.AccountID,
+				"region":      opts.Region,
+				"snapshot_id": snapshotID,
+				"error":       err.Error(),
+			})
+		}
+		if public {
+			reasons = append(reasons, "Snapshot is shared publicly.")
+			reasonCodes = append(reasonCodes, reasonCodeSnapshotPublic)
+		}
+		if len(unknownAccounts) > 0 {
+			reasons = append(reasons, fmt.Sprintf("Snapshot is shared with unknown accounts: %s.", strings.Join(unknownAccounts, ", ")))
+			reasonCodes = append(reasonCodes, reasonCodeSnapshotSharedUnknown)
+			details["shared_with_accounts"] = unknownAccounts
+		}
+		if len(reasonCodes) > 0 {
+			details["reason_codes"] = reasonCodes
+		}
+		if imageIDs := imageReferences[snapshotID]; len(imageIDs) > 0 {
+			details["referenced_by_amis"] = imageIDs
+		}
 
-			// Get resource name from tags or use description/snapshot ID
-			resourceName := aws.StringValue(snapshot.Description)
-			if resourceName == "" {
-				resourceName = aws.StringValue(snapshot.SnapshotId)
-			}
+		// Check for old snapshots
+		if ageInDays > opts.DaysUnused {
+			reasons = append(reasons, fmt.Sprintf("Snapshot is %s old.", ageString))
+		}
 
-			// Get volume type from cache or use default
-			volumeType := "gp2" // Default to gp2 if we can't determine the volume type
-			if volID := aws.StringValue(snapshot.VolumeId); volID != "" {
-				if cachedType, exists := volumeTypesCache[volID]; exists {
-					volumeType = cachedType
-				}
-			}
+		// Check for snapshots of deleted volumes
+		if !volumeExists {
+			reasons = append(reasons, fmt.Sprintf("Source volume was deleted. Snapshot has not been used in %d days.", opts.DaysUnused))
+		}
 
-			// Calculate age of snapshot
-			age := time.Since(*snapshot.StartTime)
-			ageInDays := int(age.Hours() / 24)
-			ageString := utils.FormatTimeDifference(time.Now(), snapshot.StartTime)
-
-			details := map[string]interface{}{
-				"snapshot_id":   aws.StringValue(snapshot.SnapshotId),
-				"description":   aws.StringValue(snapshot.Description),
-				"volume_id":     aws.StringValue(snapshot.VolumeId),
-				"volume_size":   aws.Int64Value(snapshot.VolumeSize),
-				"start_time":    snapshot.StartTime.Format(time.RFC3339),
-				"encrypted":     aws.BoolValue(snapshot.Encrypted),
-				"owner_id":      aws.StringValue(snapshot.OwnerId),
-				"progress":      aws.StringValue(snapshot.Progress),
-				"state":         aws.StringValue(snapshot.State),
-				"state_message": aws.StringValue(snapshot.StateMessage),
-				"tags":          tags,
-				"volume_type":   volumeType,
-				"account_id":    opts.AccountID,
-				"region":        opts.Region,
-				"hours_running": time.Since(*snapshot.StartTime).Hours(),
-			}
+		// Check for snapshots superseded by newer snapshots of the same volume
+		if newer := newerSnapshots[snapshotID]; newer >= supersededSnapshotCount {
+			reasons = append(reasons, fmt.Sprintf("Multiple snapshots exist for volume %s: %d newer snapshots supersede this one.", volumeID, newer))
+			details["newer_snapshots"] = newer
+		}
 
+		if len(reasons) > 0 {
 			// Log that we found a result
 			logging.Debug("Found unused EBS snapshot", map[string]interface{}{
 				"account_id":    opts.AccountID,
 				"region":        opts.Region,
 				"resource_name": resourceName,
-				"resource_id":   aws.StringValue(snapshot.SnapshotId),
+				"resource_id":   snapshotID,
 			})
 
-			reasons := []string{}
-			// Check for old snapshots
-			if ageInDays > opts.DaysUnused {
-				reasons = append(reasons, fmt.Sprintf("Snapshot is %s old.", ageString))
-			}
-
-			// Check for snapshots of deleted volumes
-			if _, ok := volumeTypesCache[aws.StringValue(snapshot.VolumeId)]; !ok {
-				reasons = append(reasons, fmt.Sprintf("Source volume was deleted. Snapshot has not been used in %d days.", opts.DaysUnused))
-			}
-
-			// Check for multiple snapshots of the same volume
-			if aws.StringValue(snapshot.VolumeId) != "" {
-				volumeSnapshots[aws.StringValue(snapshot.VolumeId)] = append(volumeSnapshots[aws.StringValue(snapshot.VolumeId)], aws.StringValue(snapshot.SnapshotId))
-				if len(volumeSnapshots[aws.StringValue(snapshot.VolumeId)]) > 1 {
-					reasons = append(reasons, fmt.Sprintf("Multiple snapshots exist for volume %s. This snapshot has not been used in %d days.", aws.StringValue(snapshot.VolumeId), opts.DaysUnused))
-				}
+			// Calculate costs based on snapshot size and age
+			var cost map[string]interface{}
+			if costs := s.calculateSnapshotCosts(opts, snapshotID, aws.Int64Value(snapshot.VolumeSize), volumeType, *snapshot.StartTime, now); costs != nil {
+				cost = map[string]interface{}{"total": costs}
+			} else {
+				cost = awslib.NoCost(awslib.NoCostNotEstimated)
 			}
 
-			if len(reasons) > 0 {
-				// Calculate costs based on snapshot size and age
-				costCalculations++
-				hoursRunning := time.Since(*snapshot.StartTime).Hours()
-				cost := map[string]interface{}{
-					"total": s.calculateSnapshotCosts(aws.Int64Value(snapshot.VolumeSize), hoursRunning),
-				}
-
-				results = append(results, awslib.ScanResult{
-					ResourceType: s.Label(),
-					ResourceName: resourceName,
-					ResourceID:   aws.StringValue(snapshot.SnapshotId),
-					Reason:       reasons[0],
-					Tags:         tags,
-					Details:      details,
-					Cost:         cost,
-				})
-			}
+			results = append(results, awslib.ScanResult{
+				ResourceType: s.Label(),
+				ResourceName: resourceName,
+				ResourceID:   snapshotID,
+				Reason:       strings.Join(reasons, "\n"),
+				Tags:         tags,
+				Details:      details,
+				Cost:         cost,
+			})
 		}
-
-		// Always return true to continue pagination
-		return true
-	})
-
-	if err != nil {
-		logging.Error("Failed to describe snapshots", err, nil)
-		return nil, fmt.Errorf("failed to describe snapshots: %w", err)
 	}
 
 	// Log performance metrics
@@ -266,9 +377,9 @@ func (s *EBSSnapshotScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults,
 		"account_id":          opts.AccountID,
 		"region":              opts.Region,
 		"duration_ms":         time.Since(scanStart).Milliseconds(),
-		"snapshots_processed": snapshotsProcessed,
-		"volume_lookups":      volumeLookups,
-		"cost_calculations":   costCalculations,
+		"snapshots_processed": len(snapshots),
+		"volumes_found":       len(volumeTypes),
+		"findings":            len(results),
 	})
 
 	return results, nil
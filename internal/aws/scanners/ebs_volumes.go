@@ -31,6 +31,28 @@ func (s *EBSVolumeScanner) Label() string {
 	return "EBS Volumes"
 }
 
+// RemediationTemplate implements Remediator interface. Volumes are snapshotted before
+// deletion, so the data can be restored.
+func (s *EBSVolumeScanner) RemediationTemplate() string {
+	return "aws ec2 create-snapshot --volume-id {{.ResourceID}} --description 'Before deleting unused volume' --region {{.Region}} && aws ec2 delete-volume --volume-id {{.ResourceID}} --region {{.Region}}"
+}
+
+// HasResources implements ResourceProber interface
+func (s *EBSVolumeScanner) HasResources(opts awslib.ScanOptions) (bool, error) {
+	sess, err := awslib.GetSessionInRegion(opts.Session, opts.Region)
+	if err != nil {
+		return false, fmt.Errorf("failed to create regional session: %w", err)
+	}
+
+	output, err := ec2.New(sess).DescribeVolumes(&ec2.DescribeVolumesInput{
+		MaxResults: aws.Int64(5),
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to describe volumes: %w", err)
+	}
+	return len(output.Volumes) > 0, nil
+}
+
 // Scan implements Scanner interface
 func (s *EBSVolumeScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, error) {
 	// Get regional session
@@ -245,7 +267,8 @@ func (s *EBSVolumeScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, er
 			endTime := time.Now().UTC().Truncate(time.Minute)
 			daysUnused := utils.Max(1, opts.DaysUnused)
 			metricStartTime := endTime.Add(-time.Duration(daysUnused) * 24 * time.Hour)
-			metrics, err := s.getVolumeMetrics(clients.CloudWatch, volumeID, metricStartTime, endTime)
+			recorder := utils.NewMetricRecorder(opts.IncludeMetricSamples)
+			metrics, err := s.getVolumeMetrics(clients.CloudWatch, volumeID, metricStartTime, endTime, recorder)
 			if err != nil {
 				logging.Error("Failed to get volume metrics", err, map[string]interface{}{
 					"volume_id": volumeID,
@@ -253,7 +276,11 @@ func (s *EBSVolumeScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, er
 					"endTime":   endTime.Format(time.RFC3339),
 				})
 				// Continue processing even if metrics collection fails
+				if utils.MetricsDegraded(err) {
+					utils.MarkMetricsDegraded(details, err)
+				}
 			}
+			recorder.AddTo(details)
 
 			// Check if volume is truly unused based on all criteria
 			isUnused := true
@@ -304,6 +331,12 @@ func (s *EBSVolumeScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, er
 				resourceName = name
 			}
 
+			// Volumes in Local Zones and Wavelength Zones are priced by their zone
+			zone, inEdgeZone := zoneOf(opts, svc, aws.StringValue(volume.AvailabilityZone))
+			if inEdgeZone {
+				details["zone_type"] = zone.zoneType
+			}
+
 			// Calculate costs only for unused volumes
 			var costs *awslib.CostBreakdown
 			var costDetails map[string]interface{}
@@ -320,6 +353,10 @@ func (s *EBSVolumeScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, er
 					Region:       opts.Region,
 					CreationTime: *volume.CreateTime,
 					VolumeType:   volumeType,
+					IOPS:         aws.Int64Value(volume.Iops),
+					Throughput:   aws.Int64Value(volume.Throughput),
+
+					PricingRegionCode: zone.pricingRegionCode,
 				})
 				if err != nil {
 					logging.Error("Failed to calculate costs", err, map[string]interface{}{
@@ -420,7 +457,7 @@ func (s *EBSVolumeScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, er
 	return results, nil
 }
 
-func (s *EBSVolumeScanner) getVolumeMetrics(cwClient *cloudwatch.CloudWatch, volumeID string, startTime time.Time, endTime time.Time) (map[string]float64, error) {
+func (s *EBSVolumeScanner) getVolumeMetrics(cwClient *cloudwatch.CloudWatch, volumeID string, startTime time.Time, endTime time.Time, recorder *utils.MetricRecorder) (map[string]float64, error) {
 	metrics := make(map[string]float64)
 	period := int64(86400) // 1 day
 	metricConfigs := []utils.MetricConfig{
@@ -433,6 +470,7 @@ func (s *EBSVolumeScanner) getVolumeMetrics(cwClient *cloudwatch.CloudWatch, vol
 			StartTime:     startTime,
 			EndTime:       endTime,
 			Period:        period,
+			Recorder:      recorder,
 		},
 		{
 			Namespace:     "AWS/EBS",
@@ -443,6 +481,7 @@ func (s *EBSVolumeScanner) getVolumeMetrics(cwClient *cloudwatch.CloudWatch, vol
 			StartTime:     startTime,
 			EndTime:       endTime,
 			Period:        period,
+			Recorder:      recorder,
 		},
 		{
 			Namespace:     "AWS/EBS",
@@ -453,6 +492,7 @@ func (s *EBSVolumeScanner) getVolumeMetrics(cwClient *cloudwatch.CloudWatch, vol
 			StartTime:     startTime,
 			EndTime:       endTime,
 			Period:        period,
+			Recorder:      recorder,
 		},
 	}
 
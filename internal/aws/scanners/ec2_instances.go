@@ -13,6 +13,7 @@ import (
 	"cloudsift/internal/worker"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
 	"github.com/aws/aws-sdk-go/service/cloudwatch"
 	"github.com/aws/aws-sdk-go/service/ec2"
 
@@ -36,8 +37,37 @@ func (s *EC2InstanceScanner) Label() string {
 	return "EC2 Instances"
 }
 
+// RemediationTemplate implements Remediator interface. Idle instances are stopped rather than
+// terminated, keeping their volumes.
+func (s *EC2InstanceScanner) RemediationTemplate() string {
+	return "aws ec2 stop-instances --instance-ids {{.ResourceID}} --region {{.Region}}"
+}
+
+// HasResources implements ResourceProber interface
+func (s *EC2InstanceScanner) HasResources(opts awslib.ScanOptions) (bool, error) {
+	sess, err := awslib.GetSessionInRegion(opts.Session, opts.Region)
+	if err != nil {
+		return false, fmt.Errorf("failed to create regional session: %w", err)
+	}
+
+	output, err := ec2.New(sess).DescribeInstances(&ec2.DescribeInstancesInput{
+		MaxResults: aws.Int64(5),
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to describe instances: %w", err)
+	}
+	return len(output.Reservations) > 0, nil
+}
+
 // fetchMetric gets CloudWatch metrics for a given resource
-func (s *EC2InstanceScanner) fetchMetric(cwClient *cloudwatch.CloudWatch, namespace, resourceID, dimensionName, metricName, stat string, startTime, endTime time.Time) ([]float64, error) {
+func (s *EC2InstanceScanner) fetchMetric(cwClient *cloudwatch.CloudWatch, namespace, resourceID, dimensionName, metricName, stat string, startTime, endTime time.Time, recorder *utils.MetricRecorder) ([]float64, error) {
+	_, values, err := s.fetchMetricPoints(cwClient, namespace, resourceID, dimensionName, metricName, stat, startTime, endTime, recorder)
+	return values, err
+}
+
+// fetchMetricPoints gets CloudWatch metrics for a given resource along with their timestamps.
+// The datapoints are recorded when a recorder is given.
+func (s *EC2InstanceScanner) fetchMetricPoints(cwClient *cloudwatch.CloudWatch, namespace, resourceID, dimensionName, metricName, stat string, startTime, endTime time.Time, recorder *utils.MetricRecorder) ([]time.Time, []float64, error) {
 	// Ensure start time is before end time and they're not equal
 	if startTime.Equal(endTime) {
 		startTime = startTime.Add(-1 * time.Hour)
@@ -81,25 +111,33 @@ func (s *EC2InstanceScanner) fetchMetric(cwClient *cloudwatch.CloudWatch, namesp
 		EndTime:   aws.Time(config.EndTime),
 	}
 
-	result, err := cwClient.GetMetricData(input)
+	ctx, cancel := utils.MetricsContext()
+	defer cancel()
+
+	result, err := cwClient.GetMetricDataWithContext(ctx, input, utils.MetricsRetries)
 	if err != nil {
-		return nil, err
+		return nil, nil, utils.MetricsError(ctx, err)
 	}
 
 	if len(result.MetricDataResults) == 0 || len(result.MetricDataResults[0].Values) == 0 {
-		return []float64{}, nil
+		return []time.Time{}, []float64{}, nil
 	}
 
+	timestamps := make([]time.Time, len(result.MetricDataResults[0].Timestamps))
+	for i, t := range result.MetricDataResults[0].Timestamps {
+		timestamps[i] = aws.TimeValue(t)
+	}
 	values := make([]float64, len(result.MetricDataResults[0].Values))
 	for i, v := range result.MetricDataResults[0].Values {
 		values[i] = aws.Float64Value(v)
 	}
+	recorder.Record(config.Namespace, config.MetricName, config.Statistic, timestamps, values)
 
-	return values, nil
+	return timestamps, values, nil
 }
 
 // analyzeInstanceUsage checks if an instance is underutilized
-func (s *EC2InstanceScanner) analyzeInstanceUsage(cwClient *cloudwatch.CloudWatch, instance *ec2.Instance, startTime, endTime time.Time, daysUnused int) ([]string, error) {
+func (s *EC2InstanceScanner) analyzeInstanceUsage(cwClient *cloudwatch.CloudWatch, instance *ec2.Instance, startTime, endTime time.Time, daysUnused int, recorder *utils.MetricRecorder) ([]string, error) {
 	instanceID := aws.StringValue(instance.InstanceId)
 	var reasons []string
 
@@ -109,7 +147,7 @@ func (s *EC2InstanceScanner) analyzeInstanceUsage(cwClient *cloudwatch.CloudWatc
 		"start_time":  startTime,
 		"end_time":    endTime,
 	})
-	cpuUsage, err := s.fetchMetric(cwClient, "AWS/EC2", instanceID, "InstanceId", "CPUUtilization", "Average", startTime, endTime)
+	cpuUsage, err := s.fetchMetric(cwClient, "AWS/EC2", instanceID, "InstanceId", "CPUUtilization", "Average", startTime, endTime, recorder)
 	if err != nil {
 		logging.Error("Failed to fetch CPU metrics", err, map[string]interface{}{
 			"instance_id": instanceID,
@@ -125,7 +163,7 @@ func (s *EC2InstanceScanner) analyzeInstanceUsage(cwClient *cloudwatch.CloudWatc
 		"start_time":  startTime,
 		"end_time":    endTime,
 	})
-	networkIn, err := s.fetchMetric(cwClient, "AWS/EC2", instanceID, "InstanceId", "NetworkPacketsIn", "Sum", startTime, endTime)
+	networkIn, err := s.fetchMetric(cwClient, "AWS/EC2", instanceID, "InstanceId", "NetworkPacketsIn", "Sum", startTime, endTime, recorder)
 	if err != nil {
 		logging.Error("Failed to fetch NetworkIn metrics", err, map[string]interface{}{
 			"instance_id": instanceID,
@@ -135,7 +173,7 @@ func (s *EC2InstanceScanner) analyzeInstanceUsage(cwClient *cloudwatch.CloudWatc
 		return nil, fmt.Errorf("failed to fetch NetworkIn metrics: %w", err)
 	}
 
-	networkOut, err := s.fetchMetric(cwClient, "AWS/EC2", instanceID, "InstanceId", "NetworkPacketsOut", "Sum", startTime, endTime)
+	networkOut, err := s.fetchMetric(cwClient, "AWS/EC2", instanceID, "InstanceId", "NetworkPacketsOut", "Sum", startTime, endTime, recorder)
 	if err != nil {
 		logging.Error("Failed to fetch NetworkOut metrics", err, map[string]interface{}{
 			"instance_id": instanceID,
@@ -200,6 +238,153 @@ func (s *EC2InstanceScanner) analyzeInstanceUsage(cwClient *cloudwatch.CloudWatc
 	return reasons, nil
 }
 
+// businessHoursUsage summarizes CPU utilization inside and outside the business hours window
+type businessHoursUsage struct {
+	InHoursCPUAvg   float64
+	OffHoursCPUAvg  float64
+	InHoursSamples  int
+	OffHoursSamples int
+}
+
+// scheduleCandidate returns true if the instance is busy during business hours but idle
+// outside them, making it a candidate for a scheduled stop/start rather than removal
+func (u *businessHoursUsage) scheduleCandidate() bool {
+	return u.InHoursSamples > 0 && u.OffHoursSamples > 0 && u.InHoursCPUAvg >= 5 && u.OffHoursCPUAvg < 5
+}
+
+// analyzeBusinessHours compares an instance's CPU utilization inside and outside the business hours window
+func (s *EC2InstanceScanner) analyzeBusinessHours(cwClient *cloudwatch.CloudWatch, instance *ec2.Instance, startTime, endTime time.Time, bh *awslib.BusinessHours, recorder *utils.MetricRecorder) (*businessHoursUsage, error) {
+	instanceID := aws.StringValue(instance.InstanceId)
+	timestamps, values, err := s.fetchMetricPoints(cwClient, "AWS/EC2", instanceID, "InstanceId", "CPUUtilization", "Average", startTime, endTime, recorder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch CPU metrics: %w", err)
+	}
+
+	usage := &businessHoursUsage{}
+	var inSum, offSum float64
+	for i, value := range values {
+		if i >= len(timestamps) {
+			break
+		}
+		if bh.Contains(timestamps[i]) {
+			inSum += value
+			usage.InHoursSamples++
+		} else {
+			offSum += value
+			usage.OffHoursSamples++
+		}
+	}
+	if usage.InHoursSamples > 0 {
+		usage.InHoursCPUAvg = inSum / float64(usage.InHoursSamples)
+	}
+	if usage.OffHoursSamples > 0 {
+		usage.OffHoursCPUAvg = offSum / float64(usage.OffHoursSamples)
+	}
+
+	logging.Debug("Business hours utilization analysis", map[string]interface{}{
+		"instance_id":       instanceID,
+		"business_hours":    bh.String(),
+		"in_hours_cpu_avg":  usage.InHoursCPUAvg,
+		"off_hours_cpu_avg": usage.OffHoursCPUAvg,
+		"in_hours_samples":  usage.InHoursSamples,
+		"off_hours_samples": usage.OffHoursSamples,
+	})
+
+	return usage, nil
+}
+
+// excludedReason returns why an instance is excluded from idle detection, or an empty
+// string if it should be analyzed. Auto Scaling group members are identified by the
+// aws:autoscaling:groupName tag and Spot instances by their instance lifecycle. Instances of
+// EMR clusters are always excluded: EMR manages them, and idle clusters are reported whole
+// by the EMR cluster scanner.
+func (s *EC2InstanceScanner) excludedReason(instance *ec2.Instance, opts awslib.ScanOptions) string {
+	for _, tag := range instance.Tags {
+		if aws.StringValue(tag.Key) == "aws:elasticmapreduce:job-flow-id" {
+			return fmt.Sprintf("member of EMR cluster %s", aws.StringValue(tag.Value))
+		}
+	}
+	if opts.ExcludeSpotInstances && aws.StringValue(instance.InstanceLifecycle) == ec2.InstanceLifecycleTypeSpot {
+		return "spot instance"
+	}
+	if opts.ExcludeASGInstances {
+		for _, tag := range instance.Tags {
+			if aws.StringValue(tag.Key) == "aws:autoscaling:groupName" {
+				return fmt.Sprintf("member of Auto Scaling group %s", aws.StringValue(tag.Value))
+			}
+		}
+	}
+	return ""
+}
+
+// reportedByScanner returns the argument name of the more specific scanner of the scan run
+// that reports an instance with its extra charges, or an empty string if no other scanner
+// reports it. Accelerators of instance types are nil if they are unknown.
+func (s *EC2InstanceScanner) reportedByScanner(instance *ec2.Instance, opts awslib.ScanOptions, accelerators map[string][]string) string {
+	if opts.RunsScanner("marketplace-instances") && reportsMarketplaceInstance(instance) {
+		return "marketplace-instances"
+	}
+	if opts.RunsScanner("accelerated-instances") && accelerators != nil && reportsAcceleratedInstance(instance, accelerators) {
+		return "accelerated-instances"
+	}
+	return ""
+}
+
+// warmPoolInstance is an instance kept in the warm pool of an Auto Scaling group, with the
+// name of the group and its warm pool lifecycle state
+type warmPoolInstance struct {
+	Group          string
+	LifecycleState string // Warmed:Stopped, Warmed:Running or Warmed:Hibernated
+}
+
+// warmPoolInstances returns the instances of the region in Auto Scaling warm pools by
+// instance ID. Warm pool instances are stopped, running or hibernated by the group until
+// it scales out, so they are not idle in the sense of the other instances.
+func (s *EC2InstanceScanner) warmPoolInstances(client *autoscaling.AutoScaling) (map[string]warmPoolInstance, error) {
+	instances := make(map[string]warmPoolInstance)
+	err := client.DescribeAutoScalingInstancesPages(&autoscaling.DescribeAutoScalingInstancesInput{}, func(page *autoscaling.DescribeAutoScalingInstancesOutput, lastPage bool) bool {
+		for _, instance := range page.AutoScalingInstances {
+			state := aws.StringValue(instance.LifecycleState)
+			if strings.HasPrefix(state, "Warmed:") {
+				instances[aws.StringValue(instance.InstanceId)] = warmPoolInstance{
+					Group:          aws.StringValue(instance.AutoScalingGroupName),
+					LifecycleState: state,
+				}
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe Auto Scaling instances: %w", err)
+	}
+	return instances, nil
+}
+
+// isHibernated returns whether a stopped instance was hibernated rather than stopped. Its
+// memory is kept on its root volume so it resumes where it left off.
+func isHibernated(instance *ec2.Instance) bool {
+	if aws.StringValue(instance.State.Name) != ec2.InstanceStateNameStopped {
+		return false
+	}
+	if instance.HibernationOptions == nil || !aws.BoolValue(instance.HibernationOptions.Configured) {
+		return false
+	}
+	return instance.StateReason != nil && strings.Contains(aws.StringValue(instance.StateReason.Code), "Hibernate")
+}
+
+// instanceCreatedAt returns when an instance was created, from the attach time of its root EBS
+// volume, which unlike the launch time is kept when the instance is stopped and started.
+// Instance store backed instances have no such time.
+func instanceCreatedAt(instance *ec2.Instance) (time.Time, bool) {
+	rootDevice := aws.StringValue(instance.RootDeviceName)
+	for _, mapping := range instance.BlockDeviceMappings {
+		if aws.StringValue(mapping.DeviceName) == rootDevice && mapping.Ebs != nil && mapping.Ebs.AttachTime != nil {
+			return *mapping.Ebs.AttachTime, true
+		}
+	}
+	return time.Time{}, false
+}
+
 // getEBSVolumes gets the EBS volumes attached to an instance
 func (s *EC2InstanceScanner) getEBSVolumes(ec2Client *ec2.EC2, instance *ec2.Instance, hoursRunning float64) ([]map[string]interface{}, error) {
 	var ebsDetails []map[string]interface{}
@@ -258,6 +443,19 @@ func (s *EC2InstanceScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults,
 	// Create service clients
 	clients := utils.CreateServiceClients(sess)
 	ec2Client := ec2.New(sess) // Keep direct EC2 client for backward compatibility
+	vpcNameByID := vpcNames(opts.ScanContext(), ec2Client, opts.AccountID, opts.Region)
+	subnetNameByID := subnetNames(opts.ScanContext(), ec2Client, opts.AccountID, opts.Region)
+
+	// Warm pool instances are reported with their own reason, or skipped
+	warmPool, err := s.warmPoolInstances(autoscaling.New(sess))
+	if err != nil {
+		// Warm pool instances are then reported like other instances
+		logging.Warn("Failed to get Auto Scaling warm pool instances", map[string]interface{}{
+			"account_id": opts.AccountID,
+			"region":     opts.Region,
+			"error":      err.Error(),
+		})
+	}
 
 	// Get instances
 	var results awslib.ScanResults
@@ -275,6 +473,10 @@ func (s *EC2InstanceScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults,
 	// Create a channel to collect tasks
 	var tasks []worker.Task
 
+	// Accelerators of the instance types, looked up once all instances are listed
+	var accelerators map[string][]string
+	instanceTypes := make(map[string]bool)
+
 	err = ec2Client.DescribeInstancesPages(input, func(page *ec2.DescribeInstancesOutput, lastPage bool) bool {
 		// Log page processing
 		logging.Debug("Processing instance page", map[string]interface{}{
@@ -288,6 +490,7 @@ func (s *EC2InstanceScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults,
 			for _, instance := range reservation.Instances {
 				// Create a copy of instance for the closure
 				instanceCopy := instance
+				instanceTypes[aws.StringValue(instance.InstanceType)] = true
 
 				task := func(ctx context.Context) error {
 					totalInstances++
@@ -300,6 +503,36 @@ func (s *EC2InstanceScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults,
 						return nil
 					}
 
+					// Skip elastic workloads that scale by design when requested
+					if reason := s.excludedReason(instanceCopy, opts); reason != "" {
+						logging.Debug("Skipping excluded instance", map[string]interface{}{
+							"instance_id": aws.StringValue(instanceCopy.InstanceId),
+							"reason":      reason,
+						})
+						return nil
+					}
+
+					// Skip instances a more specific scanner reports, so they are not counted twice
+					if scanner := s.reportedByScanner(instanceCopy, opts, accelerators); scanner != "" {
+						logging.Debug("Skipping instance reported by another scanner", map[string]interface{}{
+							"instance_id": aws.StringValue(instanceCopy.InstanceId),
+							"scanner":     scanner,
+						})
+						return nil
+					}
+
+					// Skip instances stopped on purpose to resume quickly when requested
+					warmInstance, inWarmPool := warmPool[aws.StringValue(instanceCopy.InstanceId)]
+					hibernated := isHibernated(instanceCopy)
+					if opts.ExcludeWarmInstances && (inWarmPool || hibernated) {
+						logging.Debug("Skipping warm pool or hibernated instance", map[string]interface{}{
+							"instance_id":  aws.StringValue(instanceCopy.InstanceId),
+							"in_warm_pool": inWarmPool,
+							"hibernated":   hibernated,
+						})
+						return nil
+					}
+
 					// Get instance name from tags
 					name := aws.StringValue(instanceCopy.InstanceId)
 					for _, tag := range instanceCopy.Tags {
@@ -326,7 +559,13 @@ func (s *EC2InstanceScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults,
 
 					// Check if instance is unused based on state
 					var reasons []string
-					if aws.StringValue(instanceCopy.State.Name) == "stopped" {
+					var scheduleUsage *businessHoursUsage
+					var metricsErr error
+					recorder := utils.NewMetricRecorder(opts.IncludeMetricSamples)
+					if inWarmPool {
+						// The group keeps the instance ready to scale out, whatever its metrics
+						reasons = append(reasons, fmt.Sprintf("Instance is kept in the warm pool of Auto Scaling group %s (%s).", warmInstance.Group, warmInstance.LifecycleState))
+					} else if aws.StringValue(instanceCopy.State.Name) == "stopped" {
 						logging.Info("Found stopped instance", map[string]interface{}{
 							"instance_id": aws.StringValue(instanceCopy.InstanceId),
 							"name":        name,
@@ -343,7 +582,11 @@ func (s *EC2InstanceScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults,
 									stoppedDays := int(stoppedDuration.Hours() / 24)
 									if stoppedDays >= opts.DaysUnused {
 										stoppedAgeStr := utils.FormatTimeDifference(time.Now(), &stopTime)
-										reasons = append(reasons, fmt.Sprintf("Instance has been stopped for %s", stoppedAgeStr))
+										if hibernated {
+											reasons = append(reasons, fmt.Sprintf("Instance has been hibernated for %s", stoppedAgeStr))
+										} else {
+											reasons = append(reasons, fmt.Sprintf("Instance has been stopped for %s", stoppedAgeStr))
+										}
 									}
 								}
 							}
@@ -355,14 +598,32 @@ func (s *EC2InstanceScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults,
 						instanceAge := time.Since(*instanceCopy.LaunchTime)
 						if instanceAge.Hours()/24 >= float64(opts.DaysUnused) {
 							// Analyze running instances using launch time
-							usageReasons, err := s.analyzeInstanceUsage(clients.CloudWatch, instanceCopy, metricStartTime, endTime, opts.DaysUnused)
+							usageReasons, err := s.analyzeInstanceUsage(clients.CloudWatch, instanceCopy, metricStartTime, endTime, opts.DaysUnused, recorder)
 							if err != nil {
 								logging.Error("Failed to analyze instance usage", err, map[string]interface{}{
 									"instance_id": aws.StringValue(instanceCopy.InstanceId),
 								})
+								if utils.MetricsDegraded(err) {
+									metricsErr = err
+									reasons = append(reasons, utils.MetricsUnavailableReason)
+								}
 							} else {
 								reasons = append(reasons, usageReasons...)
 							}
+
+							// Instances that are not idle overall may still be idle outside business hours
+							if len(reasons) == 0 && opts.BusinessHours != nil {
+								usage, err := s.analyzeBusinessHours(clients.CloudWatch, instanceCopy, metricStartTime, endTime, opts.BusinessHours, recorder)
+								if err != nil {
+									logging.Error("Failed to analyze business hours usage", err, map[string]interface{}{
+										"instance_id": aws.StringValue(instanceCopy.InstanceId),
+									})
+								} else if usage.scheduleCandidate() {
+									scheduleUsage = usage
+									reasons = append(reasons, fmt.Sprintf("Candidate for scheduled stop/start: CPU averages %.2f%% during business hours (%s) but only %.2f%% outside them in the last %d days.",
+										usage.InHoursCPUAvg, opts.BusinessHours, usage.OffHoursCPUAvg, opts.DaysUnused))
+								}
+							}
 						} else {
 							logging.Debug("Skipping instance usage analysis - too new", map[string]interface{}{
 								"instance_id":  aws.StringValue(instanceCopy.InstanceId),
@@ -396,7 +657,9 @@ func (s *EC2InstanceScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults,
 							"state":               aws.StringValue(instanceCopy.State.Name),
 							"state_code":          aws.Int64Value(instanceCopy.State.Code),
 							"subnet_id":           aws.StringValue(instanceCopy.SubnetId),
+							"subnet_name":         subnetNameByID[aws.StringValue(instanceCopy.SubnetId)],
 							"vpc_id":              aws.StringValue(instanceCopy.VpcId),
+							"vpc_name":            vpcNameByID[aws.StringValue(instanceCopy.VpcId)],
 							"hours_running":       time.Since(*instanceCopy.LaunchTime).Hours(),
 							"ebs_optimized":       aws.BoolValue(instanceCopy.EbsOptimized),
 							"ena_support":         aws.BoolValue(instanceCopy.EnaSupport),
@@ -404,16 +667,57 @@ func (s *EC2InstanceScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults,
 							"virtualization_type": aws.StringValue(instanceCopy.VirtualizationType),
 							"tags":                tags,
 						}
+						if createdAt, ok := instanceCreatedAt(instanceCopy); ok {
+							details["created_at"] = createdAt.Format(time.RFC3339)
+						}
+
+						// Instances and their volumes in Local Zones and Wavelength Zones are
+						// priced by their zone
+						var availabilityZone string
+						if instanceCopy.Placement != nil {
+							availabilityZone = aws.StringValue(instanceCopy.Placement.AvailabilityZone)
+						}
+						zone, inEdgeZone := zoneOf(opts, ec2Client, availabilityZone)
+						if inEdgeZone {
+							details["availability_zone"] = availabilityZone
+							details["zone_type"] = zone.zoneType
+						}
 
 						// Add state reason if present
 						if instanceCopy.StateReason != nil {
 							details["state_reason"] = aws.StringValue(instanceCopy.StateReason.Message)
 						}
 
+						// Add warm pool and hibernation state
+						if inWarmPool {
+							details["warm_pool_group"] = warmInstance.Group
+							details["lifecycle_state"] = warmInstance.LifecycleState
+						}
+						if hibernated {
+							details["hibernated"] = true
+						}
+
 						// Add EBS details if available
 						if len(ebsDetails) > 0 {
 							details["ebs_volumes"] = ebsDetails
 						}
+						if metricsErr != nil {
+							utils.MarkMetricsDegraded(details, metricsErr)
+						}
+						recorder.AddTo(details)
+
+						// Distinguish scheduling candidates from unused instances
+						if opts.BusinessHours != nil {
+							details["recommendation"] = "unused"
+							if scheduleUsage != nil {
+								details["recommendation"] = "scheduled_stop_start"
+								details["business_hours"] = map[string]interface{}{
+									"window":            opts.BusinessHours.String(),
+									"in_hours_cpu_avg":  scheduleUsage.InHoursCPUAvg,
+									"off_hours_cpu_avg": scheduleUsage.OffHoursCPUAvg,
+								}
+							}
+						}
 
 						// Calculate costs
 						costEstimator := awslib.DefaultCostEstimator
@@ -435,6 +739,8 @@ func (s *EC2InstanceScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults,
 										Region:       opts.Region,
 										CreationTime: time.Now().Add(-time.Duration(hoursRunning) * time.Hour),
 										VolumeType:   volumeType,
+
+										PricingRegionCode: zone.pricingRegionCode,
 									})
 									if err != nil {
 										logging.Error("Failed to calculate EBS volume costs", err, map[string]interface{}{
@@ -479,12 +785,23 @@ func (s *EC2InstanceScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults,
 									ResourceSize: aws.StringValue(instanceCopy.InstanceType),
 									Region:       opts.Region,
 									CreationTime: *instanceCopy.LaunchTime,
+
+									PricingRegionCode: zone.pricingRegionCode,
 								})
 								if err != nil {
 									logging.Error("Failed to calculate EC2 instance costs", err, map[string]interface{}{
 										"instance_id": aws.StringValue(instanceCopy.InstanceId),
 									})
 								} else if instanceCosts != nil {
+									// Stopping outside business hours saves the compute cost but not EBS storage
+									if scheduleUsage != nil {
+										offHoursRate := instanceCosts.HourlyRate * opts.BusinessHours.OffHoursFraction()
+										details["estimated_off_hours_savings"] = map[string]interface{}{
+											"monthly": roundCost(offHoursRate * 24 * 30),
+											"yearly":  roundCost(offHoursRate * 24 * 365),
+										}
+									}
+
 									lifetime := float64(int(instanceCosts.HourlyRate*hoursRunning*100+0.5)) / 100
 									instanceCosts.Lifetime = &lifetime
 									hours := float64(int(hoursRunning*100+0.5)) / 100
@@ -555,6 +872,22 @@ func (s *EC2InstanceScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults,
 		return nil, fmt.Errorf("failed to describe instances: %w", err)
 	}
 
+	// Accelerated instances are left to their scanner when it runs. If their accelerators
+	// are unknown, they are reported here rather than possibly not at all.
+	if opts.RunsScanner("accelerated-instances") {
+		typeNames := make([]string, 0, len(instanceTypes))
+		for instanceType := range instanceTypes {
+			typeNames = append(typeNames, instanceType)
+		}
+		if accelerators, err = describeAccelerators(ec2Client, typeNames); err != nil {
+			logging.Warn("Failed to get accelerators of instance types", map[string]interface{}{
+				"account_id": opts.AccountID,
+				"region":     opts.Region,
+				"error":      err.Error(),
+			})
+		}
+	}
+
 	// Execute all tasks in parallel
 	pool.ExecuteTasks(tasks)
 
@@ -573,7 +906,6 @@ func (s *EC2InstanceScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults,
 }
 
 // roundCost rounds a cost value to 2 decimal places
-// nolint:unused
 func roundCost(cost float64) float64 {
 	return math.Round(cost*100) / 100
 }
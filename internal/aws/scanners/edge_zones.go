@@ -0,0 +1,81 @@
+package scanners
+
+import (
+	awslib "cloudsift/internal/aws"
+	"cloudsift/internal/logging"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// edgeZonesKey is the scan context key of the opted-in Local Zones and Wavelength Zones of
+// the region
+const edgeZonesKey = "ec2/edge-zones"
+
+// edgeZone is a Local Zone or Wavelength Zone of a region. Its resources are listed by the
+// EC2 API of the parent region, but priced by the zone.
+type edgeZone struct {
+	zoneType          string // local-zone or wavelength-zone
+	pricingRegionCode string // Region code of the zone in the Pricing API, e.g. us-west-2-lax-1
+}
+
+// edgeZones returns the opted-in Local Zones and Wavelength Zones of the region of the EC2
+// client keyed by zone name. They are described once per scan context and shared by the EC2
+// and EBS scanners.
+func edgeZones(scanContext *awslib.ScanContext, ec2Client *ec2.EC2) (map[string]edgeZone, error) {
+	zones, err := scanContext.LoadOrCompute(edgeZonesKey, func() (interface{}, error) {
+		output, err := ec2Client.DescribeAvailabilityZones(&ec2.DescribeAvailabilityZonesInput{
+			Filters: []*ec2.Filter{
+				{
+					Name:   aws.String("zone-type"),
+					Values: aws.StringSlice([]string{"local-zone", "wavelength-zone"}),
+				},
+				{
+					Name:   aws.String("opt-in-status"),
+					Values: aws.StringSlice([]string{ec2.AvailabilityZoneOptInStatusOptedIn}),
+				},
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		zones := make(map[string]edgeZone)
+		for _, zone := range output.AvailabilityZones {
+			// Local Zones are priced by their zone group, e.g. us-west-2-lax-1 for
+			// us-west-2-lax-1a, and Wavelength Zones by the zone itself
+			regionCode := aws.StringValue(zone.GroupName)
+			if aws.StringValue(zone.ZoneType) == "wavelength-zone" {
+				regionCode = aws.StringValue(zone.ZoneName)
+			}
+			zones[aws.StringValue(zone.ZoneName)] = edgeZone{
+				zoneType:          aws.StringValue(zone.ZoneType),
+				pricingRegionCode: regionCode,
+			}
+		}
+		return zones, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return zones.(map[string]edgeZone), nil
+}
+
+// zoneOf returns the Local Zone or Wavelength Zone an availability zone is, if edge zones are
+// priced in the scan. Zones that cannot be described are priced by the region.
+func zoneOf(opts awslib.ScanOptions, ec2Client *ec2.EC2, availabilityZone string) (edgeZone, bool) {
+	if !opts.EdgeZones || availabilityZone == "" {
+		return edgeZone{}, false
+	}
+	zones, err := edgeZones(opts.ScanContext(), ec2Client)
+	if err != nil {
+		logging.Warn("Failed to describe Local Zones and Wavelength Zones", map[string]interface{}{
+			"account_id": opts.AccountID,
+			"region":     opts.Region,
+			"error":      err.Error(),
+		})
+		return edgeZone{}, false
+	}
+	zone, ok := zones[availabilityZone]
+	return zone, ok
+}
@@ -11,7 +11,8 @@ import (
 	"github.com/aws/aws-sdk-go/service/ec2"
 )
 
-// ElasticIPScanner scans for unused Elastic IPs
+// ElasticIPScanner scans for Elastic IPs that are not associated with any resource or whose
+// instance is stopped, as both are billed while not serving traffic
 type ElasticIPScanner struct{}
 
 func init() {
@@ -28,6 +29,25 @@ func (s *ElasticIPScanner) Label() string {
 	return "Elastic IPs"
 }
 
+// RemediationTemplate implements Remediator interface
+func (s *ElasticIPScanner) RemediationTemplate() string {
+	return `{{if index .Details "association_id"}}aws ec2 disassociate-address --association-id {{index .Details "association_id"}} --region {{.Region}} && {{end}}aws ec2 release-address --allocation-id {{.ResourceID}} --region {{.Region}}`
+}
+
+// HasResources implements ResourceProber interface
+func (s *ElasticIPScanner) HasResources(opts awslib.ScanOptions) (bool, error) {
+	sess, err := awslib.GetSessionInRegion(opts.Session, opts.Region)
+	if err != nil {
+		return false, fmt.Errorf("failed to create regional session: %w", err)
+	}
+
+	output, err := ec2.New(sess).DescribeAddresses(&ec2.DescribeAddressesInput{})
+	if err != nil {
+		return false, fmt.Errorf("failed to describe addresses: %w", err)
+	}
+	return len(output.Addresses) > 0, nil
+}
+
 // Scan implements Scanner interface
 func (s *ElasticIPScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, error) {
 	// Get regional session
@@ -49,6 +69,13 @@ func (s *ElasticIPScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, er
 		return nil, fmt.Errorf("failed to describe addresses: %w", err)
 	}
 
+	// Addresses of stopped instances are as idle as unassociated ones
+	stoppedInstances, err := s.stoppedInstances(ec2Client, addresses.Addresses)
+	if err != nil {
+		logging.Error("Failed to describe instances of addresses", err, nil)
+		return nil, fmt.Errorf("failed to describe instances of addresses: %w", err)
+	}
+
 	// Use default cost estimator
 	costEstimator := awslib.DefaultCostEstimator
 
@@ -70,8 +97,15 @@ func (s *ElasticIPScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, er
 			resourceName = name
 		}
 
-		// Check if the Elastic IP is not associated with any resource
-		if aws.StringValue(addr.InstanceId) == "" && aws.StringValue(addr.NetworkInterfaceId) == "" {
+		// Check if the Elastic IP is not associated with any resource or with a stopped instance
+		instanceID := aws.StringValue(addr.InstanceId)
+		reason := ""
+		if instanceID == "" && aws.StringValue(addr.NetworkInterfaceId) == "" {
+			reason = "Not associated with any resource"
+		} else if stoppedInstances[instanceID] {
+			reason = fmt.Sprintf("Associated with stopped instance %s", instanceID)
+		}
+		if reason != "" {
 			// Calculate costs - Elastic IPs have a flat rate of $0.005 per hour when not attached
 			costs, err := costEstimator.CalculateCost(awslib.ResourceCostConfig{
 				ResourceType: "ElasticIP",
@@ -92,9 +126,10 @@ func (s *ElasticIPScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, er
 				ResourceType: s.Label(),
 				ResourceName: resourceName,
 				ResourceID:   allocationID,
-				Reason:       "Not associated with any resource",
+				Reason:       reason,
 				Details: map[string]interface{}{
 					"account_id":               opts.AccountID,
+					"instance_id":              instanceID,
 					"region":                   opts.Region,
 					"public_ip":                publicIP,
 					"allocation_id":            allocationID,
@@ -110,6 +145,7 @@ func (s *ElasticIPScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, er
 					"association_id":           aws.StringValue(addr.AssociationId),
 				},
 				Tags: tags,
+				Cost: awslib.NoCost(awslib.NoCostNotEstimated),
 			}
 
 			if costs != nil {
@@ -124,3 +160,39 @@ func (s *ElasticIPScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, er
 
 	return results, nil
 }
+
+// stoppedInstances returns the IDs of the stopping and stopped instances that addresses are
+// associated with
+func (s *ElasticIPScanner) stoppedInstances(ec2Client *ec2.EC2, addresses []*ec2.Address) (map[string]bool, error) {
+	var instanceIDs []*string
+	for _, addr := range addresses {
+		if addr.InstanceId != nil {
+			instanceIDs = append(instanceIDs, addr.InstanceId)
+		}
+	}
+
+	stopped := make(map[string]bool)
+	if len(instanceIDs) == 0 {
+		return stopped, nil
+	}
+	err := ec2Client.DescribeInstancesPages(&ec2.DescribeInstancesInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("instance-id"),
+				Values: instanceIDs,
+			},
+			{
+				Name:   aws.String("instance-state-name"),
+				Values: aws.StringSlice([]string{"stopping", "stopped"}),
+			},
+		},
+	}, func(page *ec2.DescribeInstancesOutput, lastPage bool) bool {
+		for _, reservation := range page.Reservations {
+			for _, instance := range reservation.Instances {
+				stopped[aws.StringValue(instance.InstanceId)] = true
+			}
+		}
+		return true
+	})
+	return stopped, err
+}
@@ -1,16 +1,19 @@
 package scanners
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"strings"
 	"time"
 
 	awslib "cloudsift/internal/aws"
+	"cloudsift/internal/aws/utils"
 	"cloudsift/internal/logging"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/elb"
 	"github.com/aws/aws-sdk-go/service/elbv2"
 )
@@ -37,6 +40,12 @@ func (s *ELBScanner) Label() string {
 	return "Load Balancers"
 }
 
+// RemediationTemplate implements Remediator interface. Classic load balancers are deleted by
+// name, the others by ARN.
+func (s *ELBScanner) RemediationTemplate() string {
+	return `{{if eq (index .Details "type") "classic"}}aws elb delete-load-balancer --load-balancer-name {{.ResourceID}}{{else}}aws elbv2 delete-load-balancer --load-balancer-arn {{.ResourceID}}{{end}} --region {{.Region}}`
+}
+
 // getLoadBalancerName gets the name from tags or ARN
 func (s *ELBScanner) getLoadBalancerName(elbClient *elbv2.ELBV2, lb *elbv2.LoadBalancer) string {
 	// First try to get name from tags
@@ -119,7 +128,7 @@ func (s *ELBScanner) hasAttachedResources(elbClient *elbv2.ELBV2, classicClient
 }
 
 // getLoadBalancerMetrics gets CloudWatch metrics for the load balancer
-func (s *ELBScanner) getLoadBalancerMetrics(cwClient *cloudwatch.CloudWatch, lb interface{}, opts awslib.ScanOptions) (map[string]interface{}, error) {
+func (s *ELBScanner) getLoadBalancerMetrics(cwClient *cloudwatch.CloudWatch, lb interface{}, opts awslib.ScanOptions, recorder *utils.MetricRecorder) (map[string]interface{}, error) {
 	endTime := time.Now()
 	startTime := endTime.Add(-time.Duration(opts.DaysUnused) * 24 * time.Hour)
 
@@ -150,8 +159,11 @@ func (s *ELBScanner) getLoadBalancerMetrics(cwClient *cloudwatch.CloudWatch, lb
 		return nil, fmt.Errorf("unknown load balancer type: %T", lb)
 	}
 
+	ctx, cancel := utils.MetricsContext()
+	defer cancel()
+
 	// Get request count metrics
-	requestData, err := cwClient.GetMetricStatistics(&cloudwatch.GetMetricStatisticsInput{
+	requestData, err := cwClient.GetMetricStatisticsWithContext(ctx, &cloudwatch.GetMetricStatisticsInput{
 		Namespace:  aws.String(namespace),
 		MetricName: aws.String(requestMetric),
 		Dimensions: []*cloudwatch.Dimension{
@@ -166,13 +178,14 @@ func (s *ELBScanner) getLoadBalancerMetrics(cwClient *cloudwatch.CloudWatch, lb
 		Statistics: []*string{
 			aws.String("Sum"),
 		},
-	})
+	}, utils.MetricsRetries)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get request metrics: %w", err)
+		return nil, fmt.Errorf("failed to get request metrics: %w", utils.MetricsError(ctx, err))
 	}
+	recorder.RecordDatapoints(namespace, requestMetric, "Sum", requestData.Datapoints)
 
 	// Get bytes processed metrics
-	bytesData, err := cwClient.GetMetricStatistics(&cloudwatch.GetMetricStatisticsInput{
+	bytesData, err := cwClient.GetMetricStatisticsWithContext(ctx, &cloudwatch.GetMetricStatisticsInput{
 		Namespace:  aws.String(namespace),
 		MetricName: aws.String(bytesMetric),
 		Dimensions: []*cloudwatch.Dimension{
@@ -187,10 +200,11 @@ func (s *ELBScanner) getLoadBalancerMetrics(cwClient *cloudwatch.CloudWatch, lb
 		Statistics: []*string{
 			aws.String("Sum"),
 		},
-	})
+	}, utils.MetricsRetries)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get bytes metrics: %w", err)
+		return nil, fmt.Errorf("failed to get bytes metrics: %w", utils.MetricsError(ctx, err))
 	}
+	recorder.RecordDatapoints(namespace, bytesMetric, "Sum", bytesData.Datapoints)
 
 	// Calculate total requests and bytes
 	var totalRequests, totalBytes float64
@@ -311,6 +325,9 @@ func (s *ELBScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, error) {
 	elbv2Client := elbv2.New(sess)
 	elbClassicClient := elb.New(sess)
 	cwClient := cloudwatch.New(sess)
+	ec2Client := ec2.New(sess)
+	vpcNameByID := vpcNames(opts.ScanContext(), ec2Client, opts.AccountID, opts.Region)
+	subnetNameByID := subnetNames(opts.ScanContext(), ec2Client, opts.AccountID, opts.Region)
 
 	var results awslib.ScanResults
 
@@ -329,8 +346,9 @@ func (s *ELBScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, error) {
 		return nil, fmt.Errorf("failed to describe load balancers: %w", err)
 	}
 
-	// Scan each ALB/NLB
-	for _, lb := range loadBalancers {
+	// Scan the ALBs/NLBs in parallel, sharing the rate limit of the account and region
+	rateLimiter := utils.ResourceRateLimiter(opts, "elb", nil)
+	lbResults, err := utils.ForEachResourceParallel(loadBalancers, rateLimiter, func(ctx context.Context, lb *elbv2.LoadBalancer) (*awslib.ScanResult, error) {
 		lbName := s.getLoadBalancerName(elbv2Client, lb)
 		lbARN := aws.StringValue(lb.LoadBalancerArn)
 
@@ -340,19 +358,26 @@ func (s *ELBScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, error) {
 		})
 
 		// Get metrics
-		metrics, err := s.getLoadBalancerMetrics(cwClient, lb, opts)
+		recorder := utils.NewMetricRecorder(opts.IncludeMetricSamples)
+		metrics, err := s.getLoadBalancerMetrics(cwClient, lb, opts, recorder)
+		metricsErr, metricsUnavailable := err, utils.MetricsDegraded(err)
 		if err != nil {
 			logging.Error("Failed to get load balancer metrics", err, map[string]interface{}{
 				"name": lbName,
 				"arn":  lbARN,
 			})
-			continue
+			if !metricsUnavailable {
+				return nil, nil
+			}
 		}
 
 		// Check if unused based on metrics and resources
-		isUnused, reason := s.isUnusedLoadBalancer(elbv2Client, elbClassicClient, lb, metrics, opts)
+		isUnused, reason := true, utils.MetricsUnavailableReason
+		if !metricsUnavailable {
+			isUnused, reason = s.isUnusedLoadBalancer(elbv2Client, elbClassicClient, lb, metrics, opts)
+		}
 		if !isUnused {
-			continue
+			return nil, nil
 		}
 
 		// Build result details
@@ -363,6 +388,7 @@ func (s *ELBScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, error) {
 			"name":            aws.StringValue(lb.LoadBalancerName),
 			"scheme":          aws.StringValue(lb.Scheme),
 			"vpc_id":          aws.StringValue(lb.VpcId),
+			"vpc_name":        vpcNameByID[aws.StringValue(lb.VpcId)],
 			"state":           aws.StringValue(lb.State.Code),
 			"state_reason":    aws.StringValue(lb.State.Reason),
 			"created_time":    lb.CreatedTime.Format(time.RFC3339),
@@ -374,20 +400,26 @@ func (s *ELBScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, error) {
 				zones := make([]map[string]string, len(lb.AvailabilityZones))
 				for i, az := range lb.AvailabilityZones {
 					zones[i] = map[string]string{
-						"zone_name": aws.StringValue(az.ZoneName),
-						"subnet_id": aws.StringValue(az.SubnetId),
+						"zone_name":   aws.StringValue(az.ZoneName),
+						"subnet_id":   aws.StringValue(az.SubnetId),
+						"subnet_name": subnetNameByID[aws.StringValue(az.SubnetId)],
 					}
 				}
 				return zones
 			}(),
+		}
 
-			// Metric data
-			"total_requests":    metrics["TotalRequests"].(float64),
-			"total_bytes":       metrics["TotalBytesSent"].(float64),
-			"request_deviation": metrics["RequestDeviation"].(float64),
-			"processed_gb":      metrics["ProcessedGB"].(float64),
-			"datapoint_count":   metrics["DatapointCount"].(float64),
+		// Add metric data
+		if metricsUnavailable {
+			utils.MarkMetricsDegraded(details, metricsErr)
+		} else {
+			details["total_requests"] = metrics["TotalRequests"].(float64)
+			details["total_bytes"] = metrics["TotalBytesSent"].(float64)
+			details["request_deviation"] = metrics["RequestDeviation"].(float64)
+			details["processed_gb"] = metrics["ProcessedGB"].(float64)
+			details["datapoint_count"] = metrics["DatapointCount"].(float64)
 		}
+		recorder.AddTo(details)
 
 		// Add tags
 		tags := make(map[string]string)
@@ -402,7 +434,7 @@ func (s *ELBScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, error) {
 			}
 		}
 
-		results = append(results, awslib.ScanResult{
+		return &awslib.ScanResult{
 			ResourceType: s.Label(),
 			ResourceName: lbName,
 			ResourceID:   aws.StringValue(lb.LoadBalancerArn),
@@ -412,8 +444,12 @@ func (s *ELBScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, error) {
 			Cost: map[string]interface{}{
 				"total": s.calculateELBCosts(aws.StringValue(lb.Type), *lb.CreatedTime),
 			},
-		})
+		}, nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	results = append(results, lbResults...)
 
 	// Scan Classic Load Balancers
 	var classicLoadBalancers []*elb.LoadBalancerDescription
@@ -430,8 +466,8 @@ func (s *ELBScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, error) {
 		return nil, fmt.Errorf("failed to describe classic load balancers: %w", err)
 	}
 
-	// Scan each Classic ELB
-	for _, lb := range classicLoadBalancers {
+	// Scan the Classic ELBs in parallel
+	classicResults, err := utils.ForEachResourceParallel(classicLoadBalancers, rateLimiter, func(ctx context.Context, lb *elb.LoadBalancerDescription) (*awslib.ScanResult, error) {
 		lbName := aws.StringValue(lb.LoadBalancerName)
 
 		logging.Debug("Scanning classic load balancer", map[string]interface{}{
@@ -439,18 +475,25 @@ func (s *ELBScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, error) {
 		})
 
 		// Get metrics
-		metrics, err := s.getLoadBalancerMetrics(cwClient, lb, opts)
+		recorder := utils.NewMetricRecorder(opts.IncludeMetricSamples)
+		metrics, err := s.getLoadBalancerMetrics(cwClient, lb, opts, recorder)
+		metricsErr, metricsUnavailable := err, utils.MetricsDegraded(err)
 		if err != nil {
 			logging.Error("Failed to get load balancer metrics", err, map[string]interface{}{
 				"name": lbName,
 			})
-			continue
+			if !metricsUnavailable {
+				return nil, nil
+			}
 		}
 
 		// Check if unused based on metrics and resources
-		isUnused, reason := s.isUnusedLoadBalancer(elbv2Client, elbClassicClient, lb, metrics, opts)
+		isUnused, reason := true, utils.MetricsUnavailableReason
+		if !metricsUnavailable {
+			isUnused, reason = s.isUnusedLoadBalancer(elbv2Client, elbClassicClient, lb, metrics, opts)
+		}
 		if !isUnused {
-			continue
+			return nil, nil
 		}
 
 		// Build result details for classic ELB
@@ -461,6 +504,7 @@ func (s *ELBScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, error) {
 			"dns_name":        aws.StringValue(lb.DNSName),
 			"scheme":          aws.StringValue(lb.Scheme),
 			"vpc_id":          aws.StringValue(lb.VPCId),
+			"vpc_name":        vpcNameByID[aws.StringValue(lb.VPCId)],
 			"created_time":    lb.CreatedTime.Format(time.RFC3339),
 			"security_groups": aws.StringValueSlice(lb.SecurityGroups),
 
@@ -471,6 +515,15 @@ func (s *ELBScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, error) {
 			// Simple string arrays
 			"availability_zones": aws.StringValueSlice(lb.AvailabilityZones),
 			"subnets":            aws.StringValueSlice(lb.Subnets),
+			"subnet_names": func() map[string]string {
+				names := make(map[string]string)
+				for _, subnetID := range aws.StringValueSlice(lb.Subnets) {
+					if name := subnetNameByID[subnetID]; name != "" {
+						names[subnetID] = name
+					}
+				}
+				return names
+			}(),
 			"instance_ids": func() []string {
 				ids := make([]string, len(lb.Instances))
 				for i, inst := range lb.Instances {
@@ -513,14 +566,19 @@ func (s *ELBScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, error) {
 				}
 				return listeners
 			}(),
+		}
 
-			// Metric data
-			"total_requests":    metrics["TotalRequests"].(float64),
-			"total_bytes":       metrics["TotalBytesSent"].(float64),
-			"request_deviation": metrics["RequestDeviation"].(float64),
-			"processed_gb":      metrics["ProcessedGB"].(float64),
-			"datapoint_count":   metrics["DatapointCount"].(float64),
+		// Add metric data
+		if metricsUnavailable {
+			utils.MarkMetricsDegraded(details, metricsErr)
+		} else {
+			details["total_requests"] = metrics["TotalRequests"].(float64)
+			details["total_bytes"] = metrics["TotalBytesSent"].(float64)
+			details["request_deviation"] = metrics["RequestDeviation"].(float64)
+			details["processed_gb"] = metrics["ProcessedGB"].(float64)
+			details["datapoint_count"] = metrics["DatapointCount"].(float64)
 		}
+		recorder.AddTo(details)
 
 		// Add tags
 		tags := make(map[string]string)
@@ -535,7 +593,7 @@ func (s *ELBScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, error) {
 			}
 		}
 
-		results = append(results, awslib.ScanResult{
+		return &awslib.ScanResult{
 			ResourceType: s.Label(),
 			ResourceName: lbName,
 			ResourceID:   aws.StringValue(lb.LoadBalancerName),
@@ -545,8 +603,12 @@ func (s *ELBScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, error) {
 			Cost: map[string]interface{}{
 				"total": s.calculateELBCosts("classic", *lb.CreatedTime),
 			},
-		})
+		}, nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	results = append(results, classicResults...)
 
 	return results, nil
 }
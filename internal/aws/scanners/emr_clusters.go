@@ -0,0 +1,294 @@
+package scanners
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	awslib "cloudsift/internal/aws"
+	"cloudsift/internal/logging"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/emr"
+)
+
+// EMRClusterScanner scans for EMR clusters sitting in the WAITING state without steps. Waiting
+// clusters keep all their instances running and are billed the EC2 and EMR charge of each.
+type EMRClusterScanner struct{}
+
+func init() {
+	awslib.DefaultRegistry.RegisterScanner(&EMRClusterScanner{})
+}
+
+// ArgumentName implements Scanner interface
+func (s *EMRClusterScanner) ArgumentName() string {
+	return "emr-clusters"
+}
+
+// Label implements Scanner interface
+func (s *EMRClusterScanner) Label() string {
+	return "EMR Clusters"
+}
+
+// RemediationTemplate implements Remediator interface
+func (s *EMRClusterScanner) RemediationTemplate() string {
+	return "aws emr terminate-clusters --cluster-ids {{.ResourceID}} --region {{.Region}}"
+}
+
+// HasResources implements ResourceProber interface
+func (s *EMRClusterScanner) HasResources(opts awslib.ScanOptions) (bool, error) {
+	sess, err := awslib.GetSessionInRegion(opts.Session, opts.Region)
+	if err != nil {
+		return false, fmt.Errorf("failed to create regional session: %w", err)
+	}
+
+	output, err := emr.New(sess).ListClusters(&emr.ListClustersInput{
+		ClusterStates: aws.StringSlice([]string{emr.ClusterStateWaiting}),
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to list EMR clusters: %w", err)
+	}
+	return len(output.Clusters) > 0, nil
+}
+
+// lastStep returns the most recent step of a cluster, or nil if no step was ever submitted
+func (s *EMRClusterScanner) lastStep(client *emr.EMR, clusterID string) (*emr.StepSummary, error) {
+	// Steps are listed most recent first
+	output, err := client.ListSteps(&emr.ListStepsInput{
+		ClusterId: aws.String(clusterID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list steps: %w", err)
+	}
+	if len(output.Steps) == 0 {
+		return nil, nil
+	}
+	return output.Steps[0], nil
+}
+
+// runningInstances returns the running instances of a cluster
+func (s *EMRClusterScanner) runningInstances(client *emr.EMR, clusterID string) ([]*emr.Instance, error) {
+	var instances []*emr.Instance
+	err := client.ListInstancesPages(&emr.ListInstancesInput{
+		ClusterId:      aws.String(clusterID),
+		InstanceStates: aws.StringSlice([]string{emr.InstanceStateRunning}),
+	}, func(page *emr.ListInstancesOutput, lastPage bool) bool {
+		instances = append(instances, page.Instances...)
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list instances: %w", err)
+	}
+	return instances, nil
+}
+
+// addCosts adds a cost breakdown to a running total
+func addCosts(total, costs *awslib.CostBreakdown) *awslib.CostBreakdown {
+	if total == nil {
+		return costs
+	}
+	total.HourlyRate += costs.HourlyRate
+	total.DailyRate += costs.DailyRate
+	total.MonthlyRate += costs.MonthlyRate
+	total.YearlyRate += costs.YearlyRate
+	if costs.Lifetime != nil {
+		lifetime := *costs.Lifetime
+		if total.Lifetime != nil {
+			lifetime += *total.Lifetime
+		}
+		total.Lifetime = &lifetime
+	}
+	return total
+}
+
+// clusterCosts calculates the cost of the running instances of a cluster, including the EMR
+// charge on top of the EC2 price of each instance
+func (s *EMRClusterScanner) clusterCosts(instances []*emr.Instance, region string) *awslib.CostBreakdown {
+	costEstimator := awslib.DefaultCostEstimator
+	if costEstimator == nil {
+		return nil
+	}
+
+	var total *awslib.CostBreakdown
+	for _, instance := range instances {
+		instanceType := aws.StringValue(instance.InstanceType)
+		var createdAt time.Time
+		if instance.Status != nil && instance.Status.Timeline != nil {
+			createdAt = aws.TimeValue(instance.Status.Timeline.CreationDateTime)
+		}
+
+		for _, resourceType := range []string{"EC2", "EMR"} {
+			costs, err := costEstimator.CalculateCost(awslib.ResourceCostConfig{
+				ResourceType: resourceType,
+				ResourceSize: instanceType,
+				Region:       region,
+				CreationTime: createdAt,
+			})
+			if err != nil {
+				logging.Error("Failed to calculate EMR instance costs", err, map[string]interface{}{
+					"instance_id":   aws.StringValue(instance.Ec2InstanceId),
+					"instance_type": instanceType,
+					"resource_type": resourceType,
+				})
+				continue
+			}
+			total = addCosts(total, costs)
+		}
+	}
+	if total != nil {
+		total.HourlyRate = roundCost(total.HourlyRate)
+		total.DailyRate = roundCost(total.DailyRate)
+		total.MonthlyRate = roundCost(total.MonthlyRate)
+		total.YearlyRate = roundCost(total.YearlyRate)
+		if total.Lifetime != nil {
+			lifetime := roundCost(*total.Lifetime)
+			total.Lifetime = &lifetime
+		}
+	}
+	return total
+}
+
+// Scan implements Scanner interface
+func (s *EMRClusterScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, error) {
+	sess, err := awslib.GetSessionInRegion(opts.Session, opts.Region)
+	if err != nil {
+		logging.Error("Failed to create regional session", err, map[string]interface{}{
+			"region": opts.Region,
+		})
+		return nil, fmt.Errorf("failed to create regional session: %w", err)
+	}
+	client := emr.New(sess)
+
+	var clusters []*emr.ClusterSummary
+	err = client.ListClustersPages(&emr.ListClustersInput{
+		ClusterStates: aws.StringSlice([]string{emr.ClusterStateWaiting}),
+	}, func(page *emr.ListClustersOutput, lastPage bool) bool {
+		clusters = append(clusters, page.Clusters...)
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list EMR clusters: %w", err)
+	}
+
+	idleThreshold := time.Duration(opts.EMRIdleHours) * time.Hour
+
+	var results awslib.ScanResults
+	for _, summary := range clusters {
+		clusterID := aws.StringValue(summary.Id)
+
+		output, err := client.DescribeCluster(&emr.DescribeClusterInput{ClusterId: summary.Id})
+		if err != nil {
+			logging.Error("Failed to describe EMR cluster", err, map[string]interface{}{
+				"cluster_id": clusterID,
+			})
+			continue
+		}
+		cluster := output.Cluster
+
+		var state string
+		var createdAt, readyAt time.Time
+		if cluster.Status != nil {
+			state = aws.StringValue(cluster.Status.State)
+			if cluster.Status.Timeline != nil {
+				createdAt = aws.TimeValue(cluster.Status.Timeline.CreationDateTime)
+				readyAt = aws.TimeValue(cluster.Status.Timeline.ReadyDateTime)
+			}
+		}
+
+		step, err := s.lastStep(client, clusterID)
+		if err != nil {
+			logging.Error("Failed to get EMR cluster steps", err, map[string]interface{}{
+				"cluster_id": clusterID,
+			})
+			continue
+		}
+
+		// The cluster has been idle since it became ready or since its last step ended,
+		// whichever is later
+		idleSince := readyAt
+		if step != nil && step.Status != nil && step.Status.Timeline != nil {
+			timeline := step.Status.Timeline
+			last := aws.TimeValue(timeline.EndDateTime)
+			if last.IsZero() {
+				last = aws.TimeValue(timeline.CreationDateTime)
+			}
+			if last.After(idleSince) {
+				idleSince = last
+			}
+		}
+		if idleSince.IsZero() || time.Since(idleSince) < idleThreshold {
+			continue
+		}
+		idleHours := int(time.Since(idleSince).Hours())
+
+		instances, err := s.runningInstances(client, clusterID)
+		if err != nil {
+			logging.Error("Failed to get EMR cluster instances", err, map[string]interface{}{
+				"cluster_id": clusterID,
+			})
+			continue
+		}
+
+		instanceTypes := make(map[string]int)
+		for _, instance := range instances {
+			instanceTypes[aws.StringValue(instance.InstanceType)]++
+		}
+
+		details := map[string]interface{}{
+			"account_id":            opts.AccountID,
+			"region":                opts.Region,
+			"state":                 state,
+			"release_label":         aws.StringValue(cluster.ReleaseLabel),
+			"collection_type":       aws.StringValue(cluster.InstanceCollectionType),
+			"running_instances":     len(instances),
+			"instance_types":        instanceTypes,
+			"auto_terminate":        aws.BoolValue(cluster.AutoTerminate),
+			"termination_protected": aws.BoolValue(cluster.TerminationProtected),
+			"idle_hours":            idleHours,
+			"created_at":            createdAt.Format(time.RFC3339),
+		}
+
+		var reasons []string
+		if step == nil {
+			reasons = append(reasons, fmt.Sprintf("Cluster has been waiting for %d hours and no steps were ever submitted.", idleHours))
+		} else {
+			details["last_step"] = aws.StringValue(step.Name)
+			if step.Status != nil {
+				details["last_step_state"] = aws.StringValue(step.Status.State)
+			}
+			reasons = append(reasons, fmt.Sprintf("Cluster has been waiting for %d hours without new steps.", idleHours))
+		}
+
+		var costDetails map[string]interface{}
+		if costs := s.clusterCosts(instances, opts.Region); costs != nil {
+			details["cost_per_hour"] = costs.HourlyRate
+			costDetails = map[string]interface{}{
+				"total": costs,
+			}
+		}
+
+		tags := make(map[string]string, len(cluster.Tags))
+		for _, tag := range cluster.Tags {
+			tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+		}
+
+		results = append(results, awslib.ScanResult{
+			ResourceType: s.Label(),
+			ResourceName: aws.StringValue(cluster.Name),
+			ResourceID:   clusterID,
+			Reason:       strings.Join(reasons, "\n"),
+			Details:      details,
+			Tags:         tags,
+			Cost:         costDetails,
+		})
+	}
+
+	logging.Info("Completed EMR cluster scan", map[string]interface{}{
+		"account_id":       opts.AccountID,
+		"region":           opts.Region,
+		"waiting_clusters": len(clusters),
+		"idle_clusters":    len(results),
+	})
+
+	return results, nil
+}
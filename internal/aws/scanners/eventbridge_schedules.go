@@ -0,0 +1,362 @@
+package scanners
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	awslib "cloudsift/internal/aws"
+	"cloudsift/internal/logging"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/eventbridge"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/aws/aws-sdk-go/service/scheduler"
+	"github.com/aws/aws-sdk-go/service/sfn"
+	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/aws/aws-sdk-go/service/sqs"
+)
+
+// Kinds of scheduled resources, reported in the schedule_kind detail
+const (
+	scheduleKindSchedule = "schedule"
+	scheduleKindRule     = "rule"
+)
+
+// oneTimeSchedulePrefix starts the expression of schedules that run once, e.g. at(2024-03-01T06:00:00)
+const oneTimeSchedulePrefix = "at("
+
+// EventBridgeSchedulesScanner scans for EventBridge Scheduler schedules and scheduled
+// EventBridge (CloudWatch Events) rules that no longer do anything useful: schedules disabled
+// for longer than the threshold, past their end date or one-time schedules that already ran,
+// schedules and rules whose targets were deleted, and disabled rules or rules duplicating a
+// schedule they were migrated to. Orphaned schedules keep firing against deleted
+// infrastructure, or fire again when someone reenables them.
+type EventBridgeSchedulesScanner struct{}
+
+func init() {
+	awslib.DefaultRegistry.RegisterScanner(&EventBridgeSchedulesScanner{})
+}
+
+// ArgumentName implements Scanner interface
+func (s *EventBridgeSchedulesScanner) ArgumentName() string {
+	return "eventbridge-schedules"
+}
+
+// Label implements Scanner interface
+func (s *EventBridgeSchedulesScanner) Label() string {
+	return "EventBridge Schedules"
+}
+
+// RemediationTemplate implements Remediator interface. Rules are deleted after removing their
+// targets, which EventBridge requires.
+func (s *EventBridgeSchedulesScanner) RemediationTemplate() string {
+	return `{{if eq (index .Details "schedule_kind") "rule"}}` +
+		`aws events remove-targets --rule {{.ResourceName}} --ids {{index .Details "target_ids"}} --region {{.Region}} && aws events delete-rule --name {{.ResourceName}} --region {{.Region}}` +
+		`{{else}}aws scheduler delete-schedule --name {{.ResourceName}} --group-name {{index .Details "group_name"}} --region {{.Region}}{{end}}`
+}
+
+// HasResources implements ResourceProber interface
+func (s *EventBridgeSchedulesScanner) HasResources(opts awslib.ScanOptions) (bool, error) {
+	sess, err := awslib.GetSessionInRegion(opts.Session, opts.Region)
+	if err != nil {
+		return false, fmt.Errorf("failed to create regional session: %w", err)
+	}
+
+	schedules, err := scheduler.New(sess).ListSchedules(&scheduler.ListSchedulesInput{MaxResults: aws.Int64(1)})
+	if err != nil {
+		return false, fmt.Errorf("failed to list schedules: %w", err)
+	}
+	if len(schedules.Schedules) > 0 {
+		return true, nil
+	}
+	rules, err := eventbridge.New(sess).ListRules(&eventbridge.ListRulesInput{Limit: aws.Int64(1)})
+	if err != nil {
+		return false, fmt.Errorf("failed to list rules: %w", err)
+	}
+	return len(rules.Rules) > 0, nil
+}
+
+// scheduleKey identifies what a schedule or rule runs, so rules migrated to a schedule can be
+// matched with it
+func scheduleKey(expression, targetArn string) string {
+	return expression + "\x00" + targetArn
+}
+
+// Scan implements Scanner interface
+func (s *EventBridgeSchedulesScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, error) {
+	sess, err := awslib.GetSessionInRegion(opts.Session, opts.Region)
+	if err != nil {
+		logging.Error("Failed to create regional session", err, map[string]interface{}{
+			"region": opts.Region,
+		})
+		return nil, fmt.Errorf("failed to create regional session: %w", err)
+	}
+	schedulerClient := scheduler.New(sess)
+	eventsClient := eventbridge.New(sess)
+	now := time.Now().UTC()
+	threshold := now.Add(-time.Duration(opts.DaysUnused) * 24 * time.Hour)
+
+	logging.Debug("Starting EventBridge schedule scan", map[string]interface{}{
+		"account_id": opts.AccountID,
+		"region":     opts.Region,
+	})
+
+	var summaries []*scheduler.ScheduleSummary
+	err = schedulerClient.ListSchedulesPages(&scheduler.ListSchedulesInput{}, func(page *scheduler.ListSchedulesOutput, lastPage bool) bool {
+		summaries = append(summaries, page.Schedules...)
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list schedules: %w", err)
+	}
+
+	// Targets are often shared by several schedules and rules, so each is checked once
+	deletedTargets := make(map[string]bool)
+	targetDeleted := func(targetArn string) bool {
+		deleted, ok := deletedTargets[targetArn]
+		if !ok {
+			var err error
+			deleted, err = s.targetDeleted(opts, sess, targetArn)
+			if err != nil {
+				logging.Error("Failed to check schedule target", err, map[string]interface{}{
+					"target_arn": targetArn,
+				})
+			}
+			deletedTargets[targetArn] = deleted
+		}
+		return deleted
+	}
+
+	var results awslib.ScanResults
+	schedules := make(map[string]string) // Expression and target of enabled schedules to the schedule name
+	for _, summary := range summaries {
+		name := aws.StringValue(summary.Name)
+		groupName := aws.StringValue(summary.GroupName)
+		schedule, err := schedulerClient.GetSchedule(&scheduler.GetScheduleInput{
+			Name:      summary.Name,
+			GroupName: summary.GroupName,
+		})
+		if err != nil {
+			logging.Error("Failed to get schedule", err, map[string]interface{}{
+				"schedule_name": name,
+				"group_name":    groupName,
+			})
+			continue
+		}
+
+		expression := aws.StringValue(schedule.ScheduleExpression)
+		var targetArn string
+		if schedule.Target != nil {
+			targetArn = aws.StringValue(schedule.Target.Arn)
+		}
+		state := aws.StringValue(schedule.State)
+		if state == scheduler.ScheduleStateEnabled {
+			schedules[scheduleKey(expression, targetArn)] = name
+		}
+
+		var reasons []string
+		lastModified := aws.TimeValue(schedule.LastModificationDate)
+		if state == scheduler.ScheduleStateDisabled && lastModified.Before(threshold) {
+			reasons = append(reasons, fmt.Sprintf("Schedule has been disabled since %s.", lastModified.UTC().Format("2006-01-02")))
+		}
+		if endDate := aws.TimeValue(schedule.EndDate); !endDate.IsZero() && endDate.Before(now) {
+			reasons = append(reasons, fmt.Sprintf("Schedule ended on %s and does not run again.", endDate.UTC().Format("2006-01-02")))
+		}
+		if runAt, ok := oneTimeScheduleTime(expression, aws.StringValue(schedule.ScheduleExpressionTimezone)); ok && runAt.Before(now) {
+			reasons = append(reasons, fmt.Sprintf("One-time schedule ran on %s and was not deleted.", runAt.UTC().Format("2006-01-02")))
+		}
+		if targetArn != "" && targetDeleted(targetArn) {
+			reasons = append(reasons, "Schedule targets a deleted resource.")
+		}
+		if len(reasons) == 0 {
+			continue
+		}
+
+		results = append(results, awslib.ScanResult{
+			ResourceType: s.Label(),
+			ResourceName: name,
+			ResourceID:   aws.StringValue(schedule.Arn),
+			Reason:       strings.Join(reasons, "\n"),
+			Details: map[string]interface{}{
+				"account_id":          opts.AccountID,
+				"region":              opts.Region,
+				"schedule_kind":       scheduleKindSchedule,
+				"group_name":          groupName,
+				"state":               state,
+				"schedule_expression": expression,
+				"target_arn":          targetArn,
+				"created_at":          aws.TimeValue(schedule.CreationDate).UTC().Format(time.RFC3339),
+				"last_modified":       lastModified.UTC().Format(time.RFC3339),
+			},
+			// Invocations of a schedule are billed per million, and far less than a cent for
+			// schedules like these
+			Cost: awslib.NoCost(awslib.NoCostNotEstimated),
+		})
+	}
+
+	// Scheduled rules only exist on the default event bus
+	var rules []*eventbridge.Rule
+	input := &eventbridge.ListRulesInput{}
+	for {
+		output, err := eventsClient.ListRules(input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list rules: %w", err)
+		}
+		rules = append(rules, output.Rules...)
+		if aws.StringValue(output.NextToken) == "" {
+			break
+		}
+		input.NextToken = output.NextToken
+	}
+
+	scheduledRules := 0
+	for _, rule := range rules {
+		expression := aws.StringValue(rule.ScheduleExpression)
+		// Rules managed by another service are removed with the resource that created them
+		if expression == "" || aws.StringValue(rule.ManagedBy) != "" {
+			continue
+		}
+		scheduledRules++
+		name := aws.StringValue(rule.Name)
+
+		var targets []*eventbridge.Target
+		targetsInput := &eventbridge.ListTargetsByRuleInput{Rule: rule.Name}
+		for {
+			output, err := eventsClient.ListTargetsByRule(targetsInput)
+			if err != nil {
+				logging.Error("Failed to list rule targets", err, map[string]interface{}{
+					"rule_name": name,
+				})
+				break
+			}
+			targets = append(targets, output.Targets...)
+			if aws.StringValue(output.NextToken) == "" {
+				break
+			}
+			targetsInput.NextToken = output.NextToken
+		}
+
+		var targetIDs, targetArns []string
+		deleted := 0
+		var migratedTo string
+		for _, target := range targets {
+			targetArn := aws.StringValue(target.Arn)
+			targetIDs = append(targetIDs, aws.StringValue(target.Id))
+			targetArns = append(targetArns, targetArn)
+			if targetDeleted(targetArn) {
+				deleted++
+			}
+			if schedule, ok := schedules[scheduleKey(expression, targetArn)]; ok && migratedTo == "" {
+				migratedTo = schedule
+			}
+		}
+
+		var reasons []string
+		state := aws.StringValue(rule.State)
+		if state == eventbridge.RuleStateDisabled {
+			reasons = append(reasons, "Scheduled rule is disabled.")
+		}
+		if len(targets) > 0 && deleted == len(targets) {
+			reasons = append(reasons, "Scheduled rule targets only deleted resources.")
+		}
+		if migratedTo != "" {
+			reasons = append(reasons, fmt.Sprintf("Scheduled rule duplicates schedule %s with the same expression and target.", migratedTo))
+		}
+		if len(reasons) == 0 {
+			continue
+		}
+
+		results = append(results, awslib.ScanResult{
+			ResourceType: s.Label(),
+			ResourceName: name,
+			ResourceID:   aws.StringValue(rule.Arn),
+			Reason:       strings.Join(reasons, "\n"),
+			Details: map[string]interface{}{
+				"account_id":          opts.AccountID,
+				"region":              opts.Region,
+				"schedule_kind":       scheduleKindRule,
+				"state":               state,
+				"schedule_expression": expression,
+				"target_ids":          strings.Join(targetIDs, " "),
+				"target_arns":         targetArns,
+			},
+			// Scheduled rules are not billed
+			Cost: awslib.NoCost(awslib.NoCostFree),
+		})
+	}
+
+	logging.Debug("EventBridge schedule scan completed", map[string]interface{}{
+		"account_id":      opts.AccountID,
+		"region":          opts.Region,
+		"schedules":       len(summaries),
+		"scheduled_rules": scheduledRules,
+		"findings":        len(results),
+	})
+
+	return results, nil
+}
+
+// oneTimeScheduleTime returns when a one-time schedule runs, from its at() expression in its
+// time zone
+func oneTimeScheduleTime(expression, timezone string) (time.Time, bool) {
+	if !strings.HasPrefix(expression, oneTimeSchedulePrefix) || !strings.HasSuffix(expression, ")") {
+		return time.Time{}, false
+	}
+	location := time.UTC
+	if timezone != "" {
+		if loaded, err := time.LoadLocation(timezone); err == nil {
+			location = loaded
+		}
+	}
+	runAt, err := time.ParseInLocation("2006-01-02T15:04:05", strings.TrimSuffix(strings.TrimPrefix(expression, oneTimeSchedulePrefix), ")"), location)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return runAt, true
+}
+
+// targetDeleted reports whether the Lambda function, SQS queue, SNS topic or Step Functions
+// state machine a schedule or rule targets was deleted. Other targets, universal targets of
+// the Scheduler and resources of other accounts or regions are reported as not deleted.
+func (s *EventBridgeSchedulesScanner) targetDeleted(opts awslib.ScanOptions, sess *session.Session, targetArn string) (bool, error) {
+	parsed, err := arn.Parse(targetArn)
+	if err != nil || parsed.AccountID != opts.AccountID || parsed.Region != opts.Region {
+		return false, nil
+	}
+
+	var code string
+	switch parsed.Service {
+	case "lambda":
+		// Qualified ARNs of versions and aliases are accepted as function names
+		_, err = lambda.New(sess).GetFunction(&lambda.GetFunctionInput{FunctionName: aws.String(targetArn)})
+		code = lambda.ErrCodeResourceNotFoundException
+	case "sqs":
+		_, err = sqs.New(sess).GetQueueUrl(&sqs.GetQueueUrlInput{
+			QueueName:              aws.String(parsed.Resource),
+			QueueOwnerAWSAccountId: aws.String(parsed.AccountID),
+		})
+		code = sqs.ErrCodeQueueDoesNotExist
+	case "sns":
+		_, err = sns.New(sess).GetTopicAttributes(&sns.GetTopicAttributesInput{TopicArn: aws.String(targetArn)})
+		code = sns.ErrCodeNotFoundException
+	case "states":
+		if !strings.HasPrefix(parsed.Resource, "stateMachine:") {
+			return false, nil
+		}
+		_, err = sfn.New(sess).DescribeStateMachine(&sfn.DescribeStateMachineInput{StateMachineArn: aws.String(targetArn)})
+		code = sfn.ErrCodeStateMachineDoesNotExist
+	default:
+		return false, nil
+	}
+	if err == nil {
+		return false, nil
+	}
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == code {
+		return true, nil
+	}
+	return false, fmt.Errorf("failed to check %s target: %w", parsed.Service, err)
+}
@@ -0,0 +1,388 @@
+package scanners
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	awslib "cloudsift/internal/aws"
+	"cloudsift/internal/aws/utils"
+	"cloudsift/internal/logging"
+	"cloudsift/internal/worker"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/glue"
+)
+
+const (
+	// glueDevEndpointMaxAge is how long a dev endpoint may run before it is reported. Dev
+	// endpoints are billed per minute while provisioned, whether or not they are used.
+	glueDevEndpointMaxAge = 24 * time.Hour
+
+	// glueMinBookmarkBytes is the bookmark size from which an idle job is reported. Smaller
+	// bookmarks hold little more than the run metadata.
+	glueMinBookmarkBytes = 1024
+)
+
+// glueWorkerDPUs is the number of DPUs each Glue worker type provides
+var glueWorkerDPUs = map[string]float64{
+	glue.WorkerTypeStandard: 1,
+	glue.WorkerTypeG1x:      1,
+	glue.WorkerTypeG2x:      2,
+	glue.WorkerTypeG025x:    0.25,
+	glue.WorkerTypeG4x:      4,
+	glue.WorkerTypeG8x:      8,
+}
+
+// glueDPUs returns the DPUs allocated to a Glue job or dev endpoint
+func glueDPUs(workerType string, numberOfWorkers int64, capacity float64) float64 {
+	if numberOfWorkers > 0 {
+		if dpus, ok := glueWorkerDPUs[workerType]; ok {
+			return float64(numberOfWorkers) * dpus
+		}
+		return float64(numberOfWorkers)
+	}
+	return capacity
+}
+
+// GlueScanner scans for Glue dev endpoints left running, crawlers that no longer run
+// successfully and ETL jobs that stopped running but keep bookmark state
+type GlueScanner struct{}
+
+func init() {
+	awslib.DefaultRegistry.RegisterScanner(&GlueScanner{})
+}
+
+// ArgumentName implements Scanner interface
+func (s *GlueScanner) ArgumentName() string {
+	return "glue"
+}
+
+// Label implements Scanner interface
+func (s *GlueScanner) Label() string {
+	return "Glue Resources"
+}
+
+// RemediationTemplate implements Remediator interface. Dev endpoints, crawlers and jobs are
+// deleted with their own commands.
+func (s *GlueScanner) RemediationTemplate() string {
+	return `{{$type := index .Details "glue_resource_type"}}{{if eq $type "dev_endpoint"}}aws glue delete-dev-endpoint --endpoint-name {{.ResourceID}}{{else if eq $type "crawler"}}aws glue delete-crawler --name {{.ResourceID}}{{else}}aws glue delete-job --job-name {{.ResourceID}}{{end}} --region {{.Region}}`
+}
+
+// HasResources implements ResourceProber interface
+func (s *GlueScanner) HasResources(opts awslib.ScanOptions) (bool, error) {
+	if !utils.ServiceAvailable(glue.EndpointsID, opts.Region) {
+		return false, nil
+	}
+	sess, err := awslib.GetSessionInRegion(opts.Session, opts.Region)
+	if err != nil {
+		return false, fmt.Errorf("failed to create regional session: %w", err)
+	}
+	client := glue.New(sess)
+
+	jobs, err := client.GetJobs(&glue.GetJobsInput{MaxResults: aws.Int64(1)})
+	if err != nil {
+		return false, fmt.Errorf("failed to get Glue jobs: %w", err)
+	}
+	if len(jobs.Jobs) > 0 {
+		return true, nil
+	}
+	crawlers, err := client.GetCrawlers(&glue.GetCrawlersInput{MaxResults: aws.Int64(1)})
+	if err != nil {
+		return false, fmt.Errorf("failed to get Glue crawlers: %w", err)
+	}
+	if len(crawlers.Crawlers) > 0 {
+		return true, nil
+	}
+	endpoints, err := client.GetDevEndpoints(&glue.GetDevEndpointsInput{MaxResults: aws.Int64(1)})
+	if err != nil {
+		return false, fmt.Errorf("failed to get Glue dev endpoints: %w", err)
+	}
+	return len(endpoints.DevEndpoints) > 0, nil
+}
+
+// glueCost calculates the hourly cost of the DPUs of a Glue resource
+func glueCost(dpus float64, region string, createdAt time.Time) map[string]interface{} {
+	if awslib.DefaultCostEstimator == nil || dpus == 0 {
+		return nil
+	}
+	costs, err := awslib.DefaultCostEstimator.CalculateCost(awslib.ResourceCostConfig{
+		ResourceType: "GlueDPU",
+		ResourceSize: dpus,
+		Region:       region,
+		CreationTime: createdAt,
+	})
+	if err != nil {
+		logging.Error("Failed to calculate Glue costs", err, map[string]interface{}{
+			"region": region,
+		})
+		return nil
+	}
+	return map[string]interface{}{
+		"total": costs,
+	}
+}
+
+// scanDevEndpoints reports dev endpoints that have been running longer than glueDevEndpointMaxAge
+func (s *GlueScanner) scanDevEndpoints(client *glue.Glue, opts awslib.ScanOptions) (awslib.ScanResults, error) {
+	var results awslib.ScanResults
+	err := client.GetDevEndpointsPages(&glue.GetDevEndpointsInput{}, func(page *glue.GetDevEndpointsOutput, lastPage bool) bool {
+		for _, endpoint := range page.DevEndpoints {
+			createdAt := aws.TimeValue(endpoint.CreatedTimestamp)
+			running := time.Since(createdAt)
+			if aws.StringValue(endpoint.Status) != "READY" || running < glueDevEndpointMaxAge {
+				continue
+			}
+
+			dpus := glueDPUs(aws.StringValue(endpoint.WorkerType), aws.Int64Value(endpoint.NumberOfWorkers), float64(aws.Int64Value(endpoint.NumberOfNodes)))
+			name := aws.StringValue(endpoint.EndpointName)
+			results = append(results, awslib.ScanResult{
+				ResourceType: s.Label(),
+				ResourceName: name,
+				ResourceID:   name,
+				Reason:       fmt.Sprintf("Dev endpoint has been running for %d hours and is billed per DPU-hour.", int(running.Hours())),
+				Details: map[string]interface{}{
+					"account_id":         opts.AccountID,
+					"region":             opts.Region,
+					"glue_resource_type": "dev_endpoint",
+					"status":             aws.StringValue(endpoint.Status),
+					"glue_version":       aws.StringValue(endpoint.GlueVersion),
+					"worker_type":        aws.StringValue(endpoint.WorkerType),
+					"number_of_workers":  aws.Int64Value(endpoint.NumberOfWorkers),
+					"number_of_nodes":    aws.Int64Value(endpoint.NumberOfNodes),
+					"dpus":               dpus,
+					"created_at":         createdAt.Format(time.RFC3339),
+				},
+				Cost: glueCost(dpus, opts.Region, createdAt),
+			})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Glue dev endpoints: %w", err)
+	}
+	return results, nil
+}
+
+// scanCrawlers reports crawlers that have not run successfully within the unused threshold
+func (s *GlueScanner) scanCrawlers(client *glue.Glue, opts awslib.ScanOptions, cutoff time.Time) (awslib.ScanResults, error) {
+	var results awslib.ScanResults
+	err := client.GetCrawlersPages(&glue.GetCrawlersInput{}, func(page *glue.GetCrawlersOutput, lastPage bool) bool {
+		for _, crawler := range page.Crawlers {
+			createdAt := aws.TimeValue(crawler.CreationTime)
+			if createdAt.After(cutoff) {
+				continue
+			}
+
+			details := map[string]interface{}{
+				"account_id":         opts.AccountID,
+				"region":             opts.Region,
+				"glue_resource_type": "crawler",
+				"state":              aws.StringValue(crawler.State),
+				"database_name":      aws.StringValue(crawler.DatabaseName),
+				"created_at":         createdAt.Format(time.RFC3339),
+			}
+			if crawler.Schedule != nil {
+				details["schedule"] = aws.StringValue(crawler.Schedule.ScheduleExpression)
+				details["schedule_state"] = aws.StringValue(crawler.Schedule.State)
+			}
+
+			var reason string
+			last := crawler.LastCrawl
+			switch {
+			case last == nil:
+				reason = "Crawler has never run."
+			case aws.StringValue(last.Status) != glue.LastCrawlStatusSucceeded:
+				details["last_crawl"] = aws.TimeValue(last.StartTime).Format(time.RFC3339)
+				details["last_crawl_status"] = aws.StringValue(last.Status)
+				details["last_crawl_error"] = aws.StringValue(last.ErrorMessage)
+				reason = fmt.Sprintf("Last crawl did not succeed (%s).", aws.StringValue(last.Status))
+			case aws.TimeValue(last.StartTime).Before(cutoff):
+				details["last_crawl"] = aws.TimeValue(last.StartTime).Format(time.RFC3339)
+				details["last_crawl_status"] = aws.StringValue(last.Status)
+				reason = fmt.Sprintf("Crawler has not run in the last %d days.", opts.DaysUnused)
+			default:
+				continue
+			}
+
+			name := aws.StringValue(crawler.Name)
+			results = append(results, awslib.ScanResult{
+				ResourceType: s.Label(),
+				ResourceName: name,
+				ResourceID:   name,
+				Reason:       reason,
+				Details:      details,
+			})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Glue crawlers: %w", err)
+	}
+	return results, nil
+}
+
+// jobBookmarkSize returns the size of a job's bookmark state, or 0 if it has none
+func (s *GlueScanner) jobBookmarkSize(client *glue.Glue, jobName string) (int, error) {
+	output, err := client.GetJobBookmark(&glue.GetJobBookmarkInput{JobName: aws.String(jobName)})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == glue.ErrCodeEntityNotFoundException {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to get job bookmark: %w", err)
+	}
+	if output.JobBookmarkEntry == nil {
+		return 0, nil
+	}
+	return len(aws.StringValue(output.JobBookmarkEntry.JobBookmark)), nil
+}
+
+// scanJobs reports ETL jobs without runs within the unused threshold that keep bookmark state
+func (s *GlueScanner) scanJobs(client *glue.Glue, opts awslib.ScanOptions, cutoff time.Time) (awslib.ScanResults, error) {
+	var jobs []*glue.Job
+	err := client.GetJobsPages(&glue.GetJobsInput{}, func(page *glue.GetJobsOutput, lastPage bool) bool {
+		jobs = append(jobs, page.Jobs...)
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Glue jobs: %w", err)
+	}
+
+	var results awslib.ScanResults
+	var resultsMutex sync.Mutex
+	var tasks []worker.Task
+
+	for _, job := range jobs {
+		job := job
+		createdAt := aws.TimeValue(job.CreatedOn)
+		if createdAt.After(cutoff) {
+			continue
+		}
+		tasks = append(tasks, func(ctx context.Context) error {
+			name := aws.StringValue(job.Name)
+
+			// Runs are listed most recent first
+			runs, err := client.GetJobRuns(&glue.GetJobRunsInput{
+				JobName:    aws.String(name),
+				MaxResults: aws.Int64(1),
+			})
+			if err != nil {
+				logging.Error("Failed to get Glue job runs", err, map[string]interface{}{
+					"job_name": name,
+				})
+				return nil
+			}
+			var lastRun *time.Time
+			if len(runs.JobRuns) > 0 {
+				lastRun = runs.JobRuns[0].StartedOn
+			}
+			if lastRun != nil && lastRun.After(cutoff) {
+				return nil
+			}
+
+			bookmarkSize, err := s.jobBookmarkSize(client, name)
+			if err != nil {
+				logging.Error("Failed to get Glue job bookmark", err, map[string]interface{}{
+					"job_name": name,
+				})
+				return nil
+			}
+			if bookmarkSize < glueMinBookmarkBytes {
+				return nil
+			}
+
+			dpus := glueDPUs(aws.StringValue(job.WorkerType), aws.Int64Value(job.NumberOfWorkers), aws.Float64Value(job.MaxCapacity))
+			details := map[string]interface{}{
+				"account_id":          opts.AccountID,
+				"region":              opts.Region,
+				"glue_resource_type":  "job",
+				"glue_version":        aws.StringValue(job.GlueVersion),
+				"worker_type":         aws.StringValue(job.WorkerType),
+				"number_of_workers":   aws.Int64Value(job.NumberOfWorkers),
+				"dpus":                dpus,
+				"bookmark_size_bytes": bookmarkSize,
+				"created_at":          createdAt.Format(time.RFC3339),
+			}
+			if job.Command != nil {
+				details["command"] = aws.StringValue(job.Command.Name)
+			}
+			if lastRun != nil {
+				details["last_run"] = lastRun.Format(time.RFC3339)
+			}
+
+			// Idle jobs cost nothing, so the cost of an hour of the job's DPUs is shown as
+			// context for what each forgotten run would burn
+			if costs := glueCost(dpus, opts.Region, time.Now()); costs != nil {
+				details["cost_per_run_hour"] = costs["total"].(*awslib.CostBreakdown).HourlyRate
+			}
+
+			reasons := []string{
+				fmt.Sprintf("Job has not run in the last %d days but keeps %.2f KB of bookmark state.", opts.DaysUnused, float64(bookmarkSize)/1024),
+			}
+
+			resultsMutex.Lock()
+			results = append(results, awslib.ScanResult{
+				ResourceType: s.Label(),
+				ResourceName: name,
+				ResourceID:   name,
+				Reason:       strings.Join(reasons, "\n"),
+				Details:      details,
+			})
+			resultsMutex.Unlock()
+			return nil
+		})
+	}
+
+	worker.GetSharedPool().ExecuteTasks(tasks)
+	return results, nil
+}
+
+// Scan implements Scanner interface
+func (s *GlueScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, error) {
+	if !utils.ServiceAvailable(glue.EndpointsID, opts.Region) {
+		return nil, nil
+	}
+
+	sess, err := awslib.GetSessionInRegion(opts.Session, opts.Region)
+	if err != nil {
+		logging.Error("Failed to create regional session", err, map[string]interface{}{
+			"region": opts.Region,
+		})
+		return nil, fmt.Errorf("failed to create regional session: %w", err)
+	}
+	client := glue.New(sess)
+	cutoff := time.Now().UTC().Add(-time.Duration(opts.DaysUnused) * 24 * time.Hour)
+
+	var results awslib.ScanResults
+
+	endpoints, err := s.scanDevEndpoints(client, opts)
+	if err != nil {
+		return nil, err
+	}
+	results = append(results, endpoints...)
+
+	crawlers, err := s.scanCrawlers(client, opts, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	results = append(results, crawlers...)
+
+	jobs, err := s.scanJobs(client, opts, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	results = append(results, jobs...)
+
+	logging.Info("Completed Glue scan", map[string]interface{}{
+		"account_id":    opts.AccountID,
+		"region":        opts.Region,
+		"dev_endpoints": len(endpoints),
+		"crawlers":      len(crawlers),
+		"jobs":          len(jobs),
+	})
+
+	return results, nil
+}
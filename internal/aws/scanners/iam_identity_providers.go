@@ -0,0 +1,236 @@
+package scanners
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	awslib "cloudsift/internal/aws"
+	"cloudsift/internal/aws/utils"
+	"cloudsift/internal/logging"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/iam"
+)
+
+// IAMIdentityProviderScanner finds OIDC and SAML identity providers older than the unused
+// threshold that no role trust policy references, so nothing can be federated through them
+type IAMIdentityProviderScanner struct{}
+
+func init() {
+	awslib.DefaultRegistry.RegisterScanner(&IAMIdentityProviderScanner{})
+}
+
+// ArgumentName implements Scanner interface
+func (s *IAMIdentityProviderScanner) ArgumentName() string {
+	return "iam-identity-providers"
+}
+
+// Label implements Scanner interface
+func (s *IAMIdentityProviderScanner) Label() string {
+	return "IAM Identity Providers"
+}
+
+// RemediationTemplate implements Remediator interface
+func (s *IAMIdentityProviderScanner) RemediationTemplate() string {
+	return `{{if eq (index .Details "provider_type") "OIDC"}}aws iam delete-open-id-connect-provider --open-id-connect-provider-arn {{.ResourceID}}{{else}}aws iam delete-saml-provider --saml-provider-arn {{.ResourceID}}{{end}}`
+}
+
+// identityProvider is an OIDC or SAML provider of the account
+type identityProvider struct {
+	arn          string
+	providerType string // OIDC or SAML
+	createdAt    time.Time
+}
+
+// stringOrSlice decodes policy elements that are either a string or a list of strings
+type stringOrSlice []string
+
+func (s *stringOrSlice) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*s = []string{single}
+		return nil
+	}
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return err
+	}
+	*s = multiple
+	return nil
+}
+
+// trustPolicyStatement is the part of a trust policy statement naming federated principals
+type trustPolicyStatement struct {
+	Principal json.RawMessage `json:"Principal"`
+}
+
+// federatedPrincipals returns the federated principals of a URL-encoded trust policy, such as
+// the ARNs of the OIDC and SAML providers the role trusts
+func federatedPrincipals(document string) ([]string, error) {
+	decoded, err := url.QueryUnescape(document)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode trust policy: %w", err)
+	}
+
+	var policy struct {
+		Statement json.RawMessage `json:"Statement"`
+	}
+	if err := json.Unmarshal([]byte(decoded), &policy); err != nil {
+		return nil, fmt.Errorf("failed to parse trust policy: %w", err)
+	}
+
+	// Statement is either a single statement or a list of statements
+	var statements []trustPolicyStatement
+	if err := json.Unmarshal(policy.Statement, &statements); err != nil {
+		var statement trustPolicyStatement
+		if err := json.Unmarshal(policy.Statement, &statement); err != nil {
+			return nil, fmt.Errorf("failed to parse trust policy statements: %w", err)
+		}
+		statements = []trustPolicyStatement{statement}
+	}
+
+	var principals []string
+	for _, statement := range statements {
+		// Principal is "*" or a map of principal types
+		var principal struct {
+			Federated stringOrSlice `json:"Federated"`
+		}
+		if json.Unmarshal(statement.Principal, &principal) == nil {
+			principals = append(principals, principal.Federated...)
+		}
+	}
+	return principals, nil
+}
+
+// listProviders returns the OIDC and SAML providers of the account
+func (s *IAMIdentityProviderScanner) listProviders(iamClient *iam.IAM) ([]identityProvider, error) {
+	var providers []identityProvider
+
+	oidcProviders, err := iamClient.ListOpenIDConnectProviders(&iam.ListOpenIDConnectProvidersInput{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list OIDC providers: %w", err)
+	}
+	for _, entry := range oidcProviders.OpenIDConnectProviderList {
+		// The list only holds ARNs, the creation date needs the provider itself
+		provider, err := iamClient.GetOpenIDConnectProvider(&iam.GetOpenIDConnectProviderInput{
+			OpenIDConnectProviderArn: entry.Arn,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get OIDC provider %s: %w", aws.StringValue(entry.Arn), err)
+		}
+		providers = append(providers, identityProvider{
+			arn:          aws.StringValue(entry.Arn),
+			providerType: "OIDC",
+			createdAt:    aws.TimeValue(provider.CreateDate),
+		})
+	}
+
+	samlProviders, err := iamClient.ListSAMLProviders(&iam.ListSAMLProvidersInput{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list SAML providers: %w", err)
+	}
+	for _, entry := range samlProviders.SAMLProviderList {
+		providers = append(providers, identityProvider{
+			arn:          aws.StringValue(entry.Arn),
+			providerType: "SAML",
+			createdAt:    aws.TimeValue(entry.CreateDate),
+		})
+	}
+
+	return providers, nil
+}
+
+// Scan implements Scanner interface
+func (s *IAMIdentityProviderScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, error) {
+	sess, err := awslib.GetSessionInRegion(opts.Session, opts.Region)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create regional session: %w", err)
+	}
+	iamClient := iam.New(sess)
+
+	providers, err := s.listProviders(iamClient)
+	if err != nil {
+		return nil, err
+	}
+	if len(providers) == 0 {
+		return nil, nil
+	}
+
+	// Collect the providers trusted by any role
+	trusted := make(map[string]bool)
+	err = iamClient.ListRolesPages(&iam.ListRolesInput{},
+		func(page *iam.ListRolesOutput, lastPage bool) bool {
+			for _, role := range page.Roles {
+				document := aws.StringValue(role.AssumeRolePolicyDocument)
+				principals, err := federatedPrincipals(document)
+				if err != nil {
+					// Rather than report a provider the role may trust, treat any provider the
+					// policy mentions as trusted
+					logging.Warn("Failed to read role trust policy", map[string]interface{}{
+						"role_name": aws.StringValue(role.RoleName),
+						"error":     err.Error(),
+					})
+					decoded, _ := url.QueryUnescape(document)
+					for _, provider := range providers {
+						if strings.Contains(decoded, provider.arn) {
+							trusted[provider.arn] = true
+						}
+					}
+					continue
+				}
+				for _, principal := range principals {
+					trusted[principal] = true
+				}
+			}
+			return true
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list roles: %w", err)
+	}
+
+	now := time.Now().UTC()
+	threshold := now.AddDate(0, 0, -opts.DaysUnused)
+	var results awslib.ScanResults
+	for _, provider := range providers {
+		if trusted[provider.arn] || provider.createdAt.After(threshold) {
+			continue
+		}
+
+		createdAt := provider.createdAt
+		results = append(results, awslib.ScanResult{
+			ResourceType: s.Label(),
+			ResourceName: providerName(provider.arn),
+			ResourceID:   provider.arn,
+			Reason: fmt.Sprintf("%s provider created %d days ago is not trusted by any role.",
+				provider.providerType, int(now.Sub(createdAt).Hours()/24)),
+			Details: map[string]interface{}{
+				"provider_type": provider.providerType,
+				"CreatedAt":     createdAt.Format(time.RFC3339),
+				"Age":           utils.FormatTimeDifference(now, &createdAt),
+			},
+			Cost: awslib.NoCost(awslib.NoCostFree),
+		})
+	}
+
+	logging.Debug("Scanned IAM identity providers", map[string]interface{}{
+		"account_id": opts.AccountID,
+		"providers":  len(providers),
+		"unused":     len(results),
+	})
+
+	return results, nil
+}
+
+// providerName returns the name of an identity provider from its ARN, e.g. the issuer host
+// and path of an OIDC provider or the name of a SAML provider
+func providerName(arn string) string {
+	for _, prefix := range []string{":oidc-provider/", ":saml-provider/"} {
+		if i := strings.Index(arn, prefix); i >= 0 {
+			return arn[i+len(prefix):]
+		}
+	}
+	return arn
+}
@@ -0,0 +1,142 @@
+package scanners
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	awslib "cloudsift/internal/aws"
+	"cloudsift/internal/aws/utils"
+	"cloudsift/internal/logging"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/iam"
+)
+
+// IAMInstanceProfileScanner finds instance profiles older than the unused threshold that no
+// EC2 instance in the scanned regions of their partition is launched with
+type IAMInstanceProfileScanner struct{}
+
+func init() {
+	awslib.DefaultRegistry.RegisterScanner(&IAMInstanceProfileScanner{})
+}
+
+// ArgumentName implements Scanner interface
+func (s *IAMInstanceProfileScanner) ArgumentName() string {
+	return "iam-instance-profiles"
+}
+
+// Label implements Scanner interface
+func (s *IAMInstanceProfileScanner) Label() string {
+	return "IAM Instance Profiles"
+}
+
+// RemediationTemplate implements Remediator interface. Instance profiles can only be deleted
+// once their role is removed with remove-role-from-instance-profile.
+func (s *IAMInstanceProfileScanner) RemediationTemplate() string {
+	return "aws iam delete-instance-profile --instance-profile-name {{.ResourceName}}"
+}
+
+// associatedInstanceProfiles returns the ARNs of the instance profiles of the instances that
+// were not terminated in a region
+func (s *IAMInstanceProfileScanner) associatedInstanceProfiles(opts awslib.ScanOptions, region string) (map[string]bool, error) {
+	sess, err := awslib.GetSessionInRegion(opts.Session, region)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create regional session: %w", err)
+	}
+
+	profiles := make(map[string]bool)
+	err = ec2.New(sess).DescribeInstancesPages(&ec2.DescribeInstancesInput{
+		Filters: []*ec2.Filter{{
+			Name:   aws.String("instance-state-name"),
+			Values: aws.StringSlice([]string{"pending", "running", "stopping", "stopped"}),
+		}},
+	}, func(page *ec2.DescribeInstancesOutput, lastPage bool) bool {
+		for _, reservation := range page.Reservations {
+			for _, instance := range reservation.Instances {
+				if instance.IamInstanceProfile != nil {
+					profiles[aws.StringValue(instance.IamInstanceProfile.Arn)] = true
+				}
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe instances: %w", err)
+	}
+	return profiles, nil
+}
+
+// Scan implements Scanner interface
+func (s *IAMInstanceProfileScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, error) {
+	sess, err := awslib.GetSessionInRegion(opts.Session, opts.Region)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create regional session: %w", err)
+	}
+	iamClient := iam.New(sess)
+
+	// Instances may use profiles in any region of the partition, so a region that cannot be
+	// listed fails the scan rather than reporting profiles in use
+	regions := partitionRegions(opts)
+	associated := make(map[string]bool)
+	for _, region := range regions {
+		profiles, err := s.associatedInstanceProfiles(opts, region)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list instance profiles in use in %s: %w", region, err)
+		}
+		for arn := range profiles {
+			associated[arn] = true
+		}
+	}
+
+	now := time.Now().UTC()
+	threshold := now.AddDate(0, 0, -opts.DaysUnused)
+	var results awslib.ScanResults
+	err = iamClient.ListInstanceProfilesPages(&iam.ListInstanceProfilesInput{},
+		func(page *iam.ListInstanceProfilesOutput, lastPage bool) bool {
+			for _, profile := range page.InstanceProfiles {
+				profileARN := aws.StringValue(profile.Arn)
+				createdAt := aws.TimeValue(profile.CreateDate)
+				if associated[profileARN] || createdAt.After(threshold) {
+					continue
+				}
+
+				reasons := []string{fmt.Sprintf("Instance profile created %d days ago is not used by any EC2 instance.",
+					int(now.Sub(createdAt).Hours()/24))}
+				var roleNames []string
+				for _, role := range profile.Roles {
+					roleNames = append(roleNames, aws.StringValue(role.RoleName))
+				}
+				if len(roleNames) == 0 {
+					reasons = append(reasons, "Instance profile has no role.")
+				}
+
+				results = append(results, awslib.ScanResult{
+					ResourceType: s.Label(),
+					ResourceName: aws.StringValue(profile.InstanceProfileName),
+					ResourceID:   profileARN,
+					Reason:       strings.Join(reasons, "\n"),
+					Details: map[string]interface{}{
+						"Roles":     roleNames,
+						"Path":      aws.StringValue(profile.Path),
+						"CreatedAt": createdAt.Format(time.RFC3339),
+						"Age":       utils.FormatTimeDifference(now, profile.CreateDate),
+					},
+					Cost: awslib.NoCost(awslib.NoCostFree),
+				})
+			}
+			return true
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list instance profiles: %w", err)
+	}
+
+	logging.Debug("Scanned IAM instance profiles", map[string]interface{}{
+		"account_id": opts.AccountID,
+		"regions":    regions,
+		"unused":     len(results),
+	})
+
+	return results, nil
+}
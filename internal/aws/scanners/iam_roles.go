@@ -4,15 +4,12 @@ import (
 	"context"
 	"fmt"
 	"strings"
-	"sync"
-	"sync/atomic"
 	"time"
 
 	awslib "cloudsift/internal/aws"
 	"cloudsift/internal/aws/utils"
 	"cloudsift/internal/config"
 	"cloudsift/internal/logging"
-	"cloudsift/internal/worker"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/iam"
@@ -38,24 +35,12 @@ func (t *roleTask) processRole(ctx context.Context) (*awslib.ScanResult, error)
 	roleName := aws.StringValue(t.role.RoleName)
 	roleARN := aws.StringValue(t.role.Arn)
 
-	// Skip reserved roles
-	if t.scanner.isReservedRole(roleARN) {
-		logging.Debug("Skipping reserved role", map[string]interface{}{
-			"role_name": roleName,
-			"role_arn":  roleARN,
-		})
-		return nil, nil
-	}
-
 	logging.Debug("Analyzing IAM role", map[string]interface{}{
 		"role_name": roleName,
 		"role_arn":  roleARN,
 	})
 
-	// Get last used time with rate limiting
-	if err := t.rateLimiter.Wait(ctx); err != nil {
-		return nil, fmt.Errorf("rate limit wait error: %w", err)
-	}
+	// Get last used time, ForEachResourceParallel waited for the rate limiter
 	lastUsedTime, err := t.scanner.getRoleLastUsed(t.iamClient, roleName)
 	if err != nil {
 		// Log error with throttling info
@@ -94,6 +79,25 @@ func (t *roleTask) processRole(ctx context.Context) (*awslib.ScanResult, error)
 	// Determine unused reasons
 	reasons := t.scanner.determineUnusedReasons(lastUsedTime, attachedPolicies, inlinePolicies, instanceProfiles, ageString, t.opts.DaysUnused, t.opts)
 
+	// RoleLastUsed does not cover every service, so confirm the role is unused with access
+	// advisor data before reporting it
+	lastActivitySource := "role_last_used"
+	var lastAccessed *serviceLastAccessed
+	if len(reasons) > 0 && t.opts.IAMLastAccessed {
+		lastAccessed, err = t.getServiceLastAccessed(ctx)
+		if err != nil {
+			logging.Warn("Failed to get service last accessed details", map[string]interface{}{
+				"role_name": roleName,
+				"error":     err.Error(),
+			})
+		} else if lastAccessed.Time != nil && (lastUsedTime == nil || lastAccessed.Time.After(*lastUsedTime)) {
+			lastActivitySource = "access_advisor"
+			lastUsedTime = lastAccessed.Time
+			ageString = utils.FormatTimeDifference(t.now, lastUsedTime)
+			reasons = t.scanner.determineUnusedReasons(lastUsedTime, attachedPolicies, inlinePolicies, instanceProfiles, ageString, t.opts.DaysUnused, t.opts)
+		}
+	}
+
 	if len(reasons) > 0 {
 		// Create details map with IAM-specific fields
 		details := map[string]interface{}{
@@ -114,6 +118,14 @@ func (t *roleTask) processRole(ctx context.Context) (*awslib.ScanResult, error)
 		if t.role.PermissionsBoundary != nil {
 			details["permissions_boundary"] = aws.StringValue(t.role.PermissionsBoundary.PermissionsBoundaryArn)
 		}
+		if lastAccessed != nil {
+			details["last_activity_source"] = lastActivitySource
+			details["services_accessed"] = lastAccessed.ServicesAccessed
+			if lastAccessed.Time != nil {
+				details["service_last_accessed"] = lastAccessed.Time.Format(time.RFC3339)
+				details["service_last_accessed_namespace"] = lastAccessed.Namespace
+			}
+		}
 
 		return &awslib.ScanResult{
 			ResourceType: t.scanner.Label(),
@@ -121,6 +133,7 @@ func (t *roleTask) processRole(ctx context.Context) (*awslib.ScanResult, error)
 			ResourceID:   roleARN,
 			Reason:       strings.Join(reasons, "\n"),
 			Details:      details,
+			Cost:         awslib.NoCost(awslib.NoCostFree),
 		}, nil
 	}
 
@@ -141,9 +154,10 @@ func (s *IAMRoleScanner) Label() string {
 	return "IAM Roles"
 }
 
-// isReservedRole checks if the role is reserved (service or AWS reserved)
-func (s *IAMRoleScanner) isReservedRole(roleARN string) bool {
-	return strings.Contains(roleARN, "aws-reserved")
+// RemediationTemplate implements Remediator interface. Roles can only be deleted once their
+// policies and instance profiles are removed.
+func (s *IAMRoleScanner) RemediationTemplate() string {
+	return "aws iam delete-role --role-name {{.ResourceName}}"
 }
 
 // getRoleLastUsed retrieves the last used time for a role
@@ -165,6 +179,78 @@ func (s *IAMRoleScanner) getRoleLastUsed(iamClient *iam.IAM, roleName string) (*
 	return nil, nil
 }
 
+// serviceLastAccessed summarizes the IAM access advisor data of a role
+type serviceLastAccessed struct {
+	Time             *time.Time // Most recent authentication to any service, nil if never
+	Namespace        string     // Service namespace of the most recent authentication
+	ServicesAccessed int        // Number of services the role authenticated to in the tracking period
+}
+
+// getServiceLastAccessed generates and retrieves the access advisor report for the role
+func (t *roleTask) getServiceLastAccessed(ctx context.Context) (*serviceLastAccessed, error) {
+	if err := t.rateLimiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("rate limit wait error: %w", err)
+	}
+	job, err := t.iamClient.GenerateServiceLastAccessedDetails(&iam.GenerateServiceLastAccessedDetailsInput{
+		Arn: t.role.Arn,
+	})
+	if err != nil {
+		t.rateLimiter.OnFailure()
+		return nil, fmt.Errorf("failed to generate service last accessed details: %w", err)
+	}
+	t.rateLimiter.OnSuccess()
+
+	summary := &serviceLastAccessed{}
+	var marker *string
+	for {
+		if err := t.rateLimiter.Wait(ctx); err != nil {
+			return nil, fmt.Errorf("rate limit wait error: %w", err)
+		}
+		output, err := t.iamClient.GetServiceLastAccessedDetailsWithContext(ctx, &iam.GetServiceLastAccessedDetailsInput{
+			JobId:  job.JobId,
+			Marker: marker,
+		})
+		if err != nil {
+			t.rateLimiter.OnFailure()
+			return nil, fmt.Errorf("failed to get service last accessed details: %w", err)
+		}
+		t.rateLimiter.OnSuccess()
+
+		switch aws.StringValue(output.JobStatus) {
+		case iam.JobStatusTypeInProgress:
+			// The report is generated asynchronously, poll until it completes
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(time.Second):
+			}
+			continue
+		case iam.JobStatusTypeFailed:
+			message := "unknown error"
+			if output.Error != nil {
+				message = aws.StringValue(output.Error.Message)
+			}
+			return nil, fmt.Errorf("service last accessed job failed: %s", message)
+		}
+
+		for _, service := range output.ServicesLastAccessed {
+			if service.LastAuthenticated == nil {
+				continue
+			}
+			summary.ServicesAccessed++
+			if summary.Time == nil || service.LastAuthenticated.After(*summary.Time) {
+				summary.Time = service.LastAuthenticated
+				summary.Namespace = aws.StringValue(service.ServiceNamespace)
+			}
+		}
+
+		if !aws.BoolValue(output.IsTruncated) {
+			return summary, nil
+		}
+		marker = output.Marker
+	}
+}
+
 // getRolePolicies retrieves the attached policies, inline policies, and instance profiles for the role
 func (t *roleTask) getRolePolicies(ctx context.Context) ([]*iam.AttachedPolicy, []string, []*iam.InstanceProfile, error) {
 	var attachedPolicies []*iam.AttachedPolicy
@@ -276,23 +362,20 @@ func (s *IAMRoleScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, erro
 	}
 	rateLimiter := awslib.GetGlobalRegistry().GetRateLimiter(rateLimiterKey, iamConfig)
 
-	// Get the shared worker pool
-	pool := worker.GetSharedPool()
-
-	// Channel to collect results with enough buffer to avoid blocking
-	resultChan := make(chan *awslib.ScanResult, 10000)
-	errorChan := make(chan error, 1)
-
-	// WaitGroup to track all submitted tasks
-	var wg sync.WaitGroup
-	var activeWorkers int32
-
-	// Process roles in chunks to avoid memory issues
-	processRole := func(ctx context.Context, role *iam.Role) error {
-		defer wg.Done()
-		atomic.AddInt32(&activeWorkers, 1)
-		defer atomic.AddInt32(&activeWorkers, -1)
+	// List the roles, then process them in parallel
+	var roles []*iam.Role
+	err = iamClient.ListRolesPages(&iam.ListRolesInput{},
+		func(page *iam.ListRolesOutput, lastPage bool) bool {
+			roles = append(roles, page.Roles...)
+			return !lastPage
+		})
+	if err != nil {
+		logging.Error("Failed to list IAM roles", err, nil)
+		return nil, fmt.Errorf("failed to list IAM roles: %w", err)
+	}
 
+	now := time.Now()
+	return utils.ForEachResourceParallel(roles, rateLimiter, func(ctx context.Context, role *iam.Role) (*awslib.ScanResult, error) {
 		task := &roleTask{
 			role:        role,
 			iamClient:   iamClient,
@@ -300,97 +383,20 @@ func (s *IAMRoleScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, erro
 			region:      opts.Region,
 			scanner:     s,
 			opts:        opts,
-			now:         time.Now(),
+			now:         now,
 			rateLimiter: rateLimiter,
 		}
-
 		result, err := task.processRole(ctx)
-		if err != nil {
-			if strings.Contains(err.Error(), "Throttling:") {
-				// Log throttling events at debug level since they're expected and handled
-				logging.Debug("Rate limited by AWS, backing off", map[string]interface{}{
-					"role_name": aws.StringValue(role.RoleName),
-					"account":   opts.AccountID,
-					"region":    opts.Region,
-					"error":     err.Error(),
-				})
-				rateLimiter.OnFailure()
-				return err
-			}
-			select {
-			case errorChan <- err:
-			default:
-				// If error channel is full, log the error
-				logging.Error("Failed to process role", err, map[string]interface{}{
-					"role_name": aws.StringValue(role.RoleName),
-					"account":   opts.AccountID,
-					"region":    opts.Region,
-				})
-			}
-			return err
-		}
-		rateLimiter.OnSuccess()
-		if result != nil {
-			select {
-			case resultChan <- result:
-			case <-ctx.Done():
-				return ctx.Err()
-			}
-		}
-		return nil
-	}
-
-	// Start listing roles and immediately process each one
-	var results awslib.ScanResults
-	var processingError error
-	done := make(chan bool)
-
-	// Start a goroutine to collect results
-	go func() {
-		for result := range resultChan {
-			results = append(results, *result)
+		if err != nil && utils.IsThrottlingError(err) {
+			// Throttled roles are skipped, the rate limiter backs off for the others
+			logging.Debug("Rate limited by AWS, backing off", map[string]interface{}{
+				"role_name": aws.StringValue(role.RoleName),
+				"account":   opts.AccountID,
+				"region":    opts.Region,
+				"error":     err.Error(),
+			})
+			return nil, nil
 		}
-		done <- true
-	}()
-
-	// List and process roles
-	err = iamClient.ListRolesPages(&iam.ListRolesInput{},
-		func(page *iam.ListRolesOutput, lastPage bool) bool {
-			for _, role := range page.Roles {
-				// Skip if we've encountered an error
-				select {
-				case err := <-errorChan:
-					processingError = err
-					return false
-				default:
-					// Immediately submit each role to the worker pool
-					role := role // Create new variable for closure
-					wg.Add(1)
-					pool.Submit(func(ctx context.Context) error {
-						return processRole(ctx, role)
-					})
-				}
-			}
-			return !lastPage && processingError == nil
-		})
-
-	if err != nil {
-		logging.Error("Failed to list IAM roles", err, nil)
-		return nil, fmt.Errorf("failed to list IAM roles: %w", err)
-	}
-
-	// Wait for all workers to complete
-	wg.Wait()
-
-	// Now it's safe to close the result channel
-	close(resultChan)
-
-	// Wait for result collection to complete
-	<-done
-
-	if processingError != nil {
-		return nil, processingError
-	}
-
-	return results, nil
+		return result, err
+	})
 }
@@ -4,15 +4,12 @@ import (
 	"context"
 	"fmt"
 	"strings"
-	"sync"
-	"sync/atomic"
 	"time"
 
 	awslib "cloudsift/internal/aws"
 	"cloudsift/internal/aws/utils"
 	"cloudsift/internal/config"
 	"cloudsift/internal/logging"
-	"cloudsift/internal/worker"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
@@ -48,6 +45,12 @@ func (s *IAMUserScanner) Label() string {
 	return "IAM Users"
 }
 
+// RemediationTemplate implements Remediator interface. Users can only be deleted once their
+// keys, policies and group memberships are removed.
+func (s *IAMUserScanner) RemediationTemplate() string {
+	return "aws iam delete-user --user-name {{.ResourceName}}"
+}
+
 // processUser processes a single IAM user and returns a scan result if the user is unused
 func (t *userTask) processUser(ctx context.Context) (*awslib.ScanResult, error) {
 	userName := aws.StringValue(t.user.UserName)
@@ -58,10 +61,7 @@ func (t *userTask) processUser(ctx context.Context) (*awslib.ScanResult, error)
 		"user_arn":  userARN,
 	})
 
-	// Get last console login time with rate limiting
-	if err := t.rateLimiter.Wait(ctx); err != nil {
-		return nil, fmt.Errorf("rate limit wait error: %w", err)
-	}
+	// Get last console login time, ForEachResourceParallel waited for the rate limiter
 	lastLoginTime, err := t.scanner.getLastConsoleLogin(t.iamClient, userName)
 	if err != nil {
 		if strings.Contains(err.Error(), "Throttling:") {
@@ -130,6 +130,7 @@ func (t *userTask) processUser(ctx context.Context) (*awslib.ScanResult, error)
 			ResourceID:   userARN,
 			Reason:       strings.Join(reasons, "\n"),
 			Details:      details,
+			Cost:         awslib.NoCost(awslib.NoCostFree),
 		}, nil
 	}
 
@@ -250,23 +251,26 @@ func (s *IAMUserScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, erro
 	}
 	rateLimiter := awslib.GetGlobalRegistry().GetRateLimiter(rateLimiterKey, iamConfig)
 
-	// Get the shared worker pool
-	pool := worker.GetSharedPool()
-
-	// Channel to collect results with enough buffer to avoid blocking
-	resultChan := make(chan *awslib.ScanResult, 10000)
-	errorChan := make(chan error, 1)
-
-	// WaitGroup to track all submitted tasks
-	var wg sync.WaitGroup
-	var activeWorkers int32
-
-	// Process users in chunks to avoid memory issues
-	processUser := func(ctx context.Context, user *iam.User) error {
-		defer wg.Done()
-		atomic.AddInt32(&activeWorkers, 1)
-		defer atomic.AddInt32(&activeWorkers, -1)
+	// List the users older than DaysUnused, then process them in parallel
+	var users []*iam.User
+	err = iamClient.ListUsersPages(&iam.ListUsersInput{},
+		func(page *iam.ListUsersOutput, lastPage bool) bool {
+			for _, user := range page.Users {
+				age := time.Since(aws.TimeValue(user.CreateDate))
+				if age.Hours()/24 <= float64(opts.DaysUnused) {
+					continue
+				}
+				users = append(users, user)
+			}
+			return !lastPage
+		})
+	if err != nil {
+		logging.Error("Failed to list IAM users", err, nil)
+		return nil, fmt.Errorf("failed to list IAM users: %w", err)
+	}
 
+	now := time.Now()
+	return utils.ForEachResourceParallel(users, rateLimiter, func(ctx context.Context, user *iam.User) (*awslib.ScanResult, error) {
 		task := &userTask{
 			user:        user,
 			iamClient:   iamClient,
@@ -274,103 +278,22 @@ func (s *IAMUserScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, erro
 			region:      opts.Region,
 			scanner:     s,
 			opts:        opts,
-			now:         time.Now(),
+			now:         now,
 			rateLimiter: rateLimiter,
 		}
-
 		result, err := task.processUser(ctx)
-		if err != nil {
-			if strings.Contains(err.Error(), "Throttling:") {
-				logging.Debug("Rate limited by AWS, backing off", map[string]interface{}{
-					"user_name": aws.StringValue(user.UserName),
-					"account":   opts.AccountID,
-					"region":    opts.Region,
-					"error":     err.Error(),
-				})
-				rateLimiter.OnFailure()
-				return err
-			}
-			select {
-			case errorChan <- err:
-			default:
-				logging.Error("Failed to process user", err, map[string]interface{}{
-					"user_name": aws.StringValue(user.UserName),
-					"account":   opts.AccountID,
-					"region":    opts.Region,
-				})
-			}
-			return err
-		}
-		rateLimiter.OnSuccess()
-		if result != nil {
-			select {
-			case resultChan <- result:
-			case <-ctx.Done():
-				return ctx.Err()
-			}
-		}
-		return nil
-	}
-
-	// Start listing users and immediately process each one
-	var results awslib.ScanResults
-	var processingError error
-	done := make(chan bool)
-
-	// Start a goroutine to collect results
-	go func() {
-		for result := range resultChan {
-			results = append(results, *result)
+		if err != nil && utils.IsThrottlingError(err) {
+			// Throttled users are skipped, the rate limiter backs off for the others
+			logging.Debug("Rate limited by AWS, backing off", map[string]interface{}{
+				"user_name": aws.StringValue(user.UserName),
+				"account":   opts.AccountID,
+				"region":    opts.Region,
+				"error":     err.Error(),
+			})
+			return nil, nil
 		}
-		done <- true
-	}()
-
-	// List and process users
-	err = iamClient.ListUsersPages(&iam.ListUsersInput{},
-		func(page *iam.ListUsersOutput, lastPage bool) bool {
-			for _, user := range page.Users {
-				// Skip if we've encountered an error
-				select {
-				case err := <-errorChan:
-					processingError = err
-					return false
-				default:
-					// Skip users that are newer than DaysUnused
-					age := time.Since(aws.TimeValue(user.CreateDate))
-					if age.Hours()/24 <= float64(opts.DaysUnused) {
-						continue
-					}
-
-					// Immediately submit each user to the worker pool
-					user := user // Create new variable for closure
-					wg.Add(1)
-					pool.Submit(func(ctx context.Context) error {
-						return processUser(ctx, user)
-					})
-				}
-			}
-			return !lastPage && processingError == nil
-		})
-
-	if err != nil {
-		logging.Error("Failed to list IAM users", err, nil)
-		return nil, fmt.Errorf("failed to list IAM users: %w", err)
-	}
-
-	// Wait for all workers to complete
-	wg.Wait()
-
-	// Now it's safe to close the result channel
-	close(resultChan)
-
-	// Wait for result collection to complete
-	<-done
-
-	if processingError != nil {
-		return nil, processingError
-	}
-
-	return results, nil
+		return result, err
+	})
 }
 
 // formatTimeOrNever formats a time pointer as RFC3339 or returns "Never" if nil
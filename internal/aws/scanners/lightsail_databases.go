@@ -0,0 +1,202 @@
+package scanners
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	awslib "cloudsift/internal/aws"
+	"cloudsift/internal/aws/utils"
+	"cloudsift/internal/logging"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/lightsail"
+)
+
+// LightsailDatabaseScanner scans for Lightsail managed databases without connections
+type LightsailDatabaseScanner struct{}
+
+func init() {
+	awslib.DefaultRegistry.RegisterScanner(&LightsailDatabaseScanner{})
+}
+
+// ArgumentName implements Scanner interface
+func (s *LightsailDatabaseScanner) ArgumentName() string {
+	return "lightsail-databases"
+}
+
+// Label implements Scanner interface
+func (s *LightsailDatabaseScanner) Label() string {
+	return "Lightsail Databases"
+}
+
+// RemediationTemplate implements Remediator interface
+func (s *LightsailDatabaseScanner) RemediationTemplate() string {
+	return "aws lightsail delete-relational-database --relational-database-name {{.ResourceName}} --region {{.Region}}"
+}
+
+// HasResources implements ResourceProber interface
+func (s *LightsailDatabaseScanner) HasResources(opts awslib.ScanOptions) (bool, error) {
+	if !utils.ServiceAvailable(lightsail.EndpointsID, opts.Region) {
+		return false, nil
+	}
+	sess, err := awslib.GetSessionInRegion(opts.Session, opts.Region)
+	if err != nil {
+		return false, fmt.Errorf("failed to create regional session: %w", err)
+	}
+
+	output, err := lightsail.New(sess).GetRelationalDatabases(&lightsail.GetRelationalDatabasesInput{})
+	if err != nil {
+		return false, fmt.Errorf("failed to get Lightsail databases: %w", err)
+	}
+	return len(output.RelationalDatabases) > 0, nil
+}
+
+// databaseBundlePrices returns the monthly price of each Lightsail database bundle
+func (s *LightsailDatabaseScanner) databaseBundlePrices(client *lightsail.Lightsail) (map[string]float64, error) {
+	prices := make(map[string]float64)
+	input := &lightsail.GetRelationalDatabaseBundlesInput{IncludeInactive: aws.Bool(true)}
+	for {
+		output, err := client.GetRelationalDatabaseBundles(input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get Lightsail database bundles: %w", err)
+		}
+		for _, bundle := range output.Bundles {
+			prices[aws.StringValue(bundle.BundleId)] = aws.Float64Value(bundle.Price)
+		}
+		if aws.StringValue(output.NextPageToken) == "" {
+			return prices, nil
+		}
+		input.PageToken = output.NextPageToken
+	}
+}
+
+// maxConnections returns the highest number of database connections in the period
+func (s *LightsailDatabaseScanner) maxConnections(client *lightsail.Lightsail, databaseName string, startTime, endTime time.Time) (float64, error) {
+	ctx, cancel := utils.MetricsContext()
+	defer cancel()
+
+	output, err := client.GetRelationalDatabaseMetricDataWithContext(ctx, &lightsail.GetRelationalDatabaseMetricDataInput{
+		RelationalDatabaseName: aws.String(databaseName),
+		MetricName:             aws.String(lightsail.RelationalDatabaseMetricNameDatabaseConnections),
+		Period:                 aws.Int64(86400), // 1 day
+		StartTime:              aws.Time(startTime),
+		EndTime:                aws.Time(endTime),
+		Unit:                   aws.String(lightsail.MetricUnitCount),
+		Statistics:             aws.StringSlice([]string{lightsail.MetricStatisticMaximum}),
+	}, utils.MetricsRetries)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get DatabaseConnections metric data: %w", utils.MetricsError(ctx, err))
+	}
+
+	var max float64
+	for _, datapoint := range output.MetricData {
+		if aws.Float64Value(datapoint.Maximum) > max {
+			max = aws.Float64Value(datapoint.Maximum)
+		}
+	}
+	return max, nil
+}
+
+// Scan implements Scanner interface
+func (s *LightsailDatabaseScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, error) {
+	if !utils.ServiceAvailable(lightsail.EndpointsID, opts.Region) {
+		return nil, nil
+	}
+
+	sess, err := awslib.GetSessionInRegion(opts.Session, opts.Region)
+	if err != nil {
+		logging.Error("Failed to create regional session", err, map[string]interface{}{
+			"region": opts.Region,
+		})
+		return nil, fmt.Errorf("failed to create regional session: %w", err)
+	}
+	client := lightsail.New(sess)
+
+	var databases []*lightsail.RelationalDatabase
+	input := &lightsail.GetRelationalDatabasesInput{}
+	for {
+		output, err := client.GetRelationalDatabases(input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get Lightsail databases: %w", err)
+		}
+		databases = append(databases, output.RelationalDatabases...)
+		if aws.StringValue(output.NextPageToken) == "" {
+			break
+		}
+		input.PageToken = output.NextPageToken
+	}
+	if len(databases) == 0 {
+		return nil, nil
+	}
+
+	prices, err := s.databaseBundlePrices(client)
+	if err != nil {
+		logging.Warn("Failed to get Lightsail database bundle prices", map[string]interface{}{
+			"region": opts.Region,
+			"error":  err.Error(),
+		})
+	}
+
+	endTime := time.Now().UTC()
+	startTime := endTime.Add(-time.Duration(opts.DaysUnused) * 24 * time.Hour)
+
+	var results awslib.ScanResults
+	for _, database := range databases {
+		name := aws.StringValue(database.Name)
+		createdAt := aws.TimeValue(database.CreatedAt)
+		if createdAt.After(startTime) {
+			continue
+		}
+
+		state := aws.StringValue(database.State)
+		details := map[string]interface{}{
+			"account_id":     opts.AccountID,
+			"region":         opts.Region,
+			"state":          state,
+			"bundle_id":      aws.StringValue(database.RelationalDatabaseBundleId),
+			"engine":         aws.StringValue(database.Engine),
+			"engine_version": aws.StringValue(database.EngineVersion),
+			"created_at":     createdAt.Format(time.RFC3339),
+		}
+
+		var reasons []string
+		if state == "stopped" {
+			reasons = append(reasons, "Lightsail database is stopped but its bundle is still billed.")
+		} else {
+			connections, err := s.maxConnections(client, name, startTime, endTime)
+			switch {
+			case err != nil:
+				logging.Error("Failed to get Lightsail database metrics", err, map[string]interface{}{
+					"database_name": name,
+				})
+				if !utils.MetricsDegraded(err) {
+					continue
+				}
+				utils.MarkMetricsDegraded(details, err)
+				reasons = append(reasons, utils.MetricsUnavailableReason)
+			case connections == 0:
+				details["max_connections"] = connections
+				reasons = append(reasons, fmt.Sprintf("No database connections in the last %d days.", opts.DaysUnused))
+			}
+		}
+		if len(reasons) == 0 {
+			continue
+		}
+
+		monthlyPrice := prices[aws.StringValue(database.RelationalDatabaseBundleId)]
+		details["monthly_price"] = monthlyPrice
+
+		results = append(results, awslib.ScanResult{
+			ResourceType: s.Label(),
+			ResourceName: name,
+			ResourceID:   aws.StringValue(database.Arn),
+			Reason:       strings.Join(reasons, "\n"),
+			Details:      details,
+			Tags:         lightsailTags(database.Tags),
+			Cost:         lightsailCost(monthlyPrice, opts.Region, createdAt),
+		})
+	}
+
+	return results, nil
+}
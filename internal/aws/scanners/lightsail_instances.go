@@ -0,0 +1,256 @@
+package scanners
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	awslib "cloudsift/internal/aws"
+	"cloudsift/internal/aws/utils"
+	"cloudsift/internal/logging"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/lightsail"
+)
+
+// lightsailMinTrafficBytes is the network traffic below which a Lightsail instance is
+// considered unused (1 MB over the whole period)
+const lightsailMinTrafficBytes = 1024 * 1024
+
+// LightsailInstanceScanner scans for Lightsail instances without network traffic. Lightsail
+// instances are billed a fixed monthly price, including while they are stopped.
+type LightsailInstanceScanner struct{}
+
+func init() {
+	awslib.DefaultRegistry.RegisterScanner(&LightsailInstanceScanner{})
+}
+
+// ArgumentName implements Scanner interface
+func (s *LightsailInstanceScanner) ArgumentName() string {
+	return "lightsail-instances"
+}
+
+// Label implements Scanner interface
+func (s *LightsailInstanceScanner) Label() string {
+	return "Lightsail Instances"
+}
+
+// RemediationTemplate implements Remediator interface
+func (s *LightsailInstanceScanner) RemediationTemplate() string {
+	return "aws lightsail stop-instance --instance-name {{.ResourceName}} --region {{.Region}}"
+}
+
+// HasResources implements ResourceProber interface
+func (s *LightsailInstanceScanner) HasResources(opts awslib.ScanOptions) (bool, error) {
+	if !utils.ServiceAvailable(lightsail.EndpointsID, opts.Region) {
+		return false, nil
+	}
+	sess, err := awslib.GetSessionInRegion(opts.Session, opts.Region)
+	if err != nil {
+		return false, fmt.Errorf("failed to create regional session: %w", err)
+	}
+
+	output, err := lightsail.New(sess).GetInstances(&lightsail.GetInstancesInput{})
+	if err != nil {
+		return false, fmt.Errorf("failed to get Lightsail instances: %w", err)
+	}
+	return len(output.Instances) > 0, nil
+}
+
+// lightsailBundlePrices returns the monthly price of each Lightsail instance bundle
+func lightsailBundlePrices(client *lightsail.Lightsail) (map[string]float64, error) {
+	prices := make(map[string]float64)
+	input := &lightsail.GetBundlesInput{IncludeInactive: aws.Bool(true)}
+	for {
+		output, err := client.GetBundles(input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get Lightsail bundles: %w", err)
+		}
+		for _, bundle := range output.Bundles {
+			prices[aws.StringValue(bundle.BundleId)] = aws.Float64Value(bundle.Price)
+		}
+		if aws.StringValue(output.NextPageToken) == "" {
+			return prices, nil
+		}
+		input.PageToken = output.NextPageToken
+	}
+}
+
+// lightsailCost calculates the cost of a Lightsail resource from its monthly bundle price
+func lightsailCost(monthlyPrice float64, region string, createdAt time.Time) map[string]interface{} {
+	if awslib.DefaultCostEstimator == nil || monthlyPrice == 0 {
+		return nil
+	}
+	costs, err := awslib.DefaultCostEstimator.CalculateCost(awslib.ResourceCostConfig{
+		ResourceType: "Lightsail",
+		ResourceSize: monthlyPrice,
+		Region:       region,
+		CreationTime: createdAt,
+	})
+	if err != nil {
+		logging.Error("Failed to calculate Lightsail costs", err, map[string]interface{}{
+			"region": region,
+		})
+		return nil
+	}
+	return map[string]interface{}{
+		"total": costs,
+	}
+}
+
+// lightsailTags converts Lightsail tags to a map
+func lightsailTags(tags []*lightsail.Tag) map[string]string {
+	result := make(map[string]string, len(tags))
+	for _, tag := range tags {
+		result[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+	}
+	return result
+}
+
+// networkTraffic returns the total bytes of a Lightsail instance network metric
+func (s *LightsailInstanceScanner) networkTraffic(client *lightsail.Lightsail, instanceName, metricName string, startTime, endTime time.Time) (float64, error) {
+	ctx, cancel := utils.MetricsContext()
+	defer cancel()
+
+	output, err := client.GetInstanceMetricDataWithContext(ctx, &lightsail.GetInstanceMetricDataInput{
+		InstanceName: aws.String(instanceName),
+		MetricName:   aws.String(metricName),
+		Period:       aws.Int64(86400), // 1 day
+		StartTime:    aws.Time(startTime),
+		EndTime:      aws.Time(endTime),
+		Unit:         aws.String(lightsail.MetricUnitBytes),
+		Statistics:   aws.StringSlice([]string{lightsail.MetricStatisticSum}),
+	}, utils.MetricsRetries)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get %s metric data: %w", metricName, utils.MetricsError(ctx, err))
+	}
+
+	var total float64
+	for _, datapoint := range output.MetricData {
+		total += aws.Float64Value(datapoint.Sum)
+	}
+	return total, nil
+}
+
+// Scan implements Scanner interface
+func (s *LightsailInstanceScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, error) {
+	if !utils.ServiceAvailable(lightsail.EndpointsID, opts.Region) {
+		return nil, nil
+	}
+
+	sess, err := awslib.GetSessionInRegion(opts.Session, opts.Region)
+	if err != nil {
+		logging.Error("Failed to create regional session", err, map[string]interface{}{
+			"region": opts.Region,
+		})
+		return nil, fmt.Errorf("failed to create regional session: %w", err)
+	}
+	client := lightsail.New(sess)
+
+	var instances []*lightsail.Instance
+	input := &lightsail.GetInstancesInput{}
+	for {
+		output, err := client.GetInstances(input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get Lightsail instances: %w", err)
+		}
+		instances = append(instances, output.Instances...)
+		if aws.StringValue(output.NextPageToken) == "" {
+			break
+		}
+		input.PageToken = output.NextPageToken
+	}
+	if len(instances) == 0 {
+		return nil, nil
+	}
+
+	prices, err := lightsailBundlePrices(client)
+	if err != nil {
+		logging.Warn("Failed to get Lightsail bundle prices", map[string]interface{}{
+			"region": opts.Region,
+			"error":  err.Error(),
+		})
+	}
+
+	endTime := time.Now().UTC()
+	startTime := endTime.Add(-time.Duration(opts.DaysUnused) * 24 * time.Hour)
+
+	var results awslib.ScanResults
+	for _, instance := range instances {
+		name := aws.StringValue(instance.Name)
+		createdAt := aws.TimeValue(instance.CreatedAt)
+		if createdAt.After(startTime) {
+			continue
+		}
+
+		state := ""
+		if instance.State != nil {
+			state = aws.StringValue(instance.State.Name)
+		}
+
+		details := map[string]interface{}{
+			"account_id":   opts.AccountID,
+			"region":       opts.Region,
+			"state":        state,
+			"bundle_id":    aws.StringValue(instance.BundleId),
+			"blueprint_id": aws.StringValue(instance.BlueprintId),
+			"created_at":   createdAt.Format(time.RFC3339),
+			"public_ip":    aws.StringValue(instance.PublicIpAddress),
+			"static_ip":    aws.BoolValue(instance.IsStaticIp),
+		}
+
+		var reasons []string
+		if state == "stopped" {
+			reasons = append(reasons, "Lightsail instance is stopped but its bundle is still billed.")
+		} else {
+			var traffic float64
+			var metricsErr error
+			for _, metricName := range []string{lightsail.InstanceMetricNameNetworkIn, lightsail.InstanceMetricNameNetworkOut} {
+				bytes, err := s.networkTraffic(client, name, metricName, startTime, endTime)
+				if err != nil {
+					metricsErr = err
+					break
+				}
+				traffic += bytes
+			}
+
+			if metricsErr == nil {
+				details["network_bytes"] = traffic
+			}
+
+			switch {
+			case metricsErr != nil:
+				logging.Error("Failed to get Lightsail instance metrics", metricsErr, map[string]interface{}{
+					"instance_name": name,
+				})
+				if !utils.MetricsDegraded(metricsErr) {
+					continue
+				}
+				utils.MarkMetricsDegraded(details, metricsErr)
+				reasons = append(reasons, utils.MetricsUnavailableReason)
+			case traffic == 0:
+				reasons = append(reasons, fmt.Sprintf("No network traffic in the last %d days.", opts.DaysUnused))
+			case traffic < lightsailMinTrafficBytes:
+				reasons = append(reasons, fmt.Sprintf("Very low network traffic (%.2f MB) in the last %d days.", traffic/(1024*1024), opts.DaysUnused))
+			}
+		}
+		if len(reasons) == 0 {
+			continue
+		}
+
+		monthlyPrice := prices[aws.StringValue(instance.BundleId)]
+		details["monthly_price"] = monthlyPrice
+
+		results = append(results, awslib.ScanResult{
+			ResourceType: s.Label(),
+			ResourceName: name,
+			ResourceID:   aws.StringValue(instance.Arn),
+			Reason:       strings.Join(reasons, "\n"),
+			Details:      details,
+			Tags:         lightsailTags(instance.Tags),
+			Cost:         lightsailCost(monthlyPrice, opts.Region, createdAt),
+		})
+	}
+
+	return results, nil
+}
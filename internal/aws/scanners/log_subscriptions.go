@@ -0,0 +1,316 @@
+package scanners
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	awslib "cloudsift/internal/aws"
+	"cloudsift/internal/aws/utils"
+	"cloudsift/internal/logging"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/service/firehose"
+	"github.com/aws/aws-sdk-go/service/kinesis"
+	"github.com/aws/aws-sdk-go/service/lambda"
+)
+
+// List prices in USD of the processing subscription filters incur at their destination
+const (
+	firehoseIngestionPrice = 0.029      // Per GB ingested by a Firehose delivery stream
+	lambdaRequestPrice     = 0.20 / 1e6 // Per Lambda invocation
+)
+
+// Destination types of subscription filters, reported in the destination_type detail
+const (
+	destinationLambda   = "lambda"
+	destinationFirehose = "firehose"
+	destinationKinesis  = "kinesis"
+)
+
+// subscriptionDestination is the state of the destination of subscription filters
+type subscriptionDestination struct {
+	destinationType string
+	reason          string  // Why the destination delivers nothing, or "" if it works
+	monthlyPrice    float64 // Monthly processing price per GB delivered (Firehose) or per month (Lambda)
+	perGB           bool    // Whether monthlyPrice is per GB of log events delivered
+}
+
+// LogSubscriptionsScanner scans for CloudWatch Logs subscription filters that incur processing
+// charges but deliver nothing: filters whose destination was deleted, is not active or fails
+// every delivery, and filters duplicating another filter of the same log group
+type LogSubscriptionsScanner struct{}
+
+func init() {
+	awslib.DefaultRegistry.RegisterScanner(&LogSubscriptionsScanner{})
+}
+
+// ArgumentName implements Scanner interface
+func (s *LogSubscriptionsScanner) ArgumentName() string {
+	return "log-subscriptions"
+}
+
+// Label implements Scanner interface
+func (s *LogSubscriptionsScanner) Label() string {
+	return "Log Subscription Filters"
+}
+
+// RemediationTemplate implements Remediator interface
+func (s *LogSubscriptionsScanner) RemediationTemplate() string {
+	return `aws logs delete-subscription-filter --log-group-name {{index .Details "log_group"}} --filter-name {{.ResourceName}} --region {{.Region}}`
+}
+
+// isResourceNotFound returns whether an error reports a deleted resource
+func isResourceNotFound(err error) bool {
+	aerr, ok := err.(awserr.Error)
+	return ok && aerr.Code() == "ResourceNotFoundException"
+}
+
+// Scan implements Scanner interface
+func (s *LogSubscriptionsScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, error) {
+	sess, err := awslib.GetSessionInRegion(opts.Session, opts.Region)
+	if err != nil {
+		logging.Error("Failed to create regional session", err, map[string]interface{}{
+			"region": opts.Region,
+		})
+		return nil, fmt.Errorf("failed to create regional session: %w", err)
+	}
+	logsClient := cloudwatchlogs.New(sess)
+	cwClient := cloudwatch.New(sess)
+	endTime := time.Now().UTC()
+	startTime := endTime.Add(-time.Duration(opts.DaysUnused) * 24 * time.Hour)
+
+	logging.Debug("Starting log subscription filter scan", map[string]interface{}{
+		"account_id": opts.AccountID,
+		"region":     opts.Region,
+	})
+
+	var logGroups []*cloudwatchlogs.LogGroup
+	err = logsClient.DescribeLogGroupsPages(&cloudwatchlogs.DescribeLogGroupsInput{}, func(page *cloudwatchlogs.DescribeLogGroupsOutput, lastPage bool) bool {
+		logGroups = append(logGroups, page.LogGroups...)
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe log groups: %w", err)
+	}
+
+	// Log groups often share a destination, so each destination is checked once
+	destinations := make(map[string]*subscriptionDestination)
+	var results awslib.ScanResults
+	for _, logGroup := range logGroups {
+		logGroupName := aws.StringValue(logGroup.LogGroupName)
+		output, err := logsClient.DescribeSubscriptionFilters(&cloudwatchlogs.DescribeSubscriptionFiltersInput{
+			LogGroupName: aws.String(logGroupName),
+		})
+		if err != nil {
+			logging.Error("Failed to describe subscription filters", err, map[string]interface{}{
+				"log_group": logGroupName,
+			})
+			continue
+		}
+		if len(output.SubscriptionFilters) == 0 {
+			continue
+		}
+
+		// Log events delivered by each filter of the log group
+		var monthlyGB float64
+		dailyBytes, err := utils.GetResourceMetrics(cwClient, utils.MetricConfig{
+			Namespace:     "AWS/Logs",
+			ResourceID:    logGroupName,
+			DimensionName: "LogGroupName",
+			MetricName:    "IncomingBytes",
+			Statistic:     "Sum",
+			StartTime:     startTime,
+			EndTime:       endTime,
+			Period:        86400,
+		})
+		if err != nil {
+			logging.Debug("Failed to get log group incoming bytes", map[string]interface{}{
+				"log_group": logGroupName,
+				"error":     err.Error(),
+			})
+		} else {
+			monthlyGB = dailyBytes * 30 / (1024 * 1024 * 1024)
+		}
+
+		// Filters are compared oldest first, so the newer of two identical filters is the duplicate
+		filters := output.SubscriptionFilters
+		sort.Slice(filters, func(i, j int) bool {
+			return aws.Int64Value(filters[i].CreationTime) < aws.Int64Value(filters[j].CreationTime)
+		})
+		firstFilter := make(map[string]string) // Destination and pattern to the name of the first filter
+		for _, filter := range filters {
+			filterName := aws.StringValue(filter.FilterName)
+			destinationArn := aws.StringValue(filter.DestinationArn)
+
+			destination, ok := destinations[destinationArn]
+			if !ok {
+				destination, err = s.checkDestination(opts, sess, cwClient, destinationArn, startTime, endTime)
+				if err != nil {
+					logging.Error("Failed to check subscription filter destination", err, map[string]interface{}{
+						"log_group":       logGroupName,
+						"filter_name":     filterName,
+						"destination_arn": destinationArn,
+					})
+					continue
+				}
+				destinations[destinationArn] = destination
+			}
+
+			var reasons []string
+			if destination != nil && destination.reason != "" {
+				reasons = append(reasons, destination.reason)
+			}
+			key := destinationArn + "\x00" + aws.StringValue(filter.FilterPattern)
+			if first, ok := firstFilter[key]; ok {
+				reasons = append(reasons, fmt.Sprintf("Subscription filter duplicates %s with the same pattern and destination.", first))
+			} else {
+				firstFilter[key] = filterName
+			}
+			if len(reasons) == 0 {
+				continue
+			}
+
+			createdAt := time.UnixMilli(aws.Int64Value(filter.CreationTime)).UTC()
+			details := map[string]interface{}{
+				"account_id":      opts.AccountID,
+				"region":          opts.Region,
+				"log_group":       logGroupName,
+				"filter_pattern":  aws.StringValue(filter.FilterPattern),
+				"destination_arn": destinationArn,
+				"distribution":    aws.StringValue(filter.Distribution),
+				"created_at":      createdAt.Format(time.RFC3339),
+			}
+			cost := awslib.NoCost(awslib.NoCostNotEstimated)
+			if destination != nil {
+				details["destination_type"] = destination.destinationType
+				monthlyPrice := destination.monthlyPrice
+				if destination.perGB {
+					details["monthly_gb"] = monthlyGB
+					monthlyPrice *= monthlyGB
+				}
+				if estimated := monitoringCost(monthlyPrice, opts.Region, createdAt); estimated != nil {
+					cost = estimated
+				}
+			}
+
+			results = append(results, awslib.ScanResult{
+				ResourceType: s.Label(),
+				ResourceName: filterName,
+				ResourceID:   logGroupName + ":" + filterName,
+				Reason:       strings.Join(reasons, "\n"),
+				Details:      details,
+				Cost:         cost,
+			})
+		}
+	}
+
+	logging.Debug("Log subscription filter scan completed", map[string]interface{}{
+		"account_id": opts.AccountID,
+		"region":     opts.Region,
+		"log_groups": len(logGroups),
+		"findings":   len(results),
+	})
+
+	return results, nil
+}
+
+// checkDestination checks the Lambda function, Firehose delivery stream or Kinesis stream a
+// subscription filter delivers to. It returns nil for destinations it cannot check, such as
+// CloudWatch Logs destinations and resources of other accounts or regions.
+func (s *LogSubscriptionsScanner) checkDestination(opts awslib.ScanOptions, sess *session.Session, cwClient *cloudwatch.CloudWatch, destinationArn string, startTime, endTime time.Time) (*subscriptionDestination, error) {
+	parsed, err := arn.Parse(destinationArn)
+	if err != nil || parsed.AccountID != opts.AccountID || parsed.Region != opts.Region {
+		return nil, nil
+	}
+
+	switch parsed.Service {
+	case "lambda":
+		name := strings.TrimPrefix(parsed.Resource, "function:")
+		if _, err := lambda.New(sess).GetFunction(&lambda.GetFunctionInput{FunctionName: aws.String(name)}); err != nil {
+			if isResourceNotFound(err) {
+				return &subscriptionDestination{destinationType: destinationLambda, reason: "Subscription filter delivers to a deleted Lambda function."}, nil
+			}
+			return nil, fmt.Errorf("failed to get function: %w", err)
+		}
+
+		metrics, err := utils.GetResourceMetricsData(cwClient, []utils.MetricConfig{
+			{Namespace: "AWS/Lambda", ResourceID: name, DimensionName: "FunctionName", MetricName: "Invocations", Statistic: "Sum", StartTime: startTime, EndTime: endTime},
+			{Namespace: "AWS/Lambda", ResourceID: name, DimensionName: "FunctionName", MetricName: "Errors", Statistic: "Sum", StartTime: startTime, EndTime: endTime},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get function metrics: %w", err)
+		}
+		// Metric data holds the average hourly sums
+		invocations, failed := metrics["Invocations"], metrics["Errors"]
+		if invocations == 0 || failed < invocations {
+			return &subscriptionDestination{destinationType: destinationLambda}, nil
+		}
+		return &subscriptionDestination{
+			destinationType: destinationLambda,
+			reason:          fmt.Sprintf("All invocations of the Lambda function the subscription filter delivers to failed in the last %d days.", opts.DaysUnused),
+			monthlyPrice:    invocations * 730 * lambdaRequestPrice,
+		}, nil
+
+	case "firehose":
+		name := strings.TrimPrefix(parsed.Resource, "deliverystream/")
+		output, err := firehose.New(sess).DescribeDeliveryStream(&firehose.DescribeDeliveryStreamInput{DeliveryStreamName: aws.String(name)})
+		if err != nil {
+			if isResourceNotFound(err) {
+				return &subscriptionDestination{destinationType: destinationFirehose, reason: "Subscription filter delivers to a deleted Firehose delivery stream."}, nil
+			}
+			return nil, fmt.Errorf("failed to describe delivery stream: %w", err)
+		}
+		destination := &subscriptionDestination{destinationType: destinationFirehose, monthlyPrice: firehoseIngestionPrice, perGB: true}
+		status := aws.StringValue(output.DeliveryStreamDescription.DeliveryStreamStatus)
+		if status != firehose.DeliveryStreamStatusActive && status != firehose.DeliveryStreamStatusCreating {
+			destination.reason = fmt.Sprintf("Subscription filter delivers to a Firehose delivery stream that is %s.", strings.ToLower(strings.ReplaceAll(status, "_", " ")))
+			return destination, nil
+		}
+
+		// Only delivery to S3 is checked; other destinations report their own metrics
+		toS3 := false
+		for _, d := range output.DeliveryStreamDescription.Destinations {
+			if d.ExtendedS3DestinationDescription != nil {
+				toS3 = true
+			}
+		}
+		if !toS3 {
+			return destination, nil
+		}
+		metrics, err := utils.GetResourceMetricsData(cwClient, []utils.MetricConfig{
+			{Namespace: "AWS/Firehose", ResourceID: name, DimensionName: "DeliveryStreamName", MetricName: "IncomingRecords", Statistic: "Sum", StartTime: startTime, EndTime: endTime},
+			{Namespace: "AWS/Firehose", ResourceID: name, DimensionName: "DeliveryStreamName", MetricName: "DeliveryToS3.Records", Statistic: "Sum", StartTime: startTime, EndTime: endTime},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get delivery stream metrics: %w", err)
+		}
+		if metrics["IncomingRecords"] > 0 && metrics["DeliveryToS3.Records"] == 0 {
+			destination.reason = fmt.Sprintf("The Firehose delivery stream the subscription filter delivers to delivered no records to S3 in the last %d days.", opts.DaysUnused)
+		}
+		return destination, nil
+
+	case "kinesis":
+		name := strings.TrimPrefix(parsed.Resource, "stream/")
+		output, err := kinesis.New(sess).DescribeStreamSummary(&kinesis.DescribeStreamSummaryInput{StreamName: aws.String(name)})
+		if err != nil {
+			if isResourceNotFound(err) {
+				return &subscriptionDestination{destinationType: destinationKinesis, reason: "Subscription filter delivers to a deleted Kinesis stream."}, nil
+			}
+			return nil, fmt.Errorf("failed to describe stream: %w", err)
+		}
+		destination := &subscriptionDestination{destinationType: destinationKinesis}
+		status := aws.StringValue(output.StreamDescriptionSummary.StreamStatus)
+		if status == kinesis.StreamStatusDeleting {
+			destination.reason = "Subscription filter delivers to a deleted Kinesis stream."
+		}
+		return destination, nil
+	}
+	return nil, nil
+}
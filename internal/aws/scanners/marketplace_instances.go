@@ -0,0 +1,281 @@
+package scanners
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	awslib "cloudsift/internal/aws"
+	"cloudsift/internal/aws/utils"
+	"cloudsift/internal/logging"
+	"cloudsift/internal/worker"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// MarketplaceInstanceScanner scans for idle EC2 instances launched from AWS Marketplace AMIs.
+// These instances carry hourly software charges billed by the Marketplace seller on top of
+// the EC2 infrastructure cost. The EC2 instance scanner does not report the running ones when
+// this scanner runs, so they are not counted twice.
+type MarketplaceInstanceScanner struct {
+	ec2Scanner EC2InstanceScanner
+}
+
+func init() {
+	awslib.DefaultRegistry.RegisterScanner(&MarketplaceInstanceScanner{})
+}
+
+// ArgumentName implements Scanner interface
+func (s *MarketplaceInstanceScanner) ArgumentName() string {
+	return "marketplace-instances"
+}
+
+// Label implements Scanner interface
+func (s *MarketplaceInstanceScanner) Label() string {
+	return "Marketplace Instances"
+}
+
+// RemediationTemplate implements Remediator interface. Instances are stopped rather than
+// terminated, keeping their volumes.
+func (s *MarketplaceInstanceScanner) RemediationTemplate() string {
+	return "aws ec2 stop-instances --instance-ids {{.ResourceID}} --region {{.Region}}"
+}
+
+// HasResources implements ResourceProber interface
+func (s *MarketplaceInstanceScanner) HasResources(opts awslib.ScanOptions) (bool, error) {
+	sess, err := awslib.GetSessionInRegion(opts.Session, opts.Region)
+	if err != nil {
+		return false, fmt.Errorf("failed to create regional session: %w", err)
+	}
+
+	// Pages of filtered results may be empty while later pages are not
+	found := false
+	err = ec2.New(sess).DescribeInstancesPages(&ec2.DescribeInstancesInput{
+		Filters: []*ec2.Filter{{
+			Name:   aws.String("product-code.type"),
+			Values: []*string{aws.String(ec2.ProductCodeValuesMarketplace)},
+		}},
+		MaxResults: aws.Int64(5),
+	}, func(page *ec2.DescribeInstancesOutput, lastPage bool) bool {
+		found = len(page.Reservations) > 0
+		return !found
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to describe instances: %w", err)
+	}
+	return found, nil
+}
+
+// reportsMarketplaceInstance returns whether the scanner reports an instance if it is idle,
+// so the EC2 instance scanner leaves it to it
+func reportsMarketplaceInstance(instance *ec2.Instance) bool {
+	return aws.StringValue(instance.State.Name) == ec2.InstanceStateNameRunning && len(marketplaceProductCodes(instance)) > 0
+}
+
+// marketplaceProductCodes returns the Marketplace product codes of an instance
+func marketplaceProductCodes(instance *ec2.Instance) []string {
+	var codes []string
+	for _, productCode := range instance.ProductCodes {
+		if aws.StringValue(productCode.ProductCodeType) == ec2.ProductCodeValuesMarketplace {
+			codes = append(codes, aws.StringValue(productCode.ProductCodeId))
+		}
+	}
+	return codes
+}
+
+// softwareHourlyRate returns the configured Marketplace software rate for the product codes
+// and whether a rate was configured for any of them
+func softwareHourlyRate(productCodes []string, rates map[string]float64) (float64, bool) {
+	var total float64
+	var found bool
+	for _, code := range productCodes {
+		for configured, rate := range rates {
+			if strings.EqualFold(configured, code) {
+				total += rate
+				found = true
+				break
+			}
+		}
+	}
+	return total, found
+}
+
+// Scan implements Scanner interface
+func (s *MarketplaceInstanceScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, error) {
+	sess, err := awslib.GetSessionInRegion(opts.Session, opts.Region)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create regional session: %w", err)
+	}
+
+	logging.Info("Starting Marketplace instance scan", map[string]interface{}{
+		"account_id": opts.AccountID,
+		"region":     opts.Region,
+	})
+
+	clients := utils.CreateServiceClients(sess)
+	ec2Client := ec2.New(sess)
+
+	var results awslib.ScanResults
+	var resultsMutex sync.Mutex
+	var tasks []worker.Task
+	endTime := time.Now().UTC()
+	metricStartTime := endTime.Add(-time.Duration(opts.DaysUnused) * 24 * time.Hour)
+
+	// Marketplace software is only billed while the instance is running
+	input := &ec2.DescribeInstancesInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("product-code.type"),
+				Values: []*string{aws.String(ec2.ProductCodeValuesMarketplace)},
+			},
+			{
+				Name:   aws.String("instance-state-name"),
+				Values: []*string{aws.String(ec2.InstanceStateNameRunning)},
+			},
+		},
+		MaxResults: aws.Int64(1000),
+	}
+
+	err = ec2Client.DescribeInstancesPages(input, func(page *ec2.DescribeInstancesOutput, lastPage bool) bool {
+		for _, reservation := range page.Reservations {
+			for _, instance := range reservation.Instances {
+				instance := instance
+				if !reportsMarketplaceInstance(instance) {
+					continue
+				}
+				productCodes := marketplaceProductCodes(instance)
+
+				tasks = append(tasks, func(ctx context.Context) error {
+					instanceID := aws.StringValue(instance.InstanceId)
+
+					// Skip elastic workloads that scale by design when requested
+					if reason := s.ec2Scanner.excludedReason(instance, opts); reason != "" {
+						logging.Debug("Skipping excluded instance", map[string]interface{}{
+							"instance_id": instanceID,
+							"reason":      reason,
+						})
+						return nil
+					}
+
+					// Only analyze instances that are old enough based on days_unused
+					instanceAge := time.Since(*instance.LaunchTime)
+					if instanceAge.Hours()/24 < float64(opts.DaysUnused) {
+						return nil
+					}
+
+					recorder := utils.NewMetricRecorder(opts.IncludeMetricSamples)
+					reasons, err := s.ec2Scanner.analyzeInstanceUsage(clients.CloudWatch, instance, metricStartTime, endTime, opts.DaysUnused, recorder)
+					if err != nil {
+						logging.Error("Failed to analyze instance usage", err, map[string]interface{}{
+							"instance_id": instanceID,
+						})
+						return nil
+					}
+					if len(reasons) == 0 {
+						return nil
+					}
+
+					name := instanceID
+					tags := make(map[string]string)
+					for _, tag := range instance.Tags {
+						tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+						if aws.StringValue(tag.Key) == "Name" {
+							name = aws.StringValue(tag.Value)
+						}
+					}
+
+					softwareRate, softwareRateKnown := softwareHourlyRate(productCodes, opts.MarketplaceRates)
+					if softwareRateKnown {
+						reasons = append(reasons, fmt.Sprintf("Idle instance incurs Marketplace software charges of $%.4f/hour on top of EC2 costs.", softwareRate))
+					} else {
+						reasons = append(reasons, "Idle instance incurs Marketplace software charges on top of EC2 costs (configure scan.marketplace_rates to estimate them).")
+					}
+
+					hoursRunning := instanceAge.Hours()
+					details := map[string]interface{}{
+						"instance_id":      instanceID,
+						"instance_type":    aws.StringValue(instance.InstanceType),
+						"ami_id":           aws.StringValue(instance.ImageId),
+						"product_codes":    productCodes,
+						"platform_details": aws.StringValue(instance.PlatformDetails),
+						"usage_operation":  aws.StringValue(instance.UsageOperation),
+						"launch_time":      instance.LaunchTime.Format(time.RFC3339),
+						"hours_running":    hoursRunning,
+						"state":            aws.StringValue(instance.State.Name),
+						"region":           opts.Region,
+						"tags":             tags,
+					}
+					if createdAt, ok := instanceCreatedAt(instance); ok {
+						details["created_at"] = createdAt.Format(time.RFC3339)
+					}
+					if softwareRateKnown {
+						details["software_hourly_rate"] = softwareRate
+					}
+					recorder.AddTo(details)
+
+					var costDetails map[string]interface{}
+					if awslib.DefaultCostEstimator != nil {
+						costs, err := awslib.DefaultCostEstimator.CalculateCost(awslib.ResourceCostConfig{
+							ResourceType: "EC2",
+							ResourceSize: aws.StringValue(instance.InstanceType),
+							Region:       opts.Region,
+							CreationTime: *instance.LaunchTime,
+						})
+						if err != nil {
+							logging.Error("Failed to calculate EC2 instance costs", err, map[string]interface{}{
+								"instance_id": instanceID,
+							})
+						} else if costs != nil {
+							costs.HourlyRate = roundCost(costs.HourlyRate + softwareRate)
+							costs.DailyRate = roundCost(costs.HourlyRate * 24)
+							costs.MonthlyRate = roundCost(costs.DailyRate * 30)
+							costs.YearlyRate = roundCost(costs.DailyRate * 365)
+							lifetime := roundCost(costs.HourlyRate * hoursRunning)
+							costs.Lifetime = &lifetime
+							hours := roundCost(hoursRunning)
+							costs.HoursRunning = &hours
+							costDetails = map[string]interface{}{
+								"total": costs,
+							}
+						}
+					}
+
+					resultsMutex.Lock()
+					results = append(results, awslib.ScanResult{
+						ResourceType: s.Label(),
+						ResourceID:   instanceID,
+						ResourceName: name,
+						Details:      details,
+						Cost:         costDetails,
+						Reason:       strings.Join(reasons, "\n"),
+					})
+					resultsMutex.Unlock()
+
+					logging.Info("Found idle Marketplace instance", map[string]interface{}{
+						"instance_id":   instanceID,
+						"name":          name,
+						"product_codes": productCodes,
+					})
+					return nil
+				})
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe instances: %w", err)
+	}
+
+	worker.GetSharedPool().ExecuteTasks(tasks)
+
+	logging.Info("Completed Marketplace instance scan", map[string]interface{}{
+		"account_id":     opts.AccountID,
+		"region":         opts.Region,
+		"idle_instances": len(results),
+	})
+
+	return results, nil
+}
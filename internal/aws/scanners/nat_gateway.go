@@ -1,6 +1,7 @@
 package scanners
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -30,8 +31,29 @@ func (s *NATGatewayScanner) Label() string {
 	return "NAT Gateways"
 }
 
+// RemediationTemplate implements Remediator interface
+func (s *NATGatewayScanner) RemediationTemplate() string {
+	return "aws ec2 delete-nat-gateway --nat-gateway-id {{.ResourceID}} --region {{.Region}}"
+}
+
+// HasResources implements ResourceProber interface
+func (s *NATGatewayScanner) HasResources(opts awslib.ScanOptions) (bool, error) {
+	sess, err := awslib.GetSessionInRegion(opts.Session, opts.Region)
+	if err != nil {
+		return false, fmt.Errorf("failed to create regional session: %w", err)
+	}
+
+	output, err := ec2.New(sess).DescribeNatGateways(&ec2.DescribeNatGatewaysInput{
+		MaxResults: aws.Int64(5),
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to describe NAT Gateways: %w", err)
+	}
+	return len(output.NatGateways) > 0, nil
+}
+
 // fetchMetric fetches a CloudWatch metric for a NAT Gateway
-func (s *NATGatewayScanner) fetchMetric(cwClient *cloudwatch.CloudWatch, natGatewayID string, metricName string, startTime, endTime time.Time) (float64, error) {
+func (s *NATGatewayScanner) fetchMetric(cwClient *cloudwatch.CloudWatch, natGatewayID string, metricName string, startTime, endTime time.Time, recorder *utils.MetricRecorder) (float64, error) {
 	config := utils.MetricConfig{
 		Namespace:     "AWS/NATGateway",
 		ResourceID:    natGatewayID,
@@ -41,34 +63,35 @@ func (s *NATGatewayScanner) fetchMetric(cwClient *cloudwatch.CloudWatch, natGate
 		StartTime:     startTime,
 		EndTime:       endTime,
 		Period:        86400, // 1 day
+		Recorder:      recorder,
 	}
 
 	return utils.GetResourceMetrics(cwClient, config)
 }
 
 // analyzeNATGatewayUsage analyzes the usage of a NAT Gateway based on CloudWatch metrics
-func (s *NATGatewayScanner) analyzeNATGatewayUsage(cwClient *cloudwatch.CloudWatch, natGatewayID string, daysUnused int) (bool, string, error) {
+func (s *NATGatewayScanner) analyzeNATGatewayUsage(cwClient *cloudwatch.CloudWatch, natGatewayID string, daysUnused int, recorder *utils.MetricRecorder) (bool, string, error) {
 	// Calculate time range for metrics
 	endTime := time.Now()
 	startTime := endTime.Add(-time.Duration(daysUnused) * 24 * time.Hour)
 
 	// Fetch metrics to determine if NAT Gateway is unused
-	bytesInFromSource, err := s.fetchMetric(cwClient, natGatewayID, "BytesInFromSource", startTime, endTime)
+	bytesInFromSource, err := s.fetchMetric(cwClient, natGatewayID, "BytesInFromSource", startTime, endTime, recorder)
 	if err != nil {
 		return false, "", fmt.Errorf("failed to fetch BytesInFromSource metric: %w", err)
 	}
 
-	bytesOutToDestination, err := s.fetchMetric(cwClient, natGatewayID, "BytesOutToDestination", startTime, endTime)
+	bytesOutToDestination, err := s.fetchMetric(cwClient, natGatewayID, "BytesOutToDestination", startTime, endTime, recorder)
 	if err != nil {
 		return false, "", fmt.Errorf("failed to fetch BytesOutToDestination metric: %w", err)
 	}
 
-	bytesInFromDestination, err := s.fetchMetric(cwClient, natGatewayID, "BytesInFromDestination", startTime, endTime)
+	bytesInFromDestination, err := s.fetchMetric(cwClient, natGatewayID, "BytesInFromDestination", startTime, endTime, recorder)
 	if err != nil {
 		return false, "", fmt.Errorf("failed to fetch BytesInFromDestination metric: %w", err)
 	}
 
-	bytesOutToSource, err := s.fetchMetric(cwClient, natGatewayID, "BytesOutToSource", startTime, endTime)
+	bytesOutToSource, err := s.fetchMetric(cwClient, natGatewayID, "BytesOutToSource", startTime, endTime, recorder)
 	if err != nil {
 		return false, "", fmt.Errorf("failed to fetch BytesOutToSource metric: %w", err)
 	}
@@ -166,22 +189,27 @@ func (s *NATGatewayScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, e
 	// Create EC2 and CloudWatch service clients
 	ec2Client := ec2.New(sess)
 	cwClient := cloudwatch.New(sess)
+	vpcNameByID := vpcNames(opts.ScanContext(), ec2Client, opts.AccountID, opts.Region)
+	subnetNameByID := subnetNames(opts.ScanContext(), ec2Client, opts.AccountID, opts.Region)
 
 	// Describe NAT Gateways
-	input := &ec2.DescribeNatGatewaysInput{}
-	natGateways, err := ec2Client.DescribeNatGateways(input)
+	var natGateways []*ec2.NatGateway
+	err = ec2Client.DescribeNatGatewaysPages(&ec2.DescribeNatGatewaysInput{},
+		func(page *ec2.DescribeNatGatewaysOutput, lastPage bool) bool {
+			natGateways = append(natGateways, page.NatGateways...)
+			return !lastPage
+		})
 	if err != nil {
 		logging.Error("Failed to describe NAT Gateways", err, nil)
 		return nil, fmt.Errorf("failed to describe NAT Gateways: %w", err)
 	}
 
-	var results awslib.ScanResults
-
 	// Get days unused from options, default to 30 if not specified
 	daysUnused := utils.Max(opts.DaysUnused, 30)
 
-	// Analyze each NAT Gateway
-	for _, natGateway := range natGateways.NatGateways {
+	// Analyze the NAT Gateways in parallel
+	rateLimiter := utils.ResourceRateLimiter(opts, "nat-gateway", nil)
+	return utils.ForEachResourceParallel(natGateways, rateLimiter, func(ctx context.Context, natGateway *ec2.NatGateway) (*awslib.ScanResult, error) {
 		natGatewayID := aws.StringValue(natGateway.NatGatewayId)
 
 		// Skip NAT Gateways that are not in 'available' state
@@ -190,7 +218,7 @@ func (s *NATGatewayScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, e
 				"nat_gateway_id": natGatewayID,
 				"state":          aws.StringValue(natGateway.State),
 			})
-			continue
+			return nil, nil
 		}
 
 		// Get NAT Gateway name from tags
@@ -206,76 +234,87 @@ func (s *NATGatewayScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, e
 		}
 
 		// Check if NAT Gateway is unused
-		isUnused, reason, err := s.analyzeNATGatewayUsage(cwClient, natGatewayID, daysUnused)
+		recorder := utils.NewMetricRecorder(opts.IncludeMetricSamples)
+		isUnused, reason, err := s.analyzeNATGatewayUsage(cwClient, natGatewayID, daysUnused, recorder)
+		metricsErr, metricsUnavailable := err, utils.MetricsDegraded(err)
 		if err != nil {
 			logging.Error("Failed to analyze NAT Gateway usage", err, map[string]interface{}{
 				"nat_gateway_id": natGatewayID,
 			})
-			continue
+			if !metricsUnavailable {
+				return nil, nil
+			}
+			isUnused, reason = true, utils.MetricsUnavailableReason
 		}
 
-		if isUnused {
-			// Calculate cost
-			cost, err := s.calculateNATGatewayCost(natGateway, opts.Region)
-			if err != nil {
-				logging.Error("Failed to calculate NAT Gateway cost", err, map[string]interface{}{
-					"nat_gateway_id": natGatewayID,
-				})
-
-				// Get creation time for fallback calculation
-				creationTime := aws.TimeValue(natGateway.CreateTime)
-				hoursRunning := time.Since(creationTime).Hours()
-				hourlyRate := 0.045 // Default hourly rate as fallback
-				lifetime := hourlyRate * hoursRunning
-
-				cost = &awslib.CostBreakdown{
-					HourlyRate:   hourlyRate,
-					DailyRate:    hourlyRate * 24,
-					MonthlyRate:  hourlyRate * 24 * 30,
-					YearlyRate:   hourlyRate * 24 * 365,
-					HoursRunning: aws.Float64(hoursRunning),
-					Lifetime:     aws.Float64(lifetime),
-				}
-			}
+		if !isUnused {
+			return nil, nil
+		}
 
-			// Extract all tags
-			tags := make(map[string]string)
-			for _, tag := range natGateway.Tags {
-				tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
-			}
+		// Calculate cost
+		cost, err := s.calculateNATGatewayCost(natGateway, opts.Region)
+		if err != nil {
+			logging.Error("Failed to calculate NAT Gateway cost", err, map[string]interface{}{
+				"nat_gateway_id": natGatewayID,
+			})
 
-			// Get creation time
+			// Get creation time for fallback calculation
 			creationTime := aws.TimeValue(natGateway.CreateTime)
 			hoursRunning := time.Since(creationTime).Hours()
-
-			// Format cost details in the same way as other scanners (like EBS snapshots)
-			costDetails := map[string]interface{}{
-				"total": cost,
+			hourlyRate := 0.045 // Default hourly rate as fallback
+			lifetime := hourlyRate * hoursRunning
+
+			cost = &awslib.CostBreakdown{
+				HourlyRate:   hourlyRate,
+				DailyRate:    hourlyRate * 24,
+				MonthlyRate:  hourlyRate * 24 * 30,
+				YearlyRate:   hourlyRate * 24 * 365,
+				HoursRunning: aws.Float64(hoursRunning),
+				Lifetime:     aws.Float64(lifetime),
 			}
+		}
 
-			// Create result
-			result := awslib.ScanResult{
-				ResourceType: s.Label(),
-				ResourceName: natGatewayName,
-				ResourceID:   natGatewayID,
-				Reason:       reason,
-				Details: map[string]interface{}{
-					"account_id":    opts.AccountID,
-					"region":        opts.Region,
-					"state":         aws.StringValue(natGateway.State),
-					"vpc_id":        aws.StringValue(natGateway.VpcId),
-					"subnet_id":     aws.StringValue(natGateway.SubnetId),
-					"creation_time": creationTime,
-					"hours_running": hoursRunning,
-					"days_unused":   daysUnused,
-				},
-				Tags: tags,
-				Cost: costDetails,
-			}
+		// Extract all tags
+		tags := make(map[string]string)
+		for _, tag := range natGateway.Tags {
+			tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+		}
+
+		// Get creation time
+		creationTime := aws.TimeValue(natGateway.CreateTime)
+		hoursRunning := time.Since(creationTime).Hours()
 
-			results = append(results, result)
+		// Format cost details in the same way as other scanners (like EBS snapshots)
+		costDetails := map[string]interface{}{
+			"total": cost,
 		}
-	}
 
-	return results, nil
+		// Create result
+		result := awslib.ScanResult{
+			ResourceType: s.Label(),
+			ResourceName: natGatewayName,
+			ResourceID:   natGatewayID,
+			Reason:       reason,
+			Details: map[string]interface{}{
+				"account_id":    opts.AccountID,
+				"region":        opts.Region,
+				"state":         aws.StringValue(natGateway.State),
+				"vpc_id":        aws.StringValue(natGateway.VpcId),
+				"vpc_name":      vpcNameByID[aws.StringValue(natGateway.VpcId)],
+				"subnet_id":     aws.StringValue(natGateway.SubnetId),
+				"subnet_name":   subnetNameByID[aws.StringValue(natGateway.SubnetId)],
+				"creation_time": creationTime,
+				"hours_running": hoursRunning,
+				"days_unused":   daysUnused,
+			},
+			Tags: tags,
+			Cost: costDetails,
+		}
+		if metricsUnavailable {
+			utils.MarkMetricsDegraded(result.Details, metricsErr)
+		}
+		recorder.AddTo(result.Details)
+
+		return &result, nil
+	})
 }
@@ -31,8 +31,27 @@ func (s *OpenSearchScanner) Label() string {
 	return "OpenSearch Clusters"
 }
 
+// RemediationTemplate implements Remediator interface
+func (s *OpenSearchScanner) RemediationTemplate() string {
+	return "aws opensearch delete-domain --domain-name {{.ResourceName}} --region {{.Region}}"
+}
+
+// HasResources implements ResourceProber interface
+func (s *OpenSearchScanner) HasResources(opts awslib.ScanOptions) (bool, error) {
+	sess, err := awslib.GetSessionInRegion(opts.Session, opts.Region)
+	if err != nil {
+		return false, fmt.Errorf("failed to create regional session: %w", err)
+	}
+
+	output, err := opensearchservice.New(sess).ListDomainNames(&opensearchservice.ListDomainNamesInput{})
+	if err != nil {
+		return false, fmt.Errorf("failed to list OpenSearch domains: %w", err)
+	}
+	return len(output.DomainNames) > 0, nil
+}
+
 // getClusterMetrics retrieves CloudWatch metrics for an OpenSearch cluster
-func (s *OpenSearchScanner) getClusterMetrics(cwClient *cloudwatch.CloudWatch, domainName string, startTime, endTime time.Time) (map[string]float64, error) {
+func (s *OpenSearchScanner) getClusterMetrics(cwClient *cloudwatch.CloudWatch, domainName string, startTime, endTime time.Time, recorder *utils.MetricRecorder) (map[string]float64, error) {
 	metrics := []utils.MetricConfig{
 		{
 			Namespace:     "AWS/ES",
@@ -99,6 +118,10 @@ func (s *OpenSearchScanner) getClusterMetrics(cwClient *cloudwatch.CloudWatch, d
 		},
 	}
 
+	for i := range metrics {
+		metrics[i].Recorder = recorder
+	}
+
 	results, err := utils.GetResourceMetricsData(cwClient, metrics)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get metrics: %w", err)
@@ -129,6 +152,9 @@ func (s *OpenSearchScanner) determineUnusedReasons(metrics map[string]float64, v
 		} else {
 			reasons = append(reasons, fmt.Sprintf("Cluster has data but no search, index, or delete activity in the last %d days.", opts.DaysUnused))
 		}
+	} else if metrics["search_rate"] == 0 {
+		// Data indexed into a cluster that is never queried, such as logs nobody reads
+		reasons = append(reasons, fmt.Sprintf("Cluster has no search traffic in the last %d days.", opts.DaysUnused))
 	}
 
 	// Check for underutilized clusters
@@ -205,12 +231,16 @@ func (s *OpenSearchScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, e
 		status := describeOutput.DomainStatus
 
 		// Get cluster metrics
-		metrics, err := s.getClusterMetrics(cwClient, domainName, startTime, endTime)
+		recorder := utils.NewMetricRecorder(opts.IncludeMetricSamples)
+		metrics, err := s.getClusterMetrics(cwClient, domainName, startTime, endTime, recorder)
+		metricsErr, metricsUnavailable := err, utils.MetricsDegraded(err)
 		if err != nil {
 			logging.Error("Failed to get cluster metrics", err, map[string]interface{}{
 				"domain_name": domainName,
 			})
-			continue
+			if !metricsUnavailable {
+				continue
+			}
 		}
 
 		// Get cluster configuration
@@ -220,32 +250,40 @@ func (s *OpenSearchScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, e
 		volumeSize := aws.Int64Value(status.EBSOptions.VolumeSize)
 
 		// Determine if cluster is unused/underutilized
-		reasons := s.determineUnusedReasons(metrics, volumeSize, opts)
+		var reasons []string
+		if metricsUnavailable {
+			reasons = []string{utils.MetricsUnavailableReason}
+		} else {
+			reasons = s.determineUnusedReasons(metrics, volumeSize, opts)
+		}
 
 		if len(reasons) > 0 {
-			// Calculate cost
-			// costConfig := awslib.ResourceCostConfig{
-			// 	ResourceType:  "OpenSearch",
-			// 	ResourceSize:  instanceType,
-			// 	Region:        opts.Region,
-			// 	CreationTime:  time.Now(), // OpenSearch API doesn't provide creation time
-			// 	VolumeType:    volumeType,
-			// 	StorageSize:   volumeSize,
-			// 	InstanceCount: instanceCount,
-			// }
-
-			// cost, err := awslib.DefaultCostEstimator.CalculateCost(costConfig)
-			// if err != nil {
-			// 	logging.Error("Failed to calculate cost", err, map[string]interface{}{
-			// 		"domain_name": domainName,
-			// 	})
-			// }
+			// Every data node has its own EBS volume of the configured size
+			var storageSize int64
+			if aws.BoolValue(status.EBSOptions.EBSEnabled) {
+				storageSize = volumeSize * instanceCount
+			}
+			cost, err := awslib.DefaultCostEstimator.CalculateCost(awslib.ResourceCostConfig{
+				ResourceType:  "OpenSearch",
+				ResourceSize:  instanceType,
+				Region:        opts.Region,
+				CreationTime:  time.Now(), // OpenSearch API doesn't provide creation time
+				VolumeType:    volumeType,
+				StorageSize:   storageSize,
+				InstanceCount: instanceCount,
+			})
+			if err != nil {
+				logging.Error("Failed to calculate cost", err, map[string]interface{}{
+					"domain_name": domainName,
+				})
+			}
 
 			details := map[string]interface{}{
 				"InstanceType":   instanceType,
 				"InstanceCount":  instanceCount,
 				"VolumeType":     volumeType,
 				"VolumeSizeGB":   volumeSize,
+				"StorageSizeGB":  storageSize,
 				"CPUUtilization": metrics["cpu_utilization"],
 				"SearchRate":     metrics["search_rate"],
 				"IndexRate":      metrics["index_rate"],
@@ -254,10 +292,10 @@ func (s *OpenSearchScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, e
 				"opts.AccountID": opts.AccountID,
 				"Region":         opts.Region,
 			}
-
-			// if cost != nil {
-			// 	details["Cost"] = cost
-			// }
+			if metricsUnavailable {
+				utils.MarkMetricsDegraded(details, metricsErr)
+			}
+			recorder.AddTo(details)
 
 			result := awslib.ScanResult{
 				ResourceType: s.Label(),
@@ -265,6 +303,12 @@ func (s *OpenSearchScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, e
 				ResourceID:   aws.StringValue(status.ARN),
 				Reason:       strings.Join(reasons, "\n"),
 				Details:      details,
+				Cost:         awslib.NoCost(awslib.NoCostNotEstimated),
+			}
+			if cost != nil {
+				result.Cost = map[string]interface{}{
+					"total": cost,
+				}
 			}
 
 			results = append(results, result)
@@ -19,6 +19,7 @@ type RDSScanner struct{}
 
 func init() {
 	awslib.DefaultRegistry.RegisterScanner(&RDSScanner{})
+	awslib.DefaultRegistry.RegisterAlias("rds-instances", "rds")
 }
 
 // ArgumentName implements Scanner interface
@@ -31,6 +32,28 @@ func (s *RDSScanner) Label() string {
 	return "RDS Instances"
 }
 
+// RemediationTemplate implements Remediator interface. Instances are stopped rather than
+// deleted; RDS starts them again after seven days.
+func (s *RDSScanner) RemediationTemplate() string {
+	return "aws rds stop-db-instance --db-instance-identifier {{.ResourceName}} --region {{.Region}}"
+}
+
+// HasResources implements ResourceProber interface
+func (s *RDSScanner) HasResources(opts awslib.ScanOptions) (bool, error) {
+	sess, err := awslib.GetSessionInRegion(opts.Session, opts.Region)
+	if err != nil {
+		return false, fmt.Errorf("failed to create regional session: %w", err)
+	}
+
+	output, err := rds.New(sess).DescribeDBInstances(&rds.DescribeDBInstancesInput{
+		MaxRecords: aws.Int64(20),
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to describe RDS instances: %w", err)
+	}
+	return len(output.DBInstances) > 0, nil
+}
+
 // Scan implements Scanner interface
 func (s *RDSScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, error) {
 	// Get regional session
@@ -72,12 +95,17 @@ func (s *RDSScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, error) {
 		hoursRunning := endTime.Sub(aws.TimeValue(instance.InstanceCreateTime)).Hours()
 
 		// Analyze instance usage
-		reasons, err := s.analyzeInstanceUsage(clients.CloudWatch, instance, startTime, endTime)
+		recorder := utils.NewMetricRecorder(opts.IncludeMetricSamples)
+		reasons, err := s.analyzeInstanceUsage(clients.CloudWatch, instance, startTime, endTime, recorder)
+		metricsErr, metricsUnavailable := err, utils.MetricsDegraded(err)
 		if err != nil {
 			logging.Error("Failed to analyze instance usage", err, map[string]interface{}{
 				"instance_id": instanceID,
 			})
-			continue
+			if !metricsUnavailable {
+				continue
+			}
+			reasons = []string{utils.MetricsUnavailableReason}
 		}
 
 		if len(reasons) > 0 {
@@ -96,6 +124,10 @@ func (s *RDSScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, error) {
 				"MultiAZ":            aws.BoolValue(instance.MultiAZ),
 				"PubliclyAccessible": aws.BoolValue(instance.PubliclyAccessible),
 			}
+			if metricsUnavailable {
+				utils.MarkMetricsDegraded(details, metricsErr)
+			}
+			recorder.AddTo(details)
 
 			// Add optional instance details if present
 			if instance.Endpoint != nil {
@@ -115,12 +147,14 @@ func (s *RDSScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, error) {
 				}
 			}
 
+			// Instances whose price cannot be looked up are still reported, without an estimate
 			result := awslib.ScanResult{
 				ResourceType: s.Label(),
 				ResourceName: instanceID,
 				ResourceID:   aws.StringValue(instance.DBInstanceArn),
-				Reason:       strings.Join(reasons, ", "),
+				Reason:       strings.Join(reasons, "\n"),
 				Details:      details,
+				Cost:         awslib.NoCost(awslib.NoCostNotEstimated),
 			}
 
 			// Calculate cost
@@ -153,7 +187,7 @@ func (s *RDSScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, error) {
 }
 
 // analyzeInstanceUsage checks if an instance is underutilized
-func (s *RDSScanner) analyzeInstanceUsage(cwClient *cloudwatch.CloudWatch, instance *rds.DBInstance, startTime, endTime time.Time) ([]string, error) {
+func (s *RDSScanner) analyzeInstanceUsage(cwClient *cloudwatch.CloudWatch, instance *rds.DBInstance, startTime, endTime time.Time, recorder *utils.MetricRecorder) ([]string, error) {
 	instanceID := aws.StringValue(instance.DBInstanceIdentifier)
 	var reasons []string
 
@@ -182,6 +216,7 @@ func (s *RDSScanner) analyzeInstanceUsage(cwClient *cloudwatch.CloudWatch, insta
 				Statistic:     metric.stat,
 				StartTime:     startTime.UTC(),
 				EndTime:       endTime.UTC(),
+				Recorder:      recorder,
 			},
 		}
 
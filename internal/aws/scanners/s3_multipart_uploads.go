@@ -0,0 +1,306 @@
+package scanners
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	awslib "cloudsift/internal/aws"
+	"cloudsift/internal/logging"
+	"cloudsift/internal/worker"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// S3MultipartUploadScanner scans for incomplete S3 multipart uploads. Parts of uploads that
+// are never completed or aborted are billed as storage but do not show up as objects.
+type S3MultipartUploadScanner struct{}
+
+func init() {
+	awslib.DefaultRegistry.RegisterScanner(&S3MultipartUploadScanner{})
+}
+
+// ArgumentName implements Scanner interface
+func (s *S3MultipartUploadScanner) ArgumentName() string {
+	return "s3-multipart-uploads"
+}
+
+// Label implements Scanner interface
+func (s *S3MultipartUploadScanner) Label() string {
+	return "S3 Multipart Uploads"
+}
+
+// bucketRegionsKey is the scan context key of the regions of the account's buckets, keyed by
+// bucket name. Buckets are listed once per partition, in the scan context of its home region.
+const bucketRegionsKey = "s3/bucket-regions"
+
+// bucketsInRegion returns the names of the account's buckets located in a region
+func bucketsInRegion(opts awslib.ScanOptions) ([]string, error) {
+	homeRegion := awslib.PartitionHomeRegion(opts.Region)
+	bucketRegions, err := opts.Contexts.Get(opts.AccountID, homeRegion).LoadOrCompute(bucketRegionsKey, func() (interface{}, error) {
+		client := s3.New(opts.Session, aws.NewConfig().WithRegion(homeRegion))
+		output, err := client.ListBuckets(&s3.ListBucketsInput{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list buckets: %w", err)
+		}
+
+		regions := make(map[string]string, len(output.Buckets))
+		for _, bucket := range output.Buckets {
+			name := aws.StringValue(bucket.Name)
+			location, err := client.GetBucketLocation(&s3.GetBucketLocationInput{Bucket: bucket.Name})
+			if err != nil {
+				logging.Warn("Failed to get bucket location", map[string]interface{}{
+					"bucket": name,
+					"error":  err.Error(),
+				})
+				continue
+			}
+			regions[name] = s3.NormalizeBucketLocation(aws.StringValue(location.LocationConstraint))
+		}
+		return regions, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var buckets []string
+	for name, bucketRegion := range bucketRegions.(map[string]string) {
+		if bucketRegion == opts.Region {
+			buckets = append(buckets, name)
+		}
+	}
+	sort.Strings(buckets)
+	return buckets, nil
+}
+
+// HasResources implements ResourceProber interface
+func (s *S3MultipartUploadScanner) HasResources(opts awslib.ScanOptions) (bool, error) {
+	buckets, err := bucketsInRegion(opts)
+	if err != nil {
+		return false, err
+	}
+	return len(buckets) > 0, nil
+}
+
+// hasAbortMultipartRule returns true if the bucket has an enabled lifecycle rule that aborts
+// incomplete multipart uploads for the whole bucket
+func hasAbortMultipartRule(client *s3.S3, bucket string) (bool, error) {
+	output, err := client.GetBucketLifecycleConfiguration(&s3.GetBucketLifecycleConfigurationInput{
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == "NoSuchLifecycleConfiguration" {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get lifecycle configuration: %w", err)
+	}
+
+	for _, rule := range output.Rules {
+		if aws.StringValue(rule.Status) != s3.ExpirationStatusEnabled || rule.AbortIncompleteMultipartUpload == nil {
+			continue
+		}
+		prefix := aws.StringValue(rule.Prefix)
+		if rule.Filter != nil {
+			if rule.Filter.And != nil || rule.Filter.Tag != nil {
+				continue
+			}
+			prefix += aws.StringValue(rule.Filter.Prefix)
+		}
+		if prefix == "" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// uploadSize returns the total size in bytes of the parts uploaded so far
+func uploadSize(client *s3.S3, bucket string, upload *s3.MultipartUpload) (int64, error) {
+	var size int64
+	err := client.ListPartsPages(&s3.ListPartsInput{
+		Bucket:   aws.String(bucket),
+		Key:      upload.Key,
+		UploadId: upload.UploadId,
+	}, func(page *s3.ListPartsOutput, lastPage bool) bool {
+		for _, part := range page.Parts {
+			size += aws.Int64Value(part.Size)
+		}
+		return true
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list parts: %w", err)
+	}
+	return size, nil
+}
+
+// Scan implements Scanner interface
+func (s *S3MultipartUploadScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, error) {
+	sess, err := awslib.GetSessionInRegion(opts.Session, opts.Region)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create regional session: %w", err)
+	}
+
+	buckets, err := bucketsInRegion(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	logging.Info("Starting S3 multipart upload scan", map[string]interface{}{
+		"account_id": opts.AccountID,
+		"region":     opts.Region,
+		"buckets":    len(buckets),
+	})
+
+	client := s3.New(sess)
+	cutoff := time.Now().UTC().Add(-time.Duration(opts.DaysUnused) * 24 * time.Hour)
+
+	var results awslib.ScanResults
+	var resultsMutex sync.Mutex
+	var tasks []worker.Task
+
+	for _, bucket := range buckets {
+		bucket := bucket
+		tasks = append(tasks, func(ctx context.Context) error {
+			var staleUploads []*s3.MultipartUpload
+			err := client.ListMultipartUploadsPages(&s3.ListMultipartUploadsInput{
+				Bucket: aws.String(bucket),
+			}, func(page *s3.ListMultipartUploadsOutput, lastPage bool) bool {
+				for _, upload := range page.Uploads {
+					if upload.Initiated != nil && upload.Initiated.Before(cutoff) {
+						staleUploads = append(staleUploads, upload)
+					}
+				}
+				return true
+			})
+			if err != nil {
+				logging.Error("Failed to list multipart uploads", err, map[string]interface{}{
+					"bucket": bucket,
+				})
+				return nil
+			}
+			if len(staleUploads) == 0 {
+				return nil
+			}
+
+			// Sum the size of the uploaded parts per storage class, since each class is
+			// billed at its own rate
+			var totalSize int64
+			sizeByClass := make(map[string]int64)
+			oldest := *staleUploads[0].Initiated
+			for _, upload := range staleUploads {
+				size, err := uploadSize(client, bucket, upload)
+				if err != nil {
+					logging.Error("Failed to get multipart upload size", err, map[string]interface{}{
+						"bucket":    bucket,
+						"key":       aws.StringValue(upload.Key),
+						"upload_id": aws.StringValue(upload.UploadId),
+					})
+					continue
+				}
+				storageClass := aws.StringValue(upload.StorageClass)
+				if storageClass == "" {
+					storageClass = s3.StorageClassStandard
+				}
+				sizeByClass[storageClass] += size
+				totalSize += size
+				if upload.Initiated.Before(oldest) {
+					oldest = *upload.Initiated
+				}
+			}
+			totalSizeGB := float64(totalSize) / (1024 * 1024 * 1024)
+
+			hasAbortRule, err := hasAbortMultipartRule(client, bucket)
+			if err != nil {
+				logging.Warn("Failed to check bucket lifecycle rules", map[string]interface{}{
+					"bucket": bucket,
+					"error":  err.Error(),
+				})
+			}
+
+			reasons := []string{
+				fmt.Sprintf("Bucket has %d incomplete multipart uploads older than %d days (%.2f GB).", len(staleUploads), opts.DaysUnused, totalSizeGB),
+			}
+			details := map[string]interface{}{
+				"bucket":            bucket,
+				"upload_count":      len(staleUploads),
+				"total_size_bytes":  totalSize,
+				"total_size_gb":     totalSizeGB,
+				"oldest_initiated":  oldest.Format(time.RFC3339),
+				"has_abort_rule":    hasAbortRule,
+				"size_by_class":     sizeByClass,
+				"region":            opts.Region,
+				"days_since_oldest": int(time.Since(oldest).Hours() / 24),
+			}
+			if !hasAbortRule {
+				reasons = append(reasons, "No lifecycle rule aborts incomplete multipart uploads.")
+				details["recommended_lifecycle_rule"] = map[string]interface{}{
+					"ID":     "abort-incomplete-multipart-uploads",
+					"Status": "Enabled",
+					"Filter": map[string]interface{}{"Prefix": ""},
+					"AbortIncompleteMultipartUpload": map[string]interface{}{
+						"DaysAfterInitiation": opts.DaysUnused,
+					},
+				}
+			}
+
+			var costDetails map[string]interface{}
+			if awslib.DefaultCostEstimator != nil {
+				total := &awslib.CostBreakdown{}
+				for storageClass, size := range sizeByClass {
+					costs, err := awslib.DefaultCostEstimator.CalculateCost(awslib.ResourceCostConfig{
+						ResourceType: "S3Storage",
+						ResourceSize: float64(size) / (1024 * 1024 * 1024),
+						VolumeType:   storageClass,
+						Region:       opts.Region,
+					})
+					if err != nil {
+						logging.Error("Failed to calculate multipart upload costs", err, map[string]interface{}{
+							"bucket":        bucket,
+							"storage_class": storageClass,
+						})
+						continue
+					}
+					total.HourlyRate += costs.HourlyRate
+					total.DailyRate += costs.DailyRate
+					total.MonthlyRate += costs.MonthlyRate
+					total.YearlyRate += costs.YearlyRate
+				}
+				costDetails = map[string]interface{}{
+					"total": total,
+				}
+			}
+
+			resultsMutex.Lock()
+			results = append(results, awslib.ScanResult{
+				ResourceType: s.Label(),
+				ResourceID:   bucket,
+				ResourceName: bucket,
+				Details:      details,
+				Cost:         costDetails,
+				Reason:       strings.Join(reasons, "\n"),
+			})
+			resultsMutex.Unlock()
+
+			logging.Info("Found incomplete multipart uploads", map[string]interface{}{
+				"bucket":        bucket,
+				"upload_count":  len(staleUploads),
+				"total_size_gb": totalSizeGB,
+			})
+			return nil
+		})
+	}
+
+	worker.GetSharedPool().ExecuteTasks(tasks)
+
+	logging.Info("Completed S3 multipart upload scan", map[string]interface{}{
+		"account_id": opts.AccountID,
+		"region":     opts.Region,
+		"buckets":    len(results),
+	})
+
+	return results, nil
+}
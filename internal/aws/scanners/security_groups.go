@@ -27,6 +27,11 @@ func (s *SecurityGroupScanner) Label() string {
 	return "Security Groups"
 }
 
+// RemediationTemplate implements Remediator interface
+func (s *SecurityGroupScanner) RemediationTemplate() string {
+	return "aws ec2 delete-security-group --group-id {{.ResourceID}} --region {{.Region}}"
+}
+
 // Scan implements Scanner interface
 func (s *SecurityGroupScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, error) {
 	// Get regional session
@@ -59,11 +64,6 @@ func (s *SecurityGroupScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults
 		sgID := aws.StringValue(sg.GroupId)
 		sgName := aws.StringValue(sg.GroupName)
 
-		// Skip default security group
-		if sgName == "default" {
-			continue
-		}
-
 		logging.Debug("Analyzing security group", map[string]interface{}{
 			"group_id":   sgID,
 			"group_name": sgName,
@@ -200,6 +200,7 @@ func (s *SecurityGroupScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults
 				ResourceID:   sgID,
 				Reason:       "Not associated with any resource (EC2 Instance or ENI)",
 				Details:      details,
+				Cost:         awslib.NoCost(awslib.NoCostFree),
 			}
 
 			results = append(results, result)
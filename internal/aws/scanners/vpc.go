@@ -27,6 +27,73 @@ func (s *VPCScanner) Label() string {
 	return "VPCs"
 }
 
+// RemediationTemplate implements Remediator interface. VPCs can only be deleted once their
+// subnets, gateways and interfaces are removed.
+func (s *VPCScanner) RemediationTemplate() string {
+	return "aws ec2 delete-vpc --vpc-id {{.ResourceID}} --region {{.Region}}"
+}
+
+// Scan context keys of the Name tags of the VPCs and subnets of the region
+const (
+	vpcNamesKey    = "ec2/vpc-names"
+	subnetNamesKey = "ec2/subnet-names"
+)
+
+// vpcNames returns the Name tags of the VPCs in the client's region keyed by VPC ID. Several
+// scanners label their results with the VPC name, so names are looked up once per scan
+// context.
+func vpcNames(scanContext *awslib.ScanContext, client *ec2.EC2, accountID, region string) map[string]string {
+	return networkNames(scanContext, vpcNamesKey, "vpc", accountID, region, func(add func(id string, tags []*ec2.Tag)) error {
+		return client.DescribeVpcsPages(&ec2.DescribeVpcsInput{}, func(page *ec2.DescribeVpcsOutput, lastPage bool) bool {
+			for _, vpc := range page.Vpcs {
+				add(aws.StringValue(vpc.VpcId), vpc.Tags)
+			}
+			return true
+		})
+	})
+}
+
+// subnetNames returns the Name tags of the subnets in the client's region keyed by subnet
+// ID, looked up once per scan context like vpcNames
+func subnetNames(scanContext *awslib.ScanContext, client *ec2.EC2, accountID, region string) map[string]string {
+	return networkNames(scanContext, subnetNamesKey, "subnet", accountID, region, func(add func(id string, tags []*ec2.Tag)) error {
+		return client.DescribeSubnetsPages(&ec2.DescribeSubnetsInput{}, func(page *ec2.DescribeSubnetsOutput, lastPage bool) bool {
+			for _, subnet := range page.Subnets {
+				add(aws.StringValue(subnet.SubnetId), subnet.Tags)
+			}
+			return true
+		})
+	})
+}
+
+// networkNames returns the Name tags of a kind of network resource published in the scan
+// context under key, calling describe to list the resources with their tags on the first
+// lookup. Failed lookups are not published, so a later scanner tries again.
+func networkNames(scanContext *awslib.ScanContext, key, kind, accountID, region string, describe func(add func(id string, tags []*ec2.Tag)) error) map[string]string {
+	names, err := scanContext.LoadOrCompute(key, func() (interface{}, error) {
+		names := make(map[string]string)
+		err := describe(func(id string, tags []*ec2.Tag) {
+			for _, tag := range tags {
+				if aws.StringValue(tag.Key) == "Name" {
+					names[id] = aws.StringValue(tag.Value)
+					break
+				}
+			}
+		})
+		return names, err
+	})
+	if err != nil {
+		// Results are still reported, only without names
+		logging.Warn(fmt.Sprintf("Failed to get %s names", kind), map[string]interface{}{
+			"account_id": accountID,
+			"region":     region,
+			"error":      err.Error(),
+		})
+		return map[string]string{}
+	}
+	return names.(map[string]string)
+}
+
 // countEC2Instances counts the number of EC2 instances in a VPC
 func (s *VPCScanner) countEC2Instances(ec2Client *ec2.EC2, vpcID string) (int, error) {
 	input := &ec2.DescribeInstancesInput{
@@ -122,14 +189,6 @@ func (s *VPCScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, error) {
 		vpcID := aws.StringValue(vpc.VpcId)
 		isDefault := aws.BoolValue(vpc.IsDefault)
 
-		// Skip default VPCs
-		if isDefault {
-			logging.Debug("Skipping default VPC", map[string]interface{}{
-				"vpc_id": vpcID,
-			})
-			continue
-		}
-
 		// Get VPC name from tags
 		var vpcName string
 		for _, tag := range vpc.Tags {
@@ -174,6 +233,7 @@ func (s *VPCScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, error) {
 					"resource_count": resourceCount,
 				},
 				Tags: tags,
+				Cost: awslib.NoCost(awslib.NoCostFree),
 			}
 
 			results = append(results, result)
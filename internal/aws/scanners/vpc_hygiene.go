@@ -0,0 +1,291 @@
+package scanners
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	awslib "cloudsift/internal/aws"
+	"cloudsift/internal/logging"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// detachedVPCResourcesID is the resource ID of the finding rolling up the resources of a
+// region that are not attached to any VPC
+const detachedVPCResourcesID = "detached-vpc-resources"
+
+// VPCHygieneScanner rolls up leftover VPC resources, such as route tables without subnets and
+// gateways without routes, into one finding per VPC. Gateways and DHCP option sets that are
+// not attached to any VPC are rolled up into one finding per region.
+type VPCHygieneScanner struct{}
+
+func init() {
+	awslib.DefaultRegistry.RegisterScanner(&VPCHygieneScanner{})
+}
+
+// ArgumentName implements Scanner interface
+func (s *VPCHygieneScanner) ArgumentName() string {
+	return "vpc-hygiene"
+}
+
+// Label implements Scanner interface
+func (s *VPCHygieneScanner) Label() string {
+	return "VPC Hygiene"
+}
+
+// vpcHygiene collects the leftover resources of a VPC, or of a region for detached resources
+type vpcHygiene struct {
+	staleRouteTables     []string // Route tables without subnet associations
+	unroutedEgressOnly   []string // Egress-only internet gateways no route points to
+	unroutedCarrier      []string // Carrier gateways no route points to
+	detachedInternet     []string // Internet gateways not attached to a VPC
+	detachedEgressOnly   []string // Egress-only internet gateways not attached to a VPC
+	detachedVPNGateways  []string // Virtual private gateways not attached to a VPC
+	unusedDHCPOptionSets []string // DHCP option sets no VPC uses
+}
+
+// reasons returns a reason line and a detail for each kind of leftover resource
+func (h *vpcHygiene) reasons() ([]string, map[string]interface{}) {
+	var reasons []string
+	details := make(map[string]interface{})
+	for _, item := range []struct {
+		ids    []string
+		detail string
+		reason string
+	}{
+		{h.staleRouteTables, "stale_route_tables", "Route tables not associated with any subnet: %s."},
+		{h.unroutedEgressOnly, "unrouted_egress_only_gateways", "Egress-only internet gateways without routes: %s."},
+		{h.unroutedCarrier, "unrouted_carrier_gateways", "Carrier gateways without routes: %s."},
+		{h.detachedInternet, "detached_internet_gateways", "Internet gateways not attached to a VPC: %s."},
+		{h.detachedEgressOnly, "detached_egress_only_gateways", "Egress-only internet gateways not attached to a VPC: %s."},
+		{h.detachedVPNGateways, "detached_vpn_gateways", "Virtual private gateways not attached to a VPC: %s."},
+		{h.unusedDHCPOptionSets, "unused_dhcp_option_sets", "DHCP option sets not used by any VPC: %s."},
+	} {
+		if len(item.ids) == 0 {
+			continue
+		}
+		sort.Strings(item.ids)
+		reasons = append(reasons, fmt.Sprintf(item.reason, strings.Join(item.ids, ", ")))
+		details[item.detail] = item.ids
+	}
+	return reasons, details
+}
+
+// count returns the number of leftover resources
+func (h *vpcHygiene) count() int {
+	return len(h.staleRouteTables) + len(h.unroutedEgressOnly) + len(h.unroutedCarrier) +
+		len(h.detachedInternet) + len(h.detachedEgressOnly) + len(h.detachedVPNGateways) + len(h.unusedDHCPOptionSets)
+}
+
+// attachedTo returns the ID of the VPC a gateway is attached to, or "" if it is detached
+func attachedTo(attachments []*ec2.InternetGatewayAttachment) string {
+	for _, attachment := range attachments {
+		if aws.StringValue(attachment.State) != ec2.AttachmentStatusDetached {
+			return aws.StringValue(attachment.VpcId)
+		}
+	}
+	return ""
+}
+
+// Scan implements Scanner interface
+func (s *VPCHygieneScanner) Scan(opts awslib.ScanOptions) (awslib.ScanResults, error) {
+	sess, err := awslib.GetSessionInRegion(opts.Session, opts.Region)
+	if err != nil {
+		logging.Error("Failed to create regional session", err, map[string]interface{}{
+			"region": opts.Region,
+		})
+		return nil, fmt.Errorf("failed to create regional session: %w", err)
+	}
+	ec2Client := ec2.New(sess)
+
+	logging.Debug("Starting VPC hygiene scan", map[string]interface{}{
+		"account_id": opts.AccountID,
+		"region":     opts.Region,
+	})
+
+	vpcs := make(map[string]*vpcHygiene)
+	vpcTags := make(map[string][]*ec2.Tag)
+	usedDHCPOptions := make(map[string]bool)
+	err = ec2Client.DescribeVpcsPages(&ec2.DescribeVpcsInput{}, func(page *ec2.DescribeVpcsOutput, lastPage bool) bool {
+		for _, vpc := range page.Vpcs {
+			vpcs[aws.StringValue(vpc.VpcId)] = &vpcHygiene{}
+			vpcTags[aws.StringValue(vpc.VpcId)] = vpc.Tags
+			usedDHCPOptions[aws.StringValue(vpc.DhcpOptionsId)] = true
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe VPCs: %w", err)
+	}
+	detached := &vpcHygiene{}
+
+	// Route tables without subnet associations that are not the main route table of their
+	// VPC, and the gateways routes point to
+	routedGateways := make(map[string]bool)
+	err = ec2Client.DescribeRouteTablesPages(&ec2.DescribeRouteTablesInput{}, func(page *ec2.DescribeRouteTablesOutput, lastPage bool) bool {
+		for _, routeTable := range page.RouteTables {
+			for _, route := range routeTable.Routes {
+				for _, gatewayID := range []*string{route.GatewayId, route.EgressOnlyInternetGatewayId, route.CarrierGatewayId} {
+					if id := aws.StringValue(gatewayID); id != "" {
+						routedGateways[id] = true
+					}
+				}
+			}
+			if len(routeTable.Associations) > 0 {
+				continue
+			}
+			if vpc, ok := vpcs[aws.StringValue(routeTable.VpcId)]; ok {
+				vpc.staleRouteTables = append(vpc.staleRouteTables, aws.StringValue(routeTable.RouteTableId))
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe route tables: %w", err)
+	}
+
+	err = ec2Client.DescribeInternetGatewaysPages(&ec2.DescribeInternetGatewaysInput{}, func(page *ec2.DescribeInternetGatewaysOutput, lastPage bool) bool {
+		for _, gateway := range page.InternetGateways {
+			if attachedTo(gateway.Attachments) == "" {
+				detached.detachedInternet = append(detached.detachedInternet, aws.StringValue(gateway.InternetGatewayId))
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe internet gateways: %w", err)
+	}
+
+	err = ec2Client.DescribeEgressOnlyInternetGatewaysPages(&ec2.DescribeEgressOnlyInternetGatewaysInput{}, func(page *ec2.DescribeEgressOnlyInternetGatewaysOutput, lastPage bool) bool {
+		for _, gateway := range page.EgressOnlyInternetGateways {
+			gatewayID := aws.StringValue(gateway.EgressOnlyInternetGatewayId)
+			vpcID := attachedTo(gateway.Attachments)
+			if vpc, ok := vpcs[vpcID]; ok {
+				if !routedGateways[gatewayID] {
+					vpc.unroutedEgressOnly = append(vpc.unroutedEgressOnly, gatewayID)
+				}
+			} else {
+				detached.detachedEgressOnly = append(detached.detachedEgressOnly, gatewayID)
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe egress-only internet gateways: %w", err)
+	}
+
+	vpnGateways, err := ec2Client.DescribeVpnGateways(&ec2.DescribeVpnGatewaysInput{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe virtual private gateways: %w", err)
+	}
+	for _, gateway := range vpnGateways.VpnGateways {
+		if aws.StringValue(gateway.State) != ec2.VpnStateAvailable {
+			continue
+		}
+		attached := false
+		for _, attachment := range gateway.VpcAttachments {
+			if aws.StringValue(attachment.State) != ec2.AttachmentStatusDetached {
+				attached = true
+				break
+			}
+		}
+		if !attached {
+			detached.detachedVPNGateways = append(detached.detachedVPNGateways, aws.StringValue(gateway.VpnGatewayId))
+		}
+	}
+
+	err = ec2Client.DescribeDhcpOptionsPages(&ec2.DescribeDhcpOptionsInput{}, func(page *ec2.DescribeDhcpOptionsOutput, lastPage bool) bool {
+		for _, options := range page.DhcpOptions {
+			if !usedDHCPOptions[aws.StringValue(options.DhcpOptionsId)] {
+				detached.unusedDHCPOptionSets = append(detached.unusedDHCPOptionSets, aws.StringValue(options.DhcpOptionsId))
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe DHCP option sets: %w", err)
+	}
+
+	// Carrier gateways only exist in Wavelength Zones, so regions without them fail the call
+	err = ec2Client.DescribeCarrierGatewaysPages(&ec2.DescribeCarrierGatewaysInput{}, func(page *ec2.DescribeCarrierGatewaysOutput, lastPage bool) bool {
+		for _, gateway := range page.CarrierGateways {
+			gatewayID := aws.StringValue(gateway.CarrierGatewayId)
+			if vpc, ok := vpcs[aws.StringValue(gateway.VpcId)]; ok && aws.StringValue(gateway.State) == ec2.CarrierGatewayStateAvailable && !routedGateways[gatewayID] {
+				vpc.unroutedCarrier = append(vpc.unroutedCarrier, gatewayID)
+			}
+		}
+		return true
+	})
+	if err != nil {
+		logging.Debug("Failed to describe carrier gateways", map[string]interface{}{
+			"account_id": opts.AccountID,
+			"region":     opts.Region,
+			"error":      err.Error(),
+		})
+	}
+
+	var results awslib.ScanResults
+	names := vpcNames(opts.ScanContext(), ec2Client, opts.AccountID, opts.Region)
+	vpcIDs := make([]string, 0, len(vpcs))
+	for vpcID := range vpcs {
+		vpcIDs = append(vpcIDs, vpcID)
+	}
+	sort.Strings(vpcIDs)
+	for _, vpcID := range vpcIDs {
+		hygiene := vpcs[vpcID]
+		if hygiene.count() == 0 {
+			continue
+		}
+		reasons, details := hygiene.reasons()
+		details["vpc_id"] = vpcID
+		details["vpc_name"] = names[vpcID]
+		details["resource_count"] = hygiene.count()
+		details["account_id"] = opts.AccountID
+		details["region"] = opts.Region
+
+		tags := make(map[string]string)
+		for _, tag := range vpcTags[vpcID] {
+			tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+		}
+		resourceName := names[vpcID]
+		if resourceName == "" {
+			resourceName = vpcID
+		}
+
+		results = append(results, awslib.ScanResult{
+			ResourceType: s.Label(),
+			ResourceName: resourceName,
+			ResourceID:   vpcID,
+			Reason:       strings.Join(reasons, "\n"),
+			Tags:         tags,
+			Details:      details,
+			Cost:         awslib.NoCost(awslib.NoCostFree),
+		})
+	}
+
+	if detached.count() > 0 {
+		reasons, details := detached.reasons()
+		details["resource_count"] = detached.count()
+		details["account_id"] = opts.AccountID
+		details["region"] = opts.Region
+		results = append(results, awslib.ScanResult{
+			ResourceType: s.Label(),
+			ResourceName: "Resources not attached to a VPC",
+			ResourceID:   detachedVPCResourcesID,
+			Reason:       strings.Join(reasons, "\n"),
+			Details:      details,
+			Cost:         awslib.NoCost(awslib.NoCostFree),
+		})
+	}
+
+	logging.Debug("VPC hygiene scan completed", map[string]interface{}{
+		"account_id": opts.AccountID,
+		"region":     opts.Region,
+		"vpcs":       len(vpcs),
+		"findings":   len(results),
+	})
+
+	return results, nil
+}
@@ -2,23 +2,73 @@ package aws
 
 import (
 	"fmt"
-	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/sts"
 
 	"cloudsift/internal/logging"
-	"net/http"
 
 	"cloudsift/internal/config"
 )
 
+// defaultSTSRegion is the STS region used when neither the configuration nor the session
+// names one
+const defaultSTSRegion = "us-east-1"
+
+// STSConfig returns the configuration of STS clients. Regional STS endpoints are always used,
+// so role assumption does not depend on the global endpoint. The endpoint region is the
+// configured STS region, falling back to the region of the session.
+func STSConfig(sess *session.Session) *aws.Config {
+	region := config.Config.STSRegion
+	if region == "" && sess != nil && sess.Config != nil {
+		region = aws.StringValue(sess.Config.Region)
+	}
+	if region == "" {
+		region = defaultSTSRegion
+	}
+	return aws.NewConfig().
+		WithRegion(region).
+		WithSTSRegionalEndpoint(endpoints.RegionalSTSEndpoint)
+}
+
+// AssumeRoleCredentials returns credentials of a role assumed through the regional STS
+// endpoint given by STSConfig
+func AssumeRoleCredentials(sess *session.Session, roleARN string) *credentials.Credentials {
+	return stscreds.NewCredentials(sess, roleARN, func(p *stscreds.AssumeRoleProvider) {
+		p.Client = sts.New(sess, STSConfig(sess))
+	})
+}
+
+// SessionConfig returns the base config of sessions: regional STS endpoints, the configured
+// base HTTP client and FIPS endpoints if enabled
+func SessionConfig() *aws.Config {
+	cfg := aws.NewConfig().
+		WithSTSRegionalEndpoint(endpoints.RegionalSTSEndpoint).
+		WithHTTPClient(BaseHTTPClient())
+	if config.Config.UseFIPSEndpoints {
+		cfg = cfg.WithUseFIPSEndpoint(true)
+	}
+	return cfg
+}
+
+// newAssumedRoleSession creates a session using the credentials of an assumed role
+func newAssumedRoleSession(creds *credentials.Credentials) (*session.Session, error) {
+	sess, err := session.NewSession(SessionConfig().WithCredentials(creds))
+	if err != nil {
+		return nil, err
+	}
+	attachFIPSValidation(sess)
+	return sess, nil
+}
+
 // GetSession creates a new AWS session with optional region and role
 // Deprecated: Use GetSessionChain + GetSessionInRegion instead
 func GetSession(role string, region ...string) (*session.Session, error) {
-	cfg := aws.NewConfig()
+	cfg := SessionConfig()
 	if len(region) > 0 && region[0] != "" {
 		cfg = cfg.WithRegion(region[0])
 	}
@@ -28,6 +78,7 @@ func GetSession(role string, region ...string) (*session.Session, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to create AWS session: %w", err)
 	}
+	attachFIPSValidation(sess)
 
 	// If no role specified, return base session
 	if role == "" {
@@ -35,18 +86,17 @@ func GetSession(role string, region ...string) (*session.Session, error) {
 	}
 
 	// Get current account ID for role ARN
-	svc := sts.New(sess)
+	svc := sts.New(sess, STSConfig(sess))
 	identity, err := svc.GetCallerIdentity(&sts.GetCallerIdentityInput{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get caller identity: %w", err)
 	}
 
 	// Construct role ARN
-	roleARN := fmt.Sprintf("arn:aws:iam::%s:role/%s", *identity.Account, role)
+	roleARN := CallerRoleARN(identity, role)
 
 	// Create new session with assumed role
-	creds := stscreds.NewCredentials(sess, roleARN)
-	return session.NewSession(cfg.WithCredentials(creds))
+	return newAssumedRoleSession(AssumeRoleCredentials(sess, roleARN))
 }
 
 // GetSessionChain creates a new AWS session with proper role assumption chain:
@@ -66,7 +116,7 @@ func GetSessionChain(organizationRole, scannerRole string, targetAccountID strin
 	}
 
 	// Get base session identity for logging
-	stsSvc := sts.New(baseSession)
+	stsSvc := sts.New(baseSession, STSConfig(baseSession))
 	baseIdentity, err := stsSvc.GetCallerIdentity(&sts.GetCallerIdentityInput{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get base session identity: %w", err)
@@ -84,15 +134,15 @@ func GetSessionChain(organizationRole, scannerRole string, targetAccountID strin
 			"role": organizationRole,
 		})
 
-		orgRoleARN := fmt.Sprintf("arn:aws:iam::%s:role/%s", *baseIdentity.Account, organizationRole)
-		orgCreds := stscreds.NewCredentials(currentSession, orgRoleARN)
-		orgSession, err := session.NewSession(aws.NewConfig().WithCredentials(orgCreds))
+		orgRoleARN := CallerRoleARN(baseIdentity, organizationRole)
+		orgCreds := AssumeRoleCredentials(currentSession, orgRoleARN)
+		orgSession, err := newAssumedRoleSession(orgCreds)
 		if err != nil {
 			return nil, fmt.Errorf("failed to assume organization role %s: %w", organizationRole, err)
 		}
 
 		// Verify org role assumption
-		orgStsSvc := sts.New(orgSession)
+		orgStsSvc := sts.New(orgSession, STSConfig(orgSession))
 		orgIdentity, err := orgStsSvc.GetCallerIdentity(&sts.GetCallerIdentityInput{})
 		if err != nil {
 			return nil, fmt.Errorf("failed to verify organization role assumption: %w", err)
@@ -113,15 +163,15 @@ func GetSessionChain(organizationRole, scannerRole string, targetAccountID strin
 				"target_account": targetAccountID,
 			})
 
-			scannerRoleARN := fmt.Sprintf("arn:aws:iam::%s:role/%s", targetAccountID, scannerRole)
-			scannerCreds := stscreds.NewCredentials(currentSession, scannerRoleARN)
-			scannerSession, err := session.NewSession(aws.NewConfig().WithCredentials(scannerCreds))
+			scannerRoleARN := RoleARN(region, targetAccountID, scannerRole)
+			scannerCreds := AssumeRoleCredentials(currentSession, scannerRoleARN)
+			scannerSession, err := newAssumedRoleSession(scannerCreds)
 			if err != nil {
 				return nil, fmt.Errorf("failed to assume scanner role %s in account %s: %w", scannerRole, targetAccountID, err)
 			}
 
 			// Verify scanner role assumption
-			scannerStsSvc := sts.New(scannerSession)
+			scannerStsSvc := sts.New(scannerSession, STSConfig(scannerSession))
 			scannerIdentity, err := scannerStsSvc.GetCallerIdentity(&sts.GetCallerIdentityInput{})
 			if err != nil {
 				return nil, fmt.Errorf("failed to verify scanner role assumption: %w", err)
@@ -134,21 +184,21 @@ func GetSessionChain(organizationRole, scannerRole string, targetAccountID strin
 			currentSession = scannerSession
 		} else {
 			// No target account, assume scanner role in current account
-			stsSvc := sts.New(currentSession)
+			stsSvc := sts.New(currentSession, STSConfig(currentSession))
 			identity, err := stsSvc.GetCallerIdentity(&sts.GetCallerIdentityInput{})
 			if err != nil {
 				return nil, fmt.Errorf("failed to get identity for scanner role assumption: %w", err)
 			}
 
-			scannerRoleARN := fmt.Sprintf("arn:aws:iam::%s:role/%s", *identity.Account, scannerRole)
-			scannerCreds := stscreds.NewCredentials(currentSession, scannerRoleARN)
-			scannerSession, err := session.NewSession(aws.NewConfig().WithCredentials(scannerCreds))
+			scannerRoleARN := CallerRoleARN(identity, scannerRole)
+			scannerCreds := AssumeRoleCredentials(currentSession, scannerRoleARN)
+			scannerSession, err := newAssumedRoleSession(scannerCreds)
 			if err != nil {
 				return nil, fmt.Errorf("failed to assume scanner role %s: %w", scannerRole, err)
 			}
 
 			// Verify scanner role assumption
-			scannerStsSvc := sts.New(scannerSession)
+			scannerStsSvc := sts.New(scannerSession, STSConfig(scannerSession))
 			scannerIdentity, err := scannerStsSvc.GetCallerIdentity(&sts.GetCallerIdentityInput{})
 			if err != nil {
 				return nil, fmt.Errorf("failed to verify scanner role assumption: %w", err)
@@ -166,7 +216,7 @@ func GetSessionChain(organizationRole, scannerRole string, targetAccountID strin
 
 // NewSession creates a new AWS session with the specified profile and region
 func NewSession(profile string, region string) (*session.Session, error) {
-	cfg := aws.NewConfig()
+	cfg := SessionConfig()
 	if region != "" {
 		cfg = cfg.WithRegion(region)
 	}
@@ -179,7 +229,12 @@ func NewSession(profile string, region string) (*session.Session, error) {
 	}
 
 	// Create session with profile
-	return session.NewSessionWithOptions(opts)
+	sess, err := session.NewSessionWithOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	attachFIPSValidation(sess)
+	return sess, nil
 }
 
 // GetSessionInRegion creates a new session in the specified region using credentials from an existing session
@@ -188,16 +243,14 @@ func GetSessionInRegion(sess *session.Session, region string) (*session.Session,
 		return sess, nil
 	}
 
-	// Create HTTP client with timeout
-	httpClient := &http.Client{
-		Timeout: 25 * time.Second, // Set timeout slightly less than worker pool timeout
-	}
-
-	// Create new session with updated region and timeout while preserving other config options
-	newSess, err := session.NewSession(sess.Config.WithRegion(region).WithHTTPClient(httpClient))
+	// Create new session with updated region and the configured HTTP client while preserving
+	// other config options
+	newSess, err := session.NewSession(sess.Config.WithRegion(region).WithHTTPClient(HTTPClient()))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create session: %w", err)
 	}
+	attachFIPSValidation(newSess)
+	scanAPICallTracker.Load().Attach(newSess)
 	return newSess, nil
 }
 
@@ -213,17 +266,17 @@ func AssumeRole(targetAccountID, roleName string, sess *session.Session) (*sessi
 	})
 
 	// Construct role ARN for target account
-	roleARN := fmt.Sprintf("arn:aws:iam::%s:role/%s", targetAccountID, roleName)
+	roleARN := RoleARN(aws.StringValue(sess.Config.Region), targetAccountID, roleName)
 
 	// Create new session with assumed role
-	creds := stscreds.NewCredentials(sess, roleARN)
-	assumedSession, err := session.NewSession(aws.NewConfig().WithCredentials(creds))
+	creds := AssumeRoleCredentials(sess, roleARN)
+	assumedSession, err := newAssumedRoleSession(creds)
 	if err != nil {
 		return nil, fmt.Errorf("failed to assume role %s in account %s: %w", roleName, targetAccountID, err)
 	}
 
 	// Verify role assumption
-	stsSvc := sts.New(assumedSession)
+	stsSvc := sts.New(assumedSession, STSConfig(assumedSession))
 	identity, err := stsSvc.GetCallerIdentity(&sts.GetCallerIdentityInput{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to verify cross-account role assumption: %w", err)
@@ -0,0 +1,175 @@
+package aws
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/costexplorer"
+
+	"cloudsift/internal/logging"
+)
+
+// costExplorerRegion is the region of the Cost Explorer API endpoint
+const costExplorerRegion = "us-east-1"
+
+// AccountSpend is the spend of an account in the current month. It is loaded in USD, the
+// currency of Cost Explorer and of Cost and Usage Report summaries, and converted into the
+// report currency by the scan.
+type AccountSpend struct {
+	MonthToDate float64 `json:"month_to_date"` // Spend since the start of the month
+	Projected   float64 `json:"projected"`     // Month-to-date spend extrapolated to the whole month
+}
+
+// monthFraction returns the fraction of the month elapsed at a time
+func monthFraction(now time.Time) float64 {
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+	return now.UTC().Sub(start).Hours() / end.Sub(start).Hours()
+}
+
+// newAccountSpend extrapolates month-to-date spend to the whole month
+func newAccountSpend(monthToDate float64, now time.Time) AccountSpend {
+	spend := AccountSpend{MonthToDate: monthToDate, Projected: monthToDate}
+	if fraction := monthFraction(now); fraction > 0 {
+		spend.Projected = monthToDate / fraction
+	}
+	return spend
+}
+
+// GetMonthToDateSpend returns the unblended month-to-date spend of each linked account from
+// Cost Explorer. From the management account it covers all accounts of the organization;
+// from a member account only the account itself.
+func GetMonthToDateSpend(sess *session.Session, now time.Time) (map[string]AccountSpend, error) {
+	client := costexplorer.New(sess, OptionalFIPSConfig("ce", costExplorerRegion))
+
+	// The end date is exclusive, so include today
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1)
+	input := &costexplorer.GetCostAndUsageInput{
+		TimePeriod: &costexplorer.DateInterval{
+			Start: aws.String(start.Format("2006-01-02")),
+			End:   aws.String(end.Format("2006-01-02")),
+		},
+		Granularity: aws.String(costexplorer.GranularityMonthly),
+		Metrics:     aws.StringSlice([]string{costexplorer.MetricUnblendedCost}),
+		GroupBy: []*costexplorer.GroupDefinition{{
+			Type: aws.String(costexplorer.GroupDefinitionTypeDimension),
+			Key:  aws.String(costexplorer.DimensionLinkedAccount),
+		}},
+	}
+
+	totals := make(map[string]float64)
+	for {
+		output, err := client.GetCostAndUsage(input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get cost and usage: %w", err)
+		}
+		for _, period := range output.ResultsByTime {
+			for _, group := range period.Groups {
+				if len(group.Keys) == 0 {
+					continue
+				}
+				metric, ok := group.Metrics[costexplorer.MetricUnblendedCost]
+				if !ok {
+					continue
+				}
+				amount, err := strconv.ParseFloat(aws.StringValue(metric.Amount), 64)
+				if err != nil {
+					return nil, fmt.Errorf("failed to parse cost of account %s: %w", aws.StringValue(group.Keys[0]), err)
+				}
+				totals[aws.StringValue(group.Keys[0])] += amount
+			}
+		}
+		if aws.StringValue(output.NextPageToken) == "" {
+			break
+		}
+		input.NextPageToken = output.NextPageToken
+	}
+
+	spend := make(map[string]AccountSpend, len(totals))
+	for accountID, total := range totals {
+		spend[accountID] = newAccountSpend(total, now)
+	}
+	logging.Info("Fetched month-to-date spend from Cost Explorer", map[string]interface{}{
+		"accounts": len(spend),
+	})
+	return spend, nil
+}
+
+// Columns accepted in spend summary files, in order of preference
+var (
+	spendAccountColumns = []string{"account_id", "line_item_usage_account_id", "bill_payer_account_id"}
+	spendCostColumns    = []string{"cost", "month_to_date", "line_item_unblended_cost", "unblended_cost"}
+)
+
+// LoadSpendSummary reads the month-to-date spend of accounts from a CSV file, such as a
+// summary query of the Cost and Usage Report. The header names the account and cost columns,
+// e.g. account_id,cost or line_item_usage_account_id,line_item_unblended_cost; rows of the
+// same account are summed.
+func LoadSpendSummary(path string, now time.Time) (map[string]AccountSpend, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open spend summary: %w", err)
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spend summary header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	findColumn := func(names []string) (int, error) {
+		for _, name := range names {
+			if i, ok := columns[name]; ok {
+				return i, nil
+			}
+		}
+		return 0, fmt.Errorf("spend summary has no %s column", strings.Join(names, ", "))
+	}
+	accountColumn, err := findColumn(spendAccountColumns)
+	if err != nil {
+		return nil, err
+	}
+	costColumn, err := findColumn(spendCostColumns)
+	if err != nil {
+		return nil, err
+	}
+
+	totals := make(map[string]float64)
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read spend summary: %w", err)
+		}
+		amount, err := strconv.ParseFloat(strings.TrimSpace(record[costColumn]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cost on line %d of spend summary: %w", line, err)
+		}
+		totals[strings.TrimSpace(record[accountColumn])] += amount
+	}
+
+	spend := make(map[string]AccountSpend, len(totals))
+	for accountID, total := range totals {
+		spend[accountID] = newAccountSpend(total, now)
+	}
+	logging.Info("Loaded month-to-date spend summary", map[string]interface{}{
+		"path":     path,
+		"accounts": len(spend),
+	})
+	return spend, nil
+}
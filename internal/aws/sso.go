@@ -0,0 +1,238 @@
+package aws
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/ssocreds"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sso"
+	"github.com/aws/aws-sdk-go/service/ssooidc"
+
+	"cloudsift/internal/logging"
+)
+
+// ssoClientName is the client name registered with IAM Identity Center during login
+const ssoClientName = "cloudsift"
+
+// SSOToken is an IAM Identity Center access token. It is cached in the same location and
+// format as the AWS CLI, so tokens are shared between CloudSift, the CLI and SSO profiles.
+type SSOToken struct {
+	AccessToken string `json:"accessToken"`
+	ExpiresAt   string `json:"expiresAt"` // RFC 3339
+	Region      string `json:"region"`
+	StartURL    string `json:"startUrl"`
+}
+
+// Expired returns true if the token has expired or its expiry cannot be parsed
+func (t *SSOToken) Expired() bool {
+	expiresAt, err := time.Parse(time.RFC3339, t.ExpiresAt)
+	return err != nil || time.Now().After(expiresAt)
+}
+
+// SSODeviceAuthorization holds the details a user needs to approve a device login
+type SSODeviceAuthorization struct {
+	VerificationURI         string
+	VerificationURIComplete string
+	UserCode                string
+}
+
+// ssoTokenCachePath returns the AWS CLI compatible cache file for a start URL
+func ssoTokenCachePath(startURL string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	hash := sha1.Sum([]byte(startURL))
+	return filepath.Join(home, ".aws", "sso", "cache", hex.EncodeToString(hash[:])+".json"), nil
+}
+
+// LoadSSOToken returns the cached access token for a start URL
+func LoadSSOToken(startURL string) (*SSOToken, error) {
+	path, err := ssoTokenCachePath(startURL)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("no cached SSO token for %s, run 'cloudsift login --sso-start-url %s': %w", startURL, startURL, err)
+	}
+
+	var token SSOToken
+	if err := json.Unmarshal(data, &token); err != nil {
+		return nil, fmt.Errorf("failed to parse cached SSO token: %w", err)
+	}
+	if token.AccessToken == "" || token.Expired() {
+		return nil, fmt.Errorf("cached SSO token for %s has expired, run 'cloudsift login --sso-start-url %s'", startURL, startURL)
+	}
+
+	return &token, nil
+}
+
+// saveSSOToken writes an access token to the cache
+func saveSSOToken(token *SSOToken) error {
+	path, err := ssoTokenCachePath(token.StartURL)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create SSO cache directory: %w", err)
+	}
+
+	data, err := json.Marshal(token)
+	if err != nil {
+		return fmt.Errorf("failed to marshal SSO token: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write SSO token: %w", err)
+	}
+	return nil
+}
+
+// SSOLogin authenticates with IAM Identity Center using the OAuth device authorization flow
+// and caches the resulting access token. The prompt callback is invoked with the verification
+// URL and code the user must confirm in a browser.
+func SSOLogin(startURL, region string, prompt func(SSODeviceAuthorization)) (*SSOToken, error) {
+	sess, err := session.NewSession(aws.NewConfig().WithRegion(region).WithCredentials(credentials.AnonymousCredentials).WithHTTPClient(BaseHTTPClient()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create SSO session: %w", err)
+	}
+	client := ssooidc.New(sess, OptionalFIPSConfig("oidc", region))
+
+	registration, err := client.RegisterClient(&ssooidc.RegisterClientInput{
+		ClientName: aws.String(ssoClientName),
+		ClientType: aws.String("public"),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to register SSO client: %w", err)
+	}
+
+	authorization, err := client.StartDeviceAuthorization(&ssooidc.StartDeviceAuthorizationInput{
+		ClientId:     registration.ClientId,
+		ClientSecret: registration.ClientSecret,
+		StartUrl:     aws.String(startURL),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to start SSO device authorization: %w", err)
+	}
+
+	prompt(SSODeviceAuthorization{
+		VerificationURI:         aws.StringValue(authorization.VerificationUri),
+		VerificationURIComplete: aws.StringValue(authorization.VerificationUriComplete),
+		UserCode:                aws.StringValue(authorization.UserCode),
+	})
+
+	interval := time.Duration(aws.Int64Value(authorization.Interval)) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	deadline := time.Now().Add(time.Duration(aws.Int64Value(authorization.ExpiresIn)) * time.Second)
+
+	for time.Now().Before(deadline) {
+		time.Sleep(interval)
+
+		output, err := client.CreateToken(&ssooidc.CreateTokenInput{
+			ClientId:     registration.ClientId,
+			ClientSecret: registration.ClientSecret,
+			DeviceCode:   authorization.DeviceCode,
+			GrantType:    aws.String("urn:ietf:params:oauth:grant-type:device_code"),
+		})
+		if err != nil {
+			if aerr, ok := err.(awserr.Error); ok {
+				switch aerr.Code() {
+				case ssooidc.ErrCodeAuthorizationPendingException:
+					continue
+				case ssooidc.ErrCodeSlowDownException:
+					interval += 5 * time.Second
+					continue
+				}
+			}
+			return nil, fmt.Errorf("failed to create SSO token: %w", err)
+		}
+
+		token := &SSOToken{
+			AccessToken: aws.StringValue(output.AccessToken),
+			ExpiresAt:   time.Now().Add(time.Duration(aws.Int64Value(output.ExpiresIn)) * time.Second).UTC().Format(time.RFC3339),
+			Region:      region,
+			StartURL:    startURL,
+		}
+		if err := saveSSOToken(token); err != nil {
+			return nil, err
+		}
+
+		logging.Debug("Cached SSO token", map[string]interface{}{
+			"start_url":  startURL,
+			"expires_at": token.ExpiresAt,
+		})
+		return token, nil
+	}
+
+	return nil, fmt.Errorf("SSO device authorization expired before it was approved")
+}
+
+// ListSSOAccounts lists the accounts the cached SSO token for a start URL has access to
+func ListSSOAccounts(startURL, region string) ([]Account, error) {
+	token, err := LoadSSOToken(startURL)
+	if err != nil {
+		return nil, err
+	}
+
+	sess, err := session.NewSession(aws.NewConfig().WithRegion(region).WithCredentials(credentials.AnonymousCredentials).WithHTTPClient(BaseHTTPClient()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create SSO session: %w", err)
+	}
+
+	var accounts []Account
+	err = sso.New(sess, OptionalFIPSConfig("portal.sso", region)).ListAccountsPages(&sso.ListAccountsInput{
+		AccessToken: aws.String(token.AccessToken),
+	}, func(page *sso.ListAccountsOutput, lastPage bool) bool {
+		for _, account := range page.AccountList {
+			accounts = append(accounts, Account{
+				ID:   aws.StringValue(account.AccountId),
+				Name: aws.StringValue(account.AccountName),
+			})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list SSO accounts: %w", err)
+	}
+
+	logging.Info("Listed SSO accounts", map[string]interface{}{
+		"start_url":     startURL,
+		"account_count": len(accounts),
+	})
+
+	return accounts, nil
+}
+
+// NewSSOSession creates a session for an account using the credentials of an IAM Identity
+// Center permission set, exchanged from the cached SSO token for the start URL
+func NewSSOSession(startURL, ssoRegion, accountID, roleName, region string) (*session.Session, error) {
+	ssoSess, err := session.NewSession(aws.NewConfig().WithRegion(ssoRegion).WithCredentials(credentials.AnonymousCredentials).WithHTTPClient(BaseHTTPClient()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create SSO session: %w", err)
+	}
+
+	creds := ssocreds.NewCredentials(ssoSess, accountID, roleName, startURL)
+	cfg := SessionConfig().WithCredentials(creds)
+	if region != "" {
+		cfg = cfg.WithRegion(region)
+	}
+
+	sess, err := session.NewSession(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session for permission set %s in account %s: %w", roleName, accountID, err)
+	}
+	attachFIPSValidation(sess)
+	return sess, nil
+}
@@ -0,0 +1,100 @@
+package aws
+
+import "sort"
+
+// stoppedInstanceResourceType is the resource type of the findings the EBS storage of stopped
+// instances is totaled from. Other scanners reporting stopped instances, such as accelerated
+// and Marketplace instances, report the same instances again.
+const stoppedInstanceResourceType = "EC2 Instances"
+
+// StoppedInstanceStorage totals the EBS volumes attached to stopped EC2 instances, which are
+// still billed while the instances are stopped
+type StoppedInstanceStorage struct {
+	Instances   int                             `json:"instances"`
+	Volumes     int                             `json:"volumes"`
+	SizeGB      int64                           `json:"size_gb"`
+	MonthlyCost float64                         `json:"monthly_cost"`
+	Accounts    []StoppedInstanceStorageAccount `json:"accounts"` // Highest monthly cost first
+}
+
+// StoppedInstanceStorageAccount is the EBS storage of the stopped instances of an account
+type StoppedInstanceStorageAccount struct {
+	AccountID   string  `json:"account_id"`
+	AccountName string  `json:"account_name"`
+	Instances   int     `json:"instances"`
+	Volumes     int     `json:"volumes"`
+	SizeGB      int64   `json:"size_gb"`
+	MonthlyCost float64 `json:"monthly_cost"`
+}
+
+// SummarizeStoppedInstanceStorage totals the EBS storage of the stopped instances reported
+// by the EC2 instance scanner. The cost of a stopped instance finding is the cost of its
+// volumes, as stopped instances are not billed for compute. Low confidence findings are left
+// out like in the savings totals.
+func SummarizeStoppedInstanceStorage(results []ScanResult) StoppedInstanceStorage {
+	var summary StoppedInstanceStorage
+	accounts := make(map[string]*StoppedInstanceStorageAccount)
+	for _, result := range results {
+		if result.ResourceType != stoppedInstanceResourceType || result.Details["state"] != "stopped" || result.LowConfidence() {
+			continue
+		}
+
+		volumes, sizeGB := attachedVolumes(result.Details["ebs_volumes"])
+		monthlyCost := 0.0
+		if costs := result.SavingsBreakdown(); costs != nil {
+			monthlyCost = costs.MonthlyRate
+		}
+
+		account, ok := accounts[result.AccountID]
+		if !ok {
+			account = &StoppedInstanceStorageAccount{AccountID: result.AccountID, AccountName: result.AccountName}
+			accounts[result.AccountID] = account
+		}
+		account.Instances++
+		account.Volumes += volumes
+		account.SizeGB += sizeGB
+		account.MonthlyCost += monthlyCost
+		summary.Instances++
+		summary.Volumes += volumes
+		summary.SizeGB += sizeGB
+		summary.MonthlyCost += monthlyCost
+	}
+
+	for _, account := range accounts {
+		summary.Accounts = append(summary.Accounts, *account)
+	}
+	sort.Slice(summary.Accounts, func(i, j int) bool {
+		if summary.Accounts[i].MonthlyCost != summary.Accounts[j].MonthlyCost {
+			return summary.Accounts[i].MonthlyCost > summary.Accounts[j].MonthlyCost
+		}
+		return summary.Accounts[i].AccountID < summary.Accounts[j].AccountID
+	})
+	return summary
+}
+
+// attachedVolumes returns the number and total size of the volumes in the ebs_volumes detail
+// of an instance, as built by the scanner or decoded from JSON
+func attachedVolumes(detail interface{}) (int, int64) {
+	var volumes []map[string]interface{}
+	switch v := detail.(type) {
+	case []map[string]interface{}:
+		volumes = v
+	case []interface{}:
+		for _, volume := range v {
+			if m, ok := volume.(map[string]interface{}); ok {
+				volumes = append(volumes, m)
+			}
+		}
+	}
+
+	var sizeGB int64
+	for _, volume := range volumes {
+		switch size := volume["SizeGB"].(type) {
+		case int64:
+			sizeGB += size
+		case float64:
+			sizeGB += int64(size)
+		}
+	}
+	return len(volumes), sizeGB
+}
@@ -1,13 +1,99 @@
 package utils
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"sync"
 	"time"
 
+	awslib "cloudsift/internal/aws"
+
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/cloudwatch"
 )
 
+// MetricsTimeout bounds a single CloudWatch metrics request, so a hanging call stalls only
+// the resource it was made for instead of the whole scanner
+const MetricsTimeout = 30 * time.Second
+
+// MetricsUnavailable is recorded as metrics_status in the details of resources whose
+// metrics could not be retrieved in time
+const MetricsUnavailable = "metrics unavailable"
+
+// MetricsUnavailableReason is the scan reason of resources whose usage could not be checked
+// because their metrics timed out
+const MetricsUnavailableReason = "CloudWatch metrics unavailable, usage could not be verified."
+
+// MetricsIncomplete is recorded as metrics_status in the details of resources whose metrics
+// requests were still throttled after MetricsThrottleRetries
+const MetricsIncomplete = "metrics incomplete"
+
+// LowConfidence is recorded as confidence in the details of resources reported without the
+// metrics confirming they are unused
+const LowConfidence = awslib.LowConfidence
+
+// MetricsThrottleRetries is the number of times a throttled metrics request is retried before
+// the resource is reported without its metrics
+const MetricsThrottleRetries = 1
+
+// ErrMetricsTimeout is returned when a metrics request exceeds MetricsTimeout
+var ErrMetricsTimeout = errors.New("CloudWatch metrics request timed out")
+
+// ErrMetricsThrottled is returned when a metrics request is still throttled after
+// MetricsThrottleRetries
+var ErrMetricsThrottled = errors.New("CloudWatch metrics request throttled")
+
+// MetricsRetries is the request option of metrics requests. It retries throttled requests
+// only MetricsThrottleRetries times, so scanners continue without the metrics of a resource
+// instead of retrying a throttled account and region until the task fails.
+var MetricsRetries request.Option = func(r *request.Request) {
+	r.Retryer = client.DefaultRetryer{
+		NumMaxRetries:    MetricsThrottleRetries,
+		MinRetryDelay:    client.DefaultRetryerMinRetryDelay,
+		MinThrottleDelay: client.DefaultRetryerMinThrottleDelay,
+		MaxRetryDelay:    5 * time.Second,
+		MaxThrottleDelay: 5 * time.Second,
+	}
+}
+
+// MetricsContext returns a context that expires after MetricsTimeout
+func MetricsContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), MetricsTimeout)
+}
+
+// MetricsError returns ErrMetricsTimeout if the metrics request failed because ctx expired,
+// ErrMetricsThrottled if it was throttled, and err otherwise
+func MetricsError(ctx context.Context, err error) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w after %s", ErrMetricsTimeout, MetricsTimeout)
+	}
+	if IsThrottlingError(err) {
+		return fmt.Errorf("%w after %d retries: %v", ErrMetricsThrottled, MetricsThrottleRetries, err)
+	}
+	return err
+}
+
+// MetricsDegraded reports whether the metrics of a resource could not be retrieved because
+// the requests timed out or were throttled. Scanners report such resources without their
+// metrics instead of failing.
+func MetricsDegraded(err error) bool {
+	return errors.Is(err, ErrMetricsTimeout) || errors.Is(err, ErrMetricsThrottled)
+}
+
+// MarkMetricsDegraded records in result details that the metrics of a resource could not be
+// retrieved, and that the finding has a lower confidence for it
+func MarkMetricsDegraded(details map[string]interface{}, err error) {
+	details["metrics_status"] = MetricsUnavailable
+	if errors.Is(err, ErrMetricsThrottled) {
+		details["metrics_status"] = MetricsIncomplete
+	}
+	details["confidence"] = LowConfidence
+}
+
 // MetricConfig represents configuration for retrieving CloudWatch metrics
 type MetricConfig struct {
 	Namespace     string
@@ -18,6 +104,92 @@ type MetricConfig struct {
 	StartTime     time.Time
 	EndTime       time.Time
 	Period        int64
+	Recorder      *MetricRecorder // Records the raw datapoints when set
+}
+
+// MetricSample is a raw CloudWatch datapoint a usage decision was based on
+type MetricSample struct {
+	Timestamp time.Time `json:"timestamp"`
+	Value     float64   `json:"value"`
+}
+
+// MetricRecorder collects the raw datapoints of the metrics fetched for a resource, so
+// findings can be backed with the evidence they were based on. A nil recorder records
+// nothing, allowing scanners to pass one unconditionally.
+type MetricRecorder struct {
+	samples map[string][]MetricSample
+	mu      sync.Mutex
+}
+
+// NewMetricRecorder creates a recorder, or returns nil when samples are not retained
+func NewMetricRecorder(enabled bool) *MetricRecorder {
+	if !enabled {
+		return nil
+	}
+	return &MetricRecorder{
+		samples: make(map[string][]MetricSample),
+	}
+}
+
+// Record stores the datapoints of a metric statistic in chronological order, keyed like
+// "AWS/EC2/CPUUtilization:Average"
+func (r *MetricRecorder) Record(namespace, metricName, statistic string, timestamps []time.Time, values []float64) {
+	if r == nil {
+		return
+	}
+	samples := make([]MetricSample, 0, len(values))
+	for i, value := range values {
+		if i >= len(timestamps) {
+			break
+		}
+		samples = append(samples, MetricSample{Timestamp: timestamps[i].UTC(), Value: value})
+	}
+	sort.Slice(samples, func(i, j int) bool {
+		return samples[i].Timestamp.Before(samples[j].Timestamp)
+	})
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.samples[fmt.Sprintf("%s/%s:%s", namespace, metricName, statistic)] = samples
+}
+
+// RecordDatapoints stores the values of a statistic from GetMetricStatistics datapoints
+func (r *MetricRecorder) RecordDatapoints(namespace, metricName, statistic string, datapoints []*cloudwatch.Datapoint) {
+	if r == nil {
+		return
+	}
+	timestamps := make([]time.Time, 0, len(datapoints))
+	values := make([]float64, 0, len(datapoints))
+	for _, dp := range datapoints {
+		var value *float64
+		switch statistic {
+		case "Average":
+			value = dp.Average
+		case "Sum":
+			value = dp.Sum
+		case "Maximum":
+			value = dp.Maximum
+		case "Minimum":
+			value = dp.Minimum
+		}
+		if value != nil {
+			timestamps = append(timestamps, aws.TimeValue(dp.Timestamp))
+			values = append(values, *value)
+		}
+	}
+	r.Record(namespace, metricName, statistic, timestamps, values)
+}
+
+// AddTo adds the recorded samples to result details as metric_samples
+func (r *MetricRecorder) AddTo(details map[string]interface{}) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.samples) > 0 {
+		details["metric_samples"] = r.samples
+	}
 }
 
 // GetResourceMetrics retrieves CloudWatch metrics for a resource using GetMetricStatistics
@@ -39,11 +211,16 @@ func GetResourceMetrics(cwClient *cloudwatch.CloudWatch, config MetricConfig) (f
 		},
 	}
 
-	output, err := cwClient.GetMetricStatistics(input)
+	ctx, cancel := MetricsContext()
+	defer cancel()
+
+	output, err := cwClient.GetMetricStatisticsWithContext(ctx, input, MetricsRetries)
 	if err != nil {
-		return 0, fmt.Errorf("failed to get metric statistics: %w", err)
+		return 0, fmt.Errorf("failed to get metric statistics: %w", MetricsError(ctx, err))
 	}
 
+	config.Recorder.RecordDatapoints(config.Namespace, config.MetricName, config.Statistic, output.Datapoints)
+
 	if len(output.Datapoints) == 0 {
 		return 0, nil
 	}
@@ -93,13 +270,27 @@ func GetResourceMetricsData(cwClient *cloudwatch.CloudWatch, configs []MetricCon
 		EndTime:           aws.Time(configs[0].EndTime),
 	}
 
-	output, err := cwClient.GetMetricData(input)
+	ctx, cancel := MetricsContext()
+	defer cancel()
+
+	output, err := cwClient.GetMetricDataWithContext(ctx, input, MetricsRetries)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get metric data: %w", err)
+		return nil, fmt.Errorf("failed to get metric data: %w", MetricsError(ctx, err))
 	}
 
 	results := make(map[string]float64)
 	for i, metricResult := range output.MetricDataResults {
+		if configs[i].Recorder != nil {
+			timestamps := make([]time.Time, 0, len(metricResult.Values))
+			values := make([]float64, 0, len(metricResult.Values))
+			for j, value := range metricResult.Values {
+				if value != nil && j < len(metricResult.Timestamps) {
+					timestamps = append(timestamps, aws.TimeValue(metricResult.Timestamps[j]))
+					values = append(values, *value)
+				}
+			}
+			configs[i].Recorder.Record(configs[i].Namespace, configs[i].MetricName, configs[i].Statistic, timestamps, values)
+		}
 		if len(metricResult.Values) > 0 {
 			var sum float64
 			var validValues int
@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	awslib "cloudsift/internal/aws"
+	"cloudsift/internal/config"
+	"cloudsift/internal/worker"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+)
+
+// throttlingCodes are the error codes AWS APIs return when requests are throttled
+var throttlingCodes = map[string]bool{
+	"Throttling":                             true,
+	"ThrottlingException":                    true,
+	"ThrottledException":                     true,
+	"RequestLimitExceeded":                   true,
+	"RequestThrottled":                       true,
+	"RequestThrottledException":              true,
+	"TooManyRequestsException":               true,
+	"ProvisionedThroughputExceededException": true,
+}
+
+// IsThrottlingError reports whether an error was caused by AWS throttling the request
+func IsThrottlingError(err error) bool {
+	var awsErr awserr.Error
+	if errors.As(err, &awsErr) && throttlingCodes[awsErr.Code()] {
+		return true
+	}
+	return err != nil && strings.Contains(err.Error(), "Throttling:")
+}
+
+// ResourceRateLimiter returns the rate limiter shared by all scans of a service in an account
+// and region. A nil config uses the default rate limit.
+func ResourceRateLimiter(opts awslib.ScanOptions, service string, cfg *config.RateLimitConfig) *awslib.RateLimiter {
+	key := fmt.Sprintf("%s-%s-%s", opts.AccountID, opts.Region, service)
+	return awslib.GetGlobalRegistry().GetRateLimiter(key, cfg)
+}
+
+// MaxResourceWorkers is the number of resources a scanner processes concurrently
+const MaxResourceWorkers = 10
+
+// resourceTimeout bounds the processing of a single resource, including rate limiting backoff
+const resourceTimeout = 3 * time.Minute
+
+// ForEachResourceParallel processes up to MaxResourceWorkers resources concurrently, waiting
+// for the rate limiter before each one. A resource processed to nil is not reported. Results
+// keep the order of the resources; the first error fails the whole scan once all resources
+// were processed, so process should log and skip errors that only affect one resource.
+//
+// Scanners already run on the shared worker pool, so resources are processed on their own
+// goroutines: waiting for tasks submitted to the pool from one of its workers could deadlock
+// when all workers are busy.
+func ForEachResourceParallel[T any](resources []T, rateLimiter *awslib.RateLimiter, process func(ctx context.Context, resource T) (*awslib.ScanResult, error)) (awslib.ScanResults, error) {
+	if len(resources) == 0 {
+		return nil, nil
+	}
+
+	processed := make([]*awslib.ScanResult, len(resources))
+	var firstErr error
+	var errMutex sync.Mutex
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, MaxResourceWorkers)
+
+	for i, resource := range resources {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func() (err error) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			defer func() {
+				if err != nil {
+					errMutex.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					errMutex.Unlock()
+				}
+			}()
+			defer worker.Recover(&err)
+
+			ctx, cancel := context.WithTimeout(context.Background(), resourceTimeout)
+			defer cancel()
+			if err := rateLimiter.Wait(ctx); err != nil {
+				return fmt.Errorf("rate limit wait error: %w", err)
+			}
+			result, err := process(ctx, resource)
+			if IsThrottlingError(err) {
+				rateLimiter.OnFailure()
+			} else if err == nil {
+				rateLimiter.OnSuccess()
+			}
+			if err != nil {
+				return err
+			}
+			processed[i] = result
+			return nil
+		}()
+	}
+
+	// Wait for all tasks to complete
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	var results awslib.ScanResults
+	for _, result := range processed {
+		if result != nil {
+			results = append(results, *result)
+		}
+	}
+	return results, nil
+}
@@ -3,6 +3,7 @@ package utils
 import (
 	"fmt"
 
+	"github.com/aws/aws-sdk-go/aws/endpoints"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/cloudwatch"
 	"github.com/aws/aws-sdk-go/service/ec2"
@@ -41,3 +42,18 @@ func CreateServiceClients(sess *session.Session) *ServiceClients {
 		RDS:        rds.New(sess),
 	}
 }
+
+// ServiceAvailable returns true if an AWS service is offered in a region. Calls to regional
+// services in regions without an endpoint fail, so scanners skip those regions.
+func ServiceAvailable(service, region string) bool {
+	partition, ok := endpoints.PartitionForRegion(endpoints.DefaultPartitions(), region)
+	if !ok {
+		return false
+	}
+	svc, ok := partition.Services()[service]
+	if !ok {
+		return false
+	}
+	_, ok = svc.Regions()[region]
+	return ok
+}
@@ -1,5 +1,7 @@
 package config
 
+import "time"
+
 // GlobalConfig holds the global configuration for the application
 type GlobalConfig struct {
 	// Profile is the AWS profile to use
@@ -11,6 +13,30 @@ type GlobalConfig struct {
 	// ScannerRole is the role name to assume for scanning operations
 	ScannerRole string
 
+	// STSRegion is the region of the regional STS endpoint used for role assumption
+	STSRegion string
+
+	// AccountsCacheTTL is how long accounts listed with the Organizations API are reused (0 disables the cache)
+	AccountsCacheTTL time.Duration
+
+	// RefreshAccounts lists the accounts again instead of using the cached account list
+	RefreshAccounts bool
+
+	// HTTPSProxy is the URL of the proxy of AWS requests (defaults to the HTTPS_PROXY environment variable)
+	HTTPSProxy string
+
+	// CABundle is a PEM file of certificates trusted in addition to the system roots
+	CABundle string
+
+	// RequestTimeout is the timeout of each AWS request (0 disables it)
+	RequestTimeout time.Duration
+
+	// UseFIPSEndpoints makes all AWS clients use FIPS endpoints
+	UseFIPSEndpoints bool
+
+	// AccountMappings maps account IDs to friendly names, teams and environments
+	AccountMappings map[string]AccountMapping
+
 	// MaxWorkers defines the maximum number of concurrent workers
 	MaxWorkers int
 
@@ -52,6 +78,271 @@ type GlobalConfig struct {
 
 	// ScanAccounts is the list of account IDs to scan
 	ScanAccounts []string
+
+	// ScanCountResources enables the Resource Groups Tagging API pre-pass before scanning
+	ScanCountResources bool
+
+	// ScanSkipEmptyRegions skips region/scanner combinations that contain no resources
+	ScanSkipEmptyRegions bool
+
+	// ScanExcludeASGInstances excludes Auto Scaling group members from EC2 idle detection
+	ScanExcludeASGInstances bool
+
+	// ScanExcludeSpotInstances excludes Spot instances from EC2 idle detection
+	ScanExcludeSpotInstances bool
+
+	// ScanBusinessHours is the business hours window used for utilization analysis
+	ScanBusinessHours string
+
+	// ScanBusinessHoursTimezone is the IANA timezone of the business hours window
+	ScanBusinessHoursTimezone string
+
+	// ScanSchedulingPlan is the path the instance scheduling plan is written to
+	ScanSchedulingPlan string
+
+	// ScanReportLanguage is the language of the HTML report
+	ScanReportLanguage string
+
+	// ScanCurrency is the ISO 4217 currency code costs are reported in
+	ScanCurrency string
+
+	// ScanExchangeRate is a static USD exchange rate for ScanCurrency
+	ScanExchangeRate float64
+
+	// ScanExchangeRateURL is a URL returning USD exchange rates as JSON
+	ScanExchangeRateURL string
+
+	// ScanExchangeRates is a static table of USD exchange rates keyed by currency code
+	ScanExchangeRates map[string]float64
+
+	// ScanCostOverrides holds multipliers applied to list prices keyed by resource type, service or "default"
+	ScanCostOverrides map[string]float64
+
+	// ScanMarketplaceRates holds hourly Marketplace software charges keyed by product code
+	ScanMarketplaceRates map[string]float64
+
+	// ScanAnnotationsFile is the path to the reviewer annotations file merged into reports
+	ScanAnnotationsFile string
+
+	// ScanSSOStartURL is the IAM Identity Center start URL used to create account sessions
+	ScanSSOStartURL string
+
+	// ScanSSORegion is the region of the IAM Identity Center instance
+	ScanSSORegion string
+
+	// ScanSSORoleName is the IAM Identity Center permission set used in each account
+	ScanSSORoleName string
+
+	// ScanRequireReadOnly aborts the scan unless the scan credentials are verified to be read-only
+	ScanRequireReadOnly bool
+
+	// ScanEnabledScanners limits the scanners run when no scanners are selected
+	ScanEnabledScanners []string
+
+	// ScanDisabledScanners are never run, even when selected
+	ScanDisabledScanners []string
+
+	// ScanAccountScannerExclusions lists the scanners never run in an account, keyed by account ID
+	ScanAccountScannerExclusions map[string][]string
+
+	// ScanAccountMaxWorkers overrides ScanMaxWorkersPerAccount per account, keyed by account ID
+	ScanAccountMaxWorkers map[string]int
+
+	// ScanScannerDaysUnused overrides ScanDaysUnused for individual scanners
+	ScanScannerDaysUnused map[string]int
+
+	// ScanTrimDetails trims result details and streams full details to per-account files
+	ScanTrimDetails bool
+
+	// ScanIAMLastAccessed confirms unused IAM roles with IAM access advisor service last accessed data
+	ScanIAMLastAccessed bool
+
+	// ScanAccessAnalyzer imports IAM Access Analyzer unused access findings into the IAM results
+	ScanAccessAnalyzer bool
+
+	// ScanReasonTemplates rewrite matching scanner reasons in the HTML report
+	ScanReasonTemplates []ReasonTemplate
+
+	// ScanOutputOverrides write the results of matching accounts to their own format and destination
+	ScanOutputOverrides []OutputOverride
+
+	// ScanMetricsExporters push the summary metrics of each scan to monitoring services
+	ScanMetricsExporters []MetricsExporter
+
+	// ScanManagedResources extends the built-in list of AWS-managed resources excluded from results
+	ScanManagedResources []ManagedResourceRule
+
+	// ScanEMRIdleHours is the number of hours an EMR cluster may wait without steps before it is reported
+	ScanEMRIdleHours int
+
+	// ScanSecurityHub publishes findings to AWS Security Hub as custom findings
+	ScanSecurityHub bool
+
+	// ScanSecurityHubRegion is the region findings are imported into
+	ScanSecurityHubRegion string
+
+	// ScanOpsCenter opens AWS Systems Manager OpsCenter OpsItems for severe findings
+	ScanOpsCenter bool
+
+	// ScanOpsCenterRegion is the region OpsItems are opened in
+	ScanOpsCenterRegion string
+
+	// ScanOpsCenterMinSeverity is the lowest finding severity OpsItems are opened for
+	ScanOpsCenterMinSeverity string
+
+	// ScanPrefetchPrices resolves distinct prices once in a pre-scan inventory pass
+	ScanPrefetchPrices bool
+
+	// ScanIncludeSuspendedAccounts scans organization accounts that are suspended or pending closure
+	ScanIncludeSuspendedAccounts bool
+
+	// ScanIncludeMetricSamples keeps the raw CloudWatch datapoints of findings in their details
+	ScanIncludeMetricSamples bool
+
+	// ScanConfirmationScans is the number of consecutive scans a finding must appear in before it is reported
+	ScanConfirmationScans int
+
+	// ScanIncludeManagedResources reports resources matching the managed resource exclusion list
+	ScanIncludeManagedResources bool
+
+	// ScanAccountSpend fetches month-to-date spend to show waste as a share of each account's spend
+	ScanAccountSpend bool
+
+	// ScanSpendSummaryFile is a CSV of month-to-date spend per account used instead of Cost Explorer
+	ScanSpendSummaryFile string
+
+	// ScanDelegatedAdmin lists organization accounts with the credentials of a delegated administrator account
+	ScanDelegatedAdmin bool
+
+	// ScanAccountsFile is the account list used when organization accounts cannot be listed
+	ScanAccountsFile string
+
+	// ScanDynamoDBTable is the DynamoDB table findings are written to
+	ScanDynamoDBTable string
+
+	// ScanDynamoDBRegion is the region of the DynamoDB table
+	ScanDynamoDBRegion string
+
+	// ScanMaxAPICalls is the API call budget of the scan (0 for no limit)
+	ScanMaxAPICalls int
+
+	// ScanReasonVerbosity is the detail of the reasons of findings (summary, normal or debug)
+	ScanReasonVerbosity string
+
+	// ScanAccountBucket is the template of the bucket each account's results are written to
+	ScanAccountBucket string
+
+	// ScanAccountPrefix is the template of the key prefix of each account's results
+	ScanAccountPrefix string
+
+	// ScanGracePeriodDays is the number of days recently created resources are not reported (0 to disable)
+	ScanGracePeriodDays int
+
+	// ScanMaxWorkersPerAccount is the number of scanner tasks run at the same time in an account (0 for no limit)
+	ScanMaxWorkersPerAccount int
+
+	// ScanExcludeWarmInstances excludes Auto Scaling warm pool and hibernated instances from EC2 idle detection
+	ScanExcludeWarmInstances bool
+
+	// ScanProvider is the cloud provider to scan (aws or gcp)
+	ScanProvider string
+
+	// ScanGCPProjects is the comma-separated list of GCP projects scanned with the gcp provider
+	ScanGCPProjects string
+
+	// ScanGCPCredentialsFile is the GCP credentials file used with the gcp provider
+	ScanGCPCredentialsFile string
+
+	// ScanComputeOptimizer imports AWS Compute Optimizer over-provisioning findings into the results
+	ScanComputeOptimizer bool
+
+	// ScanCostAllocationTags are the tag keys the estimated savings are grouped by in the output
+	ScanCostAllocationTags []string
+
+	// ScanPlanOnly writes the tasks the scan would run without scanning
+	ScanPlanOnly bool
+
+	// ScanPlanFile is the path the scan plan is written to
+	ScanPlanFile string
+
+	// ScanCSVPerAccount writes a CSV file per account instead of a combined one
+	ScanCSVPerAccount bool
+
+	// ScanEdgeZones prices EC2 instances and EBS volumes in Local Zones and Wavelength Zones by their zone
+	ScanEdgeZones bool
+
+	// ScanExcludeManagementAccount skips the management account of the organization
+	ScanExcludeManagementAccount bool
+
+	// ScanNotifySlackWebhook is the Slack incoming webhook URL the scan summary is posted to
+	ScanNotifySlackWebhook string
+
+	// ScanHistoryDir is the directory the metrics and findings of each scan are recorded in
+	ScanHistoryDir string
+
+	// ScanPrometheusFile is the file the scan metrics are written to in Prometheus text format
+	ScanPrometheusFile string
+
+	// ScanPrometheusPushgatewayURL is the Pushgateway the scan metrics are pushed to
+	ScanPrometheusPushgatewayURL string
+
+	// ScanPrometheusListenAddress is the address the metrics of the running scan are served on
+	ScanPrometheusListenAddress string
+
+	// ScanCompareTo is the previous scan results the HTML report compares the findings with
+	ScanCompareTo string
+
+	// ScanGCPImpersonateServiceAccount is the GCP service account impersonated with the credentials
+	ScanGCPImpersonateServiceAccount string
+}
+
+// AccountMapping overrides how an account is displayed and grouped in outputs
+type AccountMapping struct {
+	Name        string `mapstructure:"name"`        // Friendly name replacing the Organizations account name
+	Team        string `mapstructure:"team"`        // Team that owns the account
+	Environment string `mapstructure:"environment"` // Environment of the account, e.g. prod or dev
+}
+
+// ReasonTemplate rewrites the scanner reasons matching an expression in the HTML report
+type ReasonTemplate struct {
+	ResourceType string `mapstructure:"resource_type"` // Only rewrite reasons of this resource type; optional
+	Match        string `mapstructure:"match"`         // Regular expression matched against each reason line
+	Template     string `mapstructure:"template"`      // Go text/template rendering the replacement line
+}
+
+// OutputOverride writes the results of the accounts it matches in its own format and to its
+// own destination instead of the scan output. Accounts match by ID or by an organizational unit
+// they are in, directly or nested; the first matching override applies.
+type OutputOverride struct {
+	Accounts            []string `mapstructure:"accounts"`             // Account IDs
+	OrganizationalUnits []string `mapstructure:"organizational_units"` // Organizational unit IDs, e.g. ou-ab12-34cd56ef
+	Format              string   `mapstructure:"format"`               // json, html, csv or markdown; defaults to the scan output format
+	Output              string   `mapstructure:"output"`               // filesystem or s3
+	Directory           string   `mapstructure:"directory"`            // Directory of filesystem output
+	Bucket              string   `mapstructure:"bucket"`               // Bucket of s3 output
+	BucketRegion        string   `mapstructure:"bucket_region"`        // Region of the bucket; looked up if empty
+	Prefix              string   `mapstructure:"prefix"`               // Key prefix of s3 output
+}
+
+// MetricsExporter pushes the findings, estimated savings and run metrics of each scan to a
+// monitoring service
+type MetricsExporter struct {
+	Type      string   `mapstructure:"type"`        // datadog or newrelic
+	APIKeyEnv string   `mapstructure:"api_key_env"` // Environment variable holding the API key; DD_API_KEY or NEW_RELIC_LICENSE_KEY by default
+	Site      string   `mapstructure:"site"`        // Datadog site, e.g. datadoghq.eu; defaults to datadoghq.com
+	Region    string   `mapstructure:"region"`      // New Relic region, us or eu; defaults to us
+	URL       string   `mapstructure:"url"`         // API URL replacing the one of the site or region, e.g. of a proxy
+	Tags      []string `mapstructure:"tags"`        // Tags in key:value form added to every metric
+}
+
+// ManagedResourceRule matches resources created and managed by AWS, which are excluded from
+// results. Patterns are case-insensitive and may contain * wildcards; all set fields must match.
+type ManagedResourceRule struct {
+	ResourceType string            `mapstructure:"resource_type"` // Scanner label the rule applies to; optional
+	Name         string            `mapstructure:"name"`          // Pattern matched against the resource name
+	ID           string            `mapstructure:"id"`            // Pattern matched against the resource ID
+	Details      map[string]string `mapstructure:"details"`       // Patterns matched against result details
+	Description  string            `mapstructure:"description"`   // Why the resources are managed by AWS
 }
 
 // Config is the global configuration instance
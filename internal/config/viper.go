@@ -25,19 +25,86 @@ func getParameterSource(key string, cmd *cobra.Command) parameterSource {
 
 	// Map config keys to flag names
 	flagNames := map[string]string{
-		"aws.profile":           "profile",
-		"aws.organization_role": "organization-role",
-		"aws.scanner_role":      "scanner-role",
-		"app.max_workers":       "max-workers",
-		"app.log_format":        "log-format",
-		"app.log_level":         "log-level",
-		"scan.regions":          "regions",
-		"scan.scanners":         "scanners",
-		"scan.output":           "output",
-		"scan.output_format":    "output-format",
-		"scan.bucket":           "bucket",
-		"scan.bucket_region":    "bucket-region",
-		"scan.days_unused":      "days-unused",
+		"aws.profile":                          "profile",
+		"aws.organization_role":                "organization-role",
+		"aws.scanner_role":                     "scanner-role",
+		"aws.sts_region":                       "sts-region",
+		"aws.accounts_cache_ttl":               "accounts-cache-ttl",
+		"aws.https_proxy":                      "https-proxy",
+		"aws.ca_bundle":                        "ca-bundle",
+		"aws.request_timeout":                  "request-timeout",
+		"aws.use_fips_endpoints":               "use-fips-endpoints",
+		"app.max_workers":                      "max-workers",
+		"app.log_format":                       "log-format",
+		"app.log_level":                        "log-level",
+		"scan.regions":                         "regions",
+		"scan.scanners":                        "scanners",
+		"scan.output":                          "output",
+		"scan.output_format":                   "output-format",
+		"scan.bucket":                          "bucket",
+		"scan.bucket_region":                   "bucket-region",
+		"scan.days_unused":                     "days-unused",
+		"scan.count_resources":                 "count-resources",
+		"scan.skip_empty_regions":              "skip-empty-regions",
+		"scan.exclude_asg_instances":           "exclude-asg-instances",
+		"scan.exclude_spot_instances":          "exclude-spot-instances",
+		"scan.business_hours":                  "business-hours",
+		"scan.business_hours_timezone":         "business-hours-timezone",
+		"scan.scheduling_plan":                 "scheduling-plan",
+		"scan.report_language":                 "report-language",
+		"scan.currency":                        "currency",
+		"scan.exchange_rate":                   "exchange-rate",
+		"scan.exchange_rate_url":               "exchange-rate-url",
+		"scan.annotations_file":                "annotations-file",
+		"scan.sso_start_url":                   "sso-start-url",
+		"scan.sso_region":                      "sso-region",
+		"scan.sso_role_name":                   "sso-role-name",
+		"scan.require_read_only":               "require-read-only",
+		"scan.scanner_days_unused":             "scanner-days-unused",
+		"scan.trim_details":                    "trim-details",
+		"scan.iam_last_accessed":               "iam-last-accessed",
+		"scan.access_analyzer":                 "access-analyzer",
+		"scan.emr_idle_hours":                  "emr-idle-hours",
+		"scan.security_hub":                    "security-hub",
+		"scan.security_hub_region":             "security-hub-region",
+		"scan.ops_center":                      "ops-center",
+		"scan.ops_center_region":               "ops-center-region",
+		"scan.ops_center_min_severity":         "ops-center-min-severity",
+		"scan.prefetch_prices":                 "prefetch-prices",
+		"scan.include_suspended_accounts":      "include-suspended-accounts",
+		"scan.include_metric_samples":          "include-metric-samples",
+		"scan.confirmation_scans":              "confirmation-scans",
+		"scan.include_managed_resources":       "include-managed-resources",
+		"scan.account_spend":                   "account-spend",
+		"scan.spend_summary_file":              "spend-summary-file",
+		"scan.delegated_admin":                 "delegated-admin",
+		"scan.accounts_file":                   "accounts-file",
+		"scan.dynamodb_table":                  "dynamodb-table",
+		"scan.dynamodb_region":                 "dynamodb-region",
+		"scan.max_api_calls":                   "max-api-calls",
+		"scan.reason_verbosity":                "reason-verbosity",
+		"scan.account_bucket":                  "account-bucket",
+		"scan.account_prefix":                  "account-prefix",
+		"scan.grace_period_days":               "grace-period-days",
+		"scan.max_workers_per_account":         "max-workers-per-account",
+		"scan.exclude_warm_instances":          "exclude-warm-instances",
+		"scan.provider":                        "provider",
+		"scan.gcp_projects":                    "gcp-projects",
+		"scan.gcp_credentials_file":            "gcp-credentials-file",
+		"scan.compute_optimizer":               "compute-optimizer",
+		"scan.cost_allocation_tags":            "cost-allocation-tags",
+		"scan.plan_only":                       "plan-only",
+		"scan.plan_file":                       "plan-file",
+		"scan.csv_per_account":                 "csv-per-account",
+		"scan.edge_zones":                      "edge-zones",
+		"scan.exclude_management_account":      "exclude-management-account",
+		"scan.notify_slack_webhook":            "notify-slack-webhook",
+		"scan.history_dir":                     "history-dir",
+		"scan.prometheus_file":                 "prometheus-file",
+		"scan.prometheus_pushgateway_url":      "prometheus-pushgateway-url",
+		"scan.prometheus_listen_address":       "prometheus-listen-address",
+		"scan.compare_to":                      "compare-to",
+		"scan.gcp_impersonate_service_account": "gcp-impersonate-service-account",
 	}
 
 	// Get the flag name from the map, or convert the key if not found
@@ -91,6 +158,12 @@ func LogConfigurationSources(shouldLog bool, cmd *cobra.Command) {
 		"aws.profile",
 		"aws.organization_role",
 		"aws.scanner_role",
+		"aws.sts_region",
+		"aws.accounts_cache_ttl",
+		"aws.https_proxy",
+		"aws.ca_bundle",
+		"aws.request_timeout",
+		"aws.use_fips_endpoints",
 		"app.max_workers",
 		"app.log_format",
 		"app.log_level",
@@ -101,6 +174,67 @@ func LogConfigurationSources(shouldLog bool, cmd *cobra.Command) {
 		"scan.bucket",
 		"scan.bucket_region",
 		"scan.days_unused",
+		"scan.count_resources",
+		"scan.skip_empty_regions",
+		"scan.exclude_asg_instances",
+		"scan.exclude_spot_instances",
+		"scan.business_hours",
+		"scan.business_hours_timezone",
+		"scan.scheduling_plan",
+		"scan.report_language",
+		"scan.currency",
+		"scan.exchange_rate",
+		"scan.exchange_rate_url",
+		"scan.annotations_file",
+		"scan.sso_start_url",
+		"scan.sso_region",
+		"scan.sso_role_name",
+		"scan.require_read_only",
+		"scan.scanner_days_unused",
+		"scan.trim_details",
+		"scan.iam_last_accessed",
+		"scan.access_analyzer",
+		"scan.emr_idle_hours",
+		"scan.security_hub",
+		"scan.security_hub_region",
+		"scan.ops_center",
+		"scan.ops_center_region",
+		"scan.ops_center_min_severity",
+		"scan.prefetch_prices",
+		"scan.include_suspended_accounts",
+		"scan.include_metric_samples",
+		"scan.confirmation_scans",
+		"scan.include_managed_resources",
+		"scan.account_spend",
+		"scan.spend_summary_file",
+		"scan.delegated_admin",
+		"scan.accounts_file",
+		"scan.dynamodb_table",
+		"scan.dynamodb_region",
+		"scan.max_api_calls",
+		"scan.reason_verbosity",
+		"scan.account_bucket",
+		"scan.account_prefix",
+		"scan.grace_period_days",
+		"scan.max_workers_per_account",
+		"scan.exclude_warm_instances",
+		"scan.provider",
+		"scan.gcp_projects",
+		"scan.gcp_credentials_file",
+		"scan.compute_optimizer",
+		"scan.cost_allocation_tags",
+		"scan.plan_only",
+		"scan.plan_file",
+		"scan.csv_per_account",
+		"scan.edge_zones",
+		"scan.exclude_management_account",
+		"scan.notify_slack_webhook",
+		"scan.history_dir",
+		"scan.prometheus_file",
+		"scan.prometheus_pushgateway_url",
+		"scan.prometheus_listen_address",
+		"scan.compare_to",
+		"scan.gcp_impersonate_service_account",
 	}
 
 	// Log the source of each parameter
@@ -128,6 +262,12 @@ func InitConfig(shouldLog bool, cmd *cobra.Command) error {
 	viper.SetDefault("aws.profile", "default")
 	viper.SetDefault("aws.organization_role", "")
 	viper.SetDefault("aws.scanner_role", "")
+	viper.SetDefault("aws.sts_region", "")
+	viper.SetDefault("aws.accounts_cache_ttl", "1h")
+	viper.SetDefault("aws.https_proxy", "")
+	viper.SetDefault("aws.ca_bundle", "")
+	viper.SetDefault("aws.request_timeout", "25s")
+	viper.SetDefault("aws.use_fips_endpoints", false)
 	viper.SetDefault("app.max_workers", 8)
 	viper.SetDefault("app.log_format", "text")
 	viper.SetDefault("app.log_level", "INFO")
@@ -138,6 +278,67 @@ func InitConfig(shouldLog bool, cmd *cobra.Command) error {
 	viper.SetDefault("scan.bucket", "")
 	viper.SetDefault("scan.bucket_region", "")
 	viper.SetDefault("scan.days_unused", 90)
+	viper.SetDefault("scan.count_resources", false)
+	viper.SetDefault("scan.skip_empty_regions", false)
+	viper.SetDefault("scan.exclude_asg_instances", false)
+	viper.SetDefault("scan.exclude_spot_instances", false)
+	viper.SetDefault("scan.business_hours", "")
+	viper.SetDefault("scan.business_hours_timezone", "UTC")
+	viper.SetDefault("scan.scheduling_plan", "")
+	viper.SetDefault("scan.report_language", "en")
+	viper.SetDefault("scan.currency", "USD")
+	viper.SetDefault("scan.exchange_rate", 0)
+	viper.SetDefault("scan.exchange_rate_url", "")
+	viper.SetDefault("scan.annotations_file", "")
+	viper.SetDefault("scan.sso_start_url", "")
+	viper.SetDefault("scan.sso_region", "us-east-1")
+	viper.SetDefault("scan.sso_role_name", "")
+	viper.SetDefault("scan.require_read_only", false)
+	viper.SetDefault("scan.scanner_days_unused", "")
+	viper.SetDefault("scan.trim_details", false)
+	viper.SetDefault("scan.iam_last_accessed", false)
+	viper.SetDefault("scan.access_analyzer", false)
+	viper.SetDefault("scan.emr_idle_hours", 4)
+	viper.SetDefault("scan.security_hub", false)
+	viper.SetDefault("scan.security_hub_region", "us-east-1")
+	viper.SetDefault("scan.ops_center", false)
+	viper.SetDefault("scan.ops_center_region", "us-east-1")
+	viper.SetDefault("scan.ops_center_min_severity", "HIGH")
+	viper.SetDefault("scan.prefetch_prices", false)
+	viper.SetDefault("scan.include_suspended_accounts", false)
+	viper.SetDefault("scan.include_metric_samples", false)
+	viper.SetDefault("scan.confirmation_scans", 1)
+	viper.SetDefault("scan.include_managed_resources", false)
+	viper.SetDefault("scan.account_spend", false)
+	viper.SetDefault("scan.spend_summary_file", "")
+	viper.SetDefault("scan.delegated_admin", false)
+	viper.SetDefault("scan.accounts_file", "")
+	viper.SetDefault("scan.dynamodb_table", "")
+	viper.SetDefault("scan.dynamodb_region", "us-east-1")
+	viper.SetDefault("scan.max_api_calls", 0)
+	viper.SetDefault("scan.reason_verbosity", "normal")
+	viper.SetDefault("scan.account_bucket", "")
+	viper.SetDefault("scan.account_prefix", "")
+	viper.SetDefault("scan.grace_period_days", 0)
+	viper.SetDefault("scan.max_workers_per_account", 0)
+	viper.SetDefault("scan.exclude_warm_instances", false)
+	viper.SetDefault("scan.provider", "aws")
+	viper.SetDefault("scan.gcp_projects", "")
+	viper.SetDefault("scan.gcp_credentials_file", "")
+	viper.SetDefault("scan.compute_optimizer", false)
+	viper.SetDefault("scan.cost_allocation_tags", "")
+	viper.SetDefault("scan.plan_only", false)
+	viper.SetDefault("scan.plan_file", "scan-plan.json")
+	viper.SetDefault("scan.csv_per_account", false)
+	viper.SetDefault("scan.edge_zones", false)
+	viper.SetDefault("scan.exclude_management_account", false)
+	viper.SetDefault("scan.notify_slack_webhook", "")
+	viper.SetDefault("scan.history_dir", "")
+	viper.SetDefault("scan.prometheus_file", "")
+	viper.SetDefault("scan.prometheus_pushgateway_url", "")
+	viper.SetDefault("scan.prometheus_listen_address", "")
+	viper.SetDefault("scan.compare_to", "")
+	viper.SetDefault("scan.gcp_impersonate_service_account", "")
 
 	// Try to read config file but don't error if not found
 	if err := viper.ReadInConfig(); err != nil {
@@ -192,6 +393,17 @@ aws:
   profile: default  # AWS profile to use (supports SSO profiles)
   organization_role: ""  # Role name to assume for organization-wide operations
   scanner_role: ""  # Role name to assume for scanning operations
+  sts_region: ""  # Region of the regional STS endpoint used for role assumption (defaults to the session region)
+  accounts_cache_ttl: 1h  # How long the organization account list is cached in the cache directory (0 disables the cache)
+  https_proxy: ""  # Proxy URL of AWS requests (defaults to the HTTPS_PROXY environment variable)
+  ca_bundle: ""  # PEM file of certificates trusted in addition to the system roots, e.g. of a TLS intercepting proxy
+  request_timeout: 25s  # Timeout of each AWS request (0 disables it)
+  use_fips_endpoints: false  # Use FIPS endpoints for all AWS requests (US, Canada and GovCloud regions)
+  account_mappings:  # Friendly names, teams and environments by account ID, overriding Organizations names
+    # "123456789012":
+    #   name: payments-prod
+    #   team: payments
+    #   environment: prod
 
 # Application Configuration
 app:
@@ -201,7 +413,7 @@ app:
 
 # Scan Command Configuration
 scan:
-  # List of regions to scan (default: all available regions)
+  # List of regions to scan, or [all-enabled] (default: all enabled regions)
   regions:
     - us-west-2  # Example region
     - us-east-1  # Example region
@@ -210,10 +422,70 @@ scan:
     - ec2-instances  # Example scanner
     - ebs-volumes   # Example scanner
   output: filesystem  # Output type (filesystem or s3)
-  output_format: html  # Output format (json or html)
+  output_format: html  # Output format (json, html, csv or markdown)
   bucket: ""  # S3 bucket name (required when output=s3)
   bucket_region: ""  # S3 bucket region (required when output=s3)
   days_unused: 90  # Number of days a resource must be unused to be reported
+  count_resources: false  # Count resources with the tagging API before scanning
+  skip_empty_regions: false  # Skip region/scanner combinations that contain no resources
+  exclude_asg_instances: false  # Exclude instances managed by Auto Scaling groups from EC2 idle detection
+  exclude_spot_instances: false  # Exclude Spot instances, including Spot fleet members, from EC2 idle detection
+  business_hours: ""  # Business hours window for utilization analysis, e.g. "Mon-Fri 08:00-18:00" (empty disables)
+  business_hours_timezone: UTC  # IANA timezone of the business hours window
+  scheduling_plan: ""  # Path to write an AWS Instance Scheduler compatible scheduling plan (requires business_hours)
+  report_language: en  # Language of the HTML report (en, de, fr)
+  currency: USD  # Currency to report costs in (e.g. EUR); costs are converted from USD
+  exchange_rate: 0  # Static USD exchange rate for currency (0 uses exchange_rate_url or exchange_rates)
+  exchange_rate_url: ""  # URL returning USD exchange rates as JSON, e.g. {"rates": {"EUR": 0.92}}
+  annotations_file: ""  # Path to a YAML or JSON file of reviewer annotations keyed by resource ID
+  sso_start_url: ""  # IAM Identity Center start URL (sign in first with cloudsift login)
+  sso_region: us-east-1  # Region of the IAM Identity Center instance
+  sso_role_name: ""  # Permission set used in each account (requires sso_start_url)
+  require_read_only: false  # Abort the scan unless the scan credentials are verified to be read-only
+  trim_details: false  # Trim result details to reduce memory use; full details are written to output/details
+  iam_last_accessed: false  # Confirm unused IAM roles with IAM access advisor service last accessed data
+  access_analyzer: false  # Import IAM Access Analyzer unused access findings into the IAM results
+  emr_idle_hours: 4  # Hours an EMR cluster may sit in WAITING without steps before it is reported
+  security_hub: false  # Publish findings to AWS Security Hub as custom findings (ASFF)
+  security_hub_region: us-east-1  # Region Security Hub findings are imported into
+  ops_center: false  # Open AWS Systems Manager OpsCenter OpsItems for severe findings
+  ops_center_region: us-east-1  # Region OpsCenter OpsItems are opened in
+  ops_center_min_severity: HIGH  # Lowest finding severity OpsItems are opened for (INFORMATIONAL, LOW, MEDIUM, HIGH)
+  prefetch_prices: false  # Resolve distinct prices once in a pre-scan inventory pass across all accounts
+  include_suspended_accounts: false  # Scan organization accounts that are suspended or pending closure instead of skipping them
+  include_metric_samples: false  # Keep the raw CloudWatch datapoints behind each finding in its details
+  confirmation_scans: 1  # Consecutive scans a finding must appear in before it is reported (requires history_dir)
+  include_managed_resources: false  # Report AWS-managed resources excluded by default (service-linked roles, default VPCs, ...)
+  account_spend: false  # Show identified waste as a percentage of each account's month-to-date spend from Cost Explorer
+  spend_summary_file: ""  # CSV of month-to-date spend per account (e.g. a CUR summary) used instead of Cost Explorer
+  delegated_admin: false  # List organization accounts with the credentials of a delegated administrator account (requires scanner_role)
+  accounts_file: ""  # Account list (local path or s3://bucket/key) used when organization accounts cannot be listed
+  dynamodb_table: ""  # DynamoDB table to write each finding to, keyed by pk (account ID) and sk (resource ID#scan time)
+  dynamodb_region: us-east-1  # Region of the DynamoDB table
+  max_api_calls: 0  # Maximum number of AWS API calls of the scan (0 for no limit)
+  reason_verbosity: normal  # Detail of the reasons of findings: summary, normal or debug
+  account_bucket: ""  # Template of the bucket each account's results are written to with output=s3-account
+  account_prefix: ""  # Template of the key prefix of each account's results with output=s3-account
+  grace_period_days: 0  # Never report resources created within this many days (0 to disable)
+  max_workers_per_account: 0  # Maximum number of scanner tasks run at the same time in an account (0 for no limit)
+  exclude_warm_instances: false  # Exclude Auto Scaling warm pool and hibernated instances from EC2 idle detection
+  provider: aws  # Cloud provider to scan (aws, gcp)
+  gcp_projects: ""  # Comma-separated GCP project IDs scanned with provider gcp
+  gcp_credentials_file: ""  # GCP credentials file (default: application default credentials)
+  compute_optimizer: false  # Import AWS Compute Optimizer over-provisioning findings into the results
+  cost_allocation_tags: []  # Tag keys the estimated savings are grouped by for chargeback, e.g. [CostCenter, Team]
+  plan_only: false  # Write the tasks the scan would run to plan_file without scanning
+  plan_file: "scan-plan.json"  # Path the scan plan of plan_only is written to
+  csv_per_account: false  # Write a CSV file per account with output_format csv
+  edge_zones: false  # Price EC2 instances and EBS volumes in Local Zones and Wavelength Zones by their zone
+  exclude_management_account: false  # Skip the management account of the organization
+  notify_slack_webhook: ""  # Slack incoming webhook URL the scan summary is posted to
+  history_dir: ""  # Directory recording the metrics and findings of each scan, listed and compared by cloudsift history
+  prometheus_file: ""  # File the findings, savings and scan metrics are written to in Prometheus text format (.prom)
+  prometheus_pushgateway_url: ""  # Prometheus Pushgateway URL the scan metrics are pushed to under job cloudsift
+  prometheus_listen_address: ""  # Address /metrics of the running scan is served on in Prometheus text format, e.g. :9101
+  compare_to: ""  # JSON results of a previous scan (file or directory) the HTML report compares the findings with
+  gcp_impersonate_service_account: ""  # GCP service account to impersonate with the credentials
 `)
 		if err := os.WriteFile(configPath, defaultConfig, 0644); err != nil {
 			return fmt.Errorf("error writing default config file: %w", err)
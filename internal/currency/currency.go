@@ -0,0 +1,153 @@
+package currency
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"cloudsift/internal/aws"
+	"cloudsift/internal/logging"
+)
+
+// BaseCurrency is the currency of all prices returned by the AWS Pricing API
+const BaseCurrency = "USD"
+
+// symbols maps ISO 4217 currency codes to their display symbols. Currencies without a
+// symbol are displayed using their code.
+var symbols = map[string]string{
+	"USD": "$",
+	"EUR": "€",
+	"GBP": "£",
+	"JPY": "¥",
+	"CHF": "CHF",
+	"CAD": "CA$",
+	"AUD": "A$",
+	"INR": "₹",
+}
+
+// Symbol returns the display symbol for a currency code
+func Symbol(code string) string {
+	if symbol, ok := symbols[strings.ToUpper(code)]; ok {
+		return symbol
+	}
+	return strings.ToUpper(code)
+}
+
+// RateProvider returns the exchange rate used to convert an amount in BaseCurrency to another currency
+type RateProvider interface {
+	Rate(currency string) (float64, error)
+}
+
+// StaticRateProvider returns exchange rates from a fixed table, e.g. rates set in the config file
+type StaticRateProvider map[string]float64
+
+// Rate implements RateProvider
+func (p StaticRateProvider) Rate(currency string) (float64, error) {
+	currency = strings.ToUpper(currency)
+	for code, rate := range p {
+		if strings.ToUpper(code) == currency {
+			return rate, nil
+		}
+	}
+	return 0, fmt.Errorf("no exchange rate configured for %s", currency)
+}
+
+// HTTPRateProvider fetches exchange rates from an HTTP endpoint that returns JSON of the
+// form {"rates": {"EUR": 0.92, ...}} with rates relative to BaseCurrency
+type HTTPRateProvider struct {
+	URL    string
+	Client *http.Client
+}
+
+// Rate implements RateProvider
+func (p *HTTPRateProvider) Rate(currency string) (float64, error) {
+	client := p.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	resp, err := client.Get(p.URL)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch exchange rates: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("failed to fetch exchange rates: unexpected status %s", resp.Status)
+	}
+
+	var body struct {
+		Rates map[string]float64 `json:"rates"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return 0, fmt.Errorf("failed to decode exchange rates: %w", err)
+	}
+
+	return StaticRateProvider(body.Rates).Rate(currency)
+}
+
+// Converter converts costs from BaseCurrency into a target currency
+type Converter struct {
+	Currency string
+	Rate     float64
+}
+
+// NewConverter creates a converter for the target currency using the given provider.
+// Converting to BaseCurrency always uses a rate of 1.
+func NewConverter(currency string, provider RateProvider) (*Converter, error) {
+	currency = strings.ToUpper(currency)
+	if currency == "" || currency == BaseCurrency {
+		return &Converter{Currency: BaseCurrency, Rate: 1}, nil
+	}
+	if provider == nil {
+		return nil, fmt.Errorf("no exchange rate provider configured for %s", currency)
+	}
+
+	rate, err := provider.Rate(currency)
+	if err != nil {
+		return nil, err
+	}
+	if rate <= 0 {
+		return nil, fmt.Errorf("invalid exchange rate for %s: %v", currency, rate)
+	}
+
+	logging.Info("Using exchange rate", map[string]interface{}{
+		"from": BaseCurrency,
+		"to":   currency,
+		"rate": rate,
+	})
+
+	return &Converter{Currency: currency, Rate: rate}, nil
+}
+
+// Symbol returns the display symbol of the target currency
+func (c *Converter) Symbol() string {
+	return Symbol(c.Currency)
+}
+
+// ConvertResults converts the cost breakdowns of the scan results in place
+func (c *Converter) ConvertResults(results aws.ScanResults) {
+	if c.Rate == 1 {
+		return
+	}
+	for _, result := range results {
+		for _, cost := range result.Cost {
+			if breakdown, ok := cost.(*aws.CostBreakdown); ok && breakdown != nil {
+				c.convertBreakdown(breakdown)
+			}
+		}
+	}
+}
+
+func (c *Converter) convertBreakdown(breakdown *aws.CostBreakdown) {
+	breakdown.HourlyRate *= c.Rate
+	breakdown.DailyRate *= c.Rate
+	breakdown.MonthlyRate *= c.Rate
+	breakdown.YearlyRate *= c.Rate
+	if breakdown.Lifetime != nil {
+		lifetime := *breakdown.Lifetime * c.Rate
+		breakdown.Lifetime = &lifetime
+	}
+}
@@ -0,0 +1,260 @@
+package gcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Default endpoints of the APIs scanners call
+const (
+	DefaultComputeEndpoint    = "https://compute.googleapis.com/compute/v1"
+	DefaultMonitoringEndpoint = "https://monitoring.googleapis.com/v3"
+)
+
+// APIError is an error response of a GCP API
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("GCP API error (status %d): %s", e.StatusCode, e.Message)
+}
+
+// apiError returns the error of a failed response, with the message of its error body if
+// it has one
+func apiError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	var parsed struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	message := strings.TrimSpace(string(body))
+	if err := json.Unmarshal(body, &parsed); err == nil {
+		if parsed.Error.Message != "" {
+			message = parsed.Error.Message
+		} else if parsed.ErrorDescription != "" {
+			message = parsed.ErrorDescription
+		}
+	}
+	return &APIError{StatusCode: resp.StatusCode, Message: message}
+}
+
+// Client calls the GCP REST APIs with the access tokens of a set of credentials
+type Client struct {
+	HTTPClient         *http.Client
+	Credentials        *Credentials
+	ComputeEndpoint    string
+	MonitoringEndpoint string
+}
+
+// NewClient creates a client calling the default GCP endpoints
+func NewClient(creds *Credentials) *Client {
+	return &Client{
+		HTTPClient:         &http.Client{Timeout: 60 * time.Second},
+		Credentials:        creds,
+		ComputeEndpoint:    DefaultComputeEndpoint,
+		MonitoringEndpoint: DefaultMonitoringEndpoint,
+	}
+}
+
+// get sends an authorized GET request and decodes the JSON response into out
+func (c *Client) get(ctx context.Context, requestURL string, out interface{}) error {
+	token, err := c.Credentials.Token()
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return apiError(resp)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response of %s: %w", req.URL.Path, err)
+	}
+	return nil
+}
+
+// aggregatedList returns the resources of a Compute Engine aggregated list across all zones
+// or regions of a project, e.g. the disks under the "disks" key of each zone
+func aggregatedList[T any](ctx context.Context, c *Client, project, collection string) ([]T, error) {
+	var resources []T
+	pageToken := ""
+	for {
+		query := url.Values{"maxResults": {"500"}, "returnPartialSuccess": {"true"}}
+		if pageToken != "" {
+			query.Set("pageToken", pageToken)
+		}
+		requestURL := fmt.Sprintf("%s/projects/%s/aggregated/%s?%s", c.ComputeEndpoint, url.PathEscape(project), collection, query.Encode())
+
+		var page struct {
+			Items         map[string]map[string]json.RawMessage `json:"items"`
+			NextPageToken string                                `json:"nextPageToken"`
+		}
+		if err := c.get(ctx, requestURL, &page); err != nil {
+			return nil, fmt.Errorf("failed to list %s of project %s: %w", collection, project, err)
+		}
+		for scope, items := range page.Items {
+			raw, ok := items[collection]
+			if !ok {
+				continue // Scopes without resources only carry a warning
+			}
+			var scoped []T
+			if err := json.Unmarshal(raw, &scoped); err != nil {
+				return nil, fmt.Errorf("failed to decode %s of %s: %w", collection, scope, err)
+			}
+			resources = append(resources, scoped...)
+		}
+
+		if page.NextPageToken == "" {
+			return resources, nil
+		}
+		pageToken = page.NextPageToken
+	}
+}
+
+// Disk is a Compute Engine persistent disk
+type Disk struct {
+	ID                  string            `json:"id"`
+	Name                string            `json:"name"`
+	Zone                string            `json:"zone"`
+	Type                string            `json:"type"`
+	SizeGB              string            `json:"sizeGb"`
+	Status              string            `json:"status"`
+	Users               []string          `json:"users"`
+	Labels              map[string]string `json:"labels"`
+	SelfLink            string            `json:"selfLink"`
+	CreationTimestamp   string            `json:"creationTimestamp"`
+	LastAttachTimestamp string            `json:"lastAttachTimestamp"`
+	LastDetachTimestamp string            `json:"lastDetachTimestamp"`
+}
+
+// AttachedDisk is a disk attached to a Compute Engine instance
+type AttachedDisk struct {
+	Source     string `json:"source"`
+	DeviceName string `json:"deviceName"`
+	Boot       bool   `json:"boot"`
+	DiskSizeGB string `json:"diskSizeGb"`
+}
+
+// Instance is a Compute Engine instance
+type Instance struct {
+	ID                 string            `json:"id"`
+	Name               string            `json:"name"`
+	Zone               string            `json:"zone"`
+	MachineType        string            `json:"machineType"`
+	Status             string            `json:"status"`
+	Labels             map[string]string `json:"labels"`
+	Disks              []AttachedDisk    `json:"disks"`
+	CreationTimestamp  string            `json:"creationTimestamp"`
+	LastStartTimestamp string            `json:"lastStartTimestamp"`
+	LastStopTimestamp  string            `json:"lastStopTimestamp"`
+}
+
+// Address is a reserved Compute Engine IP address
+type Address struct {
+	ID                string            `json:"id"`
+	Name              string            `json:"name"`
+	Region            string            `json:"region"`
+	Address           string            `json:"address"`
+	AddressType       string            `json:"addressType"`
+	Status            string            `json:"status"`
+	Users             []string          `json:"users"`
+	Labels            map[string]string `json:"labels"`
+	CreationTimestamp string            `json:"creationTimestamp"`
+}
+
+// Disks returns the persistent disks of all zones of a project
+func (c *Client) Disks(ctx context.Context, project string) ([]Disk, error) {
+	return aggregatedList[Disk](ctx, c, project, "disks")
+}
+
+// Instances returns the instances of all zones of a project
+func (c *Client) Instances(ctx context.Context, project string) ([]Instance, error) {
+	return aggregatedList[Instance](ctx, c, project, "instances")
+}
+
+// Addresses returns the regional IP addresses of a project
+func (c *Client) Addresses(ctx context.Context, project string) ([]Address, error) {
+	return aggregatedList[Address](ctx, c, project, "addresses")
+}
+
+// AverageCPUUtilization returns the average CPU utilization in percent of an instance between
+// start and end, and false if Cloud Monitoring has no datapoints for it
+func (c *Client) AverageCPUUtilization(ctx context.Context, project, instanceID string, start, end time.Time) (float64, bool, error) {
+	query := url.Values{
+		"filter":                         {fmt.Sprintf(`metric.type="compute.googleapis.com/instance/cpu/utilization" AND resource.labels.instance_id="%s"`, instanceID)},
+		"interval.startTime":             {start.UTC().Format(time.RFC3339)},
+		"interval.endTime":               {end.UTC().Format(time.RFC3339)},
+		"aggregation.alignmentPeriod":    {"86400s"},
+		"aggregation.perSeriesAligner":   {"ALIGN_MEAN"},
+		"aggregation.crossSeriesReducer": {"REDUCE_MEAN"},
+	}
+	requestURL := fmt.Sprintf("%s/projects/%s/timeSeries?%s", c.MonitoringEndpoint, url.PathEscape(project), query.Encode())
+
+	var resp struct {
+		TimeSeries []struct {
+			Points []struct {
+				Value struct {
+					DoubleValue float64 `json:"doubleValue"`
+				} `json:"value"`
+			} `json:"points"`
+		} `json:"timeSeries"`
+	}
+	if err := c.get(ctx, requestURL, &resp); err != nil {
+		return 0, false, fmt.Errorf("failed to get CPU utilization of instance %s: %w", instanceID, err)
+	}
+
+	var sum float64
+	var count int
+	for _, series := range resp.TimeSeries {
+		for _, point := range series.Points {
+			sum += point.Value.DoubleValue
+			count++
+		}
+	}
+	if count == 0 {
+		return 0, false, nil
+	}
+	return sum / float64(count) * 100, true, nil
+}
+
+// ResourceName returns the last segment of a resource URL, e.g. the zone name of a zone URL
+func ResourceName(resourceURL string) string {
+	return resourceURL[strings.LastIndex(resourceURL, "/")+1:]
+}
+
+// ZoneRegion returns the region of a zone, e.g. us-central1 for us-central1-a
+func ZoneRegion(zone string) string {
+	if i := strings.LastIndex(zone, "-"); i > 0 {
+		return zone[:i]
+	}
+	return zone
+}
+
+// ParseTimestamp parses an RFC 3339 timestamp of a GCP resource, returning the zero time if
+// it is empty or invalid
+func ParseTimestamp(value string) time.Time {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
@@ -0,0 +1,136 @@
+package gcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+const (
+	// Scope requested for access tokens. Scanners only read resources and metrics.
+	readOnlyScope = "https://www.googleapis.com/auth/cloud-platform.read-only"
+
+	// Scope of impersonated credentials. The IAM Credentials API requires it of the source
+	// credentials, which request the same scopes as the impersonated ones.
+	cloudPlatformScope = "https://www.googleapis.com/auth/cloud-platform"
+
+	// generateAccessToken endpoint of the IAM Credentials API, by service account email
+	impersonationURL = "https://iamcredentials.googleapis.com/v1/projects/-/serviceAccounts/%s:generateAccessToken"
+)
+
+// Credentials issues the access tokens of GCP API requests. Tokens are cached by the token
+// source until shortly before they expire.
+type Credentials struct {
+	// Source describes where the credentials come from, e.g. the credentials file
+	Source string
+
+	tokens oauth2.TokenSource
+}
+
+// Token returns a valid access token, fetching a new one if the cached one expires soon
+func (c *Credentials) Token() (string, error) {
+	token, err := c.tokens.Token()
+	if err != nil {
+		return "", fmt.Errorf("failed to get GCP access token from %s: %w", c.Source, err)
+	}
+	return token.AccessToken, nil
+}
+
+// FindCredentials returns the credentials of a service account key, authorized user, external
+// account or impersonated service account file, or Google's application default credentials
+// when file is empty. Tokens are requested with client, the default HTTP client if nil. If
+// serviceAccount is set, the credentials impersonate it.
+func FindCredentials(client *http.Client, file, serviceAccount string) (*Credentials, error) {
+	ctx := context.Background()
+	if client != nil {
+		ctx = context.WithValue(ctx, oauth2.HTTPClient, client)
+	}
+	params := google.CredentialsParams{Scopes: []string{readOnlyScope}}
+
+	if file == "" {
+		file = os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
+	}
+	if file == "" && serviceAccount == "" {
+		creds, err := google.FindDefaultCredentialsWithParams(ctx, params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find GCP credentials: %w", err)
+		}
+		return &Credentials{Source: "application default credentials", tokens: creds.TokenSource}, nil
+	}
+	if file == "" {
+		// Impersonation wraps the credentials file, which the metadata server has none of
+		file = wellKnownCredentialsFile()
+		if _, err := os.Stat(file); err != nil {
+			return nil, fmt.Errorf("impersonating GCP service account %s needs a credentials file or gcloud application default credentials", serviceAccount)
+		}
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read GCP credentials file: %w", err)
+	}
+	if serviceAccount != "" {
+		data, err = json.Marshal(map[string]interface{}{
+			"type":                              "impersonated_service_account",
+			"service_account_impersonation_url": fmt.Sprintf(impersonationURL, url.PathEscape(serviceAccount)),
+			"source_credentials":                json.RawMessage(data),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var header struct {
+		Type                           string `json:"type"`
+		ServiceAccountImpersonationURL string `json:"service_account_impersonation_url"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, fmt.Errorf("failed to parse GCP credentials file %s: %w", file, err)
+	}
+	source := file
+	if header.ServiceAccountImpersonationURL != "" {
+		source += " impersonating " + impersonatedServiceAccount(header.ServiceAccountImpersonationURL)
+		if header.Type == "impersonated_service_account" {
+			params.Scopes = []string{cloudPlatformScope}
+		}
+	}
+
+	creds, err := google.CredentialsFromJSONWithParams(ctx, data, params)
+	if err != nil {
+		return nil, fmt.Errorf("invalid GCP credentials file %s: %w", file, err)
+	}
+	return &Credentials{Source: source, tokens: creds.TokenSource}, nil
+}
+
+// impersonatedServiceAccount returns the service account of a generateAccessToken URL
+func impersonatedServiceAccount(generateURL string) string {
+	name := generateURL[strings.LastIndex(generateURL, "/")+1:]
+	name = strings.TrimSuffix(name, ":generateAccessToken")
+	if unescaped, err := url.PathUnescape(name); err == nil {
+		return unescaped
+	}
+	return name
+}
+
+// wellKnownCredentialsFile returns the path gcloud writes application default credentials to
+func wellKnownCredentialsFile() string {
+	if dir := os.Getenv("CLOUDSDK_CONFIG"); dir != "" {
+		return filepath.Join(dir, "application_default_credentials.json")
+	}
+	if appData := os.Getenv("APPDATA"); appData != "" {
+		return filepath.Join(appData, "gcloud", "application_default_credentials.json")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "gcloud", "application_default_credentials.json")
+}
@@ -0,0 +1,67 @@
+package gcp
+
+import (
+	"math"
+	"time"
+
+	awslib "cloudsift/internal/aws"
+)
+
+// Monthly list prices in USD per GB of persistent disk types in us-central1. GCP has no
+// pricing API usable without a billing account, so costs are estimated from these prices
+// in all regions.
+var diskMonthlyPricePerGB = map[string]float64{
+	"pd-standard":          0.04,
+	"pd-balanced":          0.10,
+	"pd-ssd":               0.17,
+	"pd-extreme":           0.125,
+	"hyperdisk-balanced":   0.06,
+	"hyperdisk-extreme":    0.125,
+	"hyperdisk-throughput": 0.05,
+}
+
+// Hourly list price in USD of a static external IP address that is reserved but not used
+const unusedStaticIPHourlyPrice = 0.01
+
+// DiskMonthlyPrice returns the estimated monthly price of a persistent disk, and false if the
+// disk type has no known price
+func DiskMonthlyPrice(diskType string, sizeGB int64) (float64, bool) {
+	price, ok := diskMonthlyPricePerGB[diskType]
+	if !ok {
+		return 0, false
+	}
+	return price * float64(sizeGB), true
+}
+
+// UnusedStaticIPMonthlyPrice returns the estimated monthly price of a reserved static IP
+// address that is not used
+func UnusedStaticIPMonthlyPrice() float64 {
+	return unusedStaticIPHourlyPrice * 730
+}
+
+// MonthlyCost returns the cost of a result billed at a monthly price since it was created,
+// with the same rates as the cost estimates of AWS resources
+func MonthlyCost(monthlyPrice float64, created time.Time) map[string]interface{} {
+	hourly := monthlyPrice / 730 // 730 hours in a month
+	daily := hourly * 24
+	costs := &awslib.CostBreakdown{
+		HourlyRate:  roundCost(hourly),
+		DailyRate:   roundCost(daily),
+		MonthlyRate: roundCost(daily * 30),
+		YearlyRate:  roundCost(daily * 365),
+	}
+	if !created.IsZero() {
+		hours := roundCost(time.Since(created).Hours())
+		lifetime := roundCost(hourly * hours)
+		costs.HoursRunning = &hours
+		costs.Lifetime = &lifetime
+	}
+	return map[string]interface{}{
+		"total": costs,
+	}
+}
+
+// roundCost rounds a cost to 2 decimal places
+func roundCost(cost float64) float64 {
+	return math.Round(cost*100) / 100
+}
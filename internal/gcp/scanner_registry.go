@@ -0,0 +1,73 @@
+package gcp
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	awslib "cloudsift/internal/aws"
+)
+
+// ScanOptions contains configuration for the scan of a GCP project
+type ScanOptions struct {
+	Project    string  // ID of the project to scan
+	DaysUnused int     // Number of days a resource must be unused to be reported
+	Client     *Client // Client calling the GCP APIs with the scan credentials
+}
+
+// Scanner interface defines methods that must be implemented by GCP resource scanners.
+// Scanners report the same results as AWS scanners, so GCP findings go through the same
+// outputs and reports.
+type Scanner interface {
+	ArgumentName() string // ArgumentName returns the name used in CLI arguments
+	Label() string        // Label returns a human-readable label for the scanner
+	Scan(opts ScanOptions) (awslib.ScanResults, error)
+}
+
+// ScannerRegistry manages available GCP scanners
+type ScannerRegistry struct {
+	scanners map[string]Scanner
+	mu       sync.RWMutex
+}
+
+// NewScannerRegistry creates a new scanner registry
+func NewScannerRegistry() *ScannerRegistry {
+	return &ScannerRegistry{
+		scanners: make(map[string]Scanner),
+	}
+}
+
+// RegisterScanner registers a scanner with the registry
+func (r *ScannerRegistry) RegisterScanner(scanner Scanner) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.scanners[scanner.ArgumentName()] = scanner
+}
+
+// GetScanner retrieves a scanner by argument name
+func (r *ScannerRegistry) GetScanner(argumentName string) (Scanner, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	scanner, ok := r.scanners[argumentName]
+	if !ok {
+		return nil, fmt.Errorf("scanner %s not found", argumentName)
+	}
+	return scanner, nil
+}
+
+// ListScanners returns a sorted list of registered scanner argument names
+func (r *ScannerRegistry) ListScanners() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var argumentNames []string
+	for argumentName := range r.scanners {
+		argumentNames = append(argumentNames, argumentName)
+	}
+	sort.Strings(argumentNames)
+	return argumentNames
+}
+
+// DefaultRegistry is the default GCP scanner registry
+var DefaultRegistry = NewScannerRegistry()
@@ -0,0 +1,71 @@
+package scanners
+
+import (
+	"context"
+	"fmt"
+
+	awslib "cloudsift/internal/aws"
+	"cloudsift/internal/gcp"
+	"cloudsift/internal/logging"
+)
+
+// StaticIPScanner scans for reserved static external IP addresses not used by any resource
+type StaticIPScanner struct{}
+
+func init() {
+	gcp.DefaultRegistry.RegisterScanner(&StaticIPScanner{})
+}
+
+// ArgumentName implements Scanner interface
+func (s *StaticIPScanner) ArgumentName() string {
+	return "static-ips"
+}
+
+// Label implements Scanner interface
+func (s *StaticIPScanner) Label() string {
+	return "Static IPs"
+}
+
+// Scan implements Scanner interface
+func (s *StaticIPScanner) Scan(opts gcp.ScanOptions) (awslib.ScanResults, error) {
+	addresses, err := opts.Client.Addresses(context.Background(), opts.Project)
+	if err != nil {
+		logging.Error("Failed to list addresses", err, map[string]interface{}{
+			"project": opts.Project,
+		})
+		return nil, fmt.Errorf("failed to list addresses: %w", err)
+	}
+
+	var results awslib.ScanResults
+	for _, address := range addresses {
+		// Internal addresses are free, and reserved addresses in use are billed with the
+		// resource using them
+		if address.AddressType != "EXTERNAL" || address.Status != "RESERVED" || len(address.Users) > 0 {
+			continue
+		}
+
+		results = append(results, awslib.ScanResult{
+			ResourceType: s.Label(),
+			ResourceName: address.Name,
+			ResourceID:   address.ID,
+			Reason:       "Not associated with any resource",
+			Details: map[string]interface{}{
+				"project":      opts.Project,
+				"region":       gcp.ResourceName(address.Region),
+				"address":      address.Address,
+				"address_type": address.AddressType,
+				"status":       address.Status,
+				"created_at":   address.CreationTimestamp,
+			},
+			Tags: address.Labels,
+			Cost: gcp.MonthlyCost(gcp.UnusedStaticIPMonthlyPrice(), gcp.ParseTimestamp(address.CreationTimestamp)),
+		})
+	}
+
+	logging.Info("Static IP scan complete", map[string]interface{}{
+		"project":           opts.Project,
+		"addresses_checked": len(addresses),
+		"unused_addresses":  len(results),
+	})
+	return results, nil
+}
@@ -0,0 +1,96 @@
+package scanners
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	awslib "cloudsift/internal/aws"
+	"cloudsift/internal/aws/utils"
+	"cloudsift/internal/gcp"
+	"cloudsift/internal/logging"
+)
+
+// DiskScanner scans for persistent disks not attached to any instance
+type DiskScanner struct{}
+
+func init() {
+	gcp.DefaultRegistry.RegisterScanner(&DiskScanner{})
+}
+
+// ArgumentName implements Scanner interface
+func (s *DiskScanner) ArgumentName() string {
+	return "persistent-disks"
+}
+
+// Label implements Scanner interface
+func (s *DiskScanner) Label() string {
+	return "Persistent Disks"
+}
+
+// Scan implements Scanner interface
+func (s *DiskScanner) Scan(opts gcp.ScanOptions) (awslib.ScanResults, error) {
+	disks, err := opts.Client.Disks(context.Background(), opts.Project)
+	if err != nil {
+		logging.Error("Failed to list persistent disks", err, map[string]interface{}{
+			"project": opts.Project,
+		})
+		return nil, fmt.Errorf("failed to list persistent disks: %w", err)
+	}
+
+	now := time.Now()
+	var results awslib.ScanResults
+	for _, disk := range disks {
+		if len(disk.Users) > 0 {
+			continue
+		}
+
+		// A disk is unused since it was detached, or since it was created if it never was
+		created := gcp.ParseTimestamp(disk.CreationTimestamp)
+		lastUsed := gcp.ParseTimestamp(disk.LastDetachTimestamp)
+		if lastUsed.IsZero() {
+			lastUsed = created
+		}
+		if lastUsed.IsZero() || now.Sub(lastUsed) < time.Duration(opts.DaysUnused)*24*time.Hour {
+			continue
+		}
+
+		zone := gcp.ResourceName(disk.Zone)
+		diskType := gcp.ResourceName(disk.Type)
+		sizeGB, _ := strconv.ParseInt(disk.SizeGB, 10, 64)
+
+		cost := awslib.NoCost(awslib.NoCostNotEstimated)
+		if price, ok := gcp.DiskMonthlyPrice(diskType, sizeGB); ok {
+			cost = gcp.MonthlyCost(price, created)
+		}
+
+		results = append(results, awslib.ScanResult{
+			ResourceType: s.Label(),
+			ResourceName: disk.Name,
+			ResourceID:   disk.ID,
+			Reason:       fmt.Sprintf("Volume has not been used in %s", utils.FormatTimeDifference(now, &lastUsed)),
+			Details: map[string]interface{}{
+				"project":               opts.Project,
+				"region":                gcp.ZoneRegion(zone),
+				"zone":                  zone,
+				"disk_type":             diskType,
+				"size_gb":               sizeGB,
+				"status":                disk.Status,
+				"created_at":            disk.CreationTimestamp,
+				"last_attach_timestamp": disk.LastAttachTimestamp,
+				"last_detach_timestamp": disk.LastDetachTimestamp,
+				"self_link":             disk.SelfLink,
+			},
+			Tags: disk.Labels,
+			Cost: cost,
+		})
+	}
+
+	logging.Info("Persistent disk scan complete", map[string]interface{}{
+		"project":       opts.Project,
+		"disks_checked": len(disks),
+		"unused_disks":  len(results),
+	})
+	return results, nil
+}
@@ -0,0 +1,155 @@
+package scanners
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	awslib "cloudsift/internal/aws"
+	"cloudsift/internal/aws/utils"
+	"cloudsift/internal/gcp"
+	"cloudsift/internal/logging"
+)
+
+// Average CPU utilization in percent below which a running instance is idle, as for EC2
+const idleCPUThreshold = 5
+
+// InstanceScanner scans for idle and long stopped Compute Engine instances
+type InstanceScanner struct{}
+
+func init() {
+	gcp.DefaultRegistry.RegisterScanner(&InstanceScanner{})
+}
+
+// ArgumentName implements Scanner interface
+func (s *InstanceScanner) ArgumentName() string {
+	return "compute-instances"
+}
+
+// Label implements Scanner interface
+func (s *InstanceScanner) Label() string {
+	return "Compute Instances"
+}
+
+// Scan implements Scanner interface
+func (s *InstanceScanner) Scan(opts gcp.ScanOptions) (awslib.ScanResults, error) {
+	ctx := context.Background()
+	instances, err := opts.Client.Instances(ctx, opts.Project)
+	if err != nil {
+		logging.Error("Failed to list instances", err, map[string]interface{}{
+			"project": opts.Project,
+		})
+		return nil, fmt.Errorf("failed to list instances: %w", err)
+	}
+
+	// Stopped instances are billed for their disks, priced by disk type
+	var diskTypes map[string]string
+	for _, instance := range instances {
+		if instance.Status == "TERMINATED" {
+			diskTypes = s.diskTypes(ctx, opts)
+			break
+		}
+	}
+
+	now := time.Now()
+	start := now.Add(-time.Duration(opts.DaysUnused) * 24 * time.Hour)
+	var results awslib.ScanResults
+	for _, instance := range instances {
+		created := gcp.ParseTimestamp(instance.CreationTimestamp)
+		if created.IsZero() || created.After(start) {
+			continue // Too new to tell whether it is used
+		}
+
+		var reason string
+		cost := awslib.NoCost(awslib.NoCostNotEstimated)
+		switch instance.Status {
+		case "TERMINATED":
+			// Compute Engine reports stopped instances as terminated
+			stopped := gcp.ParseTimestamp(instance.LastStopTimestamp)
+			if stopped.IsZero() || stopped.After(start) {
+				continue
+			}
+			reason = fmt.Sprintf("Instance has been stopped for %s", utils.FormatTimeDifference(now, &stopped))
+			if price, ok := s.diskPrice(instance, diskTypes); ok {
+				cost = gcp.MonthlyCost(price, created)
+			}
+		case "RUNNING":
+			cpuAvg, ok, err := opts.Client.AverageCPUUtilization(ctx, opts.Project, instance.ID, start, now)
+			if err != nil {
+				logging.Error("Failed to get instance CPU utilization", err, map[string]interface{}{
+					"project":     opts.Project,
+					"instance_id": instance.ID,
+				})
+				continue
+			}
+			if !ok || cpuAvg >= idleCPUThreshold {
+				continue
+			}
+			reason = fmt.Sprintf("Very low CPU utilization (%.2f%%) in the last %d days.", cpuAvg, opts.DaysUnused)
+		default:
+			continue
+		}
+
+		zone := gcp.ResourceName(instance.Zone)
+		results = append(results, awslib.ScanResult{
+			ResourceType: s.Label(),
+			ResourceName: instance.Name,
+			ResourceID:   instance.ID,
+			Reason:       reason,
+			Details: map[string]interface{}{
+				"project":              opts.Project,
+				"region":               gcp.ZoneRegion(zone),
+				"zone":                 zone,
+				"machine_type":         gcp.ResourceName(instance.MachineType),
+				"status":               instance.Status,
+				"created_at":           instance.CreationTimestamp,
+				"last_start_timestamp": instance.LastStartTimestamp,
+				"last_stop_timestamp":  instance.LastStopTimestamp,
+				"disks":                len(instance.Disks),
+			},
+			Tags: instance.Labels,
+			Cost: cost,
+		})
+	}
+
+	logging.Info("Compute instance scan complete", map[string]interface{}{
+		"project":           opts.Project,
+		"instances_checked": len(instances),
+		"unused_instances":  len(results),
+	})
+	return results, nil
+}
+
+// diskTypes returns the types of the disks of the project keyed by their self link. Stopped
+// instances are then reported without a cost if the disks cannot be listed.
+func (s *InstanceScanner) diskTypes(ctx context.Context, opts gcp.ScanOptions) map[string]string {
+	disks, err := opts.Client.Disks(ctx, opts.Project)
+	if err != nil {
+		logging.Warn("Failed to list persistent disks of stopped instances", map[string]interface{}{
+			"project": opts.Project,
+			"error":   err.Error(),
+		})
+		return nil
+	}
+	types := make(map[string]string, len(disks))
+	for _, disk := range disks {
+		types[disk.SelfLink] = gcp.ResourceName(disk.Type)
+	}
+	return types
+}
+
+// diskPrice returns the monthly price of the disks attached to an instance, and false if the
+// price of any of them is unknown
+func (s *InstanceScanner) diskPrice(instance gcp.Instance, diskTypes map[string]string) (float64, bool) {
+	var total float64
+	for _, disk := range instance.Disks {
+		sizeGB, _ := strconv.ParseInt(disk.DiskSizeGB, 10, 64)
+		price, ok := gcp.DiskMonthlyPrice(diskTypes[disk.Source], sizeGB)
+		if !ok {
+			return 0, false
+		}
+		total += price
+	}
+	return total, len(instance.Disks) > 0
+}
@@ -0,0 +1,226 @@
+package history
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"cloudsift/internal/aws"
+	"cloudsift/internal/logging"
+)
+
+// Entry records the consecutive scans a finding appeared in
+type Entry struct {
+	FirstSeen   time.Time `json:"first_seen"` // Start of the current streak of scans
+	LastSeen    time.Time `json:"last_seen"`
+	Consecutive int       `json:"consecutive"` // Consecutive scans the finding appeared in
+	LastScan    int       `json:"last_scan"`   // Number of the last scan the finding appeared in
+}
+
+// Store is the history of previous scans, keyed by finding. It is rebuilt from the runs of a
+// history directory, see LoadStore.
+type Store struct {
+	Scans    int // Number of scans recorded
+	Findings map[string]*Entry
+
+	existed bool // Whether runs were recorded before
+}
+
+// NewStore creates an empty history
+func NewStore() *Store {
+	return &Store{Findings: make(map[string]*Entry)}
+}
+
+// LoadStore rebuilds the history of the runs recorded in a history directory by recording
+// their findings, including unconfirmed ones, oldest first. A missing directory gives an
+// empty history.
+func LoadStore(dir string) (*Store, error) {
+	store := NewStore()
+	if _, err := os.Stat(dir); errors.Is(err, os.ErrNotExist) {
+		logging.Info("No scan runs recorded yet, starting a new history", map[string]interface{}{
+			"path": dir,
+		})
+		return store, nil
+	}
+
+	runs, err := ListRuns(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, run := range runs {
+		findings, err := LoadFindings(dir, run.ID)
+		if err != nil {
+			return nil, err
+		}
+		keys := make([]string, len(findings))
+		for i, finding := range findings {
+			keys[i] = finding.Key()
+		}
+		store.record(keys, ScopeOf(run.Scope), run.StartedAt)
+	}
+	store.existed = len(runs) > 0
+
+	logging.Info("Loaded scan history", map[string]interface{}{
+		"path":     dir,
+		"scans":    store.Scans,
+		"findings": len(store.Findings),
+	})
+
+	return store, nil
+}
+
+// Existed reports whether runs were recorded before the history was loaded
+func (s *Store) Existed() bool {
+	return s.existed
+}
+
+// Key identifies a finding across scans by account, region, resource type and resource ID
+func Key(result aws.ScanResult) string {
+	region, _ := result.Details["region"].(string)
+	return fmt.Sprintf("%s/%s/%s/%s", result.AccountID, region, result.ResourceType, result.ResourceID)
+}
+
+// Scope is the part of the accounts, regions and scanners a scan covered. Findings outside
+// of it, such as those of regions or scanners left out of the scan, keep their streak when
+// the scan is recorded. A nil Scope covers everything.
+type Scope struct {
+	mu      sync.Mutex
+	regions map[string]map[string]bool // "account/resource type" -> covered regions, "" for all
+}
+
+// NewScope creates an empty scope
+func NewScope() *Scope {
+	return &Scope{regions: make(map[string]map[string]bool)}
+}
+
+// ScopeOf returns the scope of the regions recorded by Regions. Runs recorded without a scope
+// give a nil Scope, which covers everything.
+func ScopeOf(regions map[string][]string) *Scope {
+	if regions == nil {
+		return nil
+	}
+	scope := NewScope()
+	for key, keyRegions := range regions {
+		scope.regions[key] = make(map[string]bool, len(keyRegions))
+		for _, region := range keyRegions {
+			scope.regions[key][region] = true
+		}
+	}
+	return scope
+}
+
+// Regions returns the covered regions by "account/resource type", sorted, to record the scope
+// with a run. A nil Scope gives nil.
+func (s *Scope) Regions() map[string][]string {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	regions := make(map[string][]string, len(s.regions))
+	for key, keyRegions := range s.regions {
+		for region := range keyRegions {
+			regions[key] = append(regions[key], region)
+		}
+		sort.Strings(regions[key])
+	}
+	return regions
+}
+
+// Add adds the regions a scanner covered in an account to the scope. An empty region covers
+// all regions, for scanners inspecting several regions at once.
+func (s *Scope) Add(accountID, resourceType, region string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := accountID + "/" + resourceType
+	if s.regions[key] == nil {
+		s.regions[key] = make(map[string]bool)
+	}
+	s.regions[key][region] = true
+}
+
+// Contains reports whether the scope covers a finding key
+func (s *Scope) Contains(key string) bool {
+	if s == nil {
+		return true
+	}
+	// Keys are account/region/resource type/resource ID, and only IDs may contain slashes
+	parts := strings.SplitN(key, "/", 4)
+	if len(parts) < 4 {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	regions := s.regions[parts[0]+"/"+parts[2]]
+	return regions[parts[1]] || regions[""]
+}
+
+// Record adds a scan of the given results to the history. Findings of the scope missing from
+// the scan lose their streak and are dropped; findings outside of it are kept unchanged, so
+// a finding's streak counts the consecutive scans that covered it.
+func (s *Store) Record(results []aws.ScanResult, scope *Scope, scannedAt time.Time) {
+	keys := make([]string, len(results))
+	for i, result := range results {
+		keys[i] = Key(result)
+	}
+	s.record(keys, scope, scannedAt)
+}
+
+// record adds a scan of the findings with the given keys to the history
+func (s *Store) record(keys []string, scope *Scope, scannedAt time.Time) {
+	s.Scans++
+	for _, key := range keys {
+		entry, ok := s.Findings[key]
+		switch {
+		case ok && entry.LastScan == s.Scans:
+			continue // Reported twice in the same scan
+		case ok:
+			// Entries missing from a scan covering them were dropped, so the finding
+			// appeared in every scan that covered it since
+			entry.Consecutive++
+		default:
+			entry = &Entry{FirstSeen: scannedAt, Consecutive: 1}
+			s.Findings[key] = entry
+		}
+		entry.LastSeen = scannedAt
+		entry.LastScan = s.Scans
+	}
+
+	for key, entry := range s.Findings {
+		if entry.LastScan != s.Scans && scope.Contains(key) {
+			delete(s.Findings, key)
+		}
+	}
+}
+
+// Consecutive returns the consecutive scans a finding appeared in, including the last
+// recorded scan
+func (s *Store) Consecutive(result aws.ScanResult) int {
+	entry, ok := s.Findings[Key(result)]
+	if !ok || entry.LastScan != s.Scans {
+		return 0
+	}
+	return entry.Consecutive
+}
+
+// Confirm returns the results that appeared in at least the given number of consecutive
+// scans, and records the streak of each in its details
+func (s *Store) Confirm(results aws.ScanResults, scans int) aws.ScanResults {
+	confirmed := make(aws.ScanResults, 0, len(results))
+	for _, result := range results {
+		consecutive := s.Consecutive(result)
+		if consecutive < scans {
+			continue
+		}
+		if result.Details == nil {
+			result.Details = make(map[string]interface{})
+		}
+		result.Details["consecutive_scans"] = consecutive
+		confirmed = append(confirmed, result)
+	}
+	return confirmed
+}
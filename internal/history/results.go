@@ -0,0 +1,74 @@
+package history
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"cloudsift/internal/output"
+)
+
+// ReadResultsFindings reads the findings of a results file written by scan --output-format
+// json, or of the results files of a directory. When a directory holds several results of an
+// account, the last one by file name, which is the latest scan, is used.
+func ReadResultsFindings(path string) ([]Finding, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scan results: %w", err)
+	}
+
+	files := []string{path}
+	if info.IsDir() {
+		files = nil
+		err := filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !entry.IsDir() && (strings.HasSuffix(file, ".json") || strings.HasSuffix(file, ".json.gz")) {
+				files = append(files, file)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read scan results directory: %w", err)
+		}
+		if len(files) == 0 {
+			return nil, fmt.Errorf("no scan results found in %s", path)
+		}
+	}
+
+	// Files are walked in lexical order, so a later scan of an account replaces an earlier one
+	accounts := make(map[string]output.AccountResults)
+	var accountIDs []string
+	for _, file := range files {
+		loaded, err := output.ReadResults(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, account := range loaded {
+			if _, ok := accounts[account.AccountID]; !ok {
+				accountIDs = append(accountIDs, account.AccountID)
+			}
+			accounts[account.AccountID] = account
+		}
+	}
+
+	findings := []Finding{}
+	for _, accountID := range accountIDs {
+		account := accounts[accountID]
+		for _, results := range account.Results {
+			for _, result := range results {
+				if result.AccountID == "" {
+					result.AccountID = account.AccountID
+				}
+				if result.AccountName == "" {
+					result.AccountName = account.AccountName
+				}
+				findings = append(findings, NewFinding(result))
+			}
+		}
+	}
+	return findings, nil
+}
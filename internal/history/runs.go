@@ -0,0 +1,399 @@
+package history
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"cloudsift/internal/aws"
+	"cloudsift/internal/logging"
+)
+
+// Files written to each run directory
+const (
+	runFile        = "run.json"
+	findingsFile   = "findings.json.gz"
+	priceCacheFile = "costs.json"
+)
+
+// runLayout names run directories by scan start time, so they sort chronologically
+const runLayout = "20060102T150405Z"
+
+// RunMetrics are the metrics of a recorded scan run
+type RunMetrics struct {
+	CompletedTasks    int64   `json:"completed_tasks"`
+	FailedTasks       int64   `json:"failed_tasks"`
+	RunTimeSeconds    float64 `json:"run_time_seconds"`
+	PeakWorkers       int64   `json:"peak_workers"`
+	SkippedAccounts   int     `json:"skipped_accounts"`
+	APICalls          int64   `json:"api_calls"`
+	APICallCost       float64 `json:"api_call_cost"`       // Estimated cost of the API calls in USD
+	APIBudgetExceeded bool    `json:"api_budget_exceeded"` // The scan stopped early at its API call budget
+}
+
+// Run is a recorded scan run with the totals of its findings. The findings themselves are
+// loaded separately with LoadFindings, so runs can be listed without reading them.
+type Run struct {
+	ID             string                    `json:"id"` // Start time in runLayout, naming the run directory
+	StartedAt      time.Time                 `json:"started_at"`
+	CompletedAt    time.Time                 `json:"completed_at"`
+	Currency       string                    `json:"currency"`
+	Accounts       int                       `json:"accounts"`
+	Findings       int                       `json:"findings"`
+	MonthlySavings float64                   `json:"monthly_savings"`
+	AccountTotals  map[string]*AccountTotals `json:"account_totals"` // By account ID, including accounts without findings
+	Scope          map[string][]string       `json:"scope"`          // Regions the scanners covered, see Scope
+	Metrics        RunMetrics                `json:"metrics"`
+}
+
+// AccountTotals are the totals of the findings of an account in a run
+type AccountTotals struct {
+	AccountName    string         `json:"account_name"`
+	Findings       int            `json:"findings"`
+	FindingsByType map[string]int `json:"findings_by_type"`
+	MonthlySavings float64        `json:"monthly_savings"`
+}
+
+// Finding is a resource found unused in a recorded scan run
+type Finding struct {
+	AccountID     string  `json:"account_id"`
+	AccountName   string  `json:"account_name,omitempty"`
+	Region        string  `json:"region"`
+	ResourceType  string  `json:"resource_type"`
+	ResourceID    string  `json:"resource_id"`
+	ResourceName  string  `json:"resource_name,omitempty"`
+	Reason        string  `json:"reason"`
+	MonthlyCost   float64 `json:"monthly_cost"`             // Zero for findings without a cost estimate
+	LowConfidence bool    `json:"low_confidence,omitempty"` // Reported without evidence of being unused, its cost is left out of savings
+	Unconfirmed   bool    `json:"unconfirmed,omitempty"`    // Held back by --confirmation-scans and left out of the run totals
+}
+
+// Key identifies a finding across runs like Key identifies scan results
+func (f Finding) Key() string {
+	return fmt.Sprintf("%s/%s/%s/%s", f.AccountID, f.Region, f.ResourceType, f.ResourceID)
+}
+
+// SavingsCost returns the monthly cost the finding counts toward savings totals, or 0 for low
+// confidence findings
+func (f Finding) SavingsCost() float64 {
+	if f.LowConfidence {
+		return 0
+	}
+	return f.MonthlyCost
+}
+
+// NewFinding returns the recorded finding of a scan result
+func NewFinding(result aws.ScanResult) Finding {
+	region, _ := result.Details["region"].(string)
+	finding := Finding{
+		AccountID:     result.AccountID,
+		AccountName:   result.AccountName,
+		Region:        region,
+		ResourceType:  result.ResourceType,
+		ResourceID:    result.ResourceID,
+		ResourceName:  result.ResourceName,
+		Reason:        result.Reason,
+		LowConfidence: result.LowConfidence(),
+	}
+	if costs, ok := result.Cost["total"].(*aws.CostBreakdown); ok && costs != nil {
+		finding.MonthlyCost = costs.MonthlyRate
+	}
+	return finding
+}
+
+// NewRun creates a run of a scan started at the given time, with the totals of its confirmed
+// findings, leaving the cost of low confidence findings out of the savings. Accounts maps the IDs of the scanned accounts to their names, and scope is the part
+// of them the scan covered.
+func NewRun(startedAt, completedAt time.Time, currency string, accounts map[string]string, findings []Finding, scope *Scope, metrics RunMetrics) *Run {
+	run := &Run{
+		ID:            startedAt.UTC().Format(runLayout),
+		StartedAt:     startedAt.UTC(),
+		CompletedAt:   completedAt.UTC(),
+		Currency:      currency,
+		Accounts:      len(accounts),
+		AccountTotals: make(map[string]*AccountTotals, len(accounts)),
+		Scope:         scope.Regions(),
+		Metrics:       metrics,
+	}
+	accountTotals := func(accountID, accountName string) *AccountTotals {
+		totals, ok := run.AccountTotals[accountID]
+		if !ok {
+			totals = &AccountTotals{AccountName: accountName, FindingsByType: make(map[string]int)}
+			run.AccountTotals[accountID] = totals
+		}
+		return totals
+	}
+	for accountID, accountName := range accounts {
+		accountTotals(accountID, accountName)
+	}
+	for _, finding := range findings {
+		if finding.Unconfirmed {
+			continue
+		}
+		run.Findings++
+		run.MonthlySavings += finding.SavingsCost()
+
+		totals := accountTotals(finding.AccountID, finding.AccountName)
+		totals.Findings++
+		totals.FindingsByType[finding.ResourceType]++
+		totals.MonthlySavings += finding.SavingsCost()
+	}
+	return run
+}
+
+// WriteRun writes a run and its findings into a new directory of the history directory, named
+// by the run ID, and returns its path
+func WriteRun(dir string, run *Run, findings []Finding) (string, error) {
+	path := filepath.Join(dir, run.ID)
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return "", fmt.Errorf("failed to create run directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(run, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal run: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(path, runFile), data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write run: %w", err)
+	}
+
+	f, err := os.Create(filepath.Join(path, findingsFile))
+	if err != nil {
+		return "", fmt.Errorf("failed to create findings file: %w", err)
+	}
+	gz := gzip.NewWriter(f)
+	if err := json.NewEncoder(gz).Encode(findings); err != nil {
+		f.Close()
+		return "", fmt.Errorf("failed to write findings: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		f.Close()
+		return "", fmt.Errorf("failed to write findings: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("failed to close findings file: %w", err)
+	}
+
+	return path, nil
+}
+
+// WritePriceCache writes a snapshot of the pricing cache into the directory of a run, keeping
+// the prices its savings were estimated with
+func WritePriceCache(dir, id string, prices map[string]float64) error {
+	data, err := json.Marshal(prices)
+	if err != nil {
+		return fmt.Errorf("failed to marshal pricing cache: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, id, priceCacheFile), data, 0644); err != nil {
+		return fmt.Errorf("failed to write pricing cache: %w", err)
+	}
+	return nil
+}
+
+// LoadPriceCache reads the pricing cache snapshot of a run in a history directory, or nil if
+// the run has none
+func LoadPriceCache(dir, id string) (map[string]float64, error) {
+	data, err := os.ReadFile(filepath.Join(dir, id, priceCacheFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pricing cache of run %s: %w", id, err)
+	}
+	var prices map[string]float64
+	if err := json.Unmarshal(data, &prices); err != nil {
+		return nil, fmt.Errorf("failed to parse pricing cache of run %s: %w", id, err)
+	}
+	return prices, nil
+}
+
+// ListRuns reads the runs recorded in a history directory, oldest first. Directories without a
+// run are skipped.
+func ListRuns(dir string) ([]*Run, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history directory: %w", err)
+	}
+
+	var runs []*Run
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name(), runFile))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read run: %w", err)
+		}
+		var run Run
+		if err := json.Unmarshal(data, &run); err != nil {
+			return nil, fmt.Errorf("failed to parse run %s: %w", entry.Name(), err)
+		}
+		runs = append(runs, &run)
+	}
+
+	sort.SliceStable(runs, func(i, j int) bool {
+		return runs[i].StartedAt.Before(runs[j].StartedAt)
+	})
+
+	logging.Debug("Loaded scan runs", map[string]interface{}{
+		"path": dir,
+		"runs": len(runs),
+	})
+
+	return runs, nil
+}
+
+// FindRun returns the run with the given ID, or the latest run for "latest" and the one before
+// it for "previous"
+func FindRun(runs []*Run, id string) (*Run, error) {
+	switch {
+	case len(runs) == 0:
+		return nil, fmt.Errorf("no scan runs recorded")
+	case id == "latest":
+		return runs[len(runs)-1], nil
+	case id == "previous":
+		if len(runs) < 2 {
+			return nil, fmt.Errorf("only one scan run recorded")
+		}
+		return runs[len(runs)-2], nil
+	}
+	for _, run := range runs {
+		if run.ID == id {
+			return run, nil
+		}
+	}
+	return nil, fmt.Errorf("scan run %s not found", id)
+}
+
+// LoadFindings reads the findings of a run in a history directory
+func LoadFindings(dir, id string) ([]Finding, error) {
+	f, err := os.Open(filepath.Join(dir, id, findingsFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open findings of run %s: %w", id, err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read findings of run %s: %w", id, err)
+	}
+	defer gz.Close()
+
+	var findings []Finding
+	if err := json.NewDecoder(gz).Decode(&findings); err != nil {
+		return nil, fmt.Errorf("failed to parse findings of run %s: %w", id, err)
+	}
+	return findings, nil
+}
+
+// Confirmed returns the findings that were reported, leaving out those held back as
+// unconfirmed
+func Confirmed(findings []Finding) []Finding {
+	confirmed := make([]Finding, 0, len(findings))
+	for _, finding := range findings {
+		if !finding.Unconfirmed {
+			confirmed = append(confirmed, finding)
+		}
+	}
+	return confirmed
+}
+
+// TypeChange is the change of the findings of a resource type between two runs
+type TypeChange struct {
+	ResourceType    string  `json:"resource_type"`
+	PreviousCount   int     `json:"previous_count"`
+	CurrentCount    int     `json:"current_count"`
+	PreviousSavings float64 `json:"previous_savings"`
+	CurrentSavings  float64 `json:"current_savings"`
+	New             int     `json:"new"`       // Findings of the current run only
+	Resolved        int     `json:"resolved"`  // Findings of the previous run only
+	Unchanged       int     `json:"unchanged"` // Findings of both runs
+}
+
+// RunDiff is the change of the findings between two runs
+type RunDiff struct {
+	New           []Finding // Findings of the current run missing from the previous run
+	Resolved      []Finding // Findings of the previous run missing from the current run
+	Unchanged     int       // Findings of both runs
+	ResourceTypes []TypeChange
+}
+
+// DiffRuns compares the findings of two runs. New and resolved findings are sorted by highest
+// monthly cost, and resource types by the largest change of their savings.
+func DiffRuns(previous, current []Finding) RunDiff {
+	var diff RunDiff
+
+	previousKeys := make(map[string]bool, len(previous))
+	for _, finding := range previous {
+		previousKeys[finding.Key()] = true
+	}
+	currentKeys := make(map[string]bool, len(current))
+	for _, finding := range current {
+		currentKeys[finding.Key()] = true
+	}
+
+	types := make(map[string]*TypeChange)
+	typeChange := func(resourceType string) *TypeChange {
+		change, ok := types[resourceType]
+		if !ok {
+			change = &TypeChange{ResourceType: resourceType}
+			types[resourceType] = change
+		}
+		return change
+	}
+	for _, finding := range previous {
+		change := typeChange(finding.ResourceType)
+		change.PreviousCount++
+		change.PreviousSavings += finding.SavingsCost()
+		if !currentKeys[finding.Key()] {
+			diff.Resolved = append(diff.Resolved, finding)
+			change.Resolved++
+		}
+	}
+	for _, finding := range current {
+		change := typeChange(finding.ResourceType)
+		change.CurrentCount++
+		change.CurrentSavings += finding.SavingsCost()
+		if previousKeys[finding.Key()] {
+			diff.Unchanged++
+			change.Unchanged++
+		} else {
+			diff.New = append(diff.New, finding)
+			change.New++
+		}
+	}
+
+	byCost := func(findings []Finding) {
+		sort.SliceStable(findings, func(i, j int) bool {
+			if findings[i].MonthlyCost != findings[j].MonthlyCost {
+				return findings[i].MonthlyCost > findings[j].MonthlyCost
+			}
+			return findings[i].Key() < findings[j].Key()
+		})
+	}
+	byCost(diff.New)
+	byCost(diff.Resolved)
+
+	for _, change := range types {
+		diff.ResourceTypes = append(diff.ResourceTypes, *change)
+	}
+	sort.Slice(diff.ResourceTypes, func(i, j int) bool {
+		a, b := diff.ResourceTypes[i], diff.ResourceTypes[j]
+		aChange, bChange := math.Abs(a.CurrentSavings-a.PreviousSavings), math.Abs(b.CurrentSavings-b.PreviousSavings)
+		if aChange != bChange {
+			return aChange > bChange
+		}
+		return a.ResourceType < b.ResourceType
+	})
+
+	return diff
+}
@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// DefaultDatadogSite is the Datadog site metrics are sent to unless another is configured
+const DefaultDatadogSite = "datadoghq.com"
+
+// datadogGauge is the type of gauge series of the Datadog metrics API
+const datadogGauge = 3
+
+// datadogPayload is the body of the Datadog v2 series API
+type datadogPayload struct {
+	Series []datadogSeries `json:"series"`
+}
+
+type datadogSeries struct {
+	Metric string         `json:"metric"`
+	Type   int            `json:"type"`
+	Points []datadogPoint `json:"points"`
+	Tags   []string       `json:"tags,omitempty"`
+}
+
+type datadogPoint struct {
+	Timestamp int64   `json:"timestamp"`
+	Value     float64 `json:"value"`
+}
+
+// DatadogURL returns the series API URL of a Datadog site, such as datadoghq.eu
+func DatadogURL(site string) string {
+	if site == "" {
+		site = DefaultDatadogSite
+	}
+	return "https://api." + site + "/api/v2/series"
+}
+
+// PushDatadog sends the metric families of a finished scan to the Datadog series API as
+// gauges, e.g. cloudsift.findings tagged with account_id, account_name and resource_type.
+// The tags, in key:value form, are added to every series.
+func PushDatadog(client *http.Client, url, apiKey string, summary ScanSummary, tags []string) error {
+	timestamp := summary.CompletedAt.Unix()
+	var series []datadogSeries
+	for _, family := range SummaryFamilies(summary) {
+		for _, sample := range family.Samples {
+			seriesTags := append([]string(nil), tags...)
+			for _, label := range sample.Labels {
+				seriesTags = append(seriesTags, label.Name+":"+datadogTagValue(label.Value))
+			}
+			series = append(series, datadogSeries{
+				Metric: dottedName(family.Name),
+				Type:   datadogGauge,
+				Points: []datadogPoint{{Timestamp: timestamp, Value: sample.Value}},
+				Tags:   seriesTags,
+			})
+		}
+	}
+
+	headers := map[string]string{"DD-API-KEY": apiKey}
+	for start := 0; start < len(series); start += exportBatchSize {
+		end := min(start+exportBatchSize, len(series))
+		if err := postJSON(client, url, headers, datadogPayload{Series: series[start:end]}); err != nil {
+			return fmt.Errorf("failed to send metrics to Datadog: %w", err)
+		}
+	}
+	return nil
+}
+
+// datadogTagValue returns a label value as a tag value, which Datadog does not allow commas in
+func datadogTagValue(value string) string {
+	return strings.ReplaceAll(value, ",", "_")
+}
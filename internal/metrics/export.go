@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// exportBatchSize is the number of series sent per request to monitoring APIs, which limit the
+// size of a payload
+const exportBatchSize = 500
+
+// dottedName returns the name of a metric family in the dotted style of Datadog and New Relic,
+// e.g. cloudsift.scan.duration_seconds for cloudsift_scan_duration_seconds
+func dottedName(name string) string {
+	name = strings.TrimPrefix(name, "cloudsift_")
+	if rest, ok := strings.CutPrefix(name, "scan_"); ok {
+		name = "scan." + rest
+	}
+	return "cloudsift." + name
+}
+
+// postJSON posts a JSON payload to a monitoring API and fails unless it is accepted
+func postJSON(client *http.Client, url string, headers map[string]string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid URL: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("unexpected status %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
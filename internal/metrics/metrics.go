@@ -0,0 +1,86 @@
+// Package metrics exports the summary and worker pool metrics of scans to monitoring systems
+package metrics
+
+import (
+	"sort"
+	"time"
+)
+
+// ScanSummary is the summary of a finished scan exported as metrics
+type ScanSummary struct {
+	CompletedAt     time.Time
+	DurationSeconds float64
+	Accounts        int
+	SkippedAccounts int
+	CompletedTasks  int64
+	FailedTasks     int64
+	PeakWorkers     int64
+	MaxWorkers      int
+	APICalls        int64
+	Currency        string          // Currency of the estimated savings
+	Groups          []FindingsGroup // By account and resource type, see SortGroups
+}
+
+// FindingsGroup is the number of findings and estimated monthly savings of a resource type in
+// an account
+type FindingsGroup struct {
+	AccountID      string
+	AccountName    string
+	ResourceType   string
+	Findings       int
+	MonthlySavings float64
+}
+
+// PoolStats are the live metrics of the worker pool running the tasks of a scan
+type PoolStats struct {
+	TotalTasks     int64 // Tasks submitted so far
+	CompletedTasks int64
+	FailedTasks    int64
+	ActiveWorkers  int64
+	PeakWorkers    int64
+	MaxWorkers     int
+	PlannedTasks   int // Tasks the scan runs in total, 0 while they are planned
+}
+
+// Totals returns the number of findings and estimated monthly savings of all groups
+func (s ScanSummary) Totals() (int, float64) {
+	findings, savings := 0, 0.0
+	for _, group := range s.Groups {
+		findings += group.Findings
+		savings += group.MonthlySavings
+	}
+	return findings, savings
+}
+
+// ResourceTypeTotals returns the findings and estimated monthly savings of each resource type
+// across accounts, sorted by resource type
+func (s ScanSummary) ResourceTypeTotals() []FindingsGroup {
+	totals := make(map[string]*FindingsGroup)
+	for _, group := range s.Groups {
+		total, ok := totals[group.ResourceType]
+		if !ok {
+			total = &FindingsGroup{ResourceType: group.ResourceType}
+			totals[group.ResourceType] = total
+		}
+		total.Findings += group.Findings
+		total.MonthlySavings += group.MonthlySavings
+	}
+	groups := make([]FindingsGroup, 0, len(totals))
+	for _, total := range totals {
+		groups = append(groups, *total)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].ResourceType < groups[j].ResourceType
+	})
+	return groups
+}
+
+// SortGroups sorts findings groups by account and resource type, so exports are stable
+func SortGroups(groups []FindingsGroup) {
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].AccountID != groups[j].AccountID {
+			return groups[i].AccountID < groups[j].AccountID
+		}
+		return groups[i].ResourceType < groups[j].ResourceType
+	})
+}
@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// newRelicPayload is the body of the New Relic Metric API
+type newRelicPayload []newRelicBatch
+
+type newRelicBatch struct {
+	Common  newRelicCommon   `json:"common"`
+	Metrics []newRelicMetric `json:"metrics"`
+}
+
+type newRelicCommon struct {
+	Timestamp  int64             `json:"timestamp"` // Milliseconds since the epoch
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+type newRelicMetric struct {
+	Name       string            `json:"name"`
+	Type       string            `json:"type"`
+	Value      float64           `json:"value"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// NewRelicURL returns the Metric API URL of a New Relic region, us or eu
+func NewRelicURL(region string) string {
+	if strings.EqualFold(region, "eu") {
+		return "https://metric-api.eu.newrelic.com/metric/v1"
+	}
+	return "https://metric-api.newrelic.com/metric/v1"
+}
+
+// PushNewRelic sends the metric families of a finished scan to the New Relic Metric API as
+// gauges, e.g. cloudsift.findings with the account_id, account_name and resource_type
+// attributes. The tags, in key:value form, are added as attributes of every metric.
+func PushNewRelic(client *http.Client, url, apiKey string, summary ScanSummary, tags []string) error {
+	common := newRelicCommon{Timestamp: summary.CompletedAt.UnixMilli()}
+	for _, tag := range tags {
+		key, value, _ := strings.Cut(tag, ":")
+		if common.Attributes == nil {
+			common.Attributes = make(map[string]string)
+		}
+		common.Attributes[key] = value
+	}
+
+	var metrics []newRelicMetric
+	for _, family := range SummaryFamilies(summary) {
+		for _, sample := range family.Samples {
+			metric := newRelicMetric{Name: dottedName(family.Name), Type: "gauge", Value: sample.Value}
+			for _, label := range sample.Labels {
+				if metric.Attributes == nil {
+					metric.Attributes = make(map[string]string)
+				}
+				metric.Attributes[label.Name] = label.Value
+			}
+			metrics = append(metrics, metric)
+		}
+	}
+
+	headers := map[string]string{"Api-Key": apiKey}
+	for start := 0; start < len(metrics); start += exportBatchSize {
+		end := min(start+exportBatchSize, len(metrics))
+		payload := newRelicPayload{{Common: common, Metrics: metrics[start:end]}}
+		if err := postJSON(client, url, headers, payload); err != nil {
+			return fmt.Errorf("failed to send metrics to New Relic: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,244 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloudsift/internal/logging"
+)
+
+// prometheusContentType is the content type of the Prometheus text exposition format
+const prometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// PrometheusJob is the job label scans are pushed to the Pushgateway with
+const PrometheusJob = "cloudsift"
+
+// Family is a metric family of the Prometheus text exposition format
+type Family struct {
+	Name    string
+	Help    string
+	Type    string // gauge or counter
+	Samples []Sample
+}
+
+// Sample is a value of a metric family with its labels
+type Sample struct {
+	Labels []Label
+	Value  float64
+}
+
+// Label is a label of a sample
+type Label struct {
+	Name  string
+	Value string
+}
+
+// gauge returns a gauge family with a single unlabeled sample
+func gauge(name, help string, value float64) Family {
+	return Family{Name: name, Help: help, Type: "gauge", Samples: []Sample{{Value: value}}}
+}
+
+// SummaryFamilies returns the metric families of a finished scan: the findings and estimated
+// monthly savings by account and resource type, and the totals and run metrics of the scan
+func SummaryFamilies(summary ScanSummary) []Family {
+	findings := Family{Name: "cloudsift_findings", Help: "Unused resources found by the last scan.", Type: "gauge"}
+	savings := Family{Name: "cloudsift_monthly_savings", Help: "Estimated monthly savings of the unused resources found by the last scan.", Type: "gauge"}
+	for _, group := range summary.Groups {
+		labels := []Label{
+			{"account_id", group.AccountID},
+			{"account_name", group.AccountName},
+			{"resource_type", group.ResourceType},
+		}
+		findings.Samples = append(findings.Samples, Sample{Labels: labels, Value: float64(group.Findings)})
+		savings.Samples = append(savings.Samples, Sample{Labels: append(labels, Label{"currency", summary.Currency}), Value: group.MonthlySavings})
+	}
+
+	return []Family{
+		findings,
+		savings,
+		gauge("cloudsift_scan_accounts", "Accounts scanned by the last scan.", float64(summary.Accounts)),
+		gauge("cloudsift_scan_skipped_accounts", "Accounts skipped by the last scan, such as suspended accounts.", float64(summary.SkippedAccounts)),
+		{
+			Name: "cloudsift_scan_tasks",
+			Help: "Scanner tasks of the last scan by state.",
+			Type: "gauge",
+			Samples: []Sample{
+				{Labels: []Label{{"state", "completed"}}, Value: float64(summary.CompletedTasks)},
+				{Labels: []Label{{"state", "failed"}}, Value: float64(summary.FailedTasks)},
+			},
+		},
+		gauge("cloudsift_scan_duration_seconds", "Run time of the last scan.", summary.DurationSeconds),
+		gauge("cloudsift_scan_peak_workers", "Most workers running scanner tasks at the same time in the last scan.", float64(summary.PeakWorkers)),
+		gauge("cloudsift_scan_max_workers", "Maximum number of workers of the last scan.", float64(summary.MaxWorkers)),
+		gauge("cloudsift_scan_api_calls", "AWS API calls of the last scan.", float64(summary.APICalls)),
+		gauge("cloudsift_scan_last_completed_timestamp_seconds", "Completion time of the last scan as a Unix timestamp.", float64(summary.CompletedAt.Unix())),
+	}
+}
+
+// PoolFamilies returns the metric families of the worker pool of a running scan
+func PoolFamilies(stats PoolStats) []Family {
+	return []Family{
+		{
+			Name: "cloudsift_worker_pool_tasks",
+			Help: "Scanner tasks of the running scan by state.",
+			Type: "gauge",
+			Samples: []Sample{
+				{Labels: []Label{{"state", "submitted"}}, Value: float64(stats.TotalTasks)},
+				{Labels: []Label{{"state", "completed"}}, Value: float64(stats.CompletedTasks)},
+				{Labels: []Label{{"state", "failed"}}, Value: float64(stats.FailedTasks)},
+			},
+		},
+		gauge("cloudsift_worker_pool_planned_tasks", "Scanner tasks the running scan runs in total.", float64(stats.PlannedTasks)),
+		gauge("cloudsift_worker_pool_active_workers", "Workers running scanner tasks.", float64(stats.ActiveWorkers)),
+		gauge("cloudsift_worker_pool_peak_workers", "Most workers running scanner tasks at the same time.", float64(stats.PeakWorkers)),
+		gauge("cloudsift_worker_pool_max_workers", "Maximum number of workers.", float64(stats.MaxWorkers)),
+	}
+}
+
+// WritePrometheus writes metric families in the Prometheus text exposition format
+func WritePrometheus(w io.Writer, families []Family) error {
+	var buf bytes.Buffer
+	for _, family := range families {
+		fmt.Fprintf(&buf, "# HELP %s %s\n", family.Name, escapeHelp(family.Help))
+		fmt.Fprintf(&buf, "# TYPE %s %s\n", family.Name, family.Type)
+		for _, sample := range family.Samples {
+			buf.WriteString(family.Name)
+			if len(sample.Labels) > 0 {
+				labels := make([]string, 0, len(sample.Labels))
+				for _, label := range sample.Labels {
+					labels = append(labels, fmt.Sprintf("%s=\"%s\"", label.Name, escapeLabelValue(label.Value)))
+				}
+				buf.WriteString("{" + strings.Join(labels, ",") + "}")
+			}
+			buf.WriteString(" " + strconv.FormatFloat(sample.Value, 'g', -1, 64) + "\n")
+		}
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// escapeHelp escapes the backslashes and line feeds of a help text
+func escapeHelp(help string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help)
+}
+
+// escapeLabelValue escapes the backslashes, double quotes and line feeds of a label value
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// WritePrometheusFile writes metric families to a file, such as a .prom file of the
+// node_exporter textfile collector. The file is replaced atomically, so it is never read
+// half written.
+func WritePrometheusFile(path string, families []Family) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create metrics directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to create metrics file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := WritePrometheus(tmp, families); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write metrics file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write metrics file: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return fmt.Errorf("failed to write metrics file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace metrics file: %w", err)
+	}
+	return nil
+}
+
+// PushPrometheus pushes metric families to a Prometheus Pushgateway, replacing the metrics
+// previously pushed for the job
+func PushPrometheus(client *http.Client, gatewayURL, job string, families []Family) error {
+	var buf bytes.Buffer
+	if err := WritePrometheus(&buf, families); err != nil {
+		return err
+	}
+	url := strings.TrimSuffix(gatewayURL, "/") + "/metrics/job/" + job
+	req, err := http.NewRequest(http.MethodPut, url, &buf)
+	if err != nil {
+		return fmt.Errorf("invalid Pushgateway URL: %w", err)
+	}
+	req.Header.Set("Content-Type", prometheusContentType)
+
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to push metrics: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("failed to push metrics: unexpected status %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// PrometheusServer serves the metrics of a running scan on /metrics
+type PrometheusServer struct {
+	server   *http.Server
+	listener net.Listener
+}
+
+// ServePrometheus starts serving the metric families returned by collect on /metrics of the
+// address. The address is bound before returning, so it fails right away if it is in use.
+func ServePrometheus(address string, collect func() []Family) (*PrometheusServer, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", address, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", prometheusContentType)
+		if err := WritePrometheus(w, collect()); err != nil {
+			logging.Debug("Failed to write metrics response", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+	})
+	s := &PrometheusServer{
+		server:   &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second},
+		listener: listener,
+	}
+	go func() {
+		if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logging.Error("Metrics server stopped", err, nil)
+		}
+	}()
+	return s, nil
+}
+
+// Address returns the address the server listens on
+func (s *PrometheusServer) Address() string {
+	return s.listener.Addr().String()
+}
+
+// Close stops the server, letting running scrapes finish
+func (s *PrometheusServer) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return s.server.Shutdown(ctx)
+}
@@ -0,0 +1,215 @@
+// Package notifications posts summaries of finished scans to chat channels
+package notifications
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloudsift/internal/logging"
+)
+
+const (
+	defaultMaxRetries = 3
+	defaultRetryDelay = 2 * time.Second
+
+	// slackMaxSectionText is the maximum length of the text of a Block Kit section
+	slackMaxSectionText = 3000
+	// slackMaxResourceTypes is the maximum number of resource types listed in a message
+	slackMaxResourceTypes = 25
+)
+
+// Summary is the summary of a finished scan
+type Summary struct {
+	Accounts          int
+	Findings          int
+	Errors            int     // Failed scanner tasks
+	MonthlySavings    float64 // Estimated monthly savings of all findings but low confidence ones
+	UnverifiedSavings float64 // Monthly cost of low confidence findings, whose usage could not be verified
+	CurrencySymbol    string
+	ResourceTypes     []ResourceTypeSummary // Highest monthly savings first
+	ReportURL         string                // Location of the results, e.g. in S3; empty if there is none to link
+}
+
+// ResourceTypeSummary is the findings count and estimated monthly savings of a resource type
+type ResourceTypeSummary struct {
+	ResourceType   string
+	Findings       int
+	MonthlySavings float64
+}
+
+// SlackNotifier posts scan summaries to a Slack incoming webhook as Block Kit messages
+type SlackNotifier struct {
+	WebhookURL string
+	Client     *http.Client
+	MaxRetries int           // Attempts of a post, including the first
+	RetryDelay time.Duration // Delay between attempts unless Slack sets Retry-After
+}
+
+// NewSlackNotifier creates a notifier posting to a Slack incoming webhook with default settings
+func NewSlackNotifier(webhookURL string) *SlackNotifier {
+	return &SlackNotifier{
+		WebhookURL: webhookURL,
+		Client:     &http.Client{Timeout: 10 * time.Second},
+		MaxRetries: defaultMaxRetries,
+		RetryDelay: defaultRetryDelay,
+	}
+}
+
+// Notify posts the summary of a scan. Rate limited and failed posts are retried; other
+// rejected posts are not, since they fail the same way again.
+func (n *SlackNotifier) Notify(summary Summary) error {
+	payload, err := json.Marshal(slackMessage(summary))
+	if err != nil {
+		return fmt.Errorf("failed to marshal Slack message: %w", err)
+	}
+
+	client := n.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	maxRetries := max(n.MaxRetries, 1)
+
+	var lastErr error
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		delay, err := n.post(client, payload)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if delay < 0 {
+			break // Not retryable
+		}
+		if attempt < maxRetries-1 {
+			logging.Warn("Retrying Slack notification", map[string]interface{}{
+				"attempt": attempt + 1,
+				"error":   err.Error(),
+			})
+			time.Sleep(delay)
+		}
+	}
+	return fmt.Errorf("failed to post Slack notification: %w", lastErr)
+}
+
+// post posts a payload to the webhook. Failed posts return the delay before they may be
+// retried, or a negative delay if they must not be.
+func (n *SlackNotifier) post(client *http.Client, payload []byte) (time.Duration, error) {
+	resp, err := client.Post(n.WebhookURL, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return n.RetryDelay, err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return 0, nil
+	case resp.StatusCode == http.StatusTooManyRequests:
+		delay := n.RetryDelay
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			delay = time.Duration(seconds) * time.Second
+		}
+		return delay, fmt.Errorf("rate limited by Slack")
+	case resp.StatusCode >= 500:
+		return n.RetryDelay, fmt.Errorf("unexpected status %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	default:
+		return -1, fmt.Errorf("unexpected status %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+}
+
+// slackBlock is a Block Kit block
+type slackBlock struct {
+	Type      string       `json:"type"`
+	Text      *slackText   `json:"text,omitempty"`
+	Fields    []slackText  `json:"fields,omitempty"`
+	Accessory *slackButton `json:"accessory,omitempty"`
+}
+
+// slackText is a Block Kit text object
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// slackButton is a Block Kit button linking to a URL
+type slackButton struct {
+	Type string    `json:"type"`
+	Text slackText `json:"text"`
+	URL  string    `json:"url"`
+}
+
+// slackMessage builds the Block Kit message of a scan summary. The text is also the
+// notification shown by clients that do not render blocks.
+func slackMessage(summary Summary) map[string]interface{} {
+	savings := formatSavings(summary.CurrencySymbol, summary.MonthlySavings)
+	text := fmt.Sprintf("CloudSift scan finished: %d findings in %d accounts, %s/month in potential savings",
+		summary.Findings, summary.Accounts, savings)
+
+	blocks := []slackBlock{
+		{Type: "header", Text: &slackText{Type: "plain_text", Text: "CloudSift scan finished"}},
+		{Type: "section", Fields: []slackText{
+			{Type: "mrkdwn", Text: fmt.Sprintf("*Potential savings*\n%s/month", savings)},
+			{Type: "mrkdwn", Text: fmt.Sprintf("*Findings*\n%d", summary.Findings)},
+			{Type: "mrkdwn", Text: fmt.Sprintf("*Accounts*\n%d", summary.Accounts)},
+			{Type: "mrkdwn", Text: fmt.Sprintf("*Failed tasks*\n%d", summary.Errors)},
+		}},
+	}
+
+	if summary.UnverifiedSavings > 0 {
+		blocks = append(blocks, slackBlock{
+			Type: "section",
+			Text: &slackText{Type: "mrkdwn", Text: fmt.Sprintf("_%s/month of low confidence findings is not counted, their usage could not be verified_",
+				formatSavings(summary.CurrencySymbol, summary.UnverifiedSavings))},
+		})
+	}
+
+	if len(summary.ResourceTypes) > 0 {
+		blocks = append(blocks, slackBlock{
+			Type: "section",
+			Text: &slackText{Type: "mrkdwn", Text: resourceTypeLines(summary)},
+		})
+	}
+
+	if summary.ReportURL != "" {
+		blocks = append(blocks, slackBlock{
+			Type: "section",
+			Text: &slackText{Type: "mrkdwn", Text: "The results were written to S3."},
+			Accessory: &slackButton{
+				Type: "button",
+				Text: slackText{Type: "plain_text", Text: "Open report"},
+				URL:  summary.ReportURL,
+			},
+		})
+	}
+
+	return map[string]interface{}{"text": text, "blocks": blocks}
+}
+
+// resourceTypeLines lists the findings and savings of the resource types, truncated to the
+// resource type limit and the section text limit of Slack
+func resourceTypeLines(summary Summary) string {
+	var b strings.Builder
+	b.WriteString("*Findings by resource type*")
+	for i, resourceType := range summary.ResourceTypes {
+		line := fmt.Sprintf("\n• %s: %d (%s/month)", resourceType.ResourceType, resourceType.Findings,
+			formatSavings(summary.CurrencySymbol, resourceType.MonthlySavings))
+		remaining := len(summary.ResourceTypes) - i
+		more := fmt.Sprintf("\n_…and %d more resource types_", remaining)
+		if i == slackMaxResourceTypes || b.Len()+len(line)+len(more) > slackMaxSectionText {
+			b.WriteString(more)
+			break
+		}
+		b.WriteString(line)
+	}
+	return b.String()
+}
+
+// formatSavings formats an amount with its currency symbol
+func formatSavings(currencySymbol string, amount float64) string {
+	return fmt.Sprintf("%s%.2f", currencySymbol, amount)
+}
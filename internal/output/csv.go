@@ -0,0 +1,108 @@
+package output
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	awsutil "cloudsift/internal/aws"
+)
+
+// csvHeader is the header row of CSV results
+var csvHeader = []string{
+	"account_id",
+	"account_name",
+	"region",
+	"resource_type",
+	"resource_name",
+	"resource_id",
+	"reason",
+	"monthly_cost",
+	"currency",
+}
+
+// csvFormulaPrefixes are the first characters spreadsheets evaluate a cell as a formula from
+var csvFormulaPrefixes = "=+-@\t\r"
+
+// csvText returns a text value for a CSV cell. Values starting like a formula are prefixed
+// with a quote, since resource names, tags and reasons are set by anyone able to create
+// resources and would otherwise run as formulas when the file is opened in a spreadsheet.
+func csvText(value string) string {
+	if value != "" && strings.ContainsRune(csvFormulaPrefixes, rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// WriteCSV writes scan results as CSV with one row per finding, so they can be imported into
+// spreadsheets and BI tools. Rows are sorted by account, region, resource type and ID. The
+// reasons of a finding are joined into one column. The monthly cost is in the given currency,
+// USD if empty, and it and the currency are empty for findings without a cost estimate.
+func WriteCSV(w io.Writer, results []awsutil.ScanResult, currency string) error {
+	if currency == "" {
+		currency = "USD"
+	}
+	rows := make([][]string, 0, len(results))
+	for _, result := range results {
+		region, _ := result.Details["region"].(string)
+		monthlyCost, costCurrency := "", ""
+		if costs, ok := result.Cost["total"].(*awsutil.CostBreakdown); ok && costs != nil {
+			monthlyCost = strconv.FormatFloat(costs.MonthlyRate, 'f', 2, 64)
+			costCurrency = currency
+		}
+		rows = append(rows, []string{
+			csvText(result.AccountID),
+			csvText(result.AccountName),
+			csvText(region),
+			csvText(result.ResourceType),
+			csvText(result.ResourceName),
+			csvText(result.ResourceID),
+			csvText(strings.Join(strings.Split(result.Reason, "\n"), "; ")),
+			monthlyCost,
+			costCurrency,
+		})
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		for _, column := range []int{0, 2, 3, 5} {
+			if rows[i][column] != rows[j][column] {
+				return rows[i][column] < rows[j][column]
+			}
+		}
+		return false
+	})
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		return fmt.Errorf("failed to write CSV rows: %w", err)
+	}
+	return nil
+}
+
+// WriteCSVFile writes scan results as CSV with costs in the given currency to the given path
+func WriteCSVFile(results []awsutil.ScanResult, currency, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create CSV file: %w", err)
+	}
+	if err := WriteCSV(f, results, currency); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to close CSV file: %w", err)
+	}
+	return nil
+}
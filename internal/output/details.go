@@ -0,0 +1,160 @@
+package output
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"time"
+
+	awsutil "cloudsift/internal/aws"
+)
+
+// keptNestedDetails are nested detail fields that are kept when trimming details because
+// reports, filters or the scheduling plan depend on them. All other nested maps and slices
+// (metric datapoints, attachments, raw API structures) are dropped.
+var keptNestedDetails = map[string]bool{
+	"tags":                        true,
+	"reason_codes":                true,
+	"product_codes":               true,
+	"shared_with_accounts":        true,
+	"estimated_off_hours_savings": true,
+}
+
+// TrimDetails returns a copy of a details map containing only scalar fields and the
+// curated nested fields in keptNestedDetails
+func TrimDetails(details map[string]interface{}) map[string]interface{} {
+	if details == nil {
+		return nil
+	}
+	trimmed := make(map[string]interface{}, len(details))
+	for key, value := range details {
+		if keptNestedDetails[key] || isScalarDetail(value) {
+			trimmed[key] = value
+		}
+	}
+	return trimmed
+}
+
+// isScalarDetail returns true for detail values that are not maps, slices or structs
+func isScalarDetail(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	if _, ok := value.(time.Time); ok {
+		return true
+	}
+	kind := reflect.TypeOf(value).Kind()
+	if kind == reflect.Ptr {
+		kind = reflect.TypeOf(value).Elem().Kind()
+	}
+	switch kind {
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct:
+		return false
+	}
+	return true
+}
+
+// detailsRecord is a single line of a details file
+type detailsRecord struct {
+	ResourceType string                 `json:"resource_type"`
+	ResourceID   string                 `json:"resource_id"`
+	Details      map[string]interface{} `json:"details"`
+}
+
+// detailsFile is an open, gzip compressed details file for one account
+type detailsFile struct {
+	file    *os.File
+	gz      *gzip.Writer
+	buf     *bufio.Writer
+	encoder *json.Encoder
+}
+
+// DetailsSpool writes the full details of scan results to one gzip compressed JSON lines file
+// per account as each scanner task finishes, so only trimmed details are kept in memory for
+// the rest of the scan. Scanners still hold the full details of a task's results until it
+// finishes.
+type DetailsSpool struct {
+	dir   string
+	mu    sync.Mutex
+	files map[string]*detailsFile
+}
+
+// NewDetailsSpool creates a spool writing details files to dir
+func NewDetailsSpool(dir string) (*DetailsSpool, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create details directory %s: %w", dir, err)
+	}
+	return &DetailsSpool{
+		dir:   dir,
+		files: make(map[string]*detailsFile),
+	}, nil
+}
+
+// Dir returns the directory details files are written to
+func (s *DetailsSpool) Dir() string {
+	return s.dir
+}
+
+// Write appends the full details of the results to the account's details file, flushes them
+// to disk and then trims the details of the results in place
+func (s *DetailsSpool) Write(accountID string, results []awsutil.ScanResult) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, ok := s.files[accountID]
+	if !ok {
+		path := filepath.Join(s.dir, accountID+".jsonl.gz")
+		f, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("failed to create details file %s: %w", path, err)
+		}
+		gz := gzip.NewWriter(f)
+		buf := bufio.NewWriter(gz)
+		file = &detailsFile{file: f, gz: gz, buf: buf, encoder: json.NewEncoder(buf)}
+		s.files[accountID] = file
+	}
+
+	for i := range results {
+		if err := file.encoder.Encode(detailsRecord{
+			ResourceType: results[i].ResourceType,
+			ResourceID:   results[i].ResourceID,
+			Details:      results[i].Details,
+		}); err != nil {
+			return fmt.Errorf("failed to write details for %s: %w", results[i].ResourceID, err)
+		}
+		results[i].Details = TrimDetails(results[i].Details)
+	}
+	if err := file.buf.Flush(); err != nil {
+		return fmt.Errorf("failed to flush details for account %s: %w", accountID, err)
+	}
+	if err := file.gz.Flush(); err != nil {
+		return fmt.Errorf("failed to compress details for account %s: %w", accountID, err)
+	}
+	return nil
+}
+
+// Close flushes and closes all details files
+func (s *DetailsSpool) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var firstErr error
+	for accountID, file := range s.files {
+		if err := file.buf.Flush(); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("failed to flush details for account %s: %w", accountID, err)
+		}
+		if err := file.gz.Close(); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("failed to compress details for account %s: %w", accountID, err)
+		}
+		if err := file.file.Close(); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("failed to close details for account %s: %w", accountID, err)
+		}
+	}
+	s.files = make(map[string]*detailsFile)
+	return firstErr
+}
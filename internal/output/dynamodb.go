@@ -0,0 +1,178 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	awsutil "cloudsift/internal/aws"
+	"cloudsift/internal/logging"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+const (
+	// Key attributes of finding items. The partition key is the account ID and the sort key
+	// the resource ID, resource type and scan time, so each scan adds a new item per finding.
+	dynamoDBPartitionKey = "pk"
+	dynamoDBSortKey      = "sk"
+
+	// dynamoDBBatchSize is the maximum number of items of a BatchWriteItem request
+	dynamoDBBatchSize = 25
+
+	// dynamoDBMaxRetries bounds the retries of items left unprocessed by throttling
+	dynamoDBMaxRetries = 5
+
+	// dynamoDBMaxItemSize is the size limit of a DynamoDB item, counting the names and values
+	// of its attributes
+	dynamoDBMaxItemSize = 400 * 1024
+)
+
+// DynamoDBFindingItem converts a scan result to a DynamoDB item keyed by account and by
+// resource ID, resource type and scan time. The monthly cost is in the given currency. Details
+// that would take the item over DynamoDB's size limit are trimmed to their scalar fields, or
+// dropped if that is not enough, and the item is marked with details_truncated.
+func DynamoDBFindingItem(result awsutil.ScanResult, currency string, scanTime time.Time) (map[string]*dynamodb.AttributeValue, error) {
+	scannedAt := scanTime.UTC().Format(time.RFC3339)
+	region, _ := result.Details["region"].(string)
+
+	details, err := json.Marshal(result.Details)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal details of %s: %w", result.ResourceID, err)
+	}
+
+	item := map[string]*dynamodb.AttributeValue{
+		dynamoDBPartitionKey: {S: aws.String(result.AccountID)},
+		dynamoDBSortKey:      {S: aws.String(fmt.Sprintf("%s#%s#%s", result.ResourceID, result.ResourceType, scannedAt))},
+		"scanned_at":         {S: aws.String(scannedAt)},
+		"account_id":         {S: aws.String(result.AccountID)},
+		"resource_type":      {S: aws.String(result.ResourceType)},
+		"resource_id":        {S: aws.String(result.ResourceID)},
+		"severity":           {S: aws.String(Severity(result))},
+		"monthly_cost":       {N: aws.String(fmt.Sprintf("%.4f", MonthlyCost(result)))},
+		"currency":           {S: aws.String(currency)},
+		"details":            {S: aws.String(string(details))},
+	}
+
+	// Optional attributes are left out when empty to keep items small
+	optional := map[string]string{
+		"account_name":        result.AccountName,
+		"account_team":        result.AccountTeam,
+		"account_environment": result.AccountEnv,
+		"resource_name":       result.ResourceName,
+		"region":              region,
+		"reason":              result.Reason,
+	}
+	for name, value := range optional {
+		if value != "" {
+			item[name] = &dynamodb.AttributeValue{S: aws.String(value)}
+		}
+	}
+	if len(result.Tags) > 0 {
+		item["tags"] = &dynamodb.AttributeValue{M: make(map[string]*dynamodb.AttributeValue, len(result.Tags))}
+		for key, value := range result.Tags {
+			item["tags"].M[key] = &dynamodb.AttributeValue{S: aws.String(value)}
+		}
+	}
+
+	if dynamoDBItemSize(item) > dynamoDBMaxItemSize {
+		item["details_truncated"] = &dynamodb.AttributeValue{BOOL: aws.Bool(true)}
+		trimmed, err := json.Marshal(TrimDetails(result.Details))
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal details of %s: %w", result.ResourceID, err)
+		}
+		item["details"] = &dynamodb.AttributeValue{S: aws.String(string(trimmed))}
+		if dynamoDBItemSize(item) > dynamoDBMaxItemSize {
+			delete(item, "details")
+		}
+		if dynamoDBItemSize(item) > dynamoDBMaxItemSize {
+			return nil, fmt.Errorf("item of %s exceeds the DynamoDB item size limit without its details", result.ResourceID)
+		}
+	}
+	return item, nil
+}
+
+// dynamoDBItemSize returns the size of an item as DynamoDB counts it towards the item size
+// limit, rounding numbers up to their string length
+func dynamoDBItemSize(item map[string]*dynamodb.AttributeValue) int {
+	size := 0
+	for name, value := range item {
+		size += len(name) + dynamoDBAttributeSize(value)
+	}
+	return size
+}
+
+// dynamoDBAttributeSize returns the size of an attribute value of the types finding items use
+func dynamoDBAttributeSize(value *dynamodb.AttributeValue) int {
+	switch {
+	case value.S != nil:
+		return len(*value.S)
+	case value.N != nil:
+		return len(*value.N)
+	case value.M != nil:
+		// Maps take 3 bytes and 1 byte per element on top of their elements
+		return 3 + len(value.M) + dynamoDBItemSize(value.M)
+	default:
+		return 1 // Booleans and nulls
+	}
+}
+
+// WriteDynamoDBFindings writes each scan result as an item to a DynamoDB table in a region and
+// returns the number of items written. The table must have a string partition key pk and a
+// string sort key sk. A resource reported twice as the same resource type is written once.
+func WriteDynamoDBFindings(sess *session.Session, table, region string, results []awsutil.ScanResult, currency string, scanTime time.Time) (int, error) {
+	client := dynamodb.New(sess, aws.NewConfig().WithRegion(region))
+
+	// BatchWriteItem rejects requests writing the same key twice
+	var requests []*dynamodb.WriteRequest
+	seen := make(map[string]bool)
+	for _, result := range results {
+		item, err := DynamoDBFindingItem(result, currency, scanTime)
+		if err != nil {
+			return 0, err
+		}
+		// The sort key holds the resource type, so resources of different types sharing an ID
+		// are both written
+		key := aws.StringValue(item[dynamoDBPartitionKey].S) + "/" + aws.StringValue(item[dynamoDBSortKey].S)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		requests = append(requests, &dynamodb.WriteRequest{PutRequest: &dynamodb.PutRequest{Item: item}})
+	}
+
+	written := 0
+	for start := 0; start < len(requests); start += dynamoDBBatchSize {
+		end := start + dynamoDBBatchSize
+		if end > len(requests) {
+			end = len(requests)
+		}
+
+		// Retry items left unprocessed, e.g. when the table is throttled
+		pending := map[string][]*dynamodb.WriteRequest{table: requests[start:end]}
+		for attempt := 0; len(pending[table]) > 0; attempt++ {
+			if attempt > dynamoDBMaxRetries {
+				return written, fmt.Errorf("failed to write %d findings to DynamoDB table %s after %d retries", len(pending[table]), table, dynamoDBMaxRetries)
+			}
+			if attempt > 0 {
+				time.Sleep(time.Duration(1<<attempt) * 100 * time.Millisecond)
+			}
+
+			output, err := client.BatchWriteItem(&dynamodb.BatchWriteItemInput{RequestItems: pending})
+			if err != nil {
+				return written, fmt.Errorf("failed to write findings to DynamoDB table %s: %w", table, err)
+			}
+			written += len(pending[table]) - len(output.UnprocessedItems[table])
+			pending = output.UnprocessedItems
+		}
+	}
+
+	logging.Debug("Wrote findings to DynamoDB", map[string]interface{}{
+		"table":    table,
+		"region":   region,
+		"findings": written,
+	})
+	return written, nil
+}
@@ -7,10 +7,146 @@ document.addEventListener('DOMContentLoaded', function() {
     displayLocalReportTime();
     convertTimestamps();
     initializeSearch();
+    initializePagedTable();
 });
 
 let costChart = null;
 
+// Locale settings injected by the report template (falls back to English)
+const locale = typeof reportLocale !== 'undefined' ? reportLocale : {
+    language: 'en',
+    currencySymbol: '$',
+    currencyAfter: false,
+    messages: {}
+};
+
+// Translate a message using the report locale
+function t(message) {
+    return (locale.messages && locale.messages[message]) || message;
+}
+
+// Format an amount as currency using the report locale
+function formatCurrency(value) {
+    const amount = value.toLocaleString(locale.language, {
+        minimumFractionDigits: 2,
+        maximumFractionDigits: 2
+    });
+    return locale.currencyAfter ? `${amount} ${locale.currencySymbol}` : `${locale.currencySymbol}${amount}`;
+}
+
+// Large reports embed their rows as arrays in column order and render them page by page.
+// Resource details are kept in a separate data file that is fetched when first needed.
+const pageSize = 100;
+const pagedTable = typeof reportRows !== 'undefined' ? {
+    rows: reportRows.map((cells, index) => ({ cells, index })),
+    filtered: [],
+    page: 0,
+    details: null
+} : null;
+
+// Row array index for each visible column of the resource table
+const pagedColumns = [0, 1, 3, 4, 5, 6, 7, 8];
+
+function initializePagedTable() {
+    if (!pagedTable) return;
+    pagedTable.filtered = pagedTable.rows;
+    renderPage();
+}
+
+function renderPage() {
+    const tbody = document.getElementById('scan-table-body');
+    const pageCount = Math.max(1, Math.ceil(pagedTable.filtered.length / pageSize));
+    pagedTable.page = Math.min(pagedTable.page, pageCount - 1);
+
+    const start = pagedTable.page * pageSize;
+    const fragment = document.createDocumentFragment();
+    pagedTable.filtered.slice(start, start + pageSize).forEach(row => {
+        const [accountId, accountName, accountGroup, resourceType, name, resourceId, region, reason, triage, triageNote] = row.cells;
+        const tr = document.createElement('tr');
+        tr.appendChild(textCell(accountId));
+        tr.appendChild(textCell(accountName, accountGroup));
+        tr.appendChild(textCell(resourceType));
+        tr.appendChild(textCell(name));
+        tr.appendChild(textCell(resourceId));
+        tr.appendChild(textCell(region));
+        const reasonCell = textCell(reason);
+        reasonCell.classList.add('reason-cell');
+        tr.appendChild(reasonCell);
+        const triageCell = textCell(triage, triageNote);
+        triageCell.title = triageNote;
+        tr.appendChild(triageCell);
+
+        const actions = document.createElement('td');
+        const button = document.createElement('button');
+        button.className = 'btn';
+        button.textContent = t('Details');
+        button.addEventListener('click', () => showRowDetails(row.index));
+        actions.appendChild(button);
+        tr.appendChild(actions);
+        fragment.appendChild(tr);
+    });
+    tbody.replaceChildren(fragment);
+
+    const pager = document.getElementById('pagination');
+    const previous = document.createElement('button');
+    previous.className = 'btn';
+    previous.textContent = t('Previous');
+    previous.disabled = pagedTable.page === 0;
+    previous.addEventListener('click', () => { pagedTable.page--; renderPage(); });
+    const status = document.createElement('span');
+    status.textContent = t('Page %d of %d').replace('%d', pagedTable.page + 1).replace('%d', pageCount);
+    const next = document.createElement('button');
+    next.className = 'btn';
+    next.textContent = t('Next');
+    next.disabled = pagedTable.page >= pageCount - 1;
+    next.addEventListener('click', () => { pagedTable.page++; renderPage(); });
+    pager.replaceChildren(previous, status, next);
+}
+
+// Create a table cell with the given text and an optional second line
+function textCell(text, subtext) {
+    const td = document.createElement('td');
+    td.textContent = text;
+    td.title = text;
+    if (subtext) {
+        td.appendChild(document.createElement('br'));
+        const small = document.createElement('small');
+        small.textContent = subtext;
+        td.appendChild(small);
+    }
+    return td;
+}
+
+// Fetch the details data file once and cache it
+function loadDetails() {
+    if (!pagedTable.details) {
+        pagedTable.details = fetch(reportDetailsURL).then(response => {
+            if (!response.ok) {
+                throw new Error(response.statusText);
+            }
+            return response.json();
+        }).catch(error => {
+            pagedTable.details = null;
+            throw error;
+        });
+    }
+    return pagedTable.details;
+}
+
+function showRowDetails(index) {
+    loadDetails()
+        .then(details => showDetailsModal(details[index]))
+        .catch(() => showDetailsModal(t('Details could not be loaded. Open the report through a web server to view resource details.')));
+}
+
+// Get the numeric value of a table cell, preferring the raw data-value attribute
+function cellNumber(cell) {
+    if (cell.dataset.value !== undefined) {
+        return parseFloat(cell.dataset.value);
+    }
+    return parseFloat(cell.textContent.replace(/[^0-9.-]+/g, ''));
+}
+
 // Chart initialization
 function initializeCharts() {
     // Resource Distribution Chart
@@ -92,8 +228,7 @@ function getCostData(period) {
         const cells = rows[i].getElementsByTagName('td');
         if (cells.length > columnIndex) {
             const resourceType = cells[0].textContent.trim();
-            const costText = cells[columnIndex].textContent.replace(/[$,]/g, '').trim();
-            const cost = parseFloat(costText);
+            const cost = cellNumber(cells[columnIndex]);
             
             if (!isNaN(cost) && cost > 0) {
                 labels.push(resourceType);
@@ -123,14 +258,14 @@ function updateCostChart(period) {
         costChart.destroy();
     }
     
-    const periodLabel = period.charAt(0).toUpperCase() + period.slice(1);
+    const periodLabel = t(period.charAt(0).toUpperCase() + period.slice(1));
     
     costChart = new Chart(costCtx, {
         type: 'bar',
         data: {
             labels: costData.labels,
             datasets: [{
-                label: `${periodLabel} Cost ($)`,
+                label: `${periodLabel} ${t('Cost')} (${locale.currencySymbol})`,
                 data: costData.data,
                 backgroundColor: 'rgba(60, 52, 156, 0.7)',
                 borderColor: 'rgb(60, 52, 156)',
@@ -148,11 +283,7 @@ function updateCostChart(period) {
                 tooltip: {
                     callbacks: {
                         label: (context) => {
-                            const value = context.raw;
-                            return `$${value.toLocaleString(undefined, {
-                                minimumFractionDigits: 2,
-                                maximumFractionDigits: 2
-                            })}`;
+                            return formatCurrency(context.raw);
                         }
                     }
                 }
@@ -165,10 +296,7 @@ function updateCostChart(period) {
                     },
                     ticks: {
                         callback: (value) => {
-                            return '$' + value.toLocaleString(undefined, {
-                                minimumFractionDigits: 2,
-                                maximumFractionDigits: 2
-                            });
+                            return formatCurrency(value);
                         },
                         color: '#1d1d1f',
                         font: {
@@ -235,6 +363,11 @@ function initializeSortableTables() {
 }
 
 function sortTable(table, column) {
+    if (pagedTable && table.id === 'scan-table') {
+        sortPagedTable(table, column);
+        return;
+    }
+
     const tbody = table.querySelector('tbody');
     const rows = Array.from(tbody.querySelectorAll('tr'));
     const headers = table.querySelectorAll('th');
@@ -257,8 +390,8 @@ function sortTable(table, column) {
         const bValue = b.cells[column].textContent.trim();
         
         // Check if the values are numbers (including currency)
-        const aNum = parseFloat(aValue.replace(/[^0-9.-]+/g, ''));
-        const bNum = parseFloat(bValue.replace(/[^0-9.-]+/g, ''));
+        const aNum = cellNumber(a.cells[column]);
+        const bNum = cellNumber(b.cells[column]);
         
         if (!isNaN(aNum) && !isNaN(bNum)) {
             return isAscending ? aNum - bNum : bNum - aNum;
@@ -273,6 +406,24 @@ function sortTable(table, column) {
     rows.forEach(row => tbody.appendChild(row));
 }
 
+function sortPagedTable(table, column) {
+    const headers = table.querySelectorAll('th');
+    const isAscending = !headers[column].classList.contains('sorted-asc');
+    headers.forEach(header => {
+        header.classList.remove('sorted-asc', 'sorted-desc');
+    });
+    headers[column].classList.add(isAscending ? 'sorted-asc' : 'sorted-desc');
+
+    const cell = pagedColumns[column];
+    pagedTable.filtered = pagedTable.filtered.slice().sort((a, b) => {
+        return isAscending ?
+            a.cells[cell].localeCompare(b.cells[cell]) :
+            b.cells[cell].localeCompare(a.cells[cell]);
+    });
+    pagedTable.page = 0;
+    renderPage();
+}
+
 // Search functionality
 function initializeSearch() {
     const searchInput = document.getElementById('search-input');
@@ -289,6 +440,15 @@ function initializeSearch() {
 function filterTable() {
     const input = document.getElementById('search-input');
     const filter = input.value.toLowerCase();
+
+    if (pagedTable) {
+        pagedTable.filtered = pagedTable.rows.filter(row =>
+            row.cells.some(cell => cell.toLowerCase().includes(filter)));
+        pagedTable.page = 0;
+        renderPage();
+        return;
+    }
+
     const table = document.getElementById('scan-table');
     const rows = table.getElementsByTagName('tr');
     
@@ -341,8 +501,8 @@ function showDetailsModal(details) {
     const modal = document.getElementById('details-modal');
     const modalContent = document.getElementById('modal-content');
     
-    // Format the JSON nicely
-    modalContent.textContent = JSON.stringify(details, null, 2);
+    // Format the JSON nicely, messages are shown as they are
+    modalContent.textContent = typeof details === 'string' ? details : JSON.stringify(details, null, 2);
     
     modal.style.display = 'block';
 }
@@ -392,6 +552,11 @@ function filterResources(filterType, value) {
 
 // Export table to CSV
 function exportToCSV() {
+    if (pagedTable) {
+        exportPagedTableToCSV();
+        return;
+    }
+
     const table = document.querySelector('#unused-resources table');
     if (!table) return;
 
@@ -399,9 +564,10 @@ function exportToCSV() {
     let csvContent = "data:text/csv;charset=utf-8,";
 
     // Get headers, excluding the Actions column and adding Details
-    const headers = Array.from(rows[0].querySelectorAll('th')).map(header => {
+    const headerCells = Array.from(rows[0].querySelectorAll('th'));
+    const headers = headerCells.map((header, index) => {
         let text = header.textContent.replace('↕', '').trim();
-        return text === 'Actions' ? 'Details' : text;
+        return index === headerCells.length - 1 ? t('Details') : text;
     });
     csvContent += headers.join(',') + '\n';
 
@@ -442,6 +608,30 @@ function exportToCSV() {
     document.body.removeChild(link);
 }
 
+// Export the filtered rows of a paginated report, including details if they can be loaded
+function exportPagedTableToCSV() {
+    const quote = text => `"${String(text).replace(/"/g, '""')}"`;
+    const headers = Array.from(document.querySelectorAll('#scan-table th')).map((header, index, all) =>
+        index === all.length - 1 ? t('Details') : header.textContent.replace('↕', '').trim());
+
+    loadDetails().catch(() => null).then(details => {
+        const lines = [headers.map(quote).join(',')];
+        pagedTable.filtered.forEach(row => {
+            const cells = pagedColumns.map(cell => quote(row.cells[cell]));
+            cells.push(quote(details ? JSON.stringify(details[row.index], null, 2) : ''));
+            lines.push(cells.join(','));
+        });
+
+        const link = document.createElement('a');
+        link.href = URL.createObjectURL(new Blob([lines.join('\n') + '\n'], { type: 'text/csv;charset=utf-8' }));
+        link.download = 'cloudsift_scan_report.csv';
+        document.body.appendChild(link);
+        link.click();
+        document.body.removeChild(link);
+        URL.revokeObjectURL(link.href);
+    });
+}
+
 // Convert UTC time to the user's local timezone and display
 function convertToLocalTime(utcTimeString) {
     const utcDate = new Date(utcTimeString);
@@ -0,0 +1,108 @@
+package html
+
+import (
+	"math"
+
+	"cloudsift/internal/aws"
+	"cloudsift/internal/history"
+)
+
+// PreviousScan is a previous scan the findings of a report are compared with
+type PreviousScan struct {
+	Source   string // Path of the previous scan results, shown in the report
+	Findings []history.Finding
+}
+
+// ForAccounts returns the previous scan limited to the findings of the accounts, for reports
+// of some of the scanned accounts
+func (p *PreviousScan) ForAccounts(accountIDs map[string]bool) *PreviousScan {
+	if p == nil {
+		return nil
+	}
+	limited := &PreviousScan{Source: p.Source, Findings: []history.Finding{}}
+	for _, finding := range p.Findings {
+		if accountIDs[finding.AccountID] {
+			limited.Findings = append(limited.Findings, finding)
+		}
+	}
+	return limited
+}
+
+// Comparison is the change of the findings of a report since a previous scan
+type Comparison struct {
+	Source           string
+	PreviousFindings int
+	CurrentFindings  int
+	New              int // Findings of the report only
+	Resolved         int // Findings of the previous scan only, remediated or gone
+	Unchanged        int
+	SavingsChange    float64         // Change of the monthly cost of the findings
+	Trend            string          // Direction of the waste: up, down or flat
+	ResourceTypes    []ComparisonRow // Largest change of the monthly cost first
+}
+
+// ComparisonRow is the change of the findings of a resource type since a previous scan
+type ComparisonRow struct {
+	ResourceType  string
+	PreviousCount int
+	CurrentCount  int
+	New           int
+	Resolved      int
+	Unchanged     int
+	SavingsChange float64
+	Trend         string
+}
+
+// compareFindings compares the findings of a report with those of a previous scan
+func compareFindings(previous *PreviousScan, results []aws.ScanResult) *Comparison {
+	if previous == nil {
+		return nil
+	}
+	current := make([]history.Finding, 0, len(results))
+	for _, result := range results {
+		current = append(current, history.NewFinding(result))
+	}
+	diff := history.DiffRuns(previous.Findings, current)
+
+	comparison := &Comparison{
+		Source:           previous.Source,
+		PreviousFindings: len(previous.Findings),
+		CurrentFindings:  len(current),
+		New:              len(diff.New),
+		Resolved:         len(diff.Resolved),
+		Unchanged:        diff.Unchanged,
+	}
+	for _, change := range diff.ResourceTypes {
+		savingsChange := change.CurrentSavings - change.PreviousSavings
+		comparison.SavingsChange += savingsChange
+		comparison.ResourceTypes = append(comparison.ResourceTypes, ComparisonRow{
+			ResourceType:  change.ResourceType,
+			PreviousCount: change.PreviousCount,
+			CurrentCount:  change.CurrentCount,
+			New:           change.New,
+			Resolved:      change.Resolved,
+			Unchanged:     change.Unchanged,
+			SavingsChange: savingsChange,
+			Trend:         trend(savingsChange, change.CurrentCount-change.PreviousCount),
+		})
+	}
+	comparison.Trend = trend(comparison.SavingsChange, comparison.CurrentFindings-comparison.PreviousFindings)
+	return comparison
+}
+
+// trend returns the direction of the waste from the change of its monthly cost, or from the
+// change of the number of findings when the cost did not change, such as for findings
+// without cost
+func trend(savingsChange float64, countChange int) string {
+	switch {
+	case math.Abs(savingsChange) >= 0.005 && savingsChange > 0:
+		return "up"
+	case math.Abs(savingsChange) >= 0.005:
+		return "down"
+	case countChange > 0:
+		return "up"
+	case countChange < 0:
+		return "down"
+	}
+	return "flat"
+}
@@ -1,14 +1,17 @@
 package html
 
 import (
+	"bufio"
 	"bytes"
 	"embed"
 	"encoding/json"
 	"fmt"
 	"html/template"
 	"io"
+	"math"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -25,41 +28,149 @@ type TemplateData struct {
 	AccountNames       map[string]string
 	ResourceTypeCounts map[string]int
 	CombinedCosts      map[string]map[string]interface{}
+	VPCRollups         []VPCRollup // Findings grouped by VPC, highest monthly cost first
 	ScanMetrics        ScanMetrics
 	Resources          []Resource
+	Paginated          bool        // Rows are rendered page by page from ResourceRows
+	ResourceRows       template.JS // JSON rows of a paginated report
+	DetailsURL         string      // Data file holding the details of a paginated report
 	Styles             template.CSS
 	Scripts            template.JS
+	Language           string
+	Currency           string
+	ReportLocale       map[string]interface{}     // Locale settings passed to the report scripts
+	AccountSpend       []AccountSpendRow          // Spend context of each account, highest waste share first
+	Forecast           aws.WasteForecast          // Cumulative waste of the findings if they are not remediated
+	StoppedStorage     aws.StoppedInstanceStorage // EBS storage still billed for stopped instances
+	CostAllocation     []aws.TagCostAllocation    // Savings grouped by the cost allocation tags
+	Comparison         *Comparison                // Change of the findings since a previous scan, if any
+}
+
+// paginationThreshold is the number of resources above which the report renders the
+// resource table page by page and loads resource details from a separate data file
+const paginationThreshold = 50000
+
+// ReportOptions controls how the HTML report is rendered
+type ReportOptions struct {
+	Language       string // Report language (see SupportedLanguages); defaults to English
+	Currency       string // ISO 4217 code of the cost figures; defaults to USD
+	CurrencySymbol string // Symbol displayed with cost figures; defaults to "$"
+
+	// ReasonTemplates rewrite matching scanner reasons, e.g. to add runbook links
+	ReasonTemplates []ReasonTemplate
+
+	// AccountSpend is the spend of each account this month in the report currency, shown with
+	// the identified waste as a share of it
+	AccountSpend map[string]aws.AccountSpend
+
+	// WasteGrowth is the monthly growth rate of the waste in previous scans the waste forecast
+	// is projected with; zero projects the current monthly waste at a constant rate
+	WasteGrowth float64
+
+	// CostAllocationTags are the tag keys the savings of the findings are grouped by
+	CostAllocationTags []string
+
+	// PreviousScan is a previous scan the findings are compared with, shown as the new,
+	// resolved and unchanged findings of each resource type; nil leaves the comparison out
+	PreviousScan *PreviousScan
+}
+
+// AccountSpendRow compares the identified waste of an account with its spend
+type AccountSpendRow struct {
+	AccountID    string
+	AccountName  string
+	MonthToDate  float64
+	Projected    float64 // Month-to-date spend extrapolated to the whole month
+	MonthlyWaste float64 // Monthly cost of the account's findings
+	WastePercent float64 // Monthly waste as a percentage of projected spend
+	HasWastePct  bool    // Whether the projected spend is known to be above zero
 }
 
 // ScanMetrics represents metrics about the scan operation
 type ScanMetrics struct {
-	TotalScans         int       `json:"total_scans"`
-	CompletedScans     int64     `json:"completed_scans"`
-	FailedScans        int64     `json:"failed_scans"`
-	AvgScansPerSecond  float64   `json:"avg_scans_per_second"`
-	TotalRunTime       float64   `json:"total_run_time"`
-	CompletedAt        time.Time `json:"completed_at"`
-	PeakWorkers        int64     `json:"peak_workers"`
-	MaxWorkers         int       `json:"max_workers"`
-	WorkerUtilization  float64   `json:"worker_utilization"`
-	AvgExecutionTimeMs int64     `json:"avg_execution_time_ms"`
-	TasksPerSecond     float64   `json:"tasks_per_second"`
+	TotalScans         int              `json:"total_scans"`
+	CompletedScans     int64            `json:"completed_scans"`
+	FailedScans        int64            `json:"failed_scans"`
+	AvgScansPerSecond  float64          `json:"avg_scans_per_second"`
+	TotalRunTime       float64          `json:"total_run_time"`
+	CompletedAt        time.Time        `json:"completed_at"`
+	PeakWorkers        int64            `json:"peak_workers"`
+	MaxWorkers         int              `json:"max_workers"`
+	WorkerUtilization  float64          `json:"worker_utilization"`
+	AvgExecutionTimeMs int64            `json:"avg_execution_time_ms"`
+	TasksPerSecond     float64          `json:"tasks_per_second"`
+	SlowestTasks       []TaskTiming     `json:"slowest_tasks"`
+	SkippedAccounts    []SkippedAccount `json:"skipped_accounts"`
+	APICalls           int64            `json:"api_calls"`
+	APICallCost        float64          `json:"api_call_cost"`       // Estimated cost of the API calls in USD
+	APIBudgetExceeded  bool             `json:"api_budget_exceeded"` // The scan stopped early at its API call budget
+}
+
+// SkippedAccount represents an account that was not scanned, such as a suspended account
+type SkippedAccount struct {
+	AccountID   string `json:"account_id"`
+	AccountName string `json:"account_name"`
+	Reason      string `json:"reason"`
+}
+
+// TaskTiming represents the wall-clock duration of a single scanner/account/region task
+type TaskTiming struct {
+	Scanner     string `json:"scanner"`
+	AccountID   string `json:"account_id"`
+	AccountName string `json:"account_name"`
+	Region      string `json:"region"`
+	DurationMs  int64  `json:"duration_ms"`
+	Failed      bool   `json:"failed"`
 }
 
 // Resource represents a single resource in the scan results
 type Resource struct {
 	AccountID    string
 	AccountName  string
+	AccountGroup string // Team and environment of the account from the account mappings, and whether it is the management account
 	Region       string
 	ResourceType string
 	Name         string
 	ResourceID   string
 	Reason       template.HTML
+	Triage       string // Localized annotation status, empty if the finding has not been reviewed
+	TriageNote   string
 	DetailsJSON  template.JS
 }
 
+// VPCRollup summarizes the findings of the resources in a VPC
+type VPCRollup struct {
+	VPCID         string
+	VPCName       string
+	AccountID     string
+	AccountName   string
+	Region        string
+	Findings      int
+	ResourceTypes []string
+	MonthlyCost   float64
+	YearlyCost    float64
+}
+
 // WriteHTML writes scan results to an HTML file
 func WriteHTML(results []aws.ScanResult, outputPath string, metrics ScanMetrics) error {
+	return WriteHTMLWithOptions(results, outputPath, metrics, ReportOptions{})
+}
+
+// WriteHTMLWithOptions writes scan results to an HTML file using the given report options
+func WriteHTMLWithOptions(results []aws.ScanResult, outputPath string, metrics ScanMetrics, opts ReportOptions) error {
+	l := getLocale(opts.Language)
+	if opts.Currency == "" {
+		opts.Currency = "USD"
+	}
+	if opts.CurrencySymbol == "" {
+		opts.CurrencySymbol = "$"
+	}
+	formatLocalCost := func(format func(float64) string) func(float64) string {
+		return func(cost float64) string {
+			return l.number(format(cost))
+		}
+	}
+
 	// Read template files
 	tmpl, err := template.New("scan_report.html").Funcs(template.FuncMap{
 		"join": strings.Join,
@@ -69,15 +180,28 @@ func WriteHTML(results []aws.ScanResult, outputPath string, metrics ScanMetrics)
 			}
 			return s[:n]
 		},
-		"formatTime": func(t time.Time) string {
-			return t.Format("January 2, 2006 at 3:04 PM MST")
+		"t":          l.translate,
+		"number":     l.number,
+		"formatTime": l.formatTime,
+		"currency": func(amount string) string {
+			return l.currency(amount, opts.CurrencySymbol)
+		},
+		"formatHourlyCost":   formatLocalCost(formatHourlyCost),
+		"formatDailyCost":    formatLocalCost(formatDailyCost),
+		"formatMonthlyCost":  formatLocalCost(formatMonthlyCost),
+		"formatYearlyCost":   formatLocalCost(formatYearlyCost),
+		"formatLifetimeCost": formatLocalCost(formatLifetimeCost),
+		"formatDuration":     l.duration,
+		"percent": func(rate float64) string {
+			return l.number(fmt.Sprintf("%+.1f", rate*100))
+		},
+		"costChange": func(change float64) string {
+			sign := "+"
+			if change < 0 {
+				sign = "-"
+			}
+			return sign + l.currency(l.number(formatMonthlyCost(math.Abs(change))), opts.CurrencySymbol)
 		},
-		"formatHourlyCost":   formatHourlyCost,
-		"formatDailyCost":    formatDailyCost,
-		"formatMonthlyCost":  formatMonthlyCost,
-		"formatYearlyCost":   formatYearlyCost,
-		"formatLifetimeCost": formatLifetimeCost,
-		"formatDuration":     formatDuration,
 		"add": func(a, b interface{}) float64 {
 			// Convert both values to float64
 			var aFloat, bFloat float64
@@ -115,8 +239,13 @@ func WriteHTML(results []aws.ScanResult, outputPath string, metrics ScanMetrics)
 		return fmt.Errorf("error reading scripts: %v", err)
 	}
 
+	reasonTemplates, err := compileReasonTemplates(opts.ReasonTemplates)
+	if err != nil {
+		return err
+	}
+
 	// Process the scan results
-	data := processResults(results)
+	data := processResults(results, l, reasonTemplates)
 	data.ScanMetrics.AvgScansPerSecond = metrics.AvgScansPerSecond
 	data.ScanMetrics.TotalRunTime = metrics.TotalRunTime
 	data.ScanMetrics.CompletedAt = metrics.CompletedAt
@@ -127,9 +256,41 @@ func WriteHTML(results []aws.ScanResult, outputPath string, metrics ScanMetrics)
 	data.ScanMetrics.WorkerUtilization = metrics.WorkerUtilization
 	data.ScanMetrics.AvgExecutionTimeMs = metrics.AvgExecutionTimeMs
 	data.ScanMetrics.TasksPerSecond = metrics.TasksPerSecond
+	data.ScanMetrics.SlowestTasks = metrics.SlowestTasks
+	data.ScanMetrics.SkippedAccounts = metrics.SkippedAccounts
+	data.ScanMetrics.APICalls = metrics.APICalls
+	data.ScanMetrics.APICallCost = metrics.APICallCost
+	data.ScanMetrics.APIBudgetExceeded = metrics.APIBudgetExceeded
+	data.Language = l.Language
+	data.Currency = opts.Currency
+	data.AccountSpend = accountSpendRows(results, opts.AccountSpend)
+	data.Forecast = aws.ForecastWaste(aws.TotalMonthlyWaste(results), opts.WasteGrowth)
+	data.StoppedStorage = aws.SummarizeStoppedInstanceStorage(results)
+	data.CostAllocation = aws.SummarizeCostAllocation(results, opts.CostAllocationTags)
+	data.Comparison = compareFindings(opts.PreviousScan, results)
+	data.ReportLocale = map[string]interface{}{
+		"language":       l.Language,
+		"currencySymbol": opts.CurrencySymbol,
+		"currencyAfter":  l.CurrencyAfter,
+		"messages":       l.scriptMessages(),
+	}
 	data.Styles = template.CSS(styles)
 	data.Scripts = template.JS(scripts)
 
+	// Large reports embed compact rows instead of table markup and move the details into a
+	// data file next to the report that is fetched when details are first viewed
+	if len(data.Resources) > paginationThreshold {
+		detailsPath := strings.TrimSuffix(outputPath, filepath.Ext(outputPath)) + "_details.json"
+		rows, err := writePaginatedData(data.Resources, detailsPath)
+		if err != nil {
+			return err
+		}
+		data.Paginated = true
+		data.ResourceRows = rows
+		data.DetailsURL = filepath.Base(detailsPath)
+		data.Resources = nil
+	}
+
 	// Create output directory if it doesn't exist
 	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
 		return fmt.Errorf("error creating output directory: %v", err)
@@ -156,13 +317,114 @@ func WriteHTML(results []aws.ScanResult, outputPath string, metrics ScanMetrics)
 	return nil
 }
 
-func processResults(results []aws.ScanResult) TemplateData {
+// accountSpendRows compares the monthly cost of each account's findings with its spend
+func accountSpendRows(results []aws.ScanResult, spend map[string]aws.AccountSpend) []AccountSpendRow {
+	if len(spend) == 0 {
+		return nil
+	}
+
+	waste := make(map[string]float64)
+	names := make(map[string]string)
+	for _, result := range results {
+		names[result.AccountID] = result.AccountName
+		if total := result.SavingsBreakdown(); total != nil {
+			waste[result.AccountID] += total.MonthlyRate
+		}
+	}
+
+	rows := make([]AccountSpendRow, 0, len(spend))
+	for accountID, accountSpend := range spend {
+		row := AccountSpendRow{
+			AccountID:    accountID,
+			AccountName:  names[accountID],
+			MonthToDate:  accountSpend.MonthToDate,
+			Projected:    accountSpend.Projected,
+			MonthlyWaste: waste[accountID],
+		}
+		if accountSpend.Projected > 0 {
+			row.WastePercent = row.MonthlyWaste / accountSpend.Projected * 100
+			row.HasWastePct = true
+		}
+		rows = append(rows, row)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].WastePercent != rows[j].WastePercent {
+			return rows[i].WastePercent > rows[j].WastePercent
+		}
+		return rows[i].AccountID < rows[j].AccountID
+	})
+	return rows
+}
+
+// writePaginatedData writes the details of the resources to a JSON array at detailsPath and
+// returns the resource rows as a JSON array of arrays in column order. The index of a row
+// is the index of its details in the data file.
+func writePaginatedData(resources []Resource, detailsPath string) (template.JS, error) {
+	if err := os.MkdirAll(filepath.Dir(detailsPath), 0755); err != nil {
+		return "", fmt.Errorf("error creating output directory: %v", err)
+	}
+	f, err := os.Create(detailsPath)
+	if err != nil {
+		return "", fmt.Errorf("error creating details file: %v", err)
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	rows := make([][]string, 0, len(resources))
+	if _, err := w.WriteString("["); err != nil {
+		return "", fmt.Errorf("error writing details file: %v", err)
+	}
+	for i, resource := range resources {
+		if i > 0 {
+			if _, err := w.WriteString(",\n"); err != nil {
+				return "", fmt.Errorf("error writing details file: %v", err)
+			}
+		}
+		if _, err := w.WriteString(string(resource.DetailsJSON)); err != nil {
+			return "", fmt.Errorf("error writing details file: %v", err)
+		}
+		rows = append(rows, []string{
+			resource.AccountID,
+			resource.AccountName,
+			resource.AccountGroup,
+			resource.ResourceType,
+			resource.Name,
+			resource.ResourceID,
+			resource.Region,
+			strings.ReplaceAll(string(resource.Reason), ".<br>", ".\n"),
+			resource.Triage,
+			resource.TriageNote,
+		})
+	}
+	if _, err := w.WriteString("]\n"); err != nil {
+		return "", fmt.Errorf("error writing details file: %v", err)
+	}
+	if err := w.Flush(); err != nil {
+		return "", fmt.Errorf("error writing details file: %v", err)
+	}
+
+	rowsJSON, err := json.Marshal(rows)
+	if err != nil {
+		return "", fmt.Errorf("error marshaling resource rows: %v", err)
+	}
+	return template.JS(rowsJSON), nil
+}
+
+// annotationLabels maps annotation statuses to their display labels
+var annotationLabels = map[string]string{
+	"acknowledged":    "Acknowledged",
+	"planned_removal": "Planned for removal",
+	"false_positive":  "False positive",
+}
+
+func processResults(results []aws.ScanResult, l *locale, reasonTemplates []compiledReasonTemplate) TemplateData {
 	data := TemplateData{
 		AccountsAndRegions: make(map[string][]string),
 		AccountNames:       make(map[string]string),
 		ResourceTypeCounts: make(map[string]int),
 		CombinedCosts:      make(map[string]map[string]interface{}),
 		Resources:          make([]Resource, 0),
+		VPCRollups:         make([]VPCRollup, 0),
 		ScanMetrics: ScanMetrics{
 			TotalScans:        len(results),
 			AvgScansPerSecond: 0, // Will be set by caller
@@ -171,6 +433,8 @@ func processResults(results []aws.ScanResult) TemplateData {
 		},
 	}
 
+	vpcRollups := make(map[string]*VPCRollup)
+
 	// Process each result
 	for _, result := range results {
 		// Extract account ID and region
@@ -196,8 +460,8 @@ func processResults(results []aws.ScanResult) TemplateData {
 		// Update resource type counts
 		data.ResourceTypeCounts[result.ResourceType]++
 
-		// Process costs
-		if result.Cost != nil {
+		// Process costs, leaving out low confidence results whose resources may still be used
+		if result.Cost != nil && !result.LowConfidence() {
 			if total, ok := result.Cost["total"].(*aws.CostBreakdown); ok && total != nil {
 				// Initialize cost map for resource type if not exists
 				if _, exists := data.CombinedCosts[result.ResourceType]; !exists {
@@ -261,6 +525,42 @@ func processResults(results []aws.ScanResult) TemplateData {
 			}
 		}
 
+		if vpcID := resultVPCID(result); vpcID != "" {
+			key := accountID + "/" + vpcID
+			rollup, ok := vpcRollups[key]
+			if !ok {
+				rollup = &VPCRollup{
+					VPCID:       vpcID,
+					AccountID:   accountID,
+					AccountName: accountName,
+					Region:      region,
+				}
+				vpcRollups[key] = rollup
+			}
+			rollup.Findings++
+			if !contains(rollup.ResourceTypes, result.ResourceType) {
+				rollup.ResourceTypes = append(rollup.ResourceTypes, result.ResourceType)
+			}
+			if name, ok := result.Details["vpc_name"].(string); ok && name != "" {
+				rollup.VPCName = name
+			} else if resourceID == vpcID && resourceName != "" && resourceName != vpcID {
+				rollup.VPCName = resourceName
+			}
+			if total := result.SavingsBreakdown(); total != nil {
+				rollup.MonthlyCost += total.MonthlyRate
+				rollup.YearlyCost += total.YearlyRate
+			}
+		}
+
+		var triage, triageNote string
+		if result.Annotation != nil {
+			triage = l.translate(annotationLabels[result.Annotation.Status])
+			triageNote = result.Annotation.Note
+			if result.Annotation.Author != "" {
+				triageNote = fmt.Sprintf("%s (%s)", triageNote, result.Annotation.Author)
+			}
+		}
+
 		detailsJSON, err := json.Marshal(result.Details)
 		if err != nil {
 			logging.Debug("Error marshaling details to JSON", map[string]interface{}{
@@ -270,21 +570,59 @@ func processResults(results []aws.ScanResult) TemplateData {
 			detailsJSON = []byte("{}")
 		}
 
+		// Findings of the management account are marked, since remediation rules differ there
+		accountGroup := strings.Trim(result.AccountTeam+" / "+result.AccountEnv, " /")
+		if result.Management {
+			accountGroup = strings.Trim(l.translate("Management account")+" / "+accountGroup, " /")
+		}
+
 		data.Resources = append(data.Resources, Resource{
 			AccountID:    accountID,
 			AccountName:  accountName,
+			AccountGroup: accountGroup,
 			Region:       region,
 			ResourceType: result.ResourceType,
 			Name:         resourceName,
 			ResourceID:   resourceID,
-			Reason:       template.HTML(strings.ReplaceAll(result.Reason, ".", ".<br>")),
+			Reason:       template.HTML(sentenceEnd.ReplaceAllString(renderReason(l, reasonTemplates, result, resourceName, resourceID, accountID, accountName, region), ".<br>")),
+			Triage:       triage,
+			TriageNote:   strings.TrimSpace(triageNote),
 			DetailsJSON:  template.JS(detailsJSON),
 		})
 	}
 
+	for _, rollup := range vpcRollups {
+		sort.Strings(rollup.ResourceTypes)
+		data.VPCRollups = append(data.VPCRollups, *rollup)
+	}
+	sort.Slice(data.VPCRollups, func(i, j int) bool {
+		a, b := data.VPCRollups[i], data.VPCRollups[j]
+		if a.MonthlyCost != b.MonthlyCost {
+			return a.MonthlyCost > b.MonthlyCost
+		}
+		if a.Findings != b.Findings {
+			return a.Findings > b.Findings
+		}
+		return a.VPCID < b.VPCID
+	})
+
 	return data
 }
 
+// resultVPCID returns the ID of the VPC a result belongs to, or "" if the resource is not
+// in a VPC. Scanners record the VPC in the details; VPC results are their own VPC.
+func resultVPCID(result aws.ScanResult) string {
+	for _, key := range []string{"vpc_id", "VpcId"} {
+		if id, ok := result.Details[key].(string); ok && id != "" {
+			return id
+		}
+	}
+	if strings.HasPrefix(result.ResourceID, "vpc-") {
+		return result.ResourceID
+	}
+	return ""
+}
+
 // NOTE: The following functions are currently unused but maintained for future use
 // in the cost breakdown calculation system. They will be used when we implement
 // the detailed cost breakdown view in the HTML output.
@@ -490,15 +828,3 @@ func formatYearlyCost(cost float64) string {
 func formatLifetimeCost(cost float64) string {
 	return formatCost(cost)
 }
-
-func formatDuration(seconds float64) string {
-	if seconds < 1 {
-		return fmt.Sprintf("%.6f seconds", seconds)
-	}
-	if seconds < 60 {
-		return fmt.Sprintf("%.2f seconds", seconds)
-	}
-	minutes := int(seconds / 60)
-	remainingSeconds := seconds - float64(minutes*60)
-	return fmt.Sprintf("%d minutes %.2f seconds", minutes, remainingSeconds)
-}
@@ -0,0 +1,699 @@
+package html
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultLanguage is the report language used when none is configured
+const DefaultLanguage = "en"
+
+// locale holds the message catalog and number formatting rules for a report language.
+// Messages are keyed by their English text so that untranslated strings fall back to English.
+type locale struct {
+	Language          string
+	DecimalSeparator  string
+	ThousandSeparator string
+	CurrencyAfter     bool   // Place the currency symbol after the amount (e.g. "1.234,56 $")
+	DateFormat        string // Go time layout; "January" is replaced with the translated month
+	Messages          map[string]string
+}
+
+// reasonTemplate matches an English reason produced by a scanner. The captured values are
+// substituted into the translated form of Format.
+type reasonTemplate struct {
+	Pattern *regexp.Regexp
+	Format  string
+}
+
+// reasonTemplates lists the scanner reasons that can be localized. Reasons that do not
+// match any template are reported unchanged.
+var reasonTemplates = []reasonTemplate{
+	{regexp.MustCompile(`^Very low CPU utilization \(([\d.]+)%\) in the last (\d+) days\.$`), "Very low CPU utilization (%[1]s%%) in the last %[2]s days."},
+	{regexp.MustCompile(`^Very low network activity \(in: ([\d.]+) KB/s, out: ([\d.]+) KB/s\) in the last (\d+) days\.$`), "Very low network activity (in: %[1]s KB/s, out: %[2]s KB/s) in the last %[3]s days."},
+	{regexp.MustCompile(`^Very low I/O activity \(reads: ([\d.]+) IOPS, writes: ([\d.]+) IOPS\) in the last (\d+) days\.$`), "Very low I/O activity (reads: %[1]s IOPS, writes: %[2]s IOPS) in the last %[3]s days."},
+	{regexp.MustCompile(`^Instance has been stopped for (\d+) days\.$`), "Instance has been stopped for %[1]s days."},
+	{regexp.MustCompile(`^Instance has been stopped for (.+)$`), "Instance has been stopped for %[1]s"},
+	{regexp.MustCompile(`^Instance has been hibernated for (.+)$`), "Instance has been hibernated for %[1]s"},
+	{regexp.MustCompile(`^Instance is kept in the warm pool of Auto Scaling group (.+) \((.+)\)\.$`), "Instance is kept in the warm pool of Auto Scaling group %[1]s (%[2]s)."},
+	{regexp.MustCompile(`^Non-running state: (.+)$`), "Non-running state: %[1]s"},
+	{regexp.MustCompile(`^No active database connections$`), "No active database connections"},
+	{regexp.MustCompile(`^Not associated with any resource$`), "Not associated with any resource"},
+	{regexp.MustCompile(`^Not associated with any resource \(EC2 Instance or ENI\)$`), "Not associated with any resource (EC2 Instance or ENI)"},
+	{regexp.MustCompile(`^Associated with stopped instance (\S+)$`), "Associated with stopped instance %[1]s"},
+	{regexp.MustCompile(`^VPC has no EC2 Instances or ENIs$`), "VPC has no EC2 Instances or ENIs"},
+	{regexp.MustCompile(`^Role has never been used\.$`), "Role has never been used."},
+	{regexp.MustCompile(`^Role has no attached policies\.$`), "Role has no attached policies."},
+	{regexp.MustCompile(`^User has never logged in to the console$`), "User has never logged in to the console"},
+	{regexp.MustCompile(`^User has never used access keys$`), "User has never used access keys"},
+	{regexp.MustCompile(`^Empty table with no read/write activity in the last (\d+) days\.$`), "Empty table with no read/write activity in the last %[1]s days."},
+	{regexp.MustCompile(`^Table has data but no read/write activity in the last (\d+) days\.$`), "Table has data but no read/write activity in the last %[1]s days."},
+	{regexp.MustCompile(`^Provisioned capacity \((\d+) RCU, (\d+) WCU\) unused and not decreased in (\d+) days\.$`), "Provisioned capacity (%[1]s RCU, %[2]s WCU) unused and not decreased in %[3]s days."},
+	{regexp.MustCompile(`^Source volume was deleted\. Snapshot has not been used in (\d+) days\.$`), "Source volume was deleted. Snapshot has not been used in %[1]s days."},
+	{regexp.MustCompile(`^Snapshot is (.+) old\.$`), "Snapshot is %[1]s old."},
+	{regexp.MustCompile(`^Multiple snapshots exist for volume (\S+): (\d+) newer snapshots supersede this one\.$`), "Multiple snapshots exist for volume %[1]s: %[2]s newer snapshots supersede this one."},
+	{regexp.MustCompile(`^Snapshot is shared publicly\.$`), "Snapshot is shared publicly."},
+	{regexp.MustCompile(`^Snapshot is shared with unknown accounts: (.+)\.$`), "Snapshot is shared with unknown accounts: %[1]s."},
+	{regexp.MustCompile(`^Bucket has (\d+) incomplete multipart uploads older than (\d+) days \(([\d.]+) GB\)\.$`), "Bucket has %[1]s incomplete multipart uploads older than %[2]s days (%[3]s GB)."},
+	{regexp.MustCompile(`^No lifecycle rule aborts incomplete multipart uploads\.$`), "No lifecycle rule aborts incomplete multipart uploads."},
+	{regexp.MustCompile(`^IAM Access Analyzer reports the role as unused\.$`), "IAM Access Analyzer reports the role as unused."},
+	{regexp.MustCompile(`^IAM Access Analyzer reports an unused access key\.$`), "IAM Access Analyzer reports an unused access key."},
+	{regexp.MustCompile(`^IAM Access Analyzer reports an unused console password\.$`), "IAM Access Analyzer reports an unused console password."},
+	{regexp.MustCompile(`^IAM Access Analyzer reports unused permissions\.$`), "IAM Access Analyzer reports unused permissions."},
+	{regexp.MustCompile(`^Instance profile created (\d+) days ago is not used by any EC2 instance\.$`), "Instance profile created %[1]s days ago is not used by any EC2 instance."},
+	{regexp.MustCompile(`^Instance profile has no role\.$`), "Instance profile has no role."},
+	{regexp.MustCompile(`^(OIDC|SAML) provider created (\d+) days ago is not trusted by any role\.$`), "%[1]s provider created %[2]s days ago is not trusted by any role."},
+	{regexp.MustCompile(`^AWS Compute Optimizer reports over-provisioning: (.+) is recommended instead of (.+)\.$`), "AWS Compute Optimizer reports over-provisioning: %[1]s is recommended instead of %[2]s."},
+	{regexp.MustCompile(`^CloudWatch metrics unavailable, usage could not be verified\.$`), "CloudWatch metrics unavailable, usage could not be verified."},
+	{regexp.MustCompile(`^Lightsail instance is stopped but its bundle is still billed\.$`), "Lightsail instance is stopped but its bundle is still billed."},
+	{regexp.MustCompile(`^No network traffic in the last (\d+) days\.$`), "No network traffic in the last %[1]s days."},
+	{regexp.MustCompile(`^Very low network traffic \(([\d.]+) MB\) in the last (\d+) days\.$`), "Very low network traffic (%[1]s MB) in the last %[2]s days."},
+	{regexp.MustCompile(`^Lightsail database is stopped but its bundle is still billed\.$`), "Lightsail database is stopped but its bundle is still billed."},
+	{regexp.MustCompile(`^No database connections in the last (\d+) days\.$`), "No database connections in the last %[1]s days."},
+	{regexp.MustCompile(`^No builds in the last (\d+) days\.$`), "No builds in the last %[1]s days."},
+	{regexp.MustCompile(`^No requests in the last (\d+) days\.$`), "No requests in the last %[1]s days."},
+	{regexp.MustCompile(`^No deployments in the last (\d+) days\.$`), "No deployments in the last %[1]s days."},
+	{regexp.MustCompile(`^Dev endpoint has been running for (\d+) hours and is billed per DPU-hour\.$`), "Dev endpoint has been running for %[1]s hours and is billed per DPU-hour."},
+	{regexp.MustCompile(`^Crawler has never run\.$`), "Crawler has never run."},
+	{regexp.MustCompile(`^Last crawl did not succeed \((\w+)\)\.$`), "Last crawl did not succeed (%[1]s)."},
+	{regexp.MustCompile(`^Crawler has not run in the last (\d+) days\.$`), "Crawler has not run in the last %[1]s days."},
+	{regexp.MustCompile(`^Job has not run in the last (\d+) days but keeps ([\d.]+) KB of bookmark state\.$`), "Job has not run in the last %[1]s days but keeps %[2]s KB of bookmark state."},
+	{regexp.MustCompile(`^Cluster has been waiting for (\d+) hours and no steps were ever submitted\.$`), "Cluster has been waiting for %[1]s hours and no steps were ever submitted."},
+	{regexp.MustCompile(`^Cluster has been waiting for (\d+) hours without new steps\.$`), "Cluster has been waiting for %[1]s hours without new steps."},
+	{regexp.MustCompile(`^No queries in the last (\d+) days but ([\d.]+) GB of query results are still stored\.$`), "No queries in the last %[1]s days but %[2]s GB of query results are still stored."},
+	{regexp.MustCompile(`^Query results \(([\d.]+) GB\) are not expired by a lifecycle rule\.$`), "Query results (%[1]s GB) are not expired by a lifecycle rule."},
+	{regexp.MustCompile(`^Project has never been built\.$`), "Project has never been built."},
+	{regexp.MustCompile(`^Fleet keeps (\d+) instances of reserved capacity but no project built on it in the last (\d+) days\.$`), "Fleet keeps %[1]s instances of reserved capacity but no project built on it in the last %[2]s days."},
+	{regexp.MustCompile(`^Pipeline has never been executed\.$`), "Pipeline has never been executed."},
+	{regexp.MustCompile(`^No pipeline executions in the last (\d+) days\.$`), "No pipeline executions in the last %[1]s days."},
+	{regexp.MustCompile(`^Route tables not associated with any subnet: (.+)\.$`), "Route tables not associated with any subnet: %[1]s."},
+	{regexp.MustCompile(`^Egress-only internet gateways without routes: (.+)\.$`), "Egress-only internet gateways without routes: %[1]s."},
+	{regexp.MustCompile(`^Carrier gateways without routes: (.+)\.$`), "Carrier gateways without routes: %[1]s."},
+	{regexp.MustCompile(`^Internet gateways not attached to a VPC: (.+)\.$`), "Internet gateways not attached to a VPC: %[1]s."},
+	{regexp.MustCompile(`^Egress-only internet gateways not attached to a VPC: (.+)\.$`), "Egress-only internet gateways not attached to a VPC: %[1]s."},
+	{regexp.MustCompile(`^Virtual private gateways not attached to a VPC: (.+)\.$`), "Virtual private gateways not attached to a VPC: %[1]s."},
+	{regexp.MustCompile(`^DHCP option sets not used by any VPC: (.+)\.$`), "DHCP option sets not used by any VPC: %[1]s."},
+	{regexp.MustCompile(`^Metric stream sends to a deleted Firehose delivery stream\.$`), "Metric stream sends to a deleted Firehose delivery stream."},
+	{regexp.MustCompile(`^Metric stream sends to a Firehose delivery stream that is (.+)\.$`), "Metric stream sends to a Firehose delivery stream that is %[1]s."},
+	{regexp.MustCompile(`^Contributor Insights rule matched no log events in the last (\d+) days\.$`), "Contributor Insights rule matched no log events in the last %[1]s days."},
+	{regexp.MustCompile(`^Composite alarm references deleted alarms: (.+)\.$`), "Composite alarm references deleted alarms: %[1]s."},
+	{regexp.MustCompile(`^Composite alarm has no actions and is not used by another composite alarm\.$`), "Composite alarm has no actions and is not used by another composite alarm."},
+	{regexp.MustCompile(`^Subscription filter delivers to a deleted Lambda function\.$`), "Subscription filter delivers to a deleted Lambda function."},
+	{regexp.MustCompile(`^Subscription filter delivers to a deleted Firehose delivery stream\.$`), "Subscription filter delivers to a deleted Firehose delivery stream."},
+	{regexp.MustCompile(`^Subscription filter delivers to a deleted Kinesis stream\.$`), "Subscription filter delivers to a deleted Kinesis stream."},
+	{regexp.MustCompile(`^Subscription filter delivers to a Firehose delivery stream that is (.+)\.$`), "Subscription filter delivers to a Firehose delivery stream that is %[1]s."},
+	{regexp.MustCompile(`^All invocations of the Lambda function the subscription filter delivers to failed in the last (\d+) days\.$`), "All invocations of the Lambda function the subscription filter delivers to failed in the last %[1]s days."},
+	{regexp.MustCompile(`^The Firehose delivery stream the subscription filter delivers to delivered no records to S3 in the last (\d+) days\.$`), "The Firehose delivery stream the subscription filter delivers to delivered no records to S3 in the last %[1]s days."},
+	{regexp.MustCompile(`^Subscription filter duplicates (.+) with the same pattern and destination\.$`), "Subscription filter duplicates %[1]s with the same pattern and destination."},
+	{regexp.MustCompile(`^Schedule has been disabled since (\d{4}-\d{2}-\d{2})\.$`), "Schedule has been disabled since %[1]s."},
+	{regexp.MustCompile(`^Schedule ended on (\d{4}-\d{2}-\d{2}) and does not run again\.$`), "Schedule ended on %[1]s and does not run again."},
+	{regexp.MustCompile(`^One-time schedule ran on (\d{4}-\d{2}-\d{2}) and was not deleted\.$`), "One-time schedule ran on %[1]s and was not deleted."},
+	{regexp.MustCompile(`^Schedule targets a deleted resource\.$`), "Schedule targets a deleted resource."},
+	{regexp.MustCompile(`^Scheduled rule is disabled\.$`), "Scheduled rule is disabled."},
+	{regexp.MustCompile(`^Scheduled rule targets only deleted resources\.$`), "Scheduled rule targets only deleted resources."},
+	{regexp.MustCompile(`^Scheduled rule duplicates schedule (.+) with the same expression and target\.$`), "Scheduled rule duplicates schedule %[1]s with the same expression and target."},
+	{regexp.MustCompile(`^Very low CPU utilization in the last (\d+) days\.$`), "Very low CPU utilization in the last %[1]s days."},
+	{regexp.MustCompile(`^Very low network activity in the last (\d+) days\.$`), "Very low network activity in the last %[1]s days."},
+	{regexp.MustCompile(`^Very low I/O activity in the last (\d+) days\.$`), "Very low I/O activity in the last %[1]s days."},
+	{regexp.MustCompile(`^Bucket has (\d+) incomplete multipart uploads older than (\d+) days\.$`), "Bucket has %[1]s incomplete multipart uploads older than %[2]s days."},
+	{regexp.MustCompile(`^Very low network traffic in the last (\d+) days\.$`), "Very low network traffic in the last %[1]s days."},
+	{regexp.MustCompile(`^Query results are not expired by a lifecycle rule\.$`), "Query results are not expired by a lifecycle rule."},
+	{regexp.MustCompile(`^Threshold: (.+)$`), "Threshold: %[1]s"},
+	{regexp.MustCompile(`^Signals: (.+)$`), "Signals: %[1]s"},
+}
+
+var numberPattern = regexp.MustCompile(`^\d+\.\d+$`)
+
+var locales = map[string]*locale{
+	"en": {
+		Language:          "en",
+		DecimalSeparator:  ".",
+		ThousandSeparator: ",",
+		DateFormat:        "January 2, 2006 at 3:04 PM MST",
+		Messages:          map[string]string{},
+	},
+	"de": {
+		Language:          "de",
+		DecimalSeparator:  ",",
+		ThousandSeparator: ".",
+		CurrencyAfter:     true,
+		DateFormat:        "2. January 2006 um 15:04 MST",
+		Messages: map[string]string{
+			// Report layout
+			"CloudSift - Scan Report":      "CloudSift - Scan-Bericht",
+			"CloudSift Scan Report":        "CloudSift Scan-Bericht",
+			"Scan completed at":            "Scan abgeschlossen am",
+			"Scanned Accounts and Regions": "Gescannte Konten und Regionen",
+			"Account ID":                   "Konto-ID",
+			"Account Name":                 "Kontoname",
+			"Regions":                      "Regionen",
+			"Region":                       "Region",
+			"Resource Distribution":        "Ressourcenverteilung",
+			"Cost Breakdown":               "Kostenaufschlüsselung",
+			"Cost by Resource Type":        "Kosten nach Ressourcentyp",
+			"Hourly":                       "Stündlich",
+			"Daily":                        "Täglich",
+			"Monthly":                      "Monatlich",
+			"Yearly":                       "Jährlich",
+			"Lifetime":                     "Lebensdauer",
+			"Cost":                         "Kosten",
+			"Resource Type Counts":         "Anzahl nach Ressourcentyp",
+			"Resource Type":                "Ressourcentyp",
+			"Count":                        "Anzahl",
+			"Scan Metrics":                 "Scan-Metriken",
+			"Metric":                       "Metrik",
+			"Value":                        "Wert",
+			"Completed Scans":              "Abgeschlossene Scans",
+			"Failed Scans":                 "Fehlgeschlagene Scans",
+			"Tasks per Second":             "Aufgaben pro Sekunde",
+			"Average Task Time":            "Durchschnittliche Aufgabendauer",
+			"Worker Utilization":           "Worker-Auslastung",
+			"workers":                      "Worker",
+			"Total Run Time":               "Gesamtlaufzeit",
+			"AWS API Calls":                "AWS-API-Aufrufe",
+			"Estimated Scan Cost (USD)":    "Geschätzte Scan-Kosten (USD)",
+			"Slowest Tasks":                "Langsamste Aufgaben",
+			"Skipped Accounts":             "Übersprungene Konten",
+			"Account Spend":                "Kontoausgaben",
+			"Month-to-Date Spend":          "Ausgaben seit Monatsbeginn",
+			"Projected Monthly Spend":      "Hochgerechnete Monatsausgaben",
+			"Identified Monthly Waste":     "Identifizierte monatliche Verschwendung",
+			"Waste % of Spend":             "Verschwendung in % der Ausgaben",
+			"Scanner":                      "Scanner",
+			"Duration":                     "Dauer",
+			"Status":                       "Status",
+			"Failed":                       "Fehlgeschlagen",
+			"Completed":                    "Abgeschlossen",
+			"Combined Cost Breakdown":      "Kombinierte Kostenaufschlüsselung",
+			"N/A":                          "k. A.",
+			"Lifetime cost not applicable for this resource type": "Gesamtkosten für diesen Ressourcentyp nicht anwendbar",
+			"Totals":                "Summen",
+			"Unused Resources":      "Ungenutzte Ressourcen",
+			"Search resources...":   "Ressourcen durchsuchen...",
+			"Clear":                 "Löschen",
+			"Export CSV":            "CSV exportieren",
+			"Name":                  "Name",
+			"Resource ID":           "Ressourcen-ID",
+			"Reason":                "Grund",
+			"Triage":                "Bewertung",
+			"Acknowledged":          "Zur Kenntnis genommen",
+			"Planned for removal":   "Entfernung geplant",
+			"False positive":        "Falsch positiv",
+			"Actions":               "Aktionen",
+			"Details":               "Details",
+			"Previous":              "Zurück",
+			"Next":                  "Weiter",
+			"Page %d of %d":         "Seite %d von %d",
+			"%s seconds":            "%s Sekunden",
+			"%d minutes %s seconds": "%d Minuten %s Sekunden",
+			"Details could not be loaded. Open the report through a web server to view resource details.": "Details konnten nicht geladen werden. Öffnen Sie den Bericht über einen Webserver, um Ressourcendetails anzuzeigen.",
+			"Findings by VPC": "Befunde nach VPC",
+			"VPC ID":          "VPC-ID",
+			"VPC Name":        "VPC-Name",
+			"Findings":        "Befunde",
+			"Resource Types":  "Ressourcentypen",
+
+			// Waste forecast
+			"Projected Waste if Not Remediated":                         "Hochgerechnete Verschwendung ohne Bereinigung",
+			"Next %d months":                                            "Nächste %d Monate",
+			"Current monthly waste of %s projected at a constant rate.": "Aktuelle monatliche Verschwendung von %s, mit gleichbleibender Rate hochgerechnet.",
+			"Current monthly waste of %s projected with a monthly growth of %s%% from previous scans.": "Aktuelle monatliche Verschwendung von %s, mit dem monatlichen Wachstum früherer Scans von %s %% hochgerechnet.",
+
+			// Comparison with a previous scan
+			"Changes Since Previous Scan": "Änderungen seit dem vorherigen Scan",
+			"Monthly Waste Change":        "Änderung der monatlichen Verschwendung",
+			"New Findings":                "Neue Befunde",
+			"Resolved Findings":           "Behobene Befunde",
+			"Unchanged Findings":          "Unveränderte Befunde",
+			"Before":                      "Vorher",
+			"Now":                         "Jetzt",
+			"Compared with %s: %d findings before, %d now.": "Verglichen mit %s: vorher %d Befunde, jetzt %d.",
+
+			// API call budget
+			"budget exceeded, scan incomplete": "Budget überschritten, Scan unvollständig",
+
+			// Stopped instance storage
+			"EBS Storage of Stopped Instances": "EBS-Speicher gestoppter Instanzen",
+			"Monthly Cost":                     "Monatliche Kosten",
+			"Stopped Instances":                "Gestoppte Instanzen",
+			"Attached Volumes":                 "Angehängte Volumes",
+			"Provisioned Storage":              "Bereitgestellter Speicher",
+
+			// Cost allocation by tag
+			"Savings by Tag":  "Einsparungen nach Tag",
+			"Tag Key":         "Tag-Schlüssel",
+			"Tag Value":       "Tag-Wert",
+			"Monthly Savings": "Monatliche Einsparungen",
+			"Yearly Savings":  "Jährliche Einsparungen",
+			"Untagged":        "Ohne Tag",
+
+			// Management account findings
+			"Management account": "Verwaltungskonto",
+
+			// Warm pool and hibernated instances
+			"Instance has been hibernated for %[1]s":                                 "Instanz ist im Ruhezustand seit: %[1]s",
+			"Instance is kept in the warm pool of Auto Scaling group %[1]s (%[2]s).": "Instanz wird im Warm Pool der Auto Scaling-Gruppe %[1]s vorgehalten (%[2]s).",
+
+			// Log subscription filters
+			"Subscription filter delivers to a deleted Lambda function.":                                                          "Abonnementfilter liefert an eine gelöschte Lambda-Funktion.",
+			"Subscription filter delivers to a deleted Firehose delivery stream.":                                                 "Abonnementfilter liefert an einen gelöschten Firehose-Bereitstellungsstream.",
+			"Subscription filter delivers to a deleted Kinesis stream.":                                                           "Abonnementfilter liefert an einen gelöschten Kinesis-Stream.",
+			"Subscription filter delivers to a Firehose delivery stream that is %[1]s.":                                           "Abonnementfilter liefert an einen Firehose-Bereitstellungsstream mit dem Status %[1]s.",
+			"All invocations of the Lambda function the subscription filter delivers to failed in the last %[1]s days.":           "Alle Aufrufe der Lambda-Funktion, an die der Abonnementfilter liefert, sind in den letzten %[1]s Tagen fehlgeschlagen.",
+			"The Firehose delivery stream the subscription filter delivers to delivered no records to S3 in the last %[1]s days.": "Der Firehose-Bereitstellungsstream, an den der Abonnementfilter liefert, hat in den letzten %[1]s Tagen keine Datensätze an S3 geliefert.",
+			"Subscription filter duplicates %[1]s with the same pattern and destination.":                                         "Abonnementfilter dupliziert %[1]s mit demselben Muster und Ziel.",
+
+			// EventBridge schedules
+			"Schedule has been disabled since %[1]s.":                                       "Zeitplan ist seit %[1]s deaktiviert.",
+			"Schedule ended on %[1]s and does not run again.":                               "Zeitplan ist am %[1]s abgelaufen und wird nicht mehr ausgeführt.",
+			"One-time schedule ran on %[1]s and was not deleted.":                           "Einmaliger Zeitplan wurde am %[1]s ausgeführt und nicht gelöscht.",
+			"Schedule targets a deleted resource.":                                          "Zeitplan zielt auf eine gelöschte Ressource.",
+			"Scheduled rule is disabled.":                                                   "Geplante Regel ist deaktiviert.",
+			"Scheduled rule targets only deleted resources.":                                "Geplante Regel zielt nur auf gelöschte Ressourcen.",
+			"Scheduled rule duplicates schedule %[1]s with the same expression and target.": "Geplante Regel dupliziert den Zeitplan %[1]s mit demselben Ausdruck und Ziel.",
+
+			// Months
+			"January": "Januar", "February": "Februar", "March": "März", "April": "April",
+			"May": "Mai", "June": "Juni", "July": "Juli", "August": "August",
+			"September": "September", "October": "Oktober", "November": "November", "December": "Dezember",
+
+			// Reasons
+			"Very low CPU utilization (%[1]s%%) in the last %[2]s days.":                                          "Sehr geringe CPU-Auslastung (%[1]s %%) in den letzten %[2]s Tagen.",
+			"Very low network activity (in: %[1]s KB/s, out: %[2]s KB/s) in the last %[3]s days.":                 "Sehr geringe Netzwerkaktivität (eingehend: %[1]s KB/s, ausgehend: %[2]s KB/s) in den letzten %[3]s Tagen.",
+			"Very low I/O activity (reads: %[1]s IOPS, writes: %[2]s IOPS) in the last %[3]s days.":               "Sehr geringe I/O-Aktivität (Lesen: %[1]s IOPS, Schreiben: %[2]s IOPS) in den letzten %[3]s Tagen.",
+			"Instance has been stopped for %[1]s days.":                                                           "Instanz ist seit %[1]s Tagen gestoppt.",
+			"Instance has been stopped for %[1]s":                                                                 "Instanz ist gestoppt seit: %[1]s",
+			"Non-running state: %[1]s":                                                                            "Nicht laufender Zustand: %[1]s",
+			"No active database connections":                                                                      "Keine aktiven Datenbankverbindungen",
+			"Not associated with any resource":                                                                    "Keiner Ressource zugeordnet",
+			"Not associated with any resource (EC2 Instance or ENI)":                                              "Keiner Ressource zugeordnet (EC2-Instanz oder ENI)",
+			"Associated with stopped instance %[1]s":                                                              "Der gestoppten Instanz %[1]s zugeordnet",
+			"VPC has no EC2 Instances or ENIs":                                                                    "VPC enthält keine EC2-Instanzen oder ENIs",
+			"Role has never been used.":                                                                           "Rolle wurde nie verwendet.",
+			"Role has no attached policies.":                                                                      "Rolle hat keine zugeordneten Richtlinien.",
+			"User has never logged in to the console":                                                             "Benutzer hat sich nie an der Konsole angemeldet",
+			"User has never used access keys":                                                                     "Benutzer hat nie Zugriffsschlüssel verwendet",
+			"Empty table with no read/write activity in the last %[1]s days.":                                     "Leere Tabelle ohne Lese-/Schreibaktivität in den letzten %[1]s Tagen.",
+			"Table has data but no read/write activity in the last %[1]s days.":                                   "Tabelle enthält Daten, aber keine Lese-/Schreibaktivität in den letzten %[1]s Tagen.",
+			"Provisioned capacity (%[1]s RCU, %[2]s WCU) unused and not decreased in %[3]s days.":                 "Bereitgestellte Kapazität (%[1]s RCU, %[2]s WCU) in %[3]s Tagen weder genutzt noch verringert.",
+			"Source volume was deleted. Snapshot has not been used in %[1]s days.":                                "Quell-Volume wurde gelöscht. Snapshot wurde seit %[1]s Tagen nicht verwendet.",
+			"Snapshot is %[1]s old.":                                                                              "Snapshot ist %[1]s alt.",
+			"Multiple snapshots exist for volume %[1]s: %[2]s newer snapshots supersede this one.":                "Für Volume %[1]s existieren mehrere Snapshots: %[2]s neuere Snapshots ersetzen diesen.",
+			"Snapshot is shared publicly.":                                                                        "Snapshot ist öffentlich freigegeben.",
+			"Snapshot is shared with unknown accounts: %[1]s.":                                                    "Snapshot ist für unbekannte Konten freigegeben: %[1]s.",
+			"Bucket has %[1]s incomplete multipart uploads older than %[2]s days (%[3]s GB).":                     "Bucket hat %[1]s unvollständige mehrteilige Uploads, die älter als %[2]s Tage sind (%[3]s GB).",
+			"No lifecycle rule aborts incomplete multipart uploads.":                                              "Keine Lebenszyklusregel bricht unvollständige mehrteilige Uploads ab.",
+			"IAM Access Analyzer reports the role as unused.":                                                     "IAM Access Analyzer meldet die Rolle als ungenutzt.",
+			"IAM Access Analyzer reports an unused access key.":                                                   "IAM Access Analyzer meldet einen ungenutzten Zugriffsschlüssel.",
+			"IAM Access Analyzer reports an unused console password.":                                             "IAM Access Analyzer meldet ein ungenutztes Konsolenpasswort.",
+			"IAM Access Analyzer reports unused permissions.":                                                     "IAM Access Analyzer meldet ungenutzte Berechtigungen.",
+			"Instance profile created %[1]s days ago is not used by any EC2 instance.":                            "Vor %[1]s Tagen erstelltes Instanzprofil wird von keiner EC2-Instanz verwendet.",
+			"Instance profile has no role.":                                                                       "Instanzprofil hat keine Rolle.",
+			"%[1]s provider created %[2]s days ago is not trusted by any role.":                                   "Vor %[2]s Tagen erstellter %[1]s-Anbieter wird von keiner Rolle als vertrauenswürdig eingestuft.",
+			"AWS Compute Optimizer reports over-provisioning: %[1]s is recommended instead of %[2]s.":             "AWS Compute Optimizer meldet Überdimensionierung: %[1]s wird anstelle von %[2]s empfohlen.",
+			"CloudWatch metrics unavailable, usage could not be verified.":                                        "CloudWatch-Metriken nicht verfügbar, Nutzung konnte nicht geprüft werden.",
+			"Lightsail instance is stopped but its bundle is still billed.":                                       "Lightsail-Instanz ist gestoppt, ihr Paket wird aber weiter berechnet.",
+			"No network traffic in the last %[1]s days.":                                                          "Kein Netzwerkverkehr in den letzten %[1]s Tagen.",
+			"Very low network traffic (%[1]s MB) in the last %[2]s days.":                                         "Sehr geringer Netzwerkverkehr (%[1]s MB) in den letzten %[2]s Tagen.",
+			"Lightsail database is stopped but its bundle is still billed.":                                       "Lightsail-Datenbank ist gestoppt, ihr Paket wird aber weiter berechnet.",
+			"No database connections in the last %[1]s days.":                                                     "Keine Datenbankverbindungen in den letzten %[1]s Tagen.",
+			"No builds in the last %[1]s days.":                                                                   "Keine Builds in den letzten %[1]s Tagen.",
+			"No requests in the last %[1]s days.":                                                                 "Keine Anfragen in den letzten %[1]s Tagen.",
+			"No deployments in the last %[1]s days.":                                                              "Keine Bereitstellungen in den letzten %[1]s Tagen.",
+			"Dev endpoint has been running for %[1]s hours and is billed per DPU-hour.":                           "Entwicklungsendpunkt läuft seit %[1]s Stunden und wird pro DPU-Stunde berechnet.",
+			"Crawler has never run.":                                                                              "Crawler wurde noch nie ausgeführt.",
+			"Last crawl did not succeed (%[1]s).":                                                                 "Letzter Crawl war nicht erfolgreich (%[1]s).",
+			"Crawler has not run in the last %[1]s days.":                                                         "Crawler wurde in den letzten %[1]s Tagen nicht ausgeführt.",
+			"Job has not run in the last %[1]s days but keeps %[2]s KB of bookmark state.":                        "Job wurde in den letzten %[1]s Tagen nicht ausgeführt, speichert aber %[2]s KB Lesezeichenstatus.",
+			"Cluster has been waiting for %[1]s hours and no steps were ever submitted.":                          "Cluster wartet seit %[1]s Stunden und es wurden nie Schritte übermittelt.",
+			"Cluster has been waiting for %[1]s hours without new steps.":                                         "Cluster wartet seit %[1]s Stunden ohne neue Schritte.",
+			"No queries in the last %[1]s days but %[2]s GB of query results are still stored.":                   "Keine Abfragen in den letzten %[1]s Tagen, aber %[2]s GB Abfrageergebnisse sind noch gespeichert.",
+			"Query results (%[1]s GB) are not expired by a lifecycle rule.":                                       "Abfrageergebnisse (%[1]s GB) werden durch keine Lebenszyklusregel gelöscht.",
+			"Project has never been built.":                                                                       "Projekt wurde noch nie gebaut.",
+			"Fleet keeps %[1]s instances of reserved capacity but no project built on it in the last %[2]s days.": "Flotte hält %[1]s Instanzen reservierter Kapazität, aber in den letzten %[2]s Tagen wurde kein Projekt darauf gebaut.",
+			"Pipeline has never been executed.":                                                                   "Pipeline wurde noch nie ausgeführt.",
+			"No pipeline executions in the last %[1]s days.":                                                      "Keine Pipeline-Ausführungen in den letzten %[1]s Tagen.",
+			"Route tables not associated with any subnet: %[1]s.":                                                 "Routentabellen ohne Subnetz-Zuordnung: %[1]s.",
+			"Egress-only internet gateways without routes: %[1]s.":                                                "Internet-Gateways nur für ausgehenden Datenverkehr ohne Routen: %[1]s.",
+			"Carrier gateways without routes: %[1]s.":                                                             "Carrier-Gateways ohne Routen: %[1]s.",
+			"Internet gateways not attached to a VPC: %[1]s.":                                                     "Nicht an eine VPC angefügte Internet-Gateways: %[1]s.",
+			"Egress-only internet gateways not attached to a VPC: %[1]s.":                                         "Nicht an eine VPC angefügte Internet-Gateways nur für ausgehenden Datenverkehr: %[1]s.",
+			"Virtual private gateways not attached to a VPC: %[1]s.":                                              "Nicht an eine VPC angefügte Virtual Private Gateways: %[1]s.",
+			"DHCP option sets not used by any VPC: %[1]s.":                                                        "Von keiner VPC verwendete DHCP-Optionssätze: %[1]s.",
+			"Metric stream sends to a deleted Firehose delivery stream.":                                          "Metrik-Stream sendet an einen gelöschten Firehose-Bereitstellungsstream.",
+			"Metric stream sends to a Firehose delivery stream that is %[1]s.":                                    "Metrik-Stream sendet an einen Firehose-Bereitstellungsstream mit dem Status %[1]s.",
+			"Contributor Insights rule matched no log events in the last %[1]s days.":                             "Contributor-Insights-Regel hat in den letzten %[1]s Tagen keine Protokollereignisse erfasst.",
+			"Composite alarm references deleted alarms: %[1]s.":                                                   "Zusammengesetzter Alarm verweist auf gelöschte Alarme: %[1]s.",
+			"Composite alarm has no actions and is not used by another composite alarm.":                          "Zusammengesetzter Alarm hat keine Aktionen und wird von keinem anderen zusammengesetzten Alarm verwendet.",
+			"Very low CPU utilization in the last %[1]s days.":                                                    "Sehr geringe CPU-Auslastung in den letzten %[1]s Tagen.",
+			"Very low network activity in the last %[1]s days.":                                                   "Sehr geringe Netzwerkaktivität in den letzten %[1]s Tagen.",
+			"Very low I/O activity in the last %[1]s days.":                                                       "Sehr geringe I/O-Aktivität in den letzten %[1]s Tagen.",
+			"Bucket has %[1]s incomplete multipart uploads older than %[2]s days.":                                "Bucket hat %[1]s unvollständige mehrteilige Uploads, die älter als %[2]s Tage sind.",
+			"Very low network traffic in the last %[1]s days.":                                                    "Sehr geringer Netzwerkverkehr in den letzten %[1]s Tagen.",
+			"Query results are not expired by a lifecycle rule.":                                                  "Abfrageergebnisse werden durch keine Lebenszyklusregel gelöscht.",
+			"Threshold: %[1]s": "Schwellenwert: %[1]s",
+			"Signals: %[1]s":   "Signale: %[1]s",
+		},
+	},
+	"fr": {
+		Language:          "fr",
+		DecimalSeparator:  ",",
+		ThousandSeparator: " ",
+		CurrencyAfter:     true,
+		DateFormat:        "2 January 2006 à 15:04 MST",
+		Messages: map[string]string{
+			// Report layout
+			"CloudSift - Scan Report":      "CloudSift - Rapport d'analyse",
+			"CloudSift Scan Report":        "Rapport d'analyse CloudSift",
+			"Scan completed at":            "Analyse terminée le",
+			"Scanned Accounts and Regions": "Comptes et régions analysés",
+			"Account ID":                   "ID du compte",
+			"Account Name":                 "Nom du compte",
+			"Regions":                      "Régions",
+			"Region":                       "Région",
+			"Resource Distribution":        "Répartition des ressources",
+			"Cost Breakdown":               "Répartition des coûts",
+			"Cost by Resource Type":        "Coût par type de ressource",
+			"Hourly":                       "Horaire",
+			"Daily":                        "Quotidien",
+			"Monthly":                      "Mensuel",
+			"Yearly":                       "Annuel",
+			"Lifetime":                     "Durée de vie",
+			"Cost":                         "Coût",
+			"Resource Type Counts":         "Nombre par type de ressource",
+			"Resource Type":                "Type de ressource",
+			"Count":                        "Nombre",
+			"Scan Metrics":                 "Métriques d'analyse",
+			"Metric":                       "Métrique",
+			"Value":                        "Valeur",
+			"Completed Scans":              "Analyses terminées",
+			"Failed Scans":                 "Analyses échouées",
+			"Tasks per Second":             "Tâches par seconde",
+			"Average Task Time":            "Durée moyenne des tâches",
+			"Worker Utilization":           "Utilisation des workers",
+			"workers":                      "workers",
+			"Total Run Time":               "Durée totale",
+			"AWS API Calls":                "Appels d'API AWS",
+			"Estimated Scan Cost (USD)":    "Coût estimé du scan (USD)",
+			"Slowest Tasks":                "Tâches les plus lentes",
+			"Skipped Accounts":             "Comptes ignorés",
+			"Account Spend":                "Dépenses par compte",
+			"Month-to-Date Spend":          "Dépenses du mois en cours",
+			"Projected Monthly Spend":      "Dépenses mensuelles projetées",
+			"Identified Monthly Waste":     "Gaspillage mensuel identifié",
+			"Waste % of Spend":             "Gaspillage en % des dépenses",
+			"Scanner":                      "Scanner",
+			"Duration":                     "Durée",
+			"Status":                       "Statut",
+			"Failed":                       "Échouée",
+			"Completed":                    "Terminée",
+			"Combined Cost Breakdown":      "Répartition combinée des coûts",
+			"N/A":                          "N/D",
+			"Lifetime cost not applicable for this resource type": "Coût sur la durée de vie non applicable pour ce type de ressource",
+			"Totals":                "Totaux",
+			"Unused Resources":      "Ressources inutilisées",
+			"Search resources...":   "Rechercher des ressources...",
+			"Clear":                 "Effacer",
+			"Export CSV":            "Exporter en CSV",
+			"Name":                  "Nom",
+			"Resource ID":           "ID de ressource",
+			"Reason":                "Raison",
+			"Triage":                "Tri",
+			"Acknowledged":          "Pris en compte",
+			"Planned for removal":   "Suppression planifiée",
+			"False positive":        "Faux positif",
+			"Actions":               "Actions",
+			"Details":               "Détails",
+			"Previous":              "Précédent",
+			"Next":                  "Suivant",
+			"Page %d of %d":         "Page %d sur %d",
+			"%s seconds":            "%s secondes",
+			"%d minutes %s seconds": "%d minutes %s secondes",
+			"Details could not be loaded. Open the report through a web server to view resource details.": "Les détails n'ont pas pu être chargés. Ouvrez le rapport via un serveur web pour afficher les détails des ressources.",
+			"Findings by VPC": "Constats par VPC",
+			"VPC ID":          "ID du VPC",
+			"VPC Name":        "Nom du VPC",
+			"Findings":        "Constats",
+			"Resource Types":  "Types de ressources",
+
+			// Waste forecast
+			"Projected Waste if Not Remediated":                         "Gaspillage projeté sans remédiation",
+			"Next %d months":                                            "%d prochains mois",
+			"Current monthly waste of %s projected at a constant rate.": "Gaspillage mensuel actuel de %s, projeté à taux constant.",
+			"Current monthly waste of %s projected with a monthly growth of %s%% from previous scans.": "Gaspillage mensuel actuel de %s, projeté avec la croissance mensuelle des scans précédents de %s %%.",
+
+			// Comparison with a previous scan
+			"Changes Since Previous Scan": "Changements depuis le scan précédent",
+			"Monthly Waste Change":        "Variation du gaspillage mensuel",
+			"New Findings":                "Nouveaux constats",
+			"Resolved Findings":           "Constats résolus",
+			"Unchanged Findings":          "Constats inchangés",
+			"Before":                      "Avant",
+			"Now":                         "Maintenant",
+			"Compared with %s: %d findings before, %d now.": "Comparé à %s : %d constats avant, %d maintenant.",
+
+			// API call budget
+			"budget exceeded, scan incomplete": "budget dépassé, scan incomplet",
+
+			// Stopped instance storage
+			"EBS Storage of Stopped Instances": "Stockage EBS des instances arrêtées",
+			"Monthly Cost":                     "Coût mensuel",
+			"Stopped Instances":                "Instances arrêtées",
+			"Attached Volumes":                 "Volumes attachés",
+			"Provisioned Storage":              "Stockage provisionné",
+
+			// Cost allocation by tag
+			"Savings by Tag":  "Économies par tag",
+			"Tag Key":         "Clé de tag",
+			"Tag Value":       "Valeur de tag",
+			"Monthly Savings": "Économies mensuelles",
+			"Yearly Savings":  "Économies annuelles",
+			"Untagged":        "Sans tag",
+
+			// Management account findings
+			"Management account": "Compte de gestion",
+
+			// Warm pool and hibernated instances
+			"Instance has been hibernated for %[1]s":                                 "L'instance est en veille prolongée depuis : %[1]s",
+			"Instance is kept in the warm pool of Auto Scaling group %[1]s (%[2]s).": "L'instance est conservée dans le warm pool du groupe Auto Scaling %[1]s (%[2]s).",
+
+			// Log subscription filters
+			"Subscription filter delivers to a deleted Lambda function.":                                                          "Le filtre d'abonnement livre vers une fonction Lambda supprimée.",
+			"Subscription filter delivers to a deleted Firehose delivery stream.":                                                 "Le filtre d'abonnement livre vers un flux de diffusion Firehose supprimé.",
+			"Subscription filter delivers to a deleted Kinesis stream.":                                                           "Le filtre d'abonnement livre vers un flux Kinesis supprimé.",
+			"Subscription filter delivers to a Firehose delivery stream that is %[1]s.":                                           "Le filtre d'abonnement livre vers un flux de diffusion Firehose à l'état %[1]s.",
+			"All invocations of the Lambda function the subscription filter delivers to failed in the last %[1]s days.":           "Toutes les invocations de la fonction Lambda vers laquelle livre le filtre d'abonnement ont échoué au cours des %[1]s derniers jours.",
+			"The Firehose delivery stream the subscription filter delivers to delivered no records to S3 in the last %[1]s days.": "Le flux de diffusion Firehose vers lequel livre le filtre d'abonnement n'a livré aucun enregistrement à S3 au cours des %[1]s derniers jours.",
+			"Subscription filter duplicates %[1]s with the same pattern and destination.":                                         "Le filtre d'abonnement duplique %[1]s avec le même modèle et la même destination.",
+
+			// EventBridge schedules
+			"Schedule has been disabled since %[1]s.":                                       "La planification est désactivée depuis le %[1]s.",
+			"Schedule ended on %[1]s and does not run again.":                               "La planification a pris fin le %[1]s et ne s'exécute plus.",
+			"One-time schedule ran on %[1]s and was not deleted.":                           "La planification ponctuelle s'est exécutée le %[1]s et n'a pas été supprimée.",
+			"Schedule targets a deleted resource.":                                          "La planification cible une ressource supprimée.",
+			"Scheduled rule is disabled.":                                                   "La règle planifiée est désactivée.",
+			"Scheduled rule targets only deleted resources.":                                "La règle planifiée ne cible que des ressources supprimées.",
+			"Scheduled rule duplicates schedule %[1]s with the same expression and target.": "La règle planifiée duplique la planification %[1]s avec la même expression et la même cible.",
+
+			// Months
+			"January": "janvier", "February": "février", "March": "mars", "April": "avril",
+			"May": "mai", "June": "juin", "July": "juillet", "August": "août",
+			"September": "septembre", "October": "octobre", "November": "novembre", "December": "décembre",
+
+			// Reasons
+			"Very low CPU utilization (%[1]s%%) in the last %[2]s days.":                                          "Utilisation CPU très faible (%[1]s %%) au cours des %[2]s derniers jours.",
+			"Very low network activity (in: %[1]s KB/s, out: %[2]s KB/s) in the last %[3]s days.":                 "Activité réseau très faible (entrant : %[1]s Ko/s, sortant : %[2]s Ko/s) au cours des %[3]s derniers jours.",
+			"Very low I/O activity (reads: %[1]s IOPS, writes: %[2]s IOPS) in the last %[3]s days.":               "Activité d'E/S très faible (lectures : %[1]s IOPS, écritures : %[2]s IOPS) au cours des %[3]s derniers jours.",
+			"Instance has been stopped for %[1]s days.":                                                           "L'instance est arrêtée depuis %[1]s jours.",
+			"Instance has been stopped for %[1]s":                                                                 "L'instance est arrêtée depuis : %[1]s",
+			"Non-running state: %[1]s":                                                                            "État non actif : %[1]s",
+			"No active database connections":                                                                      "Aucune connexion active à la base de données",
+			"Not associated with any resource":                                                                    "Associée à aucune ressource",
+			"Not associated with any resource (EC2 Instance or ENI)":                                              "Associée à aucune ressource (instance EC2 ou ENI)",
+			"Associated with stopped instance %[1]s":                                                              "Associée à l'instance arrêtée %[1]s",
+			"VPC has no EC2 Instances or ENIs":                                                                    "Le VPC ne contient aucune instance EC2 ni ENI",
+			"Role has never been used.":                                                                           "Le rôle n'a jamais été utilisé.",
+			"Role has no attached policies.":                                                                      "Le rôle n'a aucune politique attachée.",
+			"User has never logged in to the console":                                                             "L'utilisateur ne s'est jamais connecté à la console",
+			"User has never used access keys":                                                                     "L'utilisateur n'a jamais utilisé de clés d'accès",
+			"Empty table with no read/write activity in the last %[1]s days.":                                     "Table vide sans activité de lecture/écriture au cours des %[1]s derniers jours.",
+			"Table has data but no read/write activity in the last %[1]s days.":                                   "La table contient des données mais aucune activité de lecture/écriture au cours des %[1]s derniers jours.",
+			"Provisioned capacity (%[1]s RCU, %[2]s WCU) unused and not decreased in %[3]s days.":                 "Capacité provisionnée (%[1]s RCU, %[2]s WCU) ni consommée ni réduite en %[3]s jours.",
+			"Source volume was deleted. Snapshot has not been used in %[1]s days.":                                "Le volume source a été supprimé. L'instantané n'a pas été utilisé depuis %[1]s jours.",
+			"Snapshot is %[1]s old.":                                                                              "L'instantané date de %[1]s.",
+			"Multiple snapshots exist for volume %[1]s: %[2]s newer snapshots supersede this one.":                "Plusieurs instantanés existent pour le volume %[1]s : %[2]s instantanés plus récents remplacent celui-ci.",
+			"Snapshot is shared publicly.":                                                                        "L'instantané est partagé publiquement.",
+			"Snapshot is shared with unknown accounts: %[1]s.":                                                    "L'instantané est partagé avec des comptes inconnus : %[1]s.",
+			"Bucket has %[1]s incomplete multipart uploads older than %[2]s days (%[3]s GB).":                     "Le compartiment contient %[1]s chargements partitionnés incomplets de plus de %[2]s jours (%[3]s Go).",
+			"No lifecycle rule aborts incomplete multipart uploads.":                                              "Aucune règle de cycle de vie n'annule les chargements partitionnés incomplets.",
+			"IAM Access Analyzer reports the role as unused.":                                                     "IAM Access Analyzer signale le rôle comme inutilisé.",
+			"IAM Access Analyzer reports an unused access key.":                                                   "IAM Access Analyzer signale une clé d'accès inutilisée.",
+			"IAM Access Analyzer reports an unused console password.":                                             "IAM Access Analyzer signale un mot de passe de console inutilisé.",
+			"IAM Access Analyzer reports unused permissions.":                                                     "IAM Access Analyzer signale des autorisations inutilisées.",
+			"Instance profile created %[1]s days ago is not used by any EC2 instance.":                            "Le profil d'instance créé il y a %[1]s jours n'est utilisé par aucune instance EC2.",
+			"Instance profile has no role.":                                                                       "Le profil d'instance n'a pas de rôle.",
+			"%[1]s provider created %[2]s days ago is not trusted by any role.":                                   "Le fournisseur %[1]s créé il y a %[2]s jours n'est approuvé par aucun rôle.",
+			"AWS Compute Optimizer reports over-provisioning: %[1]s is recommended instead of %[2]s.":             "AWS Compute Optimizer signale un surdimensionnement : %[1]s est recommandé au lieu de %[2]s.",
+			"CloudWatch metrics unavailable, usage could not be verified.":                                        "Métriques CloudWatch indisponibles, l'utilisation n'a pas pu être vérifiée.",
+			"Lightsail instance is stopped but its bundle is still billed.":                                       "L'instance Lightsail est arrêtée mais son forfait est toujours facturé.",
+			"No network traffic in the last %[1]s days.":                                                          "Aucun trafic réseau au cours des %[1]s derniers jours.",
+			"Very low network traffic (%[1]s MB) in the last %[2]s days.":                                         "Trafic réseau très faible (%[1]s Mo) au cours des %[2]s derniers jours.",
+			"Lightsail database is stopped but its bundle is still billed.":                                       "La base de données Lightsail est arrêtée mais son forfait est toujours facturé.",
+			"No database connections in the last %[1]s days.":                                                     "Aucune connexion à la base de données au cours des %[1]s derniers jours.",
+			"No builds in the last %[1]s days.":                                                                   "Aucune génération au cours des %[1]s derniers jours.",
+			"No requests in the last %[1]s days.":                                                                 "Aucune requête au cours des %[1]s derniers jours.",
+			"No deployments in the last %[1]s days.":                                                              "Aucun déploiement au cours des %[1]s derniers jours.",
+			"Dev endpoint has been running for %[1]s hours and is billed per DPU-hour.":                           "Le point de terminaison de développement fonctionne depuis %[1]s heures et est facturé par heure DPU.",
+			"Crawler has never run.":                                                                              "Le robot d'analyse n'a jamais été exécuté.",
+			"Last crawl did not succeed (%[1]s).":                                                                 "La dernière analyse n'a pas réussi (%[1]s).",
+			"Crawler has not run in the last %[1]s days.":                                                         "Le robot d'analyse n'a pas été exécuté au cours des %[1]s derniers jours.",
+			"Job has not run in the last %[1]s days but keeps %[2]s KB of bookmark state.":                        "La tâche n'a pas été exécutée au cours des %[1]s derniers jours mais conserve %[2]s Ko d'état de signet.",
+			"Cluster has been waiting for %[1]s hours and no steps were ever submitted.":                          "Le cluster est en attente depuis %[1]s heures et aucune étape n'a jamais été soumise.",
+			"Cluster has been waiting for %[1]s hours without new steps.":                                         "Le cluster est en attente depuis %[1]s heures sans nouvelle étape.",
+			"No queries in the last %[1]s days but %[2]s GB of query results are still stored.":                   "Aucune requête au cours des %[1]s derniers jours mais %[2]s Go de résultats de requêtes sont toujours stockés.",
+			"Query results (%[1]s GB) are not expired by a lifecycle rule.":                                       "Les résultats de requêtes (%[1]s Go) ne sont expirés par aucune règle de cycle de vie.",
+			"Project has never been built.":                                                                       "Le projet n'a jamais été généré.",
+			"Fleet keeps %[1]s instances of reserved capacity but no project built on it in the last %[2]s days.": "La flotte conserve %[1]s instances de capacité réservée mais aucun projet n'y a été généré au cours des %[2]s derniers jours.",
+			"Pipeline has never been executed.":                                                                   "Le pipeline n'a jamais été exécuté.",
+			"No pipeline executions in the last %[1]s days.":                                                      "Aucune exécution du pipeline au cours des %[1]s derniers jours.",
+			"Route tables not associated with any subnet: %[1]s.":                                                 "Tables de routage associées à aucun sous-réseau : %[1]s.",
+			"Egress-only internet gateways without routes: %[1]s.":                                                "Passerelles Internet de sortie uniquement sans routes : %[1]s.",
+			"Carrier gateways without routes: %[1]s.":                                                             "Passerelles d'opérateur sans routes : %[1]s.",
+			"Internet gateways not attached to a VPC: %[1]s.":                                                     "Passerelles Internet non attachées à un VPC : %[1]s.",
+			"Egress-only internet gateways not attached to a VPC: %[1]s.":                                         "Passerelles Internet de sortie uniquement non attachées à un VPC : %[1]s.",
+			"Virtual private gateways not attached to a VPC: %[1]s.":                                              "Passerelles privées virtuelles non attachées à un VPC : %[1]s.",
+			"DHCP option sets not used by any VPC: %[1]s.":                                                        "Jeux d'options DHCP utilisés par aucun VPC : %[1]s.",
+			"Metric stream sends to a deleted Firehose delivery stream.":                                          "Le flux de métriques envoie vers un flux de diffusion Firehose supprimé.",
+			"Metric stream sends to a Firehose delivery stream that is %[1]s.":                                    "Le flux de métriques envoie vers un flux de diffusion Firehose à l'état %[1]s.",
+			"Contributor Insights rule matched no log events in the last %[1]s days.":                             "La règle Contributor Insights n'a correspondu à aucun événement de journal au cours des %[1]s derniers jours.",
+			"Composite alarm references deleted alarms: %[1]s.":                                                   "L'alarme composite fait référence à des alarmes supprimées : %[1]s.",
+			"Composite alarm has no actions and is not used by another composite alarm.":                          "L'alarme composite n'a aucune action et n'est utilisée par aucune autre alarme composite.",
+			"Very low CPU utilization in the last %[1]s days.":                                                    "Utilisation CPU très faible au cours des %[1]s derniers jours.",
+			"Very low network activity in the last %[1]s days.":                                                   "Activité réseau très faible au cours des %[1]s derniers jours.",
+			"Very low I/O activity in the last %[1]s days.":                                                       "Activité d'E/S très faible au cours des %[1]s derniers jours.",
+			"Bucket has %[1]s incomplete multipart uploads older than %[2]s days.":                                "Le compartiment contient %[1]s chargements partitionnés incomplets de plus de %[2]s jours.",
+			"Very low network traffic in the last %[1]s days.":                                                    "Trafic réseau très faible au cours des %[1]s derniers jours.",
+			"Query results are not expired by a lifecycle rule.":                                                  "Les résultats de requêtes ne sont expirés par aucune règle de cycle de vie.",
+			"Threshold: %[1]s": "Seuil : %[1]s",
+			"Signals: %[1]s":   "Signaux : %[1]s",
+		},
+	},
+}
+
+// SupportedLanguages returns the report languages that have a message catalog
+func SupportedLanguages() []string {
+	languages := make([]string, 0, len(locales))
+	for language := range locales {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// ValidateLanguage returns an error if the report language is not supported
+func ValidateLanguage(language string) error {
+	if _, ok := locales[language]; !ok {
+		return fmt.Errorf("unsupported report language %q (supported: %s)", language, strings.Join(SupportedLanguages(), ", "))
+	}
+	return nil
+}
+
+// getLocale returns the locale for a language, falling back to English
+func getLocale(language string) *locale {
+	if l, ok := locales[language]; ok {
+		return l
+	}
+	return locales[DefaultLanguage]
+}
+
+// translate returns the translation of an English message, or the message itself if untranslated
+func (l *locale) translate(message string) string {
+	if translated, ok := l.Messages[message]; ok {
+		return translated
+	}
+	return message
+}
+
+// number converts an English formatted number ("1,234.56") to the locale's separators
+func (l *locale) number(value string) string {
+	var b strings.Builder
+	for _, r := range value {
+		switch r {
+		case ',':
+			b.WriteString(l.ThousandSeparator)
+		case '.':
+			b.WriteString(l.DecimalSeparator)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// currency places a currency symbol before or after a formatted amount
+func (l *locale) currency(amount, symbol string) string {
+	if l.CurrencyAfter {
+		return amount + " " + symbol
+	}
+	return symbol + amount
+}
+
+// formatTime formats a timestamp using the locale's date layout and month names
+func (l *locale) formatTime(t time.Time) string {
+	layout := strings.Replace(l.DateFormat, "January", "\x00", 1)
+	return strings.Replace(t.Format(layout), "\x00", l.translate(t.Month().String()), 1)
+}
+
+// reason translates each line of a scanner reason that matches a known reason template
+func (l *locale) reason(reason string) string {
+	if l.Language == DefaultLanguage {
+		return reason
+	}
+	lines := strings.Split(reason, "\n")
+	for i, line := range lines {
+		for _, tmpl := range reasonTemplates {
+			matches := tmpl.Pattern.FindStringSubmatch(line)
+			if matches == nil {
+				continue
+			}
+			args := make([]interface{}, 0, len(matches)-1)
+			for _, match := range matches[1:] {
+				if numberPattern.MatchString(match) {
+					match = l.number(match)
+				}
+				args = append(args, match)
+			}
+			lines[i] = fmt.Sprintf(l.translate(tmpl.Format), args...)
+			break
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// duration formats a number of seconds as a human-readable duration
+func (l *locale) duration(seconds float64) string {
+	if seconds < 1 {
+		return fmt.Sprintf(l.translate("%s seconds"), l.number(fmt.Sprintf("%.6f", seconds)))
+	}
+	if seconds < 60 {
+		return fmt.Sprintf(l.translate("%s seconds"), l.number(fmt.Sprintf("%.2f", seconds)))
+	}
+	minutes := int(seconds / 60)
+	remainingSeconds := seconds - float64(minutes*60)
+	return fmt.Sprintf(l.translate("%d minutes %s seconds"), minutes, l.number(fmt.Sprintf("%.2f", remainingSeconds)))
+}
+
+// scriptMessages returns the translated strings used by the report scripts
+func (l *locale) scriptMessages() map[string]string {
+	messages := make(map[string]string)
+	for _, message := range []string{
+		"Hourly", "Daily", "Monthly", "Yearly", "Lifetime", "Cost", "Details", "Previous", "Next", "Page %d of %d",
+		"Details could not be loaded. Open the report through a web server to view resource details.",
+	} {
+		messages[message] = l.translate(message)
+	}
+	return messages
+}
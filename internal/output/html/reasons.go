@@ -0,0 +1,123 @@
+package html
+
+import (
+	"bytes"
+	"fmt"
+	htmlescape "html"
+	"html/template"
+	"regexp"
+	"strings"
+
+	"cloudsift/internal/aws"
+	"cloudsift/internal/logging"
+)
+
+// sentenceEnd matches the end of a sentence in a reason, where the report breaks the line.
+// Dots inside numbers and links are left alone.
+var sentenceEnd = regexp.MustCompile(`\.(\s+|$)`)
+
+// ReasonTemplate rewrites the scanner reasons matching a pattern when the report is rendered,
+// e.g. to point reviewers at an internal runbook
+type ReasonTemplate struct {
+	ResourceType string // Only rewrite reasons of this resource type (scanner label); optional
+	Match        string // Regular expression matched against each English reason line
+	Template     string // html/template rendering the replacement line, whose data is escaped
+}
+
+// reasonTemplateData is the data reason templates are executed with
+type reasonTemplateData struct {
+	Reason       string   // Reason line in the report language
+	Original     string   // Reason line as produced by the scanner
+	Matches      []string // Submatches of the Match expression
+	ResourceType string
+	ResourceID   string
+	ResourceName string
+	AccountID    string
+	AccountName  string
+	Region       string
+}
+
+// compiledReasonTemplate is a ReasonTemplate ready to be applied
+type compiledReasonTemplate struct {
+	resourceType string
+	pattern      *regexp.Regexp
+	tmpl         *template.Template
+}
+
+// compileReasonTemplates parses the match expressions and templates of reason templates
+func compileReasonTemplates(templates []ReasonTemplate) ([]compiledReasonTemplate, error) {
+	compiled := make([]compiledReasonTemplate, 0, len(templates))
+	for i, rt := range templates {
+		if rt.Template == "" {
+			return nil, fmt.Errorf("reason template %d has no template", i+1)
+		}
+		pattern, err := regexp.Compile(rt.Match)
+		if err != nil {
+			return nil, fmt.Errorf("invalid match of reason template %d: %w", i+1, err)
+		}
+		tmpl, err := template.New(fmt.Sprintf("reason_template_%d", i+1)).Option("missingkey=error").Parse(rt.Template)
+		if err != nil {
+			return nil, fmt.Errorf("invalid reason template %d: %w", i+1, err)
+		}
+		compiled = append(compiled, compiledReasonTemplate{
+			resourceType: rt.ResourceType,
+			pattern:      pattern,
+			tmpl:         tmpl,
+		})
+	}
+	return compiled, nil
+}
+
+// ValidateReasonTemplates returns an error if a reason template cannot be compiled
+func ValidateReasonTemplates(templates []ReasonTemplate) error {
+	_, err := compileReasonTemplates(templates)
+	return err
+}
+
+// renderReason translates a result's reason line by line and rewrites each line with the
+// first reason template that matches it, returning HTML. Lines no template matches are only
+// translated and escaped, since reasons contain resource names and tags set by anyone able to
+// create resources. Templates are HTML templates, which escape the data they render.
+func renderReason(l *locale, templates []compiledReasonTemplate, result aws.ScanResult, resourceName, resourceID, accountID, accountName, region string) string {
+	if len(templates) == 0 {
+		return htmlescape.EscapeString(l.reason(result.Reason))
+	}
+
+	lines := strings.Split(result.Reason, "\n")
+	for i, line := range lines {
+		translated := l.reason(line)
+		lines[i] = htmlescape.EscapeString(translated)
+		for _, rt := range templates {
+			if rt.resourceType != "" && !strings.EqualFold(rt.resourceType, result.ResourceType) {
+				continue
+			}
+			matches := rt.pattern.FindStringSubmatch(line)
+			if matches == nil {
+				continue
+			}
+			var buf bytes.Buffer
+			if err := rt.tmpl.Execute(&buf, reasonTemplateData{
+				Reason:       translated,
+				Original:     line,
+				Matches:      matches,
+				ResourceType: result.ResourceType,
+				ResourceID:   resourceID,
+				ResourceName: resourceName,
+				AccountID:    accountID,
+				AccountName:  accountName,
+				Region:       region,
+			}); err != nil {
+				// Keep the translated reason rather than dropping it from the report
+				logging.Warn("Failed to apply reason template", map[string]interface{}{
+					"template":    rt.tmpl.Name(),
+					"resource_id": resourceID,
+					"error":       err.Error(),
+				})
+				continue
+			}
+			lines[i] = buf.String()
+			break
+		}
+	}
+	return strings.Join(lines, "\n")
+}
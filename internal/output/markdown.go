@@ -0,0 +1,117 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	awsutil "cloudsift/internal/aws"
+)
+
+// markdownTopResources is the number of resources with the highest monthly savings listed in
+// the Markdown summary
+const markdownTopResources = 20
+
+// WriteMarkdown writes a compact Markdown summary of scan results, with the totals by resource
+// type, the resources with the highest monthly savings and the totals of each account, to be
+// pasted into a Slack message or a GitHub issue. Costs are shown with the currency symbol.
+func WriteMarkdown(w io.Writer, results []awsutil.ScanResult, currencySymbol string) error {
+	var b strings.Builder
+	summary := SummarizeResults(results)
+
+	fmt.Fprintf(&b, "## CloudSift scan summary\n\n")
+	fmt.Fprintf(&b, "**%d findings** in %d accounts, estimated savings of **%s/month**\n",
+		summary.Findings, len(summary.Accounts), formatMarkdownCost(currencySymbol, summary.MonthlySavings))
+	if summary.UnverifiedSavings > 0 {
+		fmt.Fprintf(&b, "\n%s/month of low confidence findings is not counted, their usage could not be verified\n",
+			formatMarkdownCost(currencySymbol, summary.UnverifiedSavings))
+	}
+
+	fmt.Fprintf(&b, "\n### Savings by resource type\n\n")
+	fmt.Fprintf(&b, "| Resource type | Findings | Monthly savings |\n|---|---:|---:|\n")
+	for _, group := range summary.ResourceTypes {
+		fmt.Fprintf(&b, "| %s | %d | %s |\n", escapeMarkdownCell(group.ResourceType), group.Findings, formatMarkdownCost(currencySymbol, group.MonthlySavings))
+	}
+
+	top := make([]awsutil.ScanResult, 0, len(results))
+	for _, result := range results {
+		if SavingsCost(result) > 0 {
+			top = append(top, result)
+		}
+	}
+	sort.SliceStable(top, func(i, j int) bool {
+		return SavingsCost(top[i]) > SavingsCost(top[j])
+	})
+	if len(top) > markdownTopResources {
+		top = top[:markdownTopResources]
+	}
+	if len(top) > 0 {
+		fmt.Fprintf(&b, "\n### Top %d resources by monthly savings\n\n", len(top))
+		fmt.Fprintf(&b, "| Account | Region | Resource type | Resource | Monthly savings |\n|---|---|---|---|---:|\n")
+		for _, result := range top {
+			region, _ := result.Details["region"].(string)
+			resource := fmt.Sprintf("`%s`", result.ResourceID)
+			if result.ResourceName != "" && result.ResourceName != result.ResourceID {
+				resource = fmt.Sprintf("%s (`%s`)", result.ResourceName, result.ResourceID)
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s | %s | %s |\n",
+				result.AccountID, region, escapeMarkdownCell(result.ResourceType), escapeMarkdownCell(resource),
+				formatMarkdownCost(currencySymbol, MonthlyCost(result)))
+		}
+	}
+
+	fmt.Fprintf(&b, "\n### Savings by account\n\n")
+	fmt.Fprintf(&b, "| Account | Findings | Monthly savings |\n|---|---:|---:|\n")
+	for _, group := range summary.Accounts {
+		fmt.Fprintf(&b, "| %s | %d | %s |\n", escapeMarkdownCell(group.Account()), group.Findings, formatMarkdownCost(currencySymbol, group.MonthlySavings))
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("failed to write Markdown summary: %w", err)
+	}
+	return nil
+}
+
+// WriteMarkdownFile writes a Markdown summary of scan results to the given path
+func WriteMarkdownFile(results []awsutil.ScanResult, currencySymbol, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create Markdown file: %w", err)
+	}
+	if err := WriteMarkdown(f, results, currencySymbol); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to close Markdown file: %w", err)
+	}
+	return nil
+}
+
+// formatMarkdownCost formats a cost with the currency symbol, two decimals and thousands
+// separators
+func formatMarkdownCost(currencySymbol string, cost float64) string {
+	formatted := fmt.Sprintf("%.2f", cost)
+	integer, decimals := formatted[:len(formatted)-3], formatted[len(formatted)-3:]
+	var b strings.Builder
+	b.WriteString(currencySymbol)
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 && integer[i-1] != '-' {
+			b.WriteByte(',')
+		}
+		b.WriteRune(digit)
+	}
+	return b.String() + decimals
+}
+
+// escapeMarkdownCell escapes the characters breaking a Markdown table cell
+func escapeMarkdownCell(text string) string {
+	return strings.NewReplacer("|", "\\|", "\n", " ").Replace(text)
+}
@@ -0,0 +1,137 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	awsutil "cloudsift/internal/aws"
+	"cloudsift/internal/logging"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ssm"
+)
+
+const (
+	// opsItemSource is the source of the OpsItems opened for findings
+	opsItemSource = "cloudsift"
+
+	// Field length limits of OpsItems
+	opsItemMaxTitle       = 1024
+	opsItemMaxDescription = 2048
+)
+
+// opsItemSeverities maps finding severities to OpsItem severities, where 1 is the most severe
+var opsItemSeverities = map[string]string{
+	SeverityHigh:          "1",
+	SeverityMedium:        "2",
+	SeverityLow:           "3",
+	SeverityInformational: "4",
+}
+
+// OpsItemInput builds the input opening an OpsItem for a scan result. The finding ID is the
+// deduplication string, so OpsCenter keeps a single open OpsItem per resource across scans.
+func OpsItemInput(result awsutil.ScanResult, region string) (*ssm.CreateOpsItemInput, error) {
+	resourceRegion, _ := result.Details["region"].(string)
+	if resourceRegion == "" || awsutil.IsGlobalRegionLabel(resourceRegion) {
+		resourceRegion = region
+	}
+	name := result.ResourceName
+	if name == "" {
+		name = result.ResourceID
+	}
+
+	dedup, err := json.Marshal(map[string]string{"dedupString": findingID(result, resourceRegion)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal deduplication string: %w", err)
+	}
+	operationalData := map[string]*ssm.OpsItemDataValue{
+		"/aws/dedup": {
+			Type:  aws.String(ssm.OpsItemDataTypeSearchableString),
+			Value: aws.String(string(dedup)),
+		},
+		"cloudsift:resource_id": {
+			Type:  aws.String(ssm.OpsItemDataTypeSearchableString),
+			Value: aws.String(result.ResourceID),
+		},
+		"cloudsift:resource_type": {
+			Type:  aws.String(ssm.OpsItemDataTypeSearchableString),
+			Value: aws.String(result.ResourceType),
+		},
+		"cloudsift:region": {
+			Type:  aws.String(ssm.OpsItemDataTypeSearchableString),
+			Value: aws.String(resourceRegion),
+		},
+		"cloudsift:monthly_cost": {
+			Type:  aws.String(ssm.OpsItemDataTypeString),
+			Value: aws.String(fmt.Sprintf("%.2f", MonthlyCost(result))),
+		},
+	}
+
+	// Resources given by ARN are linked in the OpsItem's related resources
+	if strings.HasPrefix(result.ResourceID, "arn:") {
+		resources, err := json.Marshal([]map[string]string{{"arn": result.ResourceID}})
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal related resources: %w", err)
+		}
+		operationalData["/aws/resources"] = &ssm.OpsItemDataValue{
+			Type:  aws.String(ssm.OpsItemDataTypeSearchableString),
+			Value: aws.String(string(resources)),
+		}
+	}
+	if result.AccountTeam != "" {
+		operationalData["cloudsift:team"] = &ssm.OpsItemDataValue{
+			Type:  aws.String(ssm.OpsItemDataTypeSearchableString),
+			Value: aws.String(result.AccountTeam),
+		}
+	}
+
+	description := result.Reason
+	if description == "" {
+		description = name
+	}
+
+	return &ssm.CreateOpsItemInput{
+		Source:          aws.String(opsItemSource),
+		Category:        aws.String("Cost"),
+		Severity:        aws.String(opsItemSeverities[Severity(result)]),
+		Title:           aws.String(truncate(fmt.Sprintf("%s: %s", result.ResourceType, name), opsItemMaxTitle)),
+		Description:     aws.String(truncate(description, opsItemMaxDescription)),
+		OperationalData: operationalData,
+	}, nil
+}
+
+// OpenOpsItems opens OpsItems in a region for the scan results of an account rated at least
+// minSeverity. The session must belong to the account. It returns the number of OpsItems
+// opened; findings with an open OpsItem are deduplicated by OpsCenter.
+func OpenOpsItems(sess *session.Session, region, minSeverity string, results []awsutil.ScanResult) (int, error) {
+	client := ssm.New(sess, aws.NewConfig().WithRegion(region))
+	minRank := SeverityRank(minSeverity)
+
+	opened := 0
+	for _, result := range results {
+		if SeverityRank(Severity(result)) < minRank {
+			continue
+		}
+
+		input, err := OpsItemInput(result, region)
+		if err != nil {
+			return opened, err
+		}
+		output, err := client.CreateOpsItem(input)
+		if err != nil {
+			if aerr, ok := err.(awserr.Error); ok && aerr.Code() == ssm.ErrCodeOpsItemAlreadyExistsException {
+				continue
+			}
+			return opened, fmt.Errorf("failed to create OpsItem: %w", err)
+		}
+		opened++
+		logging.Debug("Opened OpsItem", map[string]interface{}{
+			"ops_item_id": aws.StringValue(output.OpsItemId),
+			"resource_id": result.ResourceID,
+		})
+	}
+	return opened, nil
+}
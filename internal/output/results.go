@@ -0,0 +1,85 @@
+package output
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	awsutil "cloudsift/internal/aws"
+)
+
+// AccountResults is the scan result of one account, as written by scan --output-format json
+type AccountResults struct {
+	AccountID   string                         `json:"account_id"`
+	AccountName string                         `json:"account_name"`
+	ScannedAt   time.Time                      `json:"scanned_at"` // Zero in results of scans before it was recorded
+	Results     map[string]awsutil.ScanResults `json:"results"`
+}
+
+// ReadResults reads the account scan results of a file, which may be gzipped and hold a
+// single account or a list of accounts. Total costs are decoded into cost breakdowns, like
+// the results of a scan.
+func ReadResults(path string) ([]AccountResults, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scan results: %w", err)
+	}
+	if bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress scan results %s: %w", path, err)
+		}
+		if data, err = io.ReadAll(gz); err != nil {
+			return nil, fmt.Errorf("failed to decompress scan results %s: %w", path, err)
+		}
+	}
+
+	data = bytes.TrimSpace(data)
+	var accounts []AccountResults
+	if bytes.HasPrefix(data, []byte("[")) {
+		err = json.Unmarshal(data, &accounts)
+	} else {
+		var account AccountResults
+		err = json.Unmarshal(data, &account)
+		accounts = append(accounts, account)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse scan results %s: %w", path, err)
+	}
+	for _, account := range accounts {
+		if account.AccountID == "" {
+			return nil, fmt.Errorf("scan results %s have no account_id; pass results written by scan --output-format json", path)
+		}
+		for _, results := range account.Results {
+			for i := range results {
+				if err := decodeTotalCost(&results[i]); err != nil {
+					return nil, fmt.Errorf("failed to parse cost of %s in scan results %s: %w", results[i].ResourceID, path, err)
+				}
+			}
+		}
+	}
+	return accounts, nil
+}
+
+// decodeTotalCost replaces the total cost of a result decoded from JSON, a generic map, with
+// its cost breakdown
+func decodeTotalCost(result *awsutil.ScanResult) error {
+	total, ok := result.Cost["total"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	data, err := json.Marshal(total)
+	if err != nil {
+		return err
+	}
+	var costs awsutil.CostBreakdown
+	if err := json.Unmarshal(data, &costs); err != nil {
+		return err
+	}
+	result.Cost["total"] = &costs
+	return nil
+}
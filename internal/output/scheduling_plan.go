@@ -0,0 +1,131 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	awsutil "cloudsift/internal/aws"
+)
+
+// schedulingPlanName is the schedule and period name used for recommended instance schedules
+const schedulingPlanName = "cloudsift-business-hours"
+
+// SchedulingPlan is a machine-readable set of instance scheduling recommendations. The
+// periods and schedules follow the AWS Instance Scheduler configuration format, and each
+// instance lists the tag that enrolls it in the schedule.
+type SchedulingPlan struct {
+	GeneratedAt time.Time           `json:"generated_at"`
+	Periods     []SchedulePeriod    `json:"periods"`
+	Schedules   []Schedule          `json:"schedules"`
+	Instances   []ScheduledInstance `json:"instances"`
+}
+
+// SchedulePeriod is an AWS Instance Scheduler period
+type SchedulePeriod struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	BeginTime   string `json:"begintime"`
+	EndTime     string `json:"endtime"`
+	Weekdays    string `json:"weekdays"`
+}
+
+// Schedule is an AWS Instance Scheduler schedule
+type Schedule struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Timezone    string   `json:"timezone"`
+	Periods     []string `json:"periods"`
+}
+
+// ScheduledInstance is an instance recommended for a scheduled stop/start
+type ScheduledInstance struct {
+	AccountID               string            `json:"account_id"`
+	AccountName             string            `json:"account_name"`
+	Region                  string            `json:"region"`
+	InstanceID              string            `json:"instance_id"`
+	Name                    string            `json:"name"`
+	Schedule                string            `json:"schedule"`
+	Tags                    map[string]string `json:"tags"`
+	StartCron               string            `json:"start_cron"`
+	StopCron                string            `json:"stop_cron"`
+	EstimatedMonthlySavings float64           `json:"estimated_monthly_savings,omitempty"`
+}
+
+// BuildSchedulingPlan creates a scheduling plan from the instances in the scan results that
+// were flagged as candidates for a scheduled stop/start
+func BuildSchedulingPlan(results []awsutil.ScanResult, bh *awsutil.BusinessHours) *SchedulingPlan {
+	plan := &SchedulingPlan{
+		GeneratedAt: time.Now().UTC(),
+		Periods: []SchedulePeriod{{
+			Name:        schedulingPlanName,
+			Description: "Business hours recommended by CloudSift",
+			BeginTime:   bh.StartClock(),
+			EndTime:     bh.EndClock(),
+			Weekdays:    bh.Weekdays(),
+		}},
+		Schedules: []Schedule{{
+			Name:        schedulingPlanName,
+			Description: "Run instances only during business hours",
+			Timezone:    bh.Location.String(),
+			Periods:     []string{schedulingPlanName},
+		}},
+		Instances: make([]ScheduledInstance, 0),
+	}
+
+	for _, result := range results {
+		if recommendation, _ := result.Details["recommendation"].(string); recommendation != "scheduled_stop_start" {
+			continue
+		}
+
+		region, _ := result.Details["region"].(string)
+		instance := ScheduledInstance{
+			AccountID:   result.AccountID,
+			AccountName: result.AccountName,
+			Region:      region,
+			InstanceID:  result.ResourceID,
+			Name:        result.ResourceName,
+			Schedule:    schedulingPlanName,
+			Tags:        map[string]string{"Schedule": schedulingPlanName},
+			StartCron:   bh.StartCron(),
+			StopCron:    bh.StopCron(),
+		}
+		if savings, ok := result.Details["estimated_off_hours_savings"].(map[string]interface{}); ok {
+			instance.EstimatedMonthlySavings, _ = savings["monthly"].(float64)
+		}
+		plan.Instances = append(plan.Instances, instance)
+	}
+
+	sort.Slice(plan.Instances, func(i, j int) bool {
+		if plan.Instances[i].AccountID != plan.Instances[j].AccountID {
+			return plan.Instances[i].AccountID < plan.Instances[j].AccountID
+		}
+		if plan.Instances[i].Region != plan.Instances[j].Region {
+			return plan.Instances[i].Region < plan.Instances[j].Region
+		}
+		return plan.Instances[i].InstanceID < plan.Instances[j].InstanceID
+	})
+
+	return plan
+}
+
+// WriteSchedulingPlan writes a scheduling plan to the given path as indented JSON
+func WriteSchedulingPlan(plan *SchedulingPlan, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal scheduling plan: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write scheduling plan: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,226 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	awsutil "cloudsift/internal/aws"
+	"cloudsift/internal/logging"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/securityhub"
+)
+
+const (
+	// securityHubBatchSize is the maximum number of findings accepted by BatchImportFindings
+	securityHubBatchSize = 100
+
+	// securityHubFindingType classifies cost findings in the ASFF type taxonomy
+	securityHubFindingType = "Software and Configuration Checks/Cost Optimization/Unused Resources"
+
+	// Field length limits of the ASFF
+	securityHubMaxTitle       = 256
+	securityHubMaxDescription = 1024
+)
+
+// findingID returns a stable ID of the finding of a resource, so integrations update the
+// finding of later scans rather than duplicating it
+func findingID(result awsutil.ScanResult, region string) string {
+	return fmt.Sprintf("cloudsift/%s/%s/%s/%s", result.AccountID, region, result.ResourceType, result.ResourceID)
+}
+
+// securityHubProductARN returns the ARN of the account's default product in a region, which
+// custom findings are imported into
+func securityHubProductARN(region, accountID string) string {
+	partition := endpoints.AwsPartitionID
+	if p, ok := endpoints.PartitionForRegion(endpoints.DefaultPartitions(), region); ok {
+		partition = p.ID()
+	}
+	return fmt.Sprintf("arn:%s:securityhub:%s:%s:product/%s/default", partition, region, accountID, accountID)
+}
+
+// truncate shortens s to at most n bytes
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
+
+// SecurityHubFinding converts a scan result to an AWS Security Finding Format (ASFF) finding
+// of the account's default product, imported in the given region
+func SecurityHubFinding(result awsutil.ScanResult, region string, observedAt time.Time) *securityhub.AwsSecurityFinding {
+	partition := endpoints.AwsPartitionID
+	if p, ok := endpoints.PartitionForRegion(endpoints.DefaultPartitions(), region); ok {
+		partition = p.ID()
+	}
+
+	// Global resources such as IAM roles are reported in the import region
+	resourceRegion, _ := result.Details["region"].(string)
+	if resourceRegion == "" || awsutil.IsGlobalRegionLabel(resourceRegion) {
+		resourceRegion = region
+	}
+	name := result.ResourceName
+	if name == "" {
+		name = result.ResourceID
+	}
+	timestamp := observedAt.UTC().Format(time.RFC3339)
+	monthlyCost := MonthlyCost(result)
+
+	tags := make(map[string]*string, len(result.Tags))
+	for key, value := range result.Tags {
+		tags[key] = aws.String(value)
+	}
+
+	finding := &securityhub.AwsSecurityFinding{
+		SchemaVersion: aws.String("2018-10-08"),
+		Id:            aws.String(findingID(result, resourceRegion)),
+		ProductArn:    aws.String(securityHubProductARN(region, result.AccountID)),
+		GeneratorId:   aws.String("cloudsift/" + result.ResourceType),
+		AwsAccountId:  aws.String(result.AccountID),
+		Types:         aws.StringSlice([]string{securityHubFindingType}),
+		CreatedAt:     aws.String(timestamp),
+		UpdatedAt:     aws.String(timestamp),
+		Severity:      &securityhub.Severity{Label: aws.String(Severity(result))},
+		Title:         aws.String(truncate(fmt.Sprintf("%s: %s", result.ResourceType, name), securityHubMaxTitle)),
+		Description:   aws.String(truncate(strings.ReplaceAll(result.Reason, "\n", " "), securityHubMaxDescription)),
+		ProductFields: map[string]*string{
+			"cloudsift/ResourceType": aws.String(result.ResourceType),
+			"cloudsift/MonthlyCost":  aws.String(fmt.Sprintf("%.2f", monthlyCost)),
+		},
+		Resources: []*securityhub.Resource{
+			{
+				Type:      aws.String("Other"),
+				Id:        aws.String(result.ResourceID),
+				Partition: aws.String(partition),
+				Region:    aws.String(resourceRegion),
+				Tags:      tags,
+			},
+		},
+		RecordState: aws.String(securityhub.RecordStateActive),
+		Workflow:    &securityhub.Workflow{Status: aws.String(securityhub.WorkflowStatusNew)},
+	}
+	if result.AccountName != "" {
+		finding.AwsAccountName = aws.String(result.AccountName)
+	}
+
+	// Team and environment let ticket routing rules assign findings like security findings
+	userFields := make(map[string]*string)
+	if result.AccountTeam != "" {
+		userFields["team"] = aws.String(result.AccountTeam)
+	}
+	if result.AccountEnv != "" {
+		userFields["environment"] = aws.String(result.AccountEnv)
+	}
+	if len(userFields) > 0 {
+		finding.UserDefinedFields = userFields
+	}
+	return finding
+}
+
+// SecurityHubScope reports whether a scan covered a resource type in a region, so that
+// findings it no longer reports there were resolved
+type SecurityHubScope func(resourceType, region string) bool
+
+// activeSecurityHubFindings returns the active CloudSift findings of an account's default
+// product by ID
+func activeSecurityHubFindings(client *securityhub.SecurityHub, productARN string) (map[string]*securityhub.AwsSecurityFinding, error) {
+	active := make(map[string]*securityhub.AwsSecurityFinding)
+	err := client.GetFindingsPages(&securityhub.GetFindingsInput{
+		Filters: &securityhub.AwsSecurityFindingFilters{
+			ProductArn:  []*securityhub.StringFilter{{Comparison: aws.String(securityhub.StringFilterComparisonEquals), Value: aws.String(productARN)}},
+			GeneratorId: []*securityhub.StringFilter{{Comparison: aws.String(securityhub.StringFilterComparisonPrefix), Value: aws.String("cloudsift/")}},
+			RecordState: []*securityhub.StringFilter{{Comparison: aws.String(securityhub.StringFilterComparisonEquals), Value: aws.String(securityhub.RecordStateActive)}},
+		},
+		MaxResults: aws.Int64(100),
+	}, func(page *securityhub.GetFindingsOutput, lastPage bool) bool {
+		for _, finding := range page.Findings {
+			active[aws.StringValue(finding.Id)] = finding
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Security Hub findings: %w", err)
+	}
+	return active, nil
+}
+
+// SecurityHubFindings returns the findings to import for the scan results of an account.
+// Findings already active keep their CreatedAt. Active findings in the scope of the scan that
+// it no longer reports are archived, so resolved resources do not stay open.
+func SecurityHubFindings(results []awsutil.ScanResult, region string, observedAt time.Time, active map[string]*securityhub.AwsSecurityFinding, scope SecurityHubScope) []*securityhub.AwsSecurityFinding {
+	findings := make([]*securityhub.AwsSecurityFinding, 0, len(results))
+	reported := make(map[string]bool, len(results))
+	for _, result := range results {
+		finding := SecurityHubFinding(result, region, observedAt)
+		id := aws.StringValue(finding.Id)
+		reported[id] = true
+		if previous, ok := active[id]; ok && previous.CreatedAt != nil {
+			finding.CreatedAt = previous.CreatedAt
+		}
+		findings = append(findings, finding)
+	}
+
+	ids := make([]string, 0, len(active))
+	for id := range active {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		previous := active[id]
+		if reported[id] || len(previous.Resources) == 0 {
+			continue
+		}
+		resourceType := aws.StringValue(previous.ProductFields["cloudsift/ResourceType"])
+		if scope == nil || !scope(resourceType, aws.StringValue(previous.Resources[0].Region)) {
+			continue
+		}
+		archived := *previous
+		archived.RecordState = aws.String(securityhub.RecordStateArchived)
+		archived.UpdatedAt = aws.String(observedAt.UTC().Format(time.RFC3339))
+		findings = append(findings, &archived)
+	}
+	return findings
+}
+
+// PublishSecurityHubFindings imports scan results of an account as Security Hub custom
+// findings in a region, and archives the active findings in the scope of the scan that it
+// no longer reports. The session must belong to the account, since findings of the default
+// product can only be imported by the account they belong to. It returns the number of
+// findings imported, including the archived ones.
+func PublishSecurityHubFindings(sess *session.Session, region, accountID string, results []awsutil.ScanResult, scope SecurityHubScope) (int, error) {
+	client := securityhub.New(sess, aws.NewConfig().WithRegion(region))
+	observedAt := time.Now()
+
+	active, err := activeSecurityHubFindings(client, securityHubProductARN(region, accountID))
+	if err != nil {
+		return 0, err
+	}
+	findings := SecurityHubFindings(results, region, observedAt, active, scope)
+
+	imported := 0
+	for i := 0; i < len(findings); i += securityHubBatchSize {
+		end := i + securityHubBatchSize
+		if end > len(findings) {
+			end = len(findings)
+		}
+
+		output, err := client.BatchImportFindings(&securityhub.BatchImportFindingsInput{Findings: findings[i:end]})
+		if err != nil {
+			return imported, fmt.Errorf("failed to import Security Hub findings: %w", err)
+		}
+		imported += int(aws.Int64Value(output.SuccessCount))
+		for _, failed := range output.FailedFindings {
+			logging.Warn("Security Hub rejected finding", map[string]interface{}{
+				"finding_id": aws.StringValue(failed.Id),
+				"error_code": aws.StringValue(failed.ErrorCode),
+				"error":      aws.StringValue(failed.ErrorMessage),
+			})
+		}
+	}
+	return imported, nil
+}
@@ -0,0 +1,88 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+
+	awsutil "cloudsift/internal/aws"
+)
+
+// Finding severities, ordered from least to most severe. Integrations that track findings
+// in other tools map them to their own severity scales.
+const (
+	SeverityInformational = "INFORMATIONAL"
+	SeverityLow           = "LOW"
+	SeverityMedium        = "MEDIUM"
+	SeverityHigh          = "HIGH"
+)
+
+// Monthly cost from which a finding is rated at a severity, in the report currency
+const (
+	severityLowMonthlyCost    = 0.01
+	severityMediumMonthlyCost = 10
+	severityHighMonthlyCost   = 100
+)
+
+// MonthlyCost returns the monthly cost of a result, or 0 if it has no cost
+func MonthlyCost(result awsutil.ScanResult) float64 {
+	if total, ok := result.Cost["total"].(*awsutil.CostBreakdown); ok && total != nil {
+		return total.MonthlyRate
+	}
+	return 0
+}
+
+// SavingsCost returns the monthly cost a result counts toward savings totals: its MonthlyCost,
+// or 0 for low confidence results, whose resources may still be in use
+func SavingsCost(result awsutil.ScanResult) float64 {
+	if costs := result.SavingsBreakdown(); costs != nil {
+		return costs.MonthlyRate
+	}
+	return 0
+}
+
+// UnverifiedCost returns the monthly cost of a low confidence result, reported apart from the
+// savings totals, or 0 for other results
+func UnverifiedCost(result awsutil.ScanResult) float64 {
+	if !result.LowConfidence() {
+		return 0
+	}
+	return MonthlyCost(result)
+}
+
+// Severity rates a result by the monthly cost of the resource. Findings without a cost, such
+// as unused IAM roles, are informational.
+func Severity(result awsutil.ScanResult) string {
+	cost := MonthlyCost(result)
+	switch {
+	case cost >= severityHighMonthlyCost:
+		return SeverityHigh
+	case cost >= severityMediumMonthlyCost:
+		return SeverityMedium
+	case cost >= severityLowMonthlyCost:
+		return SeverityLow
+	default:
+		return SeverityInformational
+	}
+}
+
+// severities lists the finding severities from least to most severe
+var severities = []string{SeverityInformational, SeverityLow, SeverityMedium, SeverityHigh}
+
+// SeverityRank returns the position of a severity from least to most severe, or -1 if the
+// severity is unknown
+func SeverityRank(severity string) int {
+	for i, s := range severities {
+		if strings.EqualFold(s, severity) {
+			return i
+		}
+	}
+	return -1
+}
+
+// ValidateSeverity returns an error if a severity is unknown
+func ValidateSeverity(severity string) error {
+	if SeverityRank(severity) < 0 {
+		return fmt.Errorf("invalid severity %q: must be one of %s", severity, strings.Join(severities, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+
+	awsutil "cloudsift/internal/aws"
+)
+
+// SummaryAccount is the results of an account to summarize
+type SummaryAccount struct {
+	AccountID   string
+	AccountName string
+	Results     []awsutil.ScanResult
+}
+
+// Summary is the findings count and estimated monthly savings of scan results, in total and
+// grouped. It is the single source of the terminal, Markdown, notification and metrics
+// summaries of a scan, so they agree on the savings of a run. The cost of low confidence
+// findings is left out of the savings and summed apart.
+type Summary struct {
+	Findings           int
+	MonthlySavings     float64
+	UnverifiedFindings int
+	UnverifiedSavings  float64
+	Accounts           []SummaryGroup // By account, highest savings first
+	ResourceTypes      []SummaryGroup // By resource type, highest savings first
+	Groups             []SummaryGroup // By account and resource type, ordered by account ID and resource type
+}
+
+// SummaryGroup is the findings count and estimated monthly savings of an account, a resource
+// type or a resource type in an account. Fields the results are not grouped by are empty.
+type SummaryGroup struct {
+	AccountID      string
+	AccountName    string
+	ResourceType   string
+	Findings       int
+	MonthlySavings float64
+}
+
+// Account returns the name and ID of the account of the group, or the ID alone if the account
+// has no other name
+func (g SummaryGroup) Account() string {
+	if g.AccountName != "" && g.AccountName != g.AccountID {
+		return fmt.Sprintf("%s (%s)", g.AccountName, g.AccountID)
+	}
+	return g.AccountID
+}
+
+// Summarize summarizes the results of accounts. Accounts without results are kept, with no
+// findings.
+func Summarize(accounts []SummaryAccount) Summary {
+	var summary Summary
+	byAccount := make(map[string]*SummaryGroup)
+	byType := make(map[string]*SummaryGroup)
+	byGroup := make(map[string]*SummaryGroup)
+	group := func(groups map[string]*SummaryGroup, key string, init SummaryGroup) *SummaryGroup {
+		g, ok := groups[key]
+		if !ok {
+			g = &init
+			groups[key] = g
+		}
+		return g
+	}
+
+	for _, account := range accounts {
+		accountGroup := group(byAccount, account.AccountID, SummaryGroup{AccountID: account.AccountID, AccountName: account.AccountName})
+		for _, result := range account.Results {
+			savings := SavingsCost(result)
+			summary.Findings++
+			summary.MonthlySavings += savings
+			if result.LowConfidence() {
+				summary.UnverifiedFindings++
+				summary.UnverifiedSavings += UnverifiedCost(result)
+			}
+
+			typeGroup := group(byType, result.ResourceType, SummaryGroup{ResourceType: result.ResourceType})
+			accountTypeGroup := group(byGroup, account.AccountID+"/"+result.ResourceType, SummaryGroup{
+				AccountID:    account.AccountID,
+				AccountName:  account.AccountName,
+				ResourceType: result.ResourceType,
+			})
+			for _, g := range []*SummaryGroup{accountGroup, typeGroup, accountTypeGroup} {
+				g.Findings++
+				g.MonthlySavings += savings
+			}
+		}
+	}
+
+	for _, g := range byAccount {
+		summary.Accounts = append(summary.Accounts, *g)
+	}
+	sortSummaryGroups(summary.Accounts, func(g SummaryGroup) string { return g.AccountID })
+	for _, g := range byType {
+		summary.ResourceTypes = append(summary.ResourceTypes, *g)
+	}
+	sortSummaryGroups(summary.ResourceTypes, func(g SummaryGroup) string { return g.ResourceType })
+	for _, g := range byGroup {
+		summary.Groups = append(summary.Groups, *g)
+	}
+	sort.Slice(summary.Groups, func(i, j int) bool {
+		a, b := summary.Groups[i], summary.Groups[j]
+		if a.AccountID != b.AccountID {
+			return a.AccountID < b.AccountID
+		}
+		return a.ResourceType < b.ResourceType
+	})
+	return summary
+}
+
+// SummarizeResults summarizes results by the accounts they were found in
+func SummarizeResults(results []awsutil.ScanResult) Summary {
+	var accounts []SummaryAccount
+	index := make(map[string]int)
+	for _, result := range results {
+		i, ok := index[result.AccountID]
+		if !ok {
+			i = len(accounts)
+			index[result.AccountID] = i
+			accounts = append(accounts, SummaryAccount{AccountID: result.AccountID, AccountName: result.AccountName})
+		}
+		accounts[i].Results = append(accounts[i].Results, result)
+	}
+	return Summarize(accounts)
+}
+
+// sortSummaryGroups sorts groups by highest monthly savings, then by most findings and name
+func sortSummaryGroups(groups []SummaryGroup, name func(SummaryGroup) string) {
+	sort.Slice(groups, func(i, j int) bool {
+		a, b := groups[i], groups[j]
+		if a.MonthlySavings != b.MonthlySavings {
+			return a.MonthlySavings > b.MonthlySavings
+		}
+		if a.Findings != b.Findings {
+			return a.Findings > b.Findings
+		}
+		return name(a) < name(b)
+	})
+}
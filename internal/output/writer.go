@@ -3,12 +3,16 @@ package output
 import (
 	"bytes"
 	"compress/gzip"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"mime"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
+	"text/template"
 	"time"
 
 	awsutil "cloudsift/internal/aws"
@@ -16,6 +20,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 	"github.com/aws/aws-sdk-go/service/sts"
 	"github.com/schollz/progressbar/v3"
@@ -59,7 +64,31 @@ type Config struct {
 	Retry            *RetryConfig
 	Upload           *UploadConfig
 	Region           string
-	OrganizationRole string // Role to assume for S3 operations
+	OrganizationRole string           // Role to assume for S3 operations
+	S3Prefix         string           // Key prefix of the results in the bucket
+	Session          *session.Session // Session writing to S3 instead of the current credentials and OrganizationRole
+}
+
+// AccountLocation holds the fields the bucket and prefix templates of per-account S3 output
+// are executed with
+type AccountLocation struct {
+	AccountID   string
+	AccountName string
+	Team        string
+	Environment string
+}
+
+// RenderAccountLocation executes a bucket or prefix template for an account
+func RenderAccountLocation(text string, location AccountLocation) (string, error) {
+	tmpl, err := template.New("location").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid account location template %q: %w", text, err)
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, location); err != nil {
+		return "", fmt.Errorf("failed to render account location template %q: %w", text, err)
+	}
+	return b.String(), nil
 }
 
 // Writer handles writing scan results to different destinations
@@ -116,8 +145,8 @@ func (w *Writer) getFilePath(accountID string, t time.Time) string {
 		// In filesystem, create the directory structure with account ID as a folder
 		return filepath.Join(w.config.OutputDir, datePath, accountID, fileName)
 	}
-	// For S3, use the same structure without the base directory
-	return filepath.Join(datePath, accountID, fileName)
+	// For S3, use the same structure under the key prefix
+	return filepath.Join(w.config.S3Prefix, datePath, accountID, fileName)
 }
 
 // compressData compresses the input data using gzip
@@ -157,7 +186,22 @@ func (w *Writer) Write(accountID string, results interface{}) error {
 	case FileSystem:
 		return w.writeToFileSystem(path, compressedData)
 	case S3:
-		return w.writeToS3WithRetry(path, compressedData)
+		return w.writeToS3WithRetry(path, compressedData, "")
+	default:
+		return fmt.Errorf("unsupported output type: %s", w.config.Type)
+	}
+}
+
+// WriteFile writes a report file as is to the configured destination: into the output
+// directory, or under the date of the key prefix in S3 like the results. Files uploaded to S3
+// get the content type of their extension, so HTML reports open in the browser.
+func (w *Writer) WriteFile(name string, data []byte) error {
+	switch w.config.Type {
+	case FileSystem:
+		return w.writeToFileSystem(filepath.Join(w.config.OutputDir, name), data)
+	case S3:
+		key := path.Join(w.config.S3Prefix, time.Now().Format("2006/01/02"), name)
+		return w.writeToS3WithRetry(key, data, mime.TypeByExtension(path.Ext(name)))
 	default:
 		return fmt.Errorf("unsupported output type: %s", w.config.Type)
 	}
@@ -180,7 +224,7 @@ func (w *Writer) writeToFileSystem(path string, data []byte) error {
 }
 
 // writeToS3WithRetry writes data to an S3 bucket with retry logic
-func (w *Writer) writeToS3WithRetry(path string, data []byte) error {
+func (w *Writer) writeToS3WithRetry(path string, data []byte, contentType string) error {
 	if w.config.S3Bucket == "" {
 		return fmt.Errorf("S3 bucket not specified")
 	}
@@ -193,7 +237,7 @@ func (w *Writer) writeToS3WithRetry(path string, data []byte) error {
 			time.Sleep(w.config.Retry.RetryDelay)
 		}
 
-		if err := w.writeToS3(path, data); err != nil {
+		if err := w.writeToS3(path, data, contentType); err != nil {
 			lastErr = err
 			continue
 		}
@@ -206,7 +250,7 @@ func (w *Writer) writeToS3WithRetry(path string, data []byte) error {
 // getRoleARN returns the full ARN for a role. If the input is already an ARN, returns it as is.
 func getRoleARN(sess *session.Session, roleName string) (string, error) {
 	// If it's already an ARN, return it
-	if strings.HasPrefix(roleName, "arn:aws:iam::") {
+	if awsutil.IsRoleARN(roleName) {
 		return roleName, nil
 	}
 
@@ -218,15 +262,20 @@ func getRoleARN(sess *session.Session, roleName string) (string, error) {
 	}
 
 	// Construct the role ARN
-	return fmt.Sprintf("arn:aws:iam::%s:role/%s", *result.Account, roleName), nil
+	return awsutil.CallerRoleARN(result, roleName), nil
 }
 
-// writeToS3 writes data to an S3 bucket with progress tracking
-func (w *Writer) writeToS3(path string, data []byte) error {
+// s3Session returns the session writing to S3: the configured session, or the current
+// credentials assuming the organization role if one is specified
+func (w *Writer) s3Session() (*session.Session, error) {
+	if w.config.Session != nil {
+		return w.config.Session, nil
+	}
+
 	// Create base session
 	sess, err := awsutil.GetSession("", w.config.S3Region)
 	if err != nil {
-		return fmt.Errorf("failed to create AWS session: %w", err)
+		return nil, fmt.Errorf("failed to create AWS session: %w", err)
 	}
 
 	// If organization role is specified, assume it
@@ -234,7 +283,7 @@ func (w *Writer) writeToS3(path string, data []byte) error {
 		// Get full role ARN
 		roleARN, err := getRoleARN(sess, w.config.OrganizationRole)
 		if err != nil {
-			return fmt.Errorf("failed to get role ARN: %w", err)
+			return nil, fmt.Errorf("failed to get role ARN: %w", err)
 		}
 
 		// Create STS client
@@ -250,25 +299,43 @@ func (w *Writer) writeToS3(path string, data []byte) error {
 		// Assume the role
 		result, err := stsClient.AssumeRole(input)
 		if err != nil {
-			return fmt.Errorf("failed to assume role: %w", err)
+			return nil, fmt.Errorf("failed to assume role: %w", err)
 		}
 
 		// Create new session with temporary credentials
-		sess, err = session.NewSession(&aws.Config{
-			Region: aws.String(w.config.S3Region),
-			Credentials: credentials.NewStaticCredentials(
+		sess, err = session.NewSession(awsutil.SessionConfig().
+			WithRegion(w.config.S3Region).
+			WithCredentials(credentials.NewStaticCredentials(
 				*result.Credentials.AccessKeyId,
 				*result.Credentials.SecretAccessKey,
 				*result.Credentials.SessionToken,
-			),
-		})
+			)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create session with assumed role: %w", err)
+		}
+	}
+	return sess, nil
+}
+
+// writeToS3 writes data to an S3 bucket with progress tracking. An empty content type leaves
+// it to S3.
+func (w *Writer) writeToS3(path string, data []byte, contentType string) error {
+	sess, err := w.s3Session()
+	if err != nil {
+		return err
+	}
+
+	// Buckets without a configured region are written in their own region
+	region := w.config.S3Region
+	if region == "" {
+		region, err = s3manager.GetBucketRegion(context.Background(), sess, w.config.S3Bucket, "us-east-1")
 		if err != nil {
-			return fmt.Errorf("failed to create session with assumed role: %w", err)
+			return fmt.Errorf("failed to get region of bucket %s: %w", w.config.S3Bucket, err)
 		}
 	}
 
 	// Create uploader
-	uploader := s3manager.NewUploader(sess, func(u *s3manager.Uploader) {
+	uploader := s3manager.NewUploaderWithClient(s3.New(sess, aws.NewConfig().WithRegion(region)), func(u *s3manager.Uploader) {
 		u.PartSize = w.config.Upload.PartSize
 		u.Concurrency = w.config.Upload.ConcurrentParts
 	})
@@ -291,12 +358,16 @@ func (w *Writer) writeToS3(path string, data []byte) error {
 	}
 
 	// Upload the file with server-side encryption
-	_, err = uploader.Upload(&s3manager.UploadInput{
+	input := &s3manager.UploadInput{
 		Bucket:               aws.String(w.config.S3Bucket),
 		Key:                  aws.String(path),
 		Body:                 reader,
 		ServerSideEncryption: aws.String("aws:kms"),
-	})
+	}
+	if contentType != "" {
+		input.ContentType = aws.String(contentType)
+	}
+	_, err = uploader.Upload(input)
 
 	if err != nil {
 		return fmt.Errorf("failed to upload to S3: %w", err)
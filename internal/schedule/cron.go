@@ -0,0 +1,212 @@
+// Package schedule parses cron expressions and computes when they fire next
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxSearchYears bounds the search for the next time of an expression, such as 0 0 30 2 *,
+// that never fires
+const maxSearchYears = 5
+
+// descriptors are the shorthands of common expressions
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var monthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+var dayNames = map[string]int{
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+}
+
+// field is the range of values of a field of an expression
+type field struct {
+	name  string
+	min   int
+	max   int
+	names map[string]int
+}
+
+var (
+	minuteField = field{name: "minute", min: 0, max: 59}
+	hourField   = field{name: "hour", min: 0, max: 23}
+	domField    = field{name: "day of month", min: 1, max: 31}
+	monthField  = field{name: "month", min: 1, max: 12, names: monthNames}
+	// Sunday is 0 or 7
+	dowField = field{name: "day of week", min: 0, max: 7, names: dayNames}
+)
+
+// Cron is a parsed cron expression with the standard five fields: minute, hour, day of month,
+// month and day of week
+type Cron struct {
+	expr     string
+	location *time.Location
+	minutes  uint64
+	hours    uint64
+	doms     uint64
+	months   uint64
+	dows     uint64
+	// Whether the day fields are restricted. Like cron, a day matches either field when both
+	// are restricted.
+	domRestricted bool
+	dowRestricted bool
+}
+
+// ParseCron parses a cron expression, such as "0 6 * * *" or "@daily", evaluated in the
+// location. Fields are lists of values, ranges (1-5), steps (*/15, 0-30/10) and, for months
+// and days of the week, names (JAN, MON-FRI).
+func ParseCron(expr string, location *time.Location) (*Cron, error) {
+	fields := strings.Fields(expr)
+	if len(fields) == 1 && strings.HasPrefix(fields[0], "@") {
+		expanded, ok := descriptors[strings.ToLower(fields[0])]
+		if !ok {
+			return nil, fmt.Errorf("invalid cron expression %q: unknown descriptor %s", expr, fields[0])
+		}
+		fields = strings.Fields(expanded)
+	}
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid cron expression %q: expected 5 fields (minute hour day-of-month month day-of-week), got %d", expr, len(fields))
+	}
+	if location == nil {
+		location = time.UTC
+	}
+
+	c := &Cron{expr: expr, location: location}
+	var err error
+	parsed := []struct {
+		bits  *uint64
+		field field
+	}{
+		{&c.minutes, minuteField},
+		{&c.hours, hourField},
+		{&c.doms, domField},
+		{&c.months, monthField},
+		{&c.dows, dowField},
+	}
+	for i, p := range parsed {
+		if *p.bits, err = parseField(fields[i], p.field); err != nil {
+			return nil, fmt.Errorf("invalid cron expression %q: %w", expr, err)
+		}
+	}
+	if c.dows&(1<<7) != 0 {
+		c.dows |= 1
+	}
+	c.domRestricted = !strings.HasPrefix(fields[2], "*")
+	c.dowRestricted = !strings.HasPrefix(fields[4], "*")
+	return c, nil
+}
+
+// parseField returns the values of a field as a bit set
+func parseField(text string, f field) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(text, ",") {
+		rangeText, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			rangeText = part[:i]
+			step, err = strconv.Atoi(part[i+1:])
+			if err != nil || step < 1 {
+				return 0, fmt.Errorf("invalid step in %s field: %s", f.name, part)
+			}
+		}
+
+		var low, high int
+		switch {
+		case rangeText == "*":
+			low, high = f.min, f.max
+			if f.max == 7 {
+				high = 6
+			}
+		case strings.Contains(rangeText, "-"):
+			bounds := strings.SplitN(rangeText, "-", 2)
+			var err error
+			if low, err = parseValue(bounds[0], f); err != nil {
+				return 0, err
+			}
+			if high, err = parseValue(bounds[1], f); err != nil {
+				return 0, err
+			}
+			if low > high {
+				return 0, fmt.Errorf("invalid range in %s field: %s", f.name, part)
+			}
+		default:
+			var err error
+			if low, err = parseValue(rangeText, f); err != nil {
+				return 0, err
+			}
+			high = low
+			// A single value with a step, e.g. 5/15, runs from the value to the maximum
+			if strings.Contains(part, "/") {
+				high = f.max
+			}
+		}
+
+		for v := low; v <= high; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// parseValue parses a number or name of a field
+func parseValue(text string, f field) (int, error) {
+	if v, ok := f.names[strings.ToLower(text)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(text)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("invalid value in %s field: %s (%d-%d)", f.name, text, f.min, f.max)
+	}
+	return v, nil
+}
+
+// String returns the expression the schedule was parsed from
+func (c *Cron) String() string {
+	return c.expr
+}
+
+// Next returns the first time after the given time the expression fires, or the zero time if
+// it does not fire in the next years
+func (c *Cron) Next(after time.Time) time.Time {
+	t := after.In(c.location).Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(maxSearchYears, 0, 0)
+	for t.Before(limit) {
+		year, month, day := t.Date()
+		switch {
+		case c.months&(1<<uint(month)) == 0:
+			t = time.Date(year, month+1, 1, 0, 0, 0, 0, c.location)
+		case !c.dayMatches(t):
+			t = time.Date(year, month, day+1, 0, 0, 0, 0, c.location)
+		case c.hours&(1<<uint(t.Hour())) == 0:
+			t = time.Date(year, month, day, t.Hour()+1, 0, 0, 0, c.location)
+		case c.minutes&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// dayMatches returns whether the day of a time matches the day of month and day of week fields
+func (c *Cron) dayMatches(t time.Time) bool {
+	dom := c.doms&(1<<uint(t.Day())) != 0
+	dow := c.dows&(1<<uint(t.Weekday())) != 0
+	if c.domRestricted && c.dowRestricted {
+		return dom || dow
+	}
+	return dom && dow
+}
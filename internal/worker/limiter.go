@@ -0,0 +1,104 @@
+package worker
+
+import (
+	"context"
+	"sync"
+)
+
+// KeyedLimiter limits the number of tasks running at the same time per key, e.g. per account,
+// on top of the size of the pool running them
+type KeyedLimiter struct {
+	defaultLimit int
+	limits       map[string]int
+}
+
+// NewKeyedLimiter creates a limiter allowing limits[key] tasks per key, or defaultLimit for
+// keys without a limit. A limit of 0 or less does not limit the key.
+func NewKeyedLimiter(defaultLimit int, limits map[string]int) *KeyedLimiter {
+	return &KeyedLimiter{
+		defaultLimit: defaultLimit,
+		limits:       limits,
+	}
+}
+
+// Limit returns the number of tasks allowed to run at the same time for a key, or 0 if the
+// key is not limited
+func (l *KeyedLimiter) Limit(key string) int {
+	limit, ok := l.limits[key]
+	if !ok {
+		limit = l.defaultLimit
+	}
+	if limit < 0 {
+		return 0
+	}
+	return limit
+}
+
+// KeyedTask is a task counted against the limit of its key
+type KeyedTask struct {
+	Key  string
+	Task Task
+}
+
+// ExecuteKeyedTasks executes tasks like ExecuteTasks, running at most limiter.Limit(key) tasks of
+// a key at the same time. Tasks over the limit of their key are queued instead of submitted, so
+// they hold no worker and their timeout only starts once they run.
+func (p *Pool) ExecuteKeyedTasks(tasks []KeyedTask, limiter *KeyedLimiter) {
+	var wg sync.WaitGroup
+	wg.Add(len(tasks))
+
+	// Update total task count
+	p.metrics.mu.Lock()
+	p.metrics.TotalTasks += int64(len(tasks))
+	p.metrics.mu.Unlock()
+
+	var mu sync.Mutex
+	running := make(map[string]int)
+	queued := make(map[string][]Task)
+
+	// Wrap each task to track completion and hand its slot to the next queued task of its key
+	var wrap func(key string, task Task) Task
+	wrap = func(key string, task Task) Task {
+		return func(ctx context.Context) error {
+			defer wg.Done()
+			if limiter.Limit(key) > 0 {
+				defer func() {
+					mu.Lock()
+					defer mu.Unlock()
+					if next := queued[key]; len(next) > 0 {
+						queued[key] = next[1:]
+						// Submit from another goroutine so the worker never waits for a free worker
+						go p.Submit(wrap(key, next[0]))
+						return
+					}
+					running[key]--
+				}()
+			}
+			return task(ctx)
+		}
+	}
+
+	for _, t := range tasks {
+		if limit := limiter.Limit(t.Key); limit > 0 {
+			mu.Lock()
+			if running[t.Key] >= limit {
+				queued[t.Key] = append(queued[t.Key], t.Task)
+				mu.Unlock()
+				continue
+			}
+			running[t.Key]++
+			mu.Unlock()
+		}
+
+		// Submit tasks with backpressure
+		select {
+		case <-p.ctx.Done():
+			return // Pool is shutting down
+		default:
+			p.Submit(wrap(t.Key, t.Task))
+		}
+	}
+
+	// Wait for all tasks to complete
+	wg.Wait()
+}
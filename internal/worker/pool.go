@@ -2,12 +2,15 @@ package worker
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"runtime/debug"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"cloudsift/internal/config"
+	"cloudsift/internal/logging"
 )
 
 // TaskMetrics tracks performance metrics for a task
@@ -31,15 +34,51 @@ type PoolMetrics struct {
 	mu                 sync.RWMutex
 }
 
+// DefaultTaskTimeout is the timeout of each task, long enough to accommodate rate limiting backoff
+const DefaultTaskTimeout = 3 * time.Minute
+
 // Task represents a unit of work to be executed
 type Task func(ctx context.Context) error
 
 // markerTask is a special task used for synchronization
 type markerTask Task
 
+// PanicError is returned for a task that panicked instead of returning
+type PanicError struct {
+	Value interface{} // Value passed to panic
+	Stack string      // Stack trace of the panicking goroutine
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("task panicked: %v", e.Value)
+}
+
+// Recover converts a panic in the calling goroutine into a *PanicError stored in err.
+// It must be called directly with defer, e.g. defer worker.Recover(&err).
+func Recover(err *error) {
+	if r := recover(); r != nil {
+		*err = &PanicError{Value: r, Stack: string(debug.Stack())}
+	}
+}
+
+// runTask runs a task so that a panic fails the task instead of crashing the process
+func runTask(ctx context.Context, task Task) (err error) {
+	defer func() {
+		var panicErr *PanicError
+		if errors.As(err, &panicErr) {
+			logging.Error("Task panicked", err, map[string]interface{}{
+				"stack": panicErr.Stack,
+			})
+		}
+	}()
+	defer Recover(&err)
+	return task(ctx)
+}
+
 // Pool manages a pool of workers for executing tasks concurrently
 type Pool struct {
 	maxWorkers    int
+	taskTimeout   time.Duration
 	tasks         chan Task
 	wg            sync.WaitGroup
 	ctx           context.Context
@@ -53,14 +92,20 @@ type Pool struct {
 func NewPool(maxWorkers int) *Pool {
 	ctx, cancel := context.WithCancel(context.Background())
 	return &Pool{
-		maxWorkers: maxWorkers,
-		tasks:      make(chan Task, maxWorkers*2), // Buffer the channel to prevent blocking
-		ctx:        ctx,
-		cancel:     cancel,
-		metrics:    &PoolMetrics{},
+		maxWorkers:  maxWorkers,
+		taskTimeout: DefaultTaskTimeout,
+		tasks:       make(chan Task, maxWorkers*2), // Buffer the channel to prevent blocking
+		ctx:         ctx,
+		cancel:      cancel,
+		metrics:     &PoolMetrics{},
 	}
 }
 
+// SetTaskTimeout sets the timeout of each task run by the pool. It must be called before Start.
+func (p *Pool) SetTaskTimeout(timeout time.Duration) {
+	p.taskTimeout = timeout
+}
+
 // Start starts the worker pool
 func (p *Pool) Start() {
 	for i := 0; i < p.maxWorkers; i++ {
@@ -169,9 +214,9 @@ func (p *Pool) worker() {
 
 			// Create a child context for the task that is cancelled when either:
 			// 1. The pool is stopping (p.ctx is cancelled)
-			// 2. The task times out (3 minute timeout to accommodate rate limiting backoff)
-			taskCtx, cancel := context.WithTimeout(p.ctx, 3*time.Minute)
-			err := task(taskCtx)
+			// 2. The task times out (3 minutes by default to accommodate rate limiting backoff)
+			taskCtx, cancel := context.WithTimeout(p.ctx, p.taskTimeout)
+			err := runTask(taskCtx, task)
 			cancel()
 
 			executionMs := time.Since(start).Milliseconds()
@@ -195,8 +240,8 @@ func (p *Pool) worker() {
 						return
 					}
 					// Create a new timeout context since pool context is already cancelled
-					taskCtx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
-					if err := task(taskCtx); err != nil {
+					taskCtx, cancel := context.WithTimeout(context.Background(), p.taskTimeout)
+					if err := runTask(taskCtx, task); err != nil {
 						atomic.AddInt64(&p.metrics.FailedTasks, 1)
 					}
 					cancel()